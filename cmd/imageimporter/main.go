@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/image/importer"
+	"github.com/harvester/harvester/pkg/version"
+)
+
+var (
+	logDebug bool
+
+	sourcePath   string
+	diskPath     string
+	listenAddr   string
+	dataSource   string
+	uploadedName string
+)
+
+var rootCmd = &cobra.Command{
+	Use:     "image-importer",
+	Short:   "Harvester Image Importer",
+	Long:    "Import the images of VM images to Longhorn backing images in the jobs converting them",
+	Version: fmt.Sprintf("%s (%s)", version.Version, version.GitCommit),
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		logrus.SetOutput(os.Stdout)
+		if logDebug {
+			logrus.SetLevel(logrus.DebugLevel)
+		}
+	},
+}
+
+var fetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Download, pull or receive the uploaded image and write its disk to a file",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		source, err := importer.LoadSource(sourcePath)
+		if err != nil {
+			return err
+		}
+
+		if source.Type == harvesterv1.VirtualMachineImageSourceTypeUpload {
+			listener, err := net.Listen("tcp", listenAddr)
+			if err != nil {
+				return err
+			}
+			logrus.Infof("Waiting for the uploaded image on %s", listenAddr)
+			return importer.Receive(cmd.Context(), listener, source.UploadToken, func(r io.Reader, size int64) error {
+				return importer.WriteDisk(source, r, size, diskPath)
+			})
+		}
+
+		image, size, err := importer.Open(cmd.Context(), source)
+		if err != nil {
+			return err
+		}
+		defer image.Close()
+		logrus.Infof("Fetching image %s", source.URL)
+		return importer.WriteDisk(source, image, size, diskPath)
+	},
+}
+
+var uploadCmd = &cobra.Command{
+	Use:   "upload",
+	Short: "Upload the disk file to the backing image data source",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		logrus.Infof("Uploading %s to backing image data source %s", diskPath, dataSource)
+		return importer.UploadDisk(cmd.Context(), &http.Client{}, dataSource, uploadedName, diskPath)
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&logDebug, "debug", false, "set logging level to debug")
+	rootCmd.PersistentFlags().StringVar(&diskPath, "disk", "", "path of the disk file")
+	cobra.CheckErr(rootCmd.MarkPersistentFlagRequired("disk"))
+
+	fetchCmd.Flags().StringVar(&sourcePath, "source", "", "path of the source file mounted from the secret of the job")
+	fetchCmd.Flags().StringVar(&listenAddr, "listen", ":8080", "address receiving the uploaded image")
+	cobra.CheckErr(fetchCmd.MarkFlagRequired("source"))
+
+	uploadCmd.Flags().StringVar(&dataSource, "data-source", "", "name of the backing image data source")
+	uploadCmd.Flags().StringVar(&uploadedName, "name", "", "file name of the uploaded disk")
+	cobra.CheckErr(uploadCmd.MarkFlagRequired("data-source"))
+
+	rootCmd.AddCommand(fetchCmd, uploadCmd)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	cobra.CheckErr(err)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/longhorn/backupstore"
+	_ "github.com/longhorn/backupstore/nfs" //nolint
+	_ "github.com/longhorn/backupstore/s3"  //nolint
+	_ "github.com/longhorn/backupstore/vfs" //nolint
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/harvester/harvester/pkg/version"
+	"github.com/harvester/harvester/pkg/volumeremotebackup/mover"
+)
+
+var (
+	logDebug bool
+
+	backupTarget string
+	manifestPath string
+	mode         string
+	dataPath     string
+	chunkSize    int64
+	lockTimeout  time.Duration
+)
+
+// lockRetryInterval is the time to wait before trying to acquire the lock of the backup target again
+const lockRetryInterval = 30 * time.Second
+
+var rootCmd = &cobra.Command{
+	Use:     "volume-mover",
+	Short:   "Harvester Volume Mover",
+	Long:    "Move the data of a volume between a CSI snapshot and the backup target for the generic VolumeRemoteBackup driver",
+	Version: fmt.Sprintf("%s (%s)", version.Version, version.GitCommit),
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		logrus.SetOutput(os.Stdout)
+		if logDebug {
+			logrus.SetLevel(logrus.DebugLevel)
+		}
+	},
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up a block device or a mounted filesystem to the backup target",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		store, err := backupstore.GetBackupStoreDriver(backupTarget)
+		if err != nil {
+			return fmt.Errorf("failed to connect to backup target: %w", err)
+		}
+		return withLock(store, mover.LockShared, runBackup)
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a backup from the backup target to a block device or a mounted filesystem",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		store, err := backupstore.GetBackupStoreDriver(backupTarget)
+		if err != nil {
+			return fmt.Errorf("failed to connect to backup target: %w", err)
+		}
+		return withLock(store, mover.LockShared, runRestore)
+	},
+}
+
+var sweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Remove the chunks which aren't referenced by any backup from the backup target",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		store, err := backupstore.GetBackupStoreDriver(backupTarget)
+		if err != nil {
+			return fmt.Errorf("failed to connect to backup target: %w", err)
+		}
+		return withLock(store, mover.LockExclusive, runSweep)
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&logDebug, "debug", false, "set logging level to debug")
+	rootCmd.PersistentFlags().StringVar(&backupTarget, "backup-target", "", "URL of the S3 backup target or vfs:// URL of the mounted NFS backup target")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 30*time.Minute, "time to wait for the lock of the backup target")
+	cobra.CheckErr(rootCmd.MarkPersistentFlagRequired("backup-target"))
+
+	for _, cmd := range []*cobra.Command{backupCmd, restoreCmd} {
+		cmd.Flags().StringVar(&manifestPath, "manifest", "", "path of the backup manifest on the backup target")
+		cmd.Flags().StringVar(&mode, "mode", string(mover.ModeBlock), "block or filesystem")
+		cmd.Flags().StringVar(&dataPath, "path", "", "path of the block device or the mount point of the filesystem")
+		for _, flag := range []string{"manifest", "path"} {
+			cobra.CheckErr(cmd.MarkFlagRequired(flag))
+		}
+	}
+	backupCmd.Flags().Int64Var(&chunkSize, "chunk-size", mover.DefaultChunkSize, "size of the chunks in bytes")
+
+	rootCmd.AddCommand(backupCmd, restoreCmd, sweepCmd)
+}
+
+// withLock runs f while holding the lock of the backup target. Backups and restores share it,
+// the sweep must not remove the chunks a running backup has uploaded before writing its manifest.
+func withLock(store backupstore.BackupStoreDriver, lockType mover.LockType, f func(mover.Store) error) error {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		lock, err := mover.AcquireLock(store, lockType)
+		if err == nil {
+			defer func() {
+				if err := lock.Release(); err != nil {
+					logrus.WithError(err).Warn("Failed to release the lock of the backup target")
+				}
+			}()
+			return f(store)
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		logrus.WithError(err).Infof("Waiting %s for the lock of the backup target", lockRetryInterval)
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func runBackup(store mover.Store) error {
+	var src io.Reader
+	switch mover.Mode(mode) {
+	case mover.ModeBlock:
+		f, err := os.Open(dataPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		src = f
+	case mover.ModeFilesystem:
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(mover.Archive(dataPath, pw))
+		}()
+		defer pr.Close()
+		src = pr
+	default:
+		return fmt.Errorf("unsupported mode %s", mode)
+	}
+
+	manifest, stats, err := mover.Backup(store, manifestPath, mover.Mode(mode), src, chunkSize)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Backed up %d bytes to %s, chunks: %d, uploaded: %d, deduplicated: %d, zero: %d",
+		manifest.Size, manifestPath, stats.Total, stats.Uploaded, stats.Deduplicated, stats.Zero)
+	return nil
+}
+
+func runRestore(store mover.Store) error {
+	manifest, err := mover.ReadManifest(store, manifestPath)
+	if err != nil {
+		return err
+	}
+	if manifest.Mode != mover.Mode(mode) {
+		return fmt.Errorf("backup %s is in %s mode, can't be restored in %s mode", manifestPath, manifest.Mode, mode)
+	}
+
+	switch manifest.Mode {
+	case mover.ModeBlock:
+		f, err := os.OpenFile(dataPath, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		if err := mover.Restore(store, manifest, f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	case mover.ModeFilesystem:
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(mover.Restore(store, manifest, pw))
+		}()
+		if err := mover.Extract(pr, dataPath); err != nil {
+			pr.CloseWithError(err)
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported mode %s", manifest.Mode)
+	}
+
+	logrus.Infof("Restored %d bytes from %s", manifest.Size, manifestPath)
+	return nil
+}
+
+func runSweep(store mover.Store) error {
+	removed, err := mover.Sweep(store)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Removed %d unreferenced chunks", removed)
+	return nil
+}
+
+func main() {
+	cobra.CheckErr(rootCmd.Execute())
+}
@@ -0,0 +1,247 @@
+package backupcatalog
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/v3/pkg/schemas/validation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+
+	apiutil "github.com/harvester/harvester/pkg/api/util"
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/config"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	harvesterServer "github.com/harvester/harvester/pkg/server/http"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// Handler returns the backups and snapshots of a VM in all namespaces the user can list them,
+// the VM is matched by the source of the backups so that the backups of a deleted VM are found too.
+type Handler struct {
+	clientSet     kubernetes.Interface
+	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache
+	vmCache       ctlkubevirtv1.VirtualMachineCache
+}
+
+func NewHandler(scaled *config.Scaled) *Handler {
+	return &Handler{
+		clientSet:     scaled.Management.ClientSet,
+		vmBackupCache: scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup().Cache(),
+		vmCache:       scaled.VirtFactory.Kubevirt().V1().VirtualMachine().Cache(),
+	}
+}
+
+// filter selects the entries of the catalog, the zero value selects all entries
+type filter struct {
+	from  *time.Time
+	to    *time.Time
+	types map[harvesterv1.BackupType]bool
+	ready *bool
+}
+
+func parseFilter(query url.Values) (*filter, error) {
+	f := &filter{}
+	for _, key := range []string{"from", "to"} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q, it should be in RFC3339 format", key, value)
+		}
+		if key == "from" {
+			f.from = &t
+		} else {
+			f.to = &t
+		}
+	}
+
+	for _, value := range query["type"] {
+		backupType := harvesterv1.BackupType(value)
+		if backupType != harvesterv1.Backup && backupType != harvesterv1.Snapshot {
+			return nil, fmt.Errorf("invalid type %q, it should be %s or %s", value, harvesterv1.Backup, harvesterv1.Snapshot)
+		}
+		if f.types == nil {
+			f.types = map[harvesterv1.BackupType]bool{}
+		}
+		f.types[backupType] = true
+	}
+
+	if value := query.Get("ready"); value != "" {
+		ready, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ready %q, it should be true or false", value)
+		}
+		f.ready = &ready
+	}
+	return f, nil
+}
+
+func (f *filter) match(entry *Entry) bool {
+	if f.from != nil && entry.CreationTime.Time.Before(*f.from) {
+		return false
+	}
+	if f.to != nil && entry.CreationTime.Time.After(*f.to) {
+		return false
+	}
+	if f.types != nil && !f.types[entry.Type] {
+		return false
+	}
+	if f.ready != nil && *f.ready != entry.ReadyToUse {
+		return false
+	}
+	return true
+}
+
+func (h *Handler) Do(ctx *harvesterServer.Ctx) (harvesterServer.ResponseBody, error) {
+	r := ctx.Req()
+	vars := util.EncodeVars(mux.Vars(r))
+	namespace, name := vars["namespace"], vars["name"]
+
+	userInfo, ok := request.UserFrom(r.Context())
+	if !ok {
+		return nil, apierror.NewAPIError(validation.Unauthorized, "failed to get user from request")
+	}
+
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		return nil, apierror.NewAPIError(validation.InvalidFormat, err.Error())
+	}
+
+	vmBackups, err := h.vmBackupCache.List(metav1.NamespaceAll, labels.Everything())
+	if err != nil {
+		return nil, apierror.NewAPIError(validation.ServerError, fmt.Sprintf("failed to list vm backups: %v", err))
+	}
+
+	// the permission is checked once per namespace
+	allowed := map[string]bool{}
+	canList := func(backupNamespace string) (bool, error) {
+		if result, ok := allowed[backupNamespace]; ok {
+			return result, nil
+		}
+		result, err := apiutil.CanListVMBackups(h.clientSet, backupNamespace, userInfo)
+		if err != nil {
+			return false, err
+		}
+		allowed[backupNamespace] = result
+		return result, nil
+	}
+
+	var matched []*harvesterv1.VirtualMachineBackup
+	for _, vmBackup := range vmBackups {
+		if !isBackupOfVM(vmBackup, namespace, name) {
+			continue
+		}
+		ok, err := canList(vmBackup.Namespace)
+		if err != nil {
+			return nil, apierror.NewAPIError(validation.ServerError, fmt.Sprintf("failed to check permission: %v", err))
+		}
+		if ok {
+			matched = append(matched, vmBackup)
+		}
+	}
+
+	catalog := buildCatalog(namespace, name, matched, f)
+	if catalog.Deleted, err = h.isVMDeleted(userInfo, namespace, name); err != nil {
+		return nil, apierror.NewAPIError(validation.ServerError, err.Error())
+	}
+
+	return catalog, nil
+}
+
+// isVMDeleted tells whether the VM doesn't exist anymore only to the users who can get it,
+// otherwise the catalog would reveal the VMs of the namespaces the user can only list backups in
+func (h *Handler) isVMDeleted(userInfo user.Info, namespace, name string) (bool, error) {
+	ok, err := apiutil.CanGetVM(h.clientSet, namespace, name, userInfo)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	if _, err := h.vmCache.Get(namespace, name); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get vm %s/%s: %w", namespace, name, err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// isBackupOfVM matches the source of the backup, the backups synced from a backup target
+// are matched by the VM recorded in the source spec
+func isBackupOfVM(vmBackup *harvesterv1.VirtualMachineBackup, namespace, name string) bool {
+	if vmBackup.Namespace == namespace && vmBackup.Spec.Source.Name == name {
+		return true
+	}
+
+	sourceSpec := vmBackup.Status.SourceSpec
+	return sourceSpec != nil && sourceSpec.ObjectMeta.Namespace == namespace && sourceSpec.ObjectMeta.Name == name
+}
+
+func buildCatalog(namespace, name string, vmBackups []*harvesterv1.VirtualMachineBackup, f *filter) *Catalog {
+	catalog := &Catalog{
+		Namespace: namespace,
+		Name:      name,
+		Entries:   []Entry{},
+	}
+
+	for _, vmBackup := range vmBackups {
+		entry := newEntry(vmBackup)
+		if f.match(&entry) {
+			catalog.Entries = append(catalog.Entries, entry)
+		}
+	}
+
+	sort.SliceStable(catalog.Entries, func(i, j int) bool {
+		return catalog.Entries[i].CreationTime.Before(&catalog.Entries[j].CreationTime)
+	})
+	return catalog
+}
+
+func newEntry(vmBackup *harvesterv1.VirtualMachineBackup) Entry {
+	entry := Entry{
+		Namespace:    vmBackup.Namespace,
+		Name:         vmBackup.Name,
+		Type:         vmBackup.Spec.Type,
+		CreationTime: vmBackup.CreationTimestamp,
+		ReadyToUse:   vmBackup.Status.ReadyToUse != nil && *vmBackup.Status.ReadyToUse,
+		Progress:     vmBackup.Status.Progress,
+		BackupTarget: vmBackup.Status.BackupTarget,
+		Volumes:      []Volume{},
+	}
+
+	if vmBackup.Status.CreationTime != nil {
+		entry.CreationTime = *vmBackup.Status.CreationTime
+	}
+	if vmBackup.Status.SourceUID != nil {
+		entry.SourceUID = *vmBackup.Status.SourceUID
+	}
+	if vmBackup.Status.Error != nil && vmBackup.Status.Error.Message != nil {
+		entry.Error = *vmBackup.Status.Error.Message
+	}
+
+	for _, vb := range vmBackup.Status.VolumeBackups {
+		entry.Size += vb.VolumeSize
+		entry.Volumes = append(entry.Volumes, Volume{
+			Name:                  vb.VolumeName,
+			PersistentVolumeClaim: vb.PersistentVolumeClaim.ObjectMeta.Name,
+			Size:                  vb.VolumeSize,
+			ReadyToUse:            vb.ReadyToUse != nil && *vb.ReadyToUse,
+		})
+	}
+	return entry
+}
@@ -0,0 +1,191 @@
+package backupcatalog
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newVMBackup(namespace, name, vmName string, backupType harvesterv1.BackupType, created time.Time, ready bool) *harvesterv1.VirtualMachineBackup {
+	return &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: harvesterv1.VirtualMachineBackupSpec{
+			Type:   backupType,
+			Source: corev1.TypedLocalObjectReference{Name: vmName},
+		},
+		Status: harvesterv1.VirtualMachineBackupStatus{
+			ReadyToUse: ptr.To(ready),
+			VolumeBackups: []harvesterv1.VolumeBackup{
+				{
+					VolumeName: "disk-0",
+					PersistentVolumeClaim: harvesterv1.PersistentVolumeClaimSourceSpec{
+						ObjectMeta: metav1.ObjectMeta{Name: vmName + "-disk-0"},
+					},
+					VolumeSize: 10 << 30,
+					ReadyToUse: ptr.To(ready),
+				},
+				{
+					VolumeName: "disk-1",
+					PersistentVolumeClaim: harvesterv1.PersistentVolumeClaimSourceSpec{
+						ObjectMeta: metav1.ObjectMeta{Name: vmName + "-disk-1"},
+					},
+					VolumeSize: 5 << 30,
+					ReadyToUse: ptr.To(ready),
+				},
+			},
+		},
+	}
+}
+
+func Test_isBackupOfVM(t *testing.T) {
+	day := time.Date(2024, 7, 17, 0, 0, 0, 0, time.UTC)
+
+	local := newVMBackup("default", "local", "vm", harvesterv1.Backup, day, true)
+	assert.True(t, isBackupOfVM(local, "default", "vm"))
+	assert.False(t, isBackupOfVM(local, "other", "vm"))
+	assert.False(t, isBackupOfVM(local, "default", "vm2"))
+
+	// a backup synced from the backup target into another namespace
+	synced := newVMBackup("restore", "synced", "", harvesterv1.Backup, day, true)
+	synced.Status.SourceSpec = &harvesterv1.VirtualMachineSourceSpec{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"},
+	}
+	assert.True(t, isBackupOfVM(synced, "default", "vm"))
+	assert.False(t, isBackupOfVM(synced, "restore", "vm"))
+}
+
+func Test_buildCatalog(t *testing.T) {
+	day := time.Date(2024, 7, 17, 0, 0, 0, 0, time.UTC)
+	vmBackups := []*harvesterv1.VirtualMachineBackup{
+		newVMBackup("default", "backup-2", "vm", harvesterv1.Backup, day.Add(48*time.Hour), false),
+		newVMBackup("default", "snapshot-1", "vm", harvesterv1.Snapshot, day.Add(24*time.Hour), true),
+		newVMBackup("default", "backup-0", "vm", harvesterv1.Backup, day, true),
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			query:    "",
+			expected: []string{"backup-0", "snapshot-1", "backup-2"},
+		},
+		{
+			name:     "time range",
+			query:    "from=2024-07-17T12:00:00Z&to=2024-07-19T00:00:00Z",
+			expected: []string{"snapshot-1", "backup-2"},
+		},
+		{
+			name:     "type",
+			query:    "type=backup",
+			expected: []string{"backup-0", "backup-2"},
+		},
+		{
+			name:     "multiple types",
+			query:    "type=backup&type=snapshot",
+			expected: []string{"backup-0", "snapshot-1", "backup-2"},
+		},
+		{
+			name:     "ready",
+			query:    "ready=true&type=backup",
+			expected: []string{"backup-0"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+			f, err := parseFilter(query)
+			require.NoError(t, err)
+
+			catalog := buildCatalog("default", "vm", vmBackups, f)
+			var names []string
+			for _, entry := range catalog.Entries {
+				names = append(names, entry.Name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+
+	catalog := buildCatalog("default", "vm", vmBackups[2:], &filter{})
+	require.Len(t, catalog.Entries, 1)
+	entry := catalog.Entries[0]
+	assert.Equal(t, int64(15<<30), entry.Size)
+	assert.Equal(t, []Volume{
+		{Name: "disk-0", PersistentVolumeClaim: "vm-disk-0", Size: 10 << 30, ReadyToUse: true},
+		{Name: "disk-1", PersistentVolumeClaim: "vm-disk-1", Size: 5 << 30, ReadyToUse: true},
+	}, entry.Volumes)
+}
+
+func Test_parseFilterInvalid(t *testing.T) {
+	for _, query := range []string{"from=yesterday", "type=image", "ready=maybe"} {
+		values, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		_, err = parseFilter(values)
+		assert.Error(t, err, query)
+	}
+}
+
+func Test_isVMDeleted(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"},
+	})
+	// alice can get the VMs of the default namespace only
+	coreclientset := corefake.NewSimpleClientset()
+	coreclientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		assert.Equal(t, "alice", review.Spec.User)
+		assert.Equal(t, "alice-uid", review.Spec.UID)
+		assert.Equal(t, authorizationv1.ExtraValue{"rancher"}, review.Spec.Extra["principalid"])
+		review.Status.Allowed = review.Spec.ResourceAttributes.Verb == "get" &&
+			review.Spec.ResourceAttributes.Resource == "virtualmachines" &&
+			review.Spec.ResourceAttributes.Namespace == "default"
+		return true, review, nil
+	})
+	h := &Handler{
+		clientSet: coreclientset,
+		vmCache:   fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+	}
+	alice := &user.DefaultInfo{Name: "alice", UID: "alice-uid", Extra: map[string][]string{"principalid": {"rancher"}}}
+
+	tests := []struct {
+		name      string
+		namespace string
+		vmName    string
+		expected  bool
+	}{
+		{name: "existing vm", namespace: "default", vmName: "vm", expected: false},
+		{name: "deleted vm", namespace: "default", vmName: "deleted", expected: true},
+		{name: "vm the user can't get", namespace: "other", vmName: "deleted", expected: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			deleted, err := h.isVMDeleted(alice, tc.namespace, tc.vmName)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, deleted)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package backupcatalog
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+// Catalog is the timeline of the backups and snapshots of a VM, the oldest first
+type Catalog struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Deleted is true if the VM doesn't exist anymore, the backups can still be restored.
+	// It's only set for the users who can get the VM.
+	Deleted bool    `json:"deleted"`
+	Entries []Entry `json:"entries"`
+}
+
+type Entry struct {
+	Namespace    string                        `json:"namespace"`
+	Name         string                        `json:"name"`
+	Type         harvesterv1.BackupType        `json:"type"`
+	CreationTime metav1.Time                   `json:"creationTime"`
+	SourceUID    types.UID                     `json:"sourceUID,omitempty"`
+	ReadyToUse   bool                          `json:"readyToUse"`
+	Progress     int                           `json:"progress,omitempty"`
+	Error        string                        `json:"error,omitempty"`
+	BackupTarget *harvesterv1.BackupTargetInfo `json:"backupTarget,omitempty"`
+	// Size is the total size of the volumes in bytes
+	Size    int64    `json:"size"`
+	Volumes []Volume `json:"volumes"`
+}
+
+type Volume struct {
+	Name                  string `json:"name"`
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+	Size                  int64  `json:"size"`
+	ReadyToUse            bool   `json:"readyToUse"`
+}
@@ -21,20 +21,31 @@ import (
 )
 
 type HealthyHandler struct {
-	context      context.Context
-	settingCache v1beta1.SettingCache
-	secretCache  ctlcorev1.SecretCache
+	context           context.Context
+	settingCache      v1beta1.SettingCache
+	secretCache       ctlcorev1.SecretCache
+	backupTargetCache v1beta1.BackupTargetCache
 }
 
 func NewHealthyHandler(scaled *config.Scaled) *HealthyHandler {
 	return &HealthyHandler{
-		context:      scaled.Ctx,
-		settingCache: scaled.HarvesterFactory.Harvesterhci().V1beta1().Setting().Cache(),
-		secretCache:  scaled.CoreFactory.Core().V1().Secret().Cache(),
+		context:           scaled.Ctx,
+		settingCache:      scaled.HarvesterFactory.Harvesterhci().V1beta1().Setting().Cache(),
+		secretCache:       scaled.CoreFactory.Core().V1().Secret().Cache(),
+		backupTargetCache: scaled.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget().Cache(),
 	}
 }
 
 func (h *HealthyHandler) Do(ctx *harvesterServer.Ctx) (harvesterServer.ResponseBody, error) {
+	// check the named BackupTarget object if the name query parameter is given, otherwise the backup-target setting
+	if name := ctx.Req().URL.Query().Get("name"); name != "" {
+		bt, err := h.backupTargetCache.Get(name)
+		if err != nil {
+			return nil, apierror.NewAPIError(validation.NotFound, fmt.Errorf("can't get backup target %s, error: %w", name, err).Error())
+		}
+		return h.checkBackupTarget(ctx, backuputil.ConvertBackupTarget(bt))
+	}
+
 	backupTargetSetting, err := h.settingCache.Get(settings.BackupTargetSettingName)
 	if err != nil {
 		return nil, apierror.NewAPIError(validation.ServerError, fmt.Errorf("can't get %s setting, error: %w", settings.BackupTargetSettingName, err).Error())
@@ -51,7 +62,11 @@ func (h *HealthyHandler) Do(ctx *harvesterServer.Ctx) (harvesterServer.ResponseB
 		return nil, apierror.NewAPIError(validation.InvalidBodyContent, fmt.Errorf("can't check the backup target healthy, %s setting is not set", settings.BackupTargetSettingName).Error())
 	}
 
-	_, err = backuputil.GetBackupStoreDriver(h.secretCache, target)
+	return h.checkBackupTarget(ctx, target)
+}
+
+func (h *HealthyHandler) checkBackupTarget(ctx *harvesterServer.Ctx, target *settings.BackupTarget) (harvesterServer.ResponseBody, error) {
+	_, err := backuputil.GetBackupStoreDriver(h.secretCache, target)
 	if err != nil {
 		return nil, apierror.NewAPIError(validation.InvalidBodyContent, fmt.Errorf("can't connect to backup target %+v, error: %w", target, err).Error())
 	}
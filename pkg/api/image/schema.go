@@ -35,7 +35,7 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, _ config.Optio
 		harvesterv1.VMIBackendCDI:          cdi.GetDownloader(vmImageDownloader, http.Client{}, vmio),
 	}
 	uploaders := map[harvesterv1.VMIBackend]backend.Uploader{
-		harvesterv1.VMIBackendBackingImage: backingimage.GetUploader(bi.Cache(), bids, http.Client{}, vmio),
+		harvesterv1.VMIBackendBackingImage: backingimage.GetUploader(bi.Cache(), bids, scaled.Management.ClientSet, vmio),
 		harvesterv1.VMIBackendCDI:          cdi.GetUploader(ctlcdi, scClient, ctlcdiupload, http.Client{}, vmio),
 	}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/harvester/harvester/pkg/api/node"
 	"github.com/harvester/harvester/pkg/api/upgradelog"
 	"github.com/harvester/harvester/pkg/api/vm"
+	"github.com/harvester/harvester/pkg/api/vmbackup"
 	"github.com/harvester/harvester/pkg/api/vmtemplate"
 	"github.com/harvester/harvester/pkg/api/volume"
 	"github.com/harvester/harvester/pkg/api/volumesnapshot"
@@ -40,6 +41,7 @@ func Setup(ctx context.Context, server *server.Server, _ *server.Controllers, op
 		upgradelog.RegisterSchema,
 		volume.RegisterSchema,
 		volumesnapshot.RegisterSchema,
+		vmbackup.RegisterSchema,
 		cluster.RegisterSchema,
 		namespace.RegisterSchema,
 	)
@@ -0,0 +1,155 @@
+package util
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+func CanUpdateVMs(clientSet kubernetes.Interface, namespace string, user string, groups []string) (bool, error) {
+	review, err := clientSet.AuthorizationV1().SubjectAccessReviews().Create(
+		context.TODO(),
+		&authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      "update",
+					Group:     kubevirtv1.SchemeGroupVersion.Group,
+					Version:   kubevirtv1.SchemeGroupVersion.Version,
+					Resource:  "virtualmachines",
+				},
+				User:   user,
+				Groups: groups,
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"namespace": namespace,
+			"user":      user,
+		}).Error("Failed to check update virtual machines")
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// CanGetVMISubresource checks whether the user can get the subresource of the VMI, e.g. vnc and console,
+// which grant the access to the guest.
+func CanGetVMISubresource(clientSet kubernetes.Interface, namespace, name, subresource string, user string, groups []string) (bool, error) {
+	review, err := clientSet.AuthorizationV1().SubjectAccessReviews().Create(
+		context.TODO(),
+		&authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   namespace,
+					Name:        name,
+					Verb:        "get",
+					Group:       kubevirtv1.SubresourceGroupName,
+					Version:     kubevirtv1.SchemeGroupVersion.Version,
+					Resource:    "virtualmachineinstances",
+					Subresource: subresource,
+				},
+				User:   user,
+				Groups: groups,
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"namespace":   namespace,
+			"name":        name,
+			"subresource": subresource,
+			"user":        user,
+		}).Error("Failed to check get virtual machine instance subresource")
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// CanCreateVMWithVolumes checks whether the user can create the VM along with its volumes and secrets in the namespace,
+// the actions like clone create them on behalf of the user.
+func CanCreateVMWithVolumes(clientSet kubernetes.Interface, namespace string, user string, groups []string) (bool, error) {
+	for _, attributes := range []authorizationv1.ResourceAttributes{
+		{
+			Group:    kubevirtv1.SchemeGroupVersion.Group,
+			Version:  kubevirtv1.SchemeGroupVersion.Version,
+			Resource: "virtualmachines",
+		},
+		{
+			Version:  "v1",
+			Resource: "persistentvolumeclaims",
+		},
+		{
+			Version:  "v1",
+			Resource: "secrets",
+		},
+	} {
+		attributes.Namespace = namespace
+		attributes.Verb = "create"
+		review, err := clientSet.AuthorizationV1().SubjectAccessReviews().Create(
+			context.TODO(),
+			&authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					ResourceAttributes: &attributes,
+					User:               user,
+					Groups:             groups,
+				},
+			},
+			metav1.CreateOptions{},
+		)
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"namespace": namespace,
+				"resource":  attributes.Resource,
+				"user":      user,
+			}).Error("Failed to check create resources")
+			return false, err
+		}
+		if !review.Status.Allowed {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CanGetSecrets checks whether the user can get all the secrets in the namespace, e.g. the cloud-init secrets of the VM
+// which are packaged by the export.
+func CanGetSecrets(clientSet kubernetes.Interface, namespace string, names []string, user string, groups []string) (bool, error) {
+	for _, name := range names {
+		review, err := clientSet.AuthorizationV1().SubjectAccessReviews().Create(
+			context.TODO(),
+			&authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: namespace,
+						Name:      name,
+						Verb:      "get",
+						Version:   "v1",
+						Resource:  "secrets",
+					},
+					User:   user,
+					Groups: groups,
+				},
+			},
+			metav1.CreateOptions{},
+		)
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"namespace": namespace,
+				"name":      name,
+				"user":      user,
+			}).Error("Failed to check get secret")
+			return false, err
+		}
+		if !review.Status.Allowed {
+			return false, nil
+		}
+	}
+	return true, nil
+}
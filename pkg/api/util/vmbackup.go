@@ -0,0 +1,78 @@
+package util
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func CanListVMBackups(clientSet kubernetes.Interface, namespace string, userInfo user.Info) (bool, error) {
+	allowed, err := canAccess(clientSet, userInfo, authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      "list",
+		Group:     v1beta1.SchemeGroupVersion.Group,
+		Version:   v1beta1.SchemeGroupVersion.Version,
+		Resource:  v1beta1.VirtualMachineBackupResourceName,
+	})
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"namespace": namespace,
+			"user":      userInfo.GetName(),
+		}).Error("Failed to check list vm backups")
+		return false, err
+	}
+	return allowed, nil
+}
+
+// CanGetVM checks whether the user is allowed to get the VM, e.g. before telling whether it exists
+func CanGetVM(clientSet kubernetes.Interface, namespace, name string, userInfo user.Info) (bool, error) {
+	allowed, err := canAccess(clientSet, userInfo, authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Name:      name,
+		Verb:      "get",
+		Group:     kubevirtv1.SchemeGroupVersion.Group,
+		Version:   kubevirtv1.SchemeGroupVersion.Version,
+		Resource:  "virtualmachines",
+	})
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"namespace": namespace,
+			"name":      name,
+			"user":      userInfo.GetName(),
+		}).Error("Failed to check get vm")
+		return false, err
+	}
+	return allowed, nil
+}
+
+// canAccess reviews the access of the user with its whole identity, the authorizers may grant access by the UID or the extra
+func canAccess(clientSet kubernetes.Interface, userInfo user.Info, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(userInfo.GetExtra()))
+	for k, v := range userInfo.GetExtra() {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review, err := clientSet.AuthorizationV1().SubjectAccessReviews().Create(
+		context.TODO(),
+		&authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				ResourceAttributes: &attributes,
+				User:               userInfo.GetName(),
+				Groups:             userInfo.GetGroups(),
+				UID:                userInfo.GetUID(),
+				Extra:              extra,
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,183 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/v3/pkg/schemas/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	validationutil "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	apiutil "github.com/harvester/harvester/pkg/api/util"
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/config"
+)
+
+const (
+	defaultBulkActionConcurrency = 5
+	maxBulkActionConcurrency     = 20
+)
+
+// bulkActions are the VM actions which can run on many VMs at once,
+// the actions writing their output to the response are left out.
+var bulkActions = map[string]bool{
+	startVM:                true,
+	stopVM:                 true,
+	restartVM:              true,
+	softReboot:             true,
+	pauseVM:                true,
+	unpauseVM:              true,
+	forceStopVM:            true,
+	migrate:                true,
+	abortMigration:         true,
+	backupVM:               true,
+	cancelStorageMigration: true,
+}
+
+// bulkAction creates a VirtualMachineBulkAction running a VM action on the selected VMs and returns it
+// right away. The bulk action controller runs the action on the VMs with bounded concurrency and records
+// the progress and the per-VM results in the status.
+func (h *vmActionHandler) bulkAction(user user.Info, input BulkActionInput) (*harvesterv1.VirtualMachineBulkAction, error) {
+	if err := validateBulkActionInput(&input); err != nil {
+		return nil, apierror.NewAPIError(validation.InvalidBodyContent, err.Error())
+	}
+
+	if ok, err := apiutil.CanUpdateVMs(h.clientSet, input.Namespace, user.GetName(), user.GetGroups()); err != nil {
+		return nil, apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to check permission: %v", err))
+	} else if !ok {
+		return nil, apierror.NewAPIError(validation.PermissionDenied, fmt.Sprintf("User does not have permission to update virtual machines in namespace %s", input.Namespace))
+	}
+
+	actionInput := ""
+	if input.Input != nil {
+		data, err := json.Marshal(input.Input)
+		if err != nil {
+			return nil, apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Failed to encode action input: %v", err))
+		}
+		actionInput = string(data)
+	}
+
+	return h.bulkActionClient.Create(&harvesterv1.VirtualMachineBulkAction{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: input.Action + "-",
+			Namespace:    input.Namespace,
+		},
+		Spec: harvesterv1.VirtualMachineBulkActionSpec{
+			Action:      input.Action,
+			Input:       actionInput,
+			Selector:    input.Selector,
+			Names:       input.Names,
+			Concurrency: input.Concurrency,
+		},
+	})
+}
+
+func validateBulkActionInput(input *BulkActionInput) error {
+	if input.Namespace == "" {
+		return fmt.Errorf("parameter `namespace` is required")
+	}
+	if !bulkActions[input.Action] {
+		return fmt.Errorf("action %q can't run on many VMs", input.Action)
+	}
+	if (input.Selector == "") == (len(input.Names) == 0) {
+		return fmt.Errorf("one of `selector` and `names` is required")
+	}
+	if input.Selector != "" {
+		if _, err := labels.Parse(input.Selector); err != nil {
+			return fmt.Errorf("invalid selector %q: %w", input.Selector, err)
+		}
+	}
+	// every VM gets its own backup named after the given name and the VM
+	if input.Action == backupVM {
+		name, _ := input.Input["name"].(string)
+		if name == "" {
+			return fmt.Errorf("backup name is required")
+		}
+		if errs := validationutil.IsDNS1123Label(name); len(errs) != 0 {
+			return fmt.Errorf("invalid backup name %q: %v", name, errs)
+		}
+	}
+	if input.Concurrency <= 0 {
+		input.Concurrency = defaultBulkActionConcurrency
+	}
+	input.Concurrency = min(input.Concurrency, maxBulkActionConcurrency)
+	return nil
+}
+
+// BulkActionRunner runs the VM actions of the VirtualMachineBulkActions for the bulk action controller
+type BulkActionRunner struct {
+	handler *vmActionHandler
+}
+
+func NewBulkActionRunner(management *config.Management, options config.Options) (*BulkActionRunner, error) {
+	handler, err := newVMActionHandler(actionHandlerFactories{
+		virt:          management.VirtFactory,
+		harvester:     management.HarvesterFactory,
+		core:          management.CoreFactory,
+		storage:       management.StorageFactory,
+		cni:           management.CniFactory,
+		cdi:           management.CdiFactory,
+		resourceQuota: management.HarvesterFactory,
+	}, management.ClientSet, management.RestConfig, options.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &BulkActionRunner{handler: handler}, nil
+}
+
+// Run runs the action on one VM through the same path as the VM action API. The permission of the user
+// to run the action is checked by the webhook when the bulk action is created, so only the actions which
+// don't need the user can run here.
+func (r *BulkActionRunner) Run(ctx context.Context, namespace, name, action, actionInput string) harvesterv1.BulkActionResult {
+	return r.handler.runBulkAction(ctx, namespace, name, action, actionInput)
+}
+
+func (h *vmActionHandler) runBulkAction(ctx context.Context, namespace, name, action, actionInput string) harvesterv1.BulkActionResult {
+	result := harvesterv1.BulkActionResult{Name: name}
+
+	if !bulkActions[action] {
+		result.Message = fmt.Sprintf("action %q can't run on many VMs", action)
+		return result
+	}
+
+	if _, err := h.vmCache.Get(namespace, name); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	// every VM gets its own backup, named after the given name and the VM
+	if action == backupVM {
+		var input BackupInput
+		if err := json.Unmarshal([]byte(actionInput), &input); err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		if input.Name == "" {
+			result.Message = "backup name is required"
+			return result
+		}
+		input.Name = fmt.Sprintf("%s-%s", input.Name, name)
+		data, err := json.Marshal(input)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		actionInput = string(data)
+	}
+
+	if actionInput == "" {
+		actionInput = "{}"
+	}
+
+	if err := h.doAction(ctx, nil, nil, action, namespace, name, bytes.NewBufferString(actionInput)); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	result.Succeeded = true
+	return result
+}
@@ -0,0 +1,79 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBulkActionInput(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		input       BulkActionInput
+		concurrency int
+		expectErr   bool
+	}{
+		{
+			name:        "names with default concurrency",
+			input:       BulkActionInput{Namespace: "default", Action: stopVM, Names: []string{"vm1"}},
+			concurrency: defaultBulkActionConcurrency,
+		},
+		{
+			name:        "selector with capped concurrency",
+			input:       BulkActionInput{Namespace: "default", Action: startVM, Selector: "app=web", Concurrency: 100},
+			concurrency: maxBulkActionConcurrency,
+		},
+		{
+			name:      "missing namespace",
+			input:     BulkActionInput{Action: stopVM, Names: []string{"vm1"}},
+			expectErr: true,
+		},
+		{
+			name:      "unsupported action",
+			input:     BulkActionInput{Namespace: "default", Action: findMigratableNodes, Names: []string{"vm1"}},
+			expectErr: true,
+		},
+		{
+			name:      "both selector and names",
+			input:     BulkActionInput{Namespace: "default", Action: stopVM, Selector: "app=web", Names: []string{"vm1"}},
+			expectErr: true,
+		},
+		{
+			name:      "neither selector nor names",
+			input:     BulkActionInput{Namespace: "default", Action: stopVM},
+			expectErr: true,
+		},
+		{
+			name:      "invalid selector",
+			input:     BulkActionInput{Namespace: "default", Action: stopVM, Selector: "app in web"},
+			expectErr: true,
+		},
+		{
+			name:        "backup with a name",
+			input:       BulkActionInput{Namespace: "default", Action: backupVM, Selector: "app=web", Input: map[string]interface{}{"name": "nightly"}},
+			concurrency: defaultBulkActionConcurrency,
+		},
+		{
+			name:      "backup without a name",
+			input:     BulkActionInput{Namespace: "default", Action: backupVM, Selector: "app=web"},
+			expectErr: true,
+		},
+		{
+			name:      "backup with an invalid name",
+			input:     BulkActionInput{Namespace: "default", Action: backupVM, Selector: "app=web", Input: map[string]interface{}{"name": "Nightly_Backup"}},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateBulkActionInput(&tc.input)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.concurrency, tc.input.Concurrency)
+		})
+	}
+}
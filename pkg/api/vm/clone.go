@@ -0,0 +1,176 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	validationutil "k8s.io/apimachinery/pkg/util/validation"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+const (
+	maxCloneCount = 100
+
+	cloudInitUserDataKey    = "userdata"
+	cloudInitUserDataKeyAlt = "userData"
+)
+
+// cloneTemplateData is the data of the name, hostname and user data templates of a clone
+type cloneTemplateData struct {
+	Index      int
+	Name       string
+	Namespace  string
+	SourceName string
+}
+
+// cloneTarget is a clone to create, with its templates rendered
+type cloneTarget struct {
+	Index     int
+	Name      string
+	Namespace string
+	Hostname  string
+	UserData  string
+}
+
+// getCloneTargets renders the name, hostname and user data of each clone
+func getCloneTargets(input CloneInput, sourceVM *kubevirtv1.VirtualMachine) ([]cloneTarget, error) {
+	count := max(input.Count, 1)
+	if count > maxCloneCount {
+		return nil, fmt.Errorf("count %d exceeds the maximum %d", count, maxCloneCount)
+	}
+
+	namespace := input.TargetNamespace
+	if namespace == "" {
+		namespace = sourceVM.Namespace
+	}
+
+	namePattern := input.TargetVM
+	if count > 1 && !strings.Contains(namePattern, "{{") {
+		namePattern += "-{{ .Index }}"
+	}
+	hostnamePattern := input.Hostname
+	if hostnamePattern == "" {
+		hostnamePattern = "{{ .Name }}"
+	}
+
+	var targets []cloneTarget
+	seen := map[string]bool{}
+	for i := 0; i < count; i++ {
+		data := cloneTemplateData{
+			Index:      input.StartIndex + i,
+			Namespace:  namespace,
+			SourceName: sourceVM.Name,
+		}
+
+		name, err := renderCloneTemplate("targetVm", namePattern, data)
+		if err != nil {
+			return nil, err
+		}
+		if errs := validationutil.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid clone name %q: %s", name, strings.Join(errs, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("clone name %q is duplicated, the name pattern should reference {{ .Index }}", name)
+		}
+		seen[name] = true
+		data.Name = name
+
+		hostname, err := renderCloneTemplate("hostname", hostnamePattern, data)
+		if err != nil {
+			return nil, err
+		}
+		if errs := validationutil.IsDNS1123Label(hostname); input.Hostname != "" && len(errs) > 0 {
+			return nil, fmt.Errorf("invalid clone hostname %q: %s", hostname, strings.Join(errs, ", "))
+		}
+
+		var userData string
+		if input.UserData != "" {
+			if userData, err = renderCloneTemplate("userData", input.UserData, data); err != nil {
+				return nil, err
+			}
+		}
+
+		targets = append(targets, cloneTarget{
+			Index:     data.Index,
+			Name:      name,
+			Namespace: namespace,
+			Hostname:  hostname,
+			UserData:  userData,
+		})
+	}
+	return targets, nil
+}
+
+func renderCloneTemplate(field, text string, data cloneTemplateData) (string, error) {
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", field, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", field, err)
+	}
+	return buf.String(), nil
+}
+
+// getCloneDataVolume returns a DataVolume copying the PVC to another namespace or storage class
+func getCloneDataVolume(pvc *corev1.PersistentVolumeClaim, namespace, name, storageClassName string) cdiv1.DataVolume {
+	if storageClassName == "" {
+		storageClassName = *pvc.Spec.StorageClassName
+	}
+
+	return cdiv1.DataVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: cdiv1.DataVolumeSpec{
+			Source: &cdiv1.DataVolumeSource{
+				PVC: &cdiv1.DataVolumeSourcePVC{
+					Name:      pvc.Name,
+					Namespace: pvc.Namespace,
+				},
+			},
+			Storage: &cdiv1.StorageSpec{
+				AccessModes:      pvc.Spec.AccessModes,
+				VolumeMode:       pvc.Spec.VolumeMode,
+				StorageClassName: &storageClassName,
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: pvc.Spec.Resources.Requests,
+				},
+			},
+		},
+	}
+}
+
+// isUserDataSecret returns true if the secret is referenced as cloud-init user data by the VM
+func isUserDataSecret(vm *kubevirtv1.VirtualMachine, secretName string) bool {
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.CloudInitNoCloud != nil && volume.CloudInitNoCloud.UserDataSecretRef != nil &&
+			volume.CloudInitNoCloud.UserDataSecretRef.Name == secretName {
+			return true
+		}
+	}
+	return false
+}
+
+// withUserData returns a copy of the secret data with the user data replaced
+func withUserData(data map[string][]byte, userData string) map[string][]byte {
+	newData := make(map[string][]byte, len(data)+1)
+	for k, v := range data {
+		newData[k] = v
+	}
+
+	key := cloudInitUserDataKey
+	if _, ok := data[cloudInitUserDataKeyAlt]; ok {
+		key = cloudInitUserDataKeyAlt
+	}
+	newData[key] = []byte(userData)
+	return newData
+}
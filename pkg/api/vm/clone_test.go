@@ -0,0 +1,198 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func TestGetCloneTargets(t *testing.T) {
+	sourceVM := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "golden"},
+	}
+
+	var testCases = []struct {
+		name      string
+		input     CloneInput
+		expected  []cloneTarget
+		expectErr bool
+	}{
+		{
+			name:  "single clone",
+			input: CloneInput{TargetVM: "copy"},
+			expected: []cloneTarget{
+				{Index: 0, Name: "copy", Namespace: "default", Hostname: "copy"},
+			},
+		},
+		{
+			name:  "index appended to the name",
+			input: CloneInput{TargetVM: "lab", Count: 2, StartIndex: 1, TargetNamespace: "labs"},
+			expected: []cloneTarget{
+				{Index: 1, Name: "lab-1", Namespace: "labs", Hostname: "lab-1"},
+				{Index: 2, Name: "lab-2", Namespace: "labs", Hostname: "lab-2"},
+			},
+		},
+		{
+			name: "templated name, hostname and user data",
+			input: CloneInput{
+				TargetVM: "{{ .SourceName }}-{{ .Index }}",
+				Count:    2,
+				Hostname: "host{{ .Index }}",
+				UserData: "#cloud-config\nhostname: {{ .Name }}\n",
+			},
+			expected: []cloneTarget{
+				{Index: 0, Name: "golden-0", Namespace: "default", Hostname: "host0", UserData: "#cloud-config\nhostname: golden-0\n"},
+				{Index: 1, Name: "golden-1", Namespace: "default", Hostname: "host1", UserData: "#cloud-config\nhostname: golden-1\n"},
+			},
+		},
+		{
+			name:      "duplicated names",
+			input:     CloneInput{TargetVM: "lab-{{ .SourceName }}", Count: 2},
+			expectErr: true,
+		},
+		{
+			name:      "invalid name",
+			input:     CloneInput{TargetVM: "Lab_{{ .Index }}", Count: 2},
+			expectErr: true,
+		},
+		{
+			name:      "invalid template",
+			input:     CloneInput{TargetVM: "lab", UserData: "{{ .Unknown }}"},
+			expectErr: true,
+		},
+		{
+			name:      "too many clones",
+			input:     CloneInput{TargetVM: "lab", Count: maxCloneCount + 1},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			targets, err := getCloneTargets(tc.input, sourceVM)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, targets)
+		})
+	}
+}
+
+func TestWithUserData(t *testing.T) {
+	data := map[string][]byte{"userData": []byte("old"), "networkdata": []byte("net")}
+	newData := withUserData(data, "new")
+	assert.Equal(t, map[string][]byte{"userData": []byte("new"), "networkdata": []byte("net")}, newData)
+	assert.Equal(t, []byte("old"), data["userData"])
+
+	assert.Equal(t, map[string][]byte{"userdata": []byte("new")}, withUserData(nil, "new"))
+}
+
+func TestCloneVM(t *testing.T) {
+	sourceVM := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "golden"},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}},
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Volumes: []kubevirtv1.Volume{
+						{
+							Name:         "disk",
+							VolumeSource: kubevirtv1.VolumeSource{ContainerDisk: &kubevirtv1.ContainerDiskSource{Image: "image"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		allowed     map[string]bool
+		failOn      string
+		expectErr   bool
+		expectVMs   []string
+		notExpected []string
+	}{
+		{
+			name:      "clone to the namespace with permissions",
+			allowed:   map[string]bool{"virtualmachines": true, "persistentvolumeclaims": true, "secrets": true},
+			expectVMs: []string{"lab-0", "lab-1", "lab-2"},
+		},
+		{
+			name:        "no permission to create PVCs in the target namespace",
+			allowed:     map[string]bool{"virtualmachines": true, "secrets": true},
+			expectErr:   true,
+			notExpected: []string{"lab-0", "lab-1", "lab-2"},
+		},
+		{
+			name:        "created clones are rolled back if a clone fails",
+			allowed:     map[string]bool{"virtualmachines": true, "persistentvolumeclaims": true, "secrets": true},
+			failOn:      "lab-1",
+			expectErr:   true,
+			notExpected: []string{"lab-0", "lab-1", "lab-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(sourceVM)
+			clientset.PrependReactor("create", "virtualmachines", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				vm := action.(k8stesting.CreateAction).GetObject().(*kubevirtv1.VirtualMachine)
+				if vm.Name == tc.failOn {
+					return true, nil, errors.New("injected error")
+				}
+				return false, nil, nil
+			})
+			coreclientset := corefake.NewSimpleClientset()
+			coreclientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				assert.Equal(t, "team-a", review.Spec.ResourceAttributes.Namespace)
+				assert.Equal(t, "alice", review.Spec.User)
+				review.Status.Allowed = tc.allowed[review.Spec.ResourceAttributes.Resource]
+				return true, review, nil
+			})
+
+			h := &vmActionHandler{
+				clientSet: coreclientset,
+				vmCache:   fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+				vmClient:  fakeclients.VirtualMachineClient(clientset.KubevirtV1().VirtualMachines),
+			}
+			err := h.cloneVM(&user.DefaultInfo{Name: "alice"}, sourceVM.Name, sourceVM.Namespace, CloneInput{
+				TargetVM:        "lab-{{ .Index }}",
+				TargetNamespace: "team-a",
+				Count:           3,
+			})
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			vms := clientset.KubevirtV1().VirtualMachines("team-a")
+			for _, name := range tc.expectVMs {
+				_, err := vms.Get(context.TODO(), name, metav1.GetOptions{})
+				assert.NoError(t, err, name)
+			}
+			for _, name := range tc.notExpected {
+				_, err := vms.Get(context.TODO(), name, metav1.GetOptions{})
+				assert.True(t, apierrors.IsNotFound(err), name)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/v3/pkg/schemas/validation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/user"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	apiutil "github.com/harvester/harvester/pkg/api/util"
+	"github.com/harvester/harvester/pkg/util/virtualmachineinstance"
+)
+
+const (
+	maxSerialConsoleLogKB = 1024
+)
+
+// doLink serves the links of a VM, which return the diagnostics of the guest, the right-sizing recommendations
+// and the package of the exported VM
+func (h *vmActionHandler) doLink(ctx context.Context, rw http.ResponseWriter, r *http.Request, user user.Info, link, namespace, name string) error {
+	switch link {
+	case screenshotLink:
+		return h.screenshot(ctx, rw, user, namespace, name)
+	case serialConsoleLogLink:
+		kb := virtualmachineinstance.DefaultSerialConsoleLogBytes / 1024
+		if value := r.URL.Query().Get("kb"); value != "" {
+			var err error
+			if kb, err = strconv.Atoi(value); err != nil || kb <= 0 || kb > maxSerialConsoleLogKB {
+				return apierror.NewAPIError(validation.InvalidOption, fmt.Sprintf("kb must be an integer between 1 and %d", maxSerialConsoleLogKB))
+			}
+		}
+		return h.serialConsoleLog(ctx, rw, user, namespace, name, kb)
+	case recommendationsLink:
+		return h.recommendations(ctx, rw, namespace, name)
+	case downloadExportLink:
+		return h.downloadExport(ctx, rw, user, namespace, name)
+	default:
+		return apierror.NewAPIError(validation.InvalidAction, fmt.Sprintf("Unsupported GET action %s", link))
+	}
+}
+
+// screenshot writes the PNG of the current framebuffer of the VM
+func (h *vmActionHandler) screenshot(ctx context.Context, rw http.ResponseWriter, user user.Info, namespace, name string) error {
+	if _, err := h.getRunningVMIForConsole(user, namespace, name, "vnc"); err != nil {
+		return err
+	}
+
+	png, err := virtualmachineinstance.Screenshot(ctx, h.virtSubresourceRestClient, namespace, name)
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to take the screenshot of VM %s/%s: %v", namespace, name, err))
+	}
+
+	rw.Header().Set("Content-Type", "image/png")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%s-%s.png", namespace, name))
+	rw.WriteHeader(http.StatusOK)
+	_, err = rw.Write(png)
+	return err
+}
+
+// serialConsoleLog writes the last kb KB of the guest serial console log of the VM
+func (h *vmActionHandler) serialConsoleLog(ctx context.Context, rw http.ResponseWriter, user user.Info, namespace, name string, kb int) error {
+	vmi, err := h.getRunningVMIForConsole(user, namespace, name, "console")
+	if err != nil {
+		return err
+	}
+
+	pod, err := virtualmachineinstance.GetLauncherPod(vmi, h.podCache)
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, err.Error())
+	}
+	log, err := virtualmachineinstance.SerialConsoleLog(ctx, h.clientSet, pod, kb*1024)
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get the serial console log of VM %s/%s: %v", namespace, name, err))
+	}
+
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+	_, err = rw.Write(log)
+	return err
+}
+
+// getRunningVMIForConsole checks the user can access the guest through the VMI subresource, since the links are
+// served with the privilege of Harvester, and returns the VMI if it's running.
+func (h *vmActionHandler) getRunningVMIForConsole(user user.Info, namespace, name, subresource string) (*kubevirtv1.VirtualMachineInstance, error) {
+	if ok, err := apiutil.CanGetVMISubresource(h.clientSet, namespace, name, subresource, user.GetName(), user.GetGroups()); err != nil {
+		return nil, apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to check permission: %v", err))
+	} else if !ok {
+		return nil, apierror.NewAPIError(validation.PermissionDenied,
+			fmt.Sprintf("User does not have permission to get the %s of virtual machine %s/%s", subresource, namespace, name))
+	}
+
+	vmi, err := h.vmiCache.Get(namespace, name)
+	if apierrors.IsNotFound(err) {
+		return nil, apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("VM %s/%s is not running", namespace, name))
+	} else if err != nil {
+		return nil, apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get virtual machine instance %s/%s: %v", namespace, name, err))
+	}
+	if vmi.Status.Phase != kubevirtv1.Running {
+		return nil, apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("VM %s/%s is not running", namespace, name))
+	}
+	return vmi, nil
+}
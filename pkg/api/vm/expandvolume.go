@@ -0,0 +1,161 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/v3/pkg/schemas/validation"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// expandVolume expands the PVC of the VM volume. The PVC webhook validates the expansion as well, the checks here
+// return the errors of the common cases before the PVC is touched. The progress of the expansion is tracked by the
+// PVC controller in the volumeExpansion annotation of the PVC.
+func (h *vmActionHandler) expandVolume(namespace, name string, input ExpandVolumeInput) error {
+	size, err := resource.ParseQuantity(input.Size)
+	if err != nil {
+		return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Invalid size %s: %v", input.Size, err))
+	}
+
+	vm, err := h.vmCache.Get(namespace, name)
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get virtual machine %s/%s: %v", namespace, name, err))
+	}
+
+	claimName := getVolumeClaimName(vm, input.DiskName)
+	if claimName == "" {
+		return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Disk %s of virtual machine %s/%s is not backed by a PVC", input.DiskName, namespace, name))
+	}
+
+	pvc, err := h.pvcCache.Get(namespace, claimName)
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get PVC %s/%s: %v", namespace, claimName, err))
+	}
+
+	expansion, err := util.UnmarshalVolumeExpansion(pvc.Annotations[util.AnnotationVolumeExpansion])
+	if err == nil && expansion != nil && !expansion.IsFinished() {
+		return apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("Volume %s is being expanded to %s", input.DiskName, expansion.Size))
+	}
+
+	if current := pvc.Spec.Resources.Requests.Storage(); size.Cmp(*current) <= 0 {
+		return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Size must be larger than the current size %s", current.String()))
+	}
+
+	if err := h.checkVolumeExpandable(vm, pvc); err != nil {
+		return err
+	}
+
+	annotation, err := util.MarshalVolumeExpansion(&util.VolumeExpansion{
+		Size:   size.String(),
+		VM:     name,
+		Volume: input.DiskName,
+		Phase:  util.VolumeExpansionPending,
+	})
+	if err != nil {
+		return err
+	}
+
+	pvcCopy := pvc.DeepCopy()
+	if pvcCopy.Spec.Resources.Requests == nil {
+		pvcCopy.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	pvcCopy.Spec.Resources.Requests[corev1.ResourceStorage] = size
+	if pvcCopy.Annotations == nil {
+		pvcCopy.Annotations = map[string]string{}
+	}
+	pvcCopy.Annotations[util.AnnotationVolumeExpansion] = annotation
+	if _, err := h.pvcClient.Update(pvcCopy); err != nil {
+		if strings.Contains(err.Error(), util.PVCExpandErrorPrefix) {
+			return apierror.NewAPIError(validation.InvalidAction, err.Error())
+		}
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to expand PVC %s/%s: %v", namespace, claimName, err))
+	}
+
+	return h.updateVolumeClaimTemplateSize(vm, claimName, size)
+}
+
+// checkVolumeExpandable checks the storage class allows the expansion, and the provisioner supports the online
+// expansion according to the csi-online-expand-validation setting if the VM isn't stopped.
+func (h *vmActionHandler) checkVolumeExpandable(vm *kubevirtv1.VirtualMachine, pvc *corev1.PersistentVolumeClaim) error {
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+		sc, err := h.storageClassCache.Get(*pvc.Spec.StorageClassName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get storage class %s: %v", *pvc.Spec.StorageClassName, err))
+		}
+		if sc != nil && (sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion) {
+			return apierror.NewAPIError(validation.InvalidAction, fmt.Sprintf("Storage class %s doesn't allow volume expansion", sc.Name))
+		}
+	}
+
+	if vm.Status.PrintableStatus == kubevirtv1.VirtualMachineStatusStopped {
+		return nil
+	}
+
+	provisioner := util.GetProvisionedPVCProvisioner(pvc, h.storageClassCache)
+	if provisioner == "" {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to determine the provisioner of PVC %s/%s", pvc.Namespace, pvc.Name))
+	}
+	expandable, err := util.GetCSIOnlineExpandValidation(provisioner, h.settingCache)
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, err.Error())
+	}
+	if !expandable {
+		return apierror.NewAPIError(validation.InvalidAction,
+			fmt.Sprintf("Provisioner %s doesn't support online expansion, stop virtual machine %s/%s to expand the volume", provisioner, vm.Namespace, vm.Name))
+	}
+	return nil
+}
+
+// updateVolumeClaimTemplateSize keeps the size in the volumeClaimTemplates annotation of the VM in sync with the PVC
+func (h *vmActionHandler) updateVolumeClaimTemplateSize(vm *kubevirtv1.VirtualMachine, claimName string, size resource.Quantity) error {
+	entries, err := util.UnmarshalVolumeClaimTemplates(vm.Annotations[util.AnnotationVolumeClaimTemplates])
+	if err != nil {
+		return err
+	}
+
+	updated := false
+	for i := range entries {
+		if entries[i].Name != claimName || entries[i].Spec.Resources.Requests.Storage().Cmp(size) >= 0 {
+			continue
+		}
+		if entries[i].Spec.Resources.Requests == nil {
+			entries[i].Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		entries[i].Spec.Resources.Requests[corev1.ResourceStorage] = size
+		updated = true
+	}
+	if !updated {
+		return nil
+	}
+
+	data, err := util.MarshalVolumeClaimTemplates(entries)
+	if err != nil {
+		return err
+	}
+	vmCopy := vm.DeepCopy()
+	vmCopy.Annotations[util.AnnotationVolumeClaimTemplates] = data
+	_, err = h.vmClient.Update(vmCopy)
+	return err
+}
+
+// getVolumeClaimName returns the name of the PVC backing the VM volume, it's empty if the volume isn't backed by a PVC
+func getVolumeClaimName(vm *kubevirtv1.VirtualMachine, volumeName string) string {
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.Name != volumeName {
+			continue
+		}
+		if volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName
+		}
+		if volume.DataVolume != nil {
+			return volume.DataVolume.Name
+		}
+	}
+	return ""
+}
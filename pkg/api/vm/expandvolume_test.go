@@ -0,0 +1,179 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func TestExpandVolume(t *testing.T) {
+	const (
+		namespace = "default"
+		name      = "vm"
+	)
+	newVM := func(status kubevirtv1.VirtualMachinePrintableStatus) *kubevirtv1.VirtualMachine {
+		return &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineInstanceSpec{
+						Volumes: []kubevirtv1.Volume{
+							{
+								Name: "disk-0",
+								VolumeSource: kubevirtv1.VolumeSource{
+									PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+										PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{ClaimName: "vm-disk-0"},
+									},
+								},
+							},
+							{
+								Name: "cloudinitdisk",
+								VolumeSource: kubevirtv1.VolumeSource{
+									CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{},
+								},
+							},
+						},
+					},
+				},
+			},
+			Status: kubevirtv1.VirtualMachineStatus{PrintableStatus: status},
+		}
+	}
+	newPVC := func(annotations map[string]string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        "vm-disk-0",
+				Annotations: annotations,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: ptr.To("sc"),
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+		}
+	}
+	newSC := func(provisioner string, allowExpansion bool) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: "sc"},
+			Provisioner:          provisioner,
+			AllowVolumeExpansion: ptr.To(allowExpansion),
+		}
+	}
+	setting := &harvesterv1.Setting{
+		ObjectMeta: metav1.ObjectMeta{Name: settings.CSIOnlineExpandValidationSettingName},
+		Default:    `{"driver.longhorn.io":true}`,
+	}
+	expanding, _ := util.MarshalVolumeExpansion(&util.VolumeExpansion{Size: "20Gi", VM: name, Volume: "disk-0", Phase: util.VolumeExpansionExpandingVolume})
+
+	tests := []struct {
+		name        string
+		input       ExpandVolumeInput
+		vm          *kubevirtv1.VirtualMachine
+		pvc         *corev1.PersistentVolumeClaim
+		sc          *storagev1.StorageClass
+		expectError bool
+	}{
+		{
+			name:  "expand the volume of a running VM",
+			input: ExpandVolumeInput{DiskName: "disk-0", Size: "20Gi"},
+			vm:    newVM(kubevirtv1.VirtualMachineStatusRunning),
+			pvc:   newPVC(nil),
+			sc:    newSC("driver.longhorn.io", true),
+		},
+		{
+			name:        "invalid size",
+			input:       ExpandVolumeInput{DiskName: "disk-0", Size: "20G!"},
+			vm:          newVM(kubevirtv1.VirtualMachineStatusRunning),
+			pvc:         newPVC(nil),
+			sc:          newSC("driver.longhorn.io", true),
+			expectError: true,
+		},
+		{
+			name:        "size isn't larger",
+			input:       ExpandVolumeInput{DiskName: "disk-0", Size: "10Gi"},
+			vm:          newVM(kubevirtv1.VirtualMachineStatusRunning),
+			pvc:         newPVC(nil),
+			sc:          newSC("driver.longhorn.io", true),
+			expectError: true,
+		},
+		{
+			name:        "volume isn't backed by a PVC",
+			input:       ExpandVolumeInput{DiskName: "cloudinitdisk", Size: "20Gi"},
+			vm:          newVM(kubevirtv1.VirtualMachineStatusRunning),
+			pvc:         newPVC(nil),
+			sc:          newSC("driver.longhorn.io", true),
+			expectError: true,
+		},
+		{
+			name:        "volume is being expanded",
+			input:       ExpandVolumeInput{DiskName: "disk-0", Size: "30Gi"},
+			vm:          newVM(kubevirtv1.VirtualMachineStatusRunning),
+			pvc:         newPVC(map[string]string{util.AnnotationVolumeExpansion: expanding}),
+			sc:          newSC("driver.longhorn.io", true),
+			expectError: true,
+		},
+		{
+			name:        "storage class doesn't allow expansion",
+			input:       ExpandVolumeInput{DiskName: "disk-0", Size: "20Gi"},
+			vm:          newVM(kubevirtv1.VirtualMachineStatusStopped),
+			pvc:         newPVC(nil),
+			sc:          newSC("driver.longhorn.io", false),
+			expectError: true,
+		},
+		{
+			name:        "provisioner doesn't support online expansion",
+			input:       ExpandVolumeInput{DiskName: "disk-0", Size: "20Gi"},
+			vm:          newVM(kubevirtv1.VirtualMachineStatusRunning),
+			pvc:         newPVC(nil),
+			sc:          newSC("other.csi.io", true),
+			expectError: true,
+		},
+		{
+			name:  "provisioner without online expansion expands the volume of a stopped VM",
+			input: ExpandVolumeInput{DiskName: "disk-0", Size: "20Gi"},
+			vm:    newVM(kubevirtv1.VirtualMachineStatusStopped),
+			pvc:   newPVC(nil),
+			sc:    newSC("other.csi.io", true),
+		},
+	}
+
+	for _, tc := range tests {
+		clientset := fake.NewSimpleClientset(tc.vm, tc.pvc, tc.sc, setting)
+		h := &vmActionHandler{
+			pvcClient:         fakeclients.PersistentVolumeClaimClient(clientset.CoreV1().PersistentVolumeClaims),
+			vmClient:          fakeclients.VirtualMachineClient(clientset.KubevirtV1().VirtualMachines),
+			pvcCache:          fakeclients.PersistentVolumeClaimCache(clientset.CoreV1().PersistentVolumeClaims),
+			settingCache:      fakeclients.HarvesterSettingCache(clientset.HarvesterhciV1beta1().Settings),
+			storageClassCache: fakeclients.StorageClassCache(clientset.StorageV1().StorageClasses),
+			vmCache:           fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+		}
+
+		err := h.expandVolume(namespace, name, tc.input)
+		if tc.expectError {
+			assert.Error(t, err, tc.name)
+			continue
+		}
+		assert.NoError(t, err, tc.name)
+
+		pvc, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(t.Context(), "vm-disk-0", metav1.GetOptions{})
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.input.Size, pvc.Spec.Resources.Requests.Storage().String(), tc.name)
+		expansion, err := util.UnmarshalVolumeExpansion(pvc.Annotations[util.AnnotationVolumeExpansion])
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, util.VolumeExpansionPending, expansion.Phase, tc.name)
+	}
+}
@@ -0,0 +1,149 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/v3/pkg/schemas/validation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	apiutil "github.com/harvester/harvester/pkg/api/util"
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+// exportVM creates the VM downloader which packages the stopped VM, it's named after the VM so there is
+// at most one export of a VM at a time. The package is downloaded through the downloadExport link until
+// the downloader expires.
+func (h *vmActionHandler) exportVM(user user.Info, namespace, name string, input ExportVMInput) error {
+	vm, err := h.vmCache.Get(namespace, name)
+	if err != nil {
+		return apierror.NewAPIError(validation.NotFound, fmt.Sprintf("Failed to get virtual machine %s/%s: %v", namespace, name, err))
+	}
+	if err := h.checkExportPermission(user, vm); err != nil {
+		return err
+	}
+	if !canExportVM(vm, h.getVMIForExport(namespace, name)) {
+		return apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("VM %s/%s must be stopped before exporting", namespace, name))
+	}
+
+	format := input.Format
+	if format == "" {
+		format = harvesterv1.VMDownloaderFormatOVA
+	}
+	if format != harvesterv1.VMDownloaderFormatOVA {
+		return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Unsupported format %s", format))
+	}
+
+	downloader := &harvesterv1.VirtualMachineDownloader{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: harvesterv1.VirtualMachineDownloaderSpec{
+			VMName: name,
+			Format: format,
+		},
+	}
+	if _, err := h.vmDownloaderClient.Create(downloader); apierrors.IsAlreadyExists(err) {
+		return apierror.NewAPIError(validation.Conflict, fmt.Sprintf("VM %s/%s is being exported", namespace, name))
+	} else if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to create the downloader of VM %s/%s: %v", namespace, name, err))
+	}
+	return nil
+}
+
+func (h *vmActionHandler) getVMIForExport(namespace, name string) *kubevirtv1.VirtualMachineInstance {
+	vmi, err := h.vmiCache.Get(namespace, name)
+	if err != nil {
+		return nil
+	}
+	return vmi
+}
+
+// checkExportPermission checks the user can get the cloud-init secrets of the VM, since they're packaged with the VM
+func (h *vmActionHandler) checkExportPermission(user user.Info, vm *kubevirtv1.VirtualMachine) error {
+	if ok, err := apiutil.CanGetSecrets(h.clientSet, vm.Namespace, getCloudInitSecretNames(vm), user.GetName(), user.GetGroups()); err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to check permission: %v", err))
+	} else if !ok {
+		return apierror.NewAPIError(validation.PermissionDenied, fmt.Sprintf("User does not have permission to get the cloud-init secrets of VM %s/%s", vm.Namespace, vm.Name))
+	}
+	return nil
+}
+
+// downloadExport streams the package of the exported VM, the package can be downloaded repeatedly until
+// the downloader expires and is removed by the controller
+func (h *vmActionHandler) downloadExport(ctx context.Context, rw http.ResponseWriter, user user.Info, namespace, name string) error {
+	downloader, err := h.vmDownloaderClient.Get(namespace, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return apierror.NewAPIError(validation.NotFound, fmt.Sprintf("VM %s/%s is not exported", namespace, name))
+	} else if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get the downloader of VM %s/%s: %v", namespace, name, err))
+	}
+	if downloader.Status.Status != harvesterv1.ImageDownloaderStatusReady {
+		return apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("The export of VM %s/%s is not ready", namespace, name))
+	}
+
+	vm, err := h.vmCache.Get(namespace, downloader.Spec.VMName)
+	if err != nil {
+		return apierror.NewAPIError(validation.NotFound, fmt.Sprintf("Failed to get virtual machine %s/%s: %v", namespace, downloader.Spec.VMName, err))
+	}
+	if err := h.checkExportPermission(user, vm); err != nil {
+		return err
+	}
+
+	downloadReq, err := http.NewRequestWithContext(ctx, http.MethodGet, downloader.Status.DownloadURL, nil)
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to create the download request of VM %s/%s: %v", namespace, name, err))
+	}
+	downloadResp, err := h.httpClient.Do(downloadReq)
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to download the package of VM %s/%s: %v", namespace, name, err))
+	}
+	defer downloadResp.Body.Close()
+	if downloadResp.StatusCode != http.StatusOK {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to download the package of VM %s/%s: %s", namespace, name, downloadResp.Status))
+	}
+
+	rw.Header().Set("Content-Disposition", "attachment; filename="+downloader.Status.FileName)
+	rw.Header().Set("Content-Type", "application/x-tar")
+	if downloadResp.ContentLength > 0 {
+		rw.Header().Set("Content-Length", fmt.Sprint(downloadResp.ContentLength))
+	}
+	rw.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(rw, downloadResp.Body); err != nil {
+		return fmt.Errorf("failed to copy the package of VM %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// getCloudInitSecretNames returns the cloud-init secrets the export packages
+func getCloudInitSecretNames(vm *kubevirtv1.VirtualMachine) []string {
+	if vm.Spec.Template == nil {
+		return nil
+	}
+
+	var names []string
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		cloudInit := volume.CloudInitNoCloud
+		if cloudInit == nil {
+			continue
+		}
+		if cloudInit.UserDataSecretRef != nil {
+			names = append(names, cloudInit.UserDataSecretRef.Name)
+		}
+		if cloudInit.NetworkDataSecretRef != nil && (cloudInit.UserDataSecretRef == nil || cloudInit.NetworkDataSecretRef.Name != cloudInit.UserDataSecretRef.Name) {
+			names = append(names, cloudInit.NetworkDataSecretRef.Name)
+		}
+	}
+	return names
+}
+
+func canExportVM(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1.VirtualMachineInstance) bool {
+	return vm.Status.PrintableStatus == kubevirtv1.VirtualMachineStatusStopped && (vmi == nil || vmi.IsFinal())
+}
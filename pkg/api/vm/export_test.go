@@ -0,0 +1,214 @@
+package vm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func TestExportVM(t *testing.T) {
+	const (
+		namespace = "default"
+		name      = "vm"
+	)
+	newVM := func(status kubevirtv1.VirtualMachinePrintableStatus) *kubevirtv1.VirtualMachine {
+		return &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Status:     kubevirtv1.VirtualMachineStatus{PrintableStatus: status},
+		}
+	}
+	withCloudInitSecret := func(vm *kubevirtv1.VirtualMachine, secretName string) *kubevirtv1.VirtualMachine {
+		vm.Spec.Template = newCloudInitTemplate(secretName)
+		return vm
+	}
+	exporting := &harvesterv1.VirtualMachineDownloader{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       harvesterv1.VirtualMachineDownloaderSpec{VMName: name, Format: harvesterv1.VMDownloaderFormatOVA},
+	}
+
+	tests := []struct {
+		name        string
+		input       ExportVMInput
+		vm          *kubevirtv1.VirtualMachine
+		downloader  *harvesterv1.VirtualMachineDownloader
+		expectError bool
+	}{
+		{
+			name: "export a stopped VM with the default format",
+			vm:   newVM(kubevirtv1.VirtualMachineStatusStopped),
+		},
+		{
+			name:  "export a stopped VM to ova",
+			input: ExportVMInput{Format: harvesterv1.VMDownloaderFormatOVA},
+			vm:    newVM(kubevirtv1.VirtualMachineStatusStopped),
+		},
+		{
+			name:        "VM is running",
+			vm:          newVM(kubevirtv1.VirtualMachineStatusRunning),
+			expectError: true,
+		},
+		{
+			name:        "unsupported format",
+			input:       ExportVMInput{Format: "vmdk"},
+			vm:          newVM(kubevirtv1.VirtualMachineStatusStopped),
+			expectError: true,
+		},
+		{
+			name: "user can get the cloud-init secret",
+			vm:   withCloudInitSecret(newVM(kubevirtv1.VirtualMachineStatusStopped), "vm-cloud-init"),
+		},
+		{
+			name:        "user can't get the cloud-init secret",
+			vm:          withCloudInitSecret(newVM(kubevirtv1.VirtualMachineStatusStopped), "admin-cloud-init"),
+			expectError: true,
+		},
+		{
+			name:        "VM is being exported",
+			vm:          newVM(kubevirtv1.VirtualMachineStatusStopped),
+			downloader:  exporting,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		clientset := fake.NewSimpleClientset(tc.vm)
+		if tc.downloader != nil {
+			assert.NoError(t, clientset.Tracker().Add(tc.downloader), tc.name)
+		}
+		h := &vmActionHandler{
+			vmCache:            fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+			vmiCache:           fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+			vmDownloaderClient: fakeclients.VirtualMachineDownloaderClient(clientset.HarvesterhciV1beta1().VirtualMachineDownloaders),
+			clientSet:          newSecretAccessClientset(t, "vm-cloud-init"),
+		}
+
+		err := h.exportVM(&user.DefaultInfo{Name: "alice"}, namespace, name, tc.input)
+		if tc.expectError {
+			assert.Error(t, err, tc.name)
+			continue
+		}
+		assert.NoError(t, err, tc.name)
+
+		downloader, err := clientset.HarvesterhciV1beta1().VirtualMachineDownloaders(namespace).Get(t.Context(), name, metav1.GetOptions{})
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, name, downloader.Spec.VMName, tc.name)
+		assert.Equal(t, harvesterv1.VMDownloaderFormatOVA, downloader.Spec.Format, tc.name)
+	}
+}
+
+func TestDownloadExport(t *testing.T) {
+	const (
+		namespace = "default"
+		name      = "vm"
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte("ova"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		secretName     string
+		expectedStatus int
+		expectError    bool
+	}{
+		{
+			name:           "user can get the cloud-init secret",
+			secretName:     "vm-cloud-init",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "user can't get the cloud-init secret",
+			secretName:  "admin-cloud-init",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vm := &kubevirtv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				Spec:       kubevirtv1.VirtualMachineSpec{Template: newCloudInitTemplate(tc.secretName)},
+			}
+			downloader := &harvesterv1.VirtualMachineDownloader{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				Spec:       harvesterv1.VirtualMachineDownloaderSpec{VMName: name, Format: harvesterv1.VMDownloaderFormatOVA},
+				Status: harvesterv1.VirtualMachineDownloaderStatus{
+					Status:      harvesterv1.ImageDownloaderStatusReady,
+					FileName:    name + ".ova",
+					DownloadURL: server.URL + "/vms/" + name + ".ova",
+				},
+			}
+			clientset := fake.NewSimpleClientset(vm, downloader)
+			h := &vmActionHandler{
+				vmCache:            fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+				vmDownloaderClient: fakeclients.VirtualMachineDownloaderClient(clientset.HarvesterhciV1beta1().VirtualMachineDownloaders),
+				clientSet:          newSecretAccessClientset(t, "vm-cloud-init"),
+			}
+
+			rw := httptest.NewRecorder()
+			err := h.downloadExport(t.Context(), rw, &user.DefaultInfo{Name: "alice"}, namespace, name)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, rw.Code)
+			assert.Equal(t, "ova", rw.Body.String())
+
+			// the package can be downloaded again until the downloader expires
+			_, err = clientset.HarvesterhciV1beta1().VirtualMachineDownloaders(namespace).Get(t.Context(), name, metav1.GetOptions{})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func newCloudInitTemplate(secretName string) *kubevirtv1.VirtualMachineInstanceTemplateSpec {
+	return &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+		Spec: kubevirtv1.VirtualMachineInstanceSpec{
+			Volumes: []kubevirtv1.Volume{
+				{
+					Name: "cloudinitdisk",
+					VolumeSource: kubevirtv1.VolumeSource{
+						CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{
+							UserDataSecretRef:    &corev1.LocalObjectReference{Name: secretName},
+							NetworkDataSecretRef: &corev1.LocalObjectReference{Name: secretName},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newSecretAccessClientset returns a clientset whose access reviews only allow to get the secrets
+func newSecretAccessClientset(t *testing.T, secretNames ...string) *corefake.Clientset {
+	clientset := corefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		assert.Equal(t, "alice", review.Spec.User)
+		attributes := review.Spec.ResourceAttributes
+		for _, secretName := range secretNames {
+			if attributes.Verb == "get" && attributes.Resource == "secrets" && attributes.Name == secretName {
+				review.Status.Allowed = true
+			}
+		}
+		return true, review, nil
+	})
+	return clientset
+}
@@ -16,6 +16,7 @@ import (
 	cdicommon "kubevirt.io/containerized-data-importer/pkg/controller/common"
 
 	apiutil "github.com/harvester/harvester/pkg/api/util"
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/controller/master/migration"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
@@ -44,6 +45,7 @@ const (
 	createTemplate                   = "createTemplate"
 	addVolume                        = "addVolume"
 	removeVolume                     = "removeVolume"
+	expandVolume                     = "expandVolume"
 	addNic                           = "addNic"
 	removeNic                        = "removeNic"
 	findHotunpluggableNics           = "findHotunpluggableNics"
@@ -55,6 +57,13 @@ const (
 	cpuAndMemoryHotplug              = "cpuAndMemoryHotplug"
 	storageMigration                 = "storageMigration"
 	cancelStorageMigration           = "cancelStorageMigration"
+	bulkAction                       = "bulkAction"
+	exportVM                         = "exportVM"
+
+	screenshotLink       = "screenshot"
+	serialConsoleLogLink = "serialConsoleLog"
+	recommendationsLink  = "recommendations"
+	downloadExportLink   = "downloadExport"
 )
 
 type vmformatter struct {
@@ -65,9 +74,14 @@ type vmformatter struct {
 	scCache       ctlstoragev1.StorageClassCache
 	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache
 	settingCache  ctlharvesterv1.SettingCache
+	btCache       ctlharvesterv1.BackupTargetCache
 	clientSet     kubernetes.Interface
 }
 
+func (vf *vmformatter) collectionFormatter(request *types.APIRequest, collection *types.GenericCollection) {
+	collection.AddAction(request, bulkAction)
+}
+
 func (vf *vmformatter) formatter(request *types.APIRequest, resource *types.RawResource) {
 	// reset resource actions, because action map already be set when add actions handler,
 	// but current framework can't support use formatter to remove key from action map
@@ -94,6 +108,7 @@ func (vf *vmformatter) formatter(request *types.APIRequest, resource *types.RawR
 
 	resource.AddAction(request, addVolume)
 	resource.AddAction(request, removeVolume)
+	resource.AddAction(request, expandVolume)
 	resource.AddAction(request, cloneVM)
 
 	if canInsertCdRomVolume(vm) {
@@ -179,6 +194,10 @@ func (vf *vmformatter) formatter(request *types.APIRequest, resource *types.RawR
 	if hasActiveStorageMigration(vm) {
 		resource.AddAction(request, cancelStorageMigration)
 	}
+
+	if canExportVM(vm, vmi) {
+		resource.AddAction(request, exportVM)
+	}
 }
 
 func hasActiveStorageMigration(vm *kubevirtv1.VirtualMachine) bool {
@@ -346,6 +365,10 @@ func (vf *vmformatter) canDoBackup(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv
 		}
 	}
 
+	if vf.hasAvailableBackupTarget() {
+		return true
+	}
+
 	backupTargetSetting, err := vf.settingCache.Get(settings.BackupTargetSettingName)
 	if err != nil {
 		logrus.WithError(err).Errorf("Can't get setting %s", settings.BackupTargetSettingName)
@@ -362,6 +385,21 @@ func (vf *vmformatter) canDoBackup(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv
 	return true
 }
 
+// hasAvailableBackupTarget returns true if there is any BackupTarget object available for backup
+func (vf *vmformatter) hasAvailableBackupTarget() bool {
+	bts, err := vf.btCache.List(labels.Everything())
+	if err != nil {
+		logrus.WithError(err).Error("Can't list backup targets")
+		return false
+	}
+	for _, bt := range bts {
+		if bt.DeletionTimestamp == nil && harvesterv1.BackupTargetConditionAvailable.IsTrue(bt) {
+			return true
+		}
+	}
+	return false
+}
+
 func (vf *vmformatter) canDoSnapshot(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1.VirtualMachineInstance) bool {
 	if vm.Status.SnapshotInProgress != nil {
 		return false
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
 	"reflect"
 	"strings"
@@ -28,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/storage/names"
 	"k8s.io/client-go/kubernetes"
@@ -36,6 +39,7 @@ import (
 	k8svolumehelpers "k8s.io/cloud-provider/volume/helpers"
 	"k8s.io/utils/ptr"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	kubevirtmultus "kubevirt.io/kubevirt/pkg/network/multus"
 	kubevirtutil "kubevirt.io/kubevirt/pkg/virt-operator/util"
 
@@ -44,6 +48,7 @@ import (
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/builder"
 	nodecontroller "github.com/harvester/harvester/pkg/controller/master/node"
+	ctlcdiv1 "github.com/harvester/harvester/pkg/generated/controllers/cdi.kubevirt.io/v1beta1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	ctlcniv1 "github.com/harvester/harvester/pkg/generated/controllers/k8s.cni.cncf.io/v1"
 	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
@@ -75,13 +80,17 @@ type vmActionHandler struct {
 	virtRestClient            rest.Interface
 	virtSubresourceRestClient rest.Interface
 	vmio                      vmicommon.VMIOperator
+	httpClient                http.Client
 
 	backupClient            ctlharvesterv1.VirtualMachineBackupClient
+	bulkActionClient        ctlharvesterv1.VirtualMachineBulkActionClient
+	dataVolumeClient        ctlcdiv1.DataVolumeClient
 	pvcClient               ctlcorev1.PersistentVolumeClaimClient
 	resourceQuotaClient     ctlharvesterv1.ResourceQuotaClient
 	restoreClient           ctlharvesterv1.VirtualMachineRestoreClient
 	secretClient            ctlcorev1.SecretClient
 	vmClient                ctlkubevirtv1.VirtualMachineClient
+	vmDownloaderClient      ctlharvesterv1.VirtualMachineDownloaderClient
 	vmImageClient           ctlharvesterv1.VirtualMachineImageClient
 	vmTemplateClient        ctlharvesterv1.VirtualMachineTemplateClient
 	vmTemplateVersionClient ctlharvesterv1.VirtualMachineTemplateVersionClient
@@ -89,6 +98,7 @@ type vmActionHandler struct {
 	vmimClient              ctlkubevirtv1.VirtualMachineInstanceMigrationClient
 
 	backupCache       ctlharvesterv1.VirtualMachineBackupCache
+	backupTargetCache ctlharvesterv1.BackupTargetCache
 	kubevirtCache     ctlkubevirtv1.KubeVirtCache
 	nadCache          ctlcniv1.NetworkAttachmentDefinitionCache
 	nodeCache         ctlcorev1.NodeCache
@@ -117,206 +127,246 @@ func (h *vmActionHandler) Do(ctx *harvesterServer.Ctx) (harvesterServer.Response
 		return nil, apierror.NewAPIError(validation.Unauthorized, "failed to get user from request")
 	}
 
+	if r.Method == http.MethodGet {
+		return nil, h.doLink(r.Context(), rw, r, user, vars["link"], namespace, name)
+	}
+
+	if action == bulkAction {
+		var input BulkActionInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+		return h.bulkAction(user, input)
+	}
+
+	return nil, h.doAction(r.Context(), rw, user, action, namespace, name, r.Body)
+}
+
+// doAction runs the action on a VM, the input of the action is read from body.
+func (h *vmActionHandler) doAction(ctx context.Context, rw http.ResponseWriter, user user.Info, action, namespace, name string, body io.Reader) error {
 	switch action {
 	case insertCdRomVolume:
 		var input InsertCdRomVolumeActionInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
-		return nil, h.insertCdRomVolume(name, namespace, input)
+		return h.insertCdRomVolume(name, namespace, input)
 	case ejectCdRomVolume:
 		var input EjectCdRomVolumeActionInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
-		return nil, h.ejectCdRomVolume(r.Context(), name, namespace, input)
+		return h.ejectCdRomVolume(ctx, name, namespace, input)
 	case migrate:
 		var input MigrateInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
-		return nil, h.migrate(r.Context(), namespace, name, input.NodeName)
+		return h.migrate(ctx, namespace, name, input)
 	case abortMigration:
-		return nil, h.abortMigration(namespace, name)
+		return h.abortMigration(namespace, name)
 	case findMigratableNodes:
-		return nil, h.findMigratableNodes(rw, namespace, name)
+		return h.findMigratableNodes(rw, namespace, name)
 	case startVM, restartVM:
-		if err := h.subresourceOperate(r.Context(), vmResource, namespace, name, action); err != nil {
-			return nil, fmt.Errorf("%s virtual machine %s/%s failed, %v", action, namespace, name, err)
+		if err := h.subresourceOperate(ctx, vmResource, namespace, name, action); err != nil {
+			return fmt.Errorf("%s virtual machine %s/%s failed, %v", action, namespace, name, err)
 		}
 	case stopVM:
 		// To align behavior with kubevirt v1.1.1, we set runStrategy to Halted when stopping a VM.
 		if err := h.stopVM(namespace, name); err != nil {
-			return nil, fmt.Errorf("%s virtual machine %s/%s failed, %v", action, namespace, name, err)
+			return fmt.Errorf("%s virtual machine %s/%s failed, %v", action, namespace, name, err)
 		}
 	case pauseVM, unpauseVM, softReboot:
-		if err := h.subresourceOperate(r.Context(), vmiResource, namespace, name, action); err != nil {
-			return nil, fmt.Errorf("%s virtual machine %s/%s failed, %v", action, namespace, name, err)
+		if err := h.subresourceOperate(ctx, vmiResource, namespace, name, action); err != nil {
+			return fmt.Errorf("%s virtual machine %s/%s failed, %v", action, namespace, name, err)
 		}
 	case backupVM:
 		var input BackupInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
 
 		if input.Name == "" {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Parameter backup name is required")
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter backup name is required")
 		}
 
-		if err := h.checkBackupTargetConfigured(); err != nil {
-			return nil, err
+		if err := h.checkBackupTargetConfigured(input.BackupTargetName); err != nil {
+			return err
 		}
 
 		if err := h.createVMBackup(name, namespace, input); err != nil {
-			return nil, err
+			return err
 		}
 
-		return nil, nil
+		return nil
 	case snapshotVM:
 		// TODO: currently the snapshot CRD creation is handled by UI, we do nothing here.
-		return nil, nil
+		return nil
 	case restoreVM:
 		var input RestoreInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
 
 		if input.Name == "" || input.BackupName == "" {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Parameter name and backupName are required")
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter name and backupName are required")
 		}
 
-		if err := h.checkBackupTargetConfigured(); err != nil {
-			return nil, err
+		backup, err := h.backupCache.Get(namespace, input.BackupName)
+		if err != nil {
+			return err
+		}
+
+		if err := h.checkBackupTargetConfigured(backup.Spec.BackupTargetName); err != nil {
+			return err
 		}
 
 		if err := h.restoreBackup(name, namespace, input); err != nil {
-			return nil, err
+			return err
 		}
-		return nil, nil
+		return nil
 	case createTemplate:
 		var input CreateTemplateInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
 
 		if input.Name == "" {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Template name is required")
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Template name is required")
 		}
-		return nil, h.createTemplate(namespace, name, input)
+		if input.Sysprep && !input.Generalize {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Sysprep requires generalize")
+		}
+		return h.createTemplate(namespace, name, input)
 	case addVolume:
 		var input AddVolumeInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
 		if input.DiskName == "" || input.VolumeSourceName == "" {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `diskName` and `volumeName` are required")
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `diskName` and `volumeName` are required")
 		}
-		return nil, h.addVolume(r.Context(), namespace, name, input)
+		return h.addVolume(ctx, namespace, name, input)
 	case removeVolume:
 		var input RemoveVolumeInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
 		if input.DiskName == "" {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `volumeName` are required")
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `volumeName` are required")
+		}
+		return h.removeVolume(ctx, namespace, name, input)
+	case expandVolume:
+		var input ExpandVolumeInput
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		}
+		if input.DiskName == "" || input.Size == "" {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `diskName` and `size` are required")
 		}
-		return nil, h.removeVolume(r.Context(), namespace, name, input)
+		return h.expandVolume(namespace, name, input)
 	case addNic:
 		var input AddNicInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
-		return nil, h.addNic(r.Context(), namespace, name, input)
+		return h.addNic(ctx, namespace, name, input)
 	case removeNic:
 		var input RemoveNicInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
-		return nil, h.removeNic(r.Context(), namespace, name, input)
+		return h.removeNic(ctx, namespace, name, input)
 	case findHotunpluggableNics:
-		return nil, h.findHotunpluggableNics(rw, namespace, name)
+		return h.findHotunpluggableNics(rw, namespace, name)
 	case cloneVM:
 		var input CloneInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
 
 		if input.TargetVM == "" {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Parameter targetVm are required")
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter targetVm are required")
 		}
 
-		if err := h.cloneVM(name, namespace, input); err != nil {
-			return nil, err
+		if err := h.cloneVM(user, name, namespace, input); err != nil {
+			return err
 		}
-		return nil, nil
+		return nil
 	case forceStopVM:
 		var gracePeriod int64
 		stopOptions := &kubevirtv1.StopOptions{GracePeriod: &gracePeriod}
-		body, err := json.Marshal(stopOptions)
+		stopBody, err := json.Marshal(stopOptions)
 		if err != nil {
-			return nil, fmt.Errorf("%s virtual machine %s/%s failed, %v", action, namespace, name, err)
+			return fmt.Errorf("%s virtual machine %s/%s failed, %v", action, namespace, name, err)
 		}
 		// The request is equal to "virtctl stop my-vm --grace-period 0 --force"
-		if err := h.virtSubresourceRestClient.Put().Namespace(namespace).Resource(vmResource).SubResource(stopVM).Name(name).Body(body).Do(r.Context()).Error(); err != nil {
+		if err := h.virtSubresourceRestClient.Put().Namespace(namespace).Resource(vmResource).SubResource(stopVM).Name(name).Body(stopBody).Do(ctx).Error(); err != nil {
 			// Kubevirt returns "Halted does not support manual stop requests" error when VM runStrategy is Halted,
 			// but the request will still forcely stop the VM.
 			if strings.Contains(err.Error(), "Halted does not support manual stop requests") {
-				return nil, nil
+				return nil
 			}
-			return nil, err
+			return err
 		}
 	case dismissInsufficientResourceQuota:
-		return nil, h.dismissInsufficientResourceQuota(name, namespace)
+		return h.dismissInsufficientResourceQuota(name, namespace)
 	case updateResourceQuotaAction:
 		if ok, err := apiutil.CanUpdateResourceQuota(h.clientSet, namespace, user.GetName()); err != nil {
-			return nil, apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to check permission: %v", err))
+			return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to check permission: %v", err))
 		} else if !ok {
-			return nil, apierror.NewAPIError(validation.PermissionDenied, "User does not have permission to update resource quota")
+			return apierror.NewAPIError(validation.PermissionDenied, "User does not have permission to update resource quota")
 		}
 		var updateResourceQuotaInput UpdateResourceQuotaInput
-		if err := json.NewDecoder(r.Body).Decode(&updateResourceQuotaInput); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Failed to decode request body: %v ", err))
+		if err := json.NewDecoder(body).Decode(&updateResourceQuotaInput); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Failed to decode request body: %v ", err))
 		}
-		return nil, h.updateResourceQuota(namespace, name, updateResourceQuotaInput)
+		return h.updateResourceQuota(namespace, name, updateResourceQuotaInput)
 	case deleteResourceQuotaAction:
 		if ok, err := apiutil.CanUpdateResourceQuota(h.clientSet, namespace, user.GetName()); err != nil {
-			return nil, apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to check permission: %v", err))
+			return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to check permission: %v", err))
 		} else if !ok {
-			return nil, apierror.NewAPIError(validation.PermissionDenied, "User does not have permission to update resource quota")
+			return apierror.NewAPIError(validation.PermissionDenied, "User does not have permission to update resource quota")
 		}
-		return nil, h.deleteResourceQuota(namespace, name)
+		return h.deleteResourceQuota(namespace, name)
 	case cpuAndMemoryHotplug:
 		vm, err := h.vmCache.Get(namespace, name)
 		if err != nil {
-			return nil, apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get virtual machine %s/%s: %v", namespace, name, err))
+			return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get virtual machine %s/%s: %v", namespace, name, err))
 		}
 		if !canCPUAndMemoryHotplug(vm) {
-			return nil, apierror.NewAPIError(validation.InvalidAction, "CPU and memory hotplug is not supported for this VM")
+			return apierror.NewAPIError(validation.InvalidAction, "CPU and memory hotplug is not supported for this VM")
 		}
 		var input CPUAndMemoryHotplugInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
-		return nil, h.cpuAndMemoryHotplug(namespace, name, input)
+		return h.cpuAndMemoryHotplug(namespace, name, input)
+	case exportVM:
+		var input ExportVMInput
+		if err := json.NewDecoder(body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		}
+		return h.exportVM(user, namespace, name, input)
 	case storageMigration:
 		var input StorageMigrationInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: %v "+err.Error())
 		}
 		if input.SourceVolume == "" || input.TargetVolume == "" {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `sourceVolume` and `targetVolume` are required")
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `sourceVolume` and `targetVolume` are required")
 		}
 		if input.SourceVolume == input.TargetVolume {
-			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "sourceVolume and targetVolume must be different")
+			return apierror.NewAPIError(validation.InvalidBodyContent, "sourceVolume and targetVolume must be different")
 		}
-		return nil, h.storageMigration(namespace, name, input)
+		return h.storageMigration(namespace, name, input)
 	case cancelStorageMigration:
-		return nil, h.cancelStorageMigration(namespace, name)
+		return h.cancelStorageMigration(namespace, name)
 	default:
-		return nil, apierror.NewAPIError(validation.InvalidAction, "Unsupported action")
+		return apierror.NewAPIError(validation.InvalidAction, "Unsupported action")
 	}
 
-	return nil, nil
+	return nil
 }
 
 func (h *vmActionHandler) insertCdRomVolume(name, namespace string, input InsertCdRomVolumeActionInput) error {
@@ -525,7 +575,8 @@ func removeVolumeClaimTemplatesFromVMAnnotation(vm *kubevirtv1.VirtualMachine, t
 	return nil
 }
 
-func (h *vmActionHandler) migrate(ctx context.Context, namespace, vmName string, nodeName string) error {
+func (h *vmActionHandler) migrate(ctx context.Context, namespace, vmName string, input MigrateInput) error {
+	nodeName := input.NodeName
 	vmi, err := h.vmiCache.Get(namespace, vmName)
 	if err != nil {
 		return err
@@ -541,7 +592,29 @@ func (h *vmActionHandler) migrate(ctx context.Context, namespace, vmName string,
 		return err
 	}
 
-	if ok, err := h.isMigratableNode(nodeName, vmi); err != nil {
+	if nodeName != "" && nodeName == vmi.Status.NodeName {
+		return apierror.NewAPIError(validation.InvalidBodyContent, "The VM is currently running on the target node")
+	}
+
+	// KubeVirt matches the migration with a policy by the vmi labels, the target nodes are checked against the new policy.
+	// The new policy is kept in the VM only after the migration is validated.
+	toUpdateVmi := vmi.DeepCopy()
+	policyChanged := input.MigrationPolicy != "" && vmi.Labels[util.LabelMigrationPolicy] != input.MigrationPolicy
+	if policyChanged {
+		if toUpdateVmi.Labels == nil {
+			toUpdateVmi.Labels = make(map[string]string)
+		}
+		toUpdateVmi.Labels[util.LabelMigrationPolicy] = input.MigrationPolicy
+	}
+	policy, err := getVMIMigrationPolicy(toUpdateVmi)
+	if err != nil {
+		return err
+	}
+	if policyChanged && policy == nil {
+		return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Migration policy %s is not found in the %s setting", input.MigrationPolicy, settings.VMMigrationPoliciesSettingName))
+	}
+
+	if ok, err := h.isMigratableNode(nodeName, toUpdateVmi); err != nil {
 		return fmt.Errorf("can't migrate the VM to the node %s: %s", nodeName, err.Error())
 	} else if !ok {
 		return errors.New("The target node is non-migratable")
@@ -564,6 +637,12 @@ func (h *vmActionHandler) migrate(ctx context.Context, namespace, vmName string,
 		return errors.New("KubeVirt is not ready")
 	}
 
+	if policyChanged {
+		if err := h.setMigrationPolicy(namespace, vmName, input.MigrationPolicy); err != nil {
+			return err
+		}
+	}
+
 	vmim := &kubevirtv1.VirtualMachineInstanceMigration{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: vmName + "-",
@@ -573,26 +652,26 @@ func (h *vmActionHandler) migrate(ctx context.Context, namespace, vmName string,
 			VMIName: vmName,
 		},
 	}
+	if policy != nil && len(policy.NodeSelector) > 0 {
+		vmim.Spec.AddedNodeSelector = maps.Clone(policy.NodeSelector)
+	}
 	if nodeName != "" {
-		// check node name is valid
-		if nodeName == vmi.Status.NodeName {
-			return apierror.NewAPIError(validation.InvalidBodyContent, "The VM is currently running on the target node")
-		}
-
 		// set vmi node selector before starting the migration
-		toUpdateVmi := vmi.DeepCopy()
 		if toUpdateVmi.Annotations == nil {
 			toUpdateVmi.Annotations = make(map[string]string)
 		}
 		toUpdateVmi.Annotations[util.AnnotationMigrationTarget] = nodeName
 
-		if err := util.VirtClientUpdateVmi(ctx, h.virtRestClient, h.namespace, namespace, vmName, toUpdateVmi); err != nil {
-			logrus.Errorf("failed to update vmi %s/%s with migration target %s: %v", namespace, vmName, nodeName, err)
-			return err
+		if vmim.Spec.AddedNodeSelector == nil {
+			vmim.Spec.AddedNodeSelector = make(map[string]string)
 		}
+		vmim.Spec.AddedNodeSelector[corev1.LabelHostname] = nodeName
+	}
 
-		vmim.Spec.AddedNodeSelector = map[string]string{
-			corev1.LabelHostname: nodeName,
+	if !reflect.DeepEqual(vmi.ObjectMeta, toUpdateVmi.ObjectMeta) {
+		if err := util.VirtClientUpdateVmi(ctx, h.virtRestClient, h.namespace, namespace, vmName, toUpdateVmi); err != nil {
+			logrus.Errorf("failed to update vmi %s/%s with migration target %q and policy %q: %v", namespace, vmName, nodeName, input.MigrationPolicy, err)
+			return err
 		}
 	}
 
@@ -605,6 +684,34 @@ func (h *vmActionHandler) migrate(ctx context.Context, namespace, vmName string,
 	return nil
 }
 
+// setMigrationPolicy keeps the migration policy in the VM annotation, so that the VMIs started later get it too
+func (h *vmActionHandler) setMigrationPolicy(namespace, name, policyName string) error {
+	vm, err := h.vmCache.Get(namespace, name)
+	if err != nil {
+		return err
+	}
+	if vm.Annotations[util.AnnotationMigrationPolicy] == policyName {
+		return nil
+	}
+
+	vmCopy := vm.DeepCopy()
+	if vmCopy.Annotations == nil {
+		vmCopy.Annotations = make(map[string]string)
+	}
+	vmCopy.Annotations[util.AnnotationMigrationPolicy] = policyName
+	_, err = h.vmClient.Update(vmCopy)
+	return err
+}
+
+// getVMIMigrationPolicy returns the migration policy the VMI is labeled with, nil if there is none
+func getVMIMigrationPolicy(vmi *kubevirtv1.VirtualMachineInstance) (*settings.VMMigrationPolicy, error) {
+	policyName := vmi.Labels[util.LabelMigrationPolicy]
+	if policyName == "" {
+		return nil, nil
+	}
+	return settings.GetVMMigrationPolicy(policyName)
+}
+
 func (h *vmActionHandler) isMigratableNode(targetNode string, vmi *kubevirtv1.VirtualMachineInstance) (bool, error) {
 	if targetNode == "" {
 		return true, nil
@@ -694,6 +801,21 @@ func (h *vmActionHandler) findMigratableNodesByVMI(vmi *kubevirtv1.VirtualMachin
 		return nil, err
 	}
 
+	// the migration policy limits the target nodes further
+	policy, err := getVMIMigrationPolicy(vmi)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		for key, value := range policy.NodeSelector {
+			requirement, err := labels.NewRequirement(key, selection.Equals, []string{value})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create requirement for %s=%s: %w", key, value, err)
+			}
+			nodeFilter = nodeFilter.Add(*requirement)
+		}
+	}
+
 	nodes, err := h.nodeCache.List(nodeFilter)
 	if err != nil || len(nodes) == 0 {
 		return nil, err
@@ -863,7 +985,9 @@ func (h *vmActionHandler) createVMBackup(vmName, vmNamespace string, input Backu
 				Kind:     kubevirtv1.VirtualMachineGroupVersionKind.Kind,
 				Name:     vmName,
 			},
-			Type: harvesterv1.Backup,
+			Type:                  harvesterv1.Backup,
+			BackupTargetName:      input.BackupTargetName,
+			ApplicationConsistent: input.ApplicationConsistent,
 		},
 	}
 	if _, err := h.backupClient.Create(backup); err != nil {
@@ -893,6 +1017,15 @@ func (h *vmActionHandler) restoreBackup(vmName, vmNamespace string, input Restor
 			NewVM:                         false,
 		},
 	}
+	if len(input.Volumes) > 0 {
+		restore.Spec.Volumes = input.Volumes
+		restore.Spec.VolumeRestoreMode = input.VolumeRestoreMode
+		if restore.Spec.VolumeRestoreMode == "" {
+			restore.Spec.VolumeRestoreMode = harvesterv1.VolumeRestoreModeInPlace
+		}
+	} else if input.VolumeRestoreMode != "" {
+		return fmt.Errorf("volume restore mode %s only works with selected volumes", input.VolumeRestoreMode)
+	}
 	_, err := h.restoreClient.Create(restore)
 	if err != nil {
 		return fmt.Errorf("failed to create restore, error: %s", err.Error())
@@ -901,7 +1034,18 @@ func (h *vmActionHandler) restoreBackup(vmName, vmNamespace string, input Restor
 	return nil
 }
 
-func (h *vmActionHandler) checkBackupTargetConfigured() error {
+func (h *vmActionHandler) checkBackupTargetConfigured(backupTargetName string) error {
+	if backupTargetName != "" {
+		bt, err := h.backupTargetCache.Get(backupTargetName)
+		if err != nil {
+			return err
+		}
+		if harvesterv1.BackupTargetConditionAvailable.IsTrue(bt) {
+			return nil
+		}
+		return fmt.Errorf("backup target %s is not available", backupTargetName)
+	}
+
 	targetSetting, err := h.settingCache.Get(settings.BackupTargetSettingName)
 	if err == nil && harvesterv1.SettingConfigured.IsTrue(targetSetting) {
 		// backup target may be reset to initial/default, the SettingConfigured.IsTrue meets
@@ -1158,7 +1302,7 @@ func (h *vmActionHandler) addNic(ctx context.Context, namespace, name string, in
 	// https://kubevirt.io/user-guide/network/hotplug_interfaces/#migration-based-hotplug
 	// Although it's not required to manually migrate the VM as mentioned in the document,
 	// we still immediately call the migration here for better UX instead of waiting KubeVirt to discover and reconcile.
-	return h.migrate(ctx, namespace, name, "")
+	return h.migrate(ctx, namespace, name, MigrateInput{})
 }
 
 // removeNic remove a hotplug NIC by its interface name
@@ -1201,7 +1345,7 @@ func (h *vmActionHandler) removeNic(ctx context.Context, namespace, name string,
 	// https://kubevirt.io/user-guide/network/hotplug_interfaces/#migration-based-hotplug
 	// Although it's not required to manually migrate the VM as mentioned in the document,
 	// we still immediately call the migration here for better UX instead of waiting KubeVirt to discover and reconcile.
-	return h.migrate(ctx, namespace, name, "")
+	return h.migrate(ctx, namespace, name, MigrateInput{})
 }
 
 // findHotunpluggableNics return a list of NIC names that could be hot-unplugged
@@ -1229,16 +1373,110 @@ func (h *vmActionHandler) findHotunpluggableNics(rw http.ResponseWriter, namespa
 }
 
 // cloneVM creates a VM which uses volume cloning from the source VM.
-func (h *vmActionHandler) cloneVM(name string, namespace string, input CloneInput) error {
+func (h *vmActionHandler) cloneVM(user user.Info, name string, namespace string, input CloneInput) error {
 	vm, err := h.vmCache.Get(namespace, name)
 	if err != nil {
 		return fmt.Errorf("cannot get vm %s/%s, err: %w", namespace, name, err)
 	}
-	newVM := getClonedVMYamlFromSourceVM(input, vm)
 
-	newPVCs, secretNameMap, err := h.cloneVolumes(newVM)
+	targets, err := getCloneTargets(input, vm)
 	if err != nil {
-		return fmt.Errorf("clone volumes error for new vm %s/%s, err %w", newVM.Namespace, newVM.Name, err)
+		return apierror.NewAPIError(validation.InvalidBodyContent, err.Error())
+	}
+
+	// the clones are created by harvester, check the user can create them in the target namespace
+	targetNamespace := targets[0].Namespace
+	if ok, err := apiutil.CanCreateVMWithVolumes(h.clientSet, targetNamespace, user.GetName(), user.GetGroups()); err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to check permission: %v", err))
+	} else if !ok {
+		return apierror.NewAPIError(validation.PermissionDenied, fmt.Sprintf("User does not have permission to create virtual machines and volumes in namespace %s", targetNamespace))
+	}
+
+	if input.TargetStorageClassName != "" {
+		if _, err := h.storageClassCache.Get(input.TargetStorageClassName); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("cannot get storage class %s, err: %v", input.TargetStorageClassName, err))
+		}
+	}
+
+	// check all the names first, so a conflict doesn't leave a half created batch
+	for _, target := range targets {
+		if _, err := h.vmCache.Get(target.Namespace, target.Name); err == nil {
+			return apierror.NewAPIError(validation.Conflict, fmt.Sprintf("vm %s/%s already exists", target.Namespace, target.Name))
+		} else if !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	var clones []*kubevirtv1.VirtualMachine
+	for _, target := range targets {
+		clone, err := h.cloneVMTo(vm, input, target)
+		if clone != nil {
+			clones = append(clones, clone)
+		}
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"namespace": target.Namespace,
+				"name":      target.Name,
+			}).Error("failed to clone vm, rolling back the created clones")
+			h.deleteClones(clones)
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteClones rolls back the clones of a failed batch, the errors are logged since the clone error is returned
+func (h *vmActionHandler) deleteClones(clones []*kubevirtv1.VirtualMachine) {
+	for _, clone := range clones {
+		if err := h.deleteClone(clone); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"namespace": clone.Namespace,
+				"name":      clone.Name,
+			}).Warn("failed to roll back the clone")
+		}
+	}
+}
+
+// deleteClone deletes the clone along with its volumes, the secrets are owned by the clone and garbage collected
+func (h *vmActionHandler) deleteClone(clone *kubevirtv1.VirtualMachine) error {
+	var claimNames []string
+	for _, volume := range clone.Spec.Template.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		claimNames = append(claimNames, volume.PersistentVolumeClaim.ClaimName)
+		// the volumes copied by CDI are owned by the DataVolumes
+		if err := h.dataVolumeClient.Delete(clone.Namespace, volume.PersistentVolumeClaim.ClaimName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete DataVolume %s/%s, err: %w", clone.Namespace, volume.PersistentVolumeClaim.ClaimName, err)
+		}
+	}
+
+	// the VM controller deletes the PVCs in the annotation when the VM is removed
+	toUpdate := clone.DeepCopy()
+	if toUpdate.Annotations == nil {
+		toUpdate.Annotations = map[string]string{}
+	}
+	toUpdate.Annotations[util.RemovedPVCsAnnotationKey] = strings.Join(claimNames, ",")
+	if _, err := h.vmClient.Update(toUpdate); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot set removed PVCs of vm %s/%s, err: %w", clone.Namespace, clone.Name, err)
+	}
+	if err := h.vmClient.Delete(clone.Namespace, clone.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("cannot delete vm %s/%s, err: %w", clone.Namespace, clone.Name, err)
+	}
+	return nil
+}
+
+// cloneVMTo creates one clone of the source VM with its volumes and secrets, the created VM is returned
+// even if the volumes or secrets fail to be created, so the caller can roll it back
+func (h *vmActionHandler) cloneVMTo(vm *kubevirtv1.VirtualMachine, input CloneInput, target cloneTarget) (*kubevirtv1.VirtualMachine, error) {
+	newVM := getClonedVMYamlFromSourceVM(input, vm, target)
+
+	newPVCs, newDataVolumes, secretNameMap, err := h.cloneVolumes(vm.Namespace, newVM, input.TargetStorageClassName)
+	if err != nil {
+		return nil, fmt.Errorf("clone volumes error for new vm %s/%s, err %w", newVM.Namespace, newVM.Name, err)
 	}
 	newEntries := make([]util.VolumeClaimTemplateEntry, len(newPVCs))
 	for i, pvc := range newPVCs {
@@ -1246,23 +1484,31 @@ func (h *vmActionHandler) cloneVM(name string, namespace string, input CloneInpu
 	}
 	newPVCsString, err := util.MarshalVolumeClaimTemplates(newEntries)
 	if err != nil {
-		return fmt.Errorf("cannot marshal value %+v, err: %w", newEntries, err)
+		return nil, fmt.Errorf("cannot marshal value %+v, err: %w", newEntries, err)
 	}
 
 	newVM.ObjectMeta.Annotations[util.AnnotationVolumeClaimTemplates] = newPVCsString
 	if newVM, err = h.vmClient.Create(newVM); err != nil {
-		return fmt.Errorf("cannot create new VM %s/%s, err: %w", newVM.Namespace, newVM.Name, err)
+		return nil, fmt.Errorf("cannot create new VM %s/%s, err: %w", target.Namespace, target.Name, err)
+	}
+
+	// the volumes copied across namespaces or storage classes are populated by CDI,
+	// KubeVirt waits for them before starting the VM
+	for i := range newDataVolumes {
+		if _, err := h.dataVolumeClient.Create(&newDataVolumes[i]); err != nil {
+			return newVM, fmt.Errorf("cannot create DataVolume %s/%s, err: %w", newDataVolumes[i].Namespace, newDataVolumes[i].Name, err)
+		}
 	}
 
 	for oldSecretName, newSecretName := range secretNameMap {
-		secret, err := h.secretCache.Get(namespace, oldSecretName)
+		secret, err := h.secretCache.Get(vm.Namespace, oldSecretName)
 		if err != nil {
-			return fmt.Errorf("cannot get secret %s/%s, err: %w", namespace, oldSecretName, err)
+			return newVM, fmt.Errorf("cannot get secret %s/%s, err: %w", vm.Namespace, oldSecretName, err)
 		}
 
 		newSecret := corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
-				Namespace: namespace,
+				Namespace: newVM.Namespace,
 				Name:      newSecretName,
 				OwnerReferences: []metav1.OwnerReference{
 					{
@@ -1277,26 +1523,40 @@ func (h *vmActionHandler) cloneVM(name string, namespace string, input CloneInpu
 			StringData: secret.StringData,
 			Type:       secret.Type,
 		}
+		if target.UserData != "" && isUserDataSecret(newVM, newSecretName) {
+			newSecret.Data = withUserData(secret.Data, target.UserData)
+		}
 		if _, err = h.secretClient.Create(&newSecret); err != nil {
-			return fmt.Errorf("cannot create a new secret from %s/%s, err: %w", namespace, oldSecretName, err)
+			return newVM, fmt.Errorf("cannot create a new secret from %s/%s, err: %w", vm.Namespace, oldSecretName, err)
 		}
 	}
-	return nil
+	return newVM, nil
 }
 
-func (h *vmActionHandler) cloneVolumes(newVM *kubevirtv1.VirtualMachine) ([]corev1.PersistentVolumeClaim, map[string]string, error) {
+// cloneVolumes replaces the volumes of the new VM with copies of the source volumes. The volumes are cloned
+// by the CSI driver in the same namespace and storage class, otherwise they are copied by CDI DataVolumes.
+func (h *vmActionHandler) cloneVolumes(sourceNamespace string, newVM *kubevirtv1.VirtualMachine, storageClassName string) ([]corev1.PersistentVolumeClaim, []cdiv1.DataVolume, map[string]string, error) {
 	var (
-		err           error
-		newPVCs       []corev1.PersistentVolumeClaim
-		secretNameMap = map[string]string{} // sourceVM secret name to newVM secret name
+		err            error
+		newPVCs        []corev1.PersistentVolumeClaim
+		newDataVolumes []cdiv1.DataVolume
+		secretNameMap  = map[string]string{} // sourceVM secret name to newVM secret name
 	)
 
 	for i, volume := range newVM.Spec.Template.Spec.Volumes {
 		if volume.PersistentVolumeClaim != nil {
 			var pvc *corev1.PersistentVolumeClaim
-			pvc, err = h.pvcCache.Get(newVM.Namespace, volume.PersistentVolumeClaim.ClaimName)
+			pvc, err = h.pvcCache.Get(sourceNamespace, volume.PersistentVolumeClaim.ClaimName)
 			if err != nil {
-				return nil, nil, fmt.Errorf("cannot get pvc %s, err: %w", volume.PersistentVolumeClaim.ClaimName, err)
+				return nil, nil, nil, fmt.Errorf("cannot get pvc %s, err: %w", volume.PersistentVolumeClaim.ClaimName, err)
+			}
+
+			newPVCName := names.SimpleNameGenerator.GenerateName(fmt.Sprintf("%s-%s-", newVM.Name, volume.Name))
+			if newVM.Namespace != sourceNamespace || (storageClassName != "" && storageClassName != ptr.Deref(pvc.Spec.StorageClassName, "")) {
+				newDataVolumes = append(newDataVolumes, getCloneDataVolume(pvc, newVM.Namespace, newPVCName, storageClassName))
+				volume.PersistentVolumeClaim.ClaimName = newPVCName
+				newVM.Spec.Template.Spec.Volumes[i] = volume
+				continue
 			}
 
 			annotations := map[string]string{}
@@ -1306,7 +1566,7 @@ func (h *vmActionHandler) cloneVolumes(newVM *kubevirtv1.VirtualMachine) ([]core
 			newPVC := corev1.PersistentVolumeClaim{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace:   newVM.Namespace,
-					Name:        names.SimpleNameGenerator.GenerateName(fmt.Sprintf("%s-%s-", newVM.Name, volume.Name)),
+					Name:        newPVCName,
 					Annotations: annotations,
 				},
 				Spec: corev1.PersistentVolumeClaimSpec{
@@ -1340,11 +1600,11 @@ func (h *vmActionHandler) cloneVolumes(newVM *kubevirtv1.VirtualMachine) ([]core
 		} else if volume.ContainerDisk != nil {
 			continue
 		} else {
-			return nil, nil, fmt.Errorf("invalid volume %s, only support PersistentVolumeClaim, CloudInitNoCloud, Secret, and ContainerDisk", volume.Name)
+			return nil, nil, nil, fmt.Errorf("invalid volume %s, only support PersistentVolumeClaim, CloudInitNoCloud, Secret, and ContainerDisk", volume.Name)
 		}
 		newVM.Spec.Template.Spec.Volumes[i] = volume
 	}
-	return newPVCs, secretNameMap, nil
+	return newPVCs, newDataVolumes, secretNameMap, nil
 }
 
 func cloneSecretVolume(volume *kubevirtv1.Volume, secretNameMap map[string]string) {
@@ -1408,14 +1668,6 @@ func getTemplateVersionNetworkDataSecretName(templateVersionName, volumeName str
 	return wranglername.SafeConcatName("templateversion", templateVersionName, volumeName, "networkdata")
 }
 
-func getTemplateVersionPvcName(templateVersionName string, diskIndex int) string {
-	return wranglername.SafeConcatName("templateversion", templateVersionName, fmt.Sprintf("disk-%d", diskIndex))
-}
-
-func getTemplateVersionVMImageName(templateVersionName string, imageIndex int) string {
-	return wranglername.SafeConcatName("templateversion", templateVersionName, fmt.Sprintf("image-%d", imageIndex))
-}
-
 func getTemplateVersionSSHPublicKeySecretName(templateVersionName string, credentialIndex int) string {
 	return wranglername.SafeConcatName("templateversion", templateVersionName, fmt.Sprintf("credential-%d", credentialIndex), "sshpublickey")
 }
@@ -1424,11 +1676,11 @@ func getTemplateVersionUserPasswordSecretName(templateVersionName string, creden
 	return wranglername.SafeConcatName("templateversion", templateVersionName, fmt.Sprintf("credential-%d", credentialIndex), "userpassword")
 }
 
-func getClonedVMYamlFromSourceVM(input CloneInput, sourceVM *kubevirtv1.VirtualMachine) *kubevirtv1.VirtualMachine {
+func getClonedVMYamlFromSourceVM(input CloneInput, sourceVM *kubevirtv1.VirtualMachine, target cloneTarget) *kubevirtv1.VirtualMachine {
 	newVM := &kubevirtv1.VirtualMachine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        input.TargetVM,
-			Namespace:   sourceVM.Namespace,
+			Name:        target.Name,
+			Namespace:   target.Namespace,
 			Annotations: map[string]string{},
 			Labels:      sourceVM.Labels,
 		},
@@ -1444,11 +1696,30 @@ func getClonedVMYamlFromSourceVM(input CloneInput, sourceVM *kubevirtv1.VirtualM
 	if runStrategy != kubevirtv1.RunStrategyUnknown {
 		newVM.Spec.RunStrategy = &runStrategy
 	}
-	newVM.Spec.Template.Spec.Hostname = newVM.Name
+	newVM.Spec.Template.Spec.Hostname = target.Hostname
 	newVM.Spec.Template.ObjectMeta.Labels[builder.LabelKeyVirtualMachineName] = newVM.Name
 	for i := range newVM.Spec.Template.Spec.Domain.Devices.Interfaces {
 		newVM.Spec.Template.Spec.Domain.Devices.Interfaces[i].MacAddress = ""
 	}
+
+	// the networks without a namespace are in the namespace of the source VM
+	if newVM.Namespace != sourceVM.Namespace {
+		for i, network := range newVM.Spec.Template.Spec.Networks {
+			if network.Multus != nil && !strings.Contains(network.Multus.NetworkName, "/") {
+				newVM.Spec.Template.Spec.Networks[i].Multus.NetworkName = fmt.Sprintf("%s/%s", sourceVM.Namespace, network.Multus.NetworkName)
+			}
+		}
+	}
+
+	// the inline user data is replaced here, the user data in a secret is replaced when the secret is copied
+	if target.UserData != "" {
+		for _, volume := range newVM.Spec.Template.Spec.Volumes {
+			if volume.CloudInitNoCloud != nil && volume.CloudInitNoCloud.UserDataSecretRef == nil {
+				volume.CloudInitNoCloud.UserData = target.UserData
+				volume.CloudInitNoCloud.UserDataBase64 = ""
+			}
+		}
+	}
 	return newVM
 }
 
@@ -19,6 +19,7 @@ import (
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/controller/master/migration"
 	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
 	"github.com/harvester/harvester/pkg/util/fakeclients"
 	cniv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -280,7 +281,7 @@ func TestMigrateAction(t *testing.T) {
 
 		var actual output
 		var err error
-		actual.err = handler.migrate(context.Background(), tc.given.namespace, tc.given.name, tc.given.nodeName)
+		actual.err = handler.migrate(context.Background(), tc.given.namespace, tc.given.name, MigrateInput{NodeName: tc.given.nodeName})
 		actual.vmInstanceMigrations, err = handler.vmimCache.List(tc.given.namespace, labels.Everything())
 		assert.Nil(t, err, "List should return no error")
 
@@ -295,6 +296,58 @@ func TestMigrateAction(t *testing.T) {
 
 }
 
+func TestMigrateActionRefusedKeepsPolicy(t *testing.T) {
+	assert.Nil(t, settings.VMMigrationPolicies.Set(`[{"name":"database","nodeSelector":{"zone":"zone3"}}]`))
+	defer func() {
+		_ = settings.VMMigrationPolicies.Set("[]")
+	}()
+
+	var testCases = []struct {
+		name   string
+		policy string
+	}{
+		{name: "policy not found", policy: "unknown"},
+		{name: "target node not selected by the policy", policy: "database"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vm := &kubevirtv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+			}
+			vmi := &kubevirtv1.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+				Status: kubevirtv1.VirtualMachineInstanceStatus{
+					Phase:    kubevirtv1.Running,
+					NodeName: "fake-node2",
+					Conditions: []kubevirtv1.VirtualMachineInstanceCondition{
+						{Type: kubevirtv1.VirtualMachineInstanceReady, Status: corev1.ConditionTrue},
+					},
+				},
+			}
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "fake-node1"}}
+			clientset := fake.NewSimpleClientset(vm, vmi, node)
+
+			handler := &vmActionHandler{
+				nodeCache:  fakeclients.NodeCache(clientset.CoreV1().Nodes),
+				podCache:   fakeclients.PodCache(clientset.CoreV1().Pods),
+				vmClient:   fakeclients.VirtualMachineClient(clientset.KubevirtV1().VirtualMachines),
+				vmCache:    fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+				vmiCache:   fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+				vmimClient: fakeclients.VirtualMachineInstanceMigrationClient(clientset.KubevirtV1().VirtualMachineInstanceMigrations),
+			}
+
+			err := handler.migrate(context.Background(), "default", "test", MigrateInput{NodeName: "fake-node1", MigrationPolicy: tc.policy})
+			assert.NotNil(t, err)
+
+			// the refused migration doesn't change the policy of the VM
+			stored, err := clientset.KubevirtV1().VirtualMachines("default").Get(context.Background(), "test", metav1.GetOptions{})
+			assert.Nil(t, err)
+			assert.Empty(t, stored.Annotations[util.AnnotationMigrationPolicy])
+		})
+	}
+}
+
 func TestAbortMigrateAction(t *testing.T) {
 	type input struct {
 		namespace           string
@@ -749,6 +802,46 @@ func Test_vmActionHandler_findMigratableNodesByVMI(t *testing.T) {
 				"node2",
 			},
 		},
+		{
+			name: "Get migratable nodes by node selector of migration policy",
+			args: args{
+				vmi: &kubevirtv1.VirtualMachineInstance{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-policy",
+						Namespace: "default",
+						UID:       "vmi-policy-uid",
+						Labels: map[string]string{
+							util.LabelMigrationPolicy: "database",
+						},
+					},
+					Status: kubevirtv1.VirtualMachineInstanceStatus{
+						NodeName: "node1",
+						ActivePods: map[types.UID]string{
+							"pod-policy-uid": "node1",
+						},
+					},
+				},
+				pods: []*corev1.Pod{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "virt-launcher-test-policy",
+						Namespace: "default",
+						UID:       "pod-policy-uid",
+						Labels: map[string]string{
+							kubevirtv1.CreatedByLabel: "vmi-policy-uid",
+						},
+					},
+					Spec: corev1.PodSpec{
+						NodeName: "node1",
+						NodeSelector: map[string]string{
+							kubevirtv1.NodeSchedulable: "true",
+						},
+					},
+				}},
+			},
+			want: []string{
+				"node3",
+			},
+		},
 		{
 			name: "User defined custom selector from pod",
 			args: args{
@@ -1254,6 +1347,11 @@ func Test_vmActionHandler_findMigratableNodesByVMI(t *testing.T) {
 	}
 	var nodeCache = fakeclients.NodeCache(clientset.CoreV1().Nodes)
 
+	assert.Nil(t, settings.VMMigrationPolicies.Set(`[{"name":"database","nodeSelector":{"zone":"zone3"}}]`))
+	defer func() {
+		_ = settings.VMMigrationPolicies.Set("[]")
+	}()
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create fresh clientset for each test to avoid pod interference
@@ -1587,3 +1685,67 @@ func TestEjectCdRomVolumeAction(t *testing.T) {
 	_, err = pvcCache.Get(pvcNamespace, pvcName)
 	assert.True(t, apierrors.IsNotFound(err), "Should delete pvc")
 }
+
+func TestRestoreBackup(t *testing.T) {
+	backup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backup"},
+	}
+
+	var testCases = []struct {
+		name         string
+		input        RestoreInput
+		expectedErr  bool
+		expectedMode harvesterv1.VolumeRestoreMode
+	}{
+		{
+			name:  "restore all volumes",
+			input: RestoreInput{Name: "restore", BackupName: "backup"},
+		},
+		{
+			name:         "selected volumes are restored in place by default",
+			input:        RestoreInput{Name: "restore", BackupName: "backup", Volumes: []string{"disk-0"}},
+			expectedMode: harvesterv1.VolumeRestoreModeInPlace,
+		},
+		{
+			name: "standalone restore of selected volumes",
+			input: RestoreInput{
+				Name:              "restore",
+				BackupName:        "backup",
+				Volumes:           []string{"disk-0"},
+				VolumeRestoreMode: harvesterv1.VolumeRestoreModeStandalone,
+			},
+			expectedMode: harvesterv1.VolumeRestoreModeStandalone,
+		},
+		{
+			name: "restore mode without selected volumes",
+			input: RestoreInput{
+				Name:              "restore",
+				BackupName:        "backup",
+				VolumeRestoreMode: harvesterv1.VolumeRestoreModeStandalone,
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		var clientset = fake.NewSimpleClientset(backup)
+		var handler = &vmActionHandler{
+			backupCache:   fakeclients.VMBackupCache(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+			restoreClient: fakeclients.VMRestoreClient(clientset.HarvesterhciV1beta1().VirtualMachineRestores),
+		}
+
+		err := handler.restoreBackup("vm", "default", tc.input)
+		restore, getErr := clientset.HarvesterhciV1beta1().VirtualMachineRestores("default").Get(context.TODO(), tc.input.Name, metav1.GetOptions{})
+		if tc.expectedErr {
+			assert.Error(t, err, "case %q", tc.name)
+			assert.True(t, apierrors.IsNotFound(getErr), "case %q", tc.name)
+			continue
+		}
+		assert.NoError(t, err, "case %q", tc.name)
+		assert.NoError(t, getErr, "case %q", tc.name)
+		assert.Equal(t, tc.input.Volumes, restore.Spec.Volumes, "case %q", tc.name)
+		assert.Equal(t, tc.expectedMode, restore.Spec.VolumeRestoreMode, "case %q", tc.name)
+		assert.Equal(t, "vm", restore.Spec.Target.Name, "case %q", tc.name)
+		assert.False(t, restore.Spec.NewVM, "case %q", tc.name)
+	}
+}
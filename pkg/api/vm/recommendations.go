@@ -0,0 +1,83 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/v3/pkg/schemas/validation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	virtconfig "kubevirt.io/kubevirt/pkg/virt-config"
+
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/rightsizing"
+)
+
+// recommendations writes the sockets and memory suggested from the observed usage of the VM,
+// which can be posted to the cpuAndMemoryHotplug action as they are.
+func (h *vmActionHandler) recommendations(ctx context.Context, rw http.ResponseWriter, namespace, name string) error {
+	vm, err := h.vmCache.Get(namespace, name)
+	if err != nil {
+		return apierror.NewAPIError(validation.NotFound, fmt.Sprintf("Failed to get virtual machine %s/%s: %v", namespace, name, err))
+	}
+
+	// the usage ConfigMap is fetched directly rather than caching the ConfigMaps of all the namespaces
+	usage := &rightsizing.Usage{}
+	configMap, err := h.clientSet.CoreV1().ConfigMaps(namespace).Get(ctx, rightsizing.UsageConfigMapName(name), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get the resource usage of VM %s/%s: %v", namespace, name, err))
+	}
+	if err == nil && metav1.IsControlledBy(configMap, vm) {
+		usage, err = rightsizing.UnmarshalUsage(configMap.Data[rightsizing.UsageConfigMapKey])
+	}
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to parse the resource usage of VM %s/%s: %v", namespace, name, err))
+	}
+
+	vmi, err := h.vmiCache.Get(namespace, name)
+	if apierrors.IsNotFound(err) {
+		vmi = nil
+	} else if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get virtual machine instance %s/%s: %v", namespace, name, err))
+	}
+
+	maxHotplugRatio, err := h.getMaxHotplugRatio()
+	if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get the max hotplug ratio: %v", err))
+	}
+
+	recommendation, err := rightsizing.Recommend(vm, vmi, usage, maxHotplugRatio)
+	if errors.Is(err, rightsizing.ErrNotEnoughSamples) {
+		return apierror.NewAPIError(validation.InvalidState,
+			fmt.Sprintf("VM %s/%s has %d usage samples, at least %d samples are needed for the recommendations",
+				namespace, name, len(usage.Samples), rightsizing.MinSamples))
+	} else if err != nil {
+		return apierror.NewAPIError(validation.ServerError, err.Error())
+	}
+	recommendation.Hotpluggable = canCPUAndMemoryHotplug(vm)
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	return json.NewEncoder(rw).Encode(recommendation)
+}
+
+// getMaxHotplugRatio returns the max hotplug ratio KubeVirt defaults the maxSockets and maxGuest of the VMs with
+func (h *vmActionHandler) getMaxHotplugRatio() (uint32, error) {
+	kubevirt, err := h.kubevirtCache.Get(util.HarvesterSystemNamespaceName, util.KubeVirtObjectName)
+	if err != nil {
+		return 0, err
+	}
+	return maxHotplugRatioOf(kubevirt), nil
+}
+
+func maxHotplugRatioOf(kubevirt *kubevirtv1.KubeVirt) uint32 {
+	if kubevirt.Spec.Configuration.LiveUpdateConfiguration != nil && kubevirt.Spec.Configuration.LiveUpdateConfiguration.MaxHotplugRatio != 0 {
+		return kubevirt.Spec.Configuration.LiveUpdateConfiguration.MaxHotplugRatio
+	}
+	return virtconfig.DefaultMaxHotplugRatio
+}
@@ -0,0 +1,105 @@
+package vm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+	"github.com/harvester/harvester/pkg/util/rightsizing"
+)
+
+func TestRecommendations(t *testing.T) {
+	guest := resource.MustParse("16Gi")
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "vm",
+			UID:         "vm-uid",
+			Annotations: map[string]string{util.AnnotationEnableCPUAndMemoryHotplug: "true"},
+		},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Domain: kubevirtv1.DomainSpec{
+						CPU:    &kubevirtv1.CPU{Cores: 1, Threads: 1, Sockets: 8},
+						Memory: &kubevirtv1.Memory{Guest: &guest},
+					},
+				},
+			},
+		},
+		Status: kubevirtv1.VirtualMachineStatus{PrintableStatus: kubevirtv1.VirtualMachineStatusRunning},
+	}
+	newUsageConfigMap := func(samples int) *corev1.ConfigMap {
+		usage := &rightsizing.Usage{}
+		for i := 0; i < samples; i++ {
+			usage.AddSample(rightsizing.Sample{Time: int64(i * 300), CPU: 1500, Memory: 2 * 1024 * 1024 * 1024})
+		}
+		data, _ := rightsizing.MarshalUsage(usage)
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            rightsizing.UsageConfigMapName("vm"),
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(vm, kubevirtv1.VirtualMachineGroupVersionKind)},
+			},
+			Data: map[string]string{rightsizing.UsageConfigMapKey: data},
+		}
+	}
+	kubevirt := &kubevirtv1.KubeVirt{
+		ObjectMeta: metav1.ObjectMeta{Namespace: util.HarvesterSystemNamespaceName, Name: util.KubeVirtObjectName},
+		Spec: kubevirtv1.KubeVirtSpec{
+			Configuration: kubevirtv1.KubeVirtConfiguration{
+				LiveUpdateConfiguration: &kubevirtv1.LiveUpdateConfiguration{MaxHotplugRatio: 2},
+			},
+		},
+	}
+
+	newHandler := func(objects ...runtime.Object) *vmActionHandler {
+		clientset := fake.NewSimpleClientset(vm, kubevirt)
+		return &vmActionHandler{
+			clientSet:     corefake.NewSimpleClientset(objects...),
+			kubevirtCache: fakeclients.KubeVirtCache(clientset.KubevirtV1().KubeVirts),
+			vmCache:       fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+			vmiCache:      fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+		}
+	}
+
+	t.Run("recommend from the usage", func(t *testing.T) {
+		h := newHandler(newUsageConfigMap(rightsizing.MinSamples))
+		rw := httptest.NewRecorder()
+
+		assert.NoError(t, h.recommendations(t.Context(), rw, "default", "vm"))
+		assert.Equal(t, http.StatusOK, rw.Code)
+		recommendation := &rightsizing.Recommendation{}
+		assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), recommendation))
+		assert.Equal(t, uint32(2), recommendation.Sockets)
+		assert.Equal(t, "2560Mi", recommendation.Memory)
+		assert.Equal(t, uint32(16), recommendation.MaxSockets)
+		assert.Equal(t, "32Gi", recommendation.MaxMemory)
+		assert.True(t, recommendation.Hotpluggable)
+		assert.True(t, recommendation.RestartRequired)
+	})
+
+	t.Run("not enough samples", func(t *testing.T) {
+		h := newHandler(newUsageConfigMap(1))
+
+		assert.Error(t, h.recommendations(t.Context(), httptest.NewRecorder(), "default", "vm"))
+	})
+
+	t.Run("no usage configmap", func(t *testing.T) {
+		h := newHandler()
+
+		assert.Error(t, h.recommendations(t.Context(), httptest.NewRecorder(), "default", "vm"))
+	})
+}
@@ -7,13 +7,20 @@ import (
 	"github.com/rancher/steve/pkg/schema"
 	"github.com/rancher/steve/pkg/server"
 	"github.com/rancher/steve/pkg/stores/proxy"
+	"github.com/rancher/wrangler/v3/pkg/generated/controllers/core"
+	"github.com/rancher/wrangler/v3/pkg/generated/controllers/storage"
 	"github.com/rancher/wrangler/v3/pkg/schemas"
 	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
 	"github.com/harvester/harvester/pkg/config"
 	"github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
 	virtv1 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/generated/controllers/cdi.kubevirt.io"
+	"github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io"
+	cni "github.com/harvester/harvester/pkg/generated/controllers/k8s.cni.cncf.io"
+	"github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io"
 	"github.com/harvester/harvester/pkg/image/common"
 	harvesterServer "github.com/harvester/harvester/pkg/server/http"
 )
@@ -38,82 +45,37 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 	server.BaseSchemas.MustImportAndCustomize(CreateTemplateInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(AddVolumeInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(RemoveVolumeInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(ExpandVolumeInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(AddNicInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(RemoveNicInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(CloneInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(CPUAndMemoryHotplugInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(StorageMigrationInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(ExportVMInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(BulkActionInput{}, nil)
+
+	actionHandler, err := newVMActionHandler(actionHandlerFactories{
+		virt:          scaled.VirtFactory,
+		harvester:     scaled.HarvesterFactory,
+		core:          scaled.CoreFactory,
+		storage:       scaled.StorageFactory,
+		cni:           scaled.CniFactory,
+		cdi:           scaled.CdiFactory,
+		resourceQuota: scaled.Management.HarvesterFactory,
+	}, scaled.Management.ClientSet, server.RESTConfig, options.Namespace)
+	if err != nil {
+		return err
+	}
+	handler := harvesterServer.NewHandler(actionHandler)
 
-	kubevirtCache := scaled.VirtFactory.Kubevirt().V1().KubeVirt().Cache()
 	vms := scaled.VirtFactory.Kubevirt().V1().VirtualMachine()
 	vmis := scaled.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
-	vmims := scaled.VirtFactory.Kubevirt().V1().VirtualMachineInstanceMigration()
 	backups := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup()
-	restores := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineRestore()
 	settings := scaled.HarvesterFactory.Harvesterhci().V1beta1().Setting()
+	backupTargets := scaled.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 	nodes := scaled.CoreFactory.Core().V1().Node()
 	pvcs := scaled.CoreFactory.Core().V1().PersistentVolumeClaim()
-	pvs := scaled.CoreFactory.Core().V1().PersistentVolume()
-	pods := scaled.CoreFactory.Core().V1().Pod()
-	secrets := scaled.CoreFactory.Core().V1().Secret()
-	vmt := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplate()
-	vmtv := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplateVersion()
-	vmImages := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage()
 	storageClasses := scaled.StorageFactory.Storage().V1().StorageClass()
-	nads := scaled.CniFactory.K8s().V1().NetworkAttachmentDefinition()
-	resourceQuotas := scaled.Management.HarvesterFactory.Harvesterhci().V1beta1().ResourceQuota()
-
-	vmiOperator, err := common.GetVMIOperator(vmImages, vmImages.Cache(), storageClasses.Cache(), http.Client{})
-	if err != nil {
-		return err
-	}
-
-	copyConfig := rest.CopyConfig(server.RESTConfig)
-	copyConfig.GroupVersion = &kubevirtSubResouceGroupVersion
-	copyConfig.APIPath = "/apis"
-	copyConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
-	virtSubresourceClient, err := rest.RESTClientFor(copyConfig)
-	if err != nil {
-		return err
-	}
-	virtv1Client, err := virtv1.NewForConfig(copyConfig)
-	if err != nil {
-		return err
-	}
-	actionHandler := harvesterServer.NewHandler(&vmActionHandler{
-		namespace:                 options.Namespace,
-		clientSet:                 scaled.Management.ClientSet,
-		virtRestClient:            virtv1Client.RESTClient(),
-		virtSubresourceRestClient: virtSubresourceClient,
-		vmio:                      vmiOperator,
-
-		backupClient:            backups,
-		pvcClient:               pvcs,
-		resourceQuotaClient:     resourceQuotas,
-		restoreClient:           restores,
-		secretClient:            secrets,
-		vmClient:                vms,
-		vmImageClient:           vmImages,
-		vmTemplateClient:        vmt,
-		vmTemplateVersionClient: vmtv,
-		vmiClient:               vmis,
-		vmimClient:              vmims,
-
-		backupCache:       backups.Cache(),
-		kubevirtCache:     kubevirtCache,
-		nadCache:          nads.Cache(),
-		nodeCache:         nodes.Cache(),
-		podCache:          pods.Cache(),
-		pvCache:           pvs.Cache(),
-		pvcCache:          pvcs.Cache(),
-		secretCache:       secrets.Cache(),
-		settingCache:      settings.Cache(),
-		storageClassCache: storageClasses.Cache(),
-		vmCache:           vms.Cache(),
-		vmImageCache:      vmImages.Cache(),
-		vmiCache:          vmis.Cache(),
-		vmimCache:         vmims.Cache(),
-	})
 
 	vmformatter := vmformatter{
 		pvcCache:      pvcs.Cache(),
@@ -123,6 +85,7 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 		scCache:       storageClasses.Cache(),
 		vmBackupCache: backups.Cache(),
 		settingCache:  settings.Cache(),
+		btCache:       backupTargets.Cache(),
 		clientSet:     scaled.Management.ClientSet,
 	}
 
@@ -138,34 +101,43 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 		ID: vmSchemaID,
 		Customize: func(apiSchema *types.APISchema) {
 			apiSchema.ActionHandlers = map[string]http.Handler{
-				startVM:                          actionHandler,
-				stopVM:                           actionHandler,
-				restartVM:                        actionHandler,
-				softReboot:                       actionHandler,
-				insertCdRomVolume:                actionHandler,
-				ejectCdRomVolume:                 actionHandler,
-				pauseVM:                          actionHandler,
-				unpauseVM:                        actionHandler,
-				migrate:                          actionHandler,
-				abortMigration:                   actionHandler,
-				findMigratableNodes:              actionHandler,
-				backupVM:                         actionHandler,
-				snapshotVM:                       actionHandler,
-				restoreVM:                        actionHandler,
-				createTemplate:                   actionHandler,
-				addVolume:                        actionHandler,
-				removeVolume:                     actionHandler,
-				addNic:                           actionHandler,
-				removeNic:                        actionHandler,
-				findHotunpluggableNics:           actionHandler,
-				cloneVM:                          actionHandler,
-				forceStopVM:                      actionHandler,
-				dismissInsufficientResourceQuota: actionHandler,
-				updateResourceQuotaAction:        actionHandler,
-				deleteResourceQuotaAction:        actionHandler,
-				cpuAndMemoryHotplug:              actionHandler,
-				storageMigration:                 actionHandler,
-				cancelStorageMigration:           actionHandler,
+				startVM:                          handler,
+				stopVM:                           handler,
+				restartVM:                        handler,
+				softReboot:                       handler,
+				insertCdRomVolume:                handler,
+				ejectCdRomVolume:                 handler,
+				pauseVM:                          handler,
+				unpauseVM:                        handler,
+				migrate:                          handler,
+				abortMigration:                   handler,
+				findMigratableNodes:              handler,
+				backupVM:                         handler,
+				snapshotVM:                       handler,
+				restoreVM:                        handler,
+				createTemplate:                   handler,
+				addVolume:                        handler,
+				removeVolume:                     handler,
+				expandVolume:                     handler,
+				addNic:                           handler,
+				removeNic:                        handler,
+				findHotunpluggableNics:           handler,
+				cloneVM:                          handler,
+				forceStopVM:                      handler,
+				dismissInsufficientResourceQuota: handler,
+				updateResourceQuotaAction:        handler,
+				deleteResourceQuotaAction:        handler,
+				cpuAndMemoryHotplug:              handler,
+				storageMigration:                 handler,
+				cancelStorageMigration:           handler,
+				bulkAction:                       handler,
+				exportVM:                         handler,
+			}
+			apiSchema.LinkHandlers = map[string]http.Handler{
+				screenshotLink:       handler,
+				serialConsoleLogLink: handler,
+				recommendationsLink:  handler,
+				downloadExportLink:   handler,
 			}
 			apiSchema.ResourceActions = map[string]schemas.Action{
 				startVM:    {},
@@ -201,6 +173,9 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 				removeVolume: {
 					Input: "removeVolumeInput",
 				},
+				expandVolume: {
+					Input: "expandVolumeInput",
+				},
 				addNic: {
 					Input: "addNicInput",
 				},
@@ -224,7 +199,16 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 					Input: "storageMigrationInput",
 				},
 				cancelStorageMigration: {},
+				exportVM: {
+					Input: "exportVMInput",
+				},
+			}
+			apiSchema.CollectionActions = map[string]schemas.Action{
+				bulkAction: {
+					Input: "bulkActionInput",
+				},
 			}
+			apiSchema.CollectionFormatter = vmformatter.collectionFormatter
 		},
 		Formatter: vmformatter.formatter,
 		Store:     vmStore,
@@ -233,3 +217,96 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 	server.SchemaFactory.AddTemplate(t)
 	return nil
 }
+
+// actionHandlerFactories are the factories the VM action handler gets its clients and caches from,
+// the API uses the scaled ones and the bulk action controller uses the management ones.
+type actionHandlerFactories struct {
+	virt          *kubevirt.Factory
+	harvester     *harvesterhci.Factory
+	core          *core.Factory
+	storage       *storage.Factory
+	cni           *cni.Factory
+	cdi           *cdi.Factory
+	resourceQuota *harvesterhci.Factory
+}
+
+func newVMActionHandler(f actionHandlerFactories, clientSet kubernetes.Interface, restConfig *rest.Config, namespace string) (*vmActionHandler, error) {
+	kubevirtCache := f.virt.Kubevirt().V1().KubeVirt().Cache()
+	vms := f.virt.Kubevirt().V1().VirtualMachine()
+	vmis := f.virt.Kubevirt().V1().VirtualMachineInstance()
+	vmims := f.virt.Kubevirt().V1().VirtualMachineInstanceMigration()
+	backups := f.harvester.Harvesterhci().V1beta1().VirtualMachineBackup()
+	restores := f.harvester.Harvesterhci().V1beta1().VirtualMachineRestore()
+	settings := f.harvester.Harvesterhci().V1beta1().Setting()
+	backupTargets := f.harvester.Harvesterhci().V1beta1().BackupTarget()
+	nodes := f.core.Core().V1().Node()
+	pvcs := f.core.Core().V1().PersistentVolumeClaim()
+	pvs := f.core.Core().V1().PersistentVolume()
+	pods := f.core.Core().V1().Pod()
+	secrets := f.core.Core().V1().Secret()
+	vmt := f.harvester.Harvesterhci().V1beta1().VirtualMachineTemplate()
+	vmtv := f.harvester.Harvesterhci().V1beta1().VirtualMachineTemplateVersion()
+	vmImages := f.harvester.Harvesterhci().V1beta1().VirtualMachineImage()
+	storageClasses := f.storage.Storage().V1().StorageClass()
+	nads := f.cni.K8s().V1().NetworkAttachmentDefinition()
+	bulkActions := f.harvester.Harvesterhci().V1beta1().VirtualMachineBulkAction()
+	vmDownloaders := f.harvester.Harvesterhci().V1beta1().VirtualMachineDownloader()
+	resourceQuotas := f.resourceQuota.Harvesterhci().V1beta1().ResourceQuota()
+
+	vmiOperator, err := common.GetVMIOperator(vmImages, vmImages.Cache(), storageClasses.Cache(), http.Client{})
+	if err != nil {
+		return nil, err
+	}
+
+	copyConfig := rest.CopyConfig(restConfig)
+	copyConfig.GroupVersion = &kubevirtSubResouceGroupVersion
+	copyConfig.APIPath = "/apis"
+	copyConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	virtSubresourceClient, err := rest.RESTClientFor(copyConfig)
+	if err != nil {
+		return nil, err
+	}
+	virtv1Client, err := virtv1.NewForConfig(copyConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &vmActionHandler{
+		namespace:                 namespace,
+		clientSet:                 clientSet,
+		virtRestClient:            virtv1Client.RESTClient(),
+		virtSubresourceRestClient: virtSubresourceClient,
+		vmio:                      vmiOperator,
+		httpClient:                http.Client{},
+
+		backupClient:            backups,
+		bulkActionClient:        bulkActions,
+		dataVolumeClient:        f.cdi.Cdi().V1beta1().DataVolume(),
+		pvcClient:               pvcs,
+		resourceQuotaClient:     resourceQuotas,
+		restoreClient:           restores,
+		secretClient:            secrets,
+		vmClient:                vms,
+		vmDownloaderClient:      vmDownloaders,
+		vmImageClient:           vmImages,
+		vmTemplateClient:        vmt,
+		vmTemplateVersionClient: vmtv,
+		vmiClient:               vmis,
+		vmimClient:              vmims,
+
+		backupCache:       backups.Cache(),
+		backupTargetCache: backupTargets.Cache(),
+		kubevirtCache:     kubevirtCache,
+		nadCache:          nads.Cache(),
+		nodeCache:         nodes.Cache(),
+		podCache:          pods.Cache(),
+		pvCache:           pvs.Cache(),
+		pvcCache:          pvcs.Cache(),
+		secretCache:       secrets.Cache(),
+		settingCache:      settings.Cache(),
+		storageClassCache: storageClasses.Cache(),
+		vmCache:           vms.Cache(),
+		vmImageCache:      vmImages.Cache(),
+		vmiCache:          vmis.Cache(),
+		vmimCache:         vmims.Cache(),
+	}, nil
+}
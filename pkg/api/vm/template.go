@@ -2,20 +2,21 @@ package vm
 
 import (
 	"fmt"
-	"strings"
 
-	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
-	"github.com/harvester/harvester/pkg/util"
-	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/v3/pkg/schemas/validation"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/util/retry"
-	"k8s.io/utils/ptr"
 	kubevirtv1 "kubevirt.io/api/core/v1"
-	cdicommon "kubevirt.io/containerized-data-importer/pkg/controller/common"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/backup"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/templateversion"
 )
 
 // createTemplate creates a VirtualMachineTemplate and VirtualMachineTemplateVersion
@@ -57,17 +58,24 @@ func (h *vmActionHandler) createTemplate(namespace, name string, input CreateTem
 		return err
 	}
 
+	if input.Generalize {
+		if err = h.checkGeneralizable(vm); err != nil {
+			return err
+		}
+	}
+
 	vmt, err = h.createVMTemplate(namespace, input)
 	if err != nil {
 		return err
 	}
 
-	vmtv, err = h.createVMTemplateVersion(namespace, vm, vmt, keyPairIDs)
+	vmtv, err = h.createVMTemplateVersion(namespace, vm, vmt, keyPairIDs, input)
 	if err != nil {
 		return err
 	}
 
-	if input.WithData {
+	// the disks of the generalized template version are snapshotted and exported by the template controller
+	if input.WithData && !input.Generalize {
 		vmtv, err = h.createTemplateWithData(vm, vmtv)
 		if err != nil {
 			return err
@@ -77,6 +85,22 @@ func (h *vmActionHandler) createTemplate(namespace, name string, input CreateTem
 	return h.createSecrets(vmtv, vm)
 }
 
+// checkGeneralizable checks the guest of the running VM can be frozen before the disks are snapshotted,
+// otherwise the VM images of the generalized template version would only be crash-consistent.
+func (h *vmActionHandler) checkGeneralizable(vm *kubevirtv1.VirtualMachine) error {
+	vmi, err := h.vmiCache.Get(vm.Namespace, vm.Name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return apierror.NewAPIError(validation.ServerError, fmt.Sprintf("Failed to get virtual machine instance %s/%s: %v", vm.Namespace, vm.Name, err))
+	}
+	if vmi.Status.Phase == kubevirtv1.Running && !backup.IsGuestAgentConnected(vmi) {
+		return apierror.NewAPIError(validation.InvalidState,
+			fmt.Sprintf("The guest agent of virtual machine %s/%s must be connected to generalize the running virtual machine", vm.Namespace, vm.Name))
+	}
+	return nil
+}
+
 func (h *vmActionHandler) createTemplateWithData(vm *kubevirtv1.VirtualMachine, vmtv *harvesterv1.VirtualMachineTemplateVersion) (*harvesterv1.VirtualMachineTemplateVersion, error) {
 	var (
 		pvcMap     map[string]corev1.PersistentVolumeClaim
@@ -142,112 +166,17 @@ func (h *vmActionHandler) getPVCMap(vm *kubevirtv1.VirtualMachine) (pvcMap map[s
 	return pvcMap, nil
 }
 
-// Get Storage Class name from PVC or Backing Image
-// If the PVC has an annoation, which associates it with a BackingImage, then
-// this function will return the StorageClass name of the BackingImage. Otherwise it
-// will return the StorageClass name of the PVC itself.
-func (h *vmActionHandler) getSCNameFromPVC(pvc *corev1.PersistentVolumeClaim) (scName string, err error) {
-	if imageId, ok := pvc.Annotations[util.AnnotationImageID]; ok {
-		return h.getSCNameFromImgID(imageId)
-	}
-	if pvc.Spec.StorageClassName == nil {
-		return "", fmt.Errorf("failed to get the storage class name from PVC")
-	}
-	// There should really be a bit more logic here to deal with default storage
-	// classes properly.
-	return *pvc.Spec.StorageClassName, nil
-}
-
-// Get Storage Class Name from ImageID
-// Given an ImageID, find the corresponding VMImage and figure out it's
-// StorageClass name
-func (h *vmActionHandler) getSCNameFromImgID(imageId string) (scName string, err error) {
-	imageIdSplit := strings.Split(imageId, "/")
-	if len(imageIdSplit) != 2 {
-		return "", fmt.Errorf("malformed image ID: %s", imageId)
-	}
-
-	vmImage, err := h.vmImageCache.Get(imageIdSplit[0], imageIdSplit[1])
-	if err != nil {
-		return "", err
-	}
-
-	if scName, ok := vmImage.Annotations[util.AnnotationStorageClassName]; ok {
-		return scName, nil
-	}
-	return "", fmt.Errorf("VMImage %s/%s does not have an annotation %s", vmImage.Namespace, vmImage.Name, util.AnnotationStorageClassName)
-}
-
 func (h *vmActionHandler) sanitizeVolumes(templateVersion *harvesterv1.VirtualMachineTemplateVersion, pvcMap map[string]corev1.PersistentVolumeClaim, vmImageMap map[string]harvesterv1.VirtualMachineImage) error {
 	templateVersionCopy := templateVersion.DeepCopy()
-	vmSource := &templateVersionCopy.Spec.VM
-	var entries []util.VolumeClaimTemplateEntry
-	for index, volume := range vmSource.Spec.Template.Spec.Volumes {
-		if volume.PersistentVolumeClaim == nil {
-			continue
-		}
-
-		volumeClaimTemplate := h.generateVolumeClaimTemplate(index, templateVersion.Name, templateVersion.Namespace, volume.PersistentVolumeClaim.ClaimName, pvcMap, vmImageMap)
-		entries = append(entries, util.VolumeClaimTemplateEntry{PersistentVolumeClaim: volumeClaimTemplate})
-		vmSource.Spec.Template.Spec.Volumes[index].PersistentVolumeClaim.ClaimName = volumeClaimTemplate.Name
-	}
-
-	data, err := util.MarshalVolumeClaimTemplates(entries)
-	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
-			"namespace":       templateVersion.Namespace,
-			"templateVersion": templateVersion.Name,
-			"entries":         entries,
-		}).Error("Failed to marshal volume claim templates")
+	if err := h.templateVersionExporter().SetVolumeClaimTemplates(templateVersionCopy, pvcMap, vmImageMap); err != nil {
 		return err
 	}
-
-	if vmSource.ObjectMeta.Annotations == nil {
-		vmSource.ObjectMeta.Annotations = make(map[string]string)
-	}
-	vmSource.ObjectMeta.Annotations[util.AnnotationVolumeClaimTemplates] = data
 	if _, err := h.vmTemplateVersionClient.Update(templateVersionCopy); err != nil {
 		return err
 	}
 	return nil
 }
 
-// generate new volume template
-// - longhorn v1, we need to use the new storageclass Name (for backingImage)
-// - others, use the pvc StorageClassName
-func (h *vmActionHandler) generateVolumeClaimTemplate(index int, tvName, tvNamespace, claimName string, pvcMap map[string]corev1.PersistentVolumeClaim, vmImageMap map[string]harvesterv1.VirtualMachineImage) (pvc corev1.PersistentVolumeClaim) {
-	var targetSCName *string
-
-	vmImageName := getTemplateVersionVMImageName(tvName, index)
-	vmImage := vmImageMap[vmImageName]
-
-	claim := pvcMap[claimName]
-	if _, ok := claim.Annotations[cdicommon.AnnCreatedForDataVolume]; ok {
-		// no nil check, because if claim.Spec.StorageClassName is nil, it needs to
-		// be copied to the PVC template as well, so the PVC template can also use
-		// the default storage class
-		targetSCName = claim.Spec.StorageClassName
-	} else {
-		targetSCName = ptr.To(h.vmio.GetStorageClassName(&vmImage))
-	}
-
-	pvc = corev1.PersistentVolumeClaim{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: getTemplateVersionPvcName(tvName, index),
-			Annotations: map[string]string{
-				util.AnnotationImageID: fmt.Sprintf("%s/%s", tvNamespace, vmImageName),
-			},
-		},
-		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes:      claim.Spec.AccessModes,
-			Resources:        claim.Spec.Resources,
-			VolumeMode:       claim.Spec.VolumeMode,
-			StorageClassName: targetSCName,
-		},
-	}
-	return pvc
-}
-
 // Copy Credentials Secret
 func (h *vmActionHandler) copyCredentialsSecret(templateVersion *harvesterv1.VirtualMachineTemplateVersion, index int, credential *kubevirtv1.AccessCredential) (err error) {
 	sshPublicKey := credential.SSHPublicKey
@@ -329,13 +258,13 @@ func (h *vmActionHandler) createVMImages(templateVersion *harvesterv1.VirtualMac
 		}
 
 		claimName := volume.PersistentVolumeClaim.ClaimName
-		vmImageName := getTemplateVersionVMImageName(templateVersion.Name, index)
+		vmImageName := templateversion.VMImageName(templateVersion.Name, index)
 		ownerRef, err := util.GetOwnerReferenceFor(templateVersion)
 		if err != nil {
 			return nil, err
 		}
 
-		vmImage, err = h.createVMImage(vm.Namespace, vmImageName, claimName, *ownerRef)
+		vmImage, err = h.templateVersionExporter().CreateVMImage(vm.Namespace, vmImageName, claimName, *ownerRef)
 		if err != nil {
 			logrus.WithError(err).WithFields(logrus.Fields{
 				"namespace":       templateVersion.Namespace,
@@ -349,71 +278,8 @@ func (h *vmActionHandler) createVMImages(templateVersion *harvesterv1.VirtualMac
 	return vmImageMap, nil
 }
 
-func (h *vmActionHandler) createVMImage(namespace, name, claimName string, owner metav1.OwnerReference) (*harvesterv1.VirtualMachineImage, error) {
-	pvc, err := h.pvcCache.Get(namespace, claimName)
-	if err != nil {
-		return nil, err
-	}
-	targetSCName, err := h.getSCNameFromPVC(pvc)
-	if err != nil {
-		return nil, err
-	}
-	backend, err := h.getVMImageBackend(targetSCName, pvc)
-	if err != nil {
-		return nil, fmt.Errorf("failed to configure vm image backend for pvc %s", claimName)
-	}
-
-	vmImage, err := h.vmImageClient.Create(&harvesterv1.VirtualMachineImage{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-			Annotations: map[string]string{
-				util.AnnotationStorageClassName: targetSCName,
-			},
-			OwnerReferences: []metav1.OwnerReference{owner},
-		},
-		Spec: harvesterv1.VirtualMachineImageSpec{
-			Backend:                backend,
-			DisplayName:            name,
-			SourceType:             harvesterv1.VirtualMachineImageSourceTypeExportVolume,
-			PVCName:                claimName,
-			PVCNamespace:           namespace,
-			TargetStorageClassName: targetSCName,
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-	return vmImage, nil
-}
-
-// If target StorageClass name starts with `longhorn-templateversion-` use
-// backingImage
-// If target StorageClass uses does not use Longhorn as provisioner, use CDI
-// backend.
-// If target StorageClass uses Longhorn as provisioner with v2 data engine, use
-// CDI backend as well.
-// Otherwise (Longhorn as provisioner, with v1 data engine or no data engine
-// explicitly specified) use backingImage backend
-func (h *vmActionHandler) getVMImageBackend(targetSCName string, pvc *corev1.PersistentVolumeClaim) (harvesterv1.VMIBackend, error) {
-	if strings.HasPrefix(targetSCName, "longhorn-templateversion-") {
-		return harvesterv1.VMIBackendBackingImage, nil
-	}
-
-	targetSC, err := h.storageClassCache.Get(targetSCName)
-	if err != nil {
-		return "", err
-	}
-	provisioner := util.GetProvisionedPVCProvisioner(pvc, h.storageClassCache)
-	if provisioner != util.CSIProvisionerLonghorn {
-		return harvesterv1.VMIBackendCDI, nil
-	}
-
-	v := util.GetLonghornDataEngineType(targetSC)
-	if v == longhorn.DataEngineTypeV2 {
-		return harvesterv1.VMIBackendCDI, nil
-	}
-	return harvesterv1.VMIBackendBackingImage, nil
+func (h *vmActionHandler) templateVersionExporter() *templateversion.Exporter {
+	return templateversion.NewExporter(h.pvcCache, h.storageClassCache, h.vmImageClient, h.vmImageCache, h.vmio)
 }
 
 func (h *vmActionHandler) createVMTemplate(namespace string, input CreateTemplateInput) (vmt *harvesterv1.VirtualMachineTemplate, err error) {
@@ -437,15 +303,30 @@ func (h *vmActionHandler) createVMTemplate(namespace string, input CreateTemplat
 	return vmt, nil
 }
 
-func (h *vmActionHandler) createVMTemplateVersion(namespace string, vm *kubevirtv1.VirtualMachine, vmt *harvesterv1.VirtualMachineTemplate, keyPairIDs []string) (vmtv *harvesterv1.VirtualMachineTemplateVersion, err error) {
+func (h *vmActionHandler) createVMTemplateVersion(namespace string, vm *kubevirtv1.VirtualMachine, vmt *harvesterv1.VirtualMachineTemplate, keyPairIDs []string, input CreateTemplateInput) (vmtv *harvesterv1.VirtualMachineTemplateVersion, err error) {
 	name := fmt.Sprintf("%s-%s", vmt.Name, rand.String(6))
 	vmtID := fmt.Sprintf("%s/%s", vmt.Namespace, vmt.Name)
-	vmSourceSpec := h.sanitizeVirtualMachineForTemplateVersion(name, vm)
+	vmSourceSpec := h.sanitizeVirtualMachineForTemplateVersion(name, vm, input.Generalize)
+
+	var annotations map[string]string
+	if input.Generalize {
+		generalize, err := templateversion.MarshalGeneralize(&templateversion.Generalize{
+			VM:      vm.Name,
+			Sysprep: input.Sysprep,
+			Phase:   templateversion.GeneralizePending,
+		})
+		if err != nil {
+			return nil, err
+		}
+		annotations = map[string]string{util.AnnotationTemplateGeneralize: generalize}
+	}
+
 	vmtv, err = h.vmTemplateVersionClient.Create(
 		&harvesterv1.VirtualMachineTemplateVersion{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      name,
-				Namespace: namespace,
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: annotations,
 			},
 			Spec: harvesterv1.VirtualMachineTemplateVersionSpec{
 				TemplateID:  vmtID,
@@ -529,7 +410,7 @@ func (h *vmActionHandler) cleanupVMImages(templateVersion *harvesterv1.VirtualMa
 			continue
 		}
 
-		vmImageName := getTemplateVersionVMImageName(templateVersion.Name, index)
+		vmImageName := templateversion.VMImageName(templateVersion.Name, index)
 		image, err := h.vmImageCache.Get(vm.Namespace, vmImageName)
 		if err != nil {
 			logrus.WithError(err).WithFields(logrus.Fields{
@@ -551,12 +432,16 @@ func (h *vmActionHandler) cleanupVMImages(templateVersion *harvesterv1.VirtualMa
 	return nil
 }
 
-// sanitizeVirtualMachineForTemplateVersion removes mac addresses and changes secrets names.
+// sanitizeVirtualMachineForTemplateVersion removes mac addresses and changes secrets names, the hostname is
+// removed as well if the template version is generalized.
 // The PVCs are not sanitized in the function because users may want to create template with data.
 // This will change storage class name in PVCs, so we handle it with VMImages.
-func (h *vmActionHandler) sanitizeVirtualMachineForTemplateVersion(templateVersionName string, vm *kubevirtv1.VirtualMachine) harvesterv1.VirtualMachineSourceSpec {
+func (h *vmActionHandler) sanitizeVirtualMachineForTemplateVersion(templateVersionName string, vm *kubevirtv1.VirtualMachine, generalize bool) harvesterv1.VirtualMachineSourceSpec {
 	sanitizedVM := removeMacAddresses(vm)
 	sanitizedVM = replaceSecrets(templateVersionName, sanitizedVM)
+	if generalize {
+		sanitizedVM.Spec.Template.Spec.Hostname = ""
+	}
 
 	return harvesterv1.VirtualMachineSourceSpec{
 		ObjectMeta: sanitizedVM.ObjectMeta,
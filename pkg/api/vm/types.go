@@ -1,6 +1,10 @@
 package vm
 
-import "github.com/rancher/wrangler/v3/pkg/condition"
+import (
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
 
 var (
 	vmiPaused condition.Cond = "Paused"
@@ -16,22 +20,36 @@ type EjectCdRomVolumeActionInput struct {
 }
 
 type BackupInput struct {
-	Name string `json:"name"`
+	Name             string `json:"name"`
+	BackupTargetName string `json:"backupTargetName,omitempty"`
+	// ApplicationConsistent freezes the guest file systems while taking volume snapshots
+	ApplicationConsistent *harvesterv1.ApplicationConsistentSpec `json:"applicationConsistent,omitempty"`
 }
 
 type RestoreInput struct {
 	Name       string `json:"name"`
 	BackupName string `json:"backupName"`
+	// Volumes are the volumes to restore, all volumes are restored if it's empty
+	Volumes []string `json:"volumes,omitempty"`
+	// VolumeRestoreMode is how the selected volumes are restored, they are restored in place if it's empty
+	VolumeRestoreMode harvesterv1.VolumeRestoreMode `json:"volumeRestoreMode,omitempty"`
 }
 
 type MigrateInput struct {
 	NodeName string `json:"nodeName"`
+	// MigrationPolicy is the name of a policy in the vm-migration-policies setting, it's kept in the VM annotation
+	MigrationPolicy string `json:"migrationPolicy,omitempty"`
 }
 
 type CreateTemplateInput struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	WithData    bool   `json:"withData"`
+	// Generalize freezes the guest, snapshots the disks and exports the copies of the disks into VM images,
+	// the MAC addresses and the hostname are removed from the template version. It implies WithData.
+	Generalize bool `json:"generalize,omitempty"`
+	// Sysprep runs virt-sysprep on the copies of the disks before they're exported, it requires Generalize.
+	Sysprep bool `json:"sysprep,omitempty"`
 }
 
 type AddVolumeInput struct {
@@ -43,6 +61,12 @@ type RemoveVolumeInput struct {
 	DiskName string `json:"diskName"`
 }
 
+type ExpandVolumeInput struct {
+	DiskName string `json:"diskName"`
+	// Size is the new size of the volume, e.g. `20Gi`, it must be larger than the current size
+	Size string `json:"size"`
+}
+
 type AddNicInput struct {
 	InterfaceName string `json:"interfaceName"`
 	NetworkName   string `json:"networkName"`
@@ -58,14 +82,42 @@ type FindHotunpluggableNicsOutput struct {
 }
 
 type CloneInput struct {
+	// TargetVM is the name of the clone. With a count greater than 1 it's a pattern, e.g. `lab-{{ .Index }}`,
+	// the index is appended to it if it doesn't reference the index.
 	TargetVM    string `json:"targetVm"`
 	RunStrategy string `json:"runStrategy"`
+	// Count is the number of clones, one clone is created if it's not set
+	Count int `json:"count,omitempty"`
+	// StartIndex is the index of the first clone
+	StartIndex int `json:"startIndex,omitempty"`
+	// TargetNamespace is the namespace of the clones, it's the namespace of the source VM if it's empty
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	// TargetStorageClassName is the storage class of the cloned volumes, the volumes keep their storage class if it's empty
+	TargetStorageClassName string `json:"targetStorageClassName,omitempty"`
+	// Hostname is the hostname pattern of the clones, it's the name of the clone if it's empty
+	Hostname string `json:"hostname,omitempty"`
+	// UserData is the cloud-init user data template of the clones, e.g. `#cloud-config\nhostname: {{ .Name }}`,
+	// the clones keep the user data of the source VM if it's empty
+	UserData string `json:"userData,omitempty"`
 }
 
 type FindMigratableNodesOutput struct {
 	Nodes []string `json:"nodes"`
 }
 
+// BulkActionInput runs a VM action on the VMs in a namespace selected by a label selector or by names
+type BulkActionInput struct {
+	Namespace string `json:"namespace"`
+	Action    string `json:"action"`
+	// Input is the input of the action, e.g. `{"nodeName": "node1"}` for migrate
+	Input map[string]interface{} `json:"input,omitempty"`
+	// Selector is a label selector, e.g. `app=web,tier!=db`
+	Selector string   `json:"selector,omitempty"`
+	Names    []string `json:"names,omitempty"`
+	// Concurrency is the maximum number of VMs the action runs on at the same time
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
 type UpdateResourceQuotaInput struct {
 	TotalSnapshotSizeQuota string `json:"totalSnapshotSizeQuota"`
 }
@@ -79,3 +131,8 @@ type StorageMigrationInput struct {
 	SourceVolume string `json:"sourceVolume"`
 	TargetVolume string `json:"targetVolume"`
 }
+
+type ExportVMInput struct {
+	// Format is the format of the VM package, only `ova` is supported, the disks are converted to qcow2 in the package
+	Format string `json:"format,omitempty"`
+}
@@ -0,0 +1,29 @@
+package vmbackup
+
+import (
+	"github.com/rancher/apiserver/pkg/types"
+	"github.com/rancher/wrangler/v3/pkg/data/convert"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/backup"
+)
+
+const (
+	actionPromote = "promote"
+)
+
+func Formatter(request *types.APIRequest, resource *types.RawResource) {
+	resource.Actions = make(map[string]string, 1)
+	if request.AccessControl.CanCreate(request, resource.Schema) != nil {
+		return
+	}
+
+	vmBackup := &harvesterv1.VirtualMachineBackup{}
+	if err := convert.ToObj(resource.APIObject.Data(), vmBackup); err != nil {
+		return
+	}
+
+	if vmBackup.Spec.Type == harvesterv1.Snapshot && backup.IsBackupReady(vmBackup) {
+		resource.AddAction(request, actionPromote)
+	}
+}
@@ -0,0 +1,78 @@
+package vmbackup
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gorilla/mux"
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/v3/pkg/schemas/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/backup"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	harvesterServer "github.com/harvester/harvester/pkg/server/http"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+type ActionHandler struct {
+	vmBackups     ctlharvesterv1.VirtualMachineBackupClient
+	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache
+}
+
+func (h *ActionHandler) Do(ctx *harvesterServer.Ctx) (harvesterServer.ResponseBody, error) {
+	r := ctx.Req()
+
+	vars := util.EncodeVars(mux.Vars(r))
+	action := vars["action"]
+	name := vars["name"]
+	namespace := vars["namespace"]
+
+	switch action {
+	case actionPromote:
+		var input PromoteInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+		return h.promote(namespace, name, input)
+	default:
+		return nil, apierror.NewAPIError(validation.InvalidAction, "Unsupported action")
+	}
+}
+
+// promote creates a backup from a VM snapshot, the controller uploads the volume snapshots
+// of the VM snapshot to the backup target so the backup keeps the point-in-time of the snapshot.
+func (h *ActionHandler) promote(namespace, name string, input PromoteInput) (*harvesterv1.VirtualMachineBackup, error) {
+	snapshot, err := h.vmBackupCache.Get(namespace, name)
+	if err != nil {
+		return nil, apierror.NewAPIError(validation.NotFound, fmt.Sprintf("Failed to get vmbackup %s/%s: %v", namespace, name, err))
+	}
+	if snapshot.Spec.Type != harvesterv1.Snapshot {
+		return nil, apierror.NewAPIError(validation.InvalidAction, fmt.Sprintf("vmbackup %s/%s is not a snapshot", namespace, name))
+	}
+	if !backup.IsBackupReady(snapshot) {
+		return nil, apierror.NewAPIError(validation.InvalidAction, fmt.Sprintf("snapshot %s/%s is not ready", namespace, name))
+	}
+
+	backupName := input.Name
+	if backupName == "" {
+		backupName = fmt.Sprintf("%s-backup", name)
+	}
+
+	vmBackup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupName,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				util.AnnotationPromotedFrom: name,
+			},
+		},
+		Spec: harvesterv1.VirtualMachineBackupSpec{
+			Source:           snapshot.Spec.Source,
+			Type:             harvesterv1.Backup,
+			BackupTargetName: input.BackupTargetName,
+		},
+	}
+	return h.vmBackups.Create(vmBackup)
+}
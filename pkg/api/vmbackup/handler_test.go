@@ -0,0 +1,104 @@
+package vmbackup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func TestActionHandler_promote(t *testing.T) {
+	tests := []struct {
+		name         string
+		snapshot     *harvesterv1.VirtualMachineBackup
+		input        PromoteInput
+		expectedName string
+		expectError  bool
+	}{
+		{
+			name: "backup is named after the snapshot",
+			snapshot: &harvesterv1.VirtualMachineBackup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "snap"},
+				Spec: harvesterv1.VirtualMachineBackupSpec{
+					Source: corev1.TypedLocalObjectReference{Kind: "VirtualMachine", Name: "vm"},
+					Type:   harvesterv1.Snapshot,
+				},
+				Status: harvesterv1.VirtualMachineBackupStatus{ReadyToUse: ptr.To(true)},
+			},
+			input:        PromoteInput{BackupTargetName: "nfs-target"},
+			expectedName: "snap-backup",
+		},
+		{
+			name: "backup name is given",
+			snapshot: &harvesterv1.VirtualMachineBackup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "snap"},
+				Spec: harvesterv1.VirtualMachineBackupSpec{
+					Source: corev1.TypedLocalObjectReference{Kind: "VirtualMachine", Name: "vm"},
+					Type:   harvesterv1.Snapshot,
+				},
+				Status: harvesterv1.VirtualMachineBackupStatus{ReadyToUse: ptr.To(true)},
+			},
+			input:        PromoteInput{Name: "weekly"},
+			expectedName: "weekly",
+		},
+		{
+			name:        "snapshot doesn't exist",
+			expectError: true,
+		},
+		{
+			name: "promote a backup",
+			snapshot: &harvesterv1.VirtualMachineBackup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "snap"},
+				Spec:       harvesterv1.VirtualMachineBackupSpec{Type: harvesterv1.Backup},
+				Status:     harvesterv1.VirtualMachineBackupStatus{ReadyToUse: ptr.To(true)},
+			},
+			expectError: true,
+		},
+		{
+			name: "snapshot isn't ready",
+			snapshot: &harvesterv1.VirtualMachineBackup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "snap"},
+				Spec:       harvesterv1.VirtualMachineBackupSpec{Type: harvesterv1.Snapshot},
+				Status:     harvesterv1.VirtualMachineBackupStatus{ReadyToUse: ptr.To(false)},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			if tc.snapshot != nil {
+				require.NoError(t, clientset.Tracker().Add(tc.snapshot))
+			}
+			h := &ActionHandler{
+				vmBackups:     fakeclients.VMBackupClient(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+				vmBackupCache: fakeclients.VMBackupCache(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+			}
+
+			_, err := h.promote("default", "snap", tc.input)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			vmBackup, err := clientset.HarvesterhciV1beta1().VirtualMachineBackups("default").Get(context.TODO(), tc.expectedName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, harvesterv1.Backup, vmBackup.Spec.Type)
+			assert.Equal(t, tc.snapshot.Spec.Source, vmBackup.Spec.Source)
+			assert.Equal(t, tc.input.BackupTargetName, vmBackup.Spec.BackupTargetName)
+			assert.Equal(t, "snap", vmBackup.Annotations[util.AnnotationPromotedFrom])
+			assert.Nil(t, vmBackup.Status.ReadyToUse)
+		})
+	}
+}
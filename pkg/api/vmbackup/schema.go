@@ -0,0 +1,42 @@
+package vmbackup
+
+import (
+	"net/http"
+
+	"github.com/rancher/apiserver/pkg/types"
+	"github.com/rancher/steve/pkg/schema"
+	"github.com/rancher/steve/pkg/server"
+	"github.com/rancher/wrangler/v3/pkg/schemas"
+
+	"github.com/harvester/harvester/pkg/config"
+	harvesterServer "github.com/harvester/harvester/pkg/server/http"
+)
+
+const (
+	vmBackupSchemaID = "harvesterhci.io.virtualmachinebackup"
+)
+
+func RegisterSchema(scaled *config.Scaled, server *server.Server, _ config.Options) error {
+	server.BaseSchemas.MustImportAndCustomize(PromoteInput{}, nil)
+	actionHandler := harvesterServer.NewHandler(&ActionHandler{
+		vmBackups:     scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup(),
+		vmBackupCache: scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup().Cache(),
+	})
+
+	t := schema.Template{
+		ID: vmBackupSchemaID,
+		Customize: func(s *types.APISchema) {
+			s.ResourceActions = map[string]schemas.Action{
+				actionPromote: {
+					Input: "promoteInput",
+				},
+			}
+			s.ActionHandlers = map[string]http.Handler{
+				actionPromote: actionHandler,
+			}
+		},
+		Formatter: Formatter,
+	}
+	server.SchemaFactory.AddTemplate(t)
+	return nil
+}
@@ -0,0 +1,6 @@
+package vmbackup
+
+type PromoteInput struct {
+	Name             string `json:"name"`
+	BackupTargetName string `json:"backupTargetName"`
+}
@@ -20,6 +20,14 @@ const (
 
 	// BackupConditionMetadataReady is the "metadataReady" condition type
 	BackupConditionMetadataReady condition.Cond = "MetadataReady"
+
+	// BackupConditionApplicationConsistent is the "applicationConsistent" condition type,
+	// it records whether the guest file systems are frozen while taking volume snapshots
+	BackupConditionApplicationConsistent condition.Cond = "ApplicationConsistent"
+
+	// BackupConditionQueued is the "queued" condition type, a manual backup waits for the backup window
+	// or the concurrency limit of the backup-throttle setting while it's true
+	BackupConditionQueued condition.Cond = "Queued"
 )
 
 // DeletionPolicy defines that to do with resources when VirtualMachineRestore is deleted
@@ -36,6 +44,18 @@ const (
 
 type BackupType string
 
+// VolumeRestoreMode defines how the selected volumes of VirtualMachineRestore are restored
+type VolumeRestoreMode string
+
+const (
+	// VolumeRestoreModeStandalone restores the selected volumes as standalone PVCs without touching any VM
+	VolumeRestoreModeStandalone VolumeRestoreMode = "standalone"
+
+	// VolumeRestoreModeInPlace replaces the selected volumes of the existing target VM,
+	// other volumes of the VM are left untouched
+	VolumeRestoreModeInPlace VolumeRestoreMode = "inPlace"
+)
+
 const (
 	Backup   BackupType = "backup"
 	Snapshot BackupType = "snapshot"
@@ -66,6 +86,54 @@ type VirtualMachineBackupSpec struct {
 	// +kubebuilder:validation:Enum=backup;snapshot
 	// +kubebuilder:validation:Optional
 	Type BackupType `json:"type,omitempty" default:"backup"`
+
+	// BackupTargetName is the name of the BackupTarget to store the backup.
+	// The backup-target setting is used if it's empty. Only works for backup type.
+	// +optional
+	BackupTargetName string `json:"backupTargetName,omitempty"`
+
+	// ApplicationConsistent freezes the guest file systems through the qemu-guest-agent
+	// before taking volume snapshots and thaws them afterwards.
+	// The backup is crash-consistent if it's empty.
+	// +optional
+	ApplicationConsistent *ApplicationConsistentSpec `json:"applicationConsistent,omitempty"`
+}
+
+type ApplicationConsistentSpec struct {
+	// FreezeTimeoutSeconds is the maximum time the guest file systems are frozen,
+	// the qemu-guest-agent thaws them automatically once it's exceeded.
+	// +kubebuilder:default:=60
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=600
+	// +optional
+	FreezeTimeoutSeconds int `json:"freezeTimeoutSeconds,omitempty" default:"60"`
+}
+
+// GuestHooks are the commands run in the guest around the freeze of application-consistent backups.
+// They are defined in the harvesterhci.io/backupGuestHooks annotation of the VM rather than the backup,
+// so that only the users who can edit the VM decide what runs in its guest.
+type GuestHooks struct {
+	// PreFreezeHook runs in the guest before freezing the file systems, e.g. to flush database tables.
+	// The file systems aren't frozen if it fails.
+	// +optional
+	PreFreezeHook *GuestHook `json:"preFreezeHook,omitempty"`
+
+	// PostThawHook runs in the guest after thawing the file systems.
+	// +optional
+	PostThawHook *GuestHook `json:"postThawHook,omitempty"`
+}
+
+// GuestHook is a command executed in the guest through the qemu-guest-agent
+type GuestHook struct {
+	// Command is the executable path and its arguments, it's not run in a shell.
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+
+	// +kubebuilder:default:=30
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=600
+	// +optional
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty" default:"30"`
 }
 
 // VirtualMachineBackupStatus is the status for a VirtualMachineBackup resource
@@ -77,7 +145,7 @@ type VirtualMachineBackupStatus struct {
 	CreationTime *metav1.Time `json:"creationTime,omitempty"`
 
 	// +optional
-	BackupTarget *BackupTarget `json:"backupTarget,omitempty"`
+	BackupTarget *BackupTargetInfo `json:"backupTarget,omitempty"`
 
 	// +optional
 	CSIDriverVolumeSnapshotClassNames map[string]string `json:"csiDriverVolumeSnapshotClassNames,omitempty"`
@@ -104,8 +172,11 @@ type VirtualMachineBackupStatus struct {
 	Conditions []Condition `json:"conditions,omitempty"`
 }
 
-// BackupTarget is where VM Backup stores
-type BackupTarget struct {
+// BackupTargetInfo is where VM Backup stores.
+// It was named BackupTarget before the BackupTarget CRD, whose Go type has to be named after its kind.
+type BackupTargetInfo struct {
+	// Name is the name of the BackupTarget, it's empty for the backup-target setting
+	Name         string `json:"name,omitempty"`
 	Endpoint     string `json:"endpoint,omitempty"`
 	BucketName   string `json:"bucketName,omitempty"`
 	BucketRegion string `json:"bucketRegion,omitempty"`
@@ -211,6 +282,30 @@ type VirtualMachineRestoreSpec struct {
 	// KeepMacAddress only works when NewVM is true.
 	// For replacing original VM, the macaddress will be the same.
 	KeepMacAddress bool `json:"keepMacAddress,omitempty"`
+
+	// Volumes are the names of volumes in the VirtualMachineBackup to restore.
+	// All volumes are restored if it's empty.
+	// +optional
+	Volumes []string `json:"volumes,omitempty"`
+
+	// VolumeRestoreMode is required when Volumes is set. The target is ignored in standalone mode.
+	// +kubebuilder:validation:Enum=standalone;inPlace
+	// +optional
+	VolumeRestoreMode VolumeRestoreMode `json:"volumeRestoreMode,omitempty"`
+
+	// NamespaceMapping maps the namespaces of the resources referenced by the backup VM,
+	// e.g. network attachment definitions and images, to the namespaces in this cluster.
+	// +optional
+	NamespaceMapping map[string]string `json:"namespaceMapping,omitempty"`
+
+	// StorageClassMapping maps the storage classes of the backup volumes to the storage classes of the restored PVCs.
+	// +optional
+	StorageClassMapping map[string]string `json:"storageClassMapping,omitempty"`
+
+	// NetworkMapping maps the network attachment definitions of the backup VM in `namespace/name` format
+	// to the network attachment definitions in this cluster, it takes precedence over NamespaceMapping.
+	// +optional
+	NetworkMapping map[string]string `json:"networkMapping,omitempty"`
 }
 
 // VirtualMachineRestoreStatus is the spec for a VirtualMachineRestore resource
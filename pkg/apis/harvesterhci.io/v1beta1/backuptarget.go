@@ -0,0 +1,91 @@
+package v1beta1
+
+import (
+	"github.com/rancher/wrangler/v3/pkg/condition"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// BackupTargetConditionAvailable is true when the backup target is configured in Longhorn and reachable
+	BackupTargetConditionAvailable condition.Cond = "Available"
+
+	// BackupTargetConditionMetadataSynced is true when the VM backup metadata in the backup target has been synced
+	BackupTargetConditionMetadataSynced condition.Cond = "MetadataSynced"
+)
+
+// +enum
+type BackupTargetType string
+
+const (
+	BackupTargetTypeS3  BackupTargetType = "s3"
+	BackupTargetTypeNFS BackupTargetType = "nfs"
+)
+
+// BackupTarget is a named remote storage (S3 or NFS) that VM backups can be stored to.
+// Multiple backup targets can exist at the same time, VirtualMachineBackup and ScheduleVMBackup
+// reference one of them by name. When no backup target is referenced, the backup-target setting is used.
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=bt;bts,scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="TYPE",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="ENDPOINT",type=string,JSONPath=`.spec.endpoint`
+// +kubebuilder:printcolumn:name="BUCKET",type=string,JSONPath=`.spec.bucketName`
+// +kubebuilder:printcolumn:name="AVAILABLE",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+type BackupTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupTargetSpec   `json:"spec"`
+	Status BackupTargetStatus `json:"status,omitempty"`
+}
+
+type BackupTargetSpec struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=s3;nfs
+	Type BackupTargetType `json:"type"`
+
+	// For NFS, the endpoint is the NFS server address and path, e.g. nfs://10.0.0.1:/exports/backup.
+	// For S3, the endpoint is the optional S3 compatible server URL.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// +optional
+	BucketName string `json:"bucketName,omitempty"`
+
+	// +optional
+	BucketRegion string `json:"bucketRegion,omitempty"`
+
+	// CredentialSecret references a secret which contains the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys.
+	// It is required for S3 backup targets.
+	// +optional
+	CredentialSecret *corev1.SecretReference `json:"credentialSecret,omitempty"`
+
+	// +optional
+	Cert string `json:"cert,omitempty"`
+
+	// +optional
+	VirtualHostedStyle bool `json:"virtualHostedStyle,omitempty"`
+
+	// RefreshIntervalInSeconds is the interval to poll the backup target and resync the VM backup metadata.
+	// Zero means no periodical resync.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	RefreshIntervalInSeconds int64 `json:"refreshIntervalInSeconds,omitempty"`
+}
+
+type BackupTargetStatus struct {
+	// LonghornBackupTargetName is the name of the Longhorn BackupTarget object serving this backup target
+	// +optional
+	LonghornBackupTargetName string `json:"longhornBackupTargetName,omitempty"`
+
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
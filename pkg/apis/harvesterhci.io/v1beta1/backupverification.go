@@ -0,0 +1,106 @@
+package v1beta1
+
+import (
+	"github.com/rancher/wrangler/v3/pkg/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	BackupVerificationConditionRestored condition.Cond = "Restored"
+	BackupVerificationConditionBooted   condition.Cond = "Booted"
+)
+
+// +enum
+type BackupVerificationProbe string
+
+const (
+	// BackupVerificationProbeGuestAgent passes when the guest agent of the verification VM is connected
+	BackupVerificationProbeGuestAgent BackupVerificationProbe = "guestAgent"
+	// BackupVerificationProbeVMReady passes when the verification VM is ready, including its readiness probe if any
+	BackupVerificationProbeVMReady BackupVerificationProbe = "vmReady"
+)
+
+// +enum
+type BackupVerificationPhase string
+
+const (
+	BackupVerificationPhasePending   BackupVerificationPhase = "Pending"
+	BackupVerificationPhaseRestoring BackupVerificationPhase = "Restoring"
+	BackupVerificationPhaseBooting   BackupVerificationPhase = "Booting"
+	BackupVerificationPhaseSucceeded BackupVerificationPhase = "Succeeded"
+	BackupVerificationPhaseFailed    BackupVerificationPhase = "Failed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=vmbackupverification;vmbackupverifications,scope=Namespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.virtualMachineBackupName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Probe",type=string,JSONPath=`.spec.probe`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// VirtualMachineBackupVerification verifies a VirtualMachineBackup by restoring its volumes into a scratch namespace
+// and booting a VM from them with networking disabled.
+type VirtualMachineBackupVerification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineBackupVerificationSpec   `json:"spec"`
+	Status VirtualMachineBackupVerificationStatus `json:"status,omitempty"`
+}
+
+type VirtualMachineBackupVerificationSpec struct {
+	// VirtualMachineBackupName is the name of the VirtualMachineBackup in the same namespace to verify
+	// +kubebuilder:validation:Required
+	VirtualMachineBackupName string `json:"virtualMachineBackupName"`
+
+	VerificationOptions `json:",inline"`
+}
+
+// VerificationOptions configures how a backup is verified
+type VerificationOptions struct {
+	// ScratchNamespace is where the volumes are restored and the verification VM runs
+	// +optional
+	// +kubebuilder:default:="harvester-backup-verification"
+	ScratchNamespace string `json:"scratchNamespace,omitempty"`
+
+	// +optional
+	// +kubebuilder:default:=guestAgent
+	// +kubebuilder:validation:Enum=guestAgent;vmReady
+	Probe BackupVerificationProbe `json:"probe,omitempty"`
+
+	// TimeoutSeconds is the maximum duration of the whole verification, including the restore
+	// +optional
+	// +kubebuilder:default:=1800
+	// +kubebuilder:validation:Minimum=60
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
+type VirtualMachineBackupVerificationStatus struct {
+	// +optional
+	Phase BackupVerificationPhase `json:"phase,omitempty"`
+
+	// RestoreName is the name of the VirtualMachineRestore in the scratch namespace
+	// +optional
+	RestoreName string `json:"restoreName,omitempty"`
+
+	// VirtualMachineName is the name of the verification VM in the scratch namespace
+	// +optional
+	VirtualMachineName string `json:"virtualMachineName,omitempty"`
+
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +optional
+	RestoredTime *metav1.Time `json:"restoredTime,omitempty"`
+
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
@@ -12,6 +12,7 @@ var (
 	ImageRetryLimitExceeded condition.Cond = "RetryLimitExceeded"
 	BackingImageMissing     condition.Cond = "BackingImageMissing"
 	MetadataReady           condition.Cond = "MetadataReady"
+	ImageSignatureVerified  condition.Cond = "SignatureVerified"
 )
 
 // +genclient
@@ -21,6 +22,7 @@ var (
 // +kubebuilder:printcolumn:name="DISPLAY-NAME",type=string,JSONPath=`.spec.displayName`
 // +kubebuilder:printcolumn:name="SIZE",type=integer,JSONPath=`.status.size`
 // +kubebuilder:printcolumn:name="VIRTUALSIZE",type=integer,JSONPath=`.status.virtualSize`
+// +kubebuilder:printcolumn:name="DEPRECATED",type=boolean,JSONPath=`.spec.deprecated`
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=`.metadata.creationTimestamp`
 
 type VirtualMachineImage struct {
@@ -44,7 +46,7 @@ type VirtualMachineImageSpec struct {
 	DisplayName string `json:"displayName"`
 
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=download;upload;export-from-volume;restore;clone
+	// +kubebuilder:validation:Enum=download;upload;export-from-volume;restore;clone;registry
 	SourceType VirtualMachineImageSourceType `json:"sourceType"`
 
 	// +optional
@@ -53,9 +55,29 @@ type VirtualMachineImageSpec struct {
 	// +optional
 	PVCNamespace string `json:"pvcNamespace"`
 
+	// The URL of the image, or the reference of the container disk image when the source type is registry,
+	// e.g. docker://registry.example.com/images/ubuntu:22.04
 	// +optional
 	URL string `json:"url"`
 
+	// The secret in the same namespace holding the credentials and CA of the registry,
+	// the accessKeyId and secretKey keys are the username and password and the ca.crt key is the CA certificate.
+	// If it's empty, the credentials of the containerd-registry setting are used by the images in the harvester-system
+	// namespace, the images in other namespaces are pulled anonymously.
+	// +optional
+	RegistrySecretName string `json:"registrySecretName,omitempty"`
+
+	// The secret in the same namespace holding the credentials and CA of the download URL and the signature URL,
+	// the accessKeyId and secretKey keys are the basic auth username and password, the token key is the bearer token,
+	// the headers key holds the extra request headers with one "Name: value" per line and the ca.crt key is the CA certificate.
+	// +optional
+	DownloadSecretName string `json:"downloadSecretName,omitempty"`
+
+	// The detached signature of the downloaded image, the image is imported only after the signature is verified.
+	// It's not supported by the cdi backend.
+	// +optional
+	Signature *VirtualMachineImageSignature `json:"signature,omitempty"`
+
 	// +optional
 	Checksum string `json:"checksum"`
 
@@ -76,6 +98,11 @@ type VirtualMachineImageSpec struct {
 	// +optional
 	// +kubebuilder:validation:Optional
 	TargetStorageClassName string `json:"targetStorageClassName,omitempty"`
+
+	// The deprecated image is kept for the existing VMs, the new VMs using it are warned or refused by the
+	// deprecatedImagePolicy of the vm-image-lifecycle setting.
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
 }
 
 type VirtualMachineImageSecurityParameters struct {
@@ -90,6 +117,30 @@ type VirtualMachineImageSecurityParameters struct {
 	SourceImageNamespace string `json:"sourceImageNamespace"`
 }
 
+type VirtualMachineImageSignature struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=cosign;gpg
+	Type VirtualMachineImageSignatureType `json:"type"`
+
+	// The URL of the detached signature, a cosign signature is the base64 output of `cosign sign-blob`
+	// and a gpg signature is either binary or armored.
+	// +kubebuilder:validation:Required
+	URL string `json:"url"`
+
+	// The secret in the same namespace holding the public key in the publicKey key. It's ignored when
+	// the vm-image-signature-policy setting has a trusted public key.
+	// +optional
+	PublicKeySecretName string `json:"publicKeySecretName,omitempty"`
+}
+
+// +enum
+type VirtualMachineImageSignatureType string
+
+const (
+	VirtualMachineImageSignatureTypeCosign VirtualMachineImageSignatureType = "cosign"
+	VirtualMachineImageSignatureTypeGPG    VirtualMachineImageSignatureType = "gpg"
+)
+
 // +enum
 type VirtualMachineImageSourceType string
 
@@ -99,6 +150,30 @@ const (
 	VirtualMachineImageSourceTypeExportVolume VirtualMachineImageSourceType = "export-from-volume"
 	VirtualMachineImageSourceTypeRestore      VirtualMachineImageSourceType = "restore"
 	VirtualMachineImageSourceTypeClone        VirtualMachineImageSourceType = "clone"
+	VirtualMachineImageSourceTypeRegistry     VirtualMachineImageSourceType = "registry"
+)
+
+// +enum
+type VirtualMachineImageFormat string
+
+const (
+	VirtualMachineImageFormatQCOW2 VirtualMachineImageFormat = "qcow2"
+	VirtualMachineImageFormatRaw   VirtualMachineImageFormat = "raw"
+	VirtualMachineImageFormatISO   VirtualMachineImageFormat = "iso"
+	VirtualMachineImageFormatVMDK  VirtualMachineImageFormat = "vmdk"
+	VirtualMachineImageFormatVHD   VirtualMachineImageFormat = "vhd"
+	VirtualMachineImageFormatVHDX  VirtualMachineImageFormat = "vhdx"
+	VirtualMachineImageFormatVDI   VirtualMachineImageFormat = "vdi"
+	VirtualMachineImageFormatOVA   VirtualMachineImageFormat = "ova"
+)
+
+// +enum
+type VirtualMachineImageCompression string
+
+const (
+	VirtualMachineImageCompressionGzip VirtualMachineImageCompression = "gz"
+	VirtualMachineImageCompressionXZ   VirtualMachineImageCompression = "xz"
+	VirtualMachineImageCompressionZstd VirtualMachineImageCompression = "zst"
 )
 
 type VirtualMachineImageCryptoOperationType string
@@ -133,7 +208,7 @@ type VirtualMachineImageStatus struct {
 	StorageClassName string `json:"storageClassName,omitempty"`
 
 	// +optional
-	BackupTarget *BackupTarget `json:"backupTarget,omitempty"`
+	BackupTarget *BackupTargetInfo `json:"backupTarget,omitempty"`
 
 	// +optional
 	// +kubebuilder:default:=0
@@ -144,6 +219,19 @@ type VirtualMachineImageStatus struct {
 	// +kubebuilder:validation:Optional
 	LastFailedTime string `json:"lastFailedTime,omitempty"`
 
+	// The SHA-512 checksum of the image whose signature is verified, the imported image is pinned to it.
+	// +optional
+	VerifiedChecksum string `json:"verifiedChecksum,omitempty"`
+
+	// The format of the imported image detected from its content, the image is converted to the format
+	// preferred by the backend when they differ.
+	// +optional
+	SourceFormat VirtualMachineImageFormat `json:"sourceFormat,omitempty"`
+
+	// The compression of the imported image detected from its content, it's empty if the image isn't compressed.
+	// +optional
+	SourceCompression VirtualMachineImageCompression `json:"sourceCompression,omitempty"`
+
 	// +optional
 	Conditions []Condition `json:"conditions,omitempty"`
 
@@ -151,8 +239,33 @@ type VirtualMachineImageStatus struct {
 	// +optional
 	// +kubebuilder:validation:Optional
 	TargetStorageClassName string `json:"targetStorageClassName,omitempty"`
+
+	// The volumes and VM template versions using the image.
+	// +optional
+	Consumers []VirtualMachineImageConsumer `json:"consumers,omitempty"`
+
+	// The time the imported image has been unused since, it's empty while the image is used.
+	// +optional
+	UnusedSince string `json:"unusedSince,omitempty"`
+}
+
+type VirtualMachineImageConsumer struct {
+	// +kubebuilder:validation:Enum=PersistentVolumeClaim;VirtualMachineTemplateVersion
+	Kind VirtualMachineImageConsumerKind `json:"kind"`
+
+	Namespace string `json:"namespace"`
+
+	Name string `json:"name"`
 }
 
+// +enum
+type VirtualMachineImageConsumerKind string
+
+const (
+	VirtualMachineImageConsumerKindPVC             VirtualMachineImageConsumerKind = "PersistentVolumeClaim"
+	VirtualMachineImageConsumerKindTemplateVersion VirtualMachineImageConsumerKind = "VirtualMachineTemplateVersion"
+)
+
 type Condition struct {
 	// Type of the condition.
 	Type condition.Cond `json:"type"`
@@ -0,0 +1,142 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// +enum
+type ImageReplicationPhase string
+
+const (
+	// ImageReplicationPhaseExporting is exporting the source image and calculating the checksum of the export
+	ImageReplicationPhaseExporting ImageReplicationPhase = "Exporting"
+	// ImageReplicationPhaseImporting is importing the target image from the export
+	ImageReplicationPhaseImporting  ImageReplicationPhase = "Importing"
+	ImageReplicationPhaseReplicated ImageReplicationPhase = "Replicated"
+	ImageReplicationPhaseFailed     ImageReplicationPhase = "Failed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=imgrepl;imgrepls,scope=Namespaced
+// +kubebuilder:printcolumn:name="TargetNamespace",type=string,JSONPath=`.spec.target.namespace`
+// +kubebuilder:printcolumn:name="Remote",type=string,JSONPath=`.spec.target.kubeconfigSecretName`
+// +kubebuilder:printcolumn:name="Cron",type=string,JSONPath=`.spec.cron`
+// +kubebuilder:printcolumn:name="LastSync",type=date,JSONPath=`.status.lastSyncTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:subresource:status
+
+// ImageReplication replicates the selected VM images of the namespace to another namespace, or to a remote
+// cluster, so the same images don't have to be uploaded to every cluster. The source images are exported like
+// the image downloads and the replicated images are downloaded from the export.
+type ImageReplication struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ImageReplicationSpec   `json:"spec"`
+	Status ImageReplicationStatus `json:"status,omitempty"`
+}
+
+type ImageReplicationSpec struct {
+	// Selector is a label selector selecting the VM images in the namespace, e.g. `os=ubuntu`
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// Names are the names of the VM images in the namespace
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Target ImageReplicationTarget `json:"target"`
+
+	// Cron is the schedule the selected images are synced at, the replicated images deleted in the target are
+	// replicated again and the failed replications are retried. The images are synced as soon as they're
+	// imported or changed if it's empty, and the failed replications are retried when the replication is updated.
+	// +optional
+	Cron string `json:"cron,omitempty"`
+
+	// MirrorDeletion deletes the replicated images when the source images are deleted or not selected anymore,
+	// and when the replication is deleted. The replicated images are kept otherwise.
+	// +optional
+	// +kubebuilder:default:=false
+	MirrorDeletion bool `json:"mirrorDeletion"`
+
+	// +optional
+	// +kubebuilder:default:=false
+	Suspend bool `json:"suspend"`
+}
+
+type ImageReplicationTarget struct {
+	// Namespace is the namespace of the replicated images, it's the namespace of the replication if it's empty.
+	// It has to be another namespace when the images are replicated in this cluster.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// KubeconfigSecretName is the secret in the namespace of the replication holding the kubeconfig of the
+	// remote cluster in the kubeconfig key. The images are replicated in this cluster if it's empty.
+	// +optional
+	KubeconfigSecretName string `json:"kubeconfigSecretName,omitempty"`
+
+	// ExportURL is the URL of this cluster the remote cluster downloads the images from, e.g. https://192.168.1.10.
+	// It's required by the remote replications.
+	// +optional
+	ExportURL string `json:"exportURL,omitempty"`
+
+	// DownloadSecretName is the secret in the namespace of the replication holding the token and CA the remote
+	// cluster downloads the images with, it has the keys of the download secret of the VM images. It's copied to
+	// the target namespace of the remote cluster.
+	// +optional
+	DownloadSecretName string `json:"downloadSecretName,omitempty"`
+
+	// StorageClassName is the storage class of the replicated images, it's the default one of the target
+	// cluster if it's empty.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Backend is the backend of the replicated images, it's the backend of the source image if it's empty
+	// +optional
+	// +kubebuilder:validation:Enum=backingimage;cdi
+	Backend VMIBackend `json:"backend,omitempty"`
+}
+
+type ImageReplicationStatus struct {
+	// ObservedGeneration is the generation of the replication the images were synced with
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastScheduleTime is the schedule time of the last sync run by the cron
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSyncTime is the time the selected images were synced last time
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Images are the replications of the selected images
+	// +optional
+	Images []ReplicatedImage `json:"images,omitempty"`
+}
+
+type ReplicatedImage struct {
+	// Name is the name of the source image, the replicated image has the same name in the target namespace
+	Name string `json:"name"`
+
+	// SourceUID is the UID of the replicated source image, the image is replicated again if it's changed
+	// +optional
+	SourceUID types.UID `json:"sourceUID,omitempty"`
+
+	// +optional
+	Phase ImageReplicationPhase `json:"phase,omitempty"`
+
+	// Checksum is the SHA-512 checksum of the export, the replicated image verifies it when it's downloaded
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ReplicatedTime is the time the replicated image was imported
+	// +optional
+	ReplicatedTime *metav1.Time `json:"replicatedTime,omitempty"`
+}
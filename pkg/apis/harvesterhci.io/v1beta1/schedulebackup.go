@@ -7,6 +7,86 @@ import (
 
 var BackupSuspend condition.Cond = "BackupSuspend"
 
+// +enum
+type RetentionRule string
+
+const (
+	// RetentionRuleLast keeps the latest `.spec.retain` backups
+	RetentionRuleLast    RetentionRule = "last"
+	RetentionRuleHourly  RetentionRule = "hourly"
+	RetentionRuleDaily   RetentionRule = "daily"
+	RetentionRuleWeekly  RetentionRule = "weekly"
+	RetentionRuleMonthly RetentionRule = "monthly"
+)
+
+// RetentionPolicy is a grandfather-father-son retention policy.
+// For each rule, the newest ready backup in each of the latest N hours, days, weeks or months having ready backups is kept.
+// A backup is kept as long as one of the rules or `.spec.retain` keeps it.
+type RetentionPolicy struct {
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Hourly int `json:"hourly,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Daily int `json:"daily,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Weekly int `json:"weekly,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Monthly int `json:"monthly,omitempty"`
+}
+
+// BackupWindow is the daily time range in which scheduled backups are allowed to start.
+// The range wraps around midnight if End is before Start, e.g. 22:00-06:00.
+type BackupWindow struct {
+	// Start is the time of day in HH:MM format
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End is the time of day in HH:MM format
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+
+	// TimeZone is an IANA time zone name, e.g. Europe/Berlin, defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// +enum
+type BackupQueuedReason string
+
+const (
+	// BackupQueuedOutsideWindow means the backup waits for the backup window to open
+	BackupQueuedOutsideWindow BackupQueuedReason = "OutsideBackupWindow"
+	// BackupQueuedConcurrencyLimit means the backup waits for other volume backups of the cluster to complete
+	BackupQueuedConcurrencyLimit BackupQueuedReason = "ConcurrencyLimit"
+	// BackupQueuedPreviousInProgress means the backup waits for the previous backup of the schedule to complete
+	BackupQueuedPreviousInProgress BackupQueuedReason = "PreviousBackupInProgress"
+)
+
+// QueuedVMBackup is a scheduled backup which is deferred. A queued backup is replaced by a newer one
+// if the cron fires again before it starts, the skipped backup is recorded in an event.
+type QueuedVMBackup struct {
+	// Timestamp is the schedule time of the backup in the format of the VMBackup names
+	Timestamp string `json:"timestamp"`
+
+	// +optional
+	Reason BackupQueuedReason `json:"reason,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// QueuedAt is when the schedule fired
+	// +optional
+	QueuedAt *metav1.Time `json:"queuedAt,omitempty"`
+}
+
 type VolumeBackupInfo struct {
 	// +optional
 	Name *string `json:"name,omitempty"`
@@ -29,6 +109,10 @@ type VMBackupInfo struct {
 
 	// +optional
 	Error *Error `json:"error,omitempty"`
+
+	// RetainedBy lists the retention rules keeping the backup, only set when a retention policy is configured
+	// +optional
+	RetainedBy []RetentionRule `json:"retainedBy,omitempty"`
 }
 
 // +genclient
@@ -41,6 +125,8 @@ type VMBackupInfo struct {
 // +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.vmbackup.source.name`
 // +kubebuilder:printcolumn:name="Suspended",type=string,JSONPath=`.status.suspended`
 // +kubebuilder:printcolumn:name="Failure",type=integer,JSONPath=`.status.failure`
+// +kubebuilder:printcolumn:name="Running",type=string,JSONPath=`.status.runningVMBackup`
+// +kubebuilder:printcolumn:name="Queued",type=string,JSONPath=`.status.queuedVMBackup.reason`
 
 type ScheduleVMBackup struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -71,6 +157,28 @@ type ScheduleVMBackupSpec struct {
 
 	// +kubebuilder:validation:Required
 	VMBackupSpec VirtualMachineBackupSpec `json:"vmbackup"`
+
+	// RetentionPolicy keeps hourly, daily, weekly and monthly backups in addition to the latest `.spec.retain` backups
+	// +optional
+	RetentionPolicy *RetentionPolicy `json:"retentionPolicy,omitempty"`
+
+	// Verification creates a VirtualMachineBackupVerification for every Nth backup once it's ready
+	// +optional
+	Verification *ScheduleVMBackupVerification `json:"verification,omitempty"`
+
+	// Window overrides the window of the backup-throttle setting, backups fired outside of it are queued
+	// until it opens. It only applies to the backup type.
+	// +optional
+	Window *BackupWindow `json:"window,omitempty"`
+}
+
+type ScheduleVMBackupVerification struct {
+	// Every verifies one backup out of every N backups created by the schedule
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Every int `json:"every"`
+
+	VerificationOptions `json:",inline"`
 }
 
 type ScheduleVMBackupStatus struct {
@@ -83,6 +191,14 @@ type ScheduleVMBackupStatus struct {
 	// +optional
 	Suspended bool `json:"suspended,omitempty"`
 
+	// RunningVMBackup is the name of the VMBackup of the schedule in progress
+	// +optional
+	RunningVMBackup string `json:"runningVMBackup,omitempty"`
+
+	// QueuedVMBackup is the backup waiting for the backup window or the concurrency limit
+	// +optional
+	QueuedVMBackup *QueuedVMBackup `json:"queuedVMBackup,omitempty"`
+
 	// +optional
 	Conditions []Condition `json:"conditions,omitempty"`
 }
@@ -0,0 +1,89 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +enum
+type VMPowerAction string
+
+const (
+	VMPowerActionStart      VMPowerAction = "start"
+	VMPowerActionStop       VMPowerAction = "stop"
+	VMPowerActionRestart    VMPowerAction = "restart"
+	VMPowerActionSoftReboot VMPowerAction = "softreboot"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=svmpoweraction;svmpoweractions,scope=Namespaced
+// +kubebuilder:printcolumn:name="Cron",type=string,JSONPath=`.spec.cron`
+// +kubebuilder:printcolumn:name="Action",type=string,JSONPath=`.spec.action`
+// +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="LastSchedule",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ScheduleVMPowerAction starts, stops, restarts or soft reboots the selected VMs on a cron schedule,
+// e.g. to stop dev VMs at night and start them in the morning.
+type ScheduleVMPowerAction struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduleVMPowerActionSpec   `json:"spec"`
+	Status ScheduleVMPowerActionStatus `json:"status,omitempty"`
+}
+
+type ScheduleVMPowerActionSpec struct {
+	// +kubebuilder:validation:Required
+	Cron string `json:"cron"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=start;stop;restart;softreboot
+	Action VMPowerAction `json:"action"`
+
+	// Selector is a label selector selecting the VMs in the namespace, e.g. `env=dev`
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// Names are the names of the VMs in the namespace
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// +optional
+	// +kubebuilder:default:=false
+	Suspend bool `json:"suspend"`
+
+	// HistoryLimit is the number of the latest runs kept in the status
+	// +optional
+	// +kubebuilder:default:=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	HistoryLimit int `json:"historyLimit,omitempty"`
+}
+
+type ScheduleVMPowerActionStatus struct {
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// Runs are the latest runs of the schedule, the newest first
+	// +optional
+	Runs []VMPowerActionRun `json:"runs,omitempty"`
+}
+
+type VMPowerActionRun struct {
+	// Timestamp is the schedule time of the run in the format of 20060102.1504
+	Timestamp string `json:"timestamp"`
+
+	// +optional
+	ScheduleTime *metav1.Time `json:"scheduleTime,omitempty"`
+
+	// +optional
+	Succeeded int `json:"succeeded,omitempty"`
+
+	// +optional
+	Failed int `json:"failed,omitempty"`
+
+	// Results are the results of the VMs the action ran on
+	// +optional
+	Results []BulkActionResult `json:"results,omitempty"`
+}
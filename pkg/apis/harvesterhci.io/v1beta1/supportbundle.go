@@ -49,6 +49,12 @@ type SupportBundleSpec struct {
 	// +kubebuilder:validation:Minimum=0
 	// Number of minutes Harvester allows for collection of logs and configurations (Harvester) on the nodes for the support bundle.
 	NodeTimeout int `json:"nodeTimeout,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	// VMs involved in the issue in the format of namespace/name. Their screenshots and guest serial console logs
+	// are captured into the support bundle, and their namespaces are collected.
+	VMs []string `json:"vms,omitempty"`
 }
 
 type SupportBundleStatus struct {
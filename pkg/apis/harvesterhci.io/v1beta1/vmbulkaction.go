@@ -0,0 +1,91 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +enum
+type BulkActionPhase string
+
+const (
+	BulkActionPhaseRunning   BulkActionPhase = "Running"
+	BulkActionPhaseCompleted BulkActionPhase = "Completed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=vmbulkaction;vmbulkactions,scope=Namespaced
+// +kubebuilder:printcolumn:name="Action",type=string,JSONPath=`.spec.action`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.total`
+// +kubebuilder:printcolumn:name="Succeeded",type=integer,JSONPath=`.status.succeeded`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failed`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// VirtualMachineBulkAction records the progress and the per-VM results of a VM action run on
+// many VMs at once by the bulkAction collection action of the VM API.
+type VirtualMachineBulkAction struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineBulkActionSpec   `json:"spec"`
+	Status VirtualMachineBulkActionStatus `json:"status,omitempty"`
+}
+
+type VirtualMachineBulkActionSpec struct {
+	// Action is the VM action run on each VM, e.g. start, stop or migrate
+	// +kubebuilder:validation:Required
+	Action string `json:"action"`
+
+	// Input is the JSON encoded input of the action
+	// +optional
+	Input string `json:"input,omitempty"`
+
+	// Selector is a label selector selecting the VMs in the namespace, e.g. `app=web,tier!=db`
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// Names are the names of the VMs in the namespace
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// Concurrency is the maximum number of VMs the action runs on at the same time
+	// +optional
+	// +kubebuilder:default:=5
+	// +kubebuilder:validation:Minimum=1
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+type VirtualMachineBulkActionStatus struct {
+	// +optional
+	Phase BulkActionPhase `json:"phase,omitempty"`
+
+	// Total is the number of VMs the action runs on
+	// +optional
+	Total int `json:"total,omitempty"`
+
+	// +optional
+	Succeeded int `json:"succeeded,omitempty"`
+
+	// +optional
+	Failed int `json:"failed,omitempty"`
+
+	// Results are the results of the VMs the action is done on
+	// +optional
+	Results []BulkActionResult `json:"results,omitempty"`
+
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+type BulkActionResult struct {
+	Name      string `json:"name"`
+	Succeeded bool   `json:"succeeded"`
+
+	// Message is the error of the action if it failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,62 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	VMDownloaderFormatOVA = "ova"
+
+	// DownloaderCondsConverting tracks the conversion of the VM disks to qcow2
+	DownloaderCondsConverting DownloaderCondsType = "Converting"
+	// DownloaderCondsPackaging tracks the packaging of the OVF descriptor, disks and cloud-init data into the OVA
+	DownloaderCondsPackaging DownloaderCondsType = "Packaging"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=vmdownloader;vmdownloaders,scope=Namespaced
+// +kubebuilder:printcolumn:name="VM",type=string,JSONPath=`.spec.vmName`
+// +kubebuilder:printcolumn:name="Format",type="string",JSONPath=`.spec.format`
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=`.status.status`
+// +kubebuilder:subresource:status
+
+type VirtualMachineDownloader struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineDownloaderSpec   `json:"spec"`
+	Status VirtualMachineDownloaderStatus `json:"status,omitempty"`
+}
+
+type VirtualMachineDownloaderSpec struct {
+	// name of the vm
+	// +kubebuilder:validation:Required
+	VMName string `json:"vmName"`
+
+	// format of the vm package, the disks are converted to qcow2 in the package
+	// +kubebuilder:validation:Enum:=ova
+	// +kubebuilder:default:=ova
+	// +optional
+	Format string `json:"format,omitempty"`
+}
+
+type VirtualMachineDownloaderStatus struct {
+	// the conditions of the vm downloader
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:={}
+	Conditions []VirtualMachineImageDownloaderCondition `json:"conditions,omitempty"`
+
+	// the current status of the vm downloader
+	// +kubebuilder:validation:Enum:=Progressing;Ready
+	// +kubebuilder:default:=Progressing
+	Status DownloderStatus `json:"status,omitempty"`
+
+	// the url of the vm package
+	// +optional
+	DownloadURL string `json:"downloadUrl,omitempty"`
+
+	// the file name of the vm package
+	// +optional
+	FileName string `json:"fileName,omitempty"`
+}
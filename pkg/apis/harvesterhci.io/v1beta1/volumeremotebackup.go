@@ -10,6 +10,8 @@ type VolumeRemoteBackupType string
 
 const (
 	VolumeRemoteBackupLH VolumeRemoteBackupType = "lh"
+	// VolumeRemoteBackupGeneric streams a CSI snapshot of any provisioner to the backup target
+	VolumeRemoteBackupGeneric VolumeRemoteBackupType = "generic"
 )
 
 // +genclient
@@ -31,12 +33,17 @@ type VolumeRemoteBackup struct {
 
 type VolumeRemoteBackupSpec struct {
 	// +kubebuilder:default=lh
-	// +kubebuilder:validation:Enum=lh
+	// +kubebuilder:validation:Enum=lh;generic
 	// +kubebuilder:validation:Required
 	Type VolumeRemoteBackupType `json:"type"`
 
 	// +kubebuilder:validation:Required
 	Source string `json:"source"`
+
+	// BackupTargetName is the name of the BackupTarget to store the backup of the generic type.
+	// The backup-target setting is used if it's empty.
+	// +optional
+	BackupTargetName string `json:"backupTargetName,omitempty"`
 }
 
 type VolumeRemoteBackupStatus struct {
@@ -61,6 +68,8 @@ type VolumeRemoteRestoreType string
 
 const (
 	VolumeRemoteRestoreLH VolumeRemoteRestoreType = "lh"
+	// VolumeRemoteRestoreGeneric restores a VolumeRemoteBackup created by the generic driver
+	VolumeRemoteRestoreGeneric VolumeRemoteRestoreType = "generic"
 )
 
 // +genclient
@@ -80,7 +89,7 @@ type VolumeRemoteRestore struct {
 
 type VolumeRemoteRestoreSpec struct {
 	// +kubebuilder:default=lh
-	// +kubebuilder:validation:Enum=lh
+	// +kubebuilder:validation:Enum=lh;generic
 	// +kubebuilder:validation:Required
 	Type VolumeRemoteRestoreType `json:"type"`
 
@@ -22,6 +22,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	types "k8s.io/apimachinery/pkg/types"
 )
@@ -124,6 +125,22 @@ func (in *AddonStatus) DeepCopy() *AddonStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationConsistentSpec) DeepCopyInto(out *ApplicationConsistentSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationConsistentSpec.
+func (in *ApplicationConsistentSpec) DeepCopy() *ApplicationConsistentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationConsistentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Archive) DeepCopyInto(out *Archive) {
 	*out = *in
@@ -143,6 +160,10 @@ func (in *Archive) DeepCopy() *Archive {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupTarget) DeepCopyInto(out *BackupTarget) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -156,6 +177,141 @@ func (in *BackupTarget) DeepCopy() *BackupTarget {
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupTarget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTargetInfo) DeepCopyInto(out *BackupTargetInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTargetInfo.
+func (in *BackupTargetInfo) DeepCopy() *BackupTargetInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTargetInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTargetList) DeepCopyInto(out *BackupTargetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BackupTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTargetList.
+func (in *BackupTargetList) DeepCopy() *BackupTargetList {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTargetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupTargetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTargetSpec) DeepCopyInto(out *BackupTargetSpec) {
+	*out = *in
+	if in.CredentialSecret != nil {
+		in, out := &in.CredentialSecret, &out.CredentialSecret
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTargetSpec.
+func (in *BackupTargetSpec) DeepCopy() *BackupTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTargetStatus) DeepCopyInto(out *BackupTargetStatus) {
+	*out = *in
+	if in.LastSyncedTime != nil {
+		in, out := &in.LastSyncedTime, &out.LastSyncedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTargetStatus.
+func (in *BackupTargetStatus) DeepCopy() *BackupTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupWindow) DeepCopyInto(out *BackupWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupWindow.
+func (in *BackupWindow) DeepCopy() *BackupWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BulkActionResult) DeepCopyInto(out *BulkActionResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkActionResult.
+func (in *BulkActionResult) DeepCopy() *BulkActionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(BulkActionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -218,6 +374,183 @@ func (in *ErrorResponse) DeepCopy() *ErrorResponse {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestHook) DeepCopyInto(out *GuestHook) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestHook.
+func (in *GuestHook) DeepCopy() *GuestHook {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestHooks) DeepCopyInto(out *GuestHooks) {
+	*out = *in
+	if in.PreFreezeHook != nil {
+		in, out := &in.PreFreezeHook, &out.PreFreezeHook
+		*out = new(GuestHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostThawHook != nil {
+		in, out := &in.PostThawHook, &out.PostThawHook
+		*out = new(GuestHook)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestHooks.
+func (in *GuestHooks) DeepCopy() *GuestHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageReplication) DeepCopyInto(out *ImageReplication) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageReplication.
+func (in *ImageReplication) DeepCopy() *ImageReplication {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageReplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ImageReplication) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageReplicationList) DeepCopyInto(out *ImageReplicationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ImageReplication, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageReplicationList.
+func (in *ImageReplicationList) DeepCopy() *ImageReplicationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageReplicationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ImageReplicationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageReplicationSpec) DeepCopyInto(out *ImageReplicationSpec) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Target = in.Target
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageReplicationSpec.
+func (in *ImageReplicationSpec) DeepCopy() *ImageReplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageReplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageReplicationStatus) DeepCopyInto(out *ImageReplicationStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]ReplicatedImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageReplicationStatus.
+func (in *ImageReplicationStatus) DeepCopy() *ImageReplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageReplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageReplicationTarget) DeepCopyInto(out *ImageReplicationTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageReplicationTarget.
+func (in *ImageReplicationTarget) DeepCopy() *ImageReplicationTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageReplicationTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeyGenInput) DeepCopyInto(out *KeyGenInput) {
 	*out = *in
@@ -426,17 +759,57 @@ func (in *PreferenceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceQuota) DeepCopyInto(out *ResourceQuota) {
+func (in *QueuedVMBackup) DeepCopyInto(out *QueuedVMBackup) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.QueuedAt != nil {
+		in, out := &in.QueuedAt, &out.QueuedAt
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQuota.
-func (in *ResourceQuota) DeepCopy() *ResourceQuota {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueuedVMBackup.
+func (in *QueuedVMBackup) DeepCopy() *QueuedVMBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(QueuedVMBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicatedImage) DeepCopyInto(out *ReplicatedImage) {
+	*out = *in
+	if in.ReplicatedTime != nil {
+		in, out := &in.ReplicatedTime, &out.ReplicatedTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicatedImage.
+func (in *ReplicatedImage) DeepCopy() *ReplicatedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicatedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceQuota) DeepCopyInto(out *ResourceQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQuota.
+func (in *ResourceQuota) DeepCopy() *ResourceQuota {
 	if in == nil {
 		return nil
 	}
@@ -520,6 +893,22 @@ func (in *ResourceQuotaStatus) DeepCopy() *ResourceQuotaStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionPolicy) DeepCopyInto(out *RetentionPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionPolicy.
+func (in *RetentionPolicy) DeepCopy() *RetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduleVMBackup) DeepCopyInto(out *ScheduleVMBackup) {
 	*out = *in
@@ -585,6 +974,21 @@ func (in *ScheduleVMBackupList) DeepCopyObject() runtime.Object {
 func (in *ScheduleVMBackupSpec) DeepCopyInto(out *ScheduleVMBackupSpec) {
 	*out = *in
 	in.VMBackupSpec.DeepCopyInto(&out.VMBackupSpec)
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(RetentionPolicy)
+		**out = **in
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(ScheduleVMBackupVerification)
+		**out = **in
+	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(BackupWindow)
+		**out = **in
+	}
 	return
 }
 
@@ -608,6 +1012,11 @@ func (in *ScheduleVMBackupStatus) DeepCopyInto(out *ScheduleVMBackupStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.QueuedVMBackup != nil {
+		in, out := &in.QueuedVMBackup, &out.QueuedVMBackup
+		*out = new(QueuedVMBackup)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
@@ -626,6 +1035,132 @@ func (in *ScheduleVMBackupStatus) DeepCopy() *ScheduleVMBackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleVMBackupVerification) DeepCopyInto(out *ScheduleVMBackupVerification) {
+	*out = *in
+	out.VerificationOptions = in.VerificationOptions
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleVMBackupVerification.
+func (in *ScheduleVMBackupVerification) DeepCopy() *ScheduleVMBackupVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleVMBackupVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleVMPowerAction) DeepCopyInto(out *ScheduleVMPowerAction) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleVMPowerAction.
+func (in *ScheduleVMPowerAction) DeepCopy() *ScheduleVMPowerAction {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleVMPowerAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduleVMPowerAction) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleVMPowerActionList) DeepCopyInto(out *ScheduleVMPowerActionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScheduleVMPowerAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleVMPowerActionList.
+func (in *ScheduleVMPowerActionList) DeepCopy() *ScheduleVMPowerActionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleVMPowerActionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduleVMPowerActionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleVMPowerActionSpec) DeepCopyInto(out *ScheduleVMPowerActionSpec) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleVMPowerActionSpec.
+func (in *ScheduleVMPowerActionSpec) DeepCopy() *ScheduleVMPowerActionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleVMPowerActionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleVMPowerActionStatus) DeepCopyInto(out *ScheduleVMPowerActionStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Runs != nil {
+		in, out := &in.Runs, &out.Runs
+		*out = make([]VMPowerActionRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleVMPowerActionStatus.
+func (in *ScheduleVMPowerActionStatus) DeepCopy() *ScheduleVMPowerActionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleVMPowerActionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretBackup) DeepCopyInto(out *SecretBackup) {
 	*out = *in
@@ -858,6 +1393,11 @@ func (in *SupportBundleSpec) DeepCopyInto(out *SupportBundleSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.VMs != nil {
+		in, out := &in.VMs, &out.VMs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1020,147 +1560,540 @@ func (in *UpgradeLogSpec) DeepCopyInto(out *UpgradeLogSpec) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeLogSpec.
-func (in *UpgradeLogSpec) DeepCopy() *UpgradeLogSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeLogSpec.
+func (in *UpgradeLogSpec) DeepCopy() *UpgradeLogSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeLogSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeLogStatus) DeepCopyInto(out *UpgradeLogStatus) {
+	*out = *in
+	if in.Archives != nil {
+		in, out := &in.Archives, &out.Archives
+		*out = make(map[string]Archive, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeLogStatus.
+func (in *UpgradeLogStatus) DeepCopy() *UpgradeLogStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeLogStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeSpec) DeepCopyInto(out *UpgradeSpec) {
+	*out = *in
+	if in.UpgradeImage != nil {
+		in, out := &in.UpgradeImage, &out.UpgradeImage
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeSpec.
+func (in *UpgradeSpec) DeepCopy() *UpgradeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStatus) DeepCopyInto(out *UpgradeStatus) {
+	*out = *in
+	if in.NodeStatuses != nil {
+		in, out := &in.NodeStatuses, &out.NodeStatuses
+		*out = make(map[string]NodeUpgradeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStatus.
+func (in *UpgradeStatus) DeepCopy() *UpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMBackupInfo) DeepCopyInto(out *VMBackupInfo) {
+	*out = *in
+	if in.VolumeBackupInfo != nil {
+		in, out := &in.VolumeBackupInfo, &out.VolumeBackupInfo
+		*out = make([]VolumeBackupInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReadyToUse != nil {
+		in, out := &in.ReadyToUse, &out.ReadyToUse
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Error != nil {
+		in, out := &in.Error, &out.Error
+		*out = new(Error)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetainedBy != nil {
+		in, out := &in.RetainedBy, &out.RetainedBy
+		*out = make([]RetentionRule, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMBackupInfo.
+func (in *VMBackupInfo) DeepCopy() *VMBackupInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(VMBackupInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMPowerActionRun) DeepCopyInto(out *VMPowerActionRun) {
+	*out = *in
+	if in.ScheduleTime != nil {
+		in, out := &in.ScheduleTime, &out.ScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]BulkActionResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMPowerActionRun.
+func (in *VMPowerActionRun) DeepCopy() *VMPowerActionRun {
+	if in == nil {
+		return nil
+	}
+	out := new(VMPowerActionRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationOptions) DeepCopyInto(out *VerificationOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationOptions.
+func (in *VerificationOptions) DeepCopy() *VerificationOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Version) DeepCopyInto(out *Version) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Version.
+func (in *Version) DeepCopy() *Version {
+	if in == nil {
+		return nil
+	}
+	out := new(Version)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Version) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionList) DeepCopyInto(out *VersionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Version, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionList.
+func (in *VersionList) DeepCopy() *VersionList {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VersionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionSpec) DeepCopyInto(out *VersionSpec) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSpec.
+func (in *VersionSpec) DeepCopy() *VersionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineBackup) DeepCopyInto(out *VirtualMachineBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackup.
+func (in *VirtualMachineBackup) DeepCopy() *VirtualMachineBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineBackupList) DeepCopyInto(out *VirtualMachineBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupList.
+func (in *VirtualMachineBackupList) DeepCopy() *VirtualMachineBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineBackupSpec) DeepCopyInto(out *VirtualMachineBackupSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	if in.ApplicationConsistent != nil {
+		in, out := &in.ApplicationConsistent, &out.ApplicationConsistent
+		*out = new(ApplicationConsistentSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupSpec.
+func (in *VirtualMachineBackupSpec) DeepCopy() *VirtualMachineBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineBackupStatus) DeepCopyInto(out *VirtualMachineBackupStatus) {
+	*out = *in
+	if in.SourceUID != nil {
+		in, out := &in.SourceUID, &out.SourceUID
+		*out = new(types.UID)
+		**out = **in
+	}
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.BackupTarget != nil {
+		in, out := &in.BackupTarget, &out.BackupTarget
+		*out = new(BackupTargetInfo)
+		**out = **in
+	}
+	if in.CSIDriverVolumeSnapshotClassNames != nil {
+		in, out := &in.CSIDriverVolumeSnapshotClassNames, &out.CSIDriverVolumeSnapshotClassNames
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SourceSpec != nil {
+		in, out := &in.SourceSpec, &out.SourceSpec
+		*out = new(VirtualMachineSourceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeBackups != nil {
+		in, out := &in.VolumeBackups, &out.VolumeBackups
+		*out = make([]VolumeBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecretBackups != nil {
+		in, out := &in.SecretBackups, &out.SecretBackups
+		*out = make([]SecretBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReadyToUse != nil {
+		in, out := &in.ReadyToUse, &out.ReadyToUse
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Error != nil {
+		in, out := &in.Error, &out.Error
+		*out = new(Error)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupStatus.
+func (in *VirtualMachineBackupStatus) DeepCopy() *VirtualMachineBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(UpgradeLogSpec)
+	out := new(VirtualMachineBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *UpgradeLogStatus) DeepCopyInto(out *UpgradeLogStatus) {
+func (in *VirtualMachineBackupVerification) DeepCopyInto(out *VirtualMachineBackupVerification) {
 	*out = *in
-	if in.Archives != nil {
-		in, out := &in.Archives, &out.Archives
-		*out = make(map[string]Archive, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]Condition, len(*in))
-		copy(*out, *in)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeLogStatus.
-func (in *UpgradeLogStatus) DeepCopy() *UpgradeLogStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupVerification.
+func (in *VirtualMachineBackupVerification) DeepCopy() *VirtualMachineBackupVerification {
 	if in == nil {
 		return nil
 	}
-	out := new(UpgradeLogStatus)
+	out := new(VirtualMachineBackupVerification)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineBackupVerification) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *UpgradeSpec) DeepCopyInto(out *UpgradeSpec) {
+func (in *VirtualMachineBackupVerificationList) DeepCopyInto(out *VirtualMachineBackupVerificationList) {
 	*out = *in
-	if in.UpgradeImage != nil {
-		in, out := &in.UpgradeImage, &out.UpgradeImage
-		*out = new(string)
-		**out = **in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineBackupVerification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeSpec.
-func (in *UpgradeSpec) DeepCopy() *UpgradeSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupVerificationList.
+func (in *VirtualMachineBackupVerificationList) DeepCopy() *VirtualMachineBackupVerificationList {
 	if in == nil {
 		return nil
 	}
-	out := new(UpgradeSpec)
+	out := new(VirtualMachineBackupVerificationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineBackupVerificationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *UpgradeStatus) DeepCopyInto(out *UpgradeStatus) {
+func (in *VirtualMachineBackupVerificationSpec) DeepCopyInto(out *VirtualMachineBackupVerificationSpec) {
 	*out = *in
-	if in.NodeStatuses != nil {
-		in, out := &in.NodeStatuses, &out.NodeStatuses
-		*out = make(map[string]NodeUpgradeStatus, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]Condition, len(*in))
-		copy(*out, *in)
-	}
+	out.VerificationOptions = in.VerificationOptions
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStatus.
-func (in *UpgradeStatus) DeepCopy() *UpgradeStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupVerificationSpec.
+func (in *VirtualMachineBackupVerificationSpec) DeepCopy() *VirtualMachineBackupVerificationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(UpgradeStatus)
+	out := new(VirtualMachineBackupVerificationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VMBackupInfo) DeepCopyInto(out *VMBackupInfo) {
+func (in *VirtualMachineBackupVerificationStatus) DeepCopyInto(out *VirtualMachineBackupVerificationStatus) {
 	*out = *in
-	if in.VolumeBackupInfo != nil {
-		in, out := &in.VolumeBackupInfo, &out.VolumeBackupInfo
-		*out = make([]VolumeBackupInfo, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
 	}
-	if in.ReadyToUse != nil {
-		in, out := &in.ReadyToUse, &out.ReadyToUse
-		*out = new(bool)
-		**out = **in
+	if in.RestoredTime != nil {
+		in, out := &in.RestoredTime, &out.RestoredTime
+		*out = (*in).DeepCopy()
 	}
-	if in.Error != nil {
-		in, out := &in.Error, &out.Error
-		*out = new(Error)
-		(*in).DeepCopyInto(*out)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMBackupInfo.
-func (in *VMBackupInfo) DeepCopy() *VMBackupInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupVerificationStatus.
+func (in *VirtualMachineBackupVerificationStatus) DeepCopy() *VirtualMachineBackupVerificationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VMBackupInfo)
+	out := new(VirtualMachineBackupVerificationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Version) DeepCopyInto(out *Version) {
+func (in *VirtualMachineBulkAction) DeepCopyInto(out *VirtualMachineBulkAction) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Version.
-func (in *Version) DeepCopy() *Version {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBulkAction.
+func (in *VirtualMachineBulkAction) DeepCopy() *VirtualMachineBulkAction {
 	if in == nil {
 		return nil
 	}
-	out := new(Version)
+	out := new(VirtualMachineBulkAction)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Version) DeepCopyObject() runtime.Object {
+func (in *VirtualMachineBulkAction) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1168,13 +2101,13 @@ func (in *Version) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VersionList) DeepCopyInto(out *VersionList) {
+func (in *VirtualMachineBulkActionList) DeepCopyInto(out *VirtualMachineBulkActionList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Version, len(*in))
+		*out = make([]VirtualMachineBulkAction, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1182,18 +2115,18 @@ func (in *VersionList) DeepCopyInto(out *VersionList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionList.
-func (in *VersionList) DeepCopy() *VersionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBulkActionList.
+func (in *VirtualMachineBulkActionList) DeepCopy() *VirtualMachineBulkActionList {
 	if in == nil {
 		return nil
 	}
-	out := new(VersionList)
+	out := new(VirtualMachineBulkActionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VersionList) DeepCopyObject() runtime.Object {
+func (in *VirtualMachineBulkActionList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1201,48 +2134,77 @@ func (in *VersionList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VersionSpec) DeepCopyInto(out *VersionSpec) {
+func (in *VirtualMachineBulkActionSpec) DeepCopyInto(out *VirtualMachineBulkActionSpec) {
 	*out = *in
-	if in.Tags != nil {
-		in, out := &in.Tags, &out.Tags
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSpec.
-func (in *VersionSpec) DeepCopy() *VersionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBulkActionSpec.
+func (in *VirtualMachineBulkActionSpec) DeepCopy() *VirtualMachineBulkActionSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VersionSpec)
+	out := new(VirtualMachineBulkActionSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineBackup) DeepCopyInto(out *VirtualMachineBackup) {
+func (in *VirtualMachineBulkActionStatus) DeepCopyInto(out *VirtualMachineBulkActionStatus) {
+	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]BulkActionResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBulkActionStatus.
+func (in *VirtualMachineBulkActionStatus) DeepCopy() *VirtualMachineBulkActionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineBulkActionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineDownloader) DeepCopyInto(out *VirtualMachineDownloader) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackup.
-func (in *VirtualMachineBackup) DeepCopy() *VirtualMachineBackup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineDownloader.
+func (in *VirtualMachineDownloader) DeepCopy() *VirtualMachineDownloader {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineBackup)
+	out := new(VirtualMachineDownloader)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachineBackup) DeepCopyObject() runtime.Object {
+func (in *VirtualMachineDownloader) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1250,13 +2212,13 @@ func (in *VirtualMachineBackup) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineBackupList) DeepCopyInto(out *VirtualMachineBackupList) {
+func (in *VirtualMachineDownloaderList) DeepCopyInto(out *VirtualMachineDownloaderList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VirtualMachineBackup, len(*in))
+		*out = make([]VirtualMachineDownloader, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1264,18 +2226,18 @@ func (in *VirtualMachineBackupList) DeepCopyInto(out *VirtualMachineBackupList)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupList.
-func (in *VirtualMachineBackupList) DeepCopy() *VirtualMachineBackupList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineDownloaderList.
+func (in *VirtualMachineDownloaderList) DeepCopy() *VirtualMachineDownloaderList {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineBackupList)
+	out := new(VirtualMachineDownloaderList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachineBackupList) DeepCopyObject() runtime.Object {
+func (in *VirtualMachineDownloaderList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1283,89 +2245,40 @@ func (in *VirtualMachineBackupList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineBackupSpec) DeepCopyInto(out *VirtualMachineBackupSpec) {
+func (in *VirtualMachineDownloaderSpec) DeepCopyInto(out *VirtualMachineDownloaderSpec) {
 	*out = *in
-	in.Source.DeepCopyInto(&out.Source)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupSpec.
-func (in *VirtualMachineBackupSpec) DeepCopy() *VirtualMachineBackupSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineDownloaderSpec.
+func (in *VirtualMachineDownloaderSpec) DeepCopy() *VirtualMachineDownloaderSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineBackupSpec)
+	out := new(VirtualMachineDownloaderSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineBackupStatus) DeepCopyInto(out *VirtualMachineBackupStatus) {
+func (in *VirtualMachineDownloaderStatus) DeepCopyInto(out *VirtualMachineDownloaderStatus) {
 	*out = *in
-	if in.SourceUID != nil {
-		in, out := &in.SourceUID, &out.SourceUID
-		*out = new(types.UID)
-		**out = **in
-	}
-	if in.CreationTime != nil {
-		in, out := &in.CreationTime, &out.CreationTime
-		*out = (*in).DeepCopy()
-	}
-	if in.BackupTarget != nil {
-		in, out := &in.BackupTarget, &out.BackupTarget
-		*out = new(BackupTarget)
-		**out = **in
-	}
-	if in.CSIDriverVolumeSnapshotClassNames != nil {
-		in, out := &in.CSIDriverVolumeSnapshotClassNames, &out.CSIDriverVolumeSnapshotClassNames
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.SourceSpec != nil {
-		in, out := &in.SourceSpec, &out.SourceSpec
-		*out = new(VirtualMachineSourceSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.VolumeBackups != nil {
-		in, out := &in.VolumeBackups, &out.VolumeBackups
-		*out = make([]VolumeBackup, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.SecretBackups != nil {
-		in, out := &in.SecretBackups, &out.SecretBackups
-		*out = make([]SecretBackup, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]VirtualMachineImageDownloaderCondition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ReadyToUse != nil {
-		in, out := &in.ReadyToUse, &out.ReadyToUse
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Error != nil {
-		in, out := &in.Error, &out.Error
-		*out = new(Error)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]Condition, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBackupStatus.
-func (in *VirtualMachineBackupStatus) DeepCopy() *VirtualMachineBackupStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineDownloaderStatus.
+func (in *VirtualMachineDownloaderStatus) DeepCopy() *VirtualMachineDownloaderStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineBackupStatus)
+	out := new(VirtualMachineDownloaderStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1398,6 +2311,22 @@ func (in *VirtualMachineImage) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageConsumer) DeepCopyInto(out *VirtualMachineImageConsumer) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageConsumer.
+func (in *VirtualMachineImageConsumer) DeepCopy() *VirtualMachineImageConsumer {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageConsumer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineImageDownloader) DeepCopyInto(out *VirtualMachineImageDownloader) {
 	*out = *in
@@ -1564,9 +2493,30 @@ func (in *VirtualMachineImageSecurityParameters) DeepCopy() *VirtualMachineImage
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageSignature) DeepCopyInto(out *VirtualMachineImageSignature) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageSignature.
+func (in *VirtualMachineImageSignature) DeepCopy() *VirtualMachineImageSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineImageSpec) DeepCopyInto(out *VirtualMachineImageSpec) {
 	*out = *in
+	if in.Signature != nil {
+		in, out := &in.Signature, &out.Signature
+		*out = new(VirtualMachineImageSignature)
+		**out = **in
+	}
 	if in.StorageClassParameters != nil {
 		in, out := &in.StorageClassParameters, &out.StorageClassParameters
 		*out = make(map[string]string, len(*in))
@@ -1597,7 +2547,7 @@ func (in *VirtualMachineImageStatus) DeepCopyInto(out *VirtualMachineImageStatus
 	*out = *in
 	if in.BackupTarget != nil {
 		in, out := &in.BackupTarget, &out.BackupTarget
-		*out = new(BackupTarget)
+		*out = new(BackupTargetInfo)
 		**out = **in
 	}
 	if in.Conditions != nil {
@@ -1605,6 +2555,11 @@ func (in *VirtualMachineImageStatus) DeepCopyInto(out *VirtualMachineImageStatus
 		*out = make([]Condition, len(*in))
 		copy(*out, *in)
 	}
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = make([]VirtualMachineImageConsumer, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1683,6 +2638,32 @@ func (in *VirtualMachineRestoreList) DeepCopyObject() runtime.Object {
 func (in *VirtualMachineRestoreSpec) DeepCopyInto(out *VirtualMachineRestoreSpec) {
 	*out = *in
 	in.Target.DeepCopyInto(&out.Target)
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceMapping != nil {
+		in, out := &in.NamespaceMapping, &out.NamespaceMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StorageClassMapping != nil {
+		in, out := &in.StorageClassMapping, &out.StorageClassMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NetworkMapping != nil {
+		in, out := &in.NetworkMapping, &out.NetworkMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -329,3 +329,105 @@ func NewVirtualMachineImageDownloader(namespace, name string, obj VirtualMachine
 	obj.Namespace = namespace
 	return &obj
 }
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackupTargetList is a list of BackupTarget resources
+type BackupTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []BackupTarget `json:"items"`
+}
+
+func NewBackupTarget(namespace, name string, obj BackupTarget) *BackupTarget {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("BackupTarget").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VirtualMachineBackupVerificationList is a list of VirtualMachineBackupVerification resources
+type VirtualMachineBackupVerificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []VirtualMachineBackupVerification `json:"items"`
+}
+
+func NewVirtualMachineBackupVerification(namespace, name string, obj VirtualMachineBackupVerification) *VirtualMachineBackupVerification {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("VirtualMachineBackupVerification").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VirtualMachineBulkActionList is a list of VirtualMachineBulkAction resources
+type VirtualMachineBulkActionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []VirtualMachineBulkAction `json:"items"`
+}
+
+func NewVirtualMachineBulkAction(namespace, name string, obj VirtualMachineBulkAction) *VirtualMachineBulkAction {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("VirtualMachineBulkAction").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScheduleVMPowerActionList is a list of ScheduleVMPowerAction resources
+type ScheduleVMPowerActionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ScheduleVMPowerAction `json:"items"`
+}
+
+func NewScheduleVMPowerAction(namespace, name string, obj ScheduleVMPowerAction) *ScheduleVMPowerAction {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("ScheduleVMPowerAction").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ImageReplicationList is a list of ImageReplication resources
+type ImageReplicationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ImageReplication `json:"items"`
+}
+
+func NewImageReplication(namespace, name string, obj ImageReplication) *ImageReplication {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("ImageReplication").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VirtualMachineDownloaderList is a list of VirtualMachineDownloader resources
+type VirtualMachineDownloaderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []VirtualMachineDownloader `json:"items"`
+}
+
+func NewVirtualMachineDownloader(namespace, name string, obj VirtualMachineDownloader) *VirtualMachineDownloader {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("VirtualMachineDownloader").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
@@ -28,24 +28,30 @@ import (
 )
 
 var (
-	AddonResourceName                         = "addons"
-	KeyPairResourceName                       = "keypairs"
-	PreferenceResourceName                    = "preferences"
-	ResourceQuotaResourceName                 = "resourcequotas"
-	ScheduleVMBackupResourceName              = "schedulevmbackups"
-	SettingResourceName                       = "settings"
-	SupportBundleResourceName                 = "supportbundles"
-	UpgradeResourceName                       = "upgrades"
-	UpgradeLogResourceName                    = "upgradelogs"
-	VersionResourceName                       = "versions"
-	VirtualMachineBackupResourceName          = "virtualmachinebackups"
-	VirtualMachineImageResourceName           = "virtualmachineimages"
-	VirtualMachineImageDownloaderResourceName = "virtualmachineimagedownloaders"
-	VirtualMachineRestoreResourceName         = "virtualmachinerestores"
-	VirtualMachineTemplateResourceName        = "virtualmachinetemplates"
-	VirtualMachineTemplateVersionResourceName = "virtualmachinetemplateversions"
-	VolumeRemoteBackupResourceName            = "volumeremotebackups"
-	VolumeRemoteRestoreResourceName           = "volumeremoterestores"
+	AddonResourceName                            = "addons"
+	BackupTargetResourceName                     = "backuptargets"
+	ImageReplicationResourceName                 = "imagereplications"
+	KeyPairResourceName                          = "keypairs"
+	PreferenceResourceName                       = "preferences"
+	ResourceQuotaResourceName                    = "resourcequotas"
+	ScheduleVMBackupResourceName                 = "schedulevmbackups"
+	ScheduleVMPowerActionResourceName            = "schedulevmpoweractions"
+	SettingResourceName                          = "settings"
+	SupportBundleResourceName                    = "supportbundles"
+	UpgradeResourceName                          = "upgrades"
+	UpgradeLogResourceName                       = "upgradelogs"
+	VersionResourceName                          = "versions"
+	VirtualMachineBackupResourceName             = "virtualmachinebackups"
+	VirtualMachineBackupVerificationResourceName = "virtualmachinebackupverifications"
+	VirtualMachineBulkActionResourceName         = "virtualmachinebulkactions"
+	VirtualMachineDownloaderResourceName         = "virtualmachinedownloaders"
+	VirtualMachineImageResourceName              = "virtualmachineimages"
+	VirtualMachineImageDownloaderResourceName    = "virtualmachineimagedownloaders"
+	VirtualMachineRestoreResourceName            = "virtualmachinerestores"
+	VirtualMachineTemplateResourceName           = "virtualmachinetemplates"
+	VirtualMachineTemplateVersionResourceName    = "virtualmachinetemplateversions"
+	VolumeRemoteBackupResourceName               = "volumeremotebackups"
+	VolumeRemoteRestoreResourceName              = "volumeremoterestores"
 )
 
 // SchemeGroupVersion is group version used to register these objects
@@ -71,6 +77,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&Addon{},
 		&AddonList{},
+		&BackupTarget{},
+		&BackupTargetList{},
+		&ImageReplication{},
+		&ImageReplicationList{},
 		&KeyPair{},
 		&KeyPairList{},
 		&Preference{},
@@ -79,6 +89,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ResourceQuotaList{},
 		&ScheduleVMBackup{},
 		&ScheduleVMBackupList{},
+		&ScheduleVMPowerAction{},
+		&ScheduleVMPowerActionList{},
 		&Setting{},
 		&SettingList{},
 		&SupportBundle{},
@@ -91,6 +103,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VersionList{},
 		&VirtualMachineBackup{},
 		&VirtualMachineBackupList{},
+		&VirtualMachineBackupVerification{},
+		&VirtualMachineBackupVerificationList{},
+		&VirtualMachineBulkAction{},
+		&VirtualMachineBulkActionList{},
+		&VirtualMachineDownloader{},
+		&VirtualMachineDownloaderList{},
 		&VirtualMachineImage{},
 		&VirtualMachineImageList{},
 		&VirtualMachineImageDownloader{},
@@ -26,6 +26,7 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	cdiuploadv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -60,6 +61,12 @@ func main() {
 					harvesterv1.VolumeRemoteBackup{},
 					harvesterv1.VolumeRemoteRestore{},
 					harvesterv1.VirtualMachineImageDownloader{},
+					harvesterv1.BackupTarget{},
+					harvesterv1.VirtualMachineBackupVerification{},
+					harvesterv1.VirtualMachineBulkAction{},
+					harvesterv1.ScheduleVMPowerAction{},
+					harvesterv1.ImageReplication{},
+					harvesterv1.VirtualMachineDownloader{},
 				},
 				GenerateTypes:   true,
 				GenerateClients: true,
@@ -86,6 +93,13 @@ func main() {
 				GenerateTypes:   false,
 				GenerateClients: true,
 			},
+			migrationsv1alpha1.SchemeGroupVersion.Group: {
+				Types: []interface{}{
+					migrationsv1alpha1.MigrationPolicy{},
+				},
+				GenerateTypes:   false,
+				GenerateClients: true,
+			},
 			cniv1.SchemeGroupVersion.Group: {
 				Types: []interface{}{
 					cniv1.NetworkAttachmentDefinition{},
@@ -247,6 +261,7 @@ func main() {
 	capiWorkaround()
 	loggingWorkaround()
 	coreWorkaround()
+	migrationPolicyWorkaround()
 }
 
 // NB(GC), nadControllerInterfaceRefactor modify the generated resource name of NetworkAttachmentDefinition controller using a dash-separator,
@@ -334,3 +349,34 @@ func coreWorkaround() {
 		}
 	}
 }
+
+// migrationPolicyWorkaround makes the MigrationPolicy controller non-namespaced, wrangler only reads the
+// `+genclient:nonNamespaced` tag from the second closest comment lines, but kubevirt puts it in the closest ones.
+// https://github.com/kubevirt/api/blob/main/migrations/v1alpha1/types.go
+func migrationPolicyWorkaround() {
+	files := map[string][][2]string{
+		"pkg/generated/controllers/migrations.kubevirt.io/v1alpha1/interface.go": {
+			{"generic.NewController[", "generic.NewNonNamespacedController["},
+			{`"migrationpolicies", true, `, `"migrationpolicies", `},
+		},
+		"pkg/generated/controllers/migrations.kubevirt.io/v1alpha1/migrationpolicy.go": {
+			{"generic.ControllerInterface[", "generic.NonNamespacedControllerInterface["},
+			{"generic.ClientInterface[", "generic.NonNamespacedClientInterface["},
+			{"generic.CacheInterface[", "generic.NonNamespacedCacheInterface["},
+		},
+	}
+
+	for absPath, replacements := range files {
+		input, err := os.ReadFile(absPath)
+		if err != nil {
+			logrus.Fatalf("failed to read the migrationpolicies.migrations.kubevirt.io controller file: %v", err)
+		}
+		for _, replacement := range replacements {
+			input = bytes.ReplaceAll(input, []byte(replacement[0]), []byte(replacement[1]))
+		}
+
+		if err = os.WriteFile(absPath, input, 0600); err != nil {
+			logrus.Fatalf("failed to update the migrationpolicies.migrations.kubevirt.io controller file: %v", err)
+		}
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io"
 	loggingv1 "github.com/harvester/harvester/pkg/generated/controllers/logging.banzaicloud.io"
 	longhornv1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io"
+	ctlmigrationsv1 "github.com/harvester/harvester/pkg/generated/controllers/migrations.kubevirt.io"
 	monitoringv1 "github.com/harvester/harvester/pkg/generated/controllers/monitoring.coreos.com"
 	"github.com/harvester/harvester/pkg/generated/controllers/networking.k8s.io"
 	snapshotv1 "github.com/harvester/harvester/pkg/generated/controllers/snapshot.storage.k8s.io"
@@ -122,6 +123,7 @@ type Management struct {
 	RKEFactory                *rkev1.Factory
 	CdiFactory                *ctlcdiv1.Factory
 	CdiUploadFactory          *ctlcdiuploadv1.Factory
+	MigrationsFactory         *ctlmigrationsv1.Factory
 
 	ClientSet  kubernetes.Interface
 	RestConfig *rest.Config
@@ -481,6 +483,13 @@ func setupManagement(ctx context.Context, restConfig *rest.Config, opts *generic
 	management.CdiUploadFactory = cdiupload
 	management.starters = append(management.starters, cdiupload)
 
+	migrations, err := ctlmigrationsv1.NewFactoryFromConfigWithOptions(restConfig, opts)
+	if err != nil {
+		return nil, err
+	}
+	management.MigrationsFactory = migrations
+	management.starters = append(management.starters, migrations)
+
 	return management, nil
 }
 
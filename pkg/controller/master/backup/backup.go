@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"sync"
 	"time"
 
 	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
@@ -28,6 +29,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
@@ -70,6 +72,7 @@ func RegisterBackup(ctx context.Context, management *config.Management, _ config
 	pv := management.CoreFactory.Core().V1().PersistentVolume()
 	pvc := management.CoreFactory.Core().V1().PersistentVolumeClaim()
 	secrets := management.CoreFactory.Core().V1().Secret()
+	pods := management.CoreFactory.Core().V1().Pod()
 	storageClasses := management.StorageFactory.Storage().V1().StorageClass()
 	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
 	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
@@ -78,6 +81,7 @@ func RegisterBackup(ctx context.Context, management *config.Management, _ config
 	snapshots := management.SnapshotFactory.Snapshot().V1().VolumeSnapshot()
 	snapshotContents := management.SnapshotFactory.Snapshot().V1().VolumeSnapshotContent()
 	snapshotClass := management.SnapshotFactory.Snapshot().V1().VolumeSnapshotClass()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 
 	virtSubsrcConfig := rest.CopyConfig(management.RestConfig)
 	virtSubsrcConfig.GroupVersion = &k8sschema.GroupVersion{Group: "subresources.kubevirt.io", Version: "v1"}
@@ -95,20 +99,27 @@ func RegisterBackup(ctx context.Context, management *config.Management, _ config
 		pvCache:                   pv.Cache(),
 		pvcCache:                  pvc.Cache(),
 		secretCache:               secrets.Cache(),
+		podCache:                  pods.Cache(),
 		storageClassCache:         storageClasses.Cache(),
 		vms:                       vms,
 		vmsCache:                  vms.Cache(),
 		vmis:                      vmis,
 		vmisCache:                 vmis.Cache(),
+		lhbackups:                 lhbackups,
 		lhbackupCache:             lhbackups.Cache(),
+		volumes:                   volumes,
 		volumeCache:               volumes.Cache(),
+		backupTargetCache:         backupTargets.Cache(),
 		snapshots:                 snapshots,
 		snapshotCache:             snapshots.Cache(),
 		snapshotContents:          snapshotContents,
 		snapshotContentCache:      snapshotContents.Cache(),
 		snapshotClassCache:        snapshotClass.Cache(),
 		virtSubresourceRestClient: virtSubresourceClient,
+		clientSet:                 management.ClientSet,
+		restConfig:                management.RestConfig,
 		recorder:                  management.NewRecorder(backupControllerName, "", ""),
+		guestHooks:                newGuestHookRunner(),
 	}
 
 	vmBackups.OnChange(ctx, backupControllerName, vmBackupController.OnBackupChange)
@@ -129,16 +140,24 @@ type Handler struct {
 	pvCache                   ctlcorev1.PersistentVolumeCache
 	pvcCache                  ctlcorev1.PersistentVolumeClaimCache
 	secretCache               ctlcorev1.SecretCache
+	podCache                  ctlcorev1.PodCache
 	storageClassCache         ctlstoragev1.StorageClassCache
+	lhbackups                 ctllonghornv2.BackupClient
 	lhbackupCache             ctllonghornv2.BackupCache
+	volumes                   ctllonghornv2.VolumeClient
 	volumeCache               ctllonghornv2.VolumeCache
+	backupTargetCache         ctlharvesterv1.BackupTargetCache
 	snapshots                 ctlsnapshotv1.VolumeSnapshotClient
 	snapshotCache             ctlsnapshotv1.VolumeSnapshotCache
 	snapshotContents          ctlsnapshotv1.VolumeSnapshotContentClient
 	snapshotContentCache      ctlsnapshotv1.VolumeSnapshotContentCache
 	snapshotClassCache        ctlsnapshotv1.VolumeSnapshotClassCache
 	virtSubresourceRestClient rest.Interface
+	clientSet                 kubernetes.Interface
+	restConfig                *rest.Config
 	recorder                  record.EventRecorder
+	guestHooks                *guestHookRunner
+	volumeBackupTargetLock    sync.Mutex
 }
 
 // OnBackupChange handles vm backup object on change and reconcile vm backup status
@@ -147,6 +166,13 @@ func (h *Handler) OnBackupChange(_ string, vmBackup *harvesterv1.VirtualMachineB
 		return nil, nil
 	}
 
+	// thaw the guest file systems once the volume snapshots are taken,
+	// it must be done before the backup is ready because handleBackupReady skips the following reconciliation.
+	vmBackup, err := h.reconcileGuestFreeze(vmBackup)
+	if err != nil {
+		return nil, err
+	}
+
 	if IsBackupReady(vmBackup) {
 		return nil, h.handleBackupReady(vmBackup)
 	}
@@ -154,6 +180,14 @@ func (h *Handler) OnBackupChange(_ string, vmBackup *harvesterv1.VirtualMachineB
 	logrus.Debugf("OnBackupChange: vmBackup name:%s", vmBackup.Name)
 	// set vmBackup init status
 	if IsBackupMissingStatus(vmBackup) {
+		// A promoted VMBackup takes its status from the VM snapshot, the source VM may not exist anymore.
+		if IsPromotedBackup(vmBackup) {
+			if err := h.initPromotedBackup(vmBackup); err != nil {
+				return nil, h.setStatusError(vmBackup, err)
+			}
+			return nil, nil
+		}
+
 		// We cannot get VM outside this block, because we also can sync VMBackup from remote target.
 		// A VMBackup without status is a new VMBackup, so it must have sourceVM.
 		sourceVM, err := h.getBackupSource(vmBackup)
@@ -161,15 +195,17 @@ func (h *Handler) OnBackupChange(_ string, vmBackup *harvesterv1.VirtualMachineB
 			return nil, h.setStatusError(vmBackup, err)
 		}
 
-		if err = h.initBackup(vmBackup, sourceVM); err != nil {
-			return nil, h.setStatusError(vmBackup, err)
-		}
-
-		return nil, nil
+		return nil, h.startBackup(vmBackup, sourceVM)
 	}
 
 	// TODO, make sure status is initialized, and "Lock" the source VM by adding a finalizer and setting snapshotInProgress in status
 
+	// wait for the pre-freeze hook, the volume snapshots of application-consistent backups are taken
+	// only after the guest file systems are frozen or the freeze fails.
+	if isGuestFreezePending(vmBackup) {
+		return nil, nil
+	}
+
 	_, csiDriverVolumeSnapshotClassMap, err := h.getCSIDriverMap(vmBackup)
 	if err != nil {
 		return nil, h.setStatusError(vmBackup, err)
@@ -190,16 +226,21 @@ func (h *Handler) OnBackupChange(_ string, vmBackup *harvesterv1.VirtualMachineB
 
 // OnBackupRemove remove remote vm backup metadata
 func (h *Handler) OnBackupRemove(_ string, vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
-	if vmBackup == nil || vmBackup.Status.BackupTarget == nil {
+	if vmBackup == nil {
 		return nil, nil
 	}
 
-	target, err := settings.DecodeBackupTarget(settings.BackupTargetSet.Get())
-	if err != nil {
+	h.guestHooks.forget(guestHookKey(vmBackup, preFreezeHookPhase), guestHookKey(vmBackup, postThawHookPhase))
+	if vmBackup.Status.BackupTarget == nil {
+		return nil, nil
+	}
+
+	target, err := h.getBackupTarget(vmBackup)
+	if err != nil && !apierrors.IsNotFound(err) {
 		return nil, err
 	}
 
-	if !target.IsDefaultBackupTarget() {
+	if target != nil && !target.IsDefaultBackupTarget() {
 		if err := h.deleteVMBackupMetadata(vmBackup, target); err != nil {
 			return nil, err
 		}
@@ -214,6 +255,12 @@ func (h *Handler) OnBackupRemove(_ string, vmBackup *harvesterv1.VirtualMachineB
 			return nil, err
 		}
 	}
+
+	if IsPromotedBackup(vmBackup) {
+		if err := h.deletePromotedLHBackups(vmBackup); err != nil {
+			return nil, err
+		}
+	}
 	return nil, nil
 }
 
@@ -296,16 +343,7 @@ func (h *Handler) withFreezeFSAnnotation(backup *harvesterv1.VirtualMachineBacku
 		return backup, err
 	}
 
-	hasAgent := false
-
-	for _, condition := range sourceVMI.Status.Conditions {
-		if condition.Type == kubevirtv1.VirtualMachineInstanceAgentConnected {
-			hasAgent = condition.Status == corev1.ConditionTrue
-			break
-		}
-	}
-
-	return h.saveFreezeFSAnnotation(backup, hasAgent)
+	return h.saveFreezeFSAnnotation(backup, IsGuestAgentConnected(sourceVMI))
 }
 
 func (h *Handler) saveFreezeFSAnnotation(backup *harvesterv1.VirtualMachineBackup, value bool) (*harvesterv1.VirtualMachineBackup, error) {
@@ -338,6 +376,16 @@ func (h *Handler) freezeFS(ctx context.Context, vmi *kubevirtv1.VirtualMachineIn
 	return res.Error()
 }
 
+func (h *Handler) thawFS(ctx context.Context, vmi *kubevirtv1.VirtualMachineInstance) error {
+	res := h.virtSubresourceRestClient.Put().
+		Namespace(vmi.Namespace).
+		Resource("virtualmachineinstances").
+		Name(vmi.Name).
+		SubResource("unfreeze").
+		Do(ctx)
+	return res.Error()
+}
+
 // getVolumeBackups helps to build a list of VolumeBackup upon the volume list of backup VM
 func (h *Handler) getVolumeBackups(backup *harvesterv1.VirtualMachineBackup, vm *kubevirtv1.VirtualMachine) ([]harvesterv1.VolumeBackup, error) {
 	sourceVolumes := vm.Spec.Template.Spec.Volumes
@@ -497,6 +545,42 @@ func (h *Handler) getSecretBackupFromSecret(namespace, name string) (*harvesterv
 	return &harvesterv1.SecretBackup{Name: secret.Name, Data: data}, nil
 }
 
+// startBackup initializes the VM backup once the backup throttle lets it start. The scheduled backups are
+// throttled by the schedule before they're created, the manual backups wait here with the queued condition.
+func (h *Handler) startBackup(vmBackup *harvesterv1.VirtualMachineBackup, vm *kubevirtv1.VirtualMachine) error {
+	if vmBackup.Labels[util.LabelSVMBackupUID] != "" {
+		if err := h.initBackup(vmBackup, vm); err != nil {
+			return h.setStatusError(vmBackup, err)
+		}
+		return nil
+	}
+
+	ThrottleLock.Lock()
+	defer ThrottleLock.Unlock()
+
+	reason, msg, retryAfter, err := CheckBackupThrottle(h.vmBackups, h.vmsCache, vmBackup, nil, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if reason != "" {
+		h.vmBackupController.EnqueueAfter(vmBackup.Namespace, vmBackup.Name, retryAfter)
+		if IsBackupQueued(vmBackup) && harvesterv1.BackupConditionQueued.GetReason(vmBackup) == string(reason) {
+			return nil
+		}
+		vmBackupCpy := vmBackup.DeepCopy()
+		setCondition(vmBackupCpy, harvesterv1.BackupConditionQueued, true, string(reason), msg)
+		_, err := h.vmBackups.Update(vmBackupCpy)
+		return err
+	}
+
+	// initBackup replaces the status, the queued condition is dropped with it
+	if err := h.initBackup(vmBackup, vm); err != nil {
+		return h.setStatusError(vmBackup, err)
+	}
+	return nil
+}
+
 // initBackup initialize VM backup status and annotation
 func (h *Handler) initBackup(backup *harvesterv1.VirtualMachineBackup, vm *kubevirtv1.VirtualMachine) error {
 	var err error
@@ -528,12 +612,13 @@ func (h *Handler) initBackup(backup *harvesterv1.VirtualMachineBackup, vm *kubev
 	}
 
 	if backup.Spec.Type == harvesterv1.Backup {
-		target, err := settings.DecodeBackupTarget(settings.BackupTargetSet.Get())
+		target, err := h.getBackupTarget(backup)
 		if err != nil {
 			return err
 		}
 
-		backupCpy.Status.BackupTarget = &harvesterv1.BackupTarget{
+		backupCpy.Status.BackupTarget = &harvesterv1.BackupTargetInfo{
+			Name:         target.Name,
 			Endpoint:     target.Endpoint,
 			BucketName:   target.BucketName,
 			BucketRegion: target.BucketRegion,
@@ -569,6 +654,12 @@ func (h *Handler) freezeFsIfNeeded(vmBackup *harvesterv1.VirtualMachineBackup, v
 	if vb.LonghornBackupName != nil {
 		return nil
 	}
+	// the guest file systems are frozen by reconcileGuestFreeze for application-consistent backups,
+	// the volumes are frozen one by one as usual if it fails or times out.
+	if vmBackup.Spec.ApplicationConsistent != nil &&
+		harvesterv1.BackupConditionApplicationConsistent.GetReason(vmBackup) == guestFreezeReasonFrozen {
+		return nil
+	}
 
 	return h.tryFreezeFS(vmBackup)
 }
@@ -600,11 +691,30 @@ func (h *Handler) reconcileVolumeSnapshots(vmBackup *harvesterv1.VirtualMachineB
 			return nil
 		}
 
+		// a promoted VMBackup creates the Longhorn backup from the snapshot first,
+		// the volume snapshot is created from the Longhorn backup once it's completed.
+		if volumeSnapshot == nil && IsPromotedBackup(vmBackupCpy) {
+			lhBackupName, completed, err := h.reconcilePromotedLHBackup(vmBackupCpy, volumeBackup)
+			if err != nil {
+				return err
+			}
+			vmBackupCpy.Status.VolumeBackups[i].LonghornBackupName = ptr.To(lhBackupName)
+			volumeBackup.LonghornBackupName = ptr.To(lhBackupName)
+			if !completed {
+				h.vmBackupController.EnqueueAfter(vmBackup.Namespace, vmBackup.Name, 5*time.Second)
+				continue
+			}
+		}
+
 		if volumeSnapshot == nil {
 			if err := h.freezeFsIfNeeded(vmBackupCpy, volumeBackup); err != nil {
 				return err
 			}
 
+			if err := h.configureVolumeBackupTarget(vmBackupCpy, volumeBackup); err != nil {
+				return err
+			}
+
 			volumeSnapshotClass := csiDriverVolumeSnapshotClassMap[volumeBackup.CSIDriverName]
 			volumeSnapshot, err = h.createVolumeSnapshot(vmBackupCpy, volumeBackup, &volumeSnapshotClass)
 			if err != nil {
@@ -638,6 +748,73 @@ func (h *Handler) reconcileVolumeSnapshots(vmBackup *harvesterv1.VirtualMachineB
 	return nil
 }
 
+// getBackupTarget returns the backup target referenced by the vm backup.
+// The backup-target setting is returned when no backup target is referenced.
+func (h *Handler) getBackupTarget(vmBackup *harvesterv1.VirtualMachineBackup) (*settings.BackupTarget, error) {
+	return backuputil.GetBackupTarget(h.backupTargetCache, vmBackup.Spec.BackupTargetName)
+}
+
+// configureVolumeBackupTarget points the Longhorn volume to the Longhorn backup target serving the vm backup,
+// so the Longhorn backup created from the `bak` type VolumeSnapshot is stored to the right backup target.
+// Longhorn only takes the backup target from the volume, so the volume isn't pointed to another backup target
+// while a backup of it to the current one is in progress, the vm backup waits for it instead.
+func (h *Handler) configureVolumeBackupTarget(vmBackup *harvesterv1.VirtualMachineBackup, volumeBackup harvesterv1.VolumeBackup) error {
+	if vmBackup.Spec.Type != harvesterv1.Backup || volumeBackup.CSIDriverName != util.CSIProvisionerLonghorn ||
+		volumeBackup.LonghornBackupName != nil {
+		return nil
+	}
+
+	// the check and the update of the volume are serialized, otherwise two vm backups can both see no conflict
+	h.volumeBackupTargetLock.Lock()
+	defer h.volumeBackupTargetLock.Unlock()
+
+	volumeName := volumeBackup.PersistentVolumeClaim.Spec.VolumeName
+	volume, err := h.volumes.Get(util.LonghornSystemNamespaceName, volumeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get volume %s/%s: %w", util.LonghornSystemNamespaceName, volumeName, err)
+	}
+
+	lhBackupTargetName := backuputil.GetLonghornBackupTargetName(vmBackup.Spec.BackupTargetName)
+	if volume.Spec.BackupTargetName == lhBackupTargetName {
+		return nil
+	}
+
+	if conflict, err := h.getInFlightVolumeBackup(vmBackup, volumeName, volume.Spec.BackupTargetName); err != nil {
+		return err
+	} else if conflict != nil {
+		return fmt.Errorf("volume %s is being backed up to another backup target by vmbackup %s/%s, waiting for it to complete",
+			volumeName, conflict.Namespace, conflict.Name)
+	}
+
+	volumeCpy := volume.DeepCopy()
+	volumeCpy.Spec.BackupTargetName = lhBackupTargetName
+	_, err = h.volumes.Update(volumeCpy)
+	return err
+}
+
+// getInFlightVolumeBackup returns the other vm backup backing up the volume to the Longhorn backup target which isn't
+// done yet. The vm backups are listed from the API server since the cache may not have the volume backups just started.
+func (h *Handler) getInFlightVolumeBackup(vmBackup *harvesterv1.VirtualMachineBackup, volumeName, lhBackupTargetName string) (*harvesterv1.VirtualMachineBackup, error) {
+	vmBackups, err := h.vmBackups.List(vmBackup.Namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range vmBackups.Items {
+		other := &vmBackups.Items[i]
+		if other.UID == vmBackup.UID || other.Spec.Type != harvesterv1.Backup || !IsBackupProgressing(other) ||
+			backuputil.GetLonghornBackupTargetName(other.Spec.BackupTargetName) != lhBackupTargetName {
+			continue
+		}
+		for _, vb := range other.Status.VolumeBackups {
+			if vb.PersistentVolumeClaim.Spec.VolumeName == volumeName && (vb.ReadyToUse == nil || !*vb.ReadyToUse) {
+				return other, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
 func (h *Handler) getVolumeSnapshot(namespace, name string) (*snapshotv1.VolumeSnapshot, error) {
 	snapshot, err := h.snapshotCache.Get(namespace, name)
 	if err != nil {
@@ -806,7 +983,7 @@ func (h *Handler) setStatusError(vmBackup *harvesterv1.VirtualMachineBackup, err
 func (h *Handler) deleteVMBackupMetadata(vmBackup *harvesterv1.VirtualMachineBackup, target *settings.BackupTarget) error {
 	var err error
 	if target == nil {
-		if target, err = settings.DecodeBackupTarget(settings.BackupTargetSet.Get()); err != nil {
+		if target, err = h.getBackupTarget(vmBackup); err != nil {
 			return err
 		}
 	}
@@ -841,7 +1018,7 @@ func (h *Handler) uploadVMBackupMetadata(vmBackup *harvesterv1.VirtualMachineBac
 		return fmt.Errorf("no backup target in vmbackup.status")
 	}
 
-	target, err := settings.DecodeBackupTarget(settings.BackupTargetSet.Get())
+	target, err := h.getBackupTarget(vmBackup)
 	if err != nil {
 		return err
 	}
@@ -986,7 +1163,7 @@ func (h *Handler) configureBackupTargetOnStatus(vmBackup *harvesterv1.VirtualMac
 
 	logrus.Debugf("configure backup target from annotation to status for vm backup %s/%s", vmBackup.Namespace, vmBackup.Name)
 	vmBackupCpy := vmBackup.DeepCopy()
-	vmBackupCpy.Status.BackupTarget = &harvesterv1.BackupTarget{
+	vmBackupCpy.Status.BackupTarget = &harvesterv1.BackupTargetInfo{
 		Endpoint:     vmBackup.Annotations[backupTargetAnnotation],
 		BucketName:   vmBackup.Annotations[backupBucketNameAnnotation],
 		BucketRegion: vmBackup.Annotations[backupBucketRegionAnnotation],
@@ -1076,13 +1253,13 @@ func (h *Handler) shouldSkipVolumeSnapshotUpdate(vmBackup *harvesterv1.VirtualMa
 		return true, nil
 	}
 
-	backupTargetValue := settings.BackupTargetSet.Get()
-	target, err := settings.DecodeBackupTarget(backupTargetValue)
+	target, err := h.getBackupTarget(vmBackup)
 	if err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{
-			"name":      vmBackup.Name,
-			"namespace": vmBackup.Namespace,
-		}).Warnf("failed to decode backup target %s", backupTargetValue)
+			"name":         vmBackup.Name,
+			"namespace":    vmBackup.Namespace,
+			"backupTarget": vmBackup.Spec.BackupTargetName,
+		}).Warn("failed to get backup target")
 		return true, err
 	}
 
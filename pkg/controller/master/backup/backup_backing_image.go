@@ -22,7 +22,6 @@ import (
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	ctllonghornv1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta2"
 	"github.com/harvester/harvester/pkg/ref"
-	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
 	backuputil "github.com/harvester/harvester/pkg/util/backup"
 )
@@ -40,6 +39,7 @@ type backupBackingImageHandler struct {
 	vmImageCache            ctlharvesterv1.VirtualMachineImageCache
 	backingImageCache       ctllonghornv1.BackingImageCache
 	backupBackingImageCache ctllonghornv1.BackupBackingImageCache
+	backupTargetCache       ctlharvesterv1.BackupTargetCache
 }
 
 // RegisterBackupBackingImage register the backup backing image controller and resync vmimage metadata when backup backing image is completed
@@ -50,6 +50,7 @@ func RegisterBackupBackingImage(ctx context.Context, management *config.Manageme
 	longhornSettings := management.LonghornFactory.Longhorn().V1beta2().Setting()
 	backingImages := management.LonghornFactory.Longhorn().V1beta2().BackingImage()
 	backupBackingImages := management.LonghornFactory.Longhorn().V1beta2().BackupBackingImage()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 
 	backupBackingImageController := &backupBackingImageHandler{
 		ctx:                     ctx,
@@ -60,6 +61,7 @@ func RegisterBackupBackingImage(ctx context.Context, management *config.Manageme
 		vmImageCache:            vmImages.Cache(),
 		backingImageCache:       backingImages.Cache(),
 		backupBackingImageCache: backupBackingImages.Cache(),
+		backupTargetCache:       backupTargets.Cache(),
 	}
 
 	backupBackingImages.OnChange(ctx, backupBackingImageControllerName, backupBackingImageController.OnBackupBackingImageChange)
@@ -97,8 +99,12 @@ func (h *backupBackingImageHandler) OnBackupBackingImageChange(_ string, backupB
 		return nil, err
 	}
 
-	target, err := settings.DecodeBackupTarget(settings.BackupTargetSet.Get())
+	// the metadata is uploaded to the backup target the backing image is backed up to
+	target, err := backuputil.GetBackupTarget(h.backupTargetCache, backuputil.GetBackupTargetNameFromLonghorn(backupBackingImage.Spec.BackupTargetName))
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
@@ -148,7 +154,8 @@ func (h *backupBackingImageHandler) OnBackupBackingImageChange(_ string, backupB
 
 		vmImageCopy := vmImage.DeepCopy()
 		harvesterv1.MetadataReady.True(vmImageCopy)
-		vmImageCopy.Status.BackupTarget = &harvesterv1.BackupTarget{
+		vmImageCopy.Status.BackupTarget = &harvesterv1.BackupTargetInfo{
+			Name:         target.Name,
 			Endpoint:     target.Endpoint,
 			BucketName:   target.BucketName,
 			BucketRegion: target.BucketRegion,
@@ -0,0 +1,436 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	backuputil "github.com/harvester/harvester/pkg/util/backup"
+)
+
+const (
+	defaultFreezeTimeoutSeconds = 60
+	defaultHookTimeoutSeconds   = 30
+
+	guestFreezeReasonFrozen           = "Frozen"
+	guestFreezeReasonThawed           = "Thawed"
+	guestFreezeReasonAgentUnavailable = "GuestAgentUnavailable"
+	guestFreezeReasonPreFreezeHook    = "PreFreezeHookFailed"
+	guestFreezeReasonFreezeFailed     = "FreezeFailed"
+	guestFreezeReasonFreezeTimeout    = "FreezeTimeout"
+
+	guestHookFailedEvent = "GuestHookFailed"
+
+	launcherComputeContainer = "compute"
+	libvirtSocket            = "/var/run/libvirt/virtqemud-sock"
+	guestExecStatusInterval  = 1 * time.Second
+)
+
+// reconcileGuestFreeze handles the guest file systems of application-consistent backups.
+// The file systems are frozen before any volume snapshot is created, and thawed once all volume snapshots are taken
+// or the freeze timeout is exceeded. The result is recorded in the ApplicationConsistent condition,
+// the backup continues as a crash-consistent one if the file systems can't be frozen.
+// The guest hooks are taken from the annotation of the source VM and run in the background.
+func (h *Handler) reconcileGuestFreeze(vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	spec := vmBackup.Spec.ApplicationConsistent
+	if spec == nil {
+		return vmBackup, nil
+	}
+
+	switch {
+	case harvesterv1.BackupConditionApplicationConsistent.GetStatus(vmBackup) == "":
+		if IsBackupMissingStatus(vmBackup) || IsBackupReady(vmBackup) || !hasVolumeSnapshotToTake(vmBackup) {
+			return vmBackup, nil
+		}
+		return h.freezeGuest(vmBackup, spec)
+	case harvesterv1.BackupConditionApplicationConsistent.GetReason(vmBackup) == guestFreezeReasonFrozen:
+		return h.thawGuestIfNeeded(vmBackup, spec)
+	}
+
+	return vmBackup, nil
+}
+
+// isGuestFreezePending returns true if the guest file systems of the application-consistent backup
+// are going to be frozen, but the freeze isn't decided yet, e.g. the pre-freeze hook is running.
+func isGuestFreezePending(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	return vmBackup.Spec.ApplicationConsistent != nil &&
+		harvesterv1.BackupConditionApplicationConsistent.GetStatus(vmBackup) == "" &&
+		!IsBackupMissingStatus(vmBackup) && !IsBackupReady(vmBackup) && hasVolumeSnapshotToTake(vmBackup)
+}
+
+// hasVolumeSnapshotToTake returns false if all volume snapshots are taken,
+// or the vm backup is synced from the backup target, which doesn't take any volume snapshot.
+func hasVolumeSnapshotToTake(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	for _, vb := range vmBackup.Status.VolumeBackups {
+		if vb.LonghornBackupName == nil && vb.CreationTime == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) freezeGuest(vmBackup *harvesterv1.VirtualMachineBackup, spec *harvesterv1.ApplicationConsistentSpec) (*harvesterv1.VirtualMachineBackup, error) {
+	vmBackupCpy := vmBackup.DeepCopy()
+
+	sourceVMI, err := h.getBackupSourceInstance(vmBackup)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return vmBackup, err
+	}
+
+	reason, err := h.tryFreezeGuest(vmBackup, sourceVMI, spec)
+	if errors.Is(err, errGuestHookRunning) {
+		// the backup is enqueued again once the hook exits
+		return vmBackup, nil
+	}
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"namespace": vmBackup.Namespace,
+			"name":      vmBackup.Name,
+		}).Warn("failed to freeze guest file systems, fall back to crash-consistent backup")
+		setCondition(vmBackupCpy, harvesterv1.BackupConditionApplicationConsistent, false, reason, err.Error())
+	} else {
+		setCondition(vmBackupCpy, harvesterv1.BackupConditionApplicationConsistent, true, guestFreezeReasonFrozen, "Guest file systems are frozen")
+		harvesterv1.BackupConditionApplicationConsistent.LastUpdated(vmBackupCpy, time.Now().UTC().Format(time.RFC3339))
+		// thaw the guest file systems in time even if no volume snapshot changes
+		h.vmBackupController.EnqueueAfter(vmBackup.Namespace, vmBackup.Name, getFreezeTimeout(spec))
+	}
+
+	return h.vmBackups.Update(vmBackupCpy)
+}
+
+// tryFreezeGuest runs the pre-freeze hook and freezes the guest file systems,
+// the condition reason is returned along with the error. errGuestHookRunning is returned while the hook runs.
+func (h *Handler) tryFreezeGuest(vmBackup *harvesterv1.VirtualMachineBackup, vmi *kubevirtv1.VirtualMachineInstance, spec *harvesterv1.ApplicationConsistentSpec) (string, error) {
+	if vmi == nil || !IsGuestAgentConnected(vmi) {
+		return guestFreezeReasonAgentUnavailable, errors.New("virtual machine must be running with qemu-guest-agent connected")
+	}
+
+	hooks, err := h.getGuestHooks(vmBackup)
+	if err != nil {
+		return guestFreezeReasonPreFreezeHook, err
+	}
+	if hooks != nil && hooks.PreFreezeHook != nil {
+		hook := hooks.PreFreezeHook
+		done, err := h.guestHooks.run(guestHookKey(vmBackup, preFreezeHookPhase), func() error {
+			return h.runGuestHook(context.Background(), vmi, hook)
+		}, func(error) {
+			h.vmBackupController.Enqueue(vmBackup.Namespace, vmBackup.Name)
+		})
+		if !done {
+			return "", errGuestHookRunning
+		}
+		if err != nil {
+			return guestFreezeReasonPreFreezeHook, err
+		}
+	}
+
+	if err := h.freezeFS(context.Background(), vmi, getFreezeTimeout(spec)); err != nil {
+		return guestFreezeReasonFreezeFailed, err
+	}
+	return "", nil
+}
+
+// getGuestHooks returns the guest hooks in the annotation of the source VM, so that the users who can only
+// create backups can't run commands in the guest.
+func (h *Handler) getGuestHooks(vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.GuestHooks, error) {
+	sourceVM, err := h.vmsCache.Get(vmBackup.Namespace, vmBackup.Spec.Source.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return backuputil.GetGuestHooks(sourceVM)
+}
+
+func (h *Handler) thawGuestIfNeeded(vmBackup *harvesterv1.VirtualMachineBackup, spec *harvesterv1.ApplicationConsistentSpec) (*harvesterv1.VirtualMachineBackup, error) {
+	frozenAt, err := time.Parse(time.RFC3339, harvesterv1.BackupConditionApplicationConsistent.GetLastUpdated(vmBackup))
+	if err != nil {
+		return vmBackup, fmt.Errorf("invalid freeze time of vm backup %s/%s: %w", vmBackup.Namespace, vmBackup.Name, err)
+	}
+
+	timeout := time.Now().After(frozenAt.Add(getFreezeTimeout(spec)))
+	if !timeout && hasVolumeSnapshotToTake(vmBackup) {
+		return vmBackup, nil
+	}
+
+	vmBackupCpy := vmBackup.DeepCopy()
+	if timeout && hasVolumeSnapshotToTake(vmBackup) {
+		setCondition(vmBackupCpy, harvesterv1.BackupConditionApplicationConsistent, false, guestFreezeReasonFreezeTimeout,
+			fmt.Sprintf("Guest file systems are thawed before all volume snapshots are taken in %s", getFreezeTimeout(spec)))
+	} else {
+		setCondition(vmBackupCpy, harvesterv1.BackupConditionApplicationConsistent, true, guestFreezeReasonThawed,
+			"Volume snapshots are taken while guest file systems are frozen")
+	}
+
+	sourceVMI, err := h.getBackupSourceInstance(vmBackup)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return vmBackup, err
+	}
+
+	// the guest is thawed along with the VMI stopping
+	if sourceVMI != nil {
+		if err := h.thawFS(context.Background(), sourceVMI); err != nil {
+			return vmBackup, fmt.Errorf("failed to thaw guest file systems of vm %s/%s: %w", sourceVMI.Namespace, sourceVMI.Name, err)
+		}
+	}
+
+	vmBackupCpy, err = h.vmBackups.Update(vmBackupCpy)
+	if err != nil {
+		return vmBackup, err
+	}
+
+	// the post-thaw hook doesn't affect the consistency of volume snapshots, it runs after the condition is saved
+	// so that it isn't run again, and only its failure is reported.
+	if sourceVMI != nil {
+		h.runPostThawHook(vmBackupCpy, sourceVMI)
+	}
+	return vmBackupCpy, nil
+}
+
+func (h *Handler) runPostThawHook(vmBackup *harvesterv1.VirtualMachineBackup, vmi *kubevirtv1.VirtualMachineInstance) {
+	hooks, err := h.getGuestHooks(vmBackup)
+	if err != nil {
+		h.recorder.Eventf(vmBackup, corev1.EventTypeWarning, guestHookFailedEvent, "Post-thaw hook failed: %s", err.Error())
+		return
+	}
+	if hooks == nil || hooks.PostThawHook == nil {
+		return
+	}
+
+	hook := hooks.PostThawHook
+	key := guestHookKey(vmBackup, postThawHookPhase)
+	h.guestHooks.run(key, func() error {
+		return h.runGuestHook(context.Background(), vmi, hook)
+	}, func(err error) {
+		// nothing waits for the result of the post-thaw hook
+		h.guestHooks.forget(key)
+		if err != nil {
+			h.recorder.Eventf(vmBackup, corev1.EventTypeWarning, guestHookFailedEvent, "Post-thaw hook failed: %s", err.Error())
+		}
+	})
+}
+
+const (
+	preFreezeHookPhase = "pre-freeze"
+	postThawHookPhase  = "post-thaw"
+)
+
+var errGuestHookRunning = errors.New("guest hook is running")
+
+func guestHookKey(vmBackup *harvesterv1.VirtualMachineBackup, phase string) string {
+	return fmt.Sprintf("%s/%s", vmBackup.UID, phase)
+}
+
+// guestHookRunner runs the guest hooks in the background, so that a slow hook doesn't block the workers
+// of the backup controller. A hook is bounded by its own timeout.
+type guestHookRunner struct {
+	mu   sync.Mutex
+	runs map[string]*guestHookRun
+}
+
+type guestHookRun struct {
+	done bool
+	err  error
+}
+
+func newGuestHookRunner() *guestHookRunner {
+	return &guestHookRunner{runs: map[string]*guestHookRun{}}
+}
+
+// run starts f in the background the first time it's called with the key, and returns the result of f
+// once it's done. onExit is called with the result after f returns.
+func (r *guestHookRunner) run(key string, f func() error, onExit func(error)) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if run, ok := r.runs[key]; ok {
+		if !run.done {
+			return false, nil
+		}
+		delete(r.runs, key)
+		return true, run.err
+	}
+
+	run := &guestHookRun{}
+	r.runs[key] = run
+	go func() {
+		err := f()
+		r.mu.Lock()
+		run.done, run.err = true, err
+		r.mu.Unlock()
+		onExit(err)
+	}()
+	return false, nil
+}
+
+// forget drops the results nobody is going to read, e.g. the ones of a removed backup
+func (r *guestHookRunner) forget(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		delete(r.runs, key)
+	}
+}
+
+func getFreezeTimeout(spec *harvesterv1.ApplicationConsistentSpec) time.Duration {
+	if spec.FreezeTimeoutSeconds <= 0 {
+		return defaultFreezeTimeoutSeconds * time.Second
+	}
+	return time.Duration(spec.FreezeTimeoutSeconds) * time.Second
+}
+
+// IsGuestAgentConnected returns true if the guest agent of the VMI is connected
+func IsGuestAgentConnected(vmi *kubevirtv1.VirtualMachineInstance) bool {
+	for _, condition := range vmi.Status.Conditions {
+		if condition.Type == kubevirtv1.VirtualMachineInstanceAgentConnected {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+type guestExecResponse struct {
+	Return struct {
+		PID int `json:"pid"`
+	} `json:"return"`
+}
+
+type guestExecStatusResponse struct {
+	Return struct {
+		Exited   bool   `json:"exited"`
+		ExitCode int    `json:"exitcode"`
+		ErrData  string `json:"err-data"`
+	} `json:"return"`
+}
+
+// runGuestHook runs the hook command in the guest with the qemu-guest-agent guest-exec command,
+// and waits for its exit until the hook timeout is exceeded.
+func (h *Handler) runGuestHook(ctx context.Context, vmi *kubevirtv1.VirtualMachineInstance, hook *harvesterv1.GuestHook) error {
+	if len(hook.Command) == 0 {
+		return errors.New("hook command is empty")
+	}
+
+	timeoutSeconds := hook.TimeoutSeconds
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = defaultHookTimeoutSeconds
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	var execResp guestExecResponse
+	if err := h.guestAgentCommand(ctx, vmi, "guest-exec", map[string]interface{}{
+		"path":           hook.Command[0],
+		"arg":            hook.Command[1:],
+		"capture-output": true,
+	}, &execResp); err != nil {
+		return fmt.Errorf("failed to run hook %v: %w", hook.Command, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("hook %v doesn't exit in %d seconds", hook.Command, timeoutSeconds)
+		case <-time.After(guestExecStatusInterval):
+		}
+
+		var statusResp guestExecStatusResponse
+		if err := h.guestAgentCommand(ctx, vmi, "guest-exec-status", map[string]interface{}{
+			"pid": execResp.Return.PID,
+		}, &statusResp); err != nil {
+			return fmt.Errorf("failed to get status of hook %v: %w", hook.Command, err)
+		}
+
+		if !statusResp.Return.Exited {
+			continue
+		}
+		if statusResp.Return.ExitCode != 0 {
+			stderr, _ := base64.StdEncoding.DecodeString(statusResp.Return.ErrData)
+			return fmt.Errorf("hook %v exits with code %d: %s", hook.Command, statusResp.Return.ExitCode, string(stderr))
+		}
+		return nil
+	}
+}
+
+// guestAgentCommand sends the qemu-guest-agent command with virsh in the compute container of the virt-launcher pod,
+// since KubeVirt doesn't expose a subresource to run arbitrary guest agent commands.
+func (h *Handler) guestAgentCommand(ctx context.Context, vmi *kubevirtv1.VirtualMachineInstance, command string, arguments map[string]interface{}, response interface{}) error {
+	pod, err := h.getLauncherPod(vmi)
+	if err != nil {
+		return err
+	}
+
+	agentCommand, err := json.Marshal(map[string]interface{}{
+		"execute":   command,
+		"arguments": arguments,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the libvirt daemon runs in session mode if the virt-launcher is non-root
+	mode := "session"
+	if vmi.Status.RuntimeUser == 0 {
+		mode = "system"
+	}
+
+	req := h.clientSet.CoreV1().RESTClient().Post().
+		Namespace(pod.Namespace).
+		Resource("pods").
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: launcherComputeContainer,
+			Command: []string{
+				"virsh", "-c", fmt.Sprintf("qemu+unix:///%s?socket=%s", mode, libvirtSocket),
+				"qemu-agent-command", fmt.Sprintf("%s_%s", vmi.Namespace, vmi.Name), string(agentCommand),
+			},
+			Stdout: true,
+			Stderr: true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(h.restConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}); err != nil {
+		return fmt.Errorf("%w: %s", err, stderr.String())
+	}
+
+	return json.Unmarshal(stdout.Bytes(), response)
+}
+
+func (h *Handler) getLauncherPod(vmi *kubevirtv1.VirtualMachineInstance) (*corev1.Pod, error) {
+	pods, err := h.podCache.List(vmi.Namespace, labels.SelectorFromSet(labels.Set{
+		kubevirtv1.CreatedByLabel: string(vmi.UID),
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && pod.Spec.NodeName == vmi.Status.NodeName {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("can't find running virt-launcher pod of vm %s/%s", vmi.Namespace, vmi.Name)
+}
@@ -0,0 +1,286 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newFreezeHandler(clientset *fake.Clientset) *Handler {
+	return &Handler{
+		vmBackups:  fakeclients.VMBackupClient(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+		vmsCache:   fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+		vmisCache:  fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+		guestHooks: newGuestHookRunner(),
+	}
+}
+
+func newFreezeBackup(applicationConsistent bool) *harvesterv1.VirtualMachineBackup {
+	vmBackup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backup", UID: "backup-uid"},
+		Spec: harvesterv1.VirtualMachineBackupSpec{
+			Source: corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To(kubevirtv1.SchemeGroupVersion.Group),
+				Kind:     kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+				Name:     "vm",
+			},
+			Type: harvesterv1.Backup,
+		},
+		Status: harvesterv1.VirtualMachineBackupStatus{
+			SourceSpec:    &harvesterv1.VirtualMachineSourceSpec{},
+			VolumeBackups: []harvesterv1.VolumeBackup{{Name: ptr.To("volume")}},
+		},
+	}
+	if applicationConsistent {
+		vmBackup.Spec.ApplicationConsistent = &harvesterv1.ApplicationConsistentSpec{}
+	}
+	return vmBackup
+}
+
+func newFreezeVM(hooks string) *kubevirtv1.VirtualMachine {
+	vm := &kubevirtv1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"}}
+	if hooks != "" {
+		vm.Annotations = map[string]string{util.AnnotationBackupGuestHooks: hooks}
+	}
+	return vm
+}
+
+func newFreezeVMI() *kubevirtv1.VirtualMachineInstance {
+	return &kubevirtv1.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"},
+		Status: kubevirtv1.VirtualMachineInstanceStatus{
+			Conditions: []kubevirtv1.VirtualMachineInstanceCondition{{
+				Type:   kubevirtv1.VirtualMachineInstanceAgentConnected,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	}
+}
+
+func Test_guestHookRunner(t *testing.T) {
+	runner := newGuestHookRunner()
+	release := make(chan struct{})
+	exited := make(chan error, 1)
+	hookErr := errors.New("hook failed")
+	hook := func() error {
+		<-release
+		return hookErr
+	}
+	onExit := func(err error) {
+		exited <- err
+	}
+
+	done, err := runner.run("key", hook, onExit)
+	assert.False(t, done)
+	assert.NoError(t, err)
+
+	// the hook isn't started again while it runs
+	done, err = runner.run("key", func() error {
+		t.Fatal("the hook is started twice")
+		return nil
+	}, onExit)
+	assert.False(t, done)
+	assert.NoError(t, err)
+
+	close(release)
+	assert.Equal(t, hookErr, <-exited)
+
+	done, err = runner.run("key", hook, onExit)
+	assert.True(t, done)
+	assert.Equal(t, hookErr, err)
+
+	// the result is read once, the next run starts the hook again
+	done, _ = runner.run("key", hook, onExit)
+	assert.False(t, done)
+	assert.Equal(t, hookErr, <-exited)
+	runner.forget("key")
+	assert.Empty(t, runner.runs)
+}
+
+func Test_isGuestFreezePending(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*harvesterv1.VirtualMachineBackup)
+		expected bool
+	}{
+		{
+			name:     "freeze isn't decided",
+			modify:   func(*harvesterv1.VirtualMachineBackup) {},
+			expected: true,
+		},
+		{
+			name: "crash-consistent backup",
+			modify: func(vmBackup *harvesterv1.VirtualMachineBackup) {
+				vmBackup.Spec.ApplicationConsistent = nil
+			},
+			expected: false,
+		},
+		{
+			name: "guest is frozen",
+			modify: func(vmBackup *harvesterv1.VirtualMachineBackup) {
+				setCondition(vmBackup, harvesterv1.BackupConditionApplicationConsistent, true, guestFreezeReasonFrozen, "")
+			},
+			expected: false,
+		},
+		{
+			name: "freeze failed",
+			modify: func(vmBackup *harvesterv1.VirtualMachineBackup) {
+				setCondition(vmBackup, harvesterv1.BackupConditionApplicationConsistent, false, guestFreezeReasonPreFreezeHook, "")
+			},
+			expected: false,
+		},
+		{
+			name: "status isn't initialized",
+			modify: func(vmBackup *harvesterv1.VirtualMachineBackup) {
+				vmBackup.Status.VolumeBackups = nil
+			},
+			expected: false,
+		},
+		{
+			name: "volume snapshots are taken",
+			modify: func(vmBackup *harvesterv1.VirtualMachineBackup) {
+				vmBackup.Status.VolumeBackups[0].CreationTime = &metav1.Time{}
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vmBackup := newFreezeBackup(true)
+			tc.modify(vmBackup)
+			assert.Equal(t, tc.expected, isGuestFreezePending(vmBackup))
+		})
+	}
+}
+
+func Test_freezeGuest(t *testing.T) {
+	tests := []struct {
+		name   string
+		hooks  string
+		runs   map[string]*guestHookRun
+		vmi    bool
+		reason string
+	}{
+		{
+			name:   "guest agent unavailable",
+			vmi:    false,
+			reason: guestFreezeReasonAgentUnavailable,
+		},
+		{
+			name:   "invalid hooks annotation",
+			hooks:  `{"preFreezeHook":{"command":[]}}`,
+			vmi:    true,
+			reason: guestFreezeReasonPreFreezeHook,
+		},
+		{
+			name:   "pre-freeze hook is running",
+			hooks:  `{"preFreezeHook":{"command":["/usr/bin/flush"]}}`,
+			runs:   map[string]*guestHookRun{"backup-uid/pre-freeze": {}},
+			vmi:    true,
+			reason: "",
+		},
+		{
+			name:   "pre-freeze hook failed",
+			hooks:  `{"preFreezeHook":{"command":["/usr/bin/flush"]}}`,
+			runs:   map[string]*guestHookRun{"backup-uid/pre-freeze": {done: true, err: errors.New("exit code 1")}},
+			vmi:    true,
+			reason: guestFreezeReasonPreFreezeHook,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vmBackup := newFreezeBackup(true)
+			clientset := fake.NewSimpleClientset(vmBackup, newFreezeVM(tc.hooks))
+			if tc.vmi {
+				require.NoError(t, clientset.Tracker().Add(newFreezeVMI()))
+			}
+			h := newFreezeHandler(clientset)
+			if tc.runs != nil {
+				h.guestHooks.runs = tc.runs
+			}
+
+			_, err := h.freezeGuest(vmBackup, vmBackup.Spec.ApplicationConsistent)
+			require.NoError(t, err)
+
+			stored, err := clientset.HarvesterhciV1beta1().VirtualMachineBackups("default").Get(context.TODO(), "backup", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.reason, harvesterv1.BackupConditionApplicationConsistent.GetReason(stored))
+			if tc.reason != "" {
+				assert.True(t, harvesterv1.BackupConditionApplicationConsistent.IsFalse(stored))
+			}
+		})
+	}
+}
+
+func Test_freezeFsIfNeeded(t *testing.T) {
+	tests := []struct {
+		name           string
+		modify         func(*harvesterv1.VirtualMachineBackup)
+		volumeBackup   harvesterv1.VolumeBackup
+		expectedFreeze bool
+	}{
+		{
+			name:           "crash-consistent backup",
+			modify:         func(vmBackup *harvesterv1.VirtualMachineBackup) { vmBackup.Spec.ApplicationConsistent = nil },
+			expectedFreeze: true,
+		},
+		{
+			name:           "volume backup synced from the backup target",
+			modify:         func(vmBackup *harvesterv1.VirtualMachineBackup) { vmBackup.Spec.ApplicationConsistent = nil },
+			volumeBackup:   harvesterv1.VolumeBackup{LonghornBackupName: ptr.To("backup-volume")},
+			expectedFreeze: false,
+		},
+		{
+			name: "guest frozen by the application-consistent backup",
+			modify: func(vmBackup *harvesterv1.VirtualMachineBackup) {
+				setCondition(vmBackup, harvesterv1.BackupConditionApplicationConsistent, true, guestFreezeReasonFrozen, "")
+			},
+			expectedFreeze: false,
+		},
+		{
+			name: "application-consistent freeze failed",
+			modify: func(vmBackup *harvesterv1.VirtualMachineBackup) {
+				setCondition(vmBackup, harvesterv1.BackupConditionApplicationConsistent, false, guestFreezeReasonFreezeFailed, "")
+			},
+			expectedFreeze: true,
+		},
+		{
+			name: "application-consistent freeze timed out",
+			modify: func(vmBackup *harvesterv1.VirtualMachineBackup) {
+				setCondition(vmBackup, harvesterv1.BackupConditionApplicationConsistent, false, guestFreezeReasonFreezeTimeout, "")
+			},
+			expectedFreeze: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vmBackup := newFreezeBackup(true)
+			tc.modify(vmBackup)
+			clientset := fake.NewSimpleClientset(vmBackup)
+			h := newFreezeHandler(clientset)
+
+			// the source VM isn't running, so the default freeze only records it can't freeze the volume
+			require.NoError(t, h.freezeFsIfNeeded(vmBackup, tc.volumeBackup))
+
+			stored, err := clientset.HarvesterhciV1beta1().VirtualMachineBackups("default").Get(context.TODO(), "backup", metav1.GetOptions{})
+			require.NoError(t, err)
+			_, tried := stored.Annotations[util.AnnotationSnapshotFreezeFS]
+			assert.Equal(t, tc.expectedFreeze, tried)
+		})
+	}
+}
@@ -17,7 +17,6 @@ import (
 	_ "github.com/longhorn/backupstore/nfs" //nolint
 	_ "github.com/longhorn/backupstore/s3"  //nolint
 	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
-	ctlstoragev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/storage/v1"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -75,7 +74,7 @@ type MetadataHandler struct {
 	vmBackupCache                   ctlharvesterv1.VirtualMachineBackupCache
 	vmImages                        ctlharvesterv1.VirtualMachineImageClient
 	vmImageCache                    ctlharvesterv1.VirtualMachineImageCache
-	storageClassCache               ctlstoragev1.StorageClassCache
+	backupTargets                   ctlharvesterv1.BackupTargetController
 }
 
 // RegisterBackupMetadata register the setting controller and resync vm backup metadata when backup target change
@@ -89,7 +88,7 @@ func RegisterBackupMetadata(ctx context.Context, management *config.Management,
 	longhornBackups := management.LonghornFactory.Longhorn().V1beta2().Backup()
 	longhornBackupBackingImages := management.LonghornFactory.Longhorn().V1beta2().BackupBackingImage()
 	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
-	storageClass := management.StorageFactory.Storage().V1().StorageClass()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 
 	backupMetadataController := &MetadataHandler{
 		ctx:                             ctx,
@@ -105,10 +104,11 @@ func RegisterBackupMetadata(ctx context.Context, management *config.Management,
 		vmBackupCache:                   vmBackups.Cache(),
 		vmImages:                        vmImages,
 		vmImageCache:                    vmImages.Cache(),
-		storageClassCache:               storageClass.Cache(),
+		backupTargets:                   backupTargets,
 	}
 
 	settings.OnChange(ctx, backupMetadataControllerName, backupMetadataController.OnBackupTargetChange)
+	backupTargets.OnChange(ctx, backupMetadataControllerName, backupMetadataController.OnBackupTargetObjectChange)
 	return nil
 }
 
@@ -163,6 +163,103 @@ func (h *MetadataHandler) OnBackupTargetChange(_ string, setting *harvesterv1.Se
 	return h.renewBackupTarget(setting)
 }
 
+// OnBackupTargetObjectChange resync vm backup metadata files of a named BackupTarget object
+func (h *MetadataHandler) OnBackupTargetObjectChange(_ string, bt *harvesterv1.BackupTarget) (*harvesterv1.BackupTarget, error) {
+	if bt == nil || bt.DeletionTimestamp != nil {
+		return bt, nil
+	}
+
+	// wait for the Longhorn backup target to be available
+	if !harvesterv1.BackupTargetConditionAvailable.IsTrue(bt) {
+		return bt, nil
+	}
+
+	specBytes, err := json.Marshal(bt.Spec)
+	if err != nil {
+		return bt, err
+	}
+	targetHash, err := getBackupTargetHash(string(specBytes))
+	if err != nil {
+		return bt, err
+	}
+	if !h.shouldRefreshBackupTargetObject(bt, targetHash) {
+		return bt, nil
+	}
+
+	target := backuputil.ConvertBackupTarget(bt)
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"target.name":     target.Name,
+		"target.type":     target.Type,
+		"target.endpoint": target.Endpoint,
+	})
+	contextLogger.Info("start syncing vm image metadata...")
+	if err = h.syncVMImage(target); err != nil {
+		contextLogger.WithError(err).Errorf("can't sync vm image metadata")
+		h.backupTargets.EnqueueAfter(bt.Name, 5*time.Second)
+		return h.setBackupTargetMetadataSyncedCondition(bt, err)
+	}
+
+	contextLogger.Info("start syncing vm backup metadata...")
+	if err = h.syncVMBackup(target); err != nil {
+		contextLogger.WithError(err).Errorf("can't sync vm backup metadata")
+		h.backupTargets.EnqueueAfter(bt.Name, 5*time.Second)
+		return h.setBackupTargetMetadataSyncedCondition(bt, err)
+	}
+
+	contextLogger.Info("check existing vm backup...")
+	if err = h.checkExistingVMBackup(target); err != nil {
+		contextLogger.WithError(err).Errorf("can't check existing vm backup")
+		h.backupTargets.EnqueueAfter(bt.Name, 5*time.Second)
+		return h.setBackupTargetMetadataSyncedCondition(bt, err)
+	}
+
+	if bt.Annotations[util.AnnotationHash] != targetHash {
+		btCopy := bt.DeepCopy()
+		if btCopy.Annotations == nil {
+			btCopy.Annotations = map[string]string{}
+		}
+		btCopy.Annotations[util.AnnotationHash] = targetHash
+		if bt, err = h.backupTargets.Update(btCopy); err != nil {
+			return bt, err
+		}
+	}
+	if bt.Spec.RefreshIntervalInSeconds > 0 {
+		h.backupTargets.EnqueueAfter(bt.Name, time.Duration(bt.Spec.RefreshIntervalInSeconds)*time.Second)
+	}
+	return h.setBackupTargetMetadataSyncedCondition(bt, nil)
+}
+
+func (h *MetadataHandler) shouldRefreshBackupTargetObject(bt *harvesterv1.BackupTarget, targetHash string) bool {
+	if bt.Annotations[util.AnnotationHash] != targetHash || bt.Status.LastSyncedTime == nil ||
+		!harvesterv1.BackupTargetConditionMetadataSynced.IsTrue(bt) {
+		return true
+	}
+
+	if bt.Spec.RefreshIntervalInSeconds == 0 {
+		return false
+	}
+
+	refreshInterval := time.Duration(bt.Spec.RefreshIntervalInSeconds) * time.Second
+	if elapsed := time.Since(bt.Status.LastSyncedTime.Time); elapsed < refreshInterval {
+		h.backupTargets.EnqueueAfter(bt.Name, refreshInterval-elapsed)
+		return false
+	}
+	return true
+}
+
+func (h *MetadataHandler) setBackupTargetMetadataSyncedCondition(bt *harvesterv1.BackupTarget, err error) (*harvesterv1.BackupTarget, error) {
+	btCopy := bt.DeepCopy()
+	// SetError with nil error will cleanup message in condition and set the status to true
+	harvesterv1.BackupTargetConditionMetadataSynced.SetError(btCopy, "", err)
+	if err == nil {
+		btCopy.Status.LastSyncedTime = &metav1.Time{Time: time.Now()}
+	}
+	if reflect.DeepEqual(bt.Status, btCopy.Status) {
+		return bt, nil
+	}
+	return h.backupTargets.UpdateStatus(btCopy)
+}
+
 func (h *MetadataHandler) shouldRefresh(setting *harvesterv1.Setting, refreshIntervalInSeconds int64) bool {
 	if setting.Annotations == nil {
 		return true
@@ -255,10 +352,10 @@ func (h *MetadataHandler) syncVMImage(target *settings.BackupTarget) error {
 			if imageMetadata.Namespace == "" {
 				imageMetadata.Namespace = metav1.NamespaceDefault
 			}
-			if !h.checkBackupBackingImageExist(imageMetadata) {
+			if !h.checkBackupBackingImageExist(imageMetadata, target) {
 				continue
 			}
-			if err := h.createVMImageIfNotExist(*imageMetadata); err != nil {
+			if err := h.createVMImageIfNotExist(*imageMetadata, target); err != nil {
 				return err
 			}
 		}
@@ -266,7 +363,7 @@ func (h *MetadataHandler) syncVMImage(target *settings.BackupTarget) error {
 	return nil
 }
 
-func (h *MetadataHandler) checkBackupBackingImageExist(imageMetadata *VirtualMachineImageMetadata) bool {
+func (h *MetadataHandler) checkBackupBackingImageExist(imageMetadata *VirtualMachineImageMetadata, target *settings.BackupTarget) bool {
 	parsedURL, err := url.Parse(imageMetadata.URL)
 	if err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{
@@ -298,10 +395,11 @@ func (h *MetadataHandler) checkBackupBackingImageExist(imageMetadata *VirtualMac
 		return false
 	}
 	for _, backupBackingImage := range backupBackingImages {
-		if backupBackingImage.Status.BackingImage != backingImageName {
+		if backupBackingImage.Status.BackingImage != backingImageName ||
+			backuputil.GetBackupTargetNameFromLonghorn(backupBackingImage.Spec.BackupTargetName) != target.Name {
 			continue
 		}
-		if backupBackingImages[0].Status.State == "Completed" {
+		if backupBackingImage.Status.State == "Completed" {
 			return true
 		}
 		logrus.WithError(err).WithFields(logrus.Fields{
@@ -315,7 +413,7 @@ func (h *MetadataHandler) checkBackupBackingImageExist(imageMetadata *VirtualMac
 	return false
 }
 
-func (h *MetadataHandler) createVMImageIfNotExist(imageMetadata VirtualMachineImageMetadata) error {
+func (h *MetadataHandler) createVMImageIfNotExist(imageMetadata VirtualMachineImageMetadata, target *settings.BackupTarget) error {
 	if _, err := h.vmImageCache.Get(imageMetadata.Namespace, imageMetadata.Name); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	} else if err == nil {
@@ -339,10 +437,16 @@ func (h *MetadataHandler) createVMImageIfNotExist(imageMetadata VirtualMachineIm
 		return err
 	}
 
+	var annotations map[string]string
+	if target.Name != "" {
+		// the backing image is restored from the named backup target
+		annotations = map[string]string{util.AnnotationBackupTargetName: target.Name}
+	}
 	if _, err := h.vmImages.Create(&harvesterv1.VirtualMachineImage{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      imageMetadata.Name,
-			Namespace: imageMetadata.Namespace,
+			Name:        imageMetadata.Name,
+			Namespace:   imageMetadata.Namespace,
+			Annotations: annotations,
 		},
 		Spec: harvesterv1.VirtualMachineImageSpec{
 			SourceType:             harvesterv1.VirtualMachineImageSourceTypeRestore,
@@ -414,20 +518,12 @@ func (h *MetadataHandler) createVMBackupIfNotExist(backupMetadata VirtualMachine
 		return nil
 	}
 
-	for _, volumeBackup := range backupMetadata.VolumeBackups {
-		if volumeBackup.PersistentVolumeClaim.Spec.StorageClassName == nil {
-			continue
-		}
-		if _, err := h.storageClassCache.Get(*volumeBackup.PersistentVolumeClaim.Spec.StorageClassName); err != nil {
-			logrus.WithError(err).WithFields(logrus.Fields{
-				"namespace":     backupMetadata.Namespace,
-				"name":          backupMetadata.Name,
-				"storageClasss": *volumeBackup.PersistentVolumeClaim.Spec.StorageClassName,
-			}).Warn("skip creating vm backup, because the storage class is not found")
-			return nil
-		}
-	}
+	// the backup target name in the metadata may come from another cluster, use the local one
+	backupSpec := backupMetadata.BackupSpec
+	backupSpec.BackupTargetName = target.Name
 
+	// The storage classes of the volume backups may not exist in this cluster, e.g. the backup is from another cluster.
+	// They are checked when restoring the vm backup, which can map them to the storage classes in this cluster.
 	if err := h.createNamespaceIfNotExist(backupMetadata.Namespace); err != nil {
 		return err
 	}
@@ -436,10 +532,11 @@ func (h *MetadataHandler) createVMBackupIfNotExist(backupMetadata VirtualMachine
 			Name:      backupMetadata.Name,
 			Namespace: backupMetadata.Namespace,
 		},
-		Spec: backupMetadata.BackupSpec,
+		Spec: backupSpec,
 		Status: harvesterv1.VirtualMachineBackupStatus{
 			ReadyToUse: ptr.To(false),
-			BackupTarget: &harvesterv1.BackupTarget{
+			BackupTarget: &harvesterv1.BackupTargetInfo{
+				Name:         target.Name,
 				Endpoint:     target.Endpoint,
 				BucketName:   target.BucketName,
 				BucketRegion: target.BucketRegion,
@@ -482,9 +579,6 @@ func (h *MetadataHandler) loadBackupMetadataAndCreateVMBackup(target *settings.B
 		if backupMetadata.Namespace == "" {
 			backupMetadata.Namespace = metav1.NamespaceDefault
 		}
-		if !h.checkDependentStorageClassExist(backupMetadata) {
-			continue
-		}
 		if !h.checkDependentLonghornBackupExist(target, backupMetadata) {
 			continue
 		}
@@ -495,30 +589,6 @@ func (h *MetadataHandler) loadBackupMetadataAndCreateVMBackup(target *settings.B
 	return nil
 }
 
-func (h *MetadataHandler) checkDependentStorageClassExist(backupMetadata *VirtualMachineBackupMetadata) bool {
-	for _, vb := range backupMetadata.VolumeBackups {
-		if vb.PersistentVolumeClaim.Spec.StorageClassName == nil {
-			continue
-		}
-		if sc, err := h.storageClassCache.Get(*vb.PersistentVolumeClaim.Spec.StorageClassName); err != nil {
-			logrus.WithError(err).WithFields(logrus.Fields{
-				"namespace":     backupMetadata.Namespace,
-				"name":          backupMetadata.Name,
-				"storageClasss": *vb.PersistentVolumeClaim.Spec.StorageClassName,
-			}).Warn("skip creating vm backup, because the storage class is not found")
-			return false
-		} else if sc.DeletionTimestamp != nil {
-			logrus.WithFields(logrus.Fields{
-				"namespace":     backupMetadata.Namespace,
-				"name":          backupMetadata.Name,
-				"storageClasss": *vb.PersistentVolumeClaim.Spec.StorageClassName,
-			}).Warn("skip creating vm backup, because the storage class is being deleted")
-			return false
-		}
-	}
-	return true
-}
-
 func (h *MetadataHandler) checkDependentLonghornBackupExist(target *settings.BackupTarget, backupMetadata *VirtualMachineBackupMetadata) bool {
 	for _, vb := range backupMetadata.VolumeBackups {
 		if vb.LonghornBackupName == nil {
@@ -650,6 +720,9 @@ func (h *MetadataHandler) checkReadyVMBackup(vmBackups []*harvesterv1.VirtualMac
 		if vmBackup.Spec.Type == harvesterv1.Snapshot || vmBackup.Status.ReadyToUse == nil || !*vmBackup.Status.ReadyToUse {
 			continue
 		}
+		if vmBackup.Spec.BackupTargetName != target.Name {
+			continue
+		}
 
 		vmBackupCopy := vmBackup.DeepCopy()
 		status := vmBackupCopy.Status
@@ -688,9 +761,7 @@ func (h *MetadataHandler) checkReadyVMBackup(vmBackups []*harvesterv1.VirtualMac
 // checkReadyVMBackupVolume checks a single volumeBackup and updates its ReadyToUse field if needed.
 // Returns (healthy, message) for this volume.
 func (h *MetadataHandler) checkReadyVMBackupVolume(vmBackup *harvesterv1.VirtualMachineBackup, volumeBackup *harvesterv1.VolumeBackup, target *settings.BackupTarget) (string, error) {
-	if conditionMsg, err := h.checkVolumeStorageClass(vmBackup, volumeBackup); conditionMsg != "" || err != nil {
-		return conditionMsg, err
-	}
+	// the storage class isn't checked because it can be mapped when restoring the vm backup
 	if volumeBackup.LonghornBackupName == nil {
 		return "", nil
 	}
@@ -703,23 +774,6 @@ func (h *MetadataHandler) checkReadyVMBackupVolume(vmBackup *harvesterv1.Virtual
 	return "", nil
 }
 
-func (h *MetadataHandler) checkVolumeStorageClass(vmBackup *harvesterv1.VirtualMachineBackup, volumeBackup *harvesterv1.VolumeBackup) (string, error) {
-	storageClassName := volumeBackup.PersistentVolumeClaim.Spec.StorageClassName
-	if storageClassName == nil {
-		return "", nil
-	}
-	if err := checkStorageClass(h.storageClassCache, *storageClassName); err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
-			"namespace":     vmBackup.Namespace,
-			"name":          vmBackup.Name,
-			"storageClasss": *storageClassName,
-		}).Warn("cannot find storage class for a ready VMBackup, change the VMBackup to not ready")
-		volumeBackup.ReadyToUse = ptr.To(false)
-		return fmt.Sprintf("cannot find storage class %s", *storageClassName), err
-	}
-	return "", nil
-}
-
 func (h *MetadataHandler) checkLonghornBackupReady(vmBackup *harvesterv1.VirtualMachineBackup, volumeBackup *harvesterv1.VolumeBackup) (string, error) {
 	conditionMsg, err := checkLHBackup(h.longhornBackupCache, *volumeBackup.LonghornBackupName)
 	if err != nil {
@@ -74,7 +74,10 @@ func (h *Handler) updateConditions(vmBackup *harvesterv1.VirtualMachineBackup) e
 	}
 
 	if ready && (vmBackupCpy.Status.ReadyToUse == nil || !*vmBackupCpy.Status.ReadyToUse) {
-		vmBackupCpy.Status.CreationTime = currentTime()
+		// a promoted backup keeps the creation time of the snapshot it's promoted from
+		if !IsPromotedBackup(vmBackupCpy) || vmBackupCpy.Status.CreationTime == nil {
+			vmBackupCpy.Status.CreationTime = currentTime()
+		}
 		vmBackupCpy.Status.Error = nil
 		setCondition(vmBackupCpy, harvesterv1.BackupConditionProgressing, false, "", "Operation complete")
 		setCondition(vmBackupCpy, harvesterv1.BackupConditionReady, true, "", "Operation complete")
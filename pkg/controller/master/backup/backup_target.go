@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 	longhorntypes "github.com/longhorn/longhorn-manager/types"
 	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
 	"github.com/sirupsen/logrus"
@@ -37,6 +38,7 @@ func RegisterBackupTarget(ctx context.Context, management *config.Management, _
 	longhornSettings := management.LonghornFactory.Longhorn().V1beta2().Setting()
 	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
 	lhBackupTargets := management.LonghornFactory.Longhorn().V1beta2().BackupTarget()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 
 	backupTargetController := &TargetHandler{
 		ctx:                  ctx,
@@ -48,9 +50,13 @@ func RegisterBackupTarget(ctx context.Context, management *config.Management, _
 		settings:             settings,
 		lhBackupTargets:      lhBackupTargets,
 		lhBackupTargetCache:  lhBackupTargets.Cache(),
+		backupTargets:        backupTargets,
+		backupTargetCache:    backupTargets.Cache(),
 	}
 
 	settings.OnChange(ctx, backupTargetControllerName, backupTargetController.OnBackupTargetChange)
+	backupTargets.OnChange(ctx, backupTargetControllerName, backupTargetController.OnBackupTargetObjectChange)
+	backupTargets.OnRemove(ctx, backupTargetControllerName, backupTargetController.OnBackupTargetObjectRemove)
 	return nil
 }
 
@@ -64,6 +70,8 @@ type TargetHandler struct {
 	settings             ctlharvesterv1.SettingClient
 	lhBackupTargets      ctllonghornv1.BackupTargetClient
 	lhBackupTargetCache  ctllonghornv1.BackupTargetCache
+	backupTargets        ctlharvesterv1.BackupTargetController
+	backupTargetCache    ctlharvesterv1.BackupTargetCache
 }
 
 // OnBackupTargetChange handles backupTarget setting object on change
@@ -315,3 +323,162 @@ func (h *TargetHandler) updateLHBackupTargetPollInterval(backupTarget *settings.
 	_, err = h.lhBackupTargets.Update(lhBackupTargetCpy)
 	return err
 }
+
+// OnBackupTargetObjectChange reconciles a named BackupTarget object to a Longhorn BackupTarget with the same name
+func (h *TargetHandler) OnBackupTargetObjectChange(_ string, bt *harvesterv1.BackupTarget) (*harvesterv1.BackupTarget, error) {
+	if bt == nil || bt.DeletionTimestamp != nil {
+		return bt, nil
+	}
+
+	target := backuputil.ConvertBackupTarget(bt)
+	lhBackupTargetName := backuputil.GetLonghornBackupTargetName(bt.Name)
+	credentialSecret := ""
+	if target.Type == settings.S3BackupType {
+		if err := h.syncBackupTargetObjectSecret(bt, target); err != nil {
+			return h.setBackupTargetAvailableCondition(bt, nil, err)
+		}
+		credentialSecret = backuputil.GetBackupTargetSecretName(bt.Name)
+	}
+
+	lhBackupTarget, err := h.syncLonghornBackupTarget(lhBackupTargetName, target, credentialSecret)
+	if err != nil {
+		return h.setBackupTargetAvailableCondition(bt, nil, err)
+	}
+
+	if !lhBackupTarget.Status.Available {
+		// Longhorn updates the status asynchronously, check it again later
+		h.backupTargets.EnqueueAfter(bt.Name, 10*time.Second)
+	}
+	return h.setBackupTargetAvailableCondition(bt, lhBackupTarget, nil)
+}
+
+// OnBackupTargetObjectRemove removes the Longhorn BackupTarget and the credential secret of a named BackupTarget object
+func (h *TargetHandler) OnBackupTargetObjectRemove(_ string, bt *harvesterv1.BackupTarget) (*harvesterv1.BackupTarget, error) {
+	if bt == nil {
+		return bt, nil
+	}
+
+	lhBackupTargetName := backuputil.GetLonghornBackupTargetName(bt.Name)
+	if err := h.lhBackupTargets.Delete(util.LonghornSystemNamespaceName, lhBackupTargetName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return bt, err
+	}
+
+	secretName := backuputil.GetBackupTargetSecretName(bt.Name)
+	if err := h.secrets.Delete(util.LonghornSystemNamespaceName, secretName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return bt, err
+	}
+	return bt, nil
+}
+
+func (h *TargetHandler) syncBackupTargetObjectSecret(bt *harvesterv1.BackupTarget, target *settings.BackupTarget) error {
+	if bt.Spec.CredentialSecret == nil {
+		return fmt.Errorf("credential secret is required for s3 backup target")
+	}
+
+	credential, err := h.secretCache.Get(bt.Spec.CredentialSecret.Namespace, bt.Spec.CredentialSecret.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get credential secret %s/%s: %w", bt.Spec.CredentialSecret.Namespace, bt.Spec.CredentialSecret.Name, err)
+	}
+	target.AccessKeyID = string(credential.Data[util.AWSAccessKey])
+	target.SecretAccessKey = string(credential.Data[util.AWSSecretKey])
+
+	backupSecretData, err := getBackupSecretData(target)
+	if err != nil {
+		return err
+	}
+
+	secretName := backuputil.GetBackupTargetSecretName(bt.Name)
+	secret, err := h.secretCache.Get(util.LonghornSystemNamespaceName, secretName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		newSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: util.LonghornSystemNamespaceName,
+			},
+			StringData: backupSecretData,
+		}
+		_, err = h.secrets.Create(newSecret)
+		return err
+	}
+
+	secretCpy := secret.DeepCopy()
+	secretCpy.Data = map[string][]byte{}
+	for k, v := range backupSecretData {
+		secretCpy.Data[k] = []byte(v)
+	}
+	if reflect.DeepEqual(secret.Data, secretCpy.Data) {
+		return nil
+	}
+	_, err = h.secrets.Update(secretCpy)
+	return err
+}
+
+func (h *TargetHandler) syncLonghornBackupTarget(name string, target *settings.BackupTarget, credentialSecret string) (*lhv1beta2.BackupTarget, error) {
+	pollInterval := metav1.Duration{
+		Duration: time.Duration(target.RefreshIntervalInSeconds) * time.Second,
+	}
+
+	lhBackupTarget, err := h.lhBackupTargetCache.Get(util.LonghornSystemNamespaceName, name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		return h.lhBackupTargets.Create(&lhv1beta2.BackupTarget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: util.LonghornSystemNamespaceName,
+			},
+			Spec: lhv1beta2.BackupTargetSpec{
+				BackupTargetURL:  backuputil.ConstructEndpoint(target),
+				CredentialSecret: credentialSecret,
+				PollInterval:     pollInterval,
+			},
+		})
+	}
+
+	lhBackupTargetCpy := lhBackupTarget.DeepCopy()
+	lhBackupTargetCpy.Spec.BackupTargetURL = backuputil.ConstructEndpoint(target)
+	lhBackupTargetCpy.Spec.CredentialSecret = credentialSecret
+	lhBackupTargetCpy.Spec.PollInterval = pollInterval
+
+	if reflect.DeepEqual(lhBackupTarget, lhBackupTargetCpy) {
+		return lhBackupTarget, nil
+	}
+	return h.lhBackupTargets.Update(lhBackupTargetCpy)
+}
+
+func (h *TargetHandler) setBackupTargetAvailableCondition(bt *harvesterv1.BackupTarget, lhBackupTarget *lhv1beta2.BackupTarget, err error) (*harvesterv1.BackupTarget, error) {
+	btCpy := bt.DeepCopy()
+	switch {
+	case err != nil:
+		harvesterv1.BackupTargetConditionAvailable.SetError(btCpy, "", err)
+	case lhBackupTarget.Status.Available:
+		btCpy.Status.LonghornBackupTargetName = lhBackupTarget.Name
+		harvesterv1.BackupTargetConditionAvailable.SetError(btCpy, "", nil)
+	default:
+		btCpy.Status.LonghornBackupTargetName = lhBackupTarget.Name
+		message := ""
+		for _, c := range lhBackupTarget.Status.Conditions {
+			if c.Type == lhv1beta2.BackupTargetConditionTypeUnavailable {
+				message = c.Message
+			}
+		}
+		harvesterv1.BackupTargetConditionAvailable.False(btCpy)
+		harvesterv1.BackupTargetConditionAvailable.Reason(btCpy, lhv1beta2.BackupTargetConditionReasonUnavailable)
+		harvesterv1.BackupTargetConditionAvailable.Message(btCpy, message)
+	}
+
+	if reflect.DeepEqual(bt.Status, btCpy.Status) {
+		return bt, err
+	}
+	updated, updateErr := h.backupTargets.UpdateStatus(btCpy)
+	if updateErr != nil {
+		return bt, updateErr
+	}
+	return updated, err
+}
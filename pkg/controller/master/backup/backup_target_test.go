@@ -0,0 +1,179 @@
+package backup
+
+import (
+	"context"
+	"testing"
+
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newTargetHandler(clientset *fake.Clientset) *TargetHandler {
+	return &TargetHandler{
+		ctx:                 context.Background(),
+		secrets:             fakeclients.SecretClient(clientset.CoreV1().Secrets),
+		secretCache:         fakeclients.SecretCache(clientset.CoreV1().Secrets),
+		lhBackupTargets:     fakeclients.LonghornBackupTargetClient(clientset.LonghornV1beta2().BackupTargets),
+		lhBackupTargetCache: fakeclients.LonghornBackupTargetCache(clientset.LonghornV1beta2().BackupTargets),
+		backupTargets:       fakeclients.BackupTargetClient(clientset.HarvesterhciV1beta1().BackupTargets),
+		backupTargetCache:   fakeclients.BackupTargetCache(clientset.HarvesterhciV1beta1().BackupTargets),
+	}
+}
+
+func TestTargetHandler_OnBackupTargetObjectChange(t *testing.T) {
+	bt := &harvesterv1.BackupTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfs-target"},
+		Spec: harvesterv1.BackupTargetSpec{
+			Type:     harvesterv1.BackupTargetTypeNFS,
+			Endpoint: "nfs://10.0.0.1:/exports/backup",
+		},
+	}
+	clientset := fake.NewSimpleClientset(bt)
+	h := newTargetHandler(clientset)
+	backupTargets := clientset.HarvesterhciV1beta1().BackupTargets()
+
+	// the Longhorn backup target is created and the condition is false until Longhorn reports it's available
+	_, err := h.OnBackupTargetObjectChange(bt.Name, bt)
+	require.NoError(t, err)
+	lhBackupTarget, err := clientset.LonghornV1beta2().BackupTargets(util.LonghornSystemNamespaceName).Get(context.TODO(), bt.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "nfs://10.0.0.1:/exports/backup", lhBackupTarget.Spec.BackupTargetURL)
+	stored, err := backupTargets.Get(context.TODO(), bt.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, harvesterv1.BackupTargetConditionAvailable.IsFalse(stored))
+	assert.Equal(t, bt.Name, stored.Status.LonghornBackupTargetName)
+
+	// the available condition is written through the status subresource
+	lhBackupTarget.Status.Available = true
+	_, err = clientset.LonghornV1beta2().BackupTargets(util.LonghornSystemNamespaceName).UpdateStatus(context.TODO(), lhBackupTarget, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, err = h.OnBackupTargetObjectChange(stored.Name, stored)
+	require.NoError(t, err)
+	stored, err = backupTargets.Get(context.TODO(), bt.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, harvesterv1.BackupTargetConditionAvailable.IsTrue(stored))
+
+	// updating the object keeps the status
+	toUpdate := stored.DeepCopy()
+	toUpdate.Annotations = map[string]string{util.AnnotationHash: "hash"}
+	toUpdate.Status = harvesterv1.BackupTargetStatus{}
+	_, err = h.backupTargets.Update(toUpdate)
+	require.NoError(t, err)
+	stored, err = backupTargets.Get(context.TODO(), bt.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "hash", stored.Annotations[util.AnnotationHash])
+	assert.True(t, harvesterv1.BackupTargetConditionAvailable.IsTrue(stored))
+}
+
+func TestTargetHandler_OnBackupTargetObjectChange_S3WithoutCredential(t *testing.T) {
+	bt := &harvesterv1.BackupTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-target"},
+		Spec: harvesterv1.BackupTargetSpec{
+			Type:       harvesterv1.BackupTargetTypeS3,
+			BucketName: "backup",
+			CredentialSecret: &corev1.SecretReference{
+				Namespace: "default",
+				Name:      "nonexistent",
+			},
+		},
+	}
+	clientset := fake.NewSimpleClientset(bt)
+	h := newTargetHandler(clientset)
+
+	// the error is returned to retry, and written to the condition
+	_, err := h.OnBackupTargetObjectChange(bt.Name, bt)
+	require.Error(t, err)
+	stored, err := clientset.HarvesterhciV1beta1().BackupTargets().Get(context.TODO(), bt.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, harvesterv1.BackupTargetConditionAvailable.IsFalse(stored))
+	assert.Contains(t, harvesterv1.BackupTargetConditionAvailable.GetMessage(stored), "nonexistent")
+}
+
+func TestHandler_configureVolumeBackupTarget(t *testing.T) {
+	newBackup := func(name, backupTargetName string, ready bool) *harvesterv1.VirtualMachineBackup {
+		return &harvesterv1.VirtualMachineBackup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+			Spec:       harvesterv1.VirtualMachineBackupSpec{Type: harvesterv1.Backup, BackupTargetName: backupTargetName},
+			Status: harvesterv1.VirtualMachineBackupStatus{
+				VolumeBackups: []harvesterv1.VolumeBackup{{
+					Name:                  ptr.To(name + "-volume"),
+					CSIDriverName:         util.CSIProvisionerLonghorn,
+					PersistentVolumeClaim: harvesterv1.PersistentVolumeClaimSourceSpec{Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-volume"}},
+					ReadyToUse:            ptr.To(ready),
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		volumeTarget   string
+		others         []*harvesterv1.VirtualMachineBackup
+		expectError    bool
+		expectedTarget string
+	}{
+		{
+			name:           "volume points to the backup target",
+			volumeTarget:   "nfs-target",
+			expectedTarget: "nfs-target",
+		},
+		{
+			name:           "volume is pointed to the backup target",
+			volumeTarget:   "default",
+			others:         []*harvesterv1.VirtualMachineBackup{newBackup("done", "", true)},
+			expectedTarget: "nfs-target",
+		},
+		{
+			name:           "backup to the current backup target is in progress",
+			volumeTarget:   "default",
+			others:         []*harvesterv1.VirtualMachineBackup{newBackup("running", "", false)},
+			expectError:    true,
+			expectedTarget: "default",
+		},
+		{
+			name:           "backup to the same backup target is in progress",
+			volumeTarget:   "default",
+			others:         []*harvesterv1.VirtualMachineBackup{newBackup("running", "nfs-target", false)},
+			expectedTarget: "nfs-target",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			volume := &lhv1beta2.Volume{
+				ObjectMeta: metav1.ObjectMeta{Namespace: util.LonghornSystemNamespaceName, Name: "pvc-volume"},
+				Spec:       lhv1beta2.VolumeSpec{BackupTargetName: tc.volumeTarget},
+			}
+			vmBackup := newBackup("backup", "nfs-target", false)
+			clientset := fake.NewSimpleClientset(volume, vmBackup)
+			for _, other := range tc.others {
+				require.NoError(t, clientset.Tracker().Add(other))
+			}
+			h := &Handler{
+				vmBackups:   fakeclients.VMBackupClient(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+				volumes:     fakeclients.LonghornVolumeClient(clientset.LonghornV1beta2().Volumes),
+				volumeCache: fakeclients.LonghornVolumeCache(clientset.LonghornV1beta2().Volumes),
+			}
+
+			err := h.configureVolumeBackupTarget(vmBackup, vmBackup.Status.VolumeBackups[0])
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			stored, err := clientset.LonghornV1beta2().Volumes(util.LonghornSystemNamespaceName).Get(context.TODO(), "pvc-volume", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTarget, stored.Spec.BackupTargetName)
+		})
+	}
+}
@@ -0,0 +1,483 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/v3/pkg/name"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/config"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	backupVerificationControllerName = "harvester-vm-backup-verification-controller"
+
+	backupVerificationSucceededEvent = "VirtualMachineBackupVerificationSucceeded"
+	backupVerificationFailedEvent    = "VirtualMachineBackupVerificationFailed"
+
+	// DefaultBackupVerificationNamespace is the scratch namespace managed by the controller
+	DefaultBackupVerificationNamespace      = "harvester-backup-verification"
+	defaultBackupVerificationTimeoutSeconds = 1800
+	backupVerificationPollInterval          = 10 * time.Second
+)
+
+type VerificationHandler struct {
+	verifications          ctlharvesterv1.VirtualMachineBackupVerificationClient
+	verificationController ctlharvesterv1.VirtualMachineBackupVerificationController
+	backupCache            ctlharvesterv1.VirtualMachineBackupCache
+	restores               ctlharvesterv1.VirtualMachineRestoreClient
+	restoreCache           ctlharvesterv1.VirtualMachineRestoreCache
+	vms                    ctlkubevirtv1.VirtualMachineClient
+	vmCache                ctlkubevirtv1.VirtualMachineCache
+	vmiCache               ctlkubevirtv1.VirtualMachineInstanceCache
+	namespaces             ctlcorev1.NamespaceClient
+	namespaceCache         ctlcorev1.NamespaceCache
+	pvcClient              ctlcorev1.PersistentVolumeClaimClient
+	pvcCache               ctlcorev1.PersistentVolumeClaimCache
+	secretClient           ctlcorev1.SecretClient
+	secretCache            ctlcorev1.SecretCache
+
+	recorder record.EventRecorder
+}
+
+// RegisterBackupVerification registers the controller verifying VM backups by restoring and booting them in a scratch namespace
+func RegisterBackupVerification(ctx context.Context, management *config.Management, _ config.Options) error {
+	verifications := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackupVerification()
+	backups := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup()
+	restores := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineRestore()
+	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
+	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
+	namespaces := management.CoreFactory.Core().V1().Namespace()
+	pvcs := management.CoreFactory.Core().V1().PersistentVolumeClaim()
+	secrets := management.CoreFactory.Core().V1().Secret()
+
+	handler := &VerificationHandler{
+		verifications:          verifications,
+		verificationController: verifications,
+		backupCache:            backups.Cache(),
+		restores:               restores,
+		restoreCache:           restores.Cache(),
+		vms:                    vms,
+		vmCache:                vms.Cache(),
+		vmiCache:               vmis.Cache(),
+		namespaces:             namespaces,
+		namespaceCache:         namespaces.Cache(),
+		pvcClient:              pvcs,
+		pvcCache:               pvcs.Cache(),
+		secretClient:           secrets,
+		secretCache:            secrets.Cache(),
+		recorder:               management.NewRecorder(backupVerificationControllerName, "", ""),
+	}
+
+	verifications.OnChange(ctx, backupVerificationControllerName, handler.OnVerificationChange)
+	verifications.OnRemove(ctx, backupVerificationControllerName, handler.OnVerificationRemove)
+	return nil
+}
+
+// OnVerificationChange restores the volumes of the backup into the scratch namespace, boots a VM without networking from them
+// and waits for the probe to pass. The scratch resources are cleaned up once the verification succeeds or fails.
+func (h *VerificationHandler) OnVerificationChange(_ string, verification *harvesterv1.VirtualMachineBackupVerification) (*harvesterv1.VirtualMachineBackupVerification, error) {
+	if verification == nil || verification.DeletionTimestamp != nil {
+		return nil, nil
+	}
+
+	if isVerificationFinished(verification) {
+		return nil, h.cleanupVerification(verification)
+	}
+
+	if verification.Status.StartTime == nil {
+		verificationCpy := verification.DeepCopy()
+		verificationCpy.Status.Phase = harvesterv1.BackupVerificationPhasePending
+		verificationCpy.Status.StartTime = currentTime()
+		verificationCpy.Status.RestoreName = getVerificationResourceName(verification)
+		verificationCpy.Status.VirtualMachineName = getVerificationResourceName(verification)
+		return h.verifications.UpdateStatus(verificationCpy)
+	}
+
+	if time.Since(verification.Status.StartTime.Time) > getVerificationTimeout(verification) {
+		return h.failVerification(verification, fmt.Sprintf("verification timed out in phase %s: %s", verification.Status.Phase, verification.Status.Message))
+	}
+
+	backup, err := h.backupCache.Get(verification.Namespace, verification.Spec.VirtualMachineBackupName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return h.failVerification(verification, fmt.Sprintf("vmbackup %s/%s is not found", verification.Namespace, verification.Spec.VirtualMachineBackupName))
+		}
+		return nil, err
+	}
+	if !IsBackupReady(backup) || backup.Status.SourceSpec == nil {
+		return h.failVerification(verification, fmt.Sprintf("vmbackup %s/%s is not ready", backup.Namespace, backup.Name))
+	}
+
+	if err := h.ensureVerificationNamespace(verification); err != nil {
+		if apierrors.IsNotFound(err) {
+			return h.failVerification(verification, fmt.Sprintf("scratch namespace %s is not found", getVerificationNamespace(verification)))
+		}
+		return nil, err
+	}
+
+	restore, err := h.getOrCreateVerificationRestore(verification, backup)
+	if err != nil {
+		return nil, err
+	}
+	if !isOwnedByVerification(verification, restore) {
+		return h.failVerification(verification, fmt.Sprintf("vmrestore %s/%s already exists", restore.Namespace, restore.Name))
+	}
+
+	verificationCpy := verification.DeepCopy()
+	if restore.Status.Complete == nil || !*restore.Status.Complete {
+		verificationCpy.Status.Phase = harvesterv1.BackupVerificationPhaseRestoring
+		verificationCpy.Status.Message = fmt.Sprintf("restoring volumes, progress %d%%", restore.Status.Progress)
+		if message := harvesterv1.BackupConditionReady.GetMessage(restore); message != "" && harvesterv1.BackupConditionReady.GetReason(restore) == "Error" {
+			verificationCpy.Status.Message = fmt.Sprintf("restoring volumes: %s", message)
+		}
+		return h.updateVerificationAndRequeue(verification, verificationCpy)
+	}
+
+	if verificationCpy.Status.RestoredTime == nil {
+		verificationCpy.Status.RestoredTime = currentTime()
+		setCondition(verificationCpy, harvesterv1.BackupVerificationConditionRestored, true, "", fmt.Sprintf("volumes are restored by %s", restore.Name))
+	}
+
+	vm, err := h.getOrCreateVerificationVM(verification, backup, restore)
+	if err != nil {
+		return nil, err
+	}
+	if !isOwnedByVerification(verification, vm) {
+		return h.failVerification(verification, fmt.Sprintf("vm %s/%s already exists", vm.Namespace, vm.Name))
+	}
+
+	passed, err := h.probeVerificationVM(verification, vm)
+	if err != nil {
+		return nil, err
+	}
+	if !passed {
+		verificationCpy.Status.Phase = harvesterv1.BackupVerificationPhaseBooting
+		verificationCpy.Status.Message = fmt.Sprintf("waiting for %s probe of vm %s/%s", getVerificationProbe(verification), vm.Namespace, vm.Name)
+		return h.updateVerificationAndRequeue(verification, verificationCpy)
+	}
+
+	bootDuration := time.Since(verificationCpy.Status.RestoredTime.Time).Round(time.Second)
+	verificationCpy.Status.Phase = harvesterv1.BackupVerificationPhaseSucceeded
+	verificationCpy.Status.CompletionTime = currentTime()
+	verificationCpy.Status.Message = fmt.Sprintf("%s probe passed %s after the volumes were restored", getVerificationProbe(verification), bootDuration)
+	setCondition(verificationCpy, harvesterv1.BackupVerificationConditionBooted, true, "", verificationCpy.Status.Message)
+	h.recorder.Eventf(verificationCpy, corev1.EventTypeNormal, backupVerificationSucceededEvent,
+		"Successfully verified VirtualMachineBackup %s: %s", backup.Name, verificationCpy.Status.Message)
+	return h.verifications.UpdateStatus(verificationCpy)
+}
+
+// OnVerificationRemove cleans up the scratch resources of an unfinished verification
+func (h *VerificationHandler) OnVerificationRemove(_ string, verification *harvesterv1.VirtualMachineBackupVerification) (*harvesterv1.VirtualMachineBackupVerification, error) {
+	if verification == nil {
+		return nil, nil
+	}
+	return nil, h.cleanupVerification(verification)
+}
+
+func (h *VerificationHandler) updateVerificationAndRequeue(verification, verificationCpy *harvesterv1.VirtualMachineBackupVerification) (*harvesterv1.VirtualMachineBackupVerification, error) {
+	h.verificationController.EnqueueAfter(verification.Namespace, verification.Name, backupVerificationPollInterval)
+	if reflect.DeepEqual(verification.Status, verificationCpy.Status) {
+		return verification, nil
+	}
+	return h.verifications.UpdateStatus(verificationCpy)
+}
+
+func (h *VerificationHandler) failVerification(verification *harvesterv1.VirtualMachineBackupVerification, message string) (*harvesterv1.VirtualMachineBackupVerification, error) {
+	logrus.Infof("verification %s/%s of vmbackup %s failed: %s", verification.Namespace, verification.Name, verification.Spec.VirtualMachineBackupName, message)
+
+	verificationCpy := verification.DeepCopy()
+	verificationCpy.Status.Phase = harvesterv1.BackupVerificationPhaseFailed
+	verificationCpy.Status.CompletionTime = currentTime()
+	verificationCpy.Status.Message = message
+	if verificationCpy.Status.RestoredTime == nil {
+		setCondition(verificationCpy, harvesterv1.BackupVerificationConditionRestored, false, "Error", message)
+	} else {
+		setCondition(verificationCpy, harvesterv1.BackupVerificationConditionBooted, false, "Error", message)
+	}
+	h.recorder.Eventf(verificationCpy, corev1.EventTypeWarning, backupVerificationFailedEvent,
+		"Failed to verify VirtualMachineBackup %s: %s", verification.Spec.VirtualMachineBackupName, message)
+	return h.verifications.UpdateStatus(verificationCpy)
+}
+
+// ensureVerificationNamespace creates the default scratch namespace, a custom one is checked by the webhook
+// and must exist.
+func (h *VerificationHandler) ensureVerificationNamespace(verification *harvesterv1.VirtualMachineBackupVerification) error {
+	namespace := getVerificationNamespace(verification)
+	if _, err := h.namespaceCache.Get(namespace); err == nil || !apierrors.IsNotFound(err) || namespace != DefaultBackupVerificationNamespace {
+		return err
+	}
+
+	logrus.Infof("create backup verification namespace %s", namespace)
+	_, err := h.namespaces.Create(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespace,
+		},
+	})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// getOrCreateVerificationRestore restores all volumes of the backup in standalone mode into the scratch namespace
+func (h *VerificationHandler) getOrCreateVerificationRestore(
+	verification *harvesterv1.VirtualMachineBackupVerification,
+	backup *harvesterv1.VirtualMachineBackup,
+) (*harvesterv1.VirtualMachineRestore, error) {
+	namespace := getVerificationNamespace(verification)
+	restore, err := h.restoreCache.Get(namespace, verification.Status.RestoreName)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return restore, err
+	}
+
+	volumes := make([]string, 0, len(backup.Status.VolumeBackups))
+	for _, volumeBackup := range backup.Status.VolumeBackups {
+		volumes = append(volumes, volumeBackup.VolumeName)
+	}
+
+	return h.restores.Create(&harvesterv1.VirtualMachineRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      verification.Status.RestoreName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				util.LabelBackupVerification: getVerificationID(verification),
+			},
+		},
+		Spec: harvesterv1.VirtualMachineRestoreSpec{
+			Target: corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To(kubevirtv1.SchemeGroupVersion.Group),
+				Kind:     kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+				Name:     verification.Status.VirtualMachineName,
+			},
+			VirtualMachineBackupName:      backup.Name,
+			VirtualMachineBackupNamespace: backup.Namespace,
+			Volumes:                       volumes,
+			VolumeRestoreMode:             harvesterv1.VolumeRestoreModeStandalone,
+		},
+	})
+}
+
+// getOrCreateVerificationVM creates a VM from the backup source spec on top of the restored PVCs with networking disabled
+func (h *VerificationHandler) getOrCreateVerificationVM(
+	verification *harvesterv1.VirtualMachineBackupVerification,
+	backup *harvesterv1.VirtualMachineBackup,
+	restore *harvesterv1.VirtualMachineRestore,
+) (*kubevirtv1.VirtualMachine, error) {
+	vm, err := h.vmCache.Get(restore.Namespace, verification.Status.VirtualMachineName)
+	if apierrors.IsNotFound(err) {
+		logrus.Infof("create backup verification vm %s/%s", restore.Namespace, verification.Status.VirtualMachineName)
+		vm, err = h.vms.Create(newVerificationVM(verification, backup, restore))
+	}
+	if err != nil || !isOwnedByVerification(verification, vm) {
+		return vm, err
+	}
+
+	// copy the cloud-init secrets for the VM, they're removed with the VM
+	for _, secretBackup := range backup.Status.SecretBackups {
+		secretName := getVerificationSecretName(vm.Name, secretBackup.Name)
+		if _, err := h.secretCache.Get(vm.Namespace, secretName); err == nil || !apierrors.IsNotFound(err) {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if _, err := h.secretClient.Create(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            secretName,
+				Namespace:       vm.Namespace,
+				OwnerReferences: configVMOwner(vm),
+			},
+			Data: secretBackup.Data,
+		}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+	}
+
+	return vm, nil
+}
+
+func newVerificationVM(
+	verification *harvesterv1.VirtualMachineBackupVerification,
+	backup *harvesterv1.VirtualMachineBackup,
+	restore *harvesterv1.VirtualMachineRestore,
+) *kubevirtv1.VirtualMachine {
+	vmName := verification.Status.VirtualMachineName
+	spec := backup.Status.SourceSpec.Spec.Template.Spec.DeepCopy()
+
+	// disable networking, the VM must not reach or be reached by anything while it's verified
+	spec.Networks = nil
+	spec.Domain.Devices.Interfaces = nil
+	spec.Domain.Devices.AutoattachPodInterface = ptr.To(false)
+	// the network affinity and the access credentials refer to resources in the source namespace
+	spec.Affinity = nil
+	spec.AccessCredentials = nil
+
+	volumes, _ := getNewVolumes(&kubevirtv1.VirtualMachineSpec{
+		Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{Spec: *spec},
+	}, restore)
+	for i := range volumes {
+		if cloudInit := volumes[i].CloudInitNoCloud; cloudInit != nil {
+			if cloudInit.UserDataSecretRef != nil {
+				cloudInit.UserDataSecretRef.Name = getVerificationSecretName(vmName, cloudInit.UserDataSecretRef.Name)
+			}
+			if cloudInit.NetworkDataSecretRef != nil {
+				cloudInit.NetworkDataSecretRef.Name = getVerificationSecretName(vmName, cloudInit.NetworkDataSecretRef.Name)
+			}
+		}
+	}
+	spec.Volumes = volumes
+
+	return &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      vmName,
+			Namespace: restore.Namespace,
+			Labels: map[string]string{
+				util.LabelBackupVerification: getVerificationID(verification),
+			},
+		},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			RunStrategy: ptr.To(kubevirtv1.RunStrategyAlways),
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						util.LabelVMCreator: "harvester",
+						util.LabelVMName:    vmName,
+					},
+				},
+				Spec: *spec,
+			},
+		},
+	}
+}
+
+func (h *VerificationHandler) probeVerificationVM(verification *harvesterv1.VirtualMachineBackupVerification, vm *kubevirtv1.VirtualMachine) (bool, error) {
+	if getVerificationProbe(verification) == harvesterv1.BackupVerificationProbeVMReady {
+		return vm.Status.Ready, nil
+	}
+
+	vmi, err := h.vmiCache.Get(vm.Namespace, vm.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return IsGuestAgentConnected(vmi), nil
+}
+
+// cleanupVerification deletes the VM, the restored PVCs and the restore in the scratch namespace. Only the resources
+// labelled with the uid of the verification are deleted, the scratch namespace may be shared with other workloads.
+func (h *VerificationHandler) cleanupVerification(verification *harvesterv1.VirtualMachineBackupVerification) error {
+	if verification.Status.RestoreName == "" {
+		return nil
+	}
+
+	namespace := getVerificationNamespace(verification)
+	vm, err := h.vmCache.Get(namespace, verification.Status.VirtualMachineName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && isOwnedByVerification(verification, vm) {
+		propagation := metav1.DeletePropagationForeground
+		if err := h.vms.Delete(namespace, vm.Name, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	restore, err := h.restoreCache.Get(namespace, verification.Status.RestoreName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !isOwnedByVerification(verification, restore) {
+		return nil
+	}
+
+	// standalone restored PVCs have no owner, their names contain the uid of the restore
+	for _, volumeRestore := range restore.Status.VolumeRestores {
+		pvcName := volumeRestore.PersistentVolumeClaim.ObjectMeta.Name
+		pvc, err := h.pvcCache.Get(namespace, pvcName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if pvc.Annotations[restoreNameAnnotation] != restore.Name {
+			continue
+		}
+		if err := h.pvcClient.Delete(namespace, pvcName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if err := h.restores.Delete(namespace, restore.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// isOwnedByVerification returns true if the resource in the scratch namespace is created for the verification
+func isOwnedByVerification(verification *harvesterv1.VirtualMachineBackupVerification, obj metav1.Object) bool {
+	return obj.GetLabels()[util.LabelBackupVerification] == getVerificationID(verification)
+}
+
+func isVerificationFinished(verification *harvesterv1.VirtualMachineBackupVerification) bool {
+	return verification.Status.Phase == harvesterv1.BackupVerificationPhaseSucceeded ||
+		verification.Status.Phase == harvesterv1.BackupVerificationPhaseFailed
+}
+
+// getVerificationID returns the label value of the resources created for the verification, the uid tells them
+// apart from the resources of a verification recreated with the same name.
+func getVerificationID(verification *harvesterv1.VirtualMachineBackupVerification) string {
+	return string(verification.UID)
+}
+
+// getVerificationResourceName returns the name of the restore and the VM in the scratch namespace,
+// verifications in different namespaces could share the same scratch namespace.
+func getVerificationResourceName(verification *harvesterv1.VirtualMachineBackupVerification) string {
+	return name.SafeConcatName("verify", verification.Namespace, verification.Name)
+}
+
+func getVerificationSecretName(vmName, secretName string) string {
+	return name.SafeConcatName(vmName, name.Hex(secretName, 8))
+}
+
+func getVerificationNamespace(verification *harvesterv1.VirtualMachineBackupVerification) string {
+	if verification.Spec.ScratchNamespace == "" {
+		return DefaultBackupVerificationNamespace
+	}
+	return verification.Spec.ScratchNamespace
+}
+
+func getVerificationProbe(verification *harvesterv1.VirtualMachineBackupVerification) harvesterv1.BackupVerificationProbe {
+	if verification.Spec.Probe == "" {
+		return harvesterv1.BackupVerificationProbeGuestAgent
+	}
+	return verification.Spec.Probe
+}
+
+func getVerificationTimeout(verification *harvesterv1.VirtualMachineBackupVerification) time.Duration {
+	if verification.Spec.TimeoutSeconds <= 0 {
+		return defaultBackupVerificationTimeoutSeconds * time.Second
+	}
+	return time.Duration(verification.Spec.TimeoutSeconds) * time.Second
+}
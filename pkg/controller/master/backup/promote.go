@@ -0,0 +1,181 @@
+package backup
+
+import (
+	"fmt"
+
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhtypes "github.com/longhorn/longhorn-manager/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+	backuputil "github.com/harvester/harvester/pkg/util/backup"
+)
+
+// getPromotedFrom returns the VM snapshot the backup is promoted from
+func (h *Handler) getPromotedFrom(vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	snapshotName := vmBackup.Annotations[util.AnnotationPromotedFrom]
+	snapshot, err := h.vmBackupCache.Get(vmBackup.Namespace, snapshotName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot %s/%s: %w", vmBackup.Namespace, snapshotName, err)
+	}
+	if snapshot.Spec.Type != harvesterv1.Snapshot || !IsBackupReady(snapshot) {
+		return nil, fmt.Errorf("vmbackup %s/%s is not a ready snapshot", vmBackup.Namespace, snapshotName)
+	}
+	return snapshot, nil
+}
+
+// initPromotedBackup initializes the status of a backup promoted from a VM snapshot.
+// The source VM may be gone or changed, so the source spec, secrets and creation time
+// are taken from the snapshot to keep the point-in-time of the snapshot.
+func (h *Handler) initPromotedBackup(vmBackup *harvesterv1.VirtualMachineBackup) error {
+	snapshot, err := h.getPromotedFrom(vmBackup)
+	if err != nil {
+		return err
+	}
+
+	backupCpy := vmBackup.DeepCopy()
+	backupCpy.Status = harvesterv1.VirtualMachineBackupStatus{
+		ReadyToUse:    ptr.To(false),
+		SourceUID:     snapshot.Status.SourceUID,
+		SourceSpec:    snapshot.Status.SourceSpec.DeepCopy(),
+		SecretBackups: snapshot.DeepCopy().Status.SecretBackups,
+		CreationTime:  snapshot.Status.CreationTime.DeepCopy(),
+		VolumeBackups: []harvesterv1.VolumeBackup{},
+	}
+
+	for _, vb := range snapshot.Status.VolumeBackups {
+		backupCpy.Status.VolumeBackups = append(backupCpy.Status.VolumeBackups, harvesterv1.VolumeBackup{
+			Name:                  ptr.To(fmt.Sprintf("%s-volume-%s", vmBackup.Name, vb.PersistentVolumeClaim.ObjectMeta.Name)),
+			VolumeName:            vb.VolumeName,
+			CSIDriverName:         vb.CSIDriverName,
+			PersistentVolumeClaim: *vb.PersistentVolumeClaim.DeepCopy(),
+			VolumeSize:            vb.VolumeSize,
+			ReadyToUse:            ptr.To(false),
+		})
+	}
+
+	if backupCpy.Status.CSIDriverVolumeSnapshotClassNames, _, err = h.getCSIDriverMap(backupCpy); err != nil {
+		return err
+	}
+
+	target, err := h.getBackupTarget(vmBackup)
+	if err != nil {
+		return err
+	}
+	backupCpy.Status.BackupTarget = &harvesterv1.BackupTargetInfo{
+		Name:         target.Name,
+		Endpoint:     target.Endpoint,
+		BucketName:   target.BucketName,
+		BucketRegion: target.BucketRegion,
+	}
+
+	_, err = h.vmBackups.Update(backupCpy)
+	return err
+}
+
+// reconcilePromotedLHBackup creates the Longhorn backup of a volume from the Longhorn snapshot
+// behind the volume snapshot of the VM snapshot, it returns the Longhorn backup name and
+// whether the Longhorn backup is completed.
+func (h *Handler) reconcilePromotedLHBackup(vmBackup *harvesterv1.VirtualMachineBackup, volumeBackup harvesterv1.VolumeBackup) (string, bool, error) {
+	lhBackupName := *volumeBackup.Name
+	lhBackup, err := h.lhbackupCache.Get(util.LonghornSystemNamespaceName, lhBackupName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", false, err
+	}
+
+	if apierrors.IsNotFound(err) {
+		if lhBackup, err = h.createPromotedLHBackup(vmBackup, volumeBackup); err != nil {
+			return "", false, err
+		}
+	}
+
+	switch lhBackup.Status.State {
+	case lhv1beta2.BackupStateCompleted:
+		return lhBackupName, true, nil
+	case lhv1beta2.BackupStateError:
+		return "", false, fmt.Errorf("longhorn backup %s failed: %s", lhBackupName, lhBackup.Status.Error)
+	default:
+		return lhBackupName, false, nil
+	}
+}
+
+func (h *Handler) createPromotedLHBackup(vmBackup *harvesterv1.VirtualMachineBackup, volumeBackup harvesterv1.VolumeBackup) (*lhv1beta2.Backup, error) {
+	snapshot, err := h.getPromotedFrom(vmBackup)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshotVolumeBackup *harvesterv1.VolumeBackup
+	for i, vb := range snapshot.Status.VolumeBackups {
+		if vb.VolumeName == volumeBackup.VolumeName {
+			snapshotVolumeBackup = &snapshot.Status.VolumeBackups[i]
+			break
+		}
+	}
+	if snapshotVolumeBackup == nil || snapshotVolumeBackup.Name == nil {
+		return nil, fmt.Errorf("volume %s is not found in snapshot %s/%s", volumeBackup.VolumeName, snapshot.Namespace, snapshot.Name)
+	}
+
+	volumeSnapshot, err := h.snapshotCache.Get(snapshot.Namespace, *snapshotVolumeBackup.Name)
+	if err != nil {
+		return nil, err
+	}
+	if volumeSnapshot.Status == nil || volumeSnapshot.Status.BoundVolumeSnapshotContentName == nil {
+		return nil, fmt.Errorf("volumesnapshot %s/%s is not bound", volumeSnapshot.Namespace, volumeSnapshot.Name)
+	}
+
+	volumeSnapshotContent, err := h.snapshotContentCache.Get(*volumeSnapshot.Status.BoundVolumeSnapshotContentName)
+	if err != nil {
+		return nil, err
+	}
+	if volumeSnapshotContent.Status == nil || volumeSnapshotContent.Status.SnapshotHandle == nil {
+		return nil, fmt.Errorf("volumesnapshotcontent %s has no snapshot handle", volumeSnapshotContent.Name)
+	}
+
+	snapshotType, volumeName, lhSnapshotName := decodeSnapshotID(*volumeSnapshotContent.Status.SnapshotHandle)
+	if snapshotType != csiSnapshotTypeLonghornSnapshot {
+		return nil, fmt.Errorf("volumesnapshotcontent %s is not a longhorn snapshot", volumeSnapshotContent.Name)
+	}
+
+	if err := h.configureVolumeBackupTarget(vmBackup, volumeBackup); err != nil {
+		return nil, err
+	}
+
+	lhBackupTargetName := backuputil.GetLonghornBackupTargetName(vmBackup.Spec.BackupTargetName)
+	return h.lhbackups.Create(&lhv1beta2.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      *volumeBackup.Name,
+			Namespace: util.LonghornSystemNamespaceName,
+			Labels:    lhtypes.GetBackupVolumeWithBackupTargetLabels(lhBackupTargetName, volumeName),
+		},
+		Spec: lhv1beta2.BackupSpec{
+			SnapshotName: lhSnapshotName,
+		},
+	})
+}
+
+// deletePromotedLHBackups deletes the Longhorn backups which don't have a volume snapshot yet,
+// the others are deleted by the CSI driver with their volume snapshot contents.
+func (h *Handler) deletePromotedLHBackups(vmBackup *harvesterv1.VirtualMachineBackup) error {
+	for _, vb := range vmBackup.Status.VolumeBackups {
+		if vb.Name == nil || vb.LonghornBackupName == nil {
+			continue
+		}
+
+		volumeSnapshot, err := h.getVolumeSnapshot(vmBackup.Namespace, *vb.Name)
+		if err != nil {
+			return err
+		}
+		if volumeSnapshot != nil {
+			continue
+		}
+
+		if err := h.lhbackups.Delete(util.LonghornSystemNamespaceName, *vb.LonghornBackupName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+const (
+	promotedSnapshotName       = "snap"
+	promotedBackupName         = "backup"
+	promotedVolumeSnapshotName = "snap-volume-vm-disk-0"
+	promotedContentName        = "snapcontent-1"
+	promotedLHVolumeName       = "pvc-volume"
+	promotedLHBackupName       = "backup-volume-vm-disk-0"
+)
+
+// fakeVMBackupController records the vm backups enqueued by the handler
+type fakeVMBackupController struct {
+	ctlharvesterv1.VirtualMachineBackupController
+	enqueued map[string]time.Duration
+}
+
+func (c *fakeVMBackupController) EnqueueAfter(namespace, name string, duration time.Duration) {
+	c.enqueued[namespace+"/"+name] = duration
+}
+
+func newPromoteHandler(clientset *fake.Clientset) *Handler {
+	return &Handler{
+		vmBackups:            fakeclients.VMBackupClient(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+		vmBackupCache:        fakeclients.VMBackupCache(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+		vmBackupController:   &fakeVMBackupController{enqueued: map[string]time.Duration{}},
+		lhbackups:            fakeclients.LonghornBackupClient(clientset.LonghornV1beta2().Backups),
+		lhbackupCache:        fakeclients.LonghornBackupCache(clientset.LonghornV1beta2().Backups),
+		volumes:              fakeclients.LonghornVolumeClient(clientset.LonghornV1beta2().Volumes),
+		volumeCache:          fakeclients.LonghornVolumeCache(clientset.LonghornV1beta2().Volumes),
+		backupTargetCache:    fakeclients.BackupTargetCache(clientset.HarvesterhciV1beta1().BackupTargets),
+		snapshots:            fakeclients.VolumeSnapshotClient(clientset.SnapshotV1().VolumeSnapshots),
+		snapshotCache:        fakeclients.VolumeSnapshotCache(clientset.SnapshotV1().VolumeSnapshots),
+		snapshotContents:     fakeclients.VolumeSnapshotContentClient(clientset.SnapshotV1().VolumeSnapshotContents),
+		snapshotContentCache: fakeclients.VolumeSnapshotContentCache(clientset.SnapshotV1().VolumeSnapshotContents),
+		snapshotClassCache:   fakeclients.VolumeSnapshotClassCache(clientset.SnapshotV1().VolumeSnapshotClasses),
+		recorder:             record.NewFakeRecorder(10),
+	}
+}
+
+func newPromotedSnapshot() *harvesterv1.VirtualMachineBackup {
+	return &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: promotedSnapshotName, UID: "snap-uid"},
+		Spec: harvesterv1.VirtualMachineBackupSpec{
+			Source: corev1.TypedLocalObjectReference{Kind: kubevirtv1.VirtualMachineGroupVersionKind.Kind, Name: "vm"},
+			Type:   harvesterv1.Snapshot,
+		},
+		Status: harvesterv1.VirtualMachineBackupStatus{
+			ReadyToUse:   ptr.To(true),
+			SourceUID:    ptr.To(types.UID("vm-uid")),
+			SourceSpec:   &harvesterv1.VirtualMachineSourceSpec{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"}},
+			CreationTime: ptr.To(metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))),
+			VolumeBackups: []harvesterv1.VolumeBackup{{
+				Name:          ptr.To(promotedVolumeSnapshotName),
+				VolumeName:    "disk-0",
+				CSIDriverName: util.CSIProvisionerLonghorn,
+				PersistentVolumeClaim: harvesterv1.PersistentVolumeClaimSourceSpec{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm-disk-0"},
+					Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: promotedLHVolumeName},
+				},
+				VolumeSize: 10 << 30,
+				ReadyToUse: ptr.To(true),
+			}},
+		},
+	}
+}
+
+func newPromotedBackup() *harvesterv1.VirtualMachineBackup {
+	return &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        promotedBackupName,
+			UID:         "backup-uid",
+			Annotations: map[string]string{util.AnnotationPromotedFrom: promotedSnapshotName},
+		},
+		Spec: harvesterv1.VirtualMachineBackupSpec{
+			Source:           corev1.TypedLocalObjectReference{Kind: kubevirtv1.VirtualMachineGroupVersionKind.Kind, Name: "vm"},
+			Type:             harvesterv1.Backup,
+			BackupTargetName: "nfs-target",
+		},
+	}
+}
+
+// newPromotedBackupObjects returns the objects behind a ready VM snapshot, from the volume snapshot
+// down to the Longhorn snapshot of the volume
+func newPromotedBackupObjects() []runtime.Object {
+	return []runtime.Object{
+		&harvesterv1.BackupTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: "nfs-target"},
+			Spec:       harvesterv1.BackupTargetSpec{Type: harvesterv1.BackupTargetTypeNFS, Endpoint: "nfs://10.0.0.1:/exports/backup"},
+		},
+		&snapshotv1.VolumeSnapshotClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "longhorn"},
+			Driver:     util.CSIProvisionerLonghorn,
+		},
+		&snapshotv1.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: promotedVolumeSnapshotName},
+			Status:     &snapshotv1.VolumeSnapshotStatus{BoundVolumeSnapshotContentName: ptr.To(promotedContentName)},
+		},
+		&snapshotv1.VolumeSnapshotContent{
+			ObjectMeta: metav1.ObjectMeta{Name: promotedContentName},
+			Status:     &snapshotv1.VolumeSnapshotContentStatus{SnapshotHandle: ptr.To("snap://" + promotedLHVolumeName + "/snapshot-1")},
+		},
+		&lhv1beta2.Volume{
+			ObjectMeta: metav1.ObjectMeta{Namespace: util.LonghornSystemNamespaceName, Name: promotedLHVolumeName},
+			Spec:       lhv1beta2.VolumeSpec{BackupTargetName: "default"},
+		},
+	}
+}
+
+func TestHandler_initPromotedBackup(t *testing.T) {
+	tests := []struct {
+		name        string
+		snapshot    func(*harvesterv1.VirtualMachineBackup)
+		noSnapshot  bool
+		expectError bool
+	}{
+		{
+			name: "status is taken from the snapshot",
+		},
+		{
+			name:        "snapshot is gone",
+			noSnapshot:  true,
+			expectError: true,
+		},
+		{
+			name:        "snapshot isn't ready",
+			snapshot:    func(snapshot *harvesterv1.VirtualMachineBackup) { snapshot.Status.ReadyToUse = ptr.To(false) },
+			expectError: true,
+		},
+		{
+			name:        "promoted from a backup",
+			snapshot:    func(snapshot *harvesterv1.VirtualMachineBackup) { snapshot.Spec.Type = harvesterv1.Backup },
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vmBackup := newPromotedBackup()
+			clientset := fake.NewSimpleClientset(append(newPromotedBackupObjects(), vmBackup)...)
+			snapshot := newPromotedSnapshot()
+			if tc.snapshot != nil {
+				tc.snapshot(snapshot)
+			}
+			if !tc.noSnapshot {
+				require.NoError(t, clientset.Tracker().Add(snapshot))
+			}
+			h := newPromoteHandler(clientset)
+
+			err := h.initPromotedBackup(vmBackup)
+			stored, getErr := clientset.HarvesterhciV1beta1().VirtualMachineBackups("default").Get(context.TODO(), promotedBackupName, metav1.GetOptions{})
+			require.NoError(t, getErr)
+			if tc.expectError {
+				assert.Error(t, err)
+				assert.True(t, IsBackupMissingStatus(stored))
+				return
+			}
+			require.NoError(t, err)
+
+			assert.False(t, IsBackupReady(stored))
+			assert.Equal(t, snapshot.Status.SourceUID, stored.Status.SourceUID)
+			assert.Equal(t, snapshot.Status.SourceSpec, stored.Status.SourceSpec)
+			assert.Equal(t, snapshot.Status.CreationTime, stored.Status.CreationTime)
+			assert.Equal(t, map[string]string{util.CSIProvisionerLonghorn: "longhorn"}, stored.Status.CSIDriverVolumeSnapshotClassNames)
+			require.NotNil(t, stored.Status.BackupTarget)
+			assert.Equal(t, "nfs-target", stored.Status.BackupTarget.Name)
+			assert.Equal(t, "nfs://10.0.0.1:/exports/backup", stored.Status.BackupTarget.Endpoint)
+			require.Len(t, stored.Status.VolumeBackups, 1)
+			volumeBackup := stored.Status.VolumeBackups[0]
+			assert.Equal(t, promotedLHBackupName, *volumeBackup.Name)
+			assert.Equal(t, "disk-0", volumeBackup.VolumeName)
+			assert.Equal(t, snapshot.Status.VolumeBackups[0].PersistentVolumeClaim, volumeBackup.PersistentVolumeClaim)
+			assert.Equal(t, int64(10<<30), volumeBackup.VolumeSize)
+			assert.False(t, *volumeBackup.ReadyToUse)
+			assert.Nil(t, volumeBackup.LonghornBackupName)
+		})
+	}
+}
+
+func TestHandler_reconcilePromotedLHBackup(t *testing.T) {
+	tests := []struct {
+		name            string
+		lhBackupState   lhv1beta2.BackupState
+		existing        bool
+		volumeName      string
+		objects         func([]runtime.Object)
+		expectError     bool
+		expectCompleted bool
+		expectCreated   bool
+	}{
+		{
+			name:          "longhorn backup is created from the longhorn snapshot",
+			expectCreated: true,
+		},
+		{
+			name:          "longhorn backup is in progress",
+			existing:      true,
+			lhBackupState: lhv1beta2.BackupStateInProgress,
+		},
+		{
+			name:            "longhorn backup is completed",
+			existing:        true,
+			lhBackupState:   lhv1beta2.BackupStateCompleted,
+			expectCompleted: true,
+		},
+		{
+			name:          "longhorn backup failed",
+			existing:      true,
+			lhBackupState: lhv1beta2.BackupStateError,
+			expectError:   true,
+		},
+		{
+			name:        "volume isn't in the snapshot",
+			volumeName:  "disk-1",
+			expectError: true,
+		},
+		{
+			name: "volume snapshot isn't bound",
+			objects: func(objects []runtime.Object) {
+				objects[2].(*snapshotv1.VolumeSnapshot).Status = nil
+			},
+			expectError: true,
+		},
+		{
+			name: "volume snapshot content has no snapshot handle",
+			objects: func(objects []runtime.Object) {
+				objects[3].(*snapshotv1.VolumeSnapshotContent).Status.SnapshotHandle = nil
+			},
+			expectError: true,
+		},
+		{
+			name: "volume snapshot content isn't a longhorn snapshot",
+			objects: func(objects []runtime.Object) {
+				objects[3].(*snapshotv1.VolumeSnapshotContent).Status.SnapshotHandle = ptr.To("bak://" + promotedLHVolumeName + "/backup-1")
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := newPromotedBackupObjects()
+			if tc.objects != nil {
+				tc.objects(objects)
+			}
+			vmBackup := newPromotedBackup()
+			clientset := fake.NewSimpleClientset(append(objects, newPromotedSnapshot(), vmBackup)...)
+			if tc.existing {
+				require.NoError(t, clientset.Tracker().Add(&lhv1beta2.Backup{
+					ObjectMeta: metav1.ObjectMeta{Namespace: util.LonghornSystemNamespaceName, Name: promotedLHBackupName},
+					Status:     lhv1beta2.BackupStatus{State: tc.lhBackupState, Error: "failed to upload"},
+				}))
+			}
+			h := newPromoteHandler(clientset)
+			volumeName := "disk-0"
+			if tc.volumeName != "" {
+				volumeName = tc.volumeName
+			}
+
+			lhBackupName, completed, err := h.reconcilePromotedLHBackup(vmBackup, harvesterv1.VolumeBackup{
+				Name:                  ptr.To(promotedLHBackupName),
+				VolumeName:            volumeName,
+				CSIDriverName:         util.CSIProvisionerLonghorn,
+				PersistentVolumeClaim: newPromotedSnapshot().Status.VolumeBackups[0].PersistentVolumeClaim,
+			})
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, promotedLHBackupName, lhBackupName)
+			assert.Equal(t, tc.expectCompleted, completed)
+
+			lhBackup, err := clientset.LonghornV1beta2().Backups(util.LonghornSystemNamespaceName).Get(context.TODO(), promotedLHBackupName, metav1.GetOptions{})
+			require.NoError(t, err)
+			if !tc.expectCreated {
+				return
+			}
+			assert.Equal(t, "snapshot-1", lhBackup.Spec.SnapshotName)
+			assert.Equal(t, promotedLHVolumeName, lhBackup.Labels["backup-volume"])
+			assert.Equal(t, "nfs-target", lhBackup.Labels["backup-target"])
+			// the longhorn backup is stored to the backup target of the vm backup
+			volume, err := clientset.LonghornV1beta2().Volumes(util.LonghornSystemNamespaceName).Get(context.TODO(), promotedLHVolumeName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "nfs-target", volume.Spec.BackupTargetName)
+		})
+	}
+}
+
+func TestHandler_reconcileVolumeSnapshots_promoted(t *testing.T) {
+	tests := []struct {
+		name                 string
+		lhBackupState        lhv1beta2.BackupState
+		expectVolumeSnapshot bool
+	}{
+		{
+			name:          "volume snapshot waits for the longhorn backup",
+			lhBackupState: lhv1beta2.BackupStateInProgress,
+		},
+		{
+			name:                 "volume snapshot is created from the longhorn backup",
+			lhBackupState:        lhv1beta2.BackupStateCompleted,
+			expectVolumeSnapshot: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(append(newPromotedBackupObjects(), newPromotedSnapshot(), newPromotedBackup())...)
+			require.NoError(t, clientset.Tracker().Add(&lhv1beta2.Backup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: util.LonghornSystemNamespaceName, Name: promotedLHBackupName},
+				Status:     lhv1beta2.BackupStatus{State: tc.lhBackupState, VolumeName: promotedLHVolumeName},
+			}))
+			h := newPromoteHandler(clientset)
+			require.NoError(t, h.initPromotedBackup(newPromotedBackup()))
+			vmBackup, err := clientset.HarvesterhciV1beta1().VirtualMachineBackups("default").Get(context.TODO(), promotedBackupName, metav1.GetOptions{})
+			require.NoError(t, err)
+			_, volumeSnapshotClasses, err := h.getCSIDriverMap(vmBackup)
+			require.NoError(t, err)
+
+			require.NoError(t, h.reconcileVolumeSnapshots(vmBackup, volumeSnapshotClasses))
+
+			stored, err := clientset.HarvesterhciV1beta1().VirtualMachineBackups("default").Get(context.TODO(), promotedBackupName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, promotedLHBackupName, *stored.Status.VolumeBackups[0].LonghornBackupName)
+
+			volumeSnapshot, err := clientset.SnapshotV1().VolumeSnapshots("default").Get(context.TODO(), promotedLHBackupName, metav1.GetOptions{})
+			if !tc.expectVolumeSnapshot {
+				assert.True(t, apierrors.IsNotFound(err))
+				_, ok := h.vmBackupController.(*fakeVMBackupController).enqueued["default/"+promotedBackupName]
+				assert.True(t, ok)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, volumeSnapshot.Spec.Source.PersistentVolumeClaimName)
+			require.NotNil(t, volumeSnapshot.Spec.Source.VolumeSnapshotContentName)
+			content, err := clientset.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), *volumeSnapshot.Spec.Source.VolumeSnapshotContentName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "bak://"+promotedLHVolumeName+"/"+promotedLHBackupName, *content.Spec.Source.SnapshotHandle)
+		})
+	}
+}
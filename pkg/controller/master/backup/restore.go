@@ -198,8 +198,8 @@ func (h *RestoreHandler) RestoreOnChanged(_ string, restore *harvesterv1.Virtual
 		return nil, h.updateStatusError(restore, err, true)
 	}
 
-	// set vmRestore owner reference to the target VM
-	if len(restore.OwnerReferences) == 0 {
+	// set vmRestore owner reference to the target VM, there is no target VM for standalone volume restores
+	if vm != nil && len(restore.OwnerReferences) == 0 {
 		return nil, h.updateOwnerRefAndTargetUID(restore, vm)
 	}
 
@@ -416,8 +416,9 @@ func (h *RestoreHandler) initStatus(restore *harvesterv1.VirtualMachineRestore)
 	return nil
 }
 
-// getDeletedVolumesFromCurrentVM extracts PVC volume names from the current VM referenced in backup's spec.source
-func (h *RestoreHandler) getDeletedVolumesFromCurrentVM(backup *harvesterv1.VirtualMachineBackup) ([]string, error) {
+// getDeletedVolumesFromCurrentVM extracts PVC volume names from the current VM referenced in backup's spec.source,
+// only the PVCs of the selected volumes are replaced and deleted for in-place volume restores.
+func (h *RestoreHandler) getDeletedVolumesFromCurrentVM(vmRestore *harvesterv1.VirtualMachineRestore, backup *harvesterv1.VirtualMachineBackup) ([]string, error) {
 	if backup.Spec.Source.Kind != kubevirtv1.VirtualMachineGroupVersionKind.Kind {
 		return nil, fmt.Errorf("unsupported backup source kind: %s, expected %s", backup.Spec.Source.Kind, kubevirtv1.VirtualMachineGroupVersionKind.Kind)
 	}
@@ -433,7 +434,7 @@ func (h *RestoreHandler) getDeletedVolumesFromCurrentVM(backup *harvesterv1.Virt
 
 	var deletedVolumes []string
 	for _, volume := range currentVM.Spec.Template.Spec.Volumes {
-		if volume.PersistentVolumeClaim != nil {
+		if volume.PersistentVolumeClaim != nil && IsVolumeSelected(vmRestore, volume.Name) {
 			deletedVolumes = append(deletedVolumes, volume.PersistentVolumeClaim.ClaimName)
 		}
 	}
@@ -453,7 +454,7 @@ func (h *RestoreHandler) initVolumesStatus(vmr *harvesterv1.VirtualMachineRestor
 	}
 
 	if !IsNewVMOrHasRetainPolicy(vmr) && vmr.Status.DeletedVolumes == nil {
-		deletedVolumes, err := h.getDeletedVolumesFromCurrentVM(vmb)
+		deletedVolumes, err := h.getDeletedVolumesFromCurrentVM(vmr, vmb)
 		if err != nil {
 			return err
 		}
@@ -488,6 +489,10 @@ func (h *RestoreHandler) getVM(vmRestore *harvesterv1.VirtualMachineRestore) (*k
 func getVolumeRestores(vmRestore *harvesterv1.VirtualMachineRestore, backup *harvesterv1.VirtualMachineBackup) ([]harvesterv1.VolumeRestore, error) {
 	restores := make([]harvesterv1.VolumeRestore, 0, len(backup.Status.VolumeBackups))
 	for _, vb := range backup.Status.VolumeBackups {
+		if !IsVolumeSelected(vmRestore, vb.VolumeName) {
+			continue
+		}
+
 		found := false
 		for _, vr := range vmRestore.Status.VolumeRestores {
 			if vb.VolumeName == vr.VolumeName {
@@ -509,16 +514,33 @@ func getVolumeRestores(vmRestore *harvesterv1.VirtualMachineRestore, backup *har
 						Name:      getRestorePVCName(vmRestore, vb.VolumeName),
 						Namespace: vmRestore.Namespace,
 					},
-					Spec: vb.PersistentVolumeClaim.Spec,
+					Spec: *vb.PersistentVolumeClaim.Spec.DeepCopy(),
 				},
 				VolumeBackupName: *vb.Name,
 			}
+			if sc := vr.PersistentVolumeClaim.Spec.StorageClassName; sc != nil {
+				vr.PersistentVolumeClaim.Spec.StorageClassName = ptr.To(MapStorageClassName(vmRestore, *sc))
+			}
 			restores = append(restores, vr)
 		}
 	}
+
+	if len(restores) != len(vmRestore.Spec.Volumes) && len(vmRestore.Spec.Volumes) != 0 {
+		return nil, fmt.Errorf("not all volumes %v are found in vmbackup %s/%s", vmRestore.Spec.Volumes, backup.Namespace, backup.Name)
+	}
 	return restores, nil
 }
 
+// getVolumeBackup returns the volume backup of the volume restore
+func getVolumeBackup(backup *harvesterv1.VirtualMachineBackup, volumeRestore harvesterv1.VolumeRestore) (harvesterv1.VolumeBackup, error) {
+	for _, vb := range backup.Status.VolumeBackups {
+		if vb.VolumeName == volumeRestore.VolumeName {
+			return vb, nil
+		}
+	}
+	return harvesterv1.VolumeBackup{}, fmt.Errorf("volume %s is not found in vmbackup %s/%s", volumeRestore.VolumeName, backup.Namespace, backup.Name)
+}
+
 func (h *RestoreHandler) reconcileResources(
 	vmRestore *harvesterv1.VirtualMachineRestore,
 	backup *harvesterv1.VirtualMachineBackup,
@@ -529,6 +551,11 @@ func (h *RestoreHandler) reconcileResources(
 		return nil, false, err
 	}
 
+	// standalone volume restores only create PVCs
+	if IsStandaloneVolumeRestore(vmRestore) {
+		return nil, isVolumesReady, nil
+	}
+
 	// reconcile VM
 	vm, err := h.reconcileVM(vmRestore, backup)
 	if err != nil {
@@ -548,10 +575,13 @@ func (h *RestoreHandler) reconcileVolumeRestores(
 	backup *harvesterv1.VirtualMachineBackup,
 ) (bool, error) {
 	isVolumesReady := true
-	for i, volumeRestore := range vmRestore.Status.VolumeRestores {
+	for _, volumeRestore := range vmRestore.Status.VolumeRestores {
 		pvc, err := h.pvcCache.Get(vmRestore.Namespace, volumeRestore.PersistentVolumeClaim.ObjectMeta.Name)
 		if apierrors.IsNotFound(err) {
-			volumeBackup := backup.Status.VolumeBackups[i]
+			volumeBackup, err := getVolumeBackup(backup, volumeRestore)
+			if err != nil {
+				return false, err
+			}
 			if err = h.createRestoredPVC(vmRestore, volumeBackup, volumeRestore); err != nil {
 				return false, err
 			}
@@ -613,14 +643,23 @@ func (h *RestoreHandler) reconcileVM(
 	}
 
 	// VM doesn't have correct annotations like restore to existing VM.
-	// We update its volumes to new reconsile volumes
-	newVolumes, err := getNewVolumes(&backup.Status.SourceSpec.Spec, vmRestore)
+	// We update its volumes to new reconsile volumes.
+	// In-place volume restores only replace the selected volumes of the current VM.
+	vmSpec := backup.Status.SourceSpec.Spec
+	if vmRestore.Spec.VolumeRestoreMode == harvesterv1.VolumeRestoreModeInPlace {
+		vmSpec = vm.Spec
+	}
+
+	newVolumes, err := getNewVolumes(&vmSpec, vmRestore)
 	if err != nil {
 		return nil, err
 	}
 
 	vmCpy := vm.DeepCopy()
-	vmCpy.Spec = backup.Status.SourceSpec.Spec
+	vmCpy.Spec = *vmSpec.DeepCopy()
+	if vmRestore.Spec.VolumeRestoreMode != harvesterv1.VolumeRestoreModeInPlace {
+		mapVirtualMachineForRestore(vmRestore, backup.Status.SourceSpec.ObjectMeta.Namespace, &vmCpy.Spec.Template.Spec)
+	}
 
 	//if the source runStratedy is RerunOnFailure, Kubevirt will not start the new VMI
 	//set the VM runStrategy as Halted, VMI will be kicked off in startVM()
@@ -724,6 +763,7 @@ func (h *RestoreHandler) createNewVM(restore *harvesterv1.VirtualMachineRestore,
 	logrus.Infof("restore target does not exist, creating a new vm %s", vmName)
 
 	vmCpy := backup.Status.SourceSpec.DeepCopy()
+	mapVirtualMachineForRestore(restore, vmCpy.ObjectMeta.Namespace, &vmCpy.Spec.Template.Spec)
 
 	newVMAnnotations := getNewVMAnnotations(restore, vmCpy.ObjectMeta.Annotations)
 
@@ -968,23 +1008,39 @@ func (h *RestoreHandler) createRestoredPVC(
 		}
 	}
 	annotations[restoreNameAnnotation] = vmRestore.Name
+	mapPVCAnnotationsForRestore(vmRestore, annotations)
+
+	storageClassName := volumeBackup.PersistentVolumeClaim.Spec.StorageClassName
+	if storageClassName != nil {
+		storageClassName = ptr.To(MapStorageClassName(vmRestore, *storageClassName))
+		// the backup may come from another cluster with different storage classes
+		if err := checkStorageClass(h.scCache, *storageClassName); err != nil {
+			return fmt.Errorf("storage class %s of volume %s is not available, map it to another storage class: %w", *storageClassName, volumeBackup.VolumeName, err)
+		}
+	}
+
+	// standalone PVCs are kept after the restore is deleted
+	var ownerRefs []metav1.OwnerReference
+	if !IsStandaloneVolumeRestore(vmRestore) {
+		ownerRefs = []metav1.OwnerReference{
+			{
+				APIVersion:         harvesterv1.SchemeGroupVersion.String(),
+				Kind:               vmRestoreKindName,
+				Name:               vmRestore.Name,
+				UID:                vmRestore.UID,
+				Controller:         ptr.To(true),
+				BlockOwnerDeletion: ptr.To(true),
+			},
+		}
+	}
 
 	_, err = h.pvcClient.Create(&corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        volumeRestore.PersistentVolumeClaim.ObjectMeta.Name,
-			Namespace:   vmRestore.Namespace,
-			Labels:      volumeBackup.PersistentVolumeClaim.ObjectMeta.Labels,
-			Annotations: annotations,
-			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion:         harvesterv1.SchemeGroupVersion.String(),
-					Kind:               vmRestoreKindName,
-					Name:               vmRestore.Name,
-					UID:                vmRestore.UID,
-					Controller:         ptr.To(true),
-					BlockOwnerDeletion: ptr.To(true),
-				},
-			},
+			Name:            volumeRestore.PersistentVolumeClaim.ObjectMeta.Name,
+			Namespace:       vmRestore.Namespace,
+			Labels:          volumeBackup.PersistentVolumeClaim.ObjectMeta.Labels,
+			Annotations:     annotations,
+			OwnerReferences: ownerRefs,
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes: volumeBackup.PersistentVolumeClaim.Spec.AccessModes,
@@ -994,7 +1050,7 @@ func (h *RestoreHandler) createRestoredPVC(
 				Name:     dataSourceName,
 			},
 			Resources:        volumeBackup.PersistentVolumeClaim.Spec.Resources,
-			StorageClassName: volumeBackup.PersistentVolumeClaim.Spec.StorageClassName,
+			StorageClassName: storageClassName,
 			VolumeMode:       volumeBackup.PersistentVolumeClaim.Spec.VolumeMode,
 		},
 	})
@@ -1265,6 +1321,10 @@ func (h *RestoreHandler) updateStatus(
 		return nil
 	}
 
+	if IsStandaloneVolumeRestore(vmRestore) {
+		return h.completeRestore(restoreCpy)
+	}
+
 	// start VM before checking status
 	if err := h.startVM(vm); err != nil {
 		return h.updateStatusError(vmRestore, fmt.Errorf("failed to start vm, err:%s", err.Error()), false)
@@ -1287,6 +1347,10 @@ func (h *RestoreHandler) updateStatus(
 		return h.updateStatusError(vmRestore, fmt.Errorf("error cleaning up, err:%s", err.Error()), false)
 	}
 
+	return h.completeRestore(restoreCpy)
+}
+
+func (h *RestoreHandler) completeRestore(restoreCpy *harvesterv1.VirtualMachineRestore) error {
 	h.recorder.Eventf(
 		restoreCpy,
 		corev1.EventTypeNormal,
@@ -0,0 +1,161 @@
+package backup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func TestIsStandaloneVolumeRestore(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     harvesterv1.VolumeRestoreMode
+		expected bool
+	}{
+		{
+			name:     "restore the whole VM",
+			expected: false,
+		},
+		{
+			name:     "restore volumes in place",
+			mode:     harvesterv1.VolumeRestoreModeInPlace,
+			expected: false,
+		},
+		{
+			name:     "restore standalone volumes",
+			mode:     harvesterv1.VolumeRestoreModeStandalone,
+			expected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			restore := &harvesterv1.VirtualMachineRestore{}
+			restore.Spec.VolumeRestoreMode = tc.mode
+			assert.Equal(t, tc.expected, IsStandaloneVolumeRestore(restore))
+		})
+	}
+}
+
+func Test_getVolumeBackup(t *testing.T) {
+	backup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backup"},
+		Status: harvesterv1.VirtualMachineBackupStatus{
+			VolumeBackups: []harvesterv1.VolumeBackup{
+				{Name: ptr.To("backup-disk-0"), VolumeName: "disk-0"},
+				{Name: ptr.To("backup-disk-1"), VolumeName: "disk-1"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		volumeName   string
+		expectedName string
+		expectedErr  bool
+	}{
+		{
+			name:         "volume backup is found",
+			volumeName:   "disk-1",
+			expectedName: "backup-disk-1",
+		},
+		{
+			name:        "volume isn't in the backup",
+			volumeName:  "disk-2",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			volumeBackup, err := getVolumeBackup(backup, harvesterv1.VolumeRestore{VolumeName: tc.volumeName})
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedName, *volumeBackup.Name)
+			assert.Equal(t, tc.volumeName, volumeBackup.VolumeName)
+		})
+	}
+}
+
+func TestRestoreHandler_completeRestore(t *testing.T) {
+	restore := &harvesterv1.VirtualMachineRestore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "restore"},
+	}
+	clientset := fake.NewSimpleClientset(restore)
+	recorder := record.NewFakeRecorder(10)
+	h := &RestoreHandler{
+		restores: fakeclients.VMRestoreClient(clientset.HarvesterhciV1beta1().VirtualMachineRestores),
+		recorder: recorder,
+	}
+
+	require.NoError(t, h.completeRestore(restore.DeepCopy()))
+
+	completed, err := clientset.HarvesterhciV1beta1().VirtualMachineRestores("default").Get(context.TODO(), "restore", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, ptr.Deref(completed.Status.Complete, false))
+	assert.NotNil(t, completed.Status.RestoreTime)
+	assert.True(t, harvesterv1.BackupConditionReady.IsTrue(completed))
+	assert.True(t, harvesterv1.BackupConditionProgressing.IsFalse(completed))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, restoreCompleteEvent)
+}
+
+func TestRestoreHandler_updateStatus_standalone(t *testing.T) {
+	tests := []struct {
+		name           string
+		volumesReady   bool
+		expectComplete bool
+	}{
+		{
+			name:           "standalone restore waits for the new PVCs",
+			volumesReady:   false,
+			expectComplete: false,
+		},
+		{
+			name:           "standalone restore is completed without a VM",
+			volumesReady:   true,
+			expectComplete: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			restore := &harvesterv1.VirtualMachineRestore{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "restore"},
+				Spec: harvesterv1.VirtualMachineRestoreSpec{
+					Volumes:           []string{"disk-0"},
+					VolumeRestoreMode: harvesterv1.VolumeRestoreModeStandalone,
+				},
+			}
+			backup := &harvesterv1.VirtualMachineBackup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backup"},
+				Spec:       harvesterv1.VirtualMachineBackupSpec{Type: harvesterv1.Snapshot},
+			}
+			clientset := fake.NewSimpleClientset(restore)
+			h := &RestoreHandler{
+				restores: fakeclients.VMRestoreClient(clientset.HarvesterhciV1beta1().VirtualMachineRestores),
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			// the standalone restore has no VM to start
+			require.NoError(t, h.updateStatus(restore, backup, nil, tc.volumesReady))
+
+			updated, err := clientset.HarvesterhciV1beta1().VirtualMachineRestores("default").Get(context.TODO(), "restore", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectComplete, ptr.Deref(updated.Status.Complete, false))
+			assert.Equal(t, tc.expectComplete, harvesterv1.BackupConditionReady.IsTrue(updated))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package backup
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/settings"
+	backuputil "github.com/harvester/harvester/pkg/util/backup"
+)
+
+// ThrottleRetryInterval is how often a backup waiting for other backups checks again
+const ThrottleRetryInterval = 30 * time.Second
+
+// ThrottleLock is held while checking the backup throttle and starting the backup. Only one worker of the
+// scheduled and the manual backups at a time checks the capacity, otherwise the backups starting together
+// all see the same free capacity.
+var ThrottleLock sync.Mutex
+
+// CheckBackupThrottle returns the reason why the VM backup can't start now and when it should be checked again,
+// an empty reason means the backup can start. The window overrides the one of the backup-throttle setting.
+func CheckBackupThrottle(vmBackups ctlharvesterv1.VirtualMachineBackupClient, vmCache ctlkubevirtv1.VirtualMachineCache,
+	vmBackup *harvesterv1.VirtualMachineBackup, window *harvesterv1.BackupWindow, now time.Time) (
+	reason harvesterv1.BackupQueuedReason, msg string, retryAfter time.Duration, err error) {
+	// snapshots stay in the cluster and don't use the backup target
+	if vmBackup.Spec.Type != harvesterv1.Backup {
+		return "", "", 0, nil
+	}
+
+	throttle, err := settings.DecodeBackupThrottle(settings.BackupThrottleSet.Get())
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	if window == nil {
+		window = throttle.Window
+	}
+
+	until, err := backuputil.UntilBackupWindow(window, now)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if until > 0 {
+		msg = fmt.Sprintf("backup window %s-%s opens in %s", window.Start, window.End, until.Round(time.Minute))
+		return harvesterv1.BackupQueuedOutsideWindow, msg, until, nil
+	}
+
+	if throttle.MaxConcurrentVolumeBackups <= 0 {
+		return "", "", 0, nil
+	}
+
+	running, err := countRunningVolumeBackups(vmBackups, vmCache, vmBackup)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	volumes, err := countVMVolumes(vmCache, vmBackup.Namespace, vmBackup.Spec.Source.Name)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	// a VM with more volumes than the limit still runs when nothing else does
+	if running > 0 && running+volumes > throttle.MaxConcurrentVolumeBackups {
+		msg = fmt.Sprintf("%d volume backups running, %d more exceed the limit %d", running, volumes, throttle.MaxConcurrentVolumeBackups)
+		return harvesterv1.BackupQueuedConcurrencyLimit, msg, ThrottleRetryInterval, nil
+	}
+
+	return "", "", 0, nil
+}
+
+// IsBackupQueued returns true if the manual backup waits for the backup throttle
+func IsBackupQueued(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	return harvesterv1.BackupConditionQueued.IsTrue(vmBackup)
+}
+
+// countRunningVolumeBackups counts the volume backups of the progressing VM backups in all namespaces except
+// the given one, it lists them from the API server since the cache may not have the VM backups just created yet
+func countRunningVolumeBackups(vmBackups ctlharvesterv1.VirtualMachineBackupClient, vmCache ctlkubevirtv1.VirtualMachineCache,
+	self *harvesterv1.VirtualMachineBackup) (int, error) {
+	list, err := vmBackups.List(metav1.NamespaceAll, metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	running := 0
+	for i := range list.Items {
+		vmBackup := &list.Items[i]
+		if vmBackup.Spec.Type != harvesterv1.Backup || !IsBackupProgressing(vmBackup) || IsBackupQueued(vmBackup) ||
+			(self.UID != "" && vmBackup.UID == self.UID) {
+			continue
+		}
+
+		// a new VM backup doesn't have the volume backups in its status yet, it reserves the volumes of its VM
+		if len(vmBackup.Status.VolumeBackups) == 0 {
+			volumes, err := countVMVolumes(vmCache, vmBackup.Namespace, vmBackup.Spec.Source.Name)
+			if apierrors.IsNotFound(err) {
+				volumes = 1
+			} else if err != nil {
+				return 0, err
+			}
+			running += volumes
+			continue
+		}
+
+		for _, vb := range vmBackup.Status.VolumeBackups {
+			if vb.ReadyToUse == nil || !*vb.ReadyToUse {
+				running++
+			}
+		}
+	}
+	return running, nil
+}
+
+// countVMVolumes counts the volumes of the VM which are backed up
+func countVMVolumes(vmCache ctlkubevirtv1.VirtualMachineCache, namespace, name string) (int, error) {
+	vm, err := vmCache.Get(namespace, name)
+	if err != nil {
+		return 0, err
+	}
+
+	if vm.Spec.Template == nil {
+		return 1, nil
+	}
+
+	volumes := 0
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if isBackupVolume(volume) {
+			volumes++
+		}
+	}
+	return max(volumes, 1), nil
+}
+
+func isBackupVolume(volume kubevirtv1.Volume) bool {
+	return volume.PersistentVolumeClaim != nil || volume.DataVolume != nil
+}
@@ -0,0 +1,97 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newThrottleVM(volumes int) *kubevirtv1.VirtualMachine {
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{},
+		},
+	}
+	for i := 0; i < volumes; i++ {
+		vm.Spec.Template.Spec.Volumes = append(vm.Spec.Template.Spec.Volumes, kubevirtv1.Volume{
+			VolumeSource: kubevirtv1.VolumeSource{
+				PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+					PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc"},
+				},
+			},
+		})
+	}
+	return vm
+}
+
+func newThrottleVMBackup(namespace, name string, volumeBackups int) *harvesterv1.VirtualMachineBackup {
+	vmBackup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID("uid-" + name)},
+		Spec: harvesterv1.VirtualMachineBackupSpec{
+			Type:   harvesterv1.Backup,
+			Source: corev1.TypedLocalObjectReference{Kind: kubevirtv1.VirtualMachineGroupVersionKind.Kind, Name: "vm"},
+		},
+	}
+	for i := 0; i < volumeBackups; i++ {
+		vmBackup.Status.VolumeBackups = append(vmBackup.Status.VolumeBackups, harvesterv1.VolumeBackup{ReadyToUse: ptr.To(false)})
+	}
+	return vmBackup
+}
+
+func Test_CheckBackupThrottle(t *testing.T) {
+	assert := require.New(t)
+	queued := newThrottleVMBackup("default", "queued", 0)
+	setCondition(queued, harvesterv1.BackupConditionQueued, true, string(harvesterv1.BackupQueuedConcurrencyLimit), "")
+	manual := newThrottleVMBackup("default", "manual", 0)
+
+	clientset := fake.NewSimpleClientset(newThrottleVM(2), newThrottleVMBackup("other", "running", 1), queued, manual)
+	assert.NoError(settings.BackupThrottleSet.Set(`{"maxConcurrentVolumeBackups":3}`))
+	defer func() { assert.NoError(settings.BackupThrottleSet.Set("{}")) }()
+
+	// the queued backups and the backup itself don't count as running
+	reason, _, _, err := CheckBackupThrottle(
+		fakeclients.VMBackupClient(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+		fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+		manual, nil, time.Now())
+	assert.NoError(err)
+	assert.Empty(reason)
+}
+
+func Test_StartBackupQueued(t *testing.T) {
+	assert := require.New(t)
+	vm := newThrottleVM(2)
+	manual := newThrottleVMBackup("default", "manual", 0)
+
+	clientset := fake.NewSimpleClientset(vm, newThrottleVMBackup("other", "running", 2), manual)
+	assert.NoError(settings.BackupThrottleSet.Set(`{"maxConcurrentVolumeBackups":2}`))
+	defer func() { assert.NoError(settings.BackupThrottleSet.Set("{}")) }()
+
+	controller := &fakeVMBackupController{enqueued: map[string]time.Duration{}}
+	h := &Handler{
+		vmBackups:          fakeclients.VMBackupClient(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+		vmBackupController: controller,
+		vmsCache:           fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+	}
+
+	// the manual backup waits for the running one, its status isn't initialized until it starts
+	assert.NoError(h.startBackup(manual, vm))
+	stored, err := clientset.HarvesterhciV1beta1().VirtualMachineBackups("default").Get(context.TODO(), "manual", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.True(IsBackupQueued(stored))
+	assert.Equal(string(harvesterv1.BackupQueuedConcurrencyLimit), harvesterv1.BackupConditionQueued.GetReason(stored))
+	assert.True(IsBackupMissingStatus(stored))
+	assert.Equal(ThrottleRetryInterval, controller.enqueued["default/manual"])
+}
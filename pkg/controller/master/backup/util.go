@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
@@ -21,6 +22,7 @@ import (
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctllonghornv2 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta2"
+	"github.com/harvester/harvester/pkg/ref"
 	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
 	backuputil "github.com/harvester/harvester/pkg/util/backup"
@@ -33,6 +35,7 @@ const (
 
 	// The following definition need to be synced with LH
 	// https://github.com/longhorn/longhorn-manager/blob/1f343ee4c467de1264682ecb069d8f2a62850977/csi/controller_server.go#L43-L45
+	csiSnapshotTypeLonghornSnapshot         = "snap"
 	csiSnapshotTypeLonghornBackingImage     = "bi"
 	csiSnapshotTypeLonghornBackup           = "bak"
 	deprecatedCSISnapshotTypeLonghornBackup = "bs"
@@ -43,14 +46,18 @@ func IsBackupReady(backup *harvesterv1.VirtualMachineBackup) bool {
 }
 
 func IsBackupProgressing(backup *harvesterv1.VirtualMachineBackup) bool {
-	return GetVMBackupError(backup) == nil &&
-		(backup.Status.ReadyToUse == nil || !*backup.Status.ReadyToUse)
+	return backuputil.IsBackupProgressing(backup)
 }
 
 func IsBackupMissingStatus(backup *harvesterv1.VirtualMachineBackup) bool {
 	return backup.Status.SourceSpec == nil || backup.Status.VolumeBackups == nil
 }
 
+// IsPromotedBackup returns true if the backup is created from the volume snapshots of a VM snapshot
+func IsPromotedBackup(backup *harvesterv1.VirtualMachineBackup) bool {
+	return backup.Annotations[util.AnnotationPromotedFrom] != ""
+}
+
 func isBackupTargetOnAnnotation(backup *harvesterv1.VirtualMachineBackup) bool {
 	return backup.Annotations != nil &&
 		(backup.Annotations[backupTargetAnnotation] != "" ||
@@ -71,8 +78,20 @@ func isVMRestoreMissingVolumes(vmRestore *harvesterv1.VirtualMachineRestore) boo
 		(!IsNewVMOrHasRetainPolicy(vmRestore) && len(vmRestore.Status.DeletedVolumes) == 0)
 }
 
+// IsNewVMOrHasRetainPolicy returns true if no existing PVC is deleted by the restore,
+// standalone volume restores don't touch any VM, so they also keep the existing PVCs.
 func IsNewVMOrHasRetainPolicy(vmRestore *harvesterv1.VirtualMachineRestore) bool {
-	return vmRestore.Spec.NewVM || vmRestore.Spec.DeletionPolicy == harvesterv1.VirtualMachineRestoreRetain
+	return vmRestore.Spec.NewVM || vmRestore.Spec.DeletionPolicy == harvesterv1.VirtualMachineRestoreRetain ||
+		IsStandaloneVolumeRestore(vmRestore)
+}
+
+func IsStandaloneVolumeRestore(vmRestore *harvesterv1.VirtualMachineRestore) bool {
+	return vmRestore.Spec.VolumeRestoreMode == harvesterv1.VolumeRestoreModeStandalone
+}
+
+// IsVolumeSelected returns true if the volume is selected to restore, all volumes are selected if none is specified
+func IsVolumeSelected(vmRestore *harvesterv1.VirtualMachineRestore, volumeName string) bool {
+	return len(vmRestore.Spec.Volumes) == 0 || slices.Contains(vmRestore.Spec.Volumes, volumeName)
 }
 
 func GetVMBackupError(vmBackup *harvesterv1.VirtualMachineBackup) *harvesterv1.Error {
@@ -194,6 +213,53 @@ func sanitizeVirtualMachineForRestore(restore *harvesterv1.VirtualMachineRestore
 	return spec
 }
 
+// MapStorageClassName returns the storage class of the restored PVC with the storage class mapping of the restore
+func MapStorageClassName(restore *harvesterv1.VirtualMachineRestore, storageClassName string) string {
+	if mapped, ok := restore.Spec.StorageClassMapping[storageClassName]; ok {
+		return mapped
+	}
+	return storageClassName
+}
+
+// MapNetworkName returns the multus network name of the restored VM with the network and namespace mappings of the restore.
+// The network without namespace is in the same namespace as the source VM.
+func MapNetworkName(restore *harvesterv1.VirtualMachineRestore, sourceNamespace, networkName string) string {
+	namespace, name := ref.Parse(networkName)
+	if namespace == "" {
+		namespace = sourceNamespace
+	}
+
+	if mapped, ok := restore.Spec.NetworkMapping[ref.Construct(namespace, name)]; ok {
+		return mapped
+	}
+	if mapped, ok := restore.Spec.NamespaceMapping[namespace]; ok {
+		return ref.Construct(mapped, name)
+	}
+	return networkName
+}
+
+// mapVirtualMachineForRestore applies the network mapping of the restore to the VM spec
+func mapVirtualMachineForRestore(restore *harvesterv1.VirtualMachineRestore, sourceNamespace string, spec *kubevirtv1.VirtualMachineInstanceSpec) {
+	for i, network := range spec.Networks {
+		if network.Multus != nil {
+			spec.Networks[i].Multus.NetworkName = MapNetworkName(restore, sourceNamespace, network.Multus.NetworkName)
+		}
+	}
+}
+
+// mapPVCAnnotationsForRestore applies the namespace mapping of the restore to the image of the PVC
+func mapPVCAnnotationsForRestore(restore *harvesterv1.VirtualMachineRestore, annotations map[string]string) {
+	imageID, ok := annotations[util.AnnotationImageID]
+	if !ok {
+		return
+	}
+
+	namespace, name := ref.Parse(imageID)
+	if mapped, ok := restore.Spec.NamespaceMapping[namespace]; ok {
+		annotations[util.AnnotationImageID] = ref.Construct(mapped, name)
+	}
+}
+
 func getSecretRefName(vmName string, secretName string) string {
 	// Use secret Hex to avoid the length of secret name exceeding the K8s limit caused by repeated backup and restore
 	return fmt.Sprintf("vm-%s-%s-ref", vmName, wranglername.Hex(secretName, 8))
@@ -0,0 +1,208 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func newMappingRestore(namespaceMapping, storageClassMapping, networkMapping map[string]string) *harvesterv1.VirtualMachineRestore {
+	restore := &harvesterv1.VirtualMachineRestore{}
+	restore.Namespace = "target"
+	restore.Spec.NamespaceMapping = namespaceMapping
+	restore.Spec.StorageClassMapping = storageClassMapping
+	restore.Spec.NetworkMapping = networkMapping
+	return restore
+}
+
+func TestMapStorageClassName(t *testing.T) {
+	tests := []struct {
+		name             string
+		mapping          map[string]string
+		storageClassName string
+		expected         string
+	}{
+		{
+			name:             "no mapping",
+			storageClassName: "longhorn",
+			expected:         "longhorn",
+		},
+		{
+			name:             "mapped",
+			mapping:          map[string]string{"longhorn": "longhorn-ssd"},
+			storageClassName: "longhorn",
+			expected:         "longhorn-ssd",
+		},
+		{
+			name:             "unmapped",
+			mapping:          map[string]string{"longhorn": "longhorn-ssd"},
+			storageClassName: "harvester-longhorn",
+			expected:         "harvester-longhorn",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			restore := newMappingRestore(nil, tc.mapping, nil)
+			assert.Equal(t, tc.expected, MapStorageClassName(restore, tc.storageClassName))
+		})
+	}
+}
+
+func TestMapNetworkName(t *testing.T) {
+	tests := []struct {
+		name             string
+		namespaceMapping map[string]string
+		networkMapping   map[string]string
+		networkName      string
+		expected         string
+	}{
+		{
+			name:        "no mapping",
+			networkName: "source/vlan1",
+			expected:    "source/vlan1",
+		},
+		{
+			name:           "mapped network",
+			networkMapping: map[string]string{"source/vlan1": "target/vlan100"},
+			networkName:    "source/vlan1",
+			expected:       "target/vlan100",
+		},
+		{
+			name:           "mapped network without namespace is looked up in the source namespace",
+			networkMapping: map[string]string{"source/vlan1": "target/vlan100"},
+			networkName:    "vlan1",
+			expected:       "target/vlan100",
+		},
+		{
+			name:             "mapped namespace",
+			namespaceMapping: map[string]string{"source": "target"},
+			networkName:      "source/vlan1",
+			expected:         "target/vlan1",
+		},
+		{
+			name:             "mapped namespace of network without namespace",
+			namespaceMapping: map[string]string{"source": "target"},
+			networkName:      "vlan1",
+			expected:         "target/vlan1",
+		},
+		{
+			name:             "network mapping takes precedence over namespace mapping",
+			namespaceMapping: map[string]string{"source": "target"},
+			networkMapping:   map[string]string{"source/vlan1": "other/vlan200"},
+			networkName:      "source/vlan1",
+			expected:         "other/vlan200",
+		},
+		{
+			name:             "network in another namespace than the source VM",
+			namespaceMapping: map[string]string{"shared": "networks"},
+			networkName:      "shared/vlan1",
+			expected:         "networks/vlan1",
+		},
+		{
+			name:             "unmapped network",
+			namespaceMapping: map[string]string{"other": "target"},
+			networkMapping:   map[string]string{"source/vlan2": "target/vlan2"},
+			networkName:      "vlan1",
+			expected:         "vlan1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			restore := newMappingRestore(tc.namespaceMapping, nil, tc.networkMapping)
+			assert.Equal(t, tc.expected, MapNetworkName(restore, "source", tc.networkName))
+		})
+	}
+}
+
+func Test_mapVirtualMachineForRestore(t *testing.T) {
+	newSpec := func(networkNames ...string) *kubevirtv1.VirtualMachineInstanceSpec {
+		spec := &kubevirtv1.VirtualMachineInstanceSpec{
+			Networks: []kubevirtv1.Network{*kubevirtv1.DefaultPodNetwork()},
+		}
+		for _, networkName := range networkNames {
+			spec.Networks = append(spec.Networks, kubevirtv1.Network{
+				Name:          networkName,
+				NetworkSource: kubevirtv1.NetworkSource{Multus: &kubevirtv1.MultusNetwork{NetworkName: networkName}},
+			})
+		}
+		return spec
+	}
+
+	tests := []struct {
+		name             string
+		namespaceMapping map[string]string
+		networkMapping   map[string]string
+		spec             *kubevirtv1.VirtualMachineInstanceSpec
+		expected         []string
+	}{
+		{
+			name:     "no mapping",
+			spec:     newSpec("vlan1", "shared/vlan2"),
+			expected: []string{"vlan1", "shared/vlan2"},
+		},
+		{
+			name:             "mapped and unmapped networks",
+			namespaceMapping: map[string]string{"source": "target"},
+			networkMapping:   map[string]string{"shared/vlan2": "target/vlan200"},
+			spec:             newSpec("vlan1", "shared/vlan2", "shared/vlan3"),
+			expected:         []string{"target/vlan1", "target/vlan200", "shared/vlan3"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			restore := newMappingRestore(tc.namespaceMapping, nil, tc.networkMapping)
+			mapVirtualMachineForRestore(restore, "source", tc.spec)
+
+			// the pod network is kept as it is
+			assert.NotNil(t, tc.spec.Networks[0].Pod)
+			var networkNames []string
+			for _, network := range tc.spec.Networks[1:] {
+				networkNames = append(networkNames, network.Multus.NetworkName)
+			}
+			assert.Equal(t, tc.expected, networkNames)
+		})
+	}
+}
+
+func Test_mapPVCAnnotationsForRestore(t *testing.T) {
+	tests := []struct {
+		name             string
+		namespaceMapping map[string]string
+		annotations      map[string]string
+		expected         map[string]string
+	}{
+		{
+			name:             "no image",
+			namespaceMapping: map[string]string{"source": "target"},
+			annotations:      map[string]string{},
+			expected:         map[string]string{},
+		},
+		{
+			name:             "mapped image namespace",
+			namespaceMapping: map[string]string{"source": "target"},
+			annotations:      map[string]string{util.AnnotationImageID: "source/image"},
+			expected:         map[string]string{util.AnnotationImageID: "target/image"},
+		},
+		{
+			name:             "unmapped image namespace",
+			namespaceMapping: map[string]string{"source": "target"},
+			annotations:      map[string]string{util.AnnotationImageID: "default/image"},
+			expected:         map[string]string{util.AnnotationImageID: "default/image"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			restore := newMappingRestore(tc.namespaceMapping, nil, nil)
+			mapPVCAnnotationsForRestore(restore, tc.annotations)
+			assert.Equal(t, tc.expected, tc.annotations)
+		})
+	}
+}
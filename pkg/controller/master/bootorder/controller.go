@@ -0,0 +1,140 @@
+package bootorder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/bootorder"
+)
+
+const (
+	// bootHoldTimeout is how long a VMI is held at most, so that a VM which never comes up doesn't hold the others forever
+	bootHoldTimeout = 15 * time.Minute
+
+	reasonBootReleased = "BootReleased"
+	reasonBootTimeout  = "BootTimeout"
+)
+
+// Handler releases the VMIs which are started paused by the VMI mutator because their VMs boot after other VMs.
+// It applies to any start of the VMs, e.g. bulk starts, restoring VMs after upgrades and cluster restarts.
+type Handler struct {
+	vmCache                   ctlkubevirtv1.VirtualMachineCache
+	vmiController             ctlkubevirtv1.VirtualMachineInstanceController
+	vmiClient                 ctlkubevirtv1.VirtualMachineInstanceClient
+	vmiCache                  ctlkubevirtv1.VirtualMachineInstanceCache
+	virtSubresourceRestClient rest.Interface
+	recorder                  record.EventRecorder
+}
+
+func (h *Handler) OnVMIChanged(_ string, vmi *kubevirtv1.VirtualMachineInstance) (*kubevirtv1.VirtualMachineInstance, error) {
+	if vmi == nil || vmi.DeletionTimestamp != nil {
+		return vmi, nil
+	}
+
+	if vmi.Labels[util.LabelBootHeld] != "true" {
+		// the VMI may be the one the held VMIs boot after
+		return vmi, h.enqueueHeldVMIs(vmi.Namespace)
+	}
+
+	if vmi.IsFinal() {
+		return vmi, nil
+	}
+
+	// the VMI can't be unpaused until it's running paused
+	if vmi.Status.Phase != kubevirtv1.Running || !isPaused(vmi) {
+		return vmi, nil
+	}
+
+	vm, err := h.vmCache.Get(vmi.Namespace, vmi.Name)
+	if apierrors.IsNotFound(err) {
+		return vmi, h.release(vmi)
+	} else if err != nil {
+		return vmi, err
+	}
+
+	spec, err := bootorder.Parse(vm)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"namespace": vm.Namespace,
+			"name":      vm.Name,
+		}).Warn("Release the VMI held by the invalid boot order")
+		return vmi, h.release(vmi)
+	}
+
+	vms, err := h.vmCache.List(vm.Namespace, labels.Everything())
+	if err != nil {
+		return vmi, err
+	}
+	pending, readyAt, err := bootorder.Check(spec, bootorder.Dependencies(vm, spec, vms), h.vmiCache)
+	if err != nil {
+		return vmi, err
+	}
+
+	if len(pending) != 0 {
+		held := time.Since(vmi.CreationTimestamp.Time)
+		if held < bootHoldTimeout {
+			logrus.Debugf("VMI %s/%s is waiting for VMs %s to be %s", vmi.Namespace, vmi.Name, strings.Join(pending, ", "), spec.Condition)
+			h.vmiController.EnqueueAfter(vmi.Namespace, vmi.Name, bootHoldTimeout-held)
+			return vmi, nil
+		}
+		h.recorder.Eventf(vmi, corev1.EventTypeWarning, reasonBootTimeout,
+			"VMs %s are not %s in %s, boot anyway", strings.Join(pending, ", "), spec.Condition, bootHoldTimeout)
+		return vmi, h.release(vmi)
+	}
+
+	if wait := time.Until(readyAt.Add(spec.Delay)); wait > 0 {
+		h.vmiController.EnqueueAfter(vmi.Namespace, vmi.Name, wait)
+		return vmi, nil
+	}
+
+	h.recorder.Event(vmi, corev1.EventTypeNormal, reasonBootReleased, "The VMs it boots after are up")
+	return vmi, h.release(vmi)
+}
+
+func (h *Handler) enqueueHeldVMIs(namespace string) error {
+	vmis, err := h.vmiCache.List(namespace, labels.SelectorFromSet(labels.Set{util.LabelBootHeld: "true"}))
+	if err != nil {
+		return err
+	}
+	for _, vmi := range vmis {
+		h.vmiController.Enqueue(vmi.Namespace, vmi.Name)
+	}
+	return nil
+}
+
+// release unpauses the VMI and removes the hold label
+func (h *Handler) release(vmi *kubevirtv1.VirtualMachineInstance) error {
+	if isPaused(vmi) {
+		err := h.virtSubresourceRestClient.Put().Namespace(vmi.Namespace).Resource("virtualmachineinstances").
+			SubResource("unpause").Name(vmi.Name).Do(context.Background()).Error()
+		if err != nil {
+			return fmt.Errorf("failed to unpause VMI %s/%s: %w", vmi.Namespace, vmi.Name, err)
+		}
+	}
+
+	vmiCpy := vmi.DeepCopy()
+	delete(vmiCpy.Labels, util.LabelBootHeld)
+	_, err := h.vmiClient.Update(vmiCpy)
+	return err
+}
+
+func isPaused(vmi *kubevirtv1.VirtualMachineInstance) bool {
+	for _, condition := range vmi.Status.Conditions {
+		if condition.Type == kubevirtv1.VirtualMachineInstancePaused && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
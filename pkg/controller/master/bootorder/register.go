@@ -0,0 +1,41 @@
+package bootorder
+
+import (
+	"context"
+
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	"github.com/harvester/harvester/pkg/config"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
+)
+
+const (
+	bootOrderControllerName = "vm-boot-order-controller"
+)
+
+func Register(ctx context.Context, management *config.Management, _ config.Options) error {
+	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
+	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
+
+	virtSubsrcConfig := rest.CopyConfig(management.RestConfig)
+	virtSubsrcConfig.GroupVersion = &k8sschema.GroupVersion{Group: "subresources.kubevirt.io", Version: "v1"}
+	virtSubsrcConfig.APIPath = "/apis"
+	virtSubsrcConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	virtSubresourceClient, err := rest.RESTClientFor(virtSubsrcConfig)
+	if err != nil {
+		return err
+	}
+
+	handler := &Handler{
+		vmCache:                   vms.Cache(),
+		vmiController:             vmis,
+		vmiClient:                 vmis,
+		vmiCache:                  vmis.Cache(),
+		virtSubresourceRestClient: virtSubresourceClient,
+		recorder:                  management.NewRecorder(bootOrderControllerName, "", ""),
+	}
+
+	vmis.OnChange(ctx, bootOrderControllerName, handler.OnVMIChanged)
+	return nil
+}
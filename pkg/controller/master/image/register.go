@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/config"
 	"github.com/harvester/harvester/pkg/image/backend"
@@ -14,7 +16,10 @@ import (
 )
 
 const (
-	vmImageControllerName = "vm-image-controller"
+	vmImageControllerName             = "vm-image-controller"
+	vmImageUsageControllerName        = "vm-image-usage-controller"
+	vmImageConsumerWatcherName        = "vm-image-consumer-watcher"
+	vmImageLifecycleSettingController = "vm-image-lifecycle-setting-controller"
 )
 
 func Register(ctx context.Context, management *config.Management, _ config.Options) error {
@@ -23,7 +28,13 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 	sc := management.StorageFactory.Storage().V1().StorageClass()
 	pvcs := management.CoreFactory.Core().V1().PersistentVolumeClaim()
 	secrets := management.CoreFactory.Core().V1().Secret()
+	configMaps := management.CoreFactory.Core().V1().ConfigMap()
+	bids := management.LonghornFactory.Longhorn().V1beta2().BackingImageDataSource()
 	ctlcdi := management.CdiFactory.Cdi().V1beta1().DataVolume()
+	ctlcdiupload := management.CdiUploadFactory.Upload().V1beta1().UploadTokenRequest()
+	templateVersions := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplateVersion()
+	settings := management.HarvesterFactory.Harvesterhci().V1beta1().Setting()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 
 	vmio, err := common.GetVMIOperator(vmi, vmi.Cache(), sc.Cache(), http.Client{Timeout: 15 * time.Second})
 	if err != nil {
@@ -32,16 +43,19 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 	backends := map[harvesterv1.VMIBackend]backend.Backend{
 		harvesterv1.VMIBackendBackingImage: backingimage.GetBackend(
 			ctx, sc, sc.Cache(),
-			bi, bi, bi.Cache(),
-			pvcs.Cache(), secrets.Cache(),
+			bi, bi, bi.Cache(), bids,
+			pvcs.Cache(), secrets.Cache(), backupTargets.Cache(),
 			vmi, vmi.Cache(), vmio,
+			management.ClientSet,
 		),
-		harvesterv1.VMIBackendCDI: cdi.GetBackend(ctx, ctlcdi, sc, pvcs.Cache(), vmio),
+		harvesterv1.VMIBackendCDI: cdi.GetBackend(ctx, ctlcdi, sc, pvcs.Cache(), secrets, secrets.Cache(), configMaps, ctlcdiupload, vmio),
 	}
 
 	vmImageHandler := &vmImageHandler{
+		ctx:           ctx,
 		vmiClient:     vmi,
 		vmiController: vmi,
+		secretCache:   secrets.Cache(),
 		vmio:          vmio,
 		backends:      backends,
 	}
@@ -49,6 +63,19 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 	vmi.OnChange(ctx, vmImageControllerName, vmImageHandler.OnChanged)
 	vmi.OnRemove(ctx, vmImageControllerName, vmImageHandler.OnRemove)
 
+	vmImageUsageHandler := &vmImageUsageHandler{
+		vmiClient:            vmi,
+		vmiCache:             vmi.Cache(),
+		vmiController:        vmi,
+		pvcCache:             pvcs.Cache(),
+		templateVersionCache: templateVersions.Cache(),
+		now:                  time.Now,
+	}
+
+	vmi.OnChange(ctx, vmImageUsageControllerName, vmImageUsageHandler.OnChanged)
+	relatedresource.Watch(ctx, vmImageConsumerWatcherName, vmImageUsageHandler.ReconcileConsumers, vmi, pvcs, templateVersions)
+	settings.OnChange(ctx, vmImageLifecycleSettingController, vmImageUsageHandler.OnSettingChanged)
+
 	for _, b := range backends {
 		b.AddSidecarHandler()
 	}
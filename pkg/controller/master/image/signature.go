@@ -0,0 +1,118 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/ref"
+)
+
+const (
+	signatureVerifyingReason = "Verifying"
+	signatureVerifiedReason  = "Verified"
+	signatureFailedReason    = "VerificationFailed"
+)
+
+// verifySignature starts the signature verification in the background since the whole image is downloaded,
+// the VM image is initialized once the signature is verified. The verification lost by a restart of harvester is
+// started again by the next reconciliation, since the image is still verifying without a running verification.
+func (h *vmImageHandler) verifySignature(vmi *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
+	if harvesterv1.ImageSignatureVerified.GetReason(vmi) != signatureVerifyingReason {
+		toUpdate := vmi.DeepCopy()
+		harvesterv1.ImageSignatureVerified.Unknown(toUpdate)
+		harvesterv1.ImageSignatureVerified.Reason(toUpdate, signatureVerifyingReason)
+		harvesterv1.ImageSignatureVerified.Message(toUpdate, "")
+		return h.vmio.UpdateVMI(vmi, toUpdate)
+	}
+
+	key := ref.Construct(vmi.Namespace, vmi.Name)
+	ctx, cancel := context.WithCancel(h.ctx)
+	if _, loaded := h.verifications.LoadOrStore(key, cancel); loaded {
+		cancel()
+		return vmi, nil
+	}
+
+	go func() {
+		defer h.verifications.CompareAndDelete(key, cancel)
+		checksum, err := h.doVerifySignature(ctx, vmi)
+		if ctx.Err() != nil {
+			return
+		}
+		if err := h.updateSignatureVerified(vmi, checksum, err); err != nil {
+			logrus.WithError(err).Errorf("failed to update signature verification of vm image %s/%s", vmi.Namespace, vmi.Name)
+			h.vmiController.EnqueueAfter(vmi.Namespace, vmi.Name, checkInterval)
+		}
+	}()
+	return vmi, nil
+}
+
+func (h *vmImageHandler) stopSignatureVerification(vmi *harvesterv1.VirtualMachineImage) {
+	if cancel, loaded := h.verifications.LoadAndDelete(ref.Construct(vmi.Namespace, vmi.Name)); loaded {
+		cancel.(context.CancelFunc)()
+	}
+}
+
+func (h *vmImageHandler) doVerifySignature(ctx context.Context, vmi *harvesterv1.VirtualMachineImage) (string, error) {
+	credentials, err := common.GetDownloadCredentials(h.secretCache, vmi)
+	if err != nil {
+		return "", err
+	}
+	client, err := credentials.NewHTTPClient(0)
+	if err != nil {
+		return "", err
+	}
+	publicKey, err := common.GetSignaturePublicKey(h.secretCache, vmi)
+	if err != nil {
+		return "", err
+	}
+
+	checksum, err := common.VerifySignature(ctx, client, vmi.Spec.URL, vmi.Spec.Signature, publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify signature %s: %w", vmi.Spec.Signature.URL, err)
+	}
+	if vmi.Spec.Checksum != "" && !strings.EqualFold(vmi.Spec.Checksum, checksum) {
+		return "", fmt.Errorf("the checksum %s of the signed image doesn't match checksum %s", checksum, vmi.Spec.Checksum)
+	}
+	return checksum, nil
+}
+
+// updateSignatureVerified records the result of the verification, a failed verification counts as a failed
+// initialization, so it's retried like the other failures.
+func (h *vmImageHandler) updateSignatureVerified(vmi *harvesterv1.VirtualMachineImage, checksum string, verifyErr error) error {
+	if verifyErr != nil {
+		logrus.WithError(verifyErr).WithFields(logrus.Fields{
+			"namespace": vmi.Namespace,
+			"name":      vmi.Name,
+		}).Error("failed to verify signature of vm image")
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := h.vmiClient.Get(vmi.Namespace, vmi.Name, metav1.GetOptions{})
+		if err != nil || current.DeletionTimestamp != nil {
+			return err
+		}
+
+		toUpdate := current.DeepCopy()
+		if verifyErr != nil {
+			harvesterv1.ImageSignatureVerified.False(toUpdate)
+			harvesterv1.ImageSignatureVerified.Reason(toUpdate, signatureFailedReason)
+			harvesterv1.ImageSignatureVerified.Message(toUpdate, verifyErr.Error())
+			_, err = h.vmio.FailInitial(toUpdate, verifyErr)
+			return err
+		}
+
+		toUpdate.Status.VerifiedChecksum = checksum
+		harvesterv1.ImageSignatureVerified.True(toUpdate)
+		harvesterv1.ImageSignatureVerified.Reason(toUpdate, signatureVerifiedReason)
+		harvesterv1.ImageSignatureVerified.Message(toUpdate, "")
+		_, err = h.vmio.UpdateVMI(current, toUpdate)
+		return err
+	})
+}
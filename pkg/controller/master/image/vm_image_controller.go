@@ -1,8 +1,11 @@
 package image
 
 import (
+	"context"
+	"sync"
 	"time"
 
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
 	"github.com/sirupsen/logrus"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
@@ -18,10 +21,14 @@ const (
 
 // vmImageHandler syncs status on vm image changes, and manage a storageclass & a backingimage per vm image
 type vmImageHandler struct {
+	ctx           context.Context
 	vmiClient     ctlharvesterv1.VirtualMachineImageClient
 	vmiController ctlharvesterv1.VirtualMachineImageController
+	secretCache   ctlcorev1.SecretCache
 	vmio          common.VMIOperator
 	backends      map[harvesterv1.VMIBackend]backend.Backend
+	// verifications are the cancel functions of the running signature verifications, keyed by the VM image
+	verifications sync.Map
 }
 
 func (h *vmImageHandler) OnChanged(_ string, vmi *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
@@ -99,10 +106,15 @@ func (h *vmImageHandler) OnRemove(_ string, vmi *harvesterv1.VirtualMachineImage
 		return nil, nil
 	}
 
+	h.stopSignatureVerification(vmi)
 	return vmi, h.backends[util.GetVMIBackend(vmi)].Delete(vmi)
 }
 
 func (h *vmImageHandler) initialize(vmi *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
+	if vmi.Spec.Signature != nil && !harvesterv1.ImageSignatureVerified.IsTrue(vmi) {
+		return h.verifySignature(vmi)
+	}
+
 	toUpdate, err := h.backends[util.GetVMIBackend(vmi)].Initialize(vmi)
 	if err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{
@@ -152,6 +152,10 @@ func TestVMImageHandler_OnChanged_UploadImageInitialization(t *testing.T) {
 				fakeclients.DataVolumeClient(clientset.CdiV1beta1().DataVolumes),
 				fakeclients.StorageClassClient(clientset.StorageV1().StorageClasses),
 				fakeclients.PersistentVolumeClaimCache(clientset.CoreV1().PersistentVolumeClaims),
+				fakeclients.SecretClient(clientset.CoreV1().Secrets),
+				fakeclients.SecretCache(clientset.CoreV1().Secrets),
+				fakeclients.ConfigmapClient(clientset.CoreV1().ConfigMaps),
+				nil,
 				vmio,
 			)
 
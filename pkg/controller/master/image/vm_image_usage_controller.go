@@ -0,0 +1,190 @@
+package image
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/indexeres"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/settings"
+)
+
+const (
+	// gcRetryInterval is how long to wait before deleting the unused image again if the deletion is refused, e.g.
+	// the image is used by the VM backups
+	gcRetryInterval = 1 * time.Hour
+)
+
+// vmImageUsageHandler tracks the PVCs and the VM template versions using the vm image, and deletes the image unused
+// for the TTL if the GC of the vm-image-lifecycle setting is enabled
+type vmImageUsageHandler struct {
+	vmiClient            ctlharvesterv1.VirtualMachineImageClient
+	vmiCache             ctlharvesterv1.VirtualMachineImageCache
+	vmiController        ctlharvesterv1.VirtualMachineImageController
+	pvcCache             ctlcorev1.PersistentVolumeClaimCache
+	templateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache
+	now                  func() time.Time
+}
+
+func (h *vmImageUsageHandler) OnChanged(_ string, vmi *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
+	if vmi == nil || vmi.DeletionTimestamp != nil {
+		return vmi, nil
+	}
+
+	consumers, err := h.getConsumers(vmi)
+	if err != nil {
+		return vmi, err
+	}
+
+	unusedSince := ""
+	if len(consumers) == 0 && harvesterv1.ImageImported.IsTrue(vmi) {
+		unusedSince = vmi.Status.UnusedSince
+		if unusedSince == "" {
+			unusedSince = h.now().UTC().Format(time.RFC3339)
+		}
+	}
+
+	if !reflect.DeepEqual(vmi.Status.Consumers, consumers) || vmi.Status.UnusedSince != unusedSince {
+		toUpdate := vmi.DeepCopy()
+		toUpdate.Status.Consumers = consumers
+		toUpdate.Status.UnusedSince = unusedSince
+		return h.vmiClient.Update(toUpdate)
+	}
+
+	return vmi, h.collectGarbage(vmi)
+}
+
+func (h *vmImageUsageHandler) getConsumers(vmi *harvesterv1.VirtualMachineImage) ([]harvesterv1.VirtualMachineImageConsumer, error) {
+	imageID := ref.Construct(vmi.Namespace, vmi.Name)
+	var consumers []harvesterv1.VirtualMachineImageConsumer
+
+	pvcs, err := h.pvcCache.GetByIndex(indexeres.PVCByImageIDIndex, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PVCs using image %s: %w", imageID, err)
+	}
+	for _, pvc := range pvcs {
+		if pvc.DeletionTimestamp != nil {
+			continue
+		}
+		consumers = append(consumers, harvesterv1.VirtualMachineImageConsumer{
+			Kind:      harvesterv1.VirtualMachineImageConsumerKindPVC,
+			Namespace: pvc.Namespace,
+			Name:      pvc.Name,
+		})
+	}
+
+	templateVersions, err := h.templateVersionCache.GetByIndex(indexeres.VMTemplateVersionByImageIDIndex, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VM template versions using image %s: %w", imageID, err)
+	}
+	for _, templateVersion := range templateVersions {
+		if templateVersion.DeletionTimestamp != nil {
+			continue
+		}
+		consumers = append(consumers, harvesterv1.VirtualMachineImageConsumer{
+			Kind:      harvesterv1.VirtualMachineImageConsumerKindTemplateVersion,
+			Namespace: templateVersion.Namespace,
+			Name:      templateVersion.Name,
+		})
+	}
+
+	sort.Slice(consumers, func(i, j int) bool {
+		if consumers[i].Kind != consumers[j].Kind {
+			return consumers[i].Kind < consumers[j].Kind
+		}
+		if consumers[i].Namespace != consumers[j].Namespace {
+			return consumers[i].Namespace < consumers[j].Namespace
+		}
+		return consumers[i].Name < consumers[j].Name
+	})
+	return consumers, nil
+}
+
+// collectGarbage deletes the image if it's unused for the TTL, or enqueues it when the TTL expires
+func (h *vmImageUsageHandler) collectGarbage(vmi *harvesterv1.VirtualMachineImage) error {
+	if vmi.Status.UnusedSince == "" {
+		return nil
+	}
+
+	lifecycle, err := settings.DecodeVMImageLifecycle(settings.VMImageLifecycleSet.Get())
+	if err != nil {
+		return err
+	}
+	if !lifecycle.GC.Enabled || (lifecycle.GC.DeprecatedOnly && !vmi.Spec.Deprecated) {
+		return nil
+	}
+
+	ttl, err := lifecycle.GC.GetTTL()
+	if err != nil {
+		return fmt.Errorf("invalid ttl of setting %s: %w", settings.VMImageLifecycleSettingName, err)
+	}
+	unusedSince, err := time.Parse(time.RFC3339, vmi.Status.UnusedSince)
+	if err != nil {
+		return fmt.Errorf("invalid unusedSince %s of image %s/%s: %w", vmi.Status.UnusedSince, vmi.Namespace, vmi.Name, err)
+	}
+	if remaining := unusedSince.Add(ttl).Sub(h.now()); remaining > 0 {
+		h.vmiController.EnqueueAfter(vmi.Namespace, vmi.Name, remaining)
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"namespace":   vmi.Namespace,
+		"name":        vmi.Name,
+		"unusedSince": vmi.Status.UnusedSince,
+	}).Info("deleting unused vm image")
+	if err := h.vmiClient.Delete(vmi.Namespace, vmi.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"namespace": vmi.Namespace,
+			"name":      vmi.Name,
+		}).Warn("failed to delete unused vm image")
+		h.vmiController.EnqueueAfter(vmi.Namespace, vmi.Name, gcRetryInterval)
+	}
+	return nil
+}
+
+// ReconcileConsumers enqueues the images used by the changed PVC or VM template version
+func (h *vmImageUsageHandler) ReconcileConsumers(_ string, _ string, obj runtime.Object) ([]relatedresource.Key, error) {
+	var imageIDs []string
+	switch o := obj.(type) {
+	case *corev1.PersistentVolumeClaim:
+		imageIDs, _ = indexeres.PVCByImageID(o)
+	case *harvesterv1.VirtualMachineTemplateVersion:
+		imageIDs, _ = indexeres.VMTemplateVersionByImageID(o)
+	}
+
+	keys := make([]relatedresource.Key, 0, len(imageIDs))
+	for _, imageID := range imageIDs {
+		namespace, name := ref.Parse(imageID)
+		keys = append(keys, relatedresource.Key{Namespace: namespace, Name: name})
+	}
+	return keys, nil
+}
+
+// OnSettingChanged enqueues all images to apply the GC policy of the vm-image-lifecycle setting
+func (h *vmImageUsageHandler) OnSettingChanged(_ string, setting *harvesterv1.Setting) (*harvesterv1.Setting, error) {
+	if setting == nil || setting.DeletionTimestamp != nil || setting.Name != settings.VMImageLifecycleSettingName {
+		return setting, nil
+	}
+
+	vmis, err := h.vmiCache.List(metav1.NamespaceAll, labels.Everything())
+	if err != nil {
+		return setting, err
+	}
+	for _, vmi := range vmis {
+		h.vmiController.Enqueue(vmi.Namespace, vmi.Name)
+	}
+	return setting, nil
+}
@@ -0,0 +1,143 @@
+package image
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	fakegenerated "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newUsageHandler(clientset *fakegenerated.Clientset, now time.Time) *vmImageUsageHandler {
+	vmiClient := fakeclients.VirtualMachineImageClient(clientset.HarvesterhciV1beta1().VirtualMachineImages)
+	return &vmImageUsageHandler{
+		vmiClient:            vmiClient,
+		vmiCache:             fakeclients.VirtualMachineImageCache(clientset.HarvesterhciV1beta1().VirtualMachineImages),
+		vmiController:        vmiClient,
+		pvcCache:             fakeclients.PersistentVolumeClaimCache(clientset.CoreV1().PersistentVolumeClaims),
+		templateVersionCache: fakeclients.VirtualMachineTemplateVersionCache(clientset.HarvesterhciV1beta1().VirtualMachineTemplateVersions),
+		now:                  func() time.Time { return now },
+	}
+}
+
+func newImportedImage(deprecated bool) *harvesterv1.VirtualMachineImage {
+	return &harvesterv1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{Name: testImageName, Namespace: testNamespace},
+		Spec:       harvesterv1.VirtualMachineImageSpec{Deprecated: deprecated},
+		Status: harvesterv1.VirtualMachineImageStatus{
+			Conditions: []harvesterv1.Condition{
+				{Type: harvesterv1.ImageImported, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func TestVMImageUsageHandler_OnChanged_Consumers(t *testing.T) {
+	imageID := testNamespace + "/" + testImageName
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clientset := fakegenerated.NewSimpleClientset(
+		newImportedImage(false),
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "vm-disk-0",
+				Namespace:   "team-a",
+				Annotations: map[string]string{util.AnnotationImageID: imageID},
+			},
+		},
+		&harvesterv1.VirtualMachineTemplateVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "ubuntu-v1", Namespace: testNamespace},
+			Spec:       harvesterv1.VirtualMachineTemplateVersionSpec{ImageID: imageID},
+		},
+	)
+	h := newUsageHandler(clientset, now)
+	images := clientset.HarvesterhciV1beta1().VirtualMachineImages(testNamespace)
+
+	vmi, err := h.OnChanged("", newImportedImage(false))
+	require.NoError(t, err)
+	assert.Equal(t, []harvesterv1.VirtualMachineImageConsumer{
+		{Kind: harvesterv1.VirtualMachineImageConsumerKindPVC, Namespace: "team-a", Name: "vm-disk-0"},
+		{Kind: harvesterv1.VirtualMachineImageConsumerKindTemplateVersion, Namespace: testNamespace, Name: "ubuntu-v1"},
+	}, vmi.Status.Consumers)
+	assert.Empty(t, vmi.Status.UnusedSince)
+
+	// the image is unused since the last consumer is deleted
+	require.NoError(t, clientset.CoreV1().PersistentVolumeClaims("team-a").Delete(context.TODO(), "vm-disk-0", metav1.DeleteOptions{}))
+	require.NoError(t, clientset.HarvesterhciV1beta1().VirtualMachineTemplateVersions(testNamespace).Delete(context.TODO(), "ubuntu-v1", metav1.DeleteOptions{}))
+	vmi, err = h.OnChanged("", vmi)
+	require.NoError(t, err)
+	assert.Nil(t, vmi.Status.Consumers)
+	assert.Equal(t, now.Format(time.RFC3339), vmi.Status.UnusedSince)
+
+	// the image isn't deleted if the GC is disabled
+	h.now = func() time.Time { return now.Add(365 * 24 * time.Hour) }
+	vmi, err = h.OnChanged("", vmi)
+	require.NoError(t, err)
+	assert.Equal(t, now.Format(time.RFC3339), vmi.Status.UnusedSince)
+	_, err = images.Get(context.TODO(), testImageName, metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestVMImageUsageHandler_OnChanged_GC(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	unusedSince := now.Add(-48 * time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name        string
+		lifecycle   string
+		deprecated  bool
+		wantDeleted bool
+	}{
+		{
+			name:      "GC disabled",
+			lifecycle: `{"gc":{"enabled":false,"ttl":"24h"}}`,
+		},
+		{
+			name:        "unused for the TTL",
+			lifecycle:   `{"gc":{"enabled":true,"ttl":"24h"}}`,
+			wantDeleted: true,
+		},
+		{
+			name:      "unused for less than the default TTL",
+			lifecycle: `{"gc":{"enabled":true}}`,
+		},
+		{
+			name:      "not deprecated image with deprecatedOnly",
+			lifecycle: `{"gc":{"enabled":true,"ttl":"24h","deprecatedOnly":true}}`,
+		},
+		{
+			name:        "deprecated image with deprecatedOnly",
+			lifecycle:   `{"gc":{"enabled":true,"ttl":"24h","deprecatedOnly":true}}`,
+			deprecated:  true,
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, settings.VMImageLifecycleSet.Set(tt.lifecycle))
+			t.Cleanup(func() {
+				_ = settings.VMImageLifecycleSet.Set("{}")
+			})
+
+			vmi := newImportedImage(tt.deprecated)
+			vmi.Status.UnusedSince = unusedSince
+			clientset := fakegenerated.NewSimpleClientset(vmi)
+			h := newUsageHandler(clientset, now)
+
+			_, err := h.OnChanged("", vmi)
+			require.NoError(t, err)
+			_, err = clientset.HarvesterhciV1beta1().VirtualMachineImages(testNamespace).Get(context.TODO(), testImageName, metav1.GetOptions{})
+			assert.Equal(t, tt.wantDeleted, apierrors.IsNotFound(err))
+		})
+	}
+}
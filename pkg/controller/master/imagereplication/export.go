@@ -0,0 +1,173 @@
+package imagereplication
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const downloaderCompressType = "qcow2"
+
+// ensureExport returns the URL the source image is exported at in this cluster, which is the download URL of the
+// backing image, or the URL of the VM image downloader of the CDI image. The downloader is created if it doesn't
+// exist, and the URL is empty until it's ready.
+func (h *imageReplicationHandler) ensureExport(r *harvesterv1.ImageReplication, source *harvesterv1.VirtualMachineImage) (string, error) {
+	if util.GetVMIBackend(source) != harvesterv1.VMIBackendCDI {
+		biName, err := util.GetBackingImageName(h.biCache, source)
+		if err != nil {
+			return "", fmt.Errorf("failed to get backing image name for VMImage %s/%s: %w", source.Namespace, source.Name, err)
+		}
+		return fmt.Sprintf("%s/backingimages/%s/download", util.LonghornDefaultManagerURL, biName), nil
+	}
+
+	// the download API expects the downloader of the same name as the image
+	downloader, err := h.downloaderCache.Get(source.Namespace, source.Name)
+	if apierrors.IsNotFound(err) {
+		_, err = h.downloaderClient.Create(&harvesterv1.VirtualMachineImageDownloader{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      source.Name,
+				Namespace: source.Namespace,
+				Annotations: map[string]string{
+					util.AnnotationImageReplicationID: ref.Construct(r.Namespace, r.Name),
+				},
+			},
+			Spec: harvesterv1.VirtualMachineImageDownloaderSpec{
+				ImageName:    source.Name,
+				CompressType: downloaderCompressType,
+			},
+		})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return "", fmt.Errorf("failed to create vm image downloader %s/%s: %w", source.Namespace, source.Name, err)
+		}
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	if downloader.Status.Status != harvesterv1.ImageDownloaderStatusReady {
+		return "", nil
+	}
+	return downloader.Status.DownloadURL, nil
+}
+
+// deleteExport deletes the VM image downloader created by the replication for the image, the downloaders of the
+// downloads in progress are kept
+func (h *imageReplicationHandler) deleteExport(r *harvesterv1.ImageReplication, name string) error {
+	downloader, err := h.downloaderCache.Get(r.Namespace, name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if downloader.Annotations[util.AnnotationImageReplicationID] != ref.Construct(r.Namespace, r.Name) {
+		return nil
+	}
+
+	err = h.downloaderClient.Delete(downloader.Namespace, downloader.Name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete vm image downloader %s/%s: %w", downloader.Namespace, downloader.Name, err)
+	}
+	return nil
+}
+
+// getSourceChecksum returns the SHA-512 checksum Longhorn calculated for the backing image of the source image,
+// which is the file the backing image is exported as. The CDI image is converted to qcow2 by the export, so its
+// checksum is always calculated from the export.
+func (h *imageReplicationHandler) getSourceChecksum(source *harvesterv1.VirtualMachineImage) string {
+	if util.GetVMIBackend(source) == harvesterv1.VMIBackendCDI {
+		return ""
+	}
+	bi, err := util.GetBackingImage(h.biCache, source)
+	if err != nil {
+		return ""
+	}
+	return bi.Status.Checksum
+}
+
+// checksumJob calculates the SHA-512 checksum of the export in the background
+type checksumJob struct {
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	done     bool
+	checksum string
+	err      error
+}
+
+func (j *checksumJob) finish(checksum string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.done = true
+	j.checksum = checksum
+	j.err = err
+}
+
+func (j *checksumJob) result() (string, bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.checksum, j.done, j.err
+}
+
+// startChecksum starts the checksum job of the export if it isn't running, the replication is enqueued when the
+// job is done. The job lost by a restart of harvester is started again by the next reconciliation of the exporting image.
+func (h *imageReplicationHandler) startChecksum(r *harvesterv1.ImageReplication, source *harvesterv1.VirtualMachineImage, exportURL string) *checksumJob {
+	key := replicationSource(r, source.UID)
+	if job, ok := h.checksums.Load(key); ok {
+		return job.(*checksumJob)
+	}
+
+	ctx, cancel := context.WithCancel(h.ctx)
+	job := &checksumJob{cancel: cancel}
+	if existing, loaded := h.checksums.LoadOrStore(key, job); loaded {
+		cancel()
+		return existing.(*checksumJob)
+	}
+
+	namespace, name := r.Namespace, r.Name
+	go func() {
+		job.finish(h.calculateChecksum(ctx, exportURL))
+		if ctx.Err() == nil {
+			h.replicationController.Enqueue(namespace, name)
+		}
+	}()
+	return job
+}
+
+func (h *imageReplicationHandler) stopChecksum(r *harvesterv1.ImageReplication, sourceUID types.UID) {
+	if job, ok := h.checksums.LoadAndDelete(replicationSource(r, sourceUID)); ok {
+		job.(*checksumJob).cancel()
+	}
+}
+
+func (h *imageReplicationHandler) calculateChecksum(ctx context.Context, exportURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, exportURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("got %d status code from %s", resp.StatusCode, exportURL)
+	}
+
+	hash := sha512.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to read the export %s: %w", exportURL, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,605 @@
+package imagereplication
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/cronjob"
+)
+
+const (
+	imageReplicationPrefix = "imgrepl"
+
+	// requeueInterval is how often the replications waiting for the remote imports are checked
+	requeueInterval = 30 * time.Second
+)
+
+func cronJobName(r *harvesterv1.ImageReplication) string {
+	return fmt.Sprintf("%s-%s", imageReplicationPrefix, r.UID)
+}
+
+// OnChanged keeps the CronJob in sync with the schedule, syncs the selected images when the replication isn't
+// scheduled or its spec is changed, and advances the replications of the images
+func (h *imageReplicationHandler) OnChanged(_ string, r *harvesterv1.ImageReplication) (*harvesterv1.ImageReplication, error) {
+	if r == nil || r.DeletionTimestamp != nil {
+		return r, nil
+	}
+
+	if err := h.reconcileCronJob(r); err != nil {
+		return r, err
+	}
+	if r.Spec.Suspend {
+		return r, nil
+	}
+
+	clients, err := h.getTargetClients(r)
+	if err != nil {
+		return r, err
+	}
+
+	status := r.Status.DeepCopy()
+	if r.Spec.Cron == "" || status.ObservedGeneration != r.Generation {
+		resync := status.ObservedGeneration != r.Generation
+		changed, err := h.syncImages(r, clients, status, resync)
+		if err != nil {
+			return r, err
+		}
+		status.ObservedGeneration = r.Generation
+		if changed || resync {
+			status.LastSyncTime = ptr.To(metav1.Now())
+		}
+	}
+
+	requeue, err := h.replicate(r, clients, status)
+	if err != nil {
+		return r, err
+	}
+	if requeue {
+		h.replicationController.EnqueueAfter(r.Namespace, r.Name, requeueInterval)
+	}
+
+	if equality.Semantic.DeepEqual(r.Status, *status) {
+		return r, nil
+	}
+	rCpy := r.DeepCopy()
+	rCpy.Status = *status
+	return h.replicationClient.UpdateStatus(rCpy)
+}
+
+func (h *imageReplicationHandler) reconcileCronJob(r *harvesterv1.ImageReplication) error {
+	cronJob, err := h.cronJobCache.Get(cronjob.Namespace, cronJobName(r))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if r.Spec.Cron == "" {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return h.deleteCronJob(r)
+	}
+
+	if apierrors.IsNotFound(err) {
+		cronJob, err = cronjob.NewTrigger(h.clientset, h.namespace, cronJobName(r), r.Spec.Cron, map[string]string{
+			util.AnnotationImageReplicationID: ref.Construct(r.Namespace, r.Name),
+		})
+		if err != nil {
+			return err
+		}
+		cronJob.Spec.Suspend = ptr.To(r.Spec.Suspend)
+		_, err = h.cronJobsClient.Create(cronJob)
+		return err
+	}
+
+	if cronJob.Spec.Schedule == r.Spec.Cron && ptr.Deref(cronJob.Spec.Suspend, false) == r.Spec.Suspend {
+		return nil
+	}
+
+	cronJobCpy := cronJob.DeepCopy()
+	cronJobCpy.Spec.Schedule = r.Spec.Cron
+	cronJobCpy.Spec.Suspend = ptr.To(r.Spec.Suspend)
+	_, err = h.cronJobsClient.Update(cronJobCpy)
+	return err
+}
+
+func (h *imageReplicationHandler) deleteCronJob(r *harvesterv1.ImageReplication) error {
+	propagation := metav1.DeletePropagationForeground
+	err := h.cronJobsClient.Delete(cronjob.Namespace, cronJobName(r), &metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// OnRemove deletes the CronJob and the exports of the replication, the replicated images are deleted as well
+// if the deletion is mirrored
+func (h *imageReplicationHandler) OnRemove(_ string, r *harvesterv1.ImageReplication) (*harvesterv1.ImageReplication, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	if err := h.deleteCronJob(r); err != nil {
+		return r, err
+	}
+
+	var clients *targetClients
+	if r.Spec.MirrorDeletion {
+		var err error
+		if clients, err = h.getTargetClients(r); err != nil {
+			// the remote cluster may be gone, the replicated images are left there
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"namespace": r.Namespace,
+				"name":      r.Name,
+			}).Warn("Failed to get the target clients, the replicated images are kept")
+		}
+	}
+
+	for _, entry := range r.Status.Images {
+		h.stopChecksum(r, entry.SourceUID)
+		if err := h.deleteExport(r, entry.Name); err != nil {
+			return r, err
+		}
+		if clients == nil {
+			continue
+		}
+		if err := h.deleteReplicatedImage(r, clients, entry.Name); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"namespace": r.Namespace,
+				"name":      r.Name,
+				"image":     entry.Name,
+			}).Warn("Failed to delete the replicated image")
+		}
+	}
+
+	if clients != nil && clients.remote && r.Spec.Target.DownloadSecretName != "" {
+		err := clients.kube.CoreV1().Secrets(targetNamespace(r)).Delete(h.ctx, downloadSecretName(r), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"namespace": r.Namespace,
+				"name":      r.Name,
+			}).Warn("Failed to delete the download secret in the remote cluster")
+		}
+	}
+	return r, nil
+}
+
+// OnCronjobChanged syncs the selected images once per schedule time, the replicated images missing in the target
+// are replicated again and the failed replications are retried
+func (h *imageReplicationHandler) OnCronjobChanged(_ string, cronJob *batchv1.CronJob) (*batchv1.CronJob, error) {
+	if cronJob == nil || cronJob.DeletionTimestamp != nil {
+		return cronJob, nil
+	}
+
+	r := h.resolveImageReplicationRef(cronJob)
+	if r == nil || r.DeletionTimestamp != nil || r.Spec.Suspend {
+		return cronJob, nil
+	}
+
+	scheduleTime := cronjob.LastScheduleTime(cronJob)
+	if scheduleTime == nil || (r.Status.LastScheduleTime != nil && r.Status.LastScheduleTime.Equal(scheduleTime)) {
+		return cronJob, nil
+	}
+
+	clients, err := h.getTargetClients(r)
+	if err != nil {
+		return cronJob, err
+	}
+
+	status := r.Status.DeepCopy()
+	if _, err := h.syncImages(r, clients, status, true); err != nil {
+		return cronJob, err
+	}
+	status.ObservedGeneration = r.Generation
+	status.LastScheduleTime = scheduleTime.DeepCopy()
+	status.LastSyncTime = ptr.To(metav1.Now())
+
+	rCpy := r.DeepCopy()
+	rCpy.Status = *status
+	_, err = h.replicationClient.UpdateStatus(rCpy)
+	return cronJob, err
+}
+
+func (h *imageReplicationHandler) resolveImageReplicationRef(obj metav1.Object) *harvesterv1.ImageReplication {
+	id := obj.GetAnnotations()[util.AnnotationImageReplicationID]
+	if id == "" {
+		return nil
+	}
+
+	namespace, name := ref.Parse(id)
+	r, err := h.replicationCache.Get(namespace, name)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+// ReconcileImageReplications enqueues the replications of the changed image or downloader, which are the one
+// in its annotation and the ones in its namespace
+func (h *imageReplicationHandler) ReconcileImageReplications(_ string, namespace string, obj runtime.Object) ([]relatedresource.Key, error) {
+	var keys []relatedresource.Key
+	// the object is nil when it's deleted
+	if meta, ok := obj.(metav1.Object); ok {
+		if r := h.resolveImageReplicationRef(meta); r != nil {
+			keys = append(keys, relatedresource.Key{Namespace: r.Namespace, Name: r.Name})
+		}
+	}
+	replications, err := h.replicationCache.List(namespace, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range replications {
+		keys = append(keys, relatedresource.Key{Namespace: r.Namespace, Name: r.Name})
+	}
+	return keys, nil
+}
+
+// getSourceImages returns the selected images of the replication by name, the images being deleted are skipped
+func (h *imageReplicationHandler) getSourceImages(r *harvesterv1.ImageReplication) (map[string]*harvesterv1.VirtualMachineImage, error) {
+	sources := map[string]*harvesterv1.VirtualMachineImage{}
+	for _, name := range r.Spec.Names {
+		if name == "" {
+			continue
+		}
+		vmi, err := h.vmiCache.Get(r.Namespace, name)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if vmi.DeletionTimestamp == nil {
+			sources[vmi.Name] = vmi
+		}
+	}
+
+	if r.Spec.Selector != "" {
+		selector, err := labels.Parse(r.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", r.Spec.Selector, err)
+		}
+		vmis, err := h.vmiCache.List(r.Namespace, selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, vmi := range vmis {
+			if vmi.DeletionTimestamp == nil {
+				sources[vmi.Name] = vmi
+			}
+		}
+	}
+	return sources, nil
+}
+
+// syncImages syncs the images of the status with the selected images. The new and recreated images are replicated,
+// and the replications of the images not selected anymore are dropped, whose replicated images are deleted if the
+// deletion is mirrored. On resync, the failed replications are retried and the replicated images missing in the
+// target are replicated again. It returns whether the selected images are changed.
+func (h *imageReplicationHandler) syncImages(r *harvesterv1.ImageReplication, clients *targetClients, status *harvesterv1.ImageReplicationStatus, resync bool) (bool, error) {
+	sources, err := h.getSourceImages(r)
+	if err != nil {
+		return false, err
+	}
+
+	existing := map[string]harvesterv1.ReplicatedImage{}
+	for _, entry := range status.Images {
+		existing[entry.Name] = entry
+	}
+
+	changed := false
+	images := make([]harvesterv1.ReplicatedImage, 0, len(sources))
+	for name, source := range sources {
+		entry, ok := existing[name]
+		delete(existing, name)
+		if !ok || entry.SourceUID != source.UID {
+			if ok {
+				h.stopChecksum(r, entry.SourceUID)
+			}
+			changed = true
+			images = append(images, newReplicatedImage(source))
+			continue
+		}
+
+		if resync {
+			reset, err := h.needsReplication(r, clients, entry)
+			if err != nil {
+				return false, err
+			}
+			if reset {
+				entry = newReplicatedImage(source)
+			}
+		}
+		images = append(images, entry)
+	}
+
+	for _, entry := range existing {
+		changed = true
+		h.stopChecksum(r, entry.SourceUID)
+		if err := h.deleteExport(r, entry.Name); err != nil {
+			return false, err
+		}
+		if r.Spec.MirrorDeletion {
+			if err := h.deleteReplicatedImage(r, clients, entry.Name); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Name < images[j].Name
+	})
+	status.Images = images
+	return changed, nil
+}
+
+func newReplicatedImage(source *harvesterv1.VirtualMachineImage) harvesterv1.ReplicatedImage {
+	return harvesterv1.ReplicatedImage{
+		Name:      source.Name,
+		SourceUID: source.UID,
+		Phase:     harvesterv1.ImageReplicationPhaseExporting,
+	}
+}
+
+// needsReplication returns whether the image needs to be replicated again on resync, which is when the replication
+// failed, or the replicated image is missing or isn't replicated from the source anymore. The failed replicated
+// image is deleted so that it can be imported again.
+func (h *imageReplicationHandler) needsReplication(r *harvesterv1.ImageReplication, clients *targetClients, entry harvesterv1.ReplicatedImage) (bool, error) {
+	switch entry.Phase {
+	case harvesterv1.ImageReplicationPhaseFailed:
+		return true, h.deleteReplicatedImage(r, clients, entry.Name)
+	case harvesterv1.ImageReplicationPhaseReplicated:
+		target, err := clients.getImage(h.ctx, targetNamespace(r), entry.Name)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+		return target.Annotations[util.AnnotationImageReplicationSource] != replicationSource(r, entry.SourceUID), nil
+	default:
+		return false, nil
+	}
+}
+
+// replicate advances the replications of the images, it returns whether the replication has to be checked again
+// later since the progress of the replicated images in the remote cluster isn't watched
+func (h *imageReplicationHandler) replicate(r *harvesterv1.ImageReplication, clients *targetClients, status *harvesterv1.ImageReplicationStatus) (bool, error) {
+	requeue := false
+	for i := range status.Images {
+		entry := &status.Images[i]
+		if entry.Phase != harvesterv1.ImageReplicationPhaseExporting && entry.Phase != harvesterv1.ImageReplicationPhaseImporting {
+			continue
+		}
+
+		source, err := h.vmiCache.Get(r.Namespace, entry.Name)
+		if apierrors.IsNotFound(err) {
+			// it's dropped on the next sync
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if source.UID != entry.SourceUID {
+			continue
+		}
+
+		if entry.Phase == harvesterv1.ImageReplicationPhaseExporting {
+			if err := h.export(r, source, entry); err != nil {
+				return false, err
+			}
+		}
+		if entry.Phase == harvesterv1.ImageReplicationPhaseImporting {
+			waiting, err := h.importImage(r, clients, source, entry)
+			if err != nil {
+				return false, err
+			}
+			requeue = requeue || waiting
+		}
+	}
+	return requeue, nil
+}
+
+// export exports the source image and takes the checksum of the export, the image is imported with the checksum then.
+// The checksum of the backing image is used when Longhorn has calculated it, otherwise the export is read to calculate it.
+func (h *imageReplicationHandler) export(r *harvesterv1.ImageReplication, source *harvesterv1.VirtualMachineImage, entry *harvesterv1.ReplicatedImage) error {
+	if !harvesterv1.ImageImported.IsTrue(source) {
+		entry.Message = "waiting for the source image to be imported"
+		return nil
+	}
+	if source.Spec.SecurityParameters != nil && source.Spec.SecurityParameters.CryptoOperation == harvesterv1.VirtualMachineImageCryptoOperationTypeEncrypt {
+		failReplicatedImage(entry, "encrypted image can't be replicated")
+		return nil
+	}
+
+	exportURL, err := h.ensureExport(r, source)
+	if err != nil {
+		return err
+	}
+	if exportURL == "" {
+		entry.Message = "waiting for the source image to be exported"
+		return nil
+	}
+
+	if checksum := h.getSourceChecksum(source); checksum != "" {
+		entry.Phase = harvesterv1.ImageReplicationPhaseImporting
+		entry.Checksum = checksum
+		entry.Message = ""
+		return nil
+	}
+
+	job := h.startChecksum(r, source, exportURL)
+	checksum, done, err := job.result()
+	if !done {
+		entry.Message = "calculating the checksum of the export"
+		return nil
+	}
+	h.stopChecksum(r, source.UID)
+	if err != nil {
+		failReplicatedImage(entry, fmt.Sprintf("failed to calculate the checksum of the export: %v", err))
+		return nil
+	}
+
+	entry.Phase = harvesterv1.ImageReplicationPhaseImporting
+	entry.Checksum = checksum
+	entry.Message = ""
+	return nil
+}
+
+// importImage creates the replicated image downloading from the export and checks its progress, it returns whether
+// the import is still in progress
+func (h *imageReplicationHandler) importImage(r *harvesterv1.ImageReplication, clients *targetClients, source *harvesterv1.VirtualMachineImage, entry *harvesterv1.ReplicatedImage) (bool, error) {
+	namespace := targetNamespace(r)
+	target, err := clients.getImage(h.ctx, namespace, entry.Name)
+	if apierrors.IsNotFound(err) {
+		exportURL, err := h.ensureExport(r, source)
+		if err != nil {
+			return false, err
+		}
+		if exportURL == "" {
+			entry.Message = "waiting for the source image to be exported"
+			return true, nil
+		}
+
+		if err := h.createReplicatedImage(r, clients, source, entry, exportURL); err != nil {
+			entry.Message = fmt.Sprintf("failed to create the replicated image: %v", err)
+		} else {
+			entry.Message = ""
+		}
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	switch {
+	case target.Annotations[util.AnnotationImageReplicationSource] == replicationSource(r, entry.SourceUID):
+	case isReplicatedBy(r, target):
+		// it's replicated from the deleted source image of the same name
+		if err := clients.deleteImage(h.ctx, namespace, entry.Name); err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		return true, nil
+	default:
+		failReplicatedImage(entry, fmt.Sprintf("image %s/%s exists and isn't replicated from the source image", namespace, entry.Name))
+		return false, nil
+	}
+
+	switch {
+	case harvesterv1.ImageImported.IsTrue(target):
+		entry.Phase = harvesterv1.ImageReplicationPhaseReplicated
+		entry.Message = ""
+		entry.ReplicatedTime = ptr.To(metav1.Now())
+	case harvesterv1.ImageRetryLimitExceeded.IsTrue(target):
+		failReplicatedImage(entry, harvesterv1.ImageImported.GetMessage(target))
+	default:
+		if clients.remote {
+			// the download API deletes the export of the CDI image once it's downloaded, it's exported again in
+			// case the remote cluster retries the download
+			if _, err := h.ensureExport(r, source); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return false, h.deleteExport(r, entry.Name)
+}
+
+func failReplicatedImage(entry *harvesterv1.ReplicatedImage, message string) {
+	entry.Phase = harvesterv1.ImageReplicationPhaseFailed
+	entry.Message = message
+}
+
+func (h *imageReplicationHandler) createReplicatedImage(r *harvesterv1.ImageReplication, clients *targetClients, source *harvesterv1.VirtualMachineImage, entry *harvesterv1.ReplicatedImage, exportURL string) error {
+	namespace := targetNamespace(r)
+	target := &harvesterv1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: namespace,
+			Labels:    source.Labels,
+			Annotations: map[string]string{
+				util.AnnotationImageReplicationSource: replicationSource(r, entry.SourceUID),
+			},
+		},
+		Spec: harvesterv1.VirtualMachineImageSpec{
+			Backend:     r.Spec.Target.Backend,
+			DisplayName: source.Spec.DisplayName,
+			Description: source.Spec.Description,
+			SourceType:  harvesterv1.VirtualMachineImageSourceTypeDownload,
+			URL:         exportURL,
+			Checksum:    entry.Checksum,
+			Retry:       3,
+		},
+	}
+	if target.Spec.Backend == "" {
+		target.Spec.Backend = util.GetVMIBackend(source)
+	}
+	if r.Spec.Target.StorageClassName != "" {
+		target.Annotations[util.AnnotationStorageClassName] = r.Spec.Target.StorageClassName
+	}
+
+	if clients.remote {
+		// the remote cluster downloads the image through the download API of this cluster
+		target.Spec.URL = fmt.Sprintf("%s/v1/harvester/harvesterhci.io.virtualmachineimages/%s/%s/download",
+			strings.TrimSuffix(r.Spec.Target.ExportURL, "/"), source.Namespace, source.Name)
+		if r.Spec.Target.DownloadSecretName != "" {
+			if err := h.syncDownloadSecret(r, clients); err != nil {
+				return err
+			}
+			target.Spec.DownloadSecretName = downloadSecretName(r)
+		}
+	} else {
+		// the local replicated image enqueues the replication when it's changed
+		target.Annotations[util.AnnotationImageReplicationID] = ref.Construct(r.Namespace, r.Name)
+	}
+
+	return clients.createImage(h.ctx, target)
+}
+
+// deleteReplicatedImage deletes the replicated image in the target, the image not replicated by the replication
+// is kept
+func (h *imageReplicationHandler) deleteReplicatedImage(r *harvesterv1.ImageReplication, clients *targetClients, name string) error {
+	target, err := clients.getImage(h.ctx, targetNamespace(r), name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !isReplicatedBy(r, target) {
+		return nil
+	}
+	if err := clients.deleteImage(h.ctx, target.Namespace, target.Name); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func targetNamespace(r *harvesterv1.ImageReplication) string {
+	if r.Spec.Target.Namespace == "" {
+		return r.Namespace
+	}
+	return r.Spec.Target.Namespace
+}
+
+// replicationSource is the annotation of the replicated image recording the replication and the source image
+func replicationSource(r *harvesterv1.ImageReplication, sourceUID types.UID) string {
+	return fmt.Sprintf("%s/%s", r.UID, sourceUID)
+}
+
+func isReplicatedBy(r *harvesterv1.ImageReplication, target *harvesterv1.VirtualMachineImage) bool {
+	return strings.HasPrefix(target.Annotations[util.AnnotationImageReplicationSource], string(r.UID)+"/")
+}
+
+func downloadSecretName(r *harvesterv1.ImageReplication) string {
+	return fmt.Sprintf("%s-%s", imageReplicationPrefix, r.UID)
+}
@@ -0,0 +1,251 @@
+package imagereplication
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	fakegenerated "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newImage(namespace, name string, uid types.UID, labels map[string]string) *harvesterv1.VirtualMachineImage {
+	return &harvesterv1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: uid, Labels: labels},
+		Spec: harvesterv1.VirtualMachineImageSpec{
+			Backend:     harvesterv1.VMIBackendBackingImage,
+			DisplayName: name,
+			SourceType:  harvesterv1.VirtualMachineImageSourceTypeUpload,
+		},
+		Status: harvesterv1.VirtualMachineImageStatus{
+			Conditions: []harvesterv1.Condition{
+				{Type: harvesterv1.ImageImported, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func newHandler(clientset *fakegenerated.Clientset) *imageReplicationHandler {
+	return &imageReplicationHandler{
+		ctx:               context.Background(),
+		replicationClient: fakeclients.ImageReplicationClient(clientset.HarvesterhciV1beta1().ImageReplications),
+		replicationCache:  fakeclients.ImageReplicationCache(clientset.HarvesterhciV1beta1().ImageReplications),
+		vmiCache:          fakeclients.VirtualMachineImageCache(clientset.HarvesterhciV1beta1().VirtualMachineImages),
+		biCache:           fakeclients.BackingImageCache(clientset.LonghornV1beta2().BackingImages),
+		downloaderClient:  fakeclients.VirtualMachineImageDownloaderClient(clientset.HarvesterhciV1beta1().VirtualMachineImageDownloaders),
+		downloaderCache:   fakeclients.VirtualMachineImageDownloaderCache(clientset.HarvesterhciV1beta1().VirtualMachineImageDownloaders),
+		local:             &targetClients{harvester: clientset, kube: k8sfake.NewSimpleClientset()},
+		remotes:           &sync.Map{},
+		checksums:         &sync.Map{},
+	}
+}
+
+// finishChecksum records the checksum of the export as if the checksum job is done
+func finishChecksum(h *imageReplicationHandler, r *harvesterv1.ImageReplication, sourceUID types.UID, checksum string) {
+	job := &checksumJob{cancel: func() {}}
+	job.finish(checksum, nil)
+	h.checksums.Store(replicationSource(r, sourceUID), job)
+}
+
+func TestHandler_replicate(t *testing.T) {
+	r := &harvesterv1.ImageReplication{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "golden-images", UID: "replication-uid", Generation: 1},
+		Spec: harvesterv1.ImageReplicationSpec{
+			Selector:       "os=ubuntu",
+			Target:         harvesterv1.ImageReplicationTarget{Namespace: "team-a", StorageClassName: "fast"},
+			MirrorDeletion: true,
+		},
+	}
+	clientset := fakegenerated.NewSimpleClientset(
+		r,
+		newImage("default", "ubuntu", "ubuntu-uid", map[string]string{"os": "ubuntu"}),
+		newImage("default", "rocky", "rocky-uid", map[string]string{"os": "rocky"}),
+		newImage("team-a", "existing", "existing-uid", map[string]string{"os": "ubuntu"}),
+	)
+	h := newHandler(clientset)
+	images := clientset.HarvesterhciV1beta1().VirtualMachineImages
+
+	status := r.Status.DeepCopy()
+	changed, err := h.syncImages(r, h.local, status, true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	require.Len(t, status.Images, 1)
+	assert.Equal(t, harvesterv1.ReplicatedImage{Name: "ubuntu", SourceUID: "ubuntu-uid", Phase: harvesterv1.ImageReplicationPhaseExporting}, status.Images[0])
+
+	// the image is imported with the checksum of the export
+	finishChecksum(h, r, "ubuntu-uid", "ubuntu-checksum")
+	requeue, err := h.replicate(r, h.local, status)
+	require.NoError(t, err)
+	assert.True(t, requeue)
+	assert.Equal(t, harvesterv1.ImageReplicationPhaseImporting, status.Images[0].Phase)
+	assert.Equal(t, "ubuntu-checksum", status.Images[0].Checksum)
+	_, ok := h.checksums.Load(replicationSource(r, "ubuntu-uid"))
+	assert.False(t, ok)
+
+	target, err := images("team-a").Get(context.TODO(), "ubuntu", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, harvesterv1.VirtualMachineImageSourceTypeDownload, target.Spec.SourceType)
+	assert.Equal(t, harvesterv1.VMIBackendBackingImage, target.Spec.Backend)
+	assert.Equal(t, "ubuntu-checksum", target.Spec.Checksum)
+	assert.Equal(t, util.LonghornDefaultManagerURL+"/backingimages/vmi-ubuntu-uid/download", target.Spec.URL)
+	assert.Equal(t, "replication-uid/ubuntu-uid", target.Annotations[util.AnnotationImageReplicationSource])
+	assert.Equal(t, "default/golden-images", target.Annotations[util.AnnotationImageReplicationID])
+	assert.Equal(t, "fast", target.Annotations[util.AnnotationStorageClassName])
+	assert.Equal(t, "ubuntu", target.Labels["os"])
+
+	// the replication is done once the replicated image is imported
+	target.Status.Conditions = []harvesterv1.Condition{{Type: harvesterv1.ImageImported, Status: corev1.ConditionTrue}}
+	_, err = images("team-a").Update(context.TODO(), target, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	requeue, err = h.replicate(r, h.local, status)
+	require.NoError(t, err)
+	assert.False(t, requeue)
+	assert.Equal(t, harvesterv1.ImageReplicationPhaseReplicated, status.Images[0].Phase)
+	assert.NotNil(t, status.Images[0].ReplicatedTime)
+
+	// the replicated image deleted in the target is replicated again on resync only
+	require.NoError(t, images("team-a").Delete(context.TODO(), "ubuntu", metav1.DeleteOptions{}))
+	_, err = h.syncImages(r, h.local, status, false)
+	require.NoError(t, err)
+	assert.Equal(t, harvesterv1.ImageReplicationPhaseReplicated, status.Images[0].Phase)
+	_, err = h.syncImages(r, h.local, status, true)
+	require.NoError(t, err)
+	assert.Equal(t, harvesterv1.ImageReplicationPhaseExporting, status.Images[0].Phase)
+
+	// the image of the same name not replicated by the replication isn't touched
+	existing := newImage("default", "existing", "existing-source-uid", map[string]string{"os": "ubuntu"})
+	_, err = images("default").Create(context.TODO(), existing, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = h.syncImages(r, h.local, status, false)
+	require.NoError(t, err)
+	require.Len(t, status.Images, 2)
+	finishChecksum(h, r, "existing-source-uid", "existing-checksum")
+	finishChecksum(h, r, "ubuntu-uid", "ubuntu-checksum")
+	_, err = h.replicate(r, h.local, status)
+	require.NoError(t, err)
+	assert.Equal(t, "existing", status.Images[0].Name)
+	assert.Equal(t, harvesterv1.ImageReplicationPhaseFailed, status.Images[0].Phase)
+	assert.Equal(t, harvesterv1.ImageReplicationPhaseImporting, status.Images[1].Phase)
+
+	// the deletion of the source image is mirrored, the image not replicated by the replication is kept
+	require.NoError(t, images("default").Delete(context.TODO(), "ubuntu", metav1.DeleteOptions{}))
+	require.NoError(t, images("default").Delete(context.TODO(), "existing", metav1.DeleteOptions{}))
+	changed, err = h.syncImages(r, h.local, status, false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Empty(t, status.Images)
+	_, err = images("team-a").Get(context.TODO(), "ubuntu", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = images("team-a").Get(context.TODO(), "existing", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestHandler_recreatedSource(t *testing.T) {
+	r := &harvesterv1.ImageReplication{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "golden-images", UID: "replication-uid"},
+		Spec: harvesterv1.ImageReplicationSpec{
+			Names:  []string{"ubuntu"},
+			Target: harvesterv1.ImageReplicationTarget{Namespace: "team-a"},
+		},
+		Status: harvesterv1.ImageReplicationStatus{
+			Images: []harvesterv1.ReplicatedImage{
+				{Name: "ubuntu", SourceUID: "old-uid", Phase: harvesterv1.ImageReplicationPhaseReplicated},
+			},
+		},
+	}
+	replicated := newImage("team-a", "ubuntu", "replicated-uid", nil)
+	replicated.Annotations = map[string]string{util.AnnotationImageReplicationSource: "replication-uid/old-uid"}
+	clientset := fakegenerated.NewSimpleClientset(r, newImage("default", "ubuntu", "new-uid", nil), replicated)
+	h := newHandler(clientset)
+
+	// the image replicated from the deleted source is replaced
+	status := r.Status.DeepCopy()
+	changed, err := h.syncImages(r, h.local, status, false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	finishChecksum(h, r, "new-uid", "new-checksum")
+	requeue, err := h.replicate(r, h.local, status)
+	require.NoError(t, err)
+	assert.True(t, requeue)
+	_, err = clientset.HarvesterhciV1beta1().VirtualMachineImages("team-a").Get(context.TODO(), "ubuntu", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	_, err = h.replicate(r, h.local, status)
+	require.NoError(t, err)
+	target, err := clientset.HarvesterhciV1beta1().VirtualMachineImages("team-a").Get(context.TODO(), "ubuntu", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "replication-uid/new-uid", target.Annotations[util.AnnotationImageReplicationSource])
+	assert.Equal(t, "new-checksum", target.Spec.Checksum)
+}
+
+func TestHandler_export_backingImageChecksum(t *testing.T) {
+	tests := []struct {
+		name             string
+		backend          harvesterv1.VMIBackend
+		backingImage     *lhv1beta2.BackingImage
+		expectedPhase    harvesterv1.ImageReplicationPhase
+		expectedChecksum string
+		expectJob        bool
+	}{
+		{
+			name:    "checksum of the backing image",
+			backend: harvesterv1.VMIBackendBackingImage,
+			backingImage: &lhv1beta2.BackingImage{
+				ObjectMeta: metav1.ObjectMeta{Namespace: util.LonghornSystemNamespaceName, Name: "vmi-ubuntu-uid"},
+				Status:     lhv1beta2.BackingImageStatus{Checksum: "backing-image-checksum"},
+			},
+			expectedPhase:    harvesterv1.ImageReplicationPhaseImporting,
+			expectedChecksum: "backing-image-checksum",
+		},
+		{
+			name:    "backing image without checksum",
+			backend: harvesterv1.VMIBackendBackingImage,
+			backingImage: &lhv1beta2.BackingImage{
+				ObjectMeta: metav1.ObjectMeta{Namespace: util.LonghornSystemNamespaceName, Name: "vmi-ubuntu-uid"},
+			},
+			expectedPhase: harvesterv1.ImageReplicationPhaseExporting,
+			expectJob:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &harvesterv1.ImageReplication{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "golden-images", UID: "replication-uid"},
+				Spec: harvesterv1.ImageReplicationSpec{
+					Names:  []string{"ubuntu"},
+					Target: harvesterv1.ImageReplicationTarget{Namespace: "team-a"},
+				},
+			}
+			source := newImage("default", "ubuntu", "ubuntu-uid", nil)
+			source.Spec.Backend = tc.backend
+			clientset := fakegenerated.NewSimpleClientset(r, source, tc.backingImage)
+			h := newHandler(clientset)
+			// the checksum job reading the export is cancelled right away
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			h.ctx = ctx
+			h.httpClient = http.DefaultClient
+
+			entry := &harvesterv1.ReplicatedImage{Name: "ubuntu", SourceUID: "ubuntu-uid", Phase: harvesterv1.ImageReplicationPhaseExporting}
+			require.NoError(t, h.export(r, source, entry))
+			assert.Equal(t, tc.expectedPhase, entry.Phase)
+			assert.Equal(t, tc.expectedChecksum, entry.Checksum)
+			_, ok := h.checksums.Load(replicationSource(r, "ubuntu-uid"))
+			assert.Equal(t, tc.expectJob, ok)
+			h.stopChecksum(r, "ubuntu-uid")
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package imagereplication
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/harvester/harvester/pkg/config"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned"
+	ctlharvbatchv1 "github.com/harvester/harvester/pkg/generated/controllers/batch/v1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctllhv1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta2"
+)
+
+const (
+	imageReplicationControllerName = "image-replication-controller"
+	cronJobControllerName          = "image-replication-cron-job-controller"
+	imageWatcherName               = "image-replication-image-watcher"
+	downloaderWatcherName          = "image-replication-downloader-watcher"
+)
+
+type imageReplicationHandler struct {
+	ctx                   context.Context
+	replicationClient     ctlharvesterv1.ImageReplicationClient
+	replicationCache      ctlharvesterv1.ImageReplicationCache
+	replicationController ctlharvesterv1.ImageReplicationController
+	vmiCache              ctlharvesterv1.VirtualMachineImageCache
+	biCache               ctllhv1.BackingImageCache
+	downloaderClient      ctlharvesterv1.VirtualMachineImageDownloaderClient
+	downloaderCache       ctlharvesterv1.VirtualMachineImageDownloaderCache
+	secretCache           ctlcorev1.SecretCache
+	cronJobsClient        ctlharvbatchv1.CronJobClient
+	cronJobCache          ctlharvbatchv1.CronJobCache
+	clientset             kubernetes.Interface
+	namespace             string
+
+	// local are the clients of this cluster, the remote clients are cached by the kubeconfig secrets
+	local            *targetClients
+	newRemoteClients func(kubeconfig []byte) (*targetClients, error)
+	remotes          *sync.Map
+
+	httpClient *http.Client
+	// checksums are the running checksum jobs of the exports keyed by the replication and source image UIDs
+	checksums *sync.Map
+}
+
+func Register(ctx context.Context, management *config.Management, options config.Options) error {
+	replications := management.HarvesterFactory.Harvesterhci().V1beta1().ImageReplication()
+	images := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage()
+	downloaders := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImageDownloader()
+	backingImages := management.LonghornFactory.Longhorn().V1beta2().BackingImage()
+	secrets := management.CoreFactory.Core().V1().Secret()
+	cronJobs := management.HarvesterBatchFactory.Batch().V1().CronJob()
+
+	harvesterClient, err := versioned.NewForConfig(management.RestConfig)
+	if err != nil {
+		return err
+	}
+
+	handler := &imageReplicationHandler{
+		ctx:                   ctx,
+		replicationClient:     replications,
+		replicationCache:      replications.Cache(),
+		replicationController: replications,
+		vmiCache:              images.Cache(),
+		biCache:               backingImages.Cache(),
+		downloaderClient:      downloaders,
+		downloaderCache:       downloaders.Cache(),
+		secretCache:           secrets.Cache(),
+		cronJobsClient:        cronJobs,
+		cronJobCache:          cronJobs.Cache(),
+		clientset:             management.ClientSet,
+		namespace:             options.Namespace,
+		local: &targetClients{
+			harvester: harvesterClient,
+			kube:      management.ClientSet,
+		},
+		newRemoteClients: newRemoteClients,
+		httpClient:       &http.Client{},
+		remotes:          &sync.Map{},
+		checksums:        &sync.Map{},
+	}
+
+	replications.OnChange(ctx, imageReplicationControllerName, handler.OnChanged)
+	replications.OnRemove(ctx, imageReplicationControllerName, handler.OnRemove)
+	cronJobs.OnChange(ctx, cronJobControllerName, handler.OnCronjobChanged)
+	relatedresource.Watch(ctx, imageWatcherName, handler.ReconcileImageReplications, replications, images)
+	relatedresource.Watch(ctx, downloaderWatcherName, handler.ReconcileImageReplications, replications, downloaders)
+	return nil
+}
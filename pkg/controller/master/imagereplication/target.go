@@ -0,0 +1,119 @@
+package imagereplication
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned"
+)
+
+const kubeconfigSecretKey = "kubeconfig"
+
+// targetClients are the clients of the cluster the images are replicated to
+type targetClients struct {
+	harvester versioned.Interface
+	kube      kubernetes.Interface
+	remote    bool
+}
+
+// cachedClients are the clients of the remote cluster built from the version of the kubeconfig secret
+type cachedClients struct {
+	resourceVersion string
+	clients         *targetClients
+}
+
+func newRemoteClients(kubeconfig []byte) (*targetClients, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	harvesterClient, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &targetClients{harvester: harvesterClient, kube: kubeClient, remote: true}, nil
+}
+
+func (c *targetClients) getImage(ctx context.Context, namespace, name string) (*harvesterv1.VirtualMachineImage, error) {
+	return c.harvester.HarvesterhciV1beta1().VirtualMachineImages(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c *targetClients) createImage(ctx context.Context, vmi *harvesterv1.VirtualMachineImage) error {
+	_, err := c.harvester.HarvesterhciV1beta1().VirtualMachineImages(vmi.Namespace).Create(ctx, vmi, metav1.CreateOptions{})
+	return err
+}
+
+func (c *targetClients) deleteImage(ctx context.Context, namespace, name string) error {
+	return c.harvester.HarvesterhciV1beta1().VirtualMachineImages(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// getTargetClients returns the clients of the target cluster, which is this cluster if the replication doesn't
+// have a kubeconfig secret
+func (h *imageReplicationHandler) getTargetClients(r *harvesterv1.ImageReplication) (*targetClients, error) {
+	if r.Spec.Target.KubeconfigSecretName == "" {
+		return h.local, nil
+	}
+
+	secret, err := h.secretCache.Get(r.Namespace, r.Spec.Target.KubeconfigSecretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret %s/%s: %w", r.Namespace, r.Spec.Target.KubeconfigSecretName, err)
+	}
+	if cached, ok := h.remotes.Load(secret.UID); ok && cached.(*cachedClients).resourceVersion == secret.ResourceVersion {
+		return cached.(*cachedClients).clients, nil
+	}
+
+	kubeconfig, ok := secret.Data[kubeconfigSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig secret %s/%s doesn't have the %s key", secret.Namespace, secret.Name, kubeconfigSecretKey)
+	}
+	clients, err := h.newRemoteClients(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the clients of kubeconfig secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	h.remotes.Store(secret.UID, &cachedClients{resourceVersion: secret.ResourceVersion, clients: clients})
+	return clients, nil
+}
+
+// syncDownloadSecret copies the download secret of the replication to the target namespace of the remote cluster
+func (h *imageReplicationHandler) syncDownloadSecret(r *harvesterv1.ImageReplication, clients *targetClients) error {
+	secret, err := h.secretCache.Get(r.Namespace, r.Spec.Target.DownloadSecretName)
+	if err != nil {
+		return fmt.Errorf("failed to get download secret %s/%s: %w", r.Namespace, r.Spec.Target.DownloadSecretName, err)
+	}
+
+	secrets := clients.kube.CoreV1().Secrets(targetNamespace(r))
+	target, err := secrets.Get(h.ctx, downloadSecretName(r), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = secrets.Create(h.ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      downloadSecretName(r),
+				Namespace: targetNamespace(r),
+			},
+			Type: secret.Type,
+			Data: secret.Data,
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	if maps.EqualFunc(target.Data, secret.Data, func(a, b []byte) bool { return string(a) == string(b) }) {
+		return nil
+	}
+	targetCpy := target.DeepCopy()
+	targetCpy.Data = secret.Data
+	_, err = secrets.Update(h.ctx, targetCpy, metav1.UpdateOptions{})
+	return err
+}
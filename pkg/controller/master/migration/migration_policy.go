@@ -0,0 +1,153 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const migrationPolicyNamePrefix = "harvester-"
+
+// OnMigrationPolicySettingChanged creates a KubeVirt MigrationPolicy for every policy in the vm-migration-policies
+// setting and removes the ones of the deleted policies
+func (h *Handler) OnMigrationPolicySettingChanged(_ string, setting *harvesterv1.Setting) (*harvesterv1.Setting, error) {
+	if setting == nil || setting.DeletionTimestamp != nil || setting.Name != settings.VMMigrationPoliciesSettingName {
+		return setting, nil
+	}
+
+	value := setting.Value
+	if value == "" {
+		value = setting.Default
+	}
+	policies, err := settings.DecodeVMMigrationPolicies(value)
+	if err != nil {
+		return setting, err
+	}
+
+	desired := make(map[string]bool, len(policies))
+	for _, policy := range policies {
+		migrationPolicy := getKubeVirtMigrationPolicy(policy)
+		desired[migrationPolicy.Name] = true
+		if err := h.applyMigrationPolicy(migrationPolicy); err != nil {
+			return setting, err
+		}
+	}
+
+	migrationPolicies, err := h.migrationPolicyCache.List(labels.SelectorFromSet(labels.Set{
+		util.LabelSetting: settings.VMMigrationPoliciesSettingName,
+	}))
+	if err != nil {
+		return setting, err
+	}
+	for _, migrationPolicy := range migrationPolicies {
+		if desired[migrationPolicy.Name] {
+			continue
+		}
+		if err := h.migrationPolicies.Delete(migrationPolicy.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return setting, fmt.Errorf("failed to delete migration policy %s: %w", migrationPolicy.Name, err)
+		}
+	}
+	return setting, nil
+}
+
+func (h *Handler) applyMigrationPolicy(migrationPolicy *migrationsv1alpha1.MigrationPolicy) error {
+	existing, err := h.migrationPolicyCache.Get(migrationPolicy.Name)
+	if apierrors.IsNotFound(err) {
+		if _, err := h.migrationPolicies.Create(migrationPolicy); err != nil {
+			return fmt.Errorf("failed to create migration policy %s: %w", migrationPolicy.Name, err)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(existing.Spec, migrationPolicy.Spec) && reflect.DeepEqual(existing.Labels, migrationPolicy.Labels) {
+		return nil
+	}
+	existingCpy := existing.DeepCopy()
+	existingCpy.Labels = migrationPolicy.Labels
+	existingCpy.Spec = migrationPolicy.Spec
+	if _, err := h.migrationPolicies.Update(existingCpy); err != nil {
+		return fmt.Errorf("failed to update migration policy %s: %w", migrationPolicy.Name, err)
+	}
+	return nil
+}
+
+// getKubeVirtMigrationPolicy maps a policy onto a KubeVirt MigrationPolicy, which selects the VMIs by the
+// `harvesterhci.io/migrationPolicy` label. The node selector has no KubeVirt counterpart, the VirtualMachineInstanceMigration
+// mutator adds it to the migrations.
+func getKubeVirtMigrationPolicy(policy settings.VMMigrationPolicy) *migrationsv1alpha1.MigrationPolicy {
+	return &migrationsv1alpha1.MigrationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: migrationPolicyNamePrefix + policy.Name,
+			Labels: map[string]string{
+				util.LabelSetting: settings.VMMigrationPoliciesSettingName,
+			},
+		},
+		Spec: migrationsv1alpha1.MigrationPolicySpec{
+			Selectors: &migrationsv1alpha1.Selectors{
+				VirtualMachineInstanceSelector: migrationsv1alpha1.LabelSelector{
+					util.LabelMigrationPolicy: policy.Name,
+				},
+			},
+			AllowAutoConverge:       policy.AllowAutoConverge,
+			BandwidthPerMigration:   policy.BandwidthPerMigration,
+			CompletionTimeoutPerGiB: policy.CompletionTimeoutPerGiB,
+			AllowPostCopy:           policy.AllowPostCopy,
+		},
+	}
+}
+
+// OnVMChanged passes a change of the migration policy annotation on to the running VMI
+func (h *Handler) OnVMChanged(_ string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	if vm == nil || vm.DeletionTimestamp != nil {
+		return vm, nil
+	}
+
+	vmi, err := h.vmiCache.Get(vm.Namespace, vm.Name)
+	if apierrors.IsNotFound(err) {
+		return vm, nil
+	} else if err != nil {
+		return vm, err
+	}
+
+	if vmi.DeletionTimestamp != nil {
+		return vm, nil
+	}
+	_, err = h.syncMigrationPolicyLabel(vm, vmi)
+	return vm, err
+}
+
+// syncMigrationPolicyLabel labels the VMI with the migration policy in the annotation of its VM,
+// KubeVirt selects the MigrationPolicy of a migration by the VMI labels
+func (h *Handler) syncMigrationPolicyLabel(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1.VirtualMachineInstance) (bool, error) {
+	policy := vm.Annotations[util.AnnotationMigrationPolicy]
+	if vmi.Labels[util.LabelMigrationPolicy] == policy {
+		return false, nil
+	}
+
+	toUpdate := vmi.DeepCopy()
+	if policy == "" {
+		delete(toUpdate.Labels, util.LabelMigrationPolicy)
+	} else {
+		if toUpdate.Labels == nil {
+			toUpdate.Labels = make(map[string]string)
+		}
+		toUpdate.Labels[util.LabelMigrationPolicy] = policy
+	}
+
+	if err := util.VirtClientUpdateVmi(context.Background(), h.restClient, h.namespace, vmi.Namespace, vmi.Name, toUpdate); err != nil {
+		return false, fmt.Errorf("failed to label vmi %s/%s with migration policy %q: %w", vmi.Namespace, vmi.Name, policy, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,110 @@
+package migration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	fakegenerated "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func TestHandler_OnMigrationPolicySettingChanged(t *testing.T) {
+	managedLabels := map[string]string{util.LabelSetting: settings.VMMigrationPoliciesSettingName}
+	bandwidth := resource.MustParse("1Gi")
+
+	tests := []struct {
+		name     string
+		value    string
+		existing []*migrationsv1alpha1.MigrationPolicy
+		expected map[string]migrationsv1alpha1.MigrationPolicySpec
+	}{
+		{
+			name:  "create migration policies",
+			value: `[{"name":"database","allowPostCopy":true,"bandwidthPerMigration":"1Gi","completionTimeoutPerGiB":300,"nodeSelector":{"tier":"db"}},{"name":"default"}]`,
+			expected: map[string]migrationsv1alpha1.MigrationPolicySpec{
+				"harvester-database": {
+					Selectors: &migrationsv1alpha1.Selectors{
+						VirtualMachineInstanceSelector: migrationsv1alpha1.LabelSelector{util.LabelMigrationPolicy: "database"},
+					},
+					AllowPostCopy:           ptr.To(true),
+					BandwidthPerMigration:   &bandwidth,
+					CompletionTimeoutPerGiB: ptr.To[int64](300),
+				},
+				"harvester-default": {
+					Selectors: &migrationsv1alpha1.Selectors{
+						VirtualMachineInstanceSelector: migrationsv1alpha1.LabelSelector{util.LabelMigrationPolicy: "default"},
+					},
+				},
+			},
+		},
+		{
+			name:  "update and remove migration policies",
+			value: `[{"name":"database","allowAutoConverge":true}]`,
+			existing: []*migrationsv1alpha1.MigrationPolicy{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "harvester-database", Labels: managedLabels},
+					Spec: migrationsv1alpha1.MigrationPolicySpec{
+						Selectors: &migrationsv1alpha1.Selectors{
+							VirtualMachineInstanceSelector: migrationsv1alpha1.LabelSelector{util.LabelMigrationPolicy: "database"},
+						},
+						AllowPostCopy: ptr.To(true),
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "harvester-removed", Labels: managedLabels},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "user-defined"},
+				},
+			},
+			expected: map[string]migrationsv1alpha1.MigrationPolicySpec{
+				"harvester-database": {
+					Selectors: &migrationsv1alpha1.Selectors{
+						VirtualMachineInstanceSelector: migrationsv1alpha1.LabelSelector{util.LabelMigrationPolicy: "database"},
+					},
+					AllowAutoConverge: ptr.To(true),
+				},
+				"user-defined": {},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fakegenerated.NewSimpleClientset()
+			for _, migrationPolicy := range tt.existing {
+				assert.NoError(t, clientset.Tracker().Add(migrationPolicy), errMockTrackerAdd)
+			}
+
+			h := &Handler{
+				migrationPolicies:    fakeclients.MigrationPolicyClient(clientset.MigrationsV1alpha1().MigrationPolicies),
+				migrationPolicyCache: fakeclients.MigrationPolicyCache(clientset.MigrationsV1alpha1().MigrationPolicies),
+			}
+
+			setting := &harvesterv1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+				Default:    "[]",
+				Value:      tt.value,
+			}
+			_, err := h.OnMigrationPolicySettingChanged("", setting)
+			assert.NoError(t, err)
+
+			list, err := clientset.MigrationsV1alpha1().MigrationPolicies().List(context.TODO(), metav1.ListOptions{})
+			assert.NoError(t, err)
+			actual := map[string]migrationsv1alpha1.MigrationPolicySpec{}
+			for _, migrationPolicy := range list.Items {
+				actual[migrationPolicy.Name] = migrationPolicy.Spec
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
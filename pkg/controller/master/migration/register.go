@@ -12,6 +12,9 @@ import (
 const (
 	vmiControllerName  = "migrationTargetController"
 	vmimControllerName = "migrationAnnotationController"
+
+	vmMigrationPolicyControllerName      = "vmMigrationPolicyController"
+	settingMigrationPolicyControllerName = "settingMigrationPolicyController"
 )
 
 func Register(ctx context.Context, management *config.Management, options config.Options) error {
@@ -25,7 +28,8 @@ func Register(ctx context.Context, management *config.Management, options config
 	pods := management.CoreFactory.Core().V1().Pod()
 	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
 	vmims := management.VirtFactory.Kubevirt().V1().VirtualMachineInstanceMigration()
-	settingCache := management.HarvesterFactory.Harvesterhci().V1beta1().Setting().Cache()
+	settings := management.HarvesterFactory.Harvesterhci().V1beta1().Setting()
+	migrationPolicies := management.MigrationsFactory.Migrations().V1alpha1().MigrationPolicy()
 
 	handler := &Handler{
 		namespace:    options.Namespace,
@@ -36,11 +40,16 @@ func Register(ctx context.Context, management *config.Management, options config
 		vmCache:      vms.Cache(),
 		pods:         pods,
 		podCache:     pods.Cache(),
-		settingCache: settingCache,
+		settingCache: settings.Cache(),
 		restClient:   virtv1Client.RESTClient(),
+
+		migrationPolicies:    migrationPolicies,
+		migrationPolicyCache: migrationPolicies.Cache(),
 	}
 
 	vmis.OnChange(ctx, vmiControllerName, handler.OnVmiChanged)
 	vmims.OnChange(ctx, vmimControllerName, handler.OnVmimChanged)
+	vms.OnChange(ctx, vmMigrationPolicyControllerName, handler.OnVMChanged)
+	settings.OnChange(ctx, settingMigrationPolicyControllerName, handler.OnMigrationPolicySettingChanged)
 	return nil
 }
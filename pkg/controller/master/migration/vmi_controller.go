@@ -14,10 +14,12 @@ import (
 	ctlharvcorev1 "github.com/harvester/harvester/pkg/generated/controllers/core/v1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	ctlvirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	ctlmigrationsv1 "github.com/harvester/harvester/pkg/generated/controllers/migrations.kubevirt.io/v1alpha1"
 	"github.com/harvester/harvester/pkg/util"
 )
 
-// Handler resets vmi annotations and nodeSelector when a migration completes
+// Handler resets vmi annotations and nodeSelector when a migration completes,
+// and reconciles the migration policies of the VMs
 type Handler struct {
 	namespace    string
 	rqs          ctlharvcorev1.ResourceQuotaClient
@@ -29,11 +31,28 @@ type Handler struct {
 	pods         ctlcorev1.PodClient
 	settingCache ctlharvesterv1.SettingCache
 	restClient   rest.Interface
+
+	migrationPolicies    ctlmigrationsv1.MigrationPolicyClient
+	migrationPolicyCache ctlmigrationsv1.MigrationPolicyCache
 }
 
 func (h *Handler) OnVmiChanged(_ string, vmi *kubevirtv1.VirtualMachineInstance) (*kubevirtv1.VirtualMachineInstance, error) {
-	if vmi == nil || vmi.DeletionTimestamp != nil ||
-		vmi.Annotations == nil || vmi.Status.MigrationState == nil {
+	if vmi == nil || vmi.DeletionTimestamp != nil {
+		return vmi, nil
+	}
+
+	vm, err := h.vmCache.Get(vmi.Namespace, vmi.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return vmi, err
+	}
+	if vm != nil {
+		// the labeled vmi comes back to the handler
+		if updated, err := h.syncMigrationPolicyLabel(vm, vmi); err != nil || updated {
+			return vmi, err
+		}
+	}
+
+	if vmi.Annotations == nil || vmi.Status.MigrationState == nil {
 		return vmi, nil
 	}
 
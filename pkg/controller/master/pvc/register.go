@@ -7,17 +7,33 @@ import (
 )
 
 const (
-	pvcControllerName = "persistentvolumeclaim-controller"
+	pvcControllerName             = "persistentvolumeclaim-controller"
+	volumeExpansionControllerName = "volume-expansion-controller"
 )
 
 func Register(ctx context.Context, management *config.Management, _ config.Options) error {
 	dataVolume := management.CdiFactory.Cdi().V1beta1().DataVolume()
 	ctlpvc := management.CoreFactory.Core().V1().PersistentVolumeClaim()
+	lhVolumes := management.LonghornFactory.Longhorn().V1beta2().Volume()
+	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
 
 	pvcHandler := &pvcHandler{
 		dataVolumeClient: dataVolume,
 	}
 
 	ctlpvc.OnRemove(ctx, pvcControllerName, pvcHandler.cleanupDataVolume)
+
+	volumeExpansionHandler := &volumeExpansionHandler{
+		pvcClient:     ctlpvc,
+		pvcController: ctlpvc,
+		pvcCache:      ctlpvc.Cache(),
+		lhVolumeCache: lhVolumes.Cache(),
+		vmiCache:      vmis.Cache(),
+		recorder:      management.NewRecorder(volumeExpansionControllerName, "", ""),
+	}
+
+	ctlpvc.OnChange(ctx, volumeExpansionControllerName, volumeExpansionHandler.OnPVCChanged)
+	lhVolumes.OnChange(ctx, volumeExpansionControllerName, volumeExpansionHandler.OnLonghornVolumeChanged)
+	vmis.OnChange(ctx, volumeExpansionControllerName, volumeExpansionHandler.OnVMIChanged)
 	return nil
 }
@@ -0,0 +1,196 @@
+package pvc
+
+import (
+	"fmt"
+
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/record"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	ctllhv1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta2"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	reasonVolumeExpanded        = "VolumeExpanded"
+	reasonVolumeExpansionFailed = "VolumeExpansionFailed"
+)
+
+// volumeExpansionHandler tracks the expansions of VM volumes requested through the VM API. The progress goes
+// through the PVC resizing, the Longhorn volume expansion and ends when the new size is visible in the VMI status.
+type volumeExpansionHandler struct {
+	pvcClient     ctlcorev1.PersistentVolumeClaimClient
+	pvcController ctlcorev1.PersistentVolumeClaimController
+	pvcCache      ctlcorev1.PersistentVolumeClaimCache
+	lhVolumeCache ctllhv1.VolumeCache
+	vmiCache      ctlkubevirtv1.VirtualMachineInstanceCache
+	recorder      record.EventRecorder
+}
+
+func (h *volumeExpansionHandler) OnPVCChanged(_ string, pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+	if pvc == nil || pvc.DeletionTimestamp != nil {
+		return pvc, nil
+	}
+
+	expansion, err := util.UnmarshalVolumeExpansion(pvc.Annotations[util.AnnotationVolumeExpansion])
+	if err != nil || expansion == nil || expansion.IsFinished() {
+		return pvc, nil
+	}
+
+	phase, message, err := h.getVolumeExpansionPhase(pvc, expansion)
+	if err != nil {
+		return pvc, err
+	}
+	if phase == expansion.Phase && message == expansion.Message {
+		return pvc, nil
+	}
+
+	expansion.Phase = phase
+	expansion.Message = message
+	annotation, err := util.MarshalVolumeExpansion(expansion)
+	if err != nil {
+		return pvc, err
+	}
+	pvcCopy := pvc.DeepCopy()
+	pvcCopy.Annotations[util.AnnotationVolumeExpansion] = annotation
+	updated, err := h.pvcClient.Update(pvcCopy)
+	if err != nil {
+		return pvc, err
+	}
+
+	switch phase {
+	case util.VolumeExpansionCompleted:
+		h.recorder.Eventf(updated, corev1.EventTypeNormal, reasonVolumeExpanded, "Volume %s of VM %s is expanded to %s", expansion.Volume, expansion.VM, expansion.Size)
+	case util.VolumeExpansionFailed:
+		h.recorder.Eventf(updated, corev1.EventTypeWarning, reasonVolumeExpansionFailed, "Failed to expand volume %s of VM %s: %s", expansion.Volume, expansion.VM, message)
+	}
+	return updated, nil
+}
+
+// OnLonghornVolumeChanged enqueues the PVC of the Longhorn volume when it's being expanded
+func (h *volumeExpansionHandler) OnLonghornVolumeChanged(_ string, volume *lhv1beta2.Volume) (*lhv1beta2.Volume, error) {
+	if volume == nil || volume.DeletionTimestamp != nil {
+		return volume, nil
+	}
+
+	h.enqueueExpandingPVC(volume.Status.KubernetesStatus.Namespace, volume.Status.KubernetesStatus.PVCName)
+	return volume, nil
+}
+
+// OnVMIChanged enqueues the PVCs of the VMI being expanded, the expansions wait for the new sizes in the VMI status
+func (h *volumeExpansionHandler) OnVMIChanged(_ string, vmi *kubevirtv1.VirtualMachineInstance) (*kubevirtv1.VirtualMachineInstance, error) {
+	if vmi == nil || vmi.DeletionTimestamp != nil {
+		return vmi, nil
+	}
+
+	for _, status := range vmi.Status.VolumeStatus {
+		if status.PersistentVolumeClaimInfo != nil {
+			h.enqueueExpandingPVC(vmi.Namespace, status.PersistentVolumeClaimInfo.ClaimName)
+		}
+	}
+	return vmi, nil
+}
+
+func (h *volumeExpansionHandler) enqueueExpandingPVC(namespace, name string) {
+	if namespace == "" || name == "" {
+		return
+	}
+	pvc, err := h.pvcCache.Get(namespace, name)
+	if err != nil {
+		return
+	}
+	expansion, err := util.UnmarshalVolumeExpansion(pvc.Annotations[util.AnnotationVolumeExpansion])
+	if err == nil && expansion != nil && !expansion.IsFinished() {
+		h.pvcController.Enqueue(namespace, name)
+	}
+}
+
+func (h *volumeExpansionHandler) getVolumeExpansionPhase(pvc *corev1.PersistentVolumeClaim, expansion *util.VolumeExpansion) (util.VolumeExpansionPhase, string, error) {
+	size, err := resource.ParseQuantity(expansion.Size)
+	if err != nil {
+		return util.VolumeExpansionFailed, fmt.Sprintf("invalid size %s: %v", expansion.Size, err), nil
+	}
+
+	switch pvc.Status.AllocatedResourceStatuses[corev1.ResourceStorage] {
+	case corev1.PersistentVolumeClaimControllerResizeInfeasible, corev1.PersistentVolumeClaimNodeResizeInfeasible:
+		message := string(pvc.Status.AllocatedResourceStatuses[corev1.ResourceStorage])
+		if condition := getPVCCondition(pvc, corev1.PersistentVolumeClaimControllerResizeError, corev1.PersistentVolumeClaimNodeResizeError); condition != nil {
+			message = condition.Message
+		}
+		return util.VolumeExpansionFailed, message, nil
+	}
+
+	if pvc.Status.Capacity.Storage().Cmp(size) < 0 {
+		return h.getResizingPhase(pvc, size)
+	}
+
+	vmi, err := h.vmiCache.Get(pvc.Namespace, expansion.VM)
+	if apierrors.IsNotFound(err) {
+		return util.VolumeExpansionCompleted, "The VM sees the new size at the next start", nil
+	} else if err != nil {
+		return "", "", err
+	}
+	for _, status := range vmi.Status.VolumeStatus {
+		if status.PersistentVolumeClaimInfo == nil || status.PersistentVolumeClaimInfo.ClaimName != pvc.Name {
+			continue
+		}
+		if status.PersistentVolumeClaimInfo.Capacity.Storage().Cmp(size) >= 0 {
+			return util.VolumeExpansionCompleted, "", nil
+		}
+	}
+	return util.VolumeExpansionWaitingForGuest, "Waiting for the new size to be visible in the VMI status", nil
+}
+
+// getResizingPhase returns the phase of the PVC which doesn't reach the requested size yet
+func (h *volumeExpansionHandler) getResizingPhase(pvc *corev1.PersistentVolumeClaim, size resource.Quantity) (util.VolumeExpansionPhase, string, error) {
+	if getPVCCondition(pvc, corev1.PersistentVolumeClaimFileSystemResizePending) != nil {
+		return util.VolumeExpansionResizingFileSystem, "Waiting for the file system on the node to be resized", nil
+	}
+	// the resize errors are retried by the resizer, they're only reported
+	if condition := getPVCCondition(pvc, corev1.PersistentVolumeClaimControllerResizeError, corev1.PersistentVolumeClaimNodeResizeError); condition != nil {
+		return util.VolumeExpansionExpandingVolume, condition.Message, nil
+	}
+
+	if pvc.Spec.VolumeName != "" {
+		volume, err := h.lhVolumeCache.Get(util.LonghornSystemNamespaceName, pvc.Spec.VolumeName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "", "", err
+		}
+		if err == nil {
+			if volume.Spec.Size < size.Value() {
+				if getPVCCondition(pvc, corev1.PersistentVolumeClaimResizing) == nil {
+					return util.VolumeExpansionPending, "", nil
+				}
+				return util.VolumeExpansionExpandingVolume, fmt.Sprintf("Waiting for the CSI driver to expand Longhorn volume %s", volume.Name), nil
+			}
+			if volume.Status.ExpansionRequired {
+				return util.VolumeExpansionExpandingVolume, fmt.Sprintf("Longhorn is expanding volume %s", volume.Name), nil
+			}
+			return util.VolumeExpansionExpandingVolume, fmt.Sprintf("Longhorn volume %s is expanded, waiting for the PVC capacity to be updated", volume.Name), nil
+		}
+	}
+
+	if getPVCCondition(pvc, corev1.PersistentVolumeClaimResizing) == nil {
+		return util.VolumeExpansionPending, "", nil
+	}
+	return util.VolumeExpansionExpandingVolume, "", nil
+}
+
+func getPVCCondition(pvc *corev1.PersistentVolumeClaim, conditionTypes ...corev1.PersistentVolumeClaimConditionType) *corev1.PersistentVolumeClaimCondition {
+	for i, condition := range pvc.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		for _, conditionType := range conditionTypes {
+			if condition.Type == conditionType {
+				return &pvc.Status.Conditions[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package pvc
+
+import (
+	"testing"
+
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func Test_getVolumeExpansionPhase(t *testing.T) {
+	const (
+		namespace = "default"
+		vmName    = "vm"
+		pvcName   = "vm-disk-0"
+		pvName    = "pvc-0"
+	)
+	newPVC := func(capacity string, conditions ...corev1.PersistentVolumeClaimConditionType) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: pvcName},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: pvName},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+			},
+		}
+		for _, condition := range conditions {
+			pvc.Status.Conditions = append(pvc.Status.Conditions, corev1.PersistentVolumeClaimCondition{
+				Type:   condition,
+				Status: corev1.ConditionTrue,
+			})
+		}
+		return pvc
+	}
+	newLHVolume := func(size string, expansionRequired bool) *lhv1beta2.Volume {
+		quantity := resource.MustParse(size)
+		return &lhv1beta2.Volume{
+			ObjectMeta: metav1.ObjectMeta{Namespace: util.LonghornSystemNamespaceName, Name: pvName},
+			Spec:       lhv1beta2.VolumeSpec{Size: quantity.Value()},
+			Status:     lhv1beta2.VolumeStatus{ExpansionRequired: expansionRequired},
+		}
+	}
+	newVMI := func(capacity string) *kubevirtv1.VirtualMachineInstance {
+		return &kubevirtv1.VirtualMachineInstance{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: vmName},
+			Status: kubevirtv1.VirtualMachineInstanceStatus{
+				VolumeStatus: []kubevirtv1.VolumeStatus{
+					{
+						Name: "disk-0",
+						PersistentVolumeClaimInfo: &kubevirtv1.PersistentVolumeClaimInfo{
+							ClaimName: pvcName,
+							Capacity:  corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		pvc           *corev1.PersistentVolumeClaim
+		objects       []runtime.Object
+		expectedPhase util.VolumeExpansionPhase
+	}{
+		{
+			name:          "resizing not started",
+			pvc:           newPVC("10Gi"),
+			objects:       []runtime.Object{newLHVolume("10Gi", false)},
+			expectedPhase: util.VolumeExpansionPending,
+		},
+		{
+			name:          "waiting for the CSI driver",
+			pvc:           newPVC("10Gi", corev1.PersistentVolumeClaimResizing),
+			objects:       []runtime.Object{newLHVolume("10Gi", false)},
+			expectedPhase: util.VolumeExpansionExpandingVolume,
+		},
+		{
+			name:          "Longhorn is expanding",
+			pvc:           newPVC("10Gi", corev1.PersistentVolumeClaimResizing),
+			objects:       []runtime.Object{newLHVolume("20Gi", true)},
+			expectedPhase: util.VolumeExpansionExpandingVolume,
+		},
+		{
+			name:          "file system resize pending",
+			pvc:           newPVC("10Gi", corev1.PersistentVolumeClaimFileSystemResizePending),
+			objects:       []runtime.Object{newLHVolume("20Gi", false)},
+			expectedPhase: util.VolumeExpansionResizingFileSystem,
+		},
+		{
+			name:          "new size isn't in the VMI status yet",
+			pvc:           newPVC("20Gi"),
+			objects:       []runtime.Object{newLHVolume("20Gi", false), newVMI("10Gi")},
+			expectedPhase: util.VolumeExpansionWaitingForGuest,
+		},
+		{
+			name:          "new size is in the VMI status",
+			pvc:           newPVC("20Gi"),
+			objects:       []runtime.Object{newLHVolume("20Gi", false), newVMI("20Gi")},
+			expectedPhase: util.VolumeExpansionCompleted,
+		},
+		{
+			name:          "VM isn't running",
+			pvc:           newPVC("20Gi"),
+			objects:       []runtime.Object{newLHVolume("20Gi", false)},
+			expectedPhase: util.VolumeExpansionCompleted,
+		},
+		{
+			name: "resize infeasible",
+			pvc: func() *corev1.PersistentVolumeClaim {
+				pvc := newPVC("10Gi", corev1.PersistentVolumeClaimControllerResizeError)
+				pvc.Status.AllocatedResourceStatuses = map[corev1.ResourceName]corev1.ClaimResourceStatus{
+					corev1.ResourceStorage: corev1.PersistentVolumeClaimControllerResizeInfeasible,
+				}
+				return pvc
+			}(),
+			expectedPhase: util.VolumeExpansionFailed,
+		},
+	}
+
+	for _, tc := range tests {
+		clientset := fake.NewSimpleClientset(tc.objects...)
+		h := &volumeExpansionHandler{
+			lhVolumeCache: fakeclients.LonghornVolumeCache(clientset.LonghornV1beta2().Volumes),
+			vmiCache:      fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+		}
+		expansion := &util.VolumeExpansion{
+			Size:   "20Gi",
+			VM:     vmName,
+			Volume: "disk-0",
+			Phase:  util.VolumeExpansionPending,
+		}
+
+		phase, _, err := h.getVolumeExpansionPhase(tc.pvc, expansion)
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expectedPhase, phase, tc.name)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
 	"time"
 
 	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
@@ -16,6 +17,7 @@ import (
 	"github.com/harvester/harvester/pkg/controller/master/backup"
 	"github.com/harvester/harvester/pkg/ref"
 	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/cronjob"
 )
 
 const (
@@ -33,10 +35,7 @@ const (
 
 	svmbackupPrefix = "svmb"
 
-	cronJobNamespace    = "harvester-system"
-	cronJobBackoffLimit = 3
-	cronJobCmd          = "sleep"
-	cronJobArg          = "10"
+	cronJobNamespace = cronjob.Namespace
 )
 
 func cronJobName(svmbackup *harvesterv1.ScheduleVMBackup) string {
@@ -120,6 +119,14 @@ func createVMBackup(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup
 		Spec: svmbackup.Spec.VMBackupSpec,
 	}
 
+	if svmbackup.Spec.Verification != nil {
+		sequence, err := nextVMBackupSequence(h, svmbackup)
+		if err != nil {
+			return nil, err
+		}
+		vmBackup.Annotations[util.AnnotationSVMBackupSequence] = strconv.Itoa(sequence)
+	}
+
 	return h.vmBackupClient.Create(vmBackup)
 }
 
@@ -182,6 +189,10 @@ func gcVMBackups(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup) e
 		return nil
 	}
 
+	if svmbackup.Spec.RetentionPolicy != nil {
+		return gcVMBackupsByRetentionPolicy(h, svmbackup, vmBackups)
+	}
+
 	// we clear the failure backups first, and the successful backup from the oldest one
 	// the #target-delete-backups according to `.spec.retain`
 	var errs error
@@ -251,7 +262,7 @@ func convertVMBackupToInfo(vmbackup *harvesterv1.VirtualMachineBackup) harvester
 }
 
 func reconcileVMBackupList(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup) error {
-	vmbackups, _, _, failure, err := currentVMBackups(h, svmbackup)
+	vmbackups, _, lastVMBackup, failure, err := currentVMBackups(h, svmbackup)
 	if err != nil {
 		return err
 	}
@@ -259,10 +270,21 @@ func reconcileVMBackupList(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleV
 	svmbackupCpy := svmbackup.DeepCopy()
 	svmbackupCpy.Status.VMBackupInfo = make([]harvesterv1.VMBackupInfo, len(vmbackups))
 	svmbackupCpy.Status.Failure = failure
+	svmbackupCpy.Status.RunningVMBackup = ""
+	if lastVMBackup != nil && backup.IsBackupProgressing(lastVMBackup) {
+		svmbackupCpy.Status.RunningVMBackup = lastVMBackup.Name
+	}
 	for i := 0; i < len(vmbackups); i++ {
 		svmbackupCpy.Status.VMBackupInfo[i] = convertVMBackupToInfo(vmbackups[i])
 	}
 
+	if svmbackup.Spec.RetentionPolicy != nil {
+		retained := retainedVMBackups(svmbackup, vmbackups)
+		for i := range svmbackupCpy.Status.VMBackupInfo {
+			svmbackupCpy.Status.VMBackupInfo[i].RetainedBy = retained[vmbackups[i].Name]
+		}
+	}
+
 	if reflect.DeepEqual(svmbackup.Status, svmbackupCpy.Status) {
 		return nil
 	}
@@ -1,12 +1,11 @@
 package schedulevmbackup
 
 import (
-	"time"
-
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/cronjob"
 )
 
 func (h *svmbackupHandler) OnCronjobChanged(_ string, cronJob *batchv1.CronJob) (*batchv1.CronJob, error) {
@@ -21,11 +20,12 @@ func (h *svmbackupHandler) OnCronjobChanged(_ string, cronJob *batchv1.CronJob)
 
 	// cronJob.Status.LastScheduleTime could be out-of-date if the scheulde experience suspend and resume
 	// if this is happened, we should wait for the next reconcile
-	if time.Since(cronJob.Status.LastScheduleTime.Time) > time.Minute {
+	scheduleTime := cronjob.LastScheduleTime(cronJob)
+	if scheduleTime == nil {
 		return nil, nil
 	}
 
-	timestamp := cronJob.Status.LastScheduleTime.Format(timeFormat)
+	timestamp := scheduleTime.Format(timeFormat)
 	_, err := getVMBackup(h, svmbackup, timestamp)
 	if err == nil {
 		return cronJob, nil
@@ -35,7 +35,9 @@ func (h *svmbackupHandler) OnCronjobChanged(_ string, cronJob *batchv1.CronJob)
 		return nil, err
 	}
 
-	if _, err := newVMBackups(h, svmbackup, timestamp); err != nil {
+	// the backup may be deferred by the backup window or the concurrency limit,
+	// the schedule controller starts it from the queue
+	if err := queueVMBackup(h, svmbackup, timestamp); err != nil {
 		return nil, err
 	}
 
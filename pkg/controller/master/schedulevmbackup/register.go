@@ -5,11 +5,13 @@ import (
 
 	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/config"
 	ctlharvbatchv1 "github.com/harvester/harvester/pkg/generated/controllers/batch/v1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
 	ctllonghornv2 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta2"
 	ctlsnapshotv1 "github.com/harvester/harvester/pkg/generated/controllers/snapshot.storage.k8s.io/v1"
 )
@@ -44,6 +46,10 @@ type svmbackupHandler struct {
 	lhbackupClient       ctllonghornv2.BackupClient
 	snapshotContentCache ctlsnapshotv1.VolumeSnapshotContentCache
 	clientset            kubernetes.Interface
+	verificationClient   ctlharvesterv1.VirtualMachineBackupVerificationClient
+	verificationCache    ctlharvesterv1.VirtualMachineBackupVerificationCache
+	vmCache              ctlkubevirtv1.VirtualMachineCache
+	recorder             record.EventRecorder
 }
 
 func Register(ctx context.Context, management *config.Management, options config.Options) error {
@@ -56,6 +62,8 @@ func Register(ctx context.Context, management *config.Management, options config
 	secrets := management.CoreFactory.Core().V1().Secret()
 	lhbackups := management.LonghornFactory.Longhorn().V1beta2().Backup()
 	snapshotContents := management.SnapshotFactory.Snapshot().V1().VolumeSnapshotContent()
+	verifications := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackupVerification()
+	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
 
 	svmbackupHandler := &svmbackupHandler{
 		svmbackupController:  svmbackups,
@@ -76,6 +84,10 @@ func Register(ctx context.Context, management *config.Management, options config
 		lhbackupClient:       lhbackups,
 		snapshotContentCache: snapshotContents.Cache(),
 		clientset:            management.ClientSet,
+		verificationClient:   verifications,
+		verificationCache:    verifications.Cache(),
+		vmCache:              vms.Cache(),
+		recorder:             management.NewRecorder(scheduleVMBackupControllerName, "", ""),
 	}
 
 	svmbackups.OnChange(ctx, scheduleVMBackupControllerName, svmbackupHandler.OnChanged)
@@ -0,0 +1,109 @@
+package schedulevmbackup
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/multierr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/backup"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+type retentionPeriod struct {
+	rule  harvesterv1.RetentionRule
+	count int
+	key   func(t time.Time) string
+}
+
+func retentionPeriods(policy *harvesterv1.RetentionPolicy) []retentionPeriod {
+	return []retentionPeriod{
+		{
+			rule:  harvesterv1.RetentionRuleHourly,
+			count: policy.Hourly,
+			key:   func(t time.Time) string { return t.Format("2006010215") },
+		},
+		{
+			rule:  harvesterv1.RetentionRuleDaily,
+			count: policy.Daily,
+			key:   func(t time.Time) string { return t.Format("20060102") },
+		},
+		{
+			rule:  harvesterv1.RetentionRuleWeekly,
+			count: policy.Weekly,
+			key: func(t time.Time) string {
+				year, week := t.ISOWeek()
+				return fmt.Sprintf("%d-%02d", year, week)
+			},
+		},
+		{
+			rule:  harvesterv1.RetentionRuleMonthly,
+			count: policy.Monthly,
+			key:   func(t time.Time) string { return t.Format("200601") },
+		},
+	}
+}
+
+// vmBackupTime returns the scheduled time of the vmbackup from its timestamp label
+func vmBackupTime(vmbackup *harvesterv1.VirtualMachineBackup) time.Time {
+	if t, err := time.Parse(timeFormat, vmbackup.Labels[util.LabelSVMBackupTimestamp]); err == nil {
+		return t
+	}
+	return vmbackup.CreationTimestamp.Time
+}
+
+// retainedVMBackups returns the retention rules keeping each vmbackup.
+// The vmbackups must be sorted from the oldest to the newest, as currentVMBackups does.
+// The latest `.spec.retain` vmbackups are kept by the `last` rule no matter they are ready or not,
+// each rule of the retention policy keeps the newest ready vmbackup of the latest N periods having ready vmbackups.
+func retainedVMBackups(svmbackup *harvesterv1.ScheduleVMBackup, vmbackups []*harvesterv1.VirtualMachineBackup) map[string][]harvesterv1.RetentionRule {
+	retained := map[string][]harvesterv1.RetentionRule{}
+
+	for i := len(vmbackups) - 1; i >= 0 && i >= len(vmbackups)-svmbackup.Spec.Retain; i-- {
+		retained[vmbackups[i].Name] = append(retained[vmbackups[i].Name], harvesterv1.RetentionRuleLast)
+	}
+
+	if svmbackup.Spec.RetentionPolicy == nil {
+		return retained
+	}
+
+	for _, period := range retentionPeriods(svmbackup.Spec.RetentionPolicy) {
+		kept := 0
+		lastKey := ""
+		for i := len(vmbackups) - 1; i >= 0 && kept < period.count; i-- {
+			if !backup.IsBackupReady(vmbackups[i]) {
+				continue
+			}
+
+			key := period.key(vmBackupTime(vmbackups[i]))
+			if key == lastKey {
+				continue
+			}
+
+			lastKey = key
+			kept++
+			retained[vmbackups[i].Name] = append(retained[vmbackups[i].Name], period.rule)
+		}
+	}
+
+	return retained
+}
+
+// gcVMBackupsByRetentionPolicy deletes the vmbackups which aren't kept by any retention rule
+func gcVMBackupsByRetentionPolicy(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup, vmbackups []*harvesterv1.VirtualMachineBackup) error {
+	retained := retainedVMBackups(svmbackup, vmbackups)
+
+	var errs error
+	for _, vmbackup := range vmbackups {
+		if _, ok := retained[vmbackup.Name]; ok || vmbackup.DeletionTimestamp != nil {
+			continue
+		}
+
+		if err := cleanseVMBackup(h, vmbackup); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("svmbackup %s clear VMBackup %s failed %w", svmbackup.Name, vmbackup.Name, err))
+		}
+	}
+
+	return errs
+}
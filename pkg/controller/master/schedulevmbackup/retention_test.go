@@ -0,0 +1,95 @@
+package schedulevmbackup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func newRetentionVMBackup(timestamp string, ready bool) *harvesterv1.VirtualMachineBackup {
+	return &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: timestamp,
+			Labels: map[string]string{
+				util.LabelSVMBackupTimestamp: timestamp,
+			},
+		},
+		Status: harvesterv1.VirtualMachineBackupStatus{
+			ReadyToUse: ptr.To(ready),
+		},
+	}
+}
+
+func Test_retainedVMBackups(t *testing.T) {
+	// sorted from the oldest to the newest
+	vmbackups := []*harvesterv1.VirtualMachineBackup{
+		newRetentionVMBackup("20240501.0100", true),
+		newRetentionVMBackup("20240531.0100", true),
+		newRetentionVMBackup("20240601.0100", true),
+		newRetentionVMBackup("20240602.0100", true),
+		newRetentionVMBackup("20240602.2200", true),
+		newRetentionVMBackup("20240603.0100", true),
+		newRetentionVMBackup("20240603.0200", false),
+	}
+
+	tests := []struct {
+		name     string
+		policy   *harvesterv1.RetentionPolicy
+		expected map[string][]harvesterv1.RetentionRule
+	}{
+		{
+			name:   "no retention policy",
+			policy: nil,
+			expected: map[string][]harvesterv1.RetentionRule{
+				"20240603.0200": {harvesterv1.RetentionRuleLast},
+				"20240603.0100": {harvesterv1.RetentionRuleLast},
+			},
+		},
+		{
+			name: "daily and monthly",
+			policy: &harvesterv1.RetentionPolicy{
+				Daily:   3,
+				Monthly: 2,
+			},
+			expected: map[string][]harvesterv1.RetentionRule{
+				"20240603.0200": {harvesterv1.RetentionRuleLast},
+				"20240603.0100": {harvesterv1.RetentionRuleLast, harvesterv1.RetentionRuleDaily, harvesterv1.RetentionRuleMonthly},
+				"20240602.2200": {harvesterv1.RetentionRuleDaily},
+				"20240601.0100": {harvesterv1.RetentionRuleDaily},
+				"20240531.0100": {harvesterv1.RetentionRuleMonthly},
+			},
+		},
+		{
+			name: "hourly and weekly",
+			policy: &harvesterv1.RetentionPolicy{
+				Hourly: 2,
+				Weekly: 3,
+			},
+			expected: map[string][]harvesterv1.RetentionRule{
+				"20240603.0200": {harvesterv1.RetentionRuleLast},
+				// 2024-06-03 is Monday, it starts a new ISO week
+				"20240603.0100": {harvesterv1.RetentionRuleLast, harvesterv1.RetentionRuleHourly, harvesterv1.RetentionRuleWeekly},
+				"20240602.2200": {harvesterv1.RetentionRuleHourly, harvesterv1.RetentionRuleWeekly},
+				// 2024-05-31 is in the same ISO week as 2024-06-02
+				"20240501.0100": {harvesterv1.RetentionRuleWeekly},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svmbackup := &harvesterv1.ScheduleVMBackup{
+				Spec: harvesterv1.ScheduleVMBackupSpec{
+					Retain:          2,
+					RetentionPolicy: tc.policy,
+				},
+			}
+			require.Equal(t, tc.expected, retainedVMBackups(svmbackup, vmbackups))
+		})
+	}
+}
@@ -1,22 +1,15 @@
 package schedulevmbackup
 
 import (
-	"fmt"
-
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/ref"
 	"github.com/harvester/harvester/pkg/util"
-	utilHelm "github.com/harvester/harvester/pkg/util/helm"
-)
-
-const (
-	releaseAppHarvesterName = "harvester"
+	"github.com/harvester/harvester/pkg/util/cronjob"
 )
 
 func deleteCronJob(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup) error {
@@ -36,48 +29,11 @@ func deleteCronJob(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup)
 // The cronjob actually doesn't do anything in its own job, it's utilized to trigger OnCronjobChanged()
 // In OnCronjobChanged(), controller will create VMBackup for VM backup/snapshot
 func createCronJob(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup) (*batchv1.CronJob, error) {
-	backoffLimit := int32(cronJobBackoffLimit)
-	jobImage, err := utilHelm.FetchImageFromHelmValues(h.clientset, h.namespace,
-		releaseAppHarvesterName, []string{"generalJob", "image"})
+	cronJob, err := cronjob.NewTrigger(h.clientset, h.namespace, cronJobName(svmbackup), svmbackup.Spec.Cron, map[string]string{
+		util.AnnotationSVMBackupID: ref.Construct(svmbackup.Namespace, svmbackup.Name),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get harvester image (%s): %v", jobImage.ImageName(), err)
-	}
-
-	cronJob := &batchv1.CronJob{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      cronJobName(svmbackup),
-			Namespace: cronJobNamespace,
-			Annotations: map[string]string{
-				util.AnnotationSVMBackupID: ref.Construct(svmbackup.Namespace, svmbackup.Name),
-			},
-		},
-		Spec: batchv1.CronJobSpec{
-			Schedule:          svmbackup.Spec.Cron,
-			ConcurrencyPolicy: batchv1.ForbidConcurrent,
-			JobTemplate: batchv1.JobTemplateSpec{
-				Spec: batchv1.JobSpec{
-					BackoffLimit: &backoffLimit,
-					Template: corev1.PodTemplateSpec{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: cronJobName(svmbackup),
-						},
-						Spec: corev1.PodSpec{
-							Containers: []corev1.Container{
-								{
-									Name:            cronJobName(svmbackup),
-									Image:           jobImage.ImageName(),
-									Command:         []string{cronJobCmd},
-									Args:            []string{cronJobArg},
-									Resources:       corev1.ResourceRequirements{},
-									ImagePullPolicy: corev1.PullIfNotPresent,
-								},
-							},
-							RestartPolicy: corev1.RestartPolicyNever,
-						},
-					},
-				},
-			},
-		},
+		return nil, err
 	}
 	return h.cronJobsClient.Create(cronJob)
 }
@@ -115,6 +71,13 @@ func (h *svmbackupHandler) OnChanged(_ string, svmbackup *harvesterv1.ScheduleVM
 		return nil, err
 	}
 
+	updated, err := startQueuedVMBackup(h, svmbackup)
+	if err != nil {
+		return nil, err
+	}
+
+	// the deferred updateVMBackups works on the updated object
+	svmbackup = updated
 	return svmbackup, nil
 }
 
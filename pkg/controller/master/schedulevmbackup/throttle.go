@@ -0,0 +1,115 @@
+package schedulevmbackup
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/backup"
+)
+
+const (
+	// queueRetryInterval is how often a backup waiting for other backups checks again
+	queueRetryInterval = backup.ThrottleRetryInterval
+
+	vmBackupSkippedEvent = "VMBackupSkipped"
+)
+
+// queueVMBackup records the backup of a cron fire in `.status.queuedVMBackup`, the backup is started by
+// startQueuedVMBackup once the backup window is open and the cluster has capacity for it.
+// Only the latest fire is queued, a backup still waiting when the cron fires again is skipped with an event.
+func queueVMBackup(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup, timestamp string) error {
+	queued := svmbackup.Status.QueuedVMBackup
+	if queued != nil && queued.Timestamp == timestamp {
+		return nil
+	}
+
+	svmbackupCpy := svmbackup.DeepCopy()
+	if queued != nil {
+		h.recorder.Eventf(svmbackupCpy, corev1.EventTypeWarning, vmBackupSkippedEvent,
+			"Skipped the backup of %s queued since %s, the backup of %s is queued instead",
+			queued.Timestamp, queued.QueuedAt, timestamp)
+	}
+	svmbackupCpy.Status.QueuedVMBackup = &harvesterv1.QueuedVMBackup{
+		Timestamp: timestamp,
+		QueuedAt:  &metav1.Time{Time: time.Now()},
+	}
+	_, err := h.svmbackupClient.Update(svmbackupCpy)
+	return err
+}
+
+// startQueuedVMBackup creates the queued backup if nothing holds it back, otherwise it records
+// why the backup is waiting and checks again later.
+func startQueuedVMBackup(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup) (*harvesterv1.ScheduleVMBackup, error) {
+	queued := svmbackup.Status.QueuedVMBackup
+	if queued == nil {
+		return svmbackup, nil
+	}
+
+	// a suspended schedule drops its queued backup, the cron fires again after resuming
+	if svmbackup.Spec.Suspend || svmbackup.Status.Suspended {
+		return updateQueuedVMBackup(h, svmbackup, nil)
+	}
+
+	// the backup was started, e.g. before the controller restarted
+	if _, err := getVMBackup(h, svmbackup, queued.Timestamp); err == nil {
+		return updateQueuedVMBackup(h, svmbackup, nil)
+	}
+
+	backup.ThrottleLock.Lock()
+	defer backup.ThrottleLock.Unlock()
+
+	reason, msg, retryAfter, err := checkBackupThrottle(h, svmbackup, time.Now())
+	if err != nil {
+		return svmbackup, err
+	}
+
+	if reason != "" {
+		queuedCpy := queued.DeepCopy()
+		queuedCpy.Reason = reason
+		queuedCpy.Message = msg
+		h.svmbackupController.EnqueueAfter(svmbackup.Namespace, svmbackup.Name, retryAfter)
+		return updateQueuedVMBackup(h, svmbackup, queuedCpy)
+	}
+
+	if _, err := newVMBackups(h, svmbackup, queued.Timestamp); err != nil {
+		return svmbackup, err
+	}
+
+	return updateQueuedVMBackup(h, svmbackup, nil)
+}
+
+func updateQueuedVMBackup(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup, queued *harvesterv1.QueuedVMBackup) (*harvesterv1.ScheduleVMBackup, error) {
+	if reflect.DeepEqual(svmbackup.Status.QueuedVMBackup, queued) {
+		return svmbackup, nil
+	}
+
+	svmbackupCpy := svmbackup.DeepCopy()
+	svmbackupCpy.Status.QueuedVMBackup = queued
+	return h.svmbackupClient.Update(svmbackupCpy)
+}
+
+// checkBackupThrottle returns the reason why the backup of the schedule can't start now
+// and when it should be checked again, an empty reason means the backup can start.
+func checkBackupThrottle(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup, now time.Time) (
+	reason harvesterv1.BackupQueuedReason, msg string, retryAfter time.Duration, err error) {
+	_, _, lastVMBackup, _, err := currentVMBackups(h, svmbackup)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	if lastVMBackup != nil && backup.IsBackupProgressing(lastVMBackup) {
+		msg = fmt.Sprintf("waiting for vmbackup %s to complete", lastVMBackup.Name)
+		return harvesterv1.BackupQueuedPreviousInProgress, msg, queueRetryInterval, nil
+	}
+
+	vmBackup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: svmbackup.Namespace},
+		Spec:       svmbackup.Spec.VMBackupSpec,
+	}
+	return backup.CheckBackupThrottle(h.vmBackupClient, h.vmCache, vmBackup, svmbackup.Spec.Window, now)
+}
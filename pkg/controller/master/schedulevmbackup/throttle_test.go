@@ -0,0 +1,237 @@
+package schedulevmbackup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newThrottleVMBackup(namespace, name string, ready bool, volumeBackups int) *harvesterv1.VirtualMachineBackup {
+	vmBackup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: harvesterv1.VirtualMachineBackupSpec{
+			Type: harvesterv1.Backup,
+		},
+		Status: harvesterv1.VirtualMachineBackupStatus{
+			ReadyToUse: ptr.To(ready),
+		},
+	}
+	for i := 0; i < volumeBackups; i++ {
+		vmBackup.Status.VolumeBackups = append(vmBackup.Status.VolumeBackups, harvesterv1.VolumeBackup{ReadyToUse: ptr.To(ready)})
+	}
+	return vmBackup
+}
+
+func newThrottleVM(namespace, name string, volumes int) *kubevirtv1.VirtualMachine {
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{},
+		},
+	}
+	for i := 0; i < volumes; i++ {
+		vm.Spec.Template.Spec.Volumes = append(vm.Spec.Template.Spec.Volumes, kubevirtv1.Volume{
+			VolumeSource: kubevirtv1.VolumeSource{
+				PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+					PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc"},
+				},
+			},
+		})
+	}
+	vm.Spec.Template.Spec.Volumes = append(vm.Spec.Template.Spec.Volumes, kubevirtv1.Volume{
+		VolumeSource: kubevirtv1.VolumeSource{CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{}},
+	})
+	return vm
+}
+
+func Test_CheckBackupThrottle(t *testing.T) {
+	// 12:00 UTC
+	now := time.Date(2024, 7, 17, 12, 0, 0, 0, time.UTC)
+
+	throttleSVMBackup := func(window *harvesterv1.BackupWindow) *harvesterv1.ScheduleVMBackup {
+		return &harvesterv1.ScheduleVMBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "svmbackup",
+				UID:       "841f1346-ce15-4495-a5f6-fae76d11f92e",
+			},
+			Spec: harvesterv1.ScheduleVMBackupSpec{
+				VMBackupSpec: harvesterv1.VirtualMachineBackupSpec{
+					Type:   harvesterv1.Backup,
+					Source: corev1.TypedLocalObjectReference{Name: "vm"},
+				},
+				Window: window,
+			},
+		}
+	}
+
+	previous := newThrottleVMBackup("default", vmBackupName(throttleSVMBackup(nil), timestamp1), false, 1)
+	previous.Labels = map[string]string{
+		util.LabelSVMBackupUID:       "841f1346-ce15-4495-a5f6-fae76d11f92e",
+		util.LabelSVMBackupTimestamp: timestamp1,
+	}
+
+	// the VM backup just created by another schedule of the VM
+	newVMBackup := newThrottleVMBackup("default", "new", false, 0)
+	newVMBackup.Spec.Source.Name = "vm"
+
+	tests := []struct {
+		name       string
+		svmbackup  *harvesterv1.ScheduleVMBackup
+		throttle   string
+		vmBackups  []*harvesterv1.VirtualMachineBackup
+		reason     harvesterv1.BackupQueuedReason
+		retryAfter time.Duration
+	}{
+		{
+			name:      "no throttle",
+			svmbackup: throttleSVMBackup(nil),
+			throttle:  "{}",
+		},
+		{
+			name:       "previous backup in progress",
+			svmbackup:  throttleSVMBackup(nil),
+			throttle:   "{}",
+			vmBackups:  []*harvesterv1.VirtualMachineBackup{previous},
+			reason:     harvesterv1.BackupQueuedPreviousInProgress,
+			retryAfter: queueRetryInterval,
+		},
+		{
+			name:       "outside the window of the setting",
+			svmbackup:  throttleSVMBackup(nil),
+			throttle:   `{"window":{"start":"22:00","end":"06:00"}}`,
+			reason:     harvesterv1.BackupQueuedOutsideWindow,
+			retryAfter: 10 * time.Hour,
+		},
+		{
+			name:      "window of the schedule overrides the setting",
+			svmbackup: throttleSVMBackup(&harvesterv1.BackupWindow{Start: "11:00", End: "13:00"}),
+			throttle:  `{"window":{"start":"22:00","end":"06:00"}}`,
+		},
+		{
+			name:      "under the concurrency limit",
+			svmbackup: throttleSVMBackup(nil),
+			throttle:  `{"maxConcurrentVolumeBackups":4}`,
+			vmBackups: []*harvesterv1.VirtualMachineBackup{
+				newThrottleVMBackup("other", "running", false, 2),
+				newThrottleVMBackup("other", "done", true, 3),
+			},
+		},
+		{
+			name:      "over the concurrency limit",
+			svmbackup: throttleSVMBackup(nil),
+			throttle:  `{"maxConcurrentVolumeBackups":3}`,
+			vmBackups: []*harvesterv1.VirtualMachineBackup{
+				newThrottleVMBackup("other", "running", false, 2),
+			},
+			reason:     harvesterv1.BackupQueuedConcurrencyLimit,
+			retryAfter: queueRetryInterval,
+		},
+		{
+			name:      "new backup reserves the volumes of its VM",
+			svmbackup: throttleSVMBackup(nil),
+			throttle:  `{"maxConcurrentVolumeBackups":3}`,
+			vmBackups: []*harvesterv1.VirtualMachineBackup{
+				newVMBackup,
+			},
+			reason:     harvesterv1.BackupQueuedConcurrencyLimit,
+			retryAfter: queueRetryInterval,
+		},
+		{
+			name:      "more volumes than the limit start when nothing runs",
+			svmbackup: throttleSVMBackup(nil),
+			throttle:  `{"maxConcurrentVolumeBackups":1}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := require.New(t)
+			clientset := fake.NewSimpleClientset()
+			h := &svmbackupHandler{
+				vmBackupClient: fakeclients.VMBackupClient(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+				vmBackupCache:  fakeclients.VMBackupCache(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+				vmCache:        fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+			}
+
+			assert.NoError(clientset.Tracker().Add(newThrottleVM("default", "vm", 2)))
+			for _, vmBackup := range tc.vmBackups {
+				assert.NoError(clientset.Tracker().Add(vmBackup))
+			}
+			assert.NoError(settings.BackupThrottleSet.Set(tc.throttle))
+
+			reason, _, retryAfter, err := checkBackupThrottle(h, tc.svmbackup, now)
+			assert.NoError(err)
+			assert.Equal(tc.reason, reason)
+			assert.Equal(tc.retryAfter, retryAfter)
+		})
+	}
+}
+
+func Test_QueueVMBackup(t *testing.T) {
+	tests := []struct {
+		name          string
+		queued        *harvesterv1.QueuedVMBackup
+		timestamp     string
+		expectSkipped bool
+	}{
+		{
+			name:      "nothing is queued",
+			timestamp: timestamp1,
+		},
+		{
+			name:      "the fire is already queued",
+			queued:    &harvesterv1.QueuedVMBackup{Timestamp: timestamp1},
+			timestamp: timestamp1,
+		},
+		{
+			name:          "the queued backup is skipped",
+			queued:        &harvesterv1.QueuedVMBackup{Timestamp: timestamp1, QueuedAt: &metav1.Time{}},
+			timestamp:     timestamp2,
+			expectSkipped: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := require.New(t)
+			svmbackup := &harvesterv1.ScheduleVMBackup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "svmbackup"},
+				Status:     harvesterv1.ScheduleVMBackupStatus{QueuedVMBackup: tc.queued},
+			}
+			clientset := fake.NewSimpleClientset(svmbackup)
+			recorder := record.NewFakeRecorder(10)
+			h := &svmbackupHandler{
+				svmbackupClient: fakeclients.SVMBackupClient(clientset.HarvesterhciV1beta1().ScheduleVMBackups),
+				recorder:        recorder,
+			}
+
+			assert.NoError(queueVMBackup(h, svmbackup, tc.timestamp))
+
+			stored, err := clientset.HarvesterhciV1beta1().ScheduleVMBackups("default").Get(context.TODO(), "svmbackup", metav1.GetOptions{})
+			assert.NoError(err)
+			assert.NotNil(stored.Status.QueuedVMBackup)
+			assert.Equal(tc.timestamp, stored.Status.QueuedVMBackup.Timestamp)
+			assert.Equal(tc.expectSkipped, len(recorder.Events) == 1)
+		})
+	}
+}
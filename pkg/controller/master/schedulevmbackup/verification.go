@@ -0,0 +1,86 @@
+package schedulevmbackup
+
+import (
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/backup"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// vmBackupSequence returns the sequence number of the vmbackup in the schedule, 0 means unknown
+func vmBackupSequence(vmbackup *harvesterv1.VirtualMachineBackup) int {
+	if vmbackup == nil {
+		return 0
+	}
+
+	sequence, err := strconv.Atoi(vmbackup.Annotations[util.AnnotationSVMBackupSequence])
+	if err != nil {
+		return 0
+	}
+	return sequence
+}
+
+// nextVMBackupSequence numbers the new vmbackup after the latest one,
+// the latest vmbackup is always kept by `.spec.retain` so the sequence keeps increasing after the old ones are removed.
+func nextVMBackupSequence(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup) (int, error) {
+	_, _, lastVMBackup, _, err := currentVMBackups(h, svmbackup)
+	if err != nil {
+		return 0, err
+	}
+	return vmBackupSequence(lastVMBackup) + 1, nil
+}
+
+// needVerification returns true if the vmbackup is every Nth one of the schedule
+func needVerification(svmbackup *harvesterv1.ScheduleVMBackup, vmbackup *harvesterv1.VirtualMachineBackup) bool {
+	if svmbackup.Spec.Verification == nil || svmbackup.Spec.Verification.Every < 1 {
+		return false
+	}
+
+	sequence := vmBackupSequence(vmbackup)
+	return sequence > 0 && sequence%svmbackup.Spec.Verification.Every == 0
+}
+
+// reconcileVMBackupVerification creates the verification of a ready vmbackup,
+// it's owned by the vmbackup and removed with the vmbackup.
+func reconcileVMBackupVerification(h *svmbackupHandler, svmbackup *harvesterv1.ScheduleVMBackup, vmbackup *harvesterv1.VirtualMachineBackup) error {
+	if !backup.IsBackupReady(vmbackup) || !needVerification(svmbackup, vmbackup) {
+		return nil
+	}
+
+	if _, err := h.verificationCache.Get(vmbackup.Namespace, vmbackup.Name); err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+
+	verification := &harvesterv1.VirtualMachineBackupVerification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      vmbackup.Name,
+			Namespace: vmbackup.Namespace,
+			Labels: map[string]string{
+				util.LabelSVMBackupUID: string(svmbackup.UID),
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         harvesterv1.SchemeGroupVersion.String(),
+					Kind:               vmBackupKindName,
+					Name:               vmbackup.Name,
+					UID:                vmbackup.UID,
+					BlockOwnerDeletion: ptr.To(true),
+				},
+			},
+		},
+		Spec: harvesterv1.VirtualMachineBackupVerificationSpec{
+			VirtualMachineBackupName: vmbackup.Name,
+			VerificationOptions:      svmbackup.Spec.Verification.VerificationOptions,
+		},
+	}
+
+	if _, err := h.verificationClient.Create(verification); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
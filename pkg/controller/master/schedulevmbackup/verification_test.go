@@ -0,0 +1,72 @@
+package schedulevmbackup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func Test_needVerification(t *testing.T) {
+	newSequenceVMBackup := func(sequence string) *harvesterv1.VirtualMachineBackup {
+		return &harvesterv1.VirtualMachineBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					util.AnnotationSVMBackupSequence: sequence,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		verification *harvesterv1.ScheduleVMBackupVerification
+		vmbackup     *harvesterv1.VirtualMachineBackup
+		expected     bool
+	}{
+		{
+			name:         "no verification",
+			verification: nil,
+			vmbackup:     newSequenceVMBackup("3"),
+			expected:     false,
+		},
+		{
+			name:         "every backup",
+			verification: &harvesterv1.ScheduleVMBackupVerification{Every: 1},
+			vmbackup:     newSequenceVMBackup("1"),
+			expected:     true,
+		},
+		{
+			name:         "every 3rd backup",
+			verification: &harvesterv1.ScheduleVMBackupVerification{Every: 3},
+			vmbackup:     newSequenceVMBackup("6"),
+			expected:     true,
+		},
+		{
+			name:         "not the 3rd backup",
+			verification: &harvesterv1.ScheduleVMBackupVerification{Every: 3},
+			vmbackup:     newSequenceVMBackup("4"),
+			expected:     false,
+		},
+		{
+			name:         "backup without sequence",
+			verification: &harvesterv1.ScheduleVMBackupVerification{Every: 1},
+			vmbackup:     &harvesterv1.VirtualMachineBackup{},
+			expected:     false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svmbackup := &harvesterv1.ScheduleVMBackup{
+				Spec: harvesterv1.ScheduleVMBackupSpec{
+					Verification: tc.verification,
+				},
+			}
+			require.Equal(t, tc.expected, needVerification(svmbackup, tc.vmbackup))
+		})
+	}
+}
@@ -34,6 +34,10 @@ func (h *svmbackupHandler) OnVMBackupChange(_ string, vmBackup *harvesterv1.Virt
 		return nil, err
 	}
 
+	if err := reconcileVMBackupVerification(h, svmbackup, vmBackup); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
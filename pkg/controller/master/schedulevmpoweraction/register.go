@@ -0,0 +1,64 @@
+package schedulevmpoweraction
+
+import (
+	"context"
+
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/harvester/harvester/pkg/config"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
+	ctlharvbatchv1 "github.com/harvester/harvester/pkg/generated/controllers/batch/v1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+)
+
+const (
+	scheduleVMPowerActionControllerName = "schedule-vm-power-action-controller"
+	cronJobControllerName               = "schedule-vm-power-action-cron-job-controller"
+)
+
+type svmPowerActionHandler struct {
+	svmPowerActionClient      ctlharvesterv1.ScheduleVMPowerActionClient
+	svmPowerActionCache       ctlharvesterv1.ScheduleVMPowerActionCache
+	cronJobsClient            ctlharvbatchv1.CronJobClient
+	cronJobCache              ctlharvbatchv1.CronJobCache
+	vmClient                  ctlkubevirtv1.VirtualMachineClient
+	vmCache                   ctlkubevirtv1.VirtualMachineCache
+	virtSubresourceRestClient rest.Interface
+	clientset                 kubernetes.Interface
+	namespace                 string
+}
+
+func Register(ctx context.Context, management *config.Management, options config.Options) error {
+	svmPowerActions := management.HarvesterFactory.Harvesterhci().V1beta1().ScheduleVMPowerAction()
+	cronJobs := management.HarvesterBatchFactory.Batch().V1().CronJob()
+	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
+
+	virtSubsrcConfig := rest.CopyConfig(management.RestConfig)
+	virtSubsrcConfig.GroupVersion = &k8sschema.GroupVersion{Group: "subresources.kubevirt.io", Version: "v1"}
+	virtSubsrcConfig.APIPath = "/apis"
+	virtSubsrcConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	virtSubresourceClient, err := rest.RESTClientFor(virtSubsrcConfig)
+	if err != nil {
+		return err
+	}
+
+	handler := &svmPowerActionHandler{
+		svmPowerActionClient:      svmPowerActions,
+		svmPowerActionCache:       svmPowerActions.Cache(),
+		cronJobsClient:            cronJobs,
+		cronJobCache:              cronJobs.Cache(),
+		vmClient:                  vms,
+		vmCache:                   vms.Cache(),
+		virtSubresourceRestClient: virtSubresourceClient,
+		clientset:                 management.ClientSet,
+		namespace:                 options.Namespace,
+	}
+
+	svmPowerActions.OnChange(ctx, scheduleVMPowerActionControllerName, handler.OnChanged)
+	svmPowerActions.OnRemove(ctx, scheduleVMPowerActionControllerName, handler.OnRemove)
+	cronJobs.OnChange(ctx, cronJobControllerName, handler.OnCronjobChanged)
+	return nil
+}
@@ -0,0 +1,277 @@
+package schedulevmpoweraction
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/cronjob"
+)
+
+const (
+	svmPowerActionPrefix = "svmpa"
+	timeFormat           = "20060102.1504"
+
+	defaultHistoryLimit = 10
+)
+
+func cronJobName(svmPowerAction *harvesterv1.ScheduleVMPowerAction) string {
+	return fmt.Sprintf("%s-%s", svmPowerActionPrefix, svmPowerAction.UID)
+}
+
+// OnChanged keeps the CronJob triggering the schedule in sync with the cron expression and the suspend flag
+func (h *svmPowerActionHandler) OnChanged(_ string, svmPowerAction *harvesterv1.ScheduleVMPowerAction) (*harvesterv1.ScheduleVMPowerAction, error) {
+	if svmPowerAction == nil || svmPowerAction.DeletionTimestamp != nil {
+		return svmPowerAction, nil
+	}
+
+	cronJob, err := h.cronJobCache.Get(cronjob.Namespace, cronJobName(svmPowerAction))
+	if apierrors.IsNotFound(err) {
+		cronJob, err = cronjob.NewTrigger(h.clientset, h.namespace, cronJobName(svmPowerAction), svmPowerAction.Spec.Cron, map[string]string{
+			util.AnnotationSVMPowerActionID: ref.Construct(svmPowerAction.Namespace, svmPowerAction.Name),
+		})
+		if err != nil {
+			return svmPowerAction, err
+		}
+		cronJob.Spec.Suspend = ptr.To(svmPowerAction.Spec.Suspend)
+		_, err = h.cronJobsClient.Create(cronJob)
+		return svmPowerAction, err
+	} else if err != nil {
+		return svmPowerAction, err
+	}
+
+	if cronJob.Spec.Schedule == svmPowerAction.Spec.Cron && ptr.Deref(cronJob.Spec.Suspend, false) == svmPowerAction.Spec.Suspend {
+		return svmPowerAction, nil
+	}
+
+	cronJobCpy := cronJob.DeepCopy()
+	cronJobCpy.Spec.Schedule = svmPowerAction.Spec.Cron
+	cronJobCpy.Spec.Suspend = ptr.To(svmPowerAction.Spec.Suspend)
+	_, err = h.cronJobsClient.Update(cronJobCpy)
+	return svmPowerAction, err
+}
+
+func (h *svmPowerActionHandler) OnRemove(_ string, svmPowerAction *harvesterv1.ScheduleVMPowerAction) (*harvesterv1.ScheduleVMPowerAction, error) {
+	if svmPowerAction == nil {
+		return nil, nil
+	}
+
+	propagation := metav1.DeletePropagationForeground
+	err := h.cronJobsClient.Delete(cronjob.Namespace, cronJobName(svmPowerAction), &metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return svmPowerAction, err
+	}
+	return svmPowerAction, nil
+}
+
+// OnCronjobChanged runs the power action on the selected VMs once per schedule time, the per-VM results
+// of the run are recorded in the status. The run is claimed in the status before the action runs, so it never
+// runs twice for a schedule time even if the cache is stale or the results fail to be recorded.
+func (h *svmPowerActionHandler) OnCronjobChanged(_ string, cronJob *batchv1.CronJob) (*batchv1.CronJob, error) {
+	if cronJob == nil || cronJob.DeletionTimestamp != nil {
+		return cronJob, nil
+	}
+
+	svmPowerAction := h.resolveSVMPowerActionRef(cronJob)
+	if svmPowerAction == nil || svmPowerAction.DeletionTimestamp != nil || svmPowerAction.Spec.Suspend {
+		return cronJob, nil
+	}
+
+	scheduleTime := cronjob.LastScheduleTime(cronJob)
+	if scheduleTime == nil {
+		return cronJob, nil
+	}
+
+	timestamp := scheduleTime.Format(timeFormat)
+	if hasRun(svmPowerAction, timestamp) {
+		return cronJob, nil
+	}
+
+	names, err := h.getVMNames(svmPowerAction)
+	if err != nil {
+		return cronJob, err
+	}
+
+	run := harvesterv1.VMPowerActionRun{
+		Timestamp:    timestamp,
+		ScheduleTime: scheduleTime.DeepCopy(),
+	}
+	claimed, err := h.claimRun(svmPowerAction, run)
+	if err != nil || !claimed {
+		return cronJob, err
+	}
+
+	for _, name := range names {
+		result := harvesterv1.BulkActionResult{Name: name, Succeeded: true}
+		if err := h.doPowerAction(context.Background(), svmPowerAction.Namespace, name, svmPowerAction.Spec.Action); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"namespace": svmPowerAction.Namespace,
+				"name":      svmPowerAction.Name,
+				"vm":        name,
+				"action":    svmPowerAction.Spec.Action,
+			}).Warn("Failed to run scheduled power action")
+			result.Succeeded = false
+			result.Message = err.Error()
+			run.Failed++
+		} else {
+			run.Succeeded++
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	return cronJob, h.recordRun(svmPowerAction, run)
+}
+
+func hasRun(svmPowerAction *harvesterv1.ScheduleVMPowerAction, timestamp string) bool {
+	for _, run := range svmPowerAction.Status.Runs {
+		if run.Timestamp == timestamp {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *svmPowerActionHandler) resolveSVMPowerActionRef(cronJob *batchv1.CronJob) *harvesterv1.ScheduleVMPowerAction {
+	id := cronJob.Annotations[util.AnnotationSVMPowerActionID]
+	if id == "" {
+		return nil
+	}
+
+	namespace, name := ref.Parse(id)
+	svmPowerAction, err := h.svmPowerActionCache.Get(namespace, name)
+	if err != nil {
+		return nil
+	}
+	return svmPowerAction
+}
+
+// getVMNames returns the sorted names of the VMs the action runs on, the given names are kept as they are
+// so that a missing VM shows up as a failed result
+func (h *svmPowerActionHandler) getVMNames(svmPowerAction *harvesterv1.ScheduleVMPowerAction) ([]string, error) {
+	seen := map[string]bool{}
+	var names []string
+	for _, name := range svmPowerAction.Spec.Names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if svmPowerAction.Spec.Selector != "" {
+		selector, err := labels.Parse(svmPowerAction.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", svmPowerAction.Spec.Selector, err)
+		}
+		vms, err := h.vmCache.List(svmPowerAction.Namespace, selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, vm := range vms {
+			if !seen[vm.Name] {
+				seen[vm.Name] = true
+				names = append(names, vm.Name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (h *svmPowerActionHandler) doPowerAction(ctx context.Context, namespace, name string, action harvesterv1.VMPowerAction) error {
+	vm, err := h.vmCache.Get(namespace, name)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case harvesterv1.VMPowerActionStart:
+		// the VM is in the desired state already
+		if vm.Status.Ready {
+			return nil
+		}
+		return h.virtSubresourceRestClient.Put().Namespace(namespace).Resource("virtualmachines").SubResource("start").Name(name).Do(ctx).Error()
+	case harvesterv1.VMPowerActionStop:
+		// align with the stop action of the VM API, which sets the runStrategy to Halted
+		if vm.Spec.RunStrategy != nil && *vm.Spec.RunStrategy == kubevirtv1.RunStrategyHalted {
+			return nil
+		}
+		vmCpy := vm.DeepCopy()
+		vmCpy.Spec.RunStrategy = ptr.To(kubevirtv1.RunStrategyHalted)
+		_, err := h.vmClient.Update(vmCpy)
+		return err
+	case harvesterv1.VMPowerActionRestart:
+		return h.virtSubresourceRestClient.Put().Namespace(namespace).Resource("virtualmachines").SubResource("restart").Name(name).Do(ctx).Error()
+	case harvesterv1.VMPowerActionSoftReboot:
+		return h.virtSubresourceRestClient.Put().Namespace(namespace).Resource("virtualmachineinstances").SubResource("softreboot").Name(name).Do(ctx).Error()
+	default:
+		return fmt.Errorf("unknown power action %q", action)
+	}
+}
+
+// claimRun adds the run without results to the status, the newest first, and drops the runs over the history
+// limit. It returns false if the run is in the status already. The run is checked against the latest object and
+// the update fails on conflicts, so only one claim of the schedule time succeeds.
+func (h *svmPowerActionHandler) claimRun(svmPowerAction *harvesterv1.ScheduleVMPowerAction, run harvesterv1.VMPowerActionRun) (bool, error) {
+	claimed := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := h.svmPowerActionClient.Get(svmPowerAction.Namespace, svmPowerAction.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if hasRun(latest, run.Timestamp) {
+			return nil
+		}
+
+		historyLimit := latest.Spec.HistoryLimit
+		if historyLimit <= 0 {
+			historyLimit = defaultHistoryLimit
+		}
+
+		latestCpy := latest.DeepCopy()
+		latestCpy.Status.LastScheduleTime = run.ScheduleTime
+		latestCpy.Status.Runs = append([]harvesterv1.VMPowerActionRun{run}, latestCpy.Status.Runs...)
+		if len(latestCpy.Status.Runs) > historyLimit {
+			latestCpy.Status.Runs = latestCpy.Status.Runs[:historyLimit]
+		}
+
+		if _, err = h.svmPowerActionClient.Update(latestCpy); err != nil {
+			return err
+		}
+		claimed = true
+		return nil
+	})
+	return claimed, err
+}
+
+// recordRun records the results of the claimed run, the run may be dropped by the history limit already
+func (h *svmPowerActionHandler) recordRun(svmPowerAction *harvesterv1.ScheduleVMPowerAction, run harvesterv1.VMPowerActionRun) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := h.svmPowerActionClient.Get(svmPowerAction.Namespace, svmPowerAction.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		latestCpy := latest.DeepCopy()
+		for i := range latestCpy.Status.Runs {
+			if latestCpy.Status.Runs[i].Timestamp == run.Timestamp {
+				latestCpy.Status.Runs[i] = run
+				_, err = h.svmPowerActionClient.Update(latestCpy)
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,110 @@
+package schedulevmpoweraction
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	fakegenerated "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newVM(name string, labels map[string]string) *kubevirtv1.VirtualMachine {
+	return &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels},
+	}
+}
+
+func TestHandler_getVMNames(t *testing.T) {
+	clientset := fakegenerated.NewSimpleClientset(
+		newVM("dev-1", map[string]string{"env": "dev"}),
+		newVM("dev-2", map[string]string{"env": "dev"}),
+		newVM("prod-1", map[string]string{"env": "prod"}),
+	)
+	h := &svmPowerActionHandler{
+		vmCache: fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+	}
+
+	tests := []struct {
+		name     string
+		spec     harvesterv1.ScheduleVMPowerActionSpec
+		expected []string
+	}{
+		{
+			name:     "selector",
+			spec:     harvesterv1.ScheduleVMPowerActionSpec{Selector: "env=dev"},
+			expected: []string{"dev-1", "dev-2"},
+		},
+		{
+			name:     "names are kept even if the VM doesn't exist",
+			spec:     harvesterv1.ScheduleVMPowerActionSpec{Names: []string{"prod-1", "missing", "prod-1"}},
+			expected: []string{"missing", "prod-1"},
+		},
+		{
+			name:     "selector and names",
+			spec:     harvesterv1.ScheduleVMPowerActionSpec{Selector: "env=dev", Names: []string{"dev-1", "prod-1"}},
+			expected: []string{"dev-1", "dev-2", "prod-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := h.getVMNames(&harvesterv1.ScheduleVMPowerAction{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "svmpa"},
+				Spec:       tt.spec,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestHandler_claimRun(t *testing.T) {
+	var runs []harvesterv1.VMPowerActionRun
+	for i := 0; i < 3; i++ {
+		runs = append(runs, harvesterv1.VMPowerActionRun{Timestamp: fmt.Sprintf("20261018.000%d", 3-i)})
+	}
+	svmPowerAction := &harvesterv1.ScheduleVMPowerAction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "svmpa"},
+		Spec:       harvesterv1.ScheduleVMPowerActionSpec{HistoryLimit: 3},
+		Status:     harvesterv1.ScheduleVMPowerActionStatus{Runs: runs},
+	}
+	clientset := fakegenerated.NewSimpleClientset(svmPowerAction)
+	h := &svmPowerActionHandler{
+		svmPowerActionClient: fakeclients.SVMPowerActionClient(clientset.HarvesterhciV1beta1().ScheduleVMPowerActions),
+	}
+
+	scheduleTime := metav1.NewTime(time.Date(2026, 10, 18, 0, 4, 0, 0, time.UTC))
+	run := harvesterv1.VMPowerActionRun{
+		Timestamp:    scheduleTime.Format(timeFormat),
+		ScheduleTime: &scheduleTime,
+	}
+	claimed, err := h.claimRun(svmPowerAction, run)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// the run is claimed once even if the cached object doesn't have it yet
+	claimed, err = h.claimRun(svmPowerAction, run)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	run.Succeeded = 1
+	run.Results = []harvesterv1.BulkActionResult{{Name: "vm", Succeeded: true}}
+	assert.NoError(t, h.recordRun(svmPowerAction, run))
+
+	latest, err := h.svmPowerActionClient.Get("default", "svmpa", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, scheduleTime.Unix(), latest.Status.LastScheduleTime.Unix())
+	var timestamps []string
+	for _, run := range latest.Status.Runs {
+		timestamps = append(timestamps, run.Timestamp)
+	}
+	assert.Equal(t, []string{"20261018.0004", "20261018.0003", "20261018.0002"}, timestamps)
+	assert.Equal(t, 1, latest.Status.Runs[0].Succeeded)
+	assert.Len(t, latest.Status.Runs[0].Results, 1)
+}
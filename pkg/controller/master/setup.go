@@ -9,7 +9,9 @@ import (
 	"github.com/harvester/harvester/pkg/config"
 	"github.com/harvester/harvester/pkg/controller/master/addon"
 	"github.com/harvester/harvester/pkg/controller/master/backup"
+	"github.com/harvester/harvester/pkg/controller/master/bootorder"
 	"github.com/harvester/harvester/pkg/controller/master/image"
+	"github.com/harvester/harvester/pkg/controller/master/imagereplication"
 	"github.com/harvester/harvester/pkg/controller/master/keypair"
 	"github.com/harvester/harvester/pkg/controller/master/kubevirt"
 	"github.com/harvester/harvester/pkg/controller/master/machine"
@@ -21,6 +23,7 @@ import (
 	"github.com/harvester/harvester/pkg/controller/master/rancher"
 	"github.com/harvester/harvester/pkg/controller/master/resourcequota"
 	"github.com/harvester/harvester/pkg/controller/master/schedulevmbackup"
+	"github.com/harvester/harvester/pkg/controller/master/schedulevmpoweraction"
 	"github.com/harvester/harvester/pkg/controller/master/setting"
 	"github.com/harvester/harvester/pkg/controller/master/storageclass"
 	"github.com/harvester/harvester/pkg/controller/master/storagenetwork"
@@ -29,6 +32,7 @@ import (
 	"github.com/harvester/harvester/pkg/controller/master/upgrade"
 	"github.com/harvester/harvester/pkg/controller/master/upgradelog"
 	"github.com/harvester/harvester/pkg/controller/master/virtualmachine"
+	"github.com/harvester/harvester/pkg/controller/master/vmbulkaction"
 	"github.com/harvester/harvester/pkg/controller/master/vmimagedownloader"
 	pvcbackup "github.com/harvester/harvester/pkg/controller/master/volumeremotebackup"
 )
@@ -41,8 +45,11 @@ var registerFuncs = []registerFunc{
 	backup.RegisterBackupBackingImage,
 	backup.RegisterBackupMetadata,
 	backup.RegisterBackupTarget,
+	backup.RegisterBackupVerification,
 	backup.RegisterRestore,
+	bootorder.Register,
 	image.Register,
+	imagereplication.Register,
 	keypair.Register,
 	kubevirt.Register,
 	machine.ControlPlaneRegister,
@@ -60,6 +67,7 @@ var registerFuncs = []registerFunc{
 	rancher.Register,
 	resourcequota.Register,
 	schedulevmbackup.Register,
+	schedulevmpoweraction.Register,
 	setting.Register,
 	storageclass.Register,
 	storagenetwork.Register,
@@ -68,6 +76,7 @@ var registerFuncs = []registerFunc{
 	upgrade.Register,
 	upgradelog.Register,
 	virtualmachine.Register,
+	vmbulkaction.Register,
 	vmimagedownloader.Register,
 	node.ConditionAnnotationRegister,
 	pvcbackup.Register,
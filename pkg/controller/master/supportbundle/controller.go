@@ -11,11 +11,13 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	settingctl "github.com/harvester/harvester/pkg/controller/master/setting"
 	"github.com/harvester/harvester/pkg/controller/master/supportbundle/types"
 	"github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
 	"github.com/harvester/harvester/pkg/settings"
 )
 
@@ -30,8 +32,12 @@ type Handler struct {
 	services                ctlcorev1.ServiceClient
 	settings                v1beta1.SettingClient
 	settingCache            v1beta1.SettingCache
+	configMaps              ctlcorev1.ConfigMapClient
+	vmiCache                ctlkubevirtv1.VirtualMachineInstanceCache
 	clientset               kubernetes.Interface
 
+	virtSubresourceRestClient rest.Interface
+
 	manager *Manager
 }
 
@@ -61,6 +67,10 @@ func (h *Handler) OnSupportBundleChanged(_ string, sb *harvesterv1.SupportBundle
 		}
 		logrus.Debugf("[%s] support bundle image: %+v", sb.Name, image)
 
+		if err := h.captureVMDiagnostics(sb); err != nil {
+			return h.setError(sb, fmt.Sprintf("fail to capture VM diagnostics for %s: %s", sb.Name, err))
+		}
+
 		err = h.manager.Create(sb, image.ImageName(), image.ImagePullPolicy)
 		if err != nil {
 			return h.setError(sb, fmt.Sprintf("fail to create manager for %s: %s", sb.Name, err))
@@ -20,6 +20,7 @@ import (
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/controller/master/supportbundle/types"
+	"github.com/harvester/harvester/pkg/ref"
 	"github.com/harvester/harvester/pkg/settings"
 	supportBundleUtil "github.com/harvester/harvester/pkg/util/supportbundle"
 )
@@ -192,6 +193,13 @@ func (m *Manager) getCollectNamespaces(sb *harvesterv1.SupportBundle) string {
 		}
 	}
 
+	// The namespaces of the VMs involved
+	for _, vm := range sb.Spec.VMs {
+		if namespace, _ := ref.Parse(vm); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
 	// De-duplicate namespaces to avoid redundancy
 	seen := make(map[string]bool)
 	uniqueNamespaces := make([]string, 0, len(namespaces))
@@ -5,7 +5,11 @@ import (
 	"net/http"
 	"time"
 
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
 	"github.com/harvester/harvester/pkg/config"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
 )
 
 const (
@@ -20,6 +24,17 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 	daemonsets := management.AppsFactory.Apps().V1().DaemonSet()
 	services := management.CoreFactory.Core().V1().Service()
 	settings := management.HarvesterFactory.Harvesterhci().V1beta1().Setting()
+	configMaps := management.CoreFactory.Core().V1().ConfigMap()
+	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
+
+	virtSubsrcConfig := rest.CopyConfig(management.RestConfig)
+	virtSubsrcConfig.GroupVersion = &k8sschema.GroupVersion{Group: "subresources.kubevirt.io", Version: "v1"}
+	virtSubsrcConfig.APIPath = "/apis"
+	virtSubsrcConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	virtSubresourceClient, err := rest.RESTClientFor(virtSubsrcConfig)
+	if err != nil {
+		return err
+	}
 
 	handler := &Handler{
 		supportBundles:          sbs,
@@ -31,7 +46,11 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 		services:                services,
 		settings:                settings,
 		settingCache:            settings.Cache(),
+		configMaps:              configMaps,
+		vmiCache:                vmis.Cache(),
 		clientset:               management.ClientSet,
+
+		virtSubresourceRestClient: virtSubresourceClient,
 		manager: &Manager{
 			deployments: deployments,
 			nodeCache:   nodeCache,
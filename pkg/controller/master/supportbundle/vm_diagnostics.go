@@ -0,0 +1,118 @@
+package supportbundle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/supportbundle/types"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util/virtualmachineinstance"
+)
+
+const (
+	// vmDiagnosticsSizeLimit keeps the ConfigMap under the 1MiB limit of objects
+	vmDiagnosticsSizeLimit = 900 * 1024
+)
+
+func getVMDiagnosticsName(sb *harvesterv1.SupportBundle) string {
+	return fmt.Sprintf("supportbundle-%s-vm-diagnostics", sb.Name)
+}
+
+// captureVMDiagnostics captures the screenshots and the guest serial console logs of the VMs involved into a
+// ConfigMap in the namespace of the support bundle, which is collected into the support bundle. A VM failing to
+// be captured doesn't fail the support bundle, the error is recorded instead.
+func (h *Handler) captureVMDiagnostics(sb *harvesterv1.SupportBundle) error {
+	if len(sb.Spec.VMs) == 0 {
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getVMDiagnosticsName(sb),
+			Namespace: sb.Namespace,
+			Labels: map[string]string{
+				types.SupportBundleLabelKey: sb.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					Name:       sb.Name,
+					Kind:       sb.Kind,
+					UID:        sb.UID,
+					APIVersion: sb.APIVersion,
+				},
+			},
+		},
+		Data:       map[string]string{},
+		BinaryData: map[string][]byte{},
+	}
+
+	size := 0
+	for _, vm := range sb.Spec.VMs {
+		namespace, name := ref.Parse(vm)
+		prefix := fmt.Sprintf("%s_%s", namespace, name)
+
+		var errs []string
+		screenshot, log, err := h.getVMDiagnostics(namespace, name)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		if screenshot != nil {
+			if size+len(screenshot) > vmDiagnosticsSizeLimit {
+				errs = append(errs, "screenshot: skipped for the size limit")
+			} else {
+				cm.BinaryData[prefix+".png"] = screenshot
+				size += len(screenshot)
+			}
+		}
+		if log != nil {
+			if size+len(log) > vmDiagnosticsSizeLimit {
+				errs = append(errs, "serial console log: skipped for the size limit")
+			} else {
+				cm.BinaryData[prefix+".serial.log"] = log
+				size += len(log)
+			}
+		}
+		if len(errs) != 0 {
+			cm.Data[prefix+".error"] = strings.Join(errs, "\n")
+		}
+	}
+
+	if _, err := h.configMaps.Create(cm); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) getVMDiagnostics(namespace, name string) (screenshot []byte, log []byte, err error) {
+	vmi, err := h.vmiCache.Get(namespace, name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("VM is not running")
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	var errs []string
+	ctx := context.Background()
+	if screenshot, err = virtualmachineinstance.Screenshot(ctx, h.virtSubresourceRestClient, namespace, name); err != nil {
+		errs = append(errs, fmt.Sprintf("screenshot: %v", err))
+	}
+
+	pod, err := virtualmachineinstance.GetLauncherPod(vmi, h.podCache)
+	if err == nil {
+		log, err = virtualmachineinstance.SerialConsoleLog(ctx, h.clientset, pod, virtualmachineinstance.DefaultSerialConsoleLogBytes)
+	}
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("serial console log: %v", err))
+	}
+
+	if len(errs) != 0 {
+		return screenshot, log, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return screenshot, log, nil
+}
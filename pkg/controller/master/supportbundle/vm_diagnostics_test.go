@@ -0,0 +1,45 @@
+package supportbundle
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/supportbundle/types"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func Test_captureVMDiagnostics(t *testing.T) {
+	sb := &harvesterv1.SupportBundle{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "harvester-system", Name: "bundle"},
+		Spec: harvesterv1.SupportBundleSpec{
+			Description: "guest hangs",
+			VMs:         []string{"dev/stopped"},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset()
+	h := &Handler{
+		configMaps: fakeclients.ConfigmapClient(clientset.CoreV1().ConfigMaps),
+		vmiCache:   fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+	}
+
+	assert.NoError(t, h.captureVMDiagnostics(sb))
+	// capturing again doesn't fail on the existing ConfigMap
+	assert.NoError(t, h.captureVMDiagnostics(sb))
+
+	cm, err := clientset.CoreV1().ConfigMaps(sb.Namespace).Get(context.TODO(), getVMDiagnosticsName(sb), metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, sb.Name, cm.Labels[types.SupportBundleLabelKey])
+	assert.Equal(t, map[string]string{"dev_stopped.error": "VM is not running"}, cm.Data)
+	assert.Empty(t, cm.BinaryData)
+
+	// the namespaces of the VMs are collected
+	m := &Manager{}
+	assert.Contains(t, strings.Split(m.getCollectNamespaces(sb), ","), "dev")
+}
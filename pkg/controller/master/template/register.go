@@ -2,20 +2,48 @@ package template
 
 import (
 	"context"
+	"net/http"
+
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
 
 	"github.com/harvester/harvester/pkg/config"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
+	"github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/util/templateversion"
 )
 
 const (
 	templateControllerAgentName        = "template-controller"
 	templateVersionControllerAgentName = "template-version-controller"
 	vmImageControllerAgentName         = "vm-image-in-template-controller"
+	templateGeneralizeControllerName   = "template-version-generalize-controller"
 )
 
 func Register(ctx context.Context, management *config.Management, _ config.Options) error {
 	templates := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplate()
 	templateVersions := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplateVersion()
 	vmImages := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage()
+	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
+	pvcs := management.CoreFactory.Core().V1().PersistentVolumeClaim()
+	snapshots := management.SnapshotFactory.Snapshot().V1().VolumeSnapshot()
+	jobs := management.BatchFactory.Batch().V1().Job()
+	storageClasses := management.StorageFactory.Storage().V1().StorageClass()
+
+	vmiOperator, err := common.GetVMIOperator(vmImages, vmImages.Cache(), storageClasses.Cache(), http.Client{})
+	if err != nil {
+		return err
+	}
+
+	virtSubsrcConfig := rest.CopyConfig(management.RestConfig)
+	virtSubsrcConfig.GroupVersion = &k8sschema.GroupVersion{Group: "subresources.kubevirt.io", Version: "v1"}
+	virtSubsrcConfig.APIPath = "/apis"
+	virtSubsrcConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	virtSubresourceClient, err := rest.RESTClientFor(virtSubsrcConfig)
+	if err != nil {
+		return err
+	}
 
 	templateController := &templateHandler{
 		templates:            templates,
@@ -36,8 +64,26 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 		templateVersionController: templateVersions,
 	}
 
+	generalizeController := &templateVersionGeneralizeHandler{
+		templateVersions:          templateVersions,
+		vmiCache:                  vmis.Cache(),
+		pvcClient:                 pvcs,
+		pvcCache:                  pvcs.Cache(),
+		snapshotClient:            snapshots,
+		snapshotCache:             snapshots.Cache(),
+		jobClient:                 jobs,
+		jobCache:                  jobs.Cache(),
+		vmImageCache:              vmImages.Cache(),
+		storageClassCache:         storageClasses.Cache(),
+		exporter:                  templateversion.NewExporter(pvcs.Cache(), storageClasses.Cache(), vmImages, vmImages.Cache(), vmiOperator),
+		virtSubresourceRestClient: virtSubresourceClient,
+		recorder:                  management.NewRecorder(templateGeneralizeControllerName, "", ""),
+	}
+
 	templates.OnChange(ctx, templateControllerAgentName, templateController.OnChanged)
 	templateVersions.OnChange(ctx, templateVersionControllerAgentName, templateVersionController.OnChanged)
 	vmImages.OnChange(ctx, vmImageControllerAgentName, vmImageController.OnChanged)
+	templateVersions.OnChange(ctx, templateGeneralizeControllerName, generalizeController.OnChanged)
+	relatedresource.Watch(ctx, templateGeneralizeControllerName, generalizeController.ReconcileOwnerTemplateVersion, templateVersions, snapshots, pvcs, jobs)
 	return nil
 }
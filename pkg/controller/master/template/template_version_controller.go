@@ -12,6 +12,7 @@ import (
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/ref"
 	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/templateversion"
 )
 
 const (
@@ -100,6 +101,15 @@ func (h *templateVersionHandler) OnChanged(_ string, tv *harvesterv1.VirtualMach
 }
 
 func (h *templateVersionHandler) isVMImagesReady(tv *harvesterv1.VirtualMachineTemplateVersion) (bool, error) {
+	// the VM images of the generalized template version are created by the generalize controller
+	generalize, err := templateversion.UnmarshalGeneralize(tv.Annotations[util.AnnotationTemplateGeneralize])
+	if err != nil {
+		return false, fmt.Errorf("can't unmarshal %s annotation, err: %w", util.AnnotationTemplateGeneralize, err)
+	}
+	if generalize != nil && generalize.Phase != templateversion.GeneralizeCompleted {
+		return false, nil
+	}
+
 	volumeClaimTemplatesStr, ok := tv.Spec.VM.ObjectMeta.Annotations[util.AnnotationVolumeClaimTemplates]
 	if !ok || volumeClaimTemplatesStr == "" {
 		return true, nil
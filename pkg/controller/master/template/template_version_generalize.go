@@ -0,0 +1,602 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	ctlbatchv1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/batch/v1"
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	ctlstoragev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/storage/v1"
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/backup"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	ctlsnapshotv1 "github.com/harvester/harvester/pkg/generated/controllers/snapshot.storage.k8s.io/v1"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/templateversion"
+)
+
+const (
+	kindVirtualMachineTemplateVersion = "VirtualMachineTemplateVersion"
+
+	reasonTemplateVersionGeneralized      = "TemplateVersionGeneralized"
+	reasonTemplateVersionGeneralizeFailed = "TemplateVersionGeneralizeFailed"
+
+	// the guest is thawed by KubeVirt after the timeout in case the snapshots take too long
+	generalizeUnfreezeTimeout = time.Minute
+	sysprepContainerName      = "sysprep"
+	kvmDeviceResourceName     = "devices.kubevirt.io/kvm"
+)
+
+// templateVersionGeneralizeHandler creates the VM images of the template versions created with the generalize
+// option. The guest of the running VM is frozen while the disks are snapshotted, the copies of the disks restored
+// from the snapshots are cleaned up by virt-sysprep if requested, then they're exported into the VM images.
+// The progress is kept in the harvesterhci.io/templateGeneralize annotation of the template version.
+type templateVersionGeneralizeHandler struct {
+	templateVersions          ctlharvesterv1.VirtualMachineTemplateVersionClient
+	vmiCache                  ctlkubevirtv1.VirtualMachineInstanceCache
+	pvcClient                 ctlcorev1.PersistentVolumeClaimClient
+	pvcCache                  ctlcorev1.PersistentVolumeClaimCache
+	snapshotClient            ctlsnapshotv1.VolumeSnapshotClient
+	snapshotCache             ctlsnapshotv1.VolumeSnapshotCache
+	jobClient                 ctlbatchv1.JobClient
+	jobCache                  ctlbatchv1.JobCache
+	vmImageCache              ctlharvesterv1.VirtualMachineImageCache
+	storageClassCache         ctlstoragev1.StorageClassCache
+	exporter                  *templateversion.Exporter
+	virtSubresourceRestClient rest.Interface
+	recorder                  record.EventRecorder
+}
+
+// sourceVolume is a PVC volume of the template version VM, the index is the index of the volume in the VM spec
+type sourceVolume struct {
+	index     int
+	claimName string
+}
+
+func (h *templateVersionGeneralizeHandler) OnChanged(_ string, tv *harvesterv1.VirtualMachineTemplateVersion) (*harvesterv1.VirtualMachineTemplateVersion, error) {
+	if tv == nil || tv.DeletionTimestamp != nil {
+		return tv, nil
+	}
+
+	generalize, err := templateversion.UnmarshalGeneralize(tv.Annotations[util.AnnotationTemplateGeneralize])
+	if err != nil || generalize == nil || generalize.IsFinished() {
+		return tv, nil
+	}
+
+	tvCopy := tv.DeepCopy()
+	next := *generalize
+	volumes := getSourceVolumes(tv)
+	switch generalize.Phase {
+	case templateversion.GeneralizePending:
+		err = h.snapshot(tvCopy, volumes, &next)
+	case templateversion.GeneralizeSnapshotting:
+		err = h.waitForSnapshots(tvCopy, volumes, &next)
+	case templateversion.GeneralizeCopying:
+		err = h.waitForCopies(tvCopy, volumes, &next)
+	case templateversion.GeneralizeSysprepping:
+		err = h.waitForSysprep(tvCopy, &next)
+	case templateversion.GeneralizeExporting:
+		err = h.export(tvCopy, volumes, &next)
+	default:
+		next.Phase = templateversion.GeneralizeFailed
+		next.Message = fmt.Sprintf("unknown phase %s", generalize.Phase)
+	}
+	if err != nil {
+		return tv, err
+	}
+
+	annotation, err := templateversion.MarshalGeneralize(&next)
+	if err != nil {
+		return tv, err
+	}
+	tvCopy.Annotations[util.AnnotationTemplateGeneralize] = annotation
+	if reflect.DeepEqual(tv, tvCopy) {
+		return tv, nil
+	}
+	updated, err := h.templateVersions.Update(tvCopy)
+	if err != nil {
+		return tv, err
+	}
+
+	switch next.Phase {
+	case templateversion.GeneralizeCompleted:
+		h.recorder.Eventf(updated, corev1.EventTypeNormal, reasonTemplateVersionGeneralized, "The disks of VM %s are exported into the VM images", next.VM)
+		return updated, h.cleanup(updated, volumes, &next)
+	case templateversion.GeneralizeFailed:
+		h.recorder.Eventf(updated, corev1.EventTypeWarning, reasonTemplateVersionGeneralizeFailed, "Failed to generalize the disks of VM %s: %s", next.VM, next.Message)
+		return updated, h.cleanup(updated, volumes, &next)
+	}
+	return updated, nil
+}
+
+// snapshot freezes the guest of the running VM and takes the snapshots of the disks
+func (h *templateVersionGeneralizeHandler) snapshot(tv *harvesterv1.VirtualMachineTemplateVersion, volumes []sourceVolume, generalize *templateversion.Generalize) error {
+	vmi, err := h.vmiCache.Get(tv.Namespace, generalize.VM)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && vmi.Status.Phase == kubevirtv1.Running {
+		if !backup.IsGuestAgentConnected(vmi) {
+			setGeneralizeFailed(generalize, fmt.Sprintf("the guest agent of VM %s isn't connected", generalize.VM))
+			return nil
+		}
+		if err := h.freeze(vmi); err != nil {
+			return fmt.Errorf("failed to freeze the guest of VM %s: %w", generalize.VM, err)
+		}
+		generalize.FrozenAt = ptr.To(metav1.Now())
+	}
+
+	for _, volume := range volumes {
+		if err := h.createSnapshot(tv, volume); apierrors.IsNotFound(err) {
+			setGeneralizeFailed(generalize, err.Error())
+			return nil
+		} else if err != nil {
+			h.thaw(tv.Namespace, generalize)
+			return err
+		}
+	}
+
+	generalize.Phase = templateversion.GeneralizeSnapshotting
+	generalize.Message = ""
+	return nil
+}
+
+// waitForSnapshots thaws the guest once the snapshots are taken, and restores the copies of the disks from the
+// snapshots once they're ready to use.
+func (h *templateVersionGeneralizeHandler) waitForSnapshots(tv *harvesterv1.VirtualMachineTemplateVersion, volumes []sourceVolume, generalize *templateversion.Generalize) error {
+	taken, ready := true, true
+	snapshots := make(map[int]*snapshotv1.VolumeSnapshot, len(volumes))
+	for _, volume := range volumes {
+		snapshot, err := h.snapshotCache.Get(tv.Namespace, templateversion.SnapshotName(tv.Name, volume.index))
+		if apierrors.IsNotFound(err) {
+			setGeneralizeFailed(generalize, err.Error())
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if snapshot.Status != nil && snapshot.Status.Error != nil && snapshot.Status.Error.Message != nil {
+			setGeneralizeFailed(generalize, fmt.Sprintf("failed to snapshot PVC %s: %s", volume.claimName, *snapshot.Status.Error.Message))
+			return nil
+		}
+		if snapshot.Status == nil || snapshot.Status.CreationTime == nil {
+			taken = false
+		}
+		if snapshot.Status == nil || !ptr.Deref(snapshot.Status.ReadyToUse, false) {
+			ready = false
+		}
+		snapshots[volume.index] = snapshot
+	}
+
+	if taken && generalize.FrozenAt != nil {
+		h.thaw(tv.Namespace, generalize)
+	}
+	if !ready {
+		generalize.Message = "Waiting for the snapshots of the disks to be ready"
+		return nil
+	}
+
+	for _, volume := range volumes {
+		if err := h.createCopy(tv, volume, snapshots[volume.index]); apierrors.IsNotFound(err) {
+			setGeneralizeFailed(generalize, err.Error())
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	generalize.Phase = templateversion.GeneralizeCopying
+	generalize.Message = ""
+	return nil
+}
+
+// waitForCopies starts the sysprep job on the copies of the disks if requested, otherwise the copies are exported
+// once they're bound. The sysprep job doesn't have to wait, the pod is only scheduled when the copies are bound.
+func (h *templateVersionGeneralizeHandler) waitForCopies(tv *harvesterv1.VirtualMachineTemplateVersion, volumes []sourceVolume, generalize *templateversion.Generalize) error {
+	if generalize.Sysprep {
+		if err := h.createSysprepJob(tv, volumes); apierrors.IsNotFound(err) {
+			setGeneralizeFailed(generalize, err.Error())
+			return nil
+		} else if err != nil {
+			return err
+		}
+		generalize.Phase = templateversion.GeneralizeSysprepping
+		generalize.Message = ""
+		return nil
+	}
+
+	for _, volume := range volumes {
+		copyName := templateversion.CopyPVCName(tv.Name, volume.index)
+		pvc, err := h.pvcCache.Get(tv.Namespace, copyName)
+		if apierrors.IsNotFound(err) {
+			setGeneralizeFailed(generalize, err.Error())
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if pvc.Status.Phase != corev1.ClaimBound {
+			generalize.Message = fmt.Sprintf("Waiting for the copy %s of PVC %s to be bound", copyName, volume.claimName)
+			return nil
+		}
+	}
+	generalize.Phase = templateversion.GeneralizeExporting
+	generalize.Message = ""
+	return nil
+}
+
+func (h *templateVersionGeneralizeHandler) waitForSysprep(tv *harvesterv1.VirtualMachineTemplateVersion, generalize *templateversion.Generalize) error {
+	job, err := h.jobCache.Get(tv.Namespace, templateversion.SysprepJobName(tv.Name))
+	if apierrors.IsNotFound(err) {
+		setGeneralizeFailed(generalize, err.Error())
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if job.Status.Succeeded > 0 {
+		generalize.Phase = templateversion.GeneralizeExporting
+		generalize.Message = ""
+		return nil
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			setGeneralizeFailed(generalize, fmt.Sprintf("sysprep job %s failed: %s", job.Name, condition.Message))
+			return nil
+		}
+	}
+	generalize.Message = fmt.Sprintf("Waiting for sysprep job %s to complete", job.Name)
+	return nil
+}
+
+// export creates the VM images from the copies of the disks, and replaces the PVCs of the template version VM with
+// the volume claim templates restoring the disks from the images. It's completed once the images are imported.
+func (h *templateVersionGeneralizeHandler) export(tv *harvesterv1.VirtualMachineTemplateVersion, volumes []sourceVolume, generalize *templateversion.Generalize) error {
+	owner := getTemplateVersionOwnerReference(tv)
+	// the PVCs of the template version VM are the source PVCs until they're replaced with the volume claim templates
+	sourceClaims := tv.Spec.VM.ObjectMeta.Annotations[util.AnnotationVolumeClaimTemplates] == ""
+	pvcMap := make(map[string]corev1.PersistentVolumeClaim, len(volumes))
+	vmImageMap := make(map[string]harvesterv1.VirtualMachineImage, len(volumes))
+	imported := true
+	for _, volume := range volumes {
+		if sourceClaims {
+			source, err := h.pvcCache.Get(tv.Namespace, volume.claimName)
+			if apierrors.IsNotFound(err) {
+				setGeneralizeFailed(generalize, err.Error())
+				return nil
+			} else if err != nil {
+				return err
+			}
+			pvcMap[source.Name] = *source
+		}
+
+		vmImageName := templateversion.VMImageName(tv.Name, volume.index)
+		vmImage, err := h.vmImageCache.Get(tv.Namespace, vmImageName)
+		if apierrors.IsNotFound(err) {
+			vmImage, err = h.exporter.CreateVMImage(tv.Namespace, vmImageName, templateversion.CopyPVCName(tv.Name, volume.index), owner)
+		}
+		if err != nil {
+			return err
+		}
+		if harvesterv1.ImageRetryLimitExceeded.IsTrue(vmImage) {
+			setGeneralizeFailed(generalize, fmt.Sprintf("failed to export VM image %s: %s", vmImageName, harvesterv1.ImageRetryLimitExceeded.GetMessage(vmImage)))
+			return nil
+		}
+		if !harvesterv1.ImageImported.IsTrue(vmImage) {
+			imported = false
+		}
+		vmImageMap[vmImageName] = *vmImage
+	}
+
+	if sourceClaims {
+		if err := h.exporter.SetVolumeClaimTemplates(tv, pvcMap, vmImageMap); err != nil {
+			return err
+		}
+	}
+	if !imported {
+		generalize.Message = "Waiting for the VM images to be imported"
+		return nil
+	}
+	generalize.Phase = templateversion.GeneralizeCompleted
+	generalize.Message = ""
+	return nil
+}
+
+// cleanup thaws the guest if it's still frozen, and removes the snapshots, the copies of the disks and the sysprep
+// job which aren't needed once the generalization is finished.
+func (h *templateVersionGeneralizeHandler) cleanup(tv *harvesterv1.VirtualMachineTemplateVersion, volumes []sourceVolume, generalize *templateversion.Generalize) error {
+	if generalize.FrozenAt != nil {
+		h.thaw(tv.Namespace, generalize)
+	}
+
+	jobName := templateversion.SysprepJobName(tv.Name)
+	if err := h.jobClient.Delete(tv.Namespace, jobName, &metav1.DeleteOptions{PropagationPolicy: ptr.To(metav1.DeletePropagationBackground)}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	for _, volume := range volumes {
+		copyName := templateversion.CopyPVCName(tv.Name, volume.index)
+		if err := h.pvcClient.Delete(tv.Namespace, copyName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		snapshotName := templateversion.SnapshotName(tv.Name, volume.index)
+		if err := h.snapshotClient.Delete(tv.Namespace, snapshotName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *templateVersionGeneralizeHandler) createSnapshot(tv *harvesterv1.VirtualMachineTemplateVersion, volume sourceVolume) error {
+	name := templateversion.SnapshotName(tv.Name, volume.index)
+	if _, err := h.snapshotCache.Get(tv.Namespace, name); err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	pvc, err := h.pvcCache.Get(tv.Namespace, volume.claimName)
+	if err != nil {
+		return err
+	}
+	provisioner := util.GetProvisionedPVCProvisioner(pvc, h.storageClassCache)
+	csiDriverInfo, err := settings.GetCSIDriverInfo(provisioner)
+	if err != nil {
+		return err
+	}
+
+	snapshot := &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: tv.Namespace,
+			Annotations: map[string]string{
+				util.AnnotationStorageProvisioner: provisioner,
+			},
+			OwnerReferences: []metav1.OwnerReference{getTemplateVersionOwnerReference(tv)},
+		},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			Source: snapshotv1.VolumeSnapshotSource{
+				PersistentVolumeClaimName: ptr.To(pvc.Name),
+			},
+			VolumeSnapshotClassName: ptr.To(csiDriverInfo.VolumeSnapshotClassName),
+		},
+	}
+	if pvc.Spec.StorageClassName != nil {
+		snapshot.Annotations[util.AnnotationStorageClassName] = *pvc.Spec.StorageClassName
+	}
+	if imageID := pvc.Annotations[util.AnnotationImageID]; imageID != "" {
+		snapshot.Annotations[util.AnnotationImageID] = imageID
+	}
+	_, err = h.snapshotClient.Create(snapshot)
+	return err
+}
+
+// createCopy restores the copy of the disk from the snapshot, with the spec of the source PVC
+func (h *templateVersionGeneralizeHandler) createCopy(tv *harvesterv1.VirtualMachineTemplateVersion, volume sourceVolume, snapshot *snapshotv1.VolumeSnapshot) error {
+	name := templateversion.CopyPVCName(tv.Name, volume.index)
+	if _, err := h.pvcCache.Get(tv.Namespace, name); err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	source, err := h.pvcCache.Get(tv.Namespace, volume.claimName)
+	if err != nil {
+		return err
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       tv.Namespace,
+			Annotations:     map[string]string{},
+			OwnerReferences: []metav1.OwnerReference{getTemplateVersionOwnerReference(tv)},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      source.Spec.AccessModes,
+			Resources:        *source.Spec.Resources.DeepCopy(),
+			StorageClassName: source.Spec.StorageClassName,
+			VolumeMode:       source.Spec.VolumeMode,
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To(snapshotv1.GroupName),
+				Kind:     "VolumeSnapshot",
+				Name:     snapshot.Name,
+			},
+		},
+	}
+	if imageID := source.Annotations[util.AnnotationImageID]; imageID != "" {
+		pvc.Annotations[util.AnnotationImageID] = imageID
+	}
+	_, err = h.pvcClient.Create(pvc)
+	return err
+}
+
+func (h *templateVersionGeneralizeHandler) createSysprepJob(tv *harvesterv1.VirtualMachineTemplateVersion, volumes []sourceVolume) error {
+	name := templateversion.SysprepJobName(tv.Name)
+	if _, err := h.jobCache.Get(tv.Namespace, name); err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	volumeModes := make(map[int]corev1.PersistentVolumeMode, len(volumes))
+	for _, volume := range volumes {
+		source, err := h.pvcCache.Get(tv.Namespace, volume.claimName)
+		if err != nil {
+			return err
+		}
+		volumeModes[volume.index] = ptr.Deref(source.Spec.VolumeMode, corev1.PersistentVolumeFilesystem)
+	}
+
+	_, err := h.jobClient.Create(newSysprepJob(tv, volumes, volumeModes, settings.VMTemplateSysprepImage.Get()))
+	return err
+}
+
+// newSysprepJob returns the job running virt-sysprep on the copies of the disks. The block mode copies are attached
+// as devices, the disks of the filesystem mode copies are the disk.img files like KubeVirt lays them out.
+func newSysprepJob(tv *harvesterv1.VirtualMachineTemplateVersion, volumes []sourceVolume, volumeModes map[int]corev1.PersistentVolumeMode, image string) *batchv1.Job {
+	container := corev1.Container{
+		Name:            sysprepContainerName,
+		Image:           image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"virt-sysprep"},
+		Args:            []string{"--format", "raw"},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "LIBGUESTFS_BACKEND",
+				Value: "direct",
+			},
+		},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				kvmDeviceResourceName: resource.MustParse("1"),
+			},
+		},
+		// the block devices of the copies are only writable by root
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser: ptr.To(int64(0)),
+		},
+	}
+
+	var podVolumes []corev1.Volume
+	for _, volume := range volumes {
+		volumeName := fmt.Sprintf("disk-%d", volume.index)
+		podVolumes = append(podVolumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: templateversion.CopyPVCName(tv.Name, volume.index),
+				},
+			},
+		})
+
+		path := fmt.Sprintf("/%s", volumeName)
+		if volumeModes[volume.index] == corev1.PersistentVolumeBlock {
+			container.VolumeDevices = append(container.VolumeDevices, corev1.VolumeDevice{
+				Name:       volumeName,
+				DevicePath: path,
+			})
+		} else {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: path,
+			})
+			path = fmt.Sprintf("%s/disk.img", path)
+		}
+		container.Args = append(container.Args, "-a", path)
+	}
+	// cloud-init runs again on the VMs created from the template version
+	container.Args = append(container.Args, "--delete", "/var/lib/cloud/*")
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            templateversion.SysprepJobName(tv.Name),
+			Namespace:       tv.Namespace,
+			OwnerReferences: []metav1.OwnerReference{getTemplateVersionOwnerReference(tv)},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers:    []corev1.Container{container},
+					Volumes:       podVolumes,
+				},
+			},
+		},
+	}
+}
+
+func (h *templateVersionGeneralizeHandler) freeze(vmi *kubevirtv1.VirtualMachineInstance) error {
+	body, err := json.Marshal(kubevirtv1.FreezeUnfreezeTimeout{UnfreezeTimeout: &metav1.Duration{
+		Duration: generalizeUnfreezeTimeout,
+	}})
+	if err != nil {
+		return err
+	}
+
+	return h.virtSubresourceRestClient.Put().
+		Namespace(vmi.Namespace).
+		Resource("virtualmachineinstances").
+		Name(vmi.Name).
+		SubResource("freeze").
+		Body(body).
+		Do(context.Background()).
+		Error()
+}
+
+// thaw unfreezes the guest, the error is only logged because KubeVirt thaws the guest after the unfreeze timeout
+func (h *templateVersionGeneralizeHandler) thaw(namespace string, generalize *templateversion.Generalize) {
+	err := h.virtSubresourceRestClient.Put().
+		Namespace(namespace).
+		Resource("virtualmachineinstances").
+		Name(generalize.VM).
+		SubResource("unfreeze").
+		Do(context.Background()).
+		Error()
+	if err != nil && !apierrors.IsNotFound(err) {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"namespace": namespace,
+			"name":      generalize.VM,
+		}).Warn("Failed to thaw the guest of the VM")
+	}
+	generalize.FrozenAt = nil
+}
+
+// ReconcileOwnerTemplateVersion enqueues the template version owning the snapshots, the copies of the disks and the
+// sysprep job of the generalization
+func (h *templateVersionGeneralizeHandler) ReconcileOwnerTemplateVersion(_ string, _ string, obj runtime.Object) ([]relatedresource.Key, error) {
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil
+	}
+	for _, ownerReference := range object.GetOwnerReferences() {
+		if ownerReference.Kind == kindVirtualMachineTemplateVersion {
+			return []relatedresource.Key{
+				{
+					Namespace: object.GetNamespace(),
+					Name:      ownerReference.Name,
+				},
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func getSourceVolumes(tv *harvesterv1.VirtualMachineTemplateVersion) []sourceVolume {
+	if tv.Spec.VM.Spec.Template == nil {
+		return nil
+	}
+
+	var volumes []sourceVolume
+	for index, volume := range tv.Spec.VM.Spec.Template.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			volumes = append(volumes, sourceVolume{index: index, claimName: volume.PersistentVolumeClaim.ClaimName})
+		}
+	}
+	return volumes
+}
+
+func getTemplateVersionOwnerReference(tv *harvesterv1.VirtualMachineTemplateVersion) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: harvesterv1.SchemeGroupVersion.String(),
+		Kind:       kindVirtualMachineTemplateVersion,
+		Name:       tv.Name,
+		UID:        tv.UID,
+	}
+}
+
+func setGeneralizeFailed(generalize *templateversion.Generalize, message string) {
+	generalize.Phase = templateversion.GeneralizeFailed
+	generalize.Message = message
+}
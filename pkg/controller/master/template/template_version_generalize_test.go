@@ -0,0 +1,198 @@
+package template
+
+import (
+	"testing"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+	"github.com/harvester/harvester/pkg/util/templateversion"
+)
+
+func TestTemplateVersionGeneralizeHandler_OnChanged(t *testing.T) {
+	const (
+		namespace = "default"
+		tvName    = "template-abcdef"
+		vmName    = "vm"
+		claimName = "vm-disk-0"
+	)
+	newTemplateVersion := func(sysprep bool) *harvesterv1.VirtualMachineTemplateVersion {
+		generalize, _ := templateversion.MarshalGeneralize(&templateversion.Generalize{
+			VM:      vmName,
+			Sysprep: sysprep,
+			Phase:   templateversion.GeneralizePending,
+		})
+		return &harvesterv1.VirtualMachineTemplateVersion{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        tvName,
+				Annotations: map[string]string{util.AnnotationTemplateGeneralize: generalize},
+			},
+			Spec: harvesterv1.VirtualMachineTemplateVersionSpec{
+				VM: harvesterv1.VirtualMachineSourceSpec{
+					Spec: kubevirtv1.VirtualMachineSpec{
+						Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+							Spec: kubevirtv1.VirtualMachineInstanceSpec{
+								Volumes: []kubevirtv1.Volume{
+									{
+										Name: "cloudinitdisk",
+										VolumeSource: kubevirtv1.VolumeSource{
+											CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{},
+										},
+									},
+									{
+										Name: "disk-0",
+										VolumeSource: kubevirtv1.VolumeSource{
+											PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+												PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      claimName,
+			Annotations: map[string]string{
+				util.AnnStorageProvisioner: util.CSIProvisionerLonghorn,
+				util.AnnotationImageID:     "default/image",
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			StorageClassName: ptr.To("longhorn-image"),
+			VolumeMode:       ptr.To(corev1.PersistentVolumeBlock),
+		},
+	}
+	getGeneralize := func(t *testing.T, tv *harvesterv1.VirtualMachineTemplateVersion) *templateversion.Generalize {
+		generalize, err := templateversion.UnmarshalGeneralize(tv.Annotations[util.AnnotationTemplateGeneralize])
+		assert.NoError(t, err)
+		return generalize
+	}
+
+	t.Run("generalize the disks of a stopped VM with sysprep", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newTemplateVersion(true), pvc)
+		h := &templateVersionGeneralizeHandler{
+			templateVersions: fakeTemplateVersionClient(clientset.HarvesterhciV1beta1().VirtualMachineTemplateVersions),
+			vmiCache:         fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+			pvcClient:        fakeclients.PersistentVolumeClaimClient(clientset.CoreV1().PersistentVolumeClaims),
+			pvcCache:         fakeclients.PersistentVolumeClaimCache(clientset.CoreV1().PersistentVolumeClaims),
+			snapshotClient:   fakeclients.VolumeSnapshotClient(clientset.SnapshotV1().VolumeSnapshots),
+			snapshotCache:    fakeclients.VolumeSnapshotCache(clientset.SnapshotV1().VolumeSnapshots),
+			jobClient:        fakeclients.JobClient(clientset.BatchV1().Jobs),
+			jobCache:         fakeclients.JobCache(clientset.BatchV1().Jobs),
+			recorder:         record.NewFakeRecorder(10),
+		}
+		tv := newTemplateVersion(true)
+
+		tv, err := h.OnChanged("", tv)
+		assert.NoError(t, err)
+		assert.Equal(t, templateversion.GeneralizeSnapshotting, getGeneralize(t, tv).Phase)
+		snapshot, err := clientset.SnapshotV1().VolumeSnapshots(namespace).Get(t.Context(), templateversion.SnapshotName(tvName, 1), metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, claimName, *snapshot.Spec.Source.PersistentVolumeClaimName)
+		assert.Equal(t, "longhorn-snapshot", *snapshot.Spec.VolumeSnapshotClassName)
+
+		// the copies are restored once the snapshots are ready to use
+		tv, err = h.OnChanged("", tv)
+		assert.NoError(t, err)
+		assert.Equal(t, templateversion.GeneralizeSnapshotting, getGeneralize(t, tv).Phase)
+		snapshot.Status = &snapshotv1.VolumeSnapshotStatus{CreationTime: ptr.To(metav1.Now()), ReadyToUse: ptr.To(true)}
+		_, err = clientset.SnapshotV1().VolumeSnapshots(namespace).Update(t.Context(), snapshot, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		tv, err = h.OnChanged("", tv)
+		assert.NoError(t, err)
+		assert.Equal(t, templateversion.GeneralizeCopying, getGeneralize(t, tv).Phase)
+		copyPVC, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(t.Context(), templateversion.CopyPVCName(tvName, 1), metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, snapshot.Name, copyPVC.Spec.DataSource.Name)
+		assert.Equal(t, pvc.Spec.StorageClassName, copyPVC.Spec.StorageClassName)
+		assert.Equal(t, "default/image", copyPVC.Annotations[util.AnnotationImageID])
+
+		tv, err = h.OnChanged("", tv)
+		assert.NoError(t, err)
+		assert.Equal(t, templateversion.GeneralizeSysprepping, getGeneralize(t, tv).Phase)
+		job, err := clientset.BatchV1().Jobs(namespace).Get(t.Context(), templateversion.SysprepJobName(tvName), metav1.GetOptions{})
+		assert.NoError(t, err)
+		job.Status.Succeeded = 1
+		_, err = clientset.BatchV1().Jobs(namespace).Update(t.Context(), job, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		tv, err = h.OnChanged("", tv)
+		assert.NoError(t, err)
+		assert.Equal(t, templateversion.GeneralizeExporting, getGeneralize(t, tv).Phase)
+	})
+
+	t.Run("failed snapshot", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newTemplateVersion(false), pvc)
+		h := &templateVersionGeneralizeHandler{
+			templateVersions: fakeTemplateVersionClient(clientset.HarvesterhciV1beta1().VirtualMachineTemplateVersions),
+			vmiCache:         fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+			pvcClient:        fakeclients.PersistentVolumeClaimClient(clientset.CoreV1().PersistentVolumeClaims),
+			pvcCache:         fakeclients.PersistentVolumeClaimCache(clientset.CoreV1().PersistentVolumeClaims),
+			snapshotClient:   fakeclients.VolumeSnapshotClient(clientset.SnapshotV1().VolumeSnapshots),
+			snapshotCache:    fakeclients.VolumeSnapshotCache(clientset.SnapshotV1().VolumeSnapshots),
+			jobClient:        fakeclients.JobClient(clientset.BatchV1().Jobs),
+			jobCache:         fakeclients.JobCache(clientset.BatchV1().Jobs),
+			recorder:         record.NewFakeRecorder(10),
+		}
+
+		tv, err := h.OnChanged("", newTemplateVersion(false))
+		assert.NoError(t, err)
+		snapshot, err := clientset.SnapshotV1().VolumeSnapshots(namespace).Get(t.Context(), templateversion.SnapshotName(tvName, 1), metav1.GetOptions{})
+		assert.NoError(t, err)
+		snapshot.Status = &snapshotv1.VolumeSnapshotStatus{Error: &snapshotv1.VolumeSnapshotError{Message: ptr.To("out of space")}}
+		_, err = clientset.SnapshotV1().VolumeSnapshots(namespace).Update(t.Context(), snapshot, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		tv, err = h.OnChanged("", tv)
+		assert.NoError(t, err)
+		generalize := getGeneralize(t, tv)
+		assert.Equal(t, templateversion.GeneralizeFailed, generalize.Phase)
+		assert.Contains(t, generalize.Message, "out of space")
+		// the snapshots are cleaned up
+		_, err = clientset.SnapshotV1().VolumeSnapshots(namespace).Get(t.Context(), snapshot.Name, metav1.GetOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewSysprepJob(t *testing.T) {
+	tv := &harvesterv1.VirtualMachineTemplateVersion{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "template-abcdef"},
+	}
+	volumes := []sourceVolume{{index: 0, claimName: "vm-disk-0"}, {index: 2, claimName: "vm-disk-1"}}
+	volumeModes := map[int]corev1.PersistentVolumeMode{
+		0: corev1.PersistentVolumeBlock,
+		2: corev1.PersistentVolumeFilesystem,
+	}
+
+	job := newSysprepJob(tv, volumes, volumeModes, "libguestfs-tools")
+
+	assert.Equal(t, templateversion.SysprepJobName(tv.Name), job.Name)
+	assert.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+	assert.Len(t, job.Spec.Template.Spec.Volumes, 2)
+	assert.Equal(t, templateversion.CopyPVCName(tv.Name, 2), job.Spec.Template.Spec.Volumes[1].PersistentVolumeClaim.ClaimName)
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "libguestfs-tools", container.Image)
+	assert.Equal(t, []string{"--format", "raw", "-a", "/disk-0", "-a", "/disk-2/disk.img", "--delete", "/var/lib/cloud/*"}, container.Args)
+	assert.Equal(t, []corev1.VolumeDevice{{Name: "disk-0", DevicePath: "/disk-0"}}, container.VolumeDevices)
+	assert.Equal(t, []corev1.VolumeMount{{Name: "disk-2", MountPath: "/disk-2"}}, container.VolumeMounts)
+	assert.Equal(t, ptr.To(int32(0)), job.Spec.BackoffLimit)
+}
@@ -2,9 +2,14 @@ package virtualmachine
 
 import (
 	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	"github.com/harvester/harvester/pkg/config"
 	"github.com/harvester/harvester/pkg/util/resourcequota"
+	"github.com/harvester/harvester/pkg/util/rightsizing"
 )
 
 const (
@@ -19,6 +24,7 @@ const (
 	vmControllerRemoveDeprecatedFinalizerControllerName          = "VMController.RemoveDeprecatedFinalizer"
 	vmControllerSetTargetVolumeStrategyControllerName            = "VMController.SetTargetVolumeStrategy"
 	vmControllerCleanupTargetVolumeAnnotationControllerName      = "VMController.CleanupTargetVolumeAnnotation"
+	vmControllerSampleResourceUsageControllerName                = "VMController.SampleResourceUsage"
 	vmiControllerRemoveDeprecatedFinalizerControllerName         = "VMIController.RemoveDeprecatedFinalizer"
 	vmiControllerSetHaltIfOccurExceededQuotaControllerName       = "VMIController.StopVMIfExceededQuota"
 
@@ -120,5 +126,20 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 		vmCache:  vmCache,
 	}
 	virtualMachineInstanceClient.OnChange(ctx, vmControllerIgnoreNonMigratableVMI, vmiDeschedulerCtrl.IgnoreNonMigratableVM)
+
+	// the usage is sampled from the metrics API, which isn't a typed client in client-go
+	metricsRestClient := management.ClientSet.CoreV1().RESTClient()
+	configMaps := management.CoreFactory.Core().V1().ConfigMap()
+	var vmUsageCtrl = &VMUsageController{
+		vmController:   vmClient,
+		vmiCache:       vmiCache,
+		podCache:       podCache,
+		configMaps:     configMaps,
+		configMapCache: configMaps.Cache(),
+		getUsage: func(vmi *kubevirtv1.VirtualMachineInstance, pod *corev1.Pod, now time.Time) (*rightsizing.Sample, error) {
+			return rightsizing.GetLauncherUsage(ctx, metricsRestClient, vmi, pod, now)
+		},
+	}
+	virtualMachineClient.OnChange(ctx, vmControllerSampleResourceUsageControllerName, vmUsageCtrl.SampleResourceUsage)
 	return nil
 }
@@ -3,6 +3,7 @@ package virtualmachine
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -34,6 +35,10 @@ import (
 // VMI in addition to the instance labels.
 var syncLabelsToVmi = []string{util.LabelMaintainModeStrategy}
 
+// A list of labels that are managed on the VMI by other controllers,
+// they are kept even though they are not in the instance labels.
+var vmiManagedLabels = []string{util.LabelMigrationPolicy, util.LabelBootHeld}
+
 type VMController struct {
 	dataVolumeClient ctlcdiv1.DataVolumeClient
 	podClient        v1.PodClient
@@ -188,7 +193,7 @@ func (h *VMController) syncLabels(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1
 	}
 	// delete the labels exist in the `vmi.Labels` but not in the `vm.spec.template.objectMeta.Labels`
 	for k := range vmi.Labels {
-		if _, ok := vm.Spec.Template.ObjectMeta.Labels[k]; !strings.HasPrefix(k, kubevirt.GroupName) && !ok {
+		if _, ok := vm.Spec.Template.ObjectMeta.Labels[k]; !strings.HasPrefix(k, kubevirt.GroupName) && !ok && !slices.Contains(vmiManagedLabels, k) {
 			delete(vmiCopy.Labels, k)
 		}
 	}
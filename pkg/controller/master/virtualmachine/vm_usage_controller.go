@@ -0,0 +1,110 @@
+package virtualmachine
+
+import (
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	vmv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/rightsizing"
+	"github.com/harvester/harvester/pkg/util/virtualmachineinstance"
+)
+
+type VMUsageController struct {
+	vmController   vmv1.VirtualMachineController
+	vmiCache       vmv1.VirtualMachineInstanceCache
+	podCache       ctlcorev1.PodCache
+	configMaps     ctlcorev1.ConfigMapClient
+	configMapCache ctlcorev1.ConfigMapCache
+
+	getUsage func(vmi *kubevirtv1.VirtualMachineInstance, pod *corev1.Pod, now time.Time) (*rightsizing.Sample, error)
+}
+
+// SampleResourceUsage samples the CPU and memory usage of the running VM every rightsizing.SampleInterval,
+// the rolling window of the samples is kept in the usage ConfigMap of the VM for the right-sizing recommendations.
+func (h *VMUsageController) SampleResourceUsage(_ string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	if vm == nil || vm.DeletionTimestamp != nil {
+		return vm, nil
+	}
+
+	vmi, err := h.vmiCache.Get(vm.Namespace, vm.Name)
+	if apierrors.IsNotFound(err) {
+		return vm, nil
+	} else if err != nil {
+		return vm, err
+	}
+	if vmi.DeletionTimestamp != nil || vmi.Status.Phase != kubevirtv1.Running {
+		return vm, nil
+	}
+
+	configMap, err := h.configMapCache.Get(vm.Namespace, rightsizing.UsageConfigMapName(vm.Name))
+	if apierrors.IsNotFound(err) {
+		configMap = nil
+	} else if err != nil {
+		return vm, err
+	}
+	if configMap != nil && !metav1.IsControlledBy(configMap, vm) {
+		logrus.Warnf("skip sampling the resource usage of vm %s/%s, configmap %s isn't owned by the vm", vm.Namespace, vm.Name, configMap.Name)
+		return vm, nil
+	}
+
+	usage := &rightsizing.Usage{}
+	if configMap != nil {
+		if usage, err = rightsizing.UnmarshalUsage(configMap.Data[rightsizing.UsageConfigMapKey]); err != nil {
+			logrus.WithError(err).Warnf("discard the invalid resource usage of vm %s/%s", vm.Namespace, vm.Name)
+			usage = &rightsizing.Usage{}
+		}
+	}
+
+	now := time.Now()
+	if wait := usage.LastSampleTime().Add(rightsizing.SampleInterval).Sub(now); wait > 0 {
+		h.vmController.EnqueueAfter(vm.Namespace, vm.Name, wait)
+		return vm, nil
+	}
+	// the launcher pod may not be running yet or the metrics server isn't ready, try again in the next interval
+	h.vmController.EnqueueAfter(vm.Namespace, vm.Name, rightsizing.SampleInterval)
+
+	pod, err := virtualmachineinstance.GetLauncherPod(vmi, h.podCache)
+	if err != nil {
+		logrus.WithError(err).Debugf("skip sampling the resource usage of vm %s/%s", vm.Namespace, vm.Name)
+		return vm, nil
+	}
+	sample, err := h.getUsage(vmi, pod, now)
+	if err != nil {
+		logrus.WithError(err).Debugf("failed to get the resource usage of vm %s/%s", vm.Namespace, vm.Name)
+		return vm, nil
+	}
+
+	usage.AddSample(*sample)
+	data, err := rightsizing.MarshalUsage(usage)
+	if err != nil {
+		return vm, err
+	}
+
+	if configMap == nil {
+		_, err = h.configMaps.Create(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       vm.Namespace,
+				Name:            rightsizing.UsageConfigMapName(vm.Name),
+				Labels:          map[string]string{util.LabelVMName: vm.Name},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(vm, kubevirtv1.VirtualMachineGroupVersionKind)},
+			},
+			Data: map[string]string{rightsizing.UsageConfigMapKey: data},
+		})
+		return vm, err
+	}
+
+	configMapCopy := configMap.DeepCopy()
+	if configMapCopy.Data == nil {
+		configMapCopy.Data = map[string]string{}
+	}
+	configMapCopy.Data[rightsizing.UsageConfigMapKey] = data
+	_, err = h.configMaps.Update(configMapCopy)
+	return vm, err
+}
@@ -0,0 +1,148 @@
+package virtualmachine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+	"github.com/harvester/harvester/pkg/util/rightsizing"
+)
+
+func TestSampleResourceUsage(t *testing.T) {
+	const (
+		namespace = "default"
+		name      = "vm"
+	)
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: "vm-uid"},
+	}
+	newConfigMap := func(usage *rightsizing.Usage, owner *kubevirtv1.VirtualMachine) *corev1.ConfigMap {
+		data, _ := rightsizing.MarshalUsage(usage)
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       namespace,
+				Name:            rightsizing.UsageConfigMapName(name),
+				Labels:          map[string]string{util.LabelVMName: name},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(owner, kubevirtv1.VirtualMachineGroupVersionKind)},
+			},
+			Data: map[string]string{rightsizing.UsageConfigMapKey: data},
+		}
+	}
+	otherVM := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: "other-uid"},
+	}
+	vmi := &kubevirtv1.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: "vmi-uid"},
+		Status:     kubevirtv1.VirtualMachineInstanceStatus{Phase: kubevirtv1.Running, NodeName: "node1"},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "virt-launcher-vm-abcde",
+			Labels:    map[string]string{kubevirtv1.CreatedByLabel: "vmi-uid"},
+		},
+		Spec:   corev1.PodSpec{NodeName: "node1"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	getUsage := func(_ *kubevirtv1.VirtualMachineInstance, _ *corev1.Pod, now time.Time) (*rightsizing.Sample, error) {
+		return &rightsizing.Sample{Time: now.Unix(), CPU: 500, Memory: 1024}, nil
+	}
+	recent := time.Now().Add(-time.Minute).Unix()
+	stale := time.Now().Add(-rightsizing.SampleInterval - time.Minute).Unix()
+
+	tests := []struct {
+		name          string
+		configMap     *corev1.ConfigMap
+		vmi           *kubevirtv1.VirtualMachineInstance
+		getUsage      func(*kubevirtv1.VirtualMachineInstance, *corev1.Pod, time.Time) (*rightsizing.Sample, error)
+		expectSamples int
+	}{
+		{
+			name:          "stopped VM isn't sampled",
+			getUsage:      getUsage,
+			expectSamples: 0,
+		},
+		{
+			name:          "first sample",
+			vmi:           vmi,
+			getUsage:      getUsage,
+			expectSamples: 1,
+		},
+		{
+			name:          "the interval isn't passed yet",
+			configMap:     newConfigMap(&rightsizing.Usage{Samples: []rightsizing.Sample{{Time: recent}}}, vm),
+			vmi:           vmi,
+			getUsage:      getUsage,
+			expectSamples: 1,
+		},
+		{
+			name:          "the interval is passed",
+			configMap:     newConfigMap(&rightsizing.Usage{Samples: []rightsizing.Sample{{Time: stale}}}, vm),
+			vmi:           vmi,
+			getUsage:      getUsage,
+			expectSamples: 2,
+		},
+		{
+			name:      "the metrics API isn't available",
+			configMap: newConfigMap(&rightsizing.Usage{Samples: []rightsizing.Sample{{Time: stale}}}, vm),
+			vmi:       vmi,
+			getUsage: func(_ *kubevirtv1.VirtualMachineInstance, _ *corev1.Pod, _ time.Time) (*rightsizing.Sample, error) {
+				return nil, errors.New("the server could not find the requested resource")
+			},
+			expectSamples: 1,
+		},
+		{
+			name:          "the configmap of a former VM of the same name isn't updated",
+			configMap:     newConfigMap(&rightsizing.Usage{Samples: []rightsizing.Sample{{Time: stale}}}, otherVM),
+			vmi:           vmi,
+			getUsage:      getUsage,
+			expectSamples: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		clientset := fake.NewSimpleClientset(vm, pod)
+		if tc.vmi != nil {
+			assert.NoError(t, clientset.Tracker().Add(tc.vmi), tc.name)
+		}
+		if tc.configMap != nil {
+			assert.NoError(t, clientset.Tracker().Add(tc.configMap), tc.name)
+		}
+		h := &VMUsageController{
+			vmController:   fakeclients.VirtualMachineClient(clientset.KubevirtV1().VirtualMachines),
+			vmiCache:       fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+			podCache:       fakeclients.PodCache(clientset.CoreV1().Pods),
+			configMaps:     fakeclients.ConfigmapClient(clientset.CoreV1().ConfigMaps),
+			configMapCache: fakeclients.ConfigmapCache(clientset.CoreV1().ConfigMaps),
+			getUsage:       tc.getUsage,
+		}
+
+		_, err := h.SampleResourceUsage("", vm)
+		assert.NoError(t, err, tc.name)
+
+		// the VM isn't updated with the samples
+		updated, err := clientset.KubevirtV1().VirtualMachines(namespace).Get(t.Context(), name, metav1.GetOptions{})
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, vm.ResourceVersion, updated.ResourceVersion, tc.name)
+
+		usage := &rightsizing.Usage{}
+		configMap, err := clientset.CoreV1().ConfigMaps(namespace).Get(t.Context(), rightsizing.UsageConfigMapName(name), metav1.GetOptions{})
+		if err == nil {
+			usage, err = rightsizing.UnmarshalUsage(configMap.Data[rightsizing.UsageConfigMapKey])
+			assert.NoError(t, err, tc.name)
+			assert.Equal(t, name, configMap.Labels[util.LabelVMName], tc.name)
+		} else {
+			assert.True(t, apierrors.IsNotFound(err), tc.name)
+		}
+		assert.Len(t, usage.Samples, tc.expectSamples, tc.name)
+	}
+}
@@ -0,0 +1,32 @@
+package vmbulkaction
+
+import (
+	"context"
+
+	"github.com/harvester/harvester/pkg/api/vm"
+	"github.com/harvester/harvester/pkg/config"
+)
+
+const (
+	bulkActionControllerName = "vm-bulk-action-controller"
+)
+
+func Register(ctx context.Context, management *config.Management, options config.Options) error {
+	bulkActions := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBulkAction()
+	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
+
+	runner, err := vm.NewBulkActionRunner(management, options)
+	if err != nil {
+		return err
+	}
+
+	handler := &bulkActionHandler{
+		ctx:              ctx,
+		bulkActionClient: bulkActions,
+		vmCache:          vms.Cache(),
+		runner:           runner,
+	}
+
+	bulkActions.OnChange(ctx, bulkActionControllerName, handler.OnChanged)
+	return nil
+}
@@ -0,0 +1,222 @@
+package vmbulkaction
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util/bootorder"
+)
+
+const (
+	startAction = "start"
+
+	defaultConcurrency = 5
+	maxConcurrency     = 20
+)
+
+// actionRunner runs a VM action on one VM and returns the result
+type actionRunner interface {
+	Run(ctx context.Context, namespace, name, action, input string) harvesterv1.BulkActionResult
+}
+
+type bulkActionHandler struct {
+	ctx              context.Context
+	bulkActionClient ctlharvesterv1.VirtualMachineBulkActionClient
+	vmCache          ctlkubevirtv1.VirtualMachineCache
+	runner           actionRunner
+
+	// running holds the keys of the bulk actions being run
+	running sync.Map
+}
+
+// OnChanged runs the action of a bulk action on the selected VMs in the background. A bulk action left
+// running by a previous controller goes on with the VMs without a result.
+func (h *bulkActionHandler) OnChanged(_ string, bulk *harvesterv1.VirtualMachineBulkAction) (*harvesterv1.VirtualMachineBulkAction, error) {
+	if bulk == nil || bulk.DeletionTimestamp != nil || bulk.Status.Phase == harvesterv1.BulkActionPhaseCompleted {
+		return bulk, nil
+	}
+
+	key := bulk.Namespace + "/" + bulk.Name
+	if _, ok := h.running.Load(key); ok {
+		return bulk, nil
+	}
+
+	names, err := h.getVMNames(bulk)
+	if err != nil {
+		return bulk, err
+	}
+
+	done := make(map[string]bool, len(bulk.Status.Results))
+	for _, result := range bulk.Status.Results {
+		done[result.Name] = true
+	}
+	var pending []string
+	for _, name := range names {
+		if !done[name] {
+			pending = append(pending, name)
+		}
+	}
+
+	bulkCpy := bulk.DeepCopy()
+	bulkCpy.Status.Phase = harvesterv1.BulkActionPhaseRunning
+	bulkCpy.Status.Total = len(bulk.Status.Results) + len(pending)
+	if bulkCpy.Status.StartTime == nil {
+		bulkCpy.Status.StartTime = &metav1.Time{Time: time.Now()}
+	}
+	// an empty selection completes without any result
+	if len(pending) == 0 {
+		bulkCpy.Status.Phase = harvesterv1.BulkActionPhaseCompleted
+		bulkCpy.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	}
+
+	// the update fails on conflict if the bulk action is changed since it's read, so that
+	// a stale bulk action doesn't run the VMs again
+	updated, err := h.bulkActionClient.Update(bulkCpy)
+	if err != nil {
+		return bulk, err
+	}
+	if len(pending) == 0 {
+		return updated, nil
+	}
+
+	h.running.Store(key, struct{}{})
+	go func() {
+		defer h.running.Delete(key)
+		h.run(updated, pending)
+	}()
+	return updated, nil
+}
+
+// getVMNames returns the names of the VMs the action runs on, the given names are kept as they are
+// so that a missing VM shows up as a failed result. The VMs to start are sorted by the boot group.
+func (h *bulkActionHandler) getVMNames(bulk *harvesterv1.VirtualMachineBulkAction) ([]string, error) {
+	seen := map[string]bool{}
+	var names []string
+	for _, name := range bulk.Spec.Names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if bulk.Spec.Selector != "" {
+		selector, err := labels.Parse(bulk.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", bulk.Spec.Selector, err)
+		}
+		vms, err := h.vmCache.List(bulk.Namespace, selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, vm := range vms {
+			if !seen[vm.Name] {
+				seen[vm.Name] = true
+				names = append(names, vm.Name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	if bulk.Spec.Action == startAction {
+		names = h.sortByBootGroup(bulk.Namespace, names)
+	}
+	return names, nil
+}
+
+// sortByBootGroup puts the VMs in the lower boot groups first, so that the VMs booting after them
+// aren't held by the boot order controller for long. The missing VMs go last.
+func (h *bulkActionHandler) sortByBootGroup(namespace string, names []string) []string {
+	var vms []*kubevirtv1.VirtualMachine
+	var missing []string
+	for _, name := range names {
+		vm, err := h.vmCache.Get(namespace, name)
+		if err != nil {
+			missing = append(missing, name)
+			continue
+		}
+		vms = append(vms, vm)
+	}
+	bootorder.SortByGroup(vms)
+
+	sorted := make([]string, 0, len(names))
+	for _, vm := range vms {
+		sorted = append(sorted, vm.Name)
+	}
+	return append(sorted, missing...)
+}
+
+// run runs the action on the VMs with bounded concurrency and records the result of every VM
+func (h *bulkActionHandler) run(bulk *harvesterv1.VirtualMachineBulkAction, names []string) {
+	concurrency := bulk.Spec.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	concurrency = min(concurrency, maxConcurrency)
+
+	results := make(chan harvesterv1.BulkActionResult)
+	sem := make(chan struct{}, concurrency)
+	go func() {
+		for _, name := range names {
+			sem <- struct{}{}
+			go func(name string) {
+				defer func() { <-sem }()
+				results <- h.runner.Run(h.ctx, bulk.Namespace, name, bulk.Spec.Action, bulk.Spec.Input)
+			}(name)
+		}
+	}()
+
+	for range names {
+		result := <-results
+		if err := h.recordResult(bulk, result); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"namespace": bulk.Namespace,
+				"name":      bulk.Name,
+				"vm":        result.Name,
+			}).Error("Failed to record the result of bulk action")
+		}
+	}
+}
+
+// recordResult adds the result of a VM to the status, the bulk action is completed
+// when the results of all VMs are recorded.
+func (h *bulkActionHandler) recordResult(bulk *harvesterv1.VirtualMachineBulkAction, result harvesterv1.BulkActionResult) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := h.bulkActionClient.Get(bulk.Namespace, bulk.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		latestCpy := latest.DeepCopy()
+		latestCpy.Status.Results = append(latestCpy.Status.Results, result)
+		if result.Succeeded {
+			latestCpy.Status.Succeeded++
+		} else {
+			latestCpy.Status.Failed++
+		}
+		if len(latestCpy.Status.Results) >= latestCpy.Status.Total {
+			latestCpy.Status.Phase = harvesterv1.BulkActionPhaseCompleted
+			latestCpy.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		}
+
+		_, err = h.bulkActionClient.Update(latestCpy)
+		return err
+	})
+	// the bulk action may be deleted by the user, there is nothing to record then
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
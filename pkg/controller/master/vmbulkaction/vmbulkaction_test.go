@@ -0,0 +1,186 @@
+package vmbulkaction
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	fakegenerated "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+type fakeRunner struct {
+	mu   sync.Mutex
+	runs []string
+}
+
+func (r *fakeRunner) Run(_ context.Context, _, name, _, _ string) harvesterv1.BulkActionResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, name)
+	if name == "missing" {
+		return harvesterv1.BulkActionResult{Name: name, Message: "not found"}
+	}
+	return harvesterv1.BulkActionResult{Name: name, Succeeded: true}
+}
+
+func (r *fakeRunner) getRuns() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.runs...)
+}
+
+func newVM(name string, labels, annotations map[string]string) *kubevirtv1.VirtualMachine {
+	return &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels, Annotations: annotations},
+	}
+}
+
+func newHandler(clientset *fakegenerated.Clientset, runner actionRunner) *bulkActionHandler {
+	return &bulkActionHandler{
+		ctx:              context.Background(),
+		bulkActionClient: fakeclients.VMBulkActionClient(clientset.HarvesterhciV1beta1().VirtualMachineBulkActions),
+		vmCache:          fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+		runner:           runner,
+	}
+}
+
+func TestHandler_getVMNames(t *testing.T) {
+	clientset := fakegenerated.NewSimpleClientset(
+		newVM("web-2", map[string]string{"app": "web"}, nil),
+		newVM("web-1", map[string]string{"app": "web"}, map[string]string{util.AnnotationBootGroup: "1"}),
+		newVM("db", map[string]string{"app": "db"}, nil),
+	)
+	h := newHandler(clientset, nil)
+
+	tests := []struct {
+		name     string
+		spec     harvesterv1.VirtualMachineBulkActionSpec
+		expected []string
+	}{
+		{
+			name:     "selector",
+			spec:     harvesterv1.VirtualMachineBulkActionSpec{Action: "stop", Selector: "app=web"},
+			expected: []string{"web-1", "web-2"},
+		},
+		{
+			name:     "names are kept even if the VM doesn't exist",
+			spec:     harvesterv1.VirtualMachineBulkActionSpec{Action: "stop", Names: []string{"db", "missing", "db"}},
+			expected: []string{"db", "missing"},
+		},
+		{
+			name:     "start sorts by the boot group and puts the missing VMs last",
+			spec:     harvesterv1.VirtualMachineBulkActionSpec{Action: startAction, Names: []string{"web-1", "missing", "web-2", "db"}},
+			expected: []string{"db", "web-2", "web-1", "missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := h.getVMNames(&harvesterv1.VirtualMachineBulkAction{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bulk"},
+				Spec:       tt.spec,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestHandler_OnChanged(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          harvesterv1.VirtualMachineBulkActionStatus
+		expectedRuns    []string
+		expectedResults int
+		expectedFailed  int
+	}{
+		{
+			name:            "new bulk action runs on all VMs",
+			expectedRuns:    []string{"missing", "vm1", "vm2"},
+			expectedResults: 3,
+			expectedFailed:  1,
+		},
+		{
+			name: "running bulk action goes on with the VMs without a result",
+			status: harvesterv1.VirtualMachineBulkActionStatus{
+				Phase:     harvesterv1.BulkActionPhaseRunning,
+				Total:     3,
+				Succeeded: 1,
+				Results:   []harvesterv1.BulkActionResult{{Name: "vm1", Succeeded: true}},
+			},
+			expectedRuns:    []string{"missing", "vm2"},
+			expectedResults: 3,
+			expectedFailed:  1,
+		},
+		{
+			name: "completed bulk action is skipped",
+			status: harvesterv1.VirtualMachineBulkActionStatus{
+				Phase: harvesterv1.BulkActionPhaseCompleted,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bulk := &harvesterv1.VirtualMachineBulkAction{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bulk"},
+				Spec: harvesterv1.VirtualMachineBulkActionSpec{
+					Action:      "stop",
+					Names:       []string{"vm1", "vm2", "missing"},
+					Concurrency: 1,
+				},
+				Status: tt.status,
+			}
+			clientset := fakegenerated.NewSimpleClientset(bulk, newVM("vm1", nil, nil), newVM("vm2", nil, nil))
+			runner := &fakeRunner{}
+			h := newHandler(clientset, runner)
+
+			_, err := h.OnChanged("", bulk)
+			require.NoError(t, err)
+
+			if tt.status.Phase == harvesterv1.BulkActionPhaseCompleted {
+				assert.Empty(t, runner.getRuns())
+				return
+			}
+
+			var latest *harvesterv1.VirtualMachineBulkAction
+			require.Eventually(t, func() bool {
+				latest, err = h.bulkActionClient.Get("default", "bulk", metav1.GetOptions{})
+				return err == nil && latest.Status.Phase == harvesterv1.BulkActionPhaseCompleted
+			}, 5*time.Second, 10*time.Millisecond)
+
+			assert.Equal(t, tt.expectedRuns, runner.getRuns())
+			assert.Equal(t, 3, latest.Status.Total)
+			assert.Len(t, latest.Status.Results, tt.expectedResults)
+			assert.Equal(t, tt.expectedFailed, latest.Status.Failed)
+			assert.Equal(t, tt.expectedResults-tt.expectedFailed, latest.Status.Succeeded)
+			assert.NotNil(t, latest.Status.StartTime)
+			assert.NotNil(t, latest.Status.CompletionTime)
+		})
+	}
+}
+
+func TestHandler_OnChanged_EmptySelection(t *testing.T) {
+	bulk := &harvesterv1.VirtualMachineBulkAction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bulk"},
+		Spec:       harvesterv1.VirtualMachineBulkActionSpec{Action: "stop", Selector: "app=none"},
+	}
+	clientset := fakegenerated.NewSimpleClientset(bulk)
+	runner := &fakeRunner{}
+	h := newHandler(clientset, runner)
+
+	updated, err := h.OnChanged("", bulk)
+	require.NoError(t, err)
+	assert.Equal(t, harvesterv1.BulkActionPhaseCompleted, updated.Status.Phase)
+	assert.Equal(t, 0, updated.Status.Total)
+	assert.Empty(t, runner.getRuns())
+}
@@ -12,6 +12,8 @@ const (
 	vmImageDownloaderControllerName = "vmimage-downloader-controller"
 	deploymentWatcherName           = "deployment-watcher"
 	deploymentControllerName        = "deployment-controller"
+	vmDownloaderControllerName      = "vm-downloader-controller"
+	vmDownloaderWatcherName         = "vm-downloader-watcher"
 )
 
 func Register(ctx context.Context, management *config.Management, _ config.Options) error {
@@ -36,5 +38,25 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 	vmImageDownloader.OnRemove(ctx, vmImageDownloaderControllerName, storageProfileHandler.OnRemoved)
 	relatedresource.Watch(ctx, deploymentWatcherName, storageProfileHandler.ReconcileDeploymentOwners, vmImageDownloader, deployment)
 
+	vmDownloader := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineDownloader()
+	pods := management.CoreFactory.Core().V1().Pod()
+	secrets := management.CoreFactory.Core().V1().Secret()
+	vmDownloaderHandler := &vmDownloaderHandler{
+		clientSet:        clientSet,
+		vmCache:          management.VirtFactory.Kubevirt().V1().VirtualMachine().Cache(),
+		pvcCache:         pvcCache,
+		podCache:         pods.Cache(),
+		secretClient:     secrets,
+		secretCache:      secrets.Cache(),
+		scCache:          scCache,
+		deploymentClient: deployment,
+		vmDownloaders:    vmDownloader,
+		vmDownloaderCtl:  vmDownloader,
+	}
+
+	vmDownloader.OnChange(ctx, vmDownloaderControllerName, vmDownloaderHandler.OnChanged)
+	vmDownloader.OnRemove(ctx, vmDownloaderControllerName, vmDownloaderHandler.OnRemoved)
+	relatedresource.Watch(ctx, vmDownloaderWatcherName, vmDownloaderHandler.ReconcileDownloaderOwners, vmDownloader, deployment, pods)
+
 	return nil
 }
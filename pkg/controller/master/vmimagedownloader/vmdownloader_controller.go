@@ -0,0 +1,647 @@
+package vmimagedownloader
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	ctlappsv1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/apps/v1"
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	ctlstoragev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/storage/v1"
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/ovf"
+)
+
+const (
+	kindVirtualMachineDownloader = "VirtualMachineDownloader"
+
+	vmPackageDir     = "/srv/www/htdocs/vms"
+	vmPackageURLPath = "vms"
+	vmPackageVolume  = "package-dir"
+	vmConfigDir      = "/package-config"
+	vmConfigVolume   = "package-config"
+
+	cloudInitUserDataFile    = "user-data"
+	cloudInitNetworkDataFile = "network-data"
+
+	// vmPackageTTL is how long the package can be downloaded, the downloader is removed once it expires
+	vmPackageTTL = 24 * time.Hour
+	// vmPackageOverhead is added to the package volume for the OVF descriptor, cloud-init data and qcow2 metadata
+	vmPackageOverhead = 1 << 30
+)
+
+// vmDownloaderHandler packages a stopped VM into an OVA with the OVF descriptor, the qcow2 disks and the cloud-init data,
+// and serves it through a download server deployment like the vm image downloader. The download server is only reachable
+// from the harvester API server, the package is downloaded through the downloadExport action of the VM.
+type vmDownloaderHandler struct {
+	clientSet        kubernetes.Interface
+	vmCache          ctlkubevirtv1.VirtualMachineCache
+	pvcCache         ctlcorev1.PersistentVolumeClaimCache
+	podCache         ctlcorev1.PodCache
+	secretClient     ctlcorev1.SecretClient
+	secretCache      ctlcorev1.SecretCache
+	scCache          ctlstoragev1.StorageClassCache
+	deploymentClient ctlappsv1.DeploymentClient
+	vmDownloaders    v1beta1.VirtualMachineDownloaderClient
+	vmDownloaderCtl  v1beta1.VirtualMachineDownloaderController
+}
+
+// packageDisk is a PVC of the VM which is converted into a qcow2 disk of the package
+type packageDisk struct {
+	ovf.Disk
+	pvc *corev1.PersistentVolumeClaim
+}
+
+func getVMDownloaderDeploymentName(downloader *harvesterv1.VirtualMachineDownloader) string {
+	return fmt.Sprintf("%s-vm-downloader", downloader.Name)
+}
+
+func getVMPackageFileName(downloader *harvesterv1.VirtualMachineDownloader) string {
+	return fmt.Sprintf("%s.%s", downloader.Spec.VMName, downloader.Spec.Format)
+}
+
+func (h *vmDownloaderHandler) OnChanged(_ string, downloader *harvesterv1.VirtualMachineDownloader) (*harvesterv1.VirtualMachineDownloader, error) {
+	if downloader == nil || downloader.DeletionTimestamp != nil {
+		return downloader, nil
+	}
+	if downloader.Status.Status == harvesterv1.ImageDownloaderStatusReady {
+		return downloader, h.expire(downloader)
+	}
+
+	vm, err := h.vmCache.Get(downloader.Namespace, downloader.Spec.VMName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logrus.Infof("Corresponding VM %s not found, skip this", downloader.Spec.VMName)
+			return downloader, nil
+		}
+		return downloader, fmt.Errorf("failed to get vm %s: %w", downloader.Spec.VMName, err)
+	}
+
+	disks, err := h.getPackageDisks(vm)
+	if err != nil {
+		return downloader, err
+	}
+
+	config, err := h.getOrCreatePackageConfig(downloader, vm, disks)
+	if err != nil {
+		return downloader, err
+	}
+
+	deploymentName := getVMDownloaderDeploymentName(downloader)
+	deployment, err := h.deploymentClient.Get(downloader.Namespace, deploymentName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		deployment, err = h.deploymentClient.Create(h.newVMDownloaderDeployment(downloader, disks, config))
+	}
+	if err != nil {
+		return downloader, fmt.Errorf("failed to get or create deployment %s: %w", deploymentName, err)
+	}
+	if err := h.ensureNetworkPolicy(deployment); err != nil {
+		return downloader, err
+	}
+
+	if deployment.Status.ReadyReplicas != *deployment.Spec.Replicas {
+		return h.updateProgress(downloader, len(disks))
+	}
+
+	serviceTemplate := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: deploymentName,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         appsv1.SchemeGroupVersion.String(),
+					Kind:               kindDeployment,
+					Name:               deployment.Name,
+					UID:                deployment.GetUID(),
+					BlockOwnerDeletion: &boolTrue,
+				},
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				"app": deploymentName,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Protocol:   corev1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(80),
+				},
+			},
+			Type: corev1.ServiceTypeClusterIP,
+		},
+	}
+	if _, err := h.clientSet.CoreV1().Services(downloader.Namespace).Create(context.TODO(), serviceTemplate, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return downloader, fmt.Errorf("failed to create the download server service with VM(%s): %v", downloader.Name, err)
+	}
+
+	fileName := getVMPackageFileName(downloader)
+	downloaderCpy := downloader.DeepCopy()
+	for _, cond := range []harvesterv1.VirtualMachineImageDownloaderCondition{
+		newCondition(harvesterv1.DownloaderCondsConverting, "Disks Converted", fmt.Sprintf("Converted %d/%d disks", len(disks), len(disks))),
+		newCondition(harvesterv1.DownloaderCondsPackaging, "VM Packaged", fmt.Sprintf("The VM is packaged into %s", fileName)),
+		newCondition(harvesterv1.DownloaderCondsReady, "VM Downloader Ready", "The corresponding deployment and service are ready"),
+	} {
+		downloaderCpy.Status.Conditions = updateConds(downloaderCpy.Status.Conditions, cond)
+	}
+	downloaderCpy.Status.Status = harvesterv1.ImageDownloaderStatusReady
+	downloaderCpy.Status.FileName = fileName
+	downloaderCpy.Status.DownloadURL = fmt.Sprintf("http://%s.%s/%s/%s", deploymentName, downloader.Namespace, vmPackageURLPath, fileName)
+	if !reflect.DeepEqual(downloader, downloaderCpy) {
+		return h.vmDownloaders.UpdateStatus(downloaderCpy)
+	}
+	return downloader, nil
+}
+
+// expire removes the downloader once the package has been ready for the TTL, or enqueues it when the TTL expires
+func (h *vmDownloaderHandler) expire(downloader *harvesterv1.VirtualMachineDownloader) error {
+	readyTime := downloader.CreationTimestamp
+	if cond := getCond(downloader.Status.Conditions, harvesterv1.DownloaderCondsReady); cond != nil {
+		readyTime = cond.LastTransitionTime
+	}
+	if remaining := time.Until(readyTime.Add(vmPackageTTL)); remaining > 0 {
+		h.vmDownloaderCtl.EnqueueAfter(downloader.Namespace, downloader.Name, remaining)
+		return nil
+	}
+
+	logrus.Infof("VM package of downloader %s/%s expired, removing it", downloader.Namespace, downloader.Name)
+	if err := h.vmDownloaders.Delete(downloader.Namespace, downloader.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete expired downloader %s/%s: %w", downloader.Namespace, downloader.Name, err)
+	}
+	return nil
+}
+
+// ensureNetworkPolicy only lets the harvester API server reach the download server, so the package can't be
+// downloaded without the permission checks of the downloadExport action
+func (h *vmDownloaderHandler) ensureNetworkPolicy(deployment *appsv1.Deployment) error {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployment.Name,
+			Namespace: deployment.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         appsv1.SchemeGroupVersion.String(),
+					Kind:               kindDeployment,
+					Name:               deployment.Name,
+					UID:                deployment.GetUID(),
+					BlockOwnerDeletion: &boolTrue,
+				},
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": deployment.Name},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{corev1.LabelMetadataName: util.HarvesterSystemNamespaceName},
+							},
+							PodSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									"app.kubernetes.io/name":      util.HarvesterChartReleaseName,
+									"app.kubernetes.io/component": "apiserver",
+								},
+							},
+						},
+					},
+					Ports: []networkingv1.NetworkPolicyPort{
+						{
+							Protocol: ptr.To(corev1.ProtocolTCP),
+							Port:     ptr.To(intstr.FromInt(80)),
+						},
+					},
+				},
+			},
+		},
+	}
+	if _, err := h.clientSet.NetworkingV1().NetworkPolicies(deployment.Namespace).Create(context.TODO(), policy, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create network policy %s: %w", deployment.Name, err)
+	}
+	return nil
+}
+
+// updateProgress reports the disks converted by the init containers of the download server,
+// the package is being built once all the disks are converted
+func (h *vmDownloaderHandler) updateProgress(downloader *harvesterv1.VirtualMachineDownloader, total int) (*harvesterv1.VirtualMachineDownloader, error) {
+	converted := 0
+	pods, err := h.podCache.List(downloader.Namespace, labels.SelectorFromSet(labels.Set{util.LabelVMDownloader: downloader.Name}))
+	if err != nil {
+		return downloader, err
+	}
+	for _, pod := range pods {
+		converted = max(converted, countConvertedDisks(pod))
+	}
+
+	downloaderCpy := downloader.DeepCopy()
+	conds := []harvesterv1.VirtualMachineImageDownloaderCondition{
+		newCondition(harvesterv1.DownloaderCondsReconciling, "VM Downloader Reconciling", "Waiting for the corresponding deployment to be ready"),
+	}
+	if converted < total {
+		conds = append(conds, harvesterv1.VirtualMachineImageDownloaderCondition{
+			Type:               harvesterv1.DownloaderCondsConverting,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "Converting Disks",
+			Message:            fmt.Sprintf("Converted %d/%d disks", converted, total),
+		})
+	} else {
+		conds = append(conds,
+			newCondition(harvesterv1.DownloaderCondsConverting, "Disks Converted", fmt.Sprintf("Converted %d/%d disks", converted, total)),
+			harvesterv1.VirtualMachineImageDownloaderCondition{
+				Type:               harvesterv1.DownloaderCondsPackaging,
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "Packaging VM",
+				Message:            fmt.Sprintf("Packaging the VM into %s", getVMPackageFileName(downloader)),
+			})
+	}
+	for _, cond := range conds {
+		// keep the transition time if only the time changes, otherwise the status would be updated in each reconciliation
+		if existing := getCond(downloader.Status.Conditions, cond.Type); existing != nil && existing.Status == cond.Status && existing.Message == cond.Message {
+			continue
+		}
+		downloaderCpy.Status.Conditions = updateConds(downloaderCpy.Status.Conditions, cond)
+	}
+	downloaderCpy.Status.Status = harvesterv1.ImageDownloaderStatusProgressing
+	if !reflect.DeepEqual(downloader, downloaderCpy) {
+		return h.vmDownloaders.UpdateStatus(downloaderCpy)
+	}
+	return downloader, nil
+}
+
+func (h *vmDownloaderHandler) OnRemoved(_ string, downloader *harvesterv1.VirtualMachineDownloader) (*harvesterv1.VirtualMachineDownloader, error) {
+	if downloader == nil {
+		return nil, nil
+	}
+
+	deploymentName := getVMDownloaderDeploymentName(downloader)
+	if err := h.deploymentClient.Delete(downloader.Namespace, deploymentName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return downloader, fmt.Errorf("failed to delete deployment %s: %v", deploymentName, err)
+	}
+	if err := h.clientSet.CoreV1().Services(downloader.Namespace).Delete(context.TODO(), deploymentName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return downloader, fmt.Errorf("failed to delete service %s: %v", deploymentName, err)
+	}
+	if err := h.clientSet.NetworkingV1().NetworkPolicies(downloader.Namespace).Delete(context.TODO(), deploymentName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return downloader, fmt.Errorf("failed to delete network policy %s: %v", deploymentName, err)
+	}
+	if err := h.secretClient.Delete(downloader.Namespace, deploymentName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return downloader, fmt.Errorf("failed to delete secret %s: %v", deploymentName, err)
+	}
+	return downloader, nil
+}
+
+// getPackageDisks returns the PVCs of the VM in the order of the volumes
+func (h *vmDownloaderHandler) getPackageDisks(vm *kubevirtv1.VirtualMachine) ([]packageDisk, error) {
+	if vm.Spec.Template == nil {
+		return nil, fmt.Errorf("vm %s/%s has no template", vm.Namespace, vm.Name)
+	}
+
+	cdroms := map[string]bool{}
+	for _, disk := range vm.Spec.Template.Spec.Domain.Devices.Disks {
+		cdroms[disk.Name] = disk.CDRom != nil
+	}
+
+	var disks []packageDisk
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := h.pvcCache.Get(vm.Namespace, volume.PersistentVolumeClaim.ClaimName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pvc %s: %w", volume.PersistentVolumeClaim.ClaimName, err)
+		}
+		if pvc.Spec.VolumeMode == nil {
+			return nil, fmt.Errorf("failed to get volume mode of pvc %s", pvc.Name)
+		}
+		size := pvc.Spec.Resources.Requests.Storage()
+		if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			size = &capacity
+		}
+		disks = append(disks, packageDisk{
+			Disk: ovf.Disk{
+				ID:       volume.Name,
+				FileName: volume.Name + ".qcow2",
+				Capacity: size.Value(),
+				CDROM:    cdroms[volume.Name],
+			},
+			pvc: pvc,
+		})
+	}
+	return disks, nil
+}
+
+// getOrCreatePackageConfig creates the secret holding the OVF descriptor and the cloud-init data of the VM,
+// it's a secret since the cloud-init data may contain credentials
+func (h *vmDownloaderHandler) getOrCreatePackageConfig(downloader *harvesterv1.VirtualMachineDownloader, vm *kubevirtv1.VirtualMachine, disks []packageDisk) (*corev1.Secret, error) {
+	name := getVMDownloaderDeploymentName(downloader)
+	if secret, err := h.secretCache.Get(downloader.Namespace, name); err == nil {
+		return secret, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	data, err := h.getCloudInitData(vm)
+	if err != nil {
+		return nil, err
+	}
+	extraFiles := make([]string, 0, len(data))
+	for _, fileName := range []string{cloudInitUserDataFile, cloudInitNetworkDataFile} {
+		if _, ok := data[fileName]; ok {
+			extraFiles = append(extraFiles, fileName)
+		}
+	}
+	ovfDisks := make([]ovf.Disk, 0, len(disks))
+	for _, disk := range disks {
+		ovfDisks = append(ovfDisks, disk.Disk)
+	}
+	descriptor, err := ovf.Build(vm, ovfDisks, extraFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the ovf descriptor of vm %s/%s: %w", vm.Namespace, vm.Name, err)
+	}
+	data[vm.Name+".ovf"] = descriptor
+
+	secret, err := h.secretClient.Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: downloader.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         harvesterv1.SchemeGroupVersion.String(),
+					Kind:               kindVirtualMachineDownloader,
+					Name:               downloader.Name,
+					UID:                downloader.GetUID(),
+					BlockOwnerDeletion: &boolTrue,
+				},
+			},
+		},
+		Data: data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret %s: %w", name, err)
+	}
+	return secret, nil
+}
+
+// getCloudInitData returns the cloud-init user data and network data of the VM, either inline or from the secrets
+func (h *vmDownloaderHandler) getCloudInitData(vm *kubevirtv1.VirtualMachine) (map[string][]byte, error) {
+	data := map[string][]byte{}
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		cloudInit := volume.CloudInitNoCloud
+		if cloudInit == nil {
+			continue
+		}
+
+		userData, err := h.getCloudInitValue(vm.Namespace, cloudInit.UserData, cloudInit.UserDataSecretRef, "userdata")
+		if err != nil {
+			return nil, err
+		}
+		networkData, err := h.getCloudInitValue(vm.Namespace, cloudInit.NetworkData, cloudInit.NetworkDataSecretRef, "networkdata")
+		if err != nil {
+			return nil, err
+		}
+		if userData != "" {
+			data[cloudInitUserDataFile] = []byte(userData)
+		}
+		if networkData != "" {
+			data[cloudInitNetworkDataFile] = []byte(networkData)
+		}
+	}
+	return data, nil
+}
+
+func (h *vmDownloaderHandler) getCloudInitValue(namespace, value string, secretRef *corev1.LocalObjectReference, key string) (string, error) {
+	if secretRef == nil {
+		return value, nil
+	}
+	secret, err := h.secretCache.Get(namespace, secretRef.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cloud-init secret %s: %w", secretRef.Name, err)
+	}
+	// KubeVirt accepts both the lower and the camel case keys
+	for k, v := range secret.Data {
+		if strings.EqualFold(k, key) {
+			return string(v), nil
+		}
+	}
+	return "", nil
+}
+
+func (h *vmDownloaderHandler) newVMDownloaderDeployment(downloader *harvesterv1.VirtualMachineDownloader, disks []packageDisk, config *corev1.Secret) *appsv1.Deployment {
+	deploymentName := getVMDownloaderDeploymentName(downloader)
+	fileName := getVMPackageFileName(downloader)
+	virtImage := getVirtHandlerImage(h.clientSet)
+
+	volumes := []corev1.Volume{
+		{
+			Name: vmPackageVolume,
+			VolumeSource: corev1.VolumeSource{
+				Ephemeral: &corev1.EphemeralVolumeSource{
+					VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+						Spec: corev1.PersistentVolumeClaimSpec{
+							AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+							VolumeMode:  ptr.To(corev1.PersistentVolumeFilesystem),
+							Resources: corev1.VolumeResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceStorage: *getPackageVolumeSize(disks)},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: vmConfigVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: deploymentName,
+				},
+			},
+		},
+	}
+	var initContainers []corev1.Container
+	// the OVF descriptor must be the first file of the OVA
+	tarArgs := []string{"-C", vmConfigDir, downloader.Spec.VMName + ".ovf", "-C", vmPackageDir}
+	var affinity *corev1.Affinity
+	for i, disk := range disks {
+		volumeName := fmt.Sprintf("disk-%d", i)
+		volumes = append(volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: disk.pvc.Name,
+					ReadOnly:  true,
+				},
+			},
+		})
+		initContainers = append(initContainers, genConvertContainer(fmt.Sprintf("convert-disk-%d", i), virtImage, volumeName, vmPackageVolume, *disk.pvc.Spec.VolumeMode, disk.FileName))
+		tarArgs = append(tarArgs, disk.FileName)
+		if affinity == nil {
+			affinity = getAffinity(h.scCache, disk.pvc)
+		}
+	}
+	tarArgs = append(tarArgs, "-C", vmConfigDir)
+	for _, extraFile := range []string{cloudInitUserDataFile, cloudInitNetworkDataFile} {
+		if _, ok := config.Data[extraFile]; ok {
+			tarArgs = append(tarArgs, extraFile)
+		}
+	}
+
+	replicaNum := int32(1)
+	podLabels := map[string]string{
+		"app":                  deploymentName,
+		util.LabelVMDownloader: downloader.Name,
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentName,
+			Namespace: downloader.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         harvesterv1.SchemeGroupVersion.String(),
+					Kind:               kindVirtualMachineDownloader,
+					Name:               downloader.Name,
+					UID:                downloader.GetUID(),
+					BlockOwnerDeletion: &boolTrue,
+				},
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicaNum,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": deploymentName,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+				Spec: corev1.PodSpec{
+					Affinity:       affinity,
+					Volumes:        volumes,
+					InitContainers: initContainers,
+					Containers: []corev1.Container{
+						{
+							Name:            "vm-exporter",
+							Image:           getClusterRepoImage(h.clientSet),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Ports: []corev1.ContainerPort{
+								{
+									ContainerPort: 80,
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      vmPackageVolume,
+									MountPath: vmPackageDir,
+								},
+								{
+									Name:      vmConfigVolume,
+									MountPath: vmConfigDir,
+									ReadOnly:  true,
+								},
+							},
+							Command: []string{"/bin/sh", "-c"},
+							Args:    []string{genPackageScript(fileName, tarArgs)},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: fmt.Sprintf("/%s/%s", vmPackageURLPath, fileName),
+										Port: intstr.FromInt(80),
+									},
+								},
+								PeriodSeconds: 5,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// getPackageVolumeSize returns the size of the volume holding the converted disks and the package, the qcow2 disks
+// are at most the capacity of the volumes and are copied into the package before they're removed
+func getPackageVolumeSize(disks []packageDisk) *resource.Quantity {
+	size := int64(vmPackageOverhead)
+	for _, disk := range disks {
+		size += 2 * disk.Capacity
+	}
+	return resource.NewQuantity(size, resource.BinarySI)
+}
+
+// genPackageScript generates the script which packages the converted disks and the config into the OVA once,
+// and then serves it
+func genPackageScript(fileName string, tarArgs []string) string {
+	return fmt.Sprintf("set -e; cd %[1]s; if [ ! -f %[2]s ]; then tar -cf %[2]s.tmp %[3]s; rm -f *.qcow2; mv %[2]s.tmp %[2]s; fi; exec nginx -g 'daemon off;'",
+		vmPackageDir, fileName, strings.Join(tarArgs, " "))
+}
+
+// countConvertedDisks returns the number of the init containers which have converted the disks
+func countConvertedDisks(pod *corev1.Pod) int {
+	converted := 0
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.ExitCode == 0 {
+			converted++
+		}
+	}
+	return converted
+}
+
+func (h *vmDownloaderHandler) ReconcileDownloaderOwners(_ string, _ string, obj runtime.Object) ([]relatedresource.Key, error) {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		for _, ownerReference := range o.GetOwnerReferences() {
+			if ownerReference.Kind == kindVirtualMachineDownloader {
+				return []relatedresource.Key{{Namespace: o.Namespace, Name: ownerReference.Name}}, nil
+			}
+		}
+	case *corev1.Pod:
+		if name, ok := o.Labels[util.LabelVMDownloader]; ok {
+			return []relatedresource.Key{{Namespace: o.Namespace, Name: name}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func newCondition(condType harvesterv1.DownloaderCondsType, reason, message string) harvesterv1.VirtualMachineImageDownloaderCondition {
+	return harvesterv1.VirtualMachineImageDownloaderCondition{
+		Type:               condType,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+func getCond(conds []harvesterv1.VirtualMachineImageDownloaderCondition, condType harvesterv1.DownloaderCondsType) *harvesterv1.VirtualMachineImageDownloaderCondition {
+	for i := range conds {
+		if conds[i].Type == condType {
+			return &conds[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package vmimagedownloader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+	"github.com/harvester/harvester/pkg/util/ovf"
+)
+
+// fakeVMDownloaderController records the downloaders enqueued by the handler
+type fakeVMDownloaderController struct {
+	v1beta1.VirtualMachineDownloaderController
+	enqueued map[string]time.Duration
+}
+
+func (c *fakeVMDownloaderController) EnqueueAfter(namespace, name string, duration time.Duration) {
+	c.enqueued[namespace+"/"+name] = duration
+}
+
+func newTestVMDownloaderHandler(clientset *fake.Clientset, coreclientset *corefake.Clientset) *vmDownloaderHandler {
+	return &vmDownloaderHandler{
+		clientSet:        coreclientset,
+		vmCache:          fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+		pvcCache:         fakeclients.PersistentVolumeClaimCache(clientset.CoreV1().PersistentVolumeClaims),
+		podCache:         fakeclients.PodCache(clientset.CoreV1().Pods),
+		secretClient:     fakeclients.SecretClient(clientset.CoreV1().Secrets),
+		secretCache:      fakeclients.SecretCache(clientset.CoreV1().Secrets),
+		scCache:          fakeclients.StorageClassCache(clientset.StorageV1().StorageClasses),
+		deploymentClient: fakeclients.DeploymentClient(clientset.AppsV1().Deployments),
+		vmDownloaders:    fakeclients.VirtualMachineDownloaderClient(clientset.HarvesterhciV1beta1().VirtualMachineDownloaders),
+		vmDownloaderCtl:  &fakeVMDownloaderController{enqueued: map[string]time.Duration{}},
+	}
+}
+
+func newTestVMDownloader() *harvesterv1.VirtualMachineDownloader {
+	return &harvesterv1.VirtualMachineDownloader{
+		ObjectMeta: metav1.ObjectMeta{Name: "export", Namespace: "default", UID: "export-uid"},
+		Spec:       harvesterv1.VirtualMachineDownloaderSpec{VMName: "vm", Format: "ova"},
+	}
+}
+
+func newTestVM() *kubevirtv1.VirtualMachine {
+	return &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "vm", Namespace: "default"},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Volumes: []kubevirtv1.Volume{
+						{
+							Name: "disk-0",
+							VolumeSource: kubevirtv1.VolumeSource{
+								PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+									PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{ClaimName: "vm-disk-0"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestPVC() *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "vm-disk-0", Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			VolumeMode: ptr.To(corev1.PersistentVolumeBlock),
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+	}
+}
+
+func Test_getPackageVolumeSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		disks    []packageDisk
+		expected string
+	}{
+		{
+			name:     "no disks",
+			expected: "1Gi",
+		},
+		{
+			name: "disks",
+			disks: []packageDisk{
+				{Disk: ovf.Disk{Capacity: 10 << 30}},
+				{Disk: ovf.Disk{Capacity: 1 << 30}},
+			},
+			expected: "23Gi",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getPackageVolumeSize(tc.disks).String())
+		})
+	}
+}
+
+func Test_newVMDownloaderDeployment(t *testing.T) {
+	h := newTestVMDownloaderHandler(fake.NewSimpleClientset(), corefake.NewSimpleClientset())
+	disks := []packageDisk{{Disk: ovf.Disk{ID: "disk-0", FileName: "disk-0.qcow2", Capacity: 10 << 30}, pvc: newTestPVC()}}
+
+	deployment := h.newVMDownloaderDeployment(newTestVMDownloader(), disks, &corev1.Secret{})
+	volumes := deployment.Spec.Template.Spec.Volumes
+	require.NotEmpty(t, volumes)
+	assert.Equal(t, vmPackageVolume, volumes[0].Name)
+	assert.Nil(t, volumes[0].EmptyDir, "the package volume must be bounded")
+	require.NotNil(t, volumes[0].Ephemeral)
+	request := volumes[0].Ephemeral.VolumeClaimTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+	assert.Equal(t, "21Gi", request.String())
+	assert.Len(t, deployment.Spec.Template.Spec.InitContainers, 1)
+	assert.Equal(t, "export", deployment.Spec.Template.Labels[util.LabelVMDownloader])
+}
+
+func Test_vmDownloaderHandler_ensureNetworkPolicy(t *testing.T) {
+	coreclientset := corefake.NewSimpleClientset()
+	h := newTestVMDownloaderHandler(fake.NewSimpleClientset(), coreclientset)
+	deployment := h.newVMDownloaderDeployment(newTestVMDownloader(), nil, &corev1.Secret{})
+
+	require.NoError(t, h.ensureNetworkPolicy(deployment))
+	// the existing policy is kept
+	require.NoError(t, h.ensureNetworkPolicy(deployment))
+
+	policy, err := coreclientset.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), deployment.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": deployment.Name}, policy.Spec.PodSelector.MatchLabels)
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, policy.Spec.PolicyTypes)
+	require.Len(t, policy.Spec.Ingress, 1)
+	require.Len(t, policy.Spec.Ingress[0].From, 1)
+	peer := policy.Spec.Ingress[0].From[0]
+	assert.Nil(t, peer.IPBlock)
+	assert.Equal(t, map[string]string{corev1.LabelMetadataName: util.HarvesterSystemNamespaceName}, peer.NamespaceSelector.MatchLabels)
+	assert.Equal(t, "apiserver", peer.PodSelector.MatchLabels["app.kubernetes.io/component"])
+}
+
+func Test_vmDownloaderHandler_OnChanged(t *testing.T) {
+	downloader := newTestVMDownloader()
+	clientset := fake.NewSimpleClientset(downloader, newTestVM(), newTestPVC())
+	coreclientset := corefake.NewSimpleClientset()
+	h := newTestVMDownloaderHandler(clientset, coreclientset)
+
+	updated, err := h.OnChanged("", downloader)
+	require.NoError(t, err)
+	assert.Equal(t, harvesterv1.ImageDownloaderStatusProgressing, updated.Status.Status)
+	cond := getCond(updated.Status.Conditions, harvesterv1.DownloaderCondsConverting)
+	require.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, "Converted 0/1 disks", cond.Message)
+
+	name := getVMDownloaderDeploymentName(downloader)
+	_, err = clientset.CoreV1().Secrets("default").Get(context.TODO(), name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = clientset.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = coreclientset.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), name, metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func Test_vmDownloaderHandler_expire(t *testing.T) {
+	tests := []struct {
+		name           string
+		readyTime      time.Time
+		expectDeleted  bool
+		expectEnqueued bool
+	}{
+		{
+			name:           "package is downloadable",
+			readyTime:      time.Now().Add(-time.Hour),
+			expectEnqueued: true,
+		},
+		{
+			name:          "package expired",
+			readyTime:     time.Now().Add(-vmPackageTTL - time.Minute),
+			expectDeleted: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			downloader := newTestVMDownloader()
+			downloader.Status.Status = harvesterv1.ImageDownloaderStatusReady
+			cond := newCondition(harvesterv1.DownloaderCondsReady, "VM Downloader Ready", "")
+			cond.LastTransitionTime = metav1.NewTime(tc.readyTime)
+			downloader.Status.Conditions = []harvesterv1.VirtualMachineImageDownloaderCondition{cond}
+			clientset := fake.NewSimpleClientset(downloader)
+			h := newTestVMDownloaderHandler(clientset, corefake.NewSimpleClientset())
+
+			_, err := h.OnChanged("", downloader)
+			require.NoError(t, err)
+
+			_, err = clientset.HarvesterhciV1beta1().VirtualMachineDownloaders("default").Get(context.TODO(), downloader.Name, metav1.GetOptions{})
+			assert.Equal(t, tc.expectDeleted, apierrors.IsNotFound(err))
+			enqueued := h.vmDownloaderCtl.(*fakeVMDownloaderController).enqueued
+			_, ok := enqueued["default/export"]
+			assert.Equal(t, tc.expectEnqueued, ok)
+		})
+	}
+}
+
+func Test_vmDownloaderHandler_OnRemoved(t *testing.T) {
+	downloader := newTestVMDownloader()
+	clientset := fake.NewSimpleClientset(downloader)
+	coreclientset := corefake.NewSimpleClientset()
+	h := newTestVMDownloaderHandler(clientset, coreclientset)
+	deployment := h.newVMDownloaderDeployment(downloader, nil, &corev1.Secret{})
+	_, err := h.deploymentClient.Create(deployment)
+	require.NoError(t, err)
+	require.NoError(t, h.ensureNetworkPolicy(deployment))
+
+	_, err = h.OnRemoved("", downloader)
+	require.NoError(t, err)
+
+	_, err = clientset.AppsV1().Deployments("default").Get(context.TODO(), deployment.Name, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = coreclientset.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), deployment.Name, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -179,9 +179,9 @@ func (h *vmImageDownloaderHandler) createDownloaderDeployment(deploymentName str
 		return nil, fmt.Errorf("failed to get volume mode of pvc %s", vmImage.Name)
 	}
 	volMode := pvc.Spec.VolumeMode
-	clusterRepoImageStr := h.getClusterRepoImage()
+	clusterRepoImageStr := getClusterRepoImage(h.clientSet)
 	initContainer := h.genInitContainer(volMode, vmImage.Name)
-	affinity := h.getAffinity(pvc)
+	affinity := getAffinity(h.scCache, pvc)
 
 	replicaNum := int32(1)
 	deployment := &appsv1.Deployment{
@@ -260,12 +260,12 @@ func (h *vmImageDownloaderHandler) createDownloaderDeployment(deploymentName str
 	return h.deploymentClient.Create(deployment)
 }
 
-func (h *vmImageDownloaderHandler) getAffinity(pvc *corev1.PersistentVolumeClaim) *corev1.Affinity {
+func getAffinity(scCache ctlstoragev1.StorageClassCache, pvc *corev1.PersistentVolumeClaim) *corev1.Affinity {
 	pvcSC := pvc.Spec.StorageClassName
 	if pvcSC == nil {
 		return nil
 	}
-	sc, err := h.scCache.Get(*pvcSC)
+	sc, err := scCache.Get(*pvcSC)
 	if err != nil {
 		logrus.Errorf("Failed to get storage class %s: %v", *pvcSC, err)
 		return nil
@@ -317,8 +317,8 @@ func (h *vmImageDownloaderHandler) getAffinity(pvc *corev1.PersistentVolumeClaim
 	return affinity
 }
 
-func (h *vmImageDownloaderHandler) getVirtHandlerImage() string {
-	image, err := utilHelm.FetchImageFromHelmValues(h.clientSet,
+func getVirtHandlerImage(clientSet kubernetes.Interface) string {
+	image, err := utilHelm.FetchImageFromHelmValues(clientSet,
 		util.HarvesterSystemNamespaceName,
 		util.HarvesterChartReleaseName,
 		[]string{"kubevirt-operator", "containers", "handler", "image"})
@@ -329,8 +329,8 @@ func (h *vmImageDownloaderHandler) getVirtHandlerImage() string {
 	return targetImage
 }
 
-func (h *vmImageDownloaderHandler) getClusterRepoImage() string {
-	deployContent, err := h.clientSet.AppsV1().Deployments("cattle-system").Get(context.TODO(), "harvester-cluster-repo", metav1.GetOptions{})
+func getClusterRepoImage(clientSet kubernetes.Interface) string {
+	deployContent, err := clientSet.AppsV1().Deployments("cattle-system").Get(context.TODO(), "harvester-cluster-repo", metav1.GetOptions{})
 	if err != nil {
 		logrus.Errorf("Failed to get the harvester-cluster-repo deployment: %v", err)
 		return ""
@@ -362,15 +362,19 @@ func (h *vmImageDownloaderHandler) ReconcileDeploymentOwners(_ string, _ string,
 }
 
 func (h *vmImageDownloaderHandler) genInitContainer(mode *corev1.PersistentVolumeMode, vmImageName string) corev1.Container {
-	virtImageStr := h.getVirtHandlerImage()
-	initContainer := corev1.Container{
-		Name:            "image-coverter",
-		Image:           virtImageStr,
+	return genConvertContainer("image-coverter", getVirtHandlerImage(h.clientSet), "image-vol", "image-dir", *mode, vmImageName+".qcow2")
+}
+
+// genConvertContainer generates the container converting the raw disk on the volume to the qcow2 file in the target volume
+func genConvertContainer(name, image, volumeName, targetVolumeName string, mode corev1.PersistentVolumeMode, targetFileName string) corev1.Container {
+	container := corev1.Container{
+		Name:            name,
+		Image:           image,
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		Command:         []string{"/bin/sh", "-c"},
 		VolumeMounts: []corev1.VolumeMount{
 			{
-				Name:      "image-dir",
+				Name:      targetVolumeName,
 				MountPath: "/image-dir",
 			},
 		},
@@ -378,26 +382,26 @@ func (h *vmImageDownloaderHandler) genInitContainer(mode *corev1.PersistentVolum
 
 	targetImgVolPath := "/tmp/image-vol"
 	convertSrcPath := targetImgVolPath
-	if *mode == corev1.PersistentVolumeFilesystem {
+	if mode == corev1.PersistentVolumeFilesystem {
 		convertSrcPath = fmt.Sprintf("%s/disk.img", targetImgVolPath)
 	}
-	if *mode == corev1.PersistentVolumeBlock {
-		initContainer.VolumeDevices = []corev1.VolumeDevice{
+	if mode == corev1.PersistentVolumeBlock {
+		container.VolumeDevices = []corev1.VolumeDevice{
 			{
-				Name:       "image-vol",
+				Name:       volumeName,
 				DevicePath: targetImgVolPath,
 			},
 		}
-	} else if *mode == corev1.PersistentVolumeFilesystem {
-		initContainer.VolumeMounts = append(initContainer.VolumeMounts, corev1.VolumeMount{
-			Name:      "image-vol",
+	} else if mode == corev1.PersistentVolumeFilesystem {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
 			MountPath: targetImgVolPath,
 		})
 	}
-	convertCmd := fmt.Sprintf("qemu-img convert -t none -T none -W -m 8 -f raw %s -O qcow2 -c -S 4K /image-dir/%s.qcow2", convertSrcPath, vmImageName)
+	convertCmd := fmt.Sprintf("qemu-img convert -t none -T none -W -m 8 -f raw %s -O qcow2 -c -S 4K /image-dir/%s", convertSrcPath, targetFileName)
 
-	initContainer.Args = []string{convertCmd}
-	return initContainer
+	container.Args = []string{convertCmd}
+	return container
 }
 
 func updateConds(curConds []harvesterv1.VirtualMachineImageDownloaderCondition, c harvesterv1.VirtualMachineImageDownloaderCondition) []harvesterv1.VirtualMachineImageDownloaderCondition {
@@ -13,6 +13,7 @@ import (
 	ctlsnapshotv1 "github.com/harvester/harvester/pkg/generated/controllers/snapshot.storage.k8s.io/v1"
 	"github.com/harvester/harvester/pkg/volumeremotebackup/common"
 	"github.com/harvester/harvester/pkg/volumeremotebackup/driver"
+	"github.com/harvester/harvester/pkg/volumeremotebackup/generic"
 	"github.com/harvester/harvester/pkg/volumeremotebackup/longhorn"
 )
 
@@ -183,7 +184,7 @@ func (r *remoteRestoreResolver) enqueue(namespace, name string) {
 	r.handler.vrrController.Enqueue(namespace, name)
 }
 
-func Register(ctx context.Context, management *config.Management, _ config.Options) error {
+func Register(ctx context.Context, management *config.Management, options config.Options) error {
 	pbs := management.HarvesterFactory.Harvesterhci().V1beta1().VolumeRemoteBackup()
 	vss := management.SnapshotFactory.Snapshot().V1().VolumeSnapshot()
 	vsClasses := management.SnapshotFactory.Snapshot().V1().VolumeSnapshotClass()
@@ -192,8 +193,13 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 	scs := management.StorageFactory.Storage().V1().StorageClass()
 	settings := management.HarvesterFactory.Harvesterhci().V1beta1().Setting()
 	prs := management.HarvesterFactory.Harvesterhci().V1beta1().VolumeRemoteRestore()
+	jobs := management.BatchFactory.Batch().V1().Job()
+	secrets := management.CoreFactory.Core().V1().Secret()
+	pvs := management.CoreFactory.Core().V1().PersistentVolume()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 
 	pbo := common.NewBackupOperator(pbs, pvcs.Cache(), scs.Cache(), settings.Cache())
+	mover := generic.NewMoverJobOperator(jobs.Cache(), jobs, secrets.Cache(), secrets, backupTargets.Cache(), management.ClientSet, options.Namespace)
 
 	// Create shared volumeSnapshotHandler
 	vsh := &volumeSnapshotHandler{
@@ -206,8 +212,8 @@ func Register(ctx context.Context, management *config.Management, _ config.Optio
 	}
 
 	// Register backup and restore handlers, passing the shared handlers
-	registerBackupHandlers(ctx, pbs, vss, vsClasses, vscs, pvcs, scs, pbo, vsh)
-	registerRestoreHandlers(ctx, prs, pbs, vss, vsClasses, vscs, pvcs, scs, settings, pbo, vsh, pvch)
+	registerBackupHandlers(ctx, pbs, vss, vsClasses, vscs, pvcs, scs, secrets, pbo, mover, vsh)
+	registerRestoreHandlers(ctx, prs, pbs, vss, vsClasses, vscs, pvcs, pvs, scs, settings, pbo, mover, vsh, pvch)
 
 	// Register the shared volumeSnapshotHandler once
 	vss.OnChange(ctx, volumeSnapshotControllerName, vsh.onChanged)
@@ -226,7 +232,9 @@ func registerBackupHandlers(
 	vscs ctlsnapshotv1.VolumeSnapshotContentController,
 	pvcs ctlcorev1.PersistentVolumeClaimController,
 	scs ctlstoragev1.StorageClassController,
+	secrets ctlcorev1.SecretController,
 	bo common.BackupOperator,
+	mover *generic.MoverJobOperator,
 	vsh *volumeSnapshotHandler,
 ) {
 	operations := map[harvesterv1.VolumeRemoteBackupType]driver.BackupOperation{
@@ -239,6 +247,17 @@ func registerBackupHandlers(
 			pvcs.Cache(),
 			scs.Cache(),
 		),
+		harvesterv1.VolumeRemoteBackupGeneric: generic.GetGenericBackupOperation(
+			bo,
+			mover,
+			vss.Cache(),
+			vss,
+			vscs.Cache(),
+			vscs,
+			pvcs.Cache(),
+			pvcs,
+			secrets.Cache(),
+		),
 	}
 
 	remotebackupHandler := &remoteBackupHandler{
@@ -263,9 +282,11 @@ func registerRestoreHandlers(
 	vsClasses ctlsnapshotv1.VolumeSnapshotClassController,
 	vscs ctlsnapshotv1.VolumeSnapshotContentController,
 	pvcs ctlcorev1.PersistentVolumeClaimController,
+	pvs ctlcorev1.PersistentVolumeController,
 	scs ctlstoragev1.StorageClassController,
 	settings ctlharvesterv1.SettingController,
 	bo common.BackupOperator,
+	mover *generic.MoverJobOperator,
 	vsh *volumeSnapshotHandler,
 	pvch *pvcHandler,
 ) {
@@ -285,6 +306,16 @@ func registerRestoreHandlers(
 			pvcs,
 			scs.Cache(),
 		),
+		harvesterv1.VolumeRemoteRestoreGeneric: generic.GetGenericRestoreOperation(
+			ro,
+			bo,
+			mover,
+			vrbs.Cache(),
+			pvcs.Cache(),
+			pvcs,
+			pvs.Cache(),
+			pvs,
+		),
 	}
 
 	remoteRestoreHandler := &remoteRestoreHandler{
@@ -27,6 +27,7 @@ func createCRDs(ctx context.Context, restConfig *rest.Config) error {
 	return factory.
 		BatchCreateCRDsIfNotExisted(
 			crd.NonNamespacedFromGV(harvesterv1.SchemeGroupVersion, "Setting", harvesterv1.Setting{}),
+			crd.NonNamespacedFromGV(harvesterv1.SchemeGroupVersion, "BackupTarget", harvesterv1.BackupTarget{}).WithStatus(),
 			crd.NonNamespacedFromGV(rancherv3.SchemeGroupVersion, "APIService", rancherv3.APIService{}),
 			crd.NonNamespacedFromGV(rancherv3.SchemeGroupVersion, "Setting", rancherv3.Setting{}),
 			crd.NonNamespacedFromGV(rancherv3.SchemeGroupVersion, "User", rancherv3.User{}),
@@ -54,6 +55,10 @@ func createCRDs(ctx context.Context, restConfig *rest.Config) error {
 			crd.FromGV(harvesterv1.SchemeGroupVersion, "ScheduleVMBackup", harvesterv1.ScheduleVMBackup{}),
 			crd.FromGV(harvesterv1.SchemeGroupVersion, "VolumeRemoteBackup", harvesterv1.VolumeRemoteBackup{}),
 			crd.FromGV(harvesterv1.SchemeGroupVersion, "VolumeRemoteRestore", harvesterv1.VolumeRemoteRestore{}),
+			crd.FromGV(harvesterv1.SchemeGroupVersion, "VirtualMachineBackupVerification", harvesterv1.VirtualMachineBackupVerification{}).WithStatus(),
+			crd.FromGV(harvesterv1.SchemeGroupVersion, "VirtualMachineBulkAction", harvesterv1.VirtualMachineBulkAction{}),
+			crd.FromGV(harvesterv1.SchemeGroupVersion, "ScheduleVMPowerAction", harvesterv1.ScheduleVMPowerAction{}),
+			crd.FromGV(harvesterv1.SchemeGroupVersion, "ImageReplication", harvesterv1.ImageReplication{}),
 			// The BackingImage struct is not compatible with wrangler schemas generation, pass nil as the workaround.
 			// The expected CRD will be applied by Longhorn chart.
 			crd.FromGV(lhv1beta2.SchemeGroupVersion, "BackingImage", nil),
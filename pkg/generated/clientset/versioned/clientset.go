@@ -34,6 +34,7 @@ import (
 	loggingv1beta1 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/logging.banzaicloud.io/v1beta1"
 	longhornv1beta2 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/longhorn.io/v1beta2"
 	managementv3 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/management.cattle.io/v3"
+	migrationsv1alpha1 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/migrations.kubevirt.io/v1alpha1"
 	monitoringv1 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/monitoring.coreos.com/v1"
 	networkv1beta1 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/network.harvesterhci.io/v1beta1"
 	networkingv1 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/networking.k8s.io/v1"
@@ -64,6 +65,7 @@ type Interface interface {
 	LoggingV1beta1() loggingv1beta1.LoggingV1beta1Interface
 	LonghornV1beta2() longhornv1beta2.LonghornV1beta2Interface
 	ManagementV3() managementv3.ManagementV3Interface
+	MigrationsV1alpha1() migrationsv1alpha1.MigrationsV1alpha1Interface
 	MonitoringV1() monitoringv1.MonitoringV1Interface
 	NetworkV1beta1() networkv1beta1.NetworkV1beta1Interface
 	NetworkingV1() networkingv1.NetworkingV1Interface
@@ -91,6 +93,7 @@ type Clientset struct {
 	loggingV1beta1      *loggingv1beta1.LoggingV1beta1Client
 	longhornV1beta2     *longhornv1beta2.LonghornV1beta2Client
 	managementV3        *managementv3.ManagementV3Client
+	migrationsV1alpha1  *migrationsv1alpha1.MigrationsV1alpha1Client
 	monitoringV1        *monitoringv1.MonitoringV1Client
 	networkV1beta1      *networkv1beta1.NetworkV1beta1Client
 	networkingV1        *networkingv1.NetworkingV1Client
@@ -167,6 +170,11 @@ func (c *Clientset) ManagementV3() managementv3.ManagementV3Interface {
 	return c.managementV3
 }
 
+// MigrationsV1alpha1 retrieves the MigrationsV1alpha1Client
+func (c *Clientset) MigrationsV1alpha1() migrationsv1alpha1.MigrationsV1alpha1Interface {
+	return c.migrationsV1alpha1
+}
+
 // MonitoringV1 retrieves the MonitoringV1Client
 func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
 	return c.monitoringV1
@@ -308,6 +316,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.migrationsV1alpha1, err = migrationsv1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 	cs.monitoringV1, err = monitoringv1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
@@ -378,6 +390,7 @@ func New(c rest.Interface) *Clientset {
 	cs.loggingV1beta1 = loggingv1beta1.New(c)
 	cs.longhornV1beta2 = longhornv1beta2.New(c)
 	cs.managementV3 = managementv3.New(c)
+	cs.migrationsV1alpha1 = migrationsv1alpha1.New(c)
 	cs.monitoringV1 = monitoringv1.New(c)
 	cs.networkV1beta1 = networkv1beta1.New(c)
 	cs.networkingV1 = networkingv1.New(c)
@@ -43,6 +43,7 @@ import (
 	serializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
 	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	uploadv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1"
 	clusterv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -65,6 +66,7 @@ var localSchemeBuilder = runtime.SchemeBuilder{
 	loggingv1beta1.AddToScheme,
 	longhornv1beta2.AddToScheme,
 	managementv3.AddToScheme,
+	migrationsv1alpha1.AddToScheme,
 	monitoringv1.AddToScheme,
 	networkv1beta1.AddToScheme,
 	networkingv1.AddToScheme,
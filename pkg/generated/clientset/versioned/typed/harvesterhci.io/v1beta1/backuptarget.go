@@ -0,0 +1,70 @@
+/*
+Copyright 2026 SUSE, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	context "context"
+
+	harvesterhciiov1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	scheme "github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// BackupTargetsGetter has a method to return a BackupTargetInterface.
+// A group's client should implement this interface.
+type BackupTargetsGetter interface {
+	BackupTargets() BackupTargetInterface
+}
+
+// BackupTargetInterface has methods to work with BackupTarget resources.
+type BackupTargetInterface interface {
+	Create(ctx context.Context, backupTarget *harvesterhciiov1beta1.BackupTarget, opts v1.CreateOptions) (*harvesterhciiov1beta1.BackupTarget, error)
+	Update(ctx context.Context, backupTarget *harvesterhciiov1beta1.BackupTarget, opts v1.UpdateOptions) (*harvesterhciiov1beta1.BackupTarget, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, backupTarget *harvesterhciiov1beta1.BackupTarget, opts v1.UpdateOptions) (*harvesterhciiov1beta1.BackupTarget, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*harvesterhciiov1beta1.BackupTarget, error)
+	List(ctx context.Context, opts v1.ListOptions) (*harvesterhciiov1beta1.BackupTargetList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *harvesterhciiov1beta1.BackupTarget, err error)
+	BackupTargetExpansion
+}
+
+// backupTargets implements BackupTargetInterface
+type backupTargets struct {
+	*gentype.ClientWithList[*harvesterhciiov1beta1.BackupTarget, *harvesterhciiov1beta1.BackupTargetList]
+}
+
+// newBackupTargets returns a BackupTargets
+func newBackupTargets(c *HarvesterhciV1beta1Client) *backupTargets {
+	return &backupTargets{
+		gentype.NewClientWithList[*harvesterhciiov1beta1.BackupTarget, *harvesterhciiov1beta1.BackupTargetList](
+			"backuptargets",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			"",
+			func() *harvesterhciiov1beta1.BackupTarget { return &harvesterhciiov1beta1.BackupTarget{} },
+			func() *harvesterhciiov1beta1.BackupTargetList { return &harvesterhciiov1beta1.BackupTargetList{} },
+		),
+	}
+}
@@ -32,6 +32,14 @@ func (c *FakeHarvesterhciV1beta1) Addons(namespace string) v1beta1.AddonInterfac
 	return newFakeAddons(c, namespace)
 }
 
+func (c *FakeHarvesterhciV1beta1) BackupTargets() v1beta1.BackupTargetInterface {
+	return newFakeBackupTargets(c)
+}
+
+func (c *FakeHarvesterhciV1beta1) ImageReplications(namespace string) v1beta1.ImageReplicationInterface {
+	return newFakeImageReplications(c, namespace)
+}
+
 func (c *FakeHarvesterhciV1beta1) KeyPairs(namespace string) v1beta1.KeyPairInterface {
 	return newFakeKeyPairs(c, namespace)
 }
@@ -48,6 +56,10 @@ func (c *FakeHarvesterhciV1beta1) ScheduleVMBackups(namespace string) v1beta1.Sc
 	return newFakeScheduleVMBackups(c, namespace)
 }
 
+func (c *FakeHarvesterhciV1beta1) ScheduleVMPowerActions(namespace string) v1beta1.ScheduleVMPowerActionInterface {
+	return newFakeScheduleVMPowerActions(c, namespace)
+}
+
 func (c *FakeHarvesterhciV1beta1) Settings() v1beta1.SettingInterface {
 	return newFakeSettings(c)
 }
@@ -72,6 +84,18 @@ func (c *FakeHarvesterhciV1beta1) VirtualMachineBackups(namespace string) v1beta
 	return newFakeVirtualMachineBackups(c, namespace)
 }
 
+func (c *FakeHarvesterhciV1beta1) VirtualMachineBackupVerifications(namespace string) v1beta1.VirtualMachineBackupVerificationInterface {
+	return newFakeVirtualMachineBackupVerifications(c, namespace)
+}
+
+func (c *FakeHarvesterhciV1beta1) VirtualMachineBulkActions(namespace string) v1beta1.VirtualMachineBulkActionInterface {
+	return newFakeVirtualMachineBulkActions(c, namespace)
+}
+
+func (c *FakeHarvesterhciV1beta1) VirtualMachineDownloaders(namespace string) v1beta1.VirtualMachineDownloaderInterface {
+	return newFakeVirtualMachineDownloaders(c, namespace)
+}
+
 func (c *FakeHarvesterhciV1beta1) VirtualMachineImages(namespace string) v1beta1.VirtualMachineImageInterface {
 	return newFakeVirtualMachineImages(c, namespace)
 }
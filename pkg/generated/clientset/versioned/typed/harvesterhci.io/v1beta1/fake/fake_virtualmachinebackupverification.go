@@ -0,0 +1,54 @@
+/*
+Copyright 2026 SUSE, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harvesterhciiov1beta1 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeVirtualMachineBackupVerifications implements VirtualMachineBackupVerificationInterface
+type fakeVirtualMachineBackupVerifications struct {
+	*gentype.FakeClientWithList[*v1beta1.VirtualMachineBackupVerification, *v1beta1.VirtualMachineBackupVerificationList]
+	Fake *FakeHarvesterhciV1beta1
+}
+
+func newFakeVirtualMachineBackupVerifications(fake *FakeHarvesterhciV1beta1, namespace string) harvesterhciiov1beta1.VirtualMachineBackupVerificationInterface {
+	return &fakeVirtualMachineBackupVerifications{
+		gentype.NewFakeClientWithList[*v1beta1.VirtualMachineBackupVerification, *v1beta1.VirtualMachineBackupVerificationList](
+			fake.Fake,
+			namespace,
+			v1beta1.SchemeGroupVersion.WithResource("virtualmachinebackupverifications"),
+			v1beta1.SchemeGroupVersion.WithKind("VirtualMachineBackupVerification"),
+			func() *v1beta1.VirtualMachineBackupVerification { return &v1beta1.VirtualMachineBackupVerification{} },
+			func() *v1beta1.VirtualMachineBackupVerificationList {
+				return &v1beta1.VirtualMachineBackupVerificationList{}
+			},
+			func(dst, src *v1beta1.VirtualMachineBackupVerificationList) { dst.ListMeta = src.ListMeta },
+			func(list *v1beta1.VirtualMachineBackupVerificationList) []*v1beta1.VirtualMachineBackupVerification {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1beta1.VirtualMachineBackupVerificationList, items []*v1beta1.VirtualMachineBackupVerification) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2026 SUSE, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harvesterhciiov1beta1 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeVirtualMachineDownloaders implements VirtualMachineDownloaderInterface
+type fakeVirtualMachineDownloaders struct {
+	*gentype.FakeClientWithList[*v1beta1.VirtualMachineDownloader, *v1beta1.VirtualMachineDownloaderList]
+	Fake *FakeHarvesterhciV1beta1
+}
+
+func newFakeVirtualMachineDownloaders(fake *FakeHarvesterhciV1beta1, namespace string) harvesterhciiov1beta1.VirtualMachineDownloaderInterface {
+	return &fakeVirtualMachineDownloaders{
+		gentype.NewFakeClientWithList[*v1beta1.VirtualMachineDownloader, *v1beta1.VirtualMachineDownloaderList](
+			fake.Fake,
+			namespace,
+			v1beta1.SchemeGroupVersion.WithResource("virtualmachinedownloaders"),
+			v1beta1.SchemeGroupVersion.WithKind("VirtualMachineDownloader"),
+			func() *v1beta1.VirtualMachineDownloader { return &v1beta1.VirtualMachineDownloader{} },
+			func() *v1beta1.VirtualMachineDownloaderList { return &v1beta1.VirtualMachineDownloaderList{} },
+			func(dst, src *v1beta1.VirtualMachineDownloaderList) { dst.ListMeta = src.ListMeta },
+			func(list *v1beta1.VirtualMachineDownloaderList) []*v1beta1.VirtualMachineDownloader {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1beta1.VirtualMachineDownloaderList, items []*v1beta1.VirtualMachineDownloader) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -20,6 +20,10 @@ package v1beta1
 
 type AddonExpansion interface{}
 
+type BackupTargetExpansion interface{}
+
+type ImageReplicationExpansion interface{}
+
 type KeyPairExpansion interface{}
 
 type PreferenceExpansion interface{}
@@ -28,6 +32,8 @@ type ResourceQuotaExpansion interface{}
 
 type ScheduleVMBackupExpansion interface{}
 
+type ScheduleVMPowerActionExpansion interface{}
+
 type SettingExpansion interface{}
 
 type SupportBundleExpansion interface{}
@@ -40,6 +46,12 @@ type VersionExpansion interface{}
 
 type VirtualMachineBackupExpansion interface{}
 
+type VirtualMachineBackupVerificationExpansion interface{}
+
+type VirtualMachineBulkActionExpansion interface{}
+
+type VirtualMachineDownloaderExpansion interface{}
+
 type VirtualMachineImageExpansion interface{}
 
 type VirtualMachineImageDownloaderExpansion interface{}
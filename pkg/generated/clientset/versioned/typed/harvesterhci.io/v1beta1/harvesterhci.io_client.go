@@ -29,16 +29,22 @@ import (
 type HarvesterhciV1beta1Interface interface {
 	RESTClient() rest.Interface
 	AddonsGetter
+	BackupTargetsGetter
+	ImageReplicationsGetter
 	KeyPairsGetter
 	PreferencesGetter
 	ResourceQuotasGetter
 	ScheduleVMBackupsGetter
+	ScheduleVMPowerActionsGetter
 	SettingsGetter
 	SupportBundlesGetter
 	UpgradesGetter
 	UpgradeLogsGetter
 	VersionsGetter
 	VirtualMachineBackupsGetter
+	VirtualMachineBackupVerificationsGetter
+	VirtualMachineBulkActionsGetter
+	VirtualMachineDownloadersGetter
 	VirtualMachineImagesGetter
 	VirtualMachineImageDownloadersGetter
 	VirtualMachineRestoresGetter
@@ -57,6 +63,14 @@ func (c *HarvesterhciV1beta1Client) Addons(namespace string) AddonInterface {
 	return newAddons(c, namespace)
 }
 
+func (c *HarvesterhciV1beta1Client) BackupTargets() BackupTargetInterface {
+	return newBackupTargets(c)
+}
+
+func (c *HarvesterhciV1beta1Client) ImageReplications(namespace string) ImageReplicationInterface {
+	return newImageReplications(c, namespace)
+}
+
 func (c *HarvesterhciV1beta1Client) KeyPairs(namespace string) KeyPairInterface {
 	return newKeyPairs(c, namespace)
 }
@@ -73,6 +87,10 @@ func (c *HarvesterhciV1beta1Client) ScheduleVMBackups(namespace string) Schedule
 	return newScheduleVMBackups(c, namespace)
 }
 
+func (c *HarvesterhciV1beta1Client) ScheduleVMPowerActions(namespace string) ScheduleVMPowerActionInterface {
+	return newScheduleVMPowerActions(c, namespace)
+}
+
 func (c *HarvesterhciV1beta1Client) Settings() SettingInterface {
 	return newSettings(c)
 }
@@ -97,6 +115,18 @@ func (c *HarvesterhciV1beta1Client) VirtualMachineBackups(namespace string) Virt
 	return newVirtualMachineBackups(c, namespace)
 }
 
+func (c *HarvesterhciV1beta1Client) VirtualMachineBackupVerifications(namespace string) VirtualMachineBackupVerificationInterface {
+	return newVirtualMachineBackupVerifications(c, namespace)
+}
+
+func (c *HarvesterhciV1beta1Client) VirtualMachineBulkActions(namespace string) VirtualMachineBulkActionInterface {
+	return newVirtualMachineBulkActions(c, namespace)
+}
+
+func (c *HarvesterhciV1beta1Client) VirtualMachineDownloaders(namespace string) VirtualMachineDownloaderInterface {
+	return newVirtualMachineDownloaders(c, namespace)
+}
+
 func (c *HarvesterhciV1beta1Client) VirtualMachineImages(namespace string) VirtualMachineImageInterface {
 	return newVirtualMachineImages(c, namespace)
 }
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 SUSE, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	context "context"
+
+	harvesterhciiov1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	scheme "github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// VirtualMachineBackupVerificationsGetter has a method to return a VirtualMachineBackupVerificationInterface.
+// A group's client should implement this interface.
+type VirtualMachineBackupVerificationsGetter interface {
+	VirtualMachineBackupVerifications(namespace string) VirtualMachineBackupVerificationInterface
+}
+
+// VirtualMachineBackupVerificationInterface has methods to work with VirtualMachineBackupVerification resources.
+type VirtualMachineBackupVerificationInterface interface {
+	Create(ctx context.Context, virtualMachineBackupVerification *harvesterhciiov1beta1.VirtualMachineBackupVerification, opts v1.CreateOptions) (*harvesterhciiov1beta1.VirtualMachineBackupVerification, error)
+	Update(ctx context.Context, virtualMachineBackupVerification *harvesterhciiov1beta1.VirtualMachineBackupVerification, opts v1.UpdateOptions) (*harvesterhciiov1beta1.VirtualMachineBackupVerification, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, virtualMachineBackupVerification *harvesterhciiov1beta1.VirtualMachineBackupVerification, opts v1.UpdateOptions) (*harvesterhciiov1beta1.VirtualMachineBackupVerification, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*harvesterhciiov1beta1.VirtualMachineBackupVerification, error)
+	List(ctx context.Context, opts v1.ListOptions) (*harvesterhciiov1beta1.VirtualMachineBackupVerificationList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *harvesterhciiov1beta1.VirtualMachineBackupVerification, err error)
+	VirtualMachineBackupVerificationExpansion
+}
+
+// virtualMachineBackupVerifications implements VirtualMachineBackupVerificationInterface
+type virtualMachineBackupVerifications struct {
+	*gentype.ClientWithList[*harvesterhciiov1beta1.VirtualMachineBackupVerification, *harvesterhciiov1beta1.VirtualMachineBackupVerificationList]
+}
+
+// newVirtualMachineBackupVerifications returns a VirtualMachineBackupVerifications
+func newVirtualMachineBackupVerifications(c *HarvesterhciV1beta1Client, namespace string) *virtualMachineBackupVerifications {
+	return &virtualMachineBackupVerifications{
+		gentype.NewClientWithList[*harvesterhciiov1beta1.VirtualMachineBackupVerification, *harvesterhciiov1beta1.VirtualMachineBackupVerificationList](
+			"virtualmachinebackupverifications",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			namespace,
+			func() *harvesterhciiov1beta1.VirtualMachineBackupVerification {
+				return &harvesterhciiov1beta1.VirtualMachineBackupVerification{}
+			},
+			func() *harvesterhciiov1beta1.VirtualMachineBackupVerificationList {
+				return &harvesterhciiov1beta1.VirtualMachineBackupVerificationList{}
+			},
+		),
+	}
+}
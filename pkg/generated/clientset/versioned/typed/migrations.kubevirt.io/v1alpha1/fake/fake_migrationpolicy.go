@@ -0,0 +1,52 @@
+/*
+Copyright 2026 SUSE, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	migrationskubevirtiov1alpha1 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/migrations.kubevirt.io/v1alpha1"
+	gentype "k8s.io/client-go/gentype"
+	v1alpha1 "kubevirt.io/api/migrations/v1alpha1"
+)
+
+// fakeMigrationPolicies implements MigrationPolicyInterface
+type fakeMigrationPolicies struct {
+	*gentype.FakeClientWithList[*v1alpha1.MigrationPolicy, *v1alpha1.MigrationPolicyList]
+	Fake *FakeMigrationsV1alpha1
+}
+
+func newFakeMigrationPolicies(fake *FakeMigrationsV1alpha1) migrationskubevirtiov1alpha1.MigrationPolicyInterface {
+	return &fakeMigrationPolicies{
+		gentype.NewFakeClientWithList[*v1alpha1.MigrationPolicy, *v1alpha1.MigrationPolicyList](
+			fake.Fake,
+			"",
+			v1alpha1.SchemeGroupVersion.WithResource("migrationpolicies"),
+			v1alpha1.SchemeGroupVersion.WithKind("MigrationPolicy"),
+			func() *v1alpha1.MigrationPolicy { return &v1alpha1.MigrationPolicy{} },
+			func() *v1alpha1.MigrationPolicyList { return &v1alpha1.MigrationPolicyList{} },
+			func(dst, src *v1alpha1.MigrationPolicyList) { dst.ListMeta = src.ListMeta },
+			func(list *v1alpha1.MigrationPolicyList) []*v1alpha1.MigrationPolicy {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1alpha1.MigrationPolicyList, items []*v1alpha1.MigrationPolicy) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -32,16 +32,22 @@ func init() {
 
 type Interface interface {
 	Addon() AddonController
+	BackupTarget() BackupTargetController
+	ImageReplication() ImageReplicationController
 	KeyPair() KeyPairController
 	Preference() PreferenceController
 	ResourceQuota() ResourceQuotaController
 	ScheduleVMBackup() ScheduleVMBackupController
+	ScheduleVMPowerAction() ScheduleVMPowerActionController
 	Setting() SettingController
 	SupportBundle() SupportBundleController
 	Upgrade() UpgradeController
 	UpgradeLog() UpgradeLogController
 	Version() VersionController
 	VirtualMachineBackup() VirtualMachineBackupController
+	VirtualMachineBackupVerification() VirtualMachineBackupVerificationController
+	VirtualMachineBulkAction() VirtualMachineBulkActionController
+	VirtualMachineDownloader() VirtualMachineDownloaderController
 	VirtualMachineImage() VirtualMachineImageController
 	VirtualMachineImageDownloader() VirtualMachineImageDownloaderController
 	VirtualMachineRestore() VirtualMachineRestoreController
@@ -65,6 +71,14 @@ func (v *version) Addon() AddonController {
 	return generic.NewController[*v1beta1.Addon, *v1beta1.AddonList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "Addon"}, "addons", true, v.controllerFactory)
 }
 
+func (v *version) BackupTarget() BackupTargetController {
+	return generic.NewNonNamespacedController[*v1beta1.BackupTarget, *v1beta1.BackupTargetList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "BackupTarget"}, "backuptargets", v.controllerFactory)
+}
+
+func (v *version) ImageReplication() ImageReplicationController {
+	return generic.NewController[*v1beta1.ImageReplication, *v1beta1.ImageReplicationList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "ImageReplication"}, "imagereplications", true, v.controllerFactory)
+}
+
 func (v *version) KeyPair() KeyPairController {
 	return generic.NewController[*v1beta1.KeyPair, *v1beta1.KeyPairList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "KeyPair"}, "keypairs", true, v.controllerFactory)
 }
@@ -81,6 +95,10 @@ func (v *version) ScheduleVMBackup() ScheduleVMBackupController {
 	return generic.NewController[*v1beta1.ScheduleVMBackup, *v1beta1.ScheduleVMBackupList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "ScheduleVMBackup"}, "schedulevmbackups", true, v.controllerFactory)
 }
 
+func (v *version) ScheduleVMPowerAction() ScheduleVMPowerActionController {
+	return generic.NewController[*v1beta1.ScheduleVMPowerAction, *v1beta1.ScheduleVMPowerActionList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "ScheduleVMPowerAction"}, "schedulevmpoweractions", true, v.controllerFactory)
+}
+
 func (v *version) Setting() SettingController {
 	return generic.NewNonNamespacedController[*v1beta1.Setting, *v1beta1.SettingList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "Setting"}, "settings", v.controllerFactory)
 }
@@ -105,6 +123,18 @@ func (v *version) VirtualMachineBackup() VirtualMachineBackupController {
 	return generic.NewController[*v1beta1.VirtualMachineBackup, *v1beta1.VirtualMachineBackupList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineBackup"}, "virtualmachinebackups", true, v.controllerFactory)
 }
 
+func (v *version) VirtualMachineBackupVerification() VirtualMachineBackupVerificationController {
+	return generic.NewController[*v1beta1.VirtualMachineBackupVerification, *v1beta1.VirtualMachineBackupVerificationList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineBackupVerification"}, "virtualmachinebackupverifications", true, v.controllerFactory)
+}
+
+func (v *version) VirtualMachineBulkAction() VirtualMachineBulkActionController {
+	return generic.NewController[*v1beta1.VirtualMachineBulkAction, *v1beta1.VirtualMachineBulkActionList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineBulkAction"}, "virtualmachinebulkactions", true, v.controllerFactory)
+}
+
+func (v *version) VirtualMachineDownloader() VirtualMachineDownloaderController {
+	return generic.NewController[*v1beta1.VirtualMachineDownloader, *v1beta1.VirtualMachineDownloaderList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineDownloader"}, "virtualmachinedownloaders", true, v.controllerFactory)
+}
+
 func (v *version) VirtualMachineImage() VirtualMachineImageController {
 	return generic.NewController[*v1beta1.VirtualMachineImage, *v1beta1.VirtualMachineImageList](schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineImage"}, "virtualmachineimages", true, v.controllerFactory)
 }
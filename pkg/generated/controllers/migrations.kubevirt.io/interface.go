@@ -0,0 +1,43 @@
+/*
+Copyright 2026 SUSE, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package migrations
+
+import (
+	v1alpha1 "github.com/harvester/harvester/pkg/generated/controllers/migrations.kubevirt.io/v1alpha1"
+	"github.com/rancher/lasso/pkg/controller"
+)
+
+type Interface interface {
+	V1alpha1() v1alpha1.Interface
+}
+
+type group struct {
+	controllerFactory controller.SharedControllerFactory
+}
+
+// New returns a new Interface.
+func New(controllerFactory controller.SharedControllerFactory) Interface {
+	return &group{
+		controllerFactory: controllerFactory,
+	}
+}
+
+func (g *group) V1alpha1() v1alpha1.Interface {
+	return v1alpha1.New(g.controllerFactory)
+}
@@ -10,27 +10,30 @@ import (
 	ctlharvesterv1beta1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/image/common"
 	"github.com/harvester/harvester/pkg/ref"
-	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
+	backuputil "github.com/harvester/harvester/pkg/util/backup"
 )
 
 // backingImageHandler syncs upload progress from backing image to vm image status
 type backingImageHandler struct {
 	vmiCache ctlharvesterv1beta1.VirtualMachineImageCache
+	btCache  ctlharvesterv1beta1.BackupTargetCache
 	vmio     common.VMIOperator
 }
 
 func (h *backingImageHandler) updateBackupTarget(vmi *harvesterv1.VirtualMachineImage) error {
-	target, err := settings.DecodeBackupTarget(settings.BackupTargetSet.Get())
+	// the backing image is restored from the backup target the image metadata is synced from
+	target, err := backuputil.GetBackupTarget(h.btCache, vmi.Annotations[util.AnnotationBackupTargetName])
 	if err != nil {
-		return fmt.Errorf("failed to decode backup target: %w", err)
+		return fmt.Errorf("failed to get backup target: %w", err)
 	}
 
 	if target.IsDefaultBackupTarget() {
 		return nil
 	}
 
-	_, err = h.vmio.UpdateBackupTarget(vmi, &harvesterv1.BackupTarget{
+	_, err = h.vmio.UpdateBackupTarget(vmi, &harvesterv1.BackupTargetInfo{
+		Name:         target.Name,
 		Endpoint:     target.Endpoint,
 		BucketName:   target.BucketName,
 		BucketRegion: target.BucketRegion,
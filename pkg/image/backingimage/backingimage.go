@@ -5,6 +5,7 @@ import (
 	errs "errors"
 	"fmt"
 	"strconv"
+	"sync"
 
 	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 	lhmanager "github.com/longhorn/longhorn-manager/manager"
@@ -16,6 +17,7 @@ import (
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/ptr"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
@@ -24,7 +26,6 @@ import (
 	"github.com/harvester/harvester/pkg/image/backend"
 	"github.com/harvester/harvester/pkg/image/common"
 	"github.com/harvester/harvester/pkg/ref"
-	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
 	backuputil "github.com/harvester/harvester/pkg/util/backup"
 )
@@ -45,18 +46,24 @@ type Backend struct {
 	biController ctllhv1.BackingImageController
 	biClient     ctllhv1.BackingImageClient
 	biCache      ctllhv1.BackingImageCache
+	bidsClient   ctllhv1.BackingImageDataSourceClient
 	pvcCache     ctlcorev1.PersistentVolumeClaimCache
 	secretCache  ctlcorev1.SecretCache
+	btCache      ctlharvesterv1.BackupTargetCache
 	vmiClient    ctlharvesterv1.VirtualMachineImageClient
 	vmiCache     ctlharvesterv1.VirtualMachineImageCache
 	vmio         common.VMIOperator
+	// clientSet runs the jobs of the imported images
+	clientSet kubernetes.Interface
+	// imports are the cancel functions of the imports uploaded by harvester, keyed by the VM image
+	imports sync.Map
 }
 
 func GetBackend(ctx context.Context, scClient ctlstoragev1.StorageClassClient, scCache ctlstoragev1.StorageClassCache,
 	biController ctllhv1.BackingImageController, biClient ctllhv1.BackingImageClient, biCache ctllhv1.BackingImageCache,
-	pvcCache ctlcorev1.PersistentVolumeClaimCache, secretCache ctlcorev1.SecretCache,
-	vmiClient ctlharvesterv1.VirtualMachineImageClient, vmiCache ctlharvesterv1.VirtualMachineImageCache,
-	vmio common.VMIOperator) backend.Backend {
+	bidsClient ctllhv1.BackingImageDataSourceClient, pvcCache ctlcorev1.PersistentVolumeClaimCache, secretCache ctlcorev1.SecretCache,
+	btCache ctlharvesterv1.BackupTargetCache, vmiClient ctlharvesterv1.VirtualMachineImageClient, vmiCache ctlharvesterv1.VirtualMachineImageCache,
+	vmio common.VMIOperator, clientSet kubernetes.Interface) backend.Backend {
 	return &Backend{
 		ctx:          ctx,
 		scClient:     scClient,
@@ -64,11 +71,14 @@ func GetBackend(ctx context.Context, scClient ctlstoragev1.StorageClassClient, s
 		biController: biController,
 		biClient:     biClient,
 		biCache:      biCache,
+		bidsClient:   bidsClient,
 		pvcCache:     pvcCache,
 		secretCache:  secretCache,
+		btCache:      btCache,
 		vmiClient:    vmiClient,
 		vmiCache:     vmiCache,
 		vmio:         vmio,
+		clientSet:    clientSet,
 	}
 }
 
@@ -133,6 +143,11 @@ func (bib *Backend) createBackingImage(vmi *harvesterv1.VirtualMachineImage) err
 
 	switch vmio.GetSourceType(vmi) {
 	case harvesterv1.VirtualMachineImageSourceTypeDownload:
+		if needsImport(vmi) {
+			// Longhorn can't send the credentials or convert the image, the image is uploaded by importImage
+			bi.Spec.SourceType = lhv1beta2.BackingImageDataSourceTypeUpload
+			break
+		}
 		bi.Spec.SourceParameters[lhv1beta2.DataSourceTypeDownloadParameterURL] = vmio.GetURL(vmi)
 	case harvesterv1.VirtualMachineImageSourceTypeExportVolume:
 		pvc, err := bib.pvcCache.Get(vmio.GetPVCNamespace(vmi), vmio.GetPVCName(vmi))
@@ -144,6 +159,10 @@ func (bib *Backend) createBackingImage(vmi *harvesterv1.VirtualMachineImage) err
 		bi.Spec.SourceParameters[lhmanager.DataSourceTypeExportFromVolumeParameterExportType] = lhmanager.DataSourceTypeExportFromVolumeParameterExportTypeRAW
 	case harvesterv1.VirtualMachineImageSourceTypeRestore:
 		bi.Spec.SourceParameters[lhv1beta2.DataSourceTypeRestoreParameterBackupURL] = vmio.GetURL(vmi)
+		bi.Spec.SourceParameters[lhv1beta2.DataSourceTypeRestoreParameterBackupTargetName] = backuputil.GetLonghornBackupTargetName(vmi.Annotations[util.AnnotationBackupTargetName])
+	case harvesterv1.VirtualMachineImageSourceTypeRegistry:
+		// Longhorn can't pull images from registries, the disk is uploaded by importImage
+		bi.Spec.SourceType = lhv1beta2.BackingImageDataSourceTypeUpload
 	case harvesterv1.VirtualMachineImageSourceTypeClone:
 		bi.Spec.SourceParameters[lhv1beta2.DataSourceTypeCloneParameterEncryption] = vmio.GetSecurityCryptoOption(vmi)
 
@@ -170,6 +189,11 @@ func (bib *Backend) createBackingImage(vmi *harvesterv1.VirtualMachineImage) err
 		bi.Spec.SourceParameters[lhv1beta2.DataSourceTypeCloneParameterSecretNamespace] = vmio.GetSCParameters(targetImage)[util.CSINodePublishSecretNamespaceKey]
 	}
 
+	if bi.Spec.SourceType == lhv1beta2.BackingImageDataSourceTypeUpload {
+		// the uploads may be converted from the source, whose checksum is verified by harvester instead
+		bi.Spec.Checksum = ""
+	}
+
 	_, err = bib.biClient.Create(bi)
 	return err
 }
@@ -214,6 +238,7 @@ func (bib *Backend) createBackingImageAndStorageClass(vmi *harvesterv1.VirtualMa
 }
 
 func (bib *Backend) Initialize(vmi *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
+	bib.stopImport(vmi)
 	if err := bib.deleteBackingImageAndStorageClass(vmi); err != nil {
 		return vmi, err
 	}
@@ -222,11 +247,24 @@ func (bib *Backend) Initialize(vmi *harvesterv1.VirtualMachineImage) (*harvester
 	if err != nil {
 		return checkedImg, err
 	}
+	if checkedImg.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeDownload {
+		// the detected format decides whether Longhorn downloads the image or harvester converts and uploads it
+		img, err := common.ProbeDownload(bib.ctx, bib.secretCache, checkedImg)
+		if err != nil {
+			return checkedImg, err
+		}
+		if checkedImg, err = bib.vmio.UpdateSourceFormat(checkedImg, img.Format, img.Compression); err != nil {
+			return checkedImg, err
+		}
+	}
 
 	toUpdate, err := bib.createBackingImageAndStorageClass(checkedImg)
 	if err != nil {
 		return toUpdate, err
 	}
+	if needsImport(toUpdate) {
+		bib.startImport(toUpdate)
+	}
 	return bib.vmio.UpdateVMI(checkedImg, toUpdate)
 }
 
@@ -244,10 +282,25 @@ func (bib *Backend) Check(vmi *harvesterv1.VirtualMachineImage) error {
 		return common.ErrRetryLater
 	}
 
+	ready := false
 	for _, status := range bi.Status.DiskFileStatusMap {
 		if status.State == lhv1beta2.BackingImageStateFailed {
 			return common.ErrRetryAble
 		}
+		ready = ready || status.State == lhv1beta2.BackingImageStateReady
+	}
+
+	// the import uploaded by harvester is lost if harvester restarts, the pending backing image is removed for
+	// the image to be initialized and imported again once it's gone
+	if !ready && needsImport(vmi) && !bib.isImportStarted(vmi) {
+		logrus.WithFields(logrus.Fields{
+			"namespace": vmi.Namespace,
+			"name":      vmi.Name,
+		}).Info("restarting the lost import of vm image")
+		if err := bib.deleteBackingImage(vmi); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return common.ErrRetryLater
 	}
 
 	storageClassName := bib.vmio.GetStorageClassName(vmi)
@@ -277,12 +330,25 @@ func (bib *Backend) UpdateVirtualSize(vmi *harvesterv1.VirtualMachineImage) (*ha
 }
 
 func (bib *Backend) deleteVMImageMetadata(vmi *harvesterv1.VirtualMachineImage) error {
-	target, err := settings.DecodeBackupTarget(settings.BackupTargetSet.Get())
+	vmio := bib.vmio
+	// the metadata is deleted from the backup target it was uploaded to
+	backupTargetName := ""
+	if vmi.Status.BackupTarget != nil {
+		backupTargetName = vmi.Status.BackupTarget.Name
+	}
+	target, err := backuputil.GetBackupTarget(bib.btCache, backupTargetName)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			logrus.WithFields(logrus.Fields{
+				"namespace":    vmio.GetNamespace(vmi),
+				"name":         vmio.GetName(vmi),
+				"backupTarget": backupTargetName,
+			}).Debugf("skip deleting vm image metadata, because backup target is not found")
+			return nil
+		}
 		return err
 	}
 
-	vmio := bib.vmio
 	// when backup target has been reset to default, skip following
 	if target.IsDefaultBackupTarget() {
 		logrus.WithFields(logrus.Fields{
@@ -320,6 +386,7 @@ func (bib *Backend) deleteVMImageMetadata(vmi *harvesterv1.VirtualMachineImage)
 }
 
 func (bib *Backend) Delete(vmi *harvesterv1.VirtualMachineImage) error {
+	bib.stopImport(vmi)
 	if err := bib.deleteBackingImageAndStorageClass(vmi); err != nil {
 		return err
 	}
@@ -330,6 +397,7 @@ func (bib *Backend) Delete(vmi *harvesterv1.VirtualMachineImage) error {
 func (bib *Backend) AddSidecarHandler() {
 	backingImageHandler := &backingImageHandler{
 		vmiCache: bib.vmiCache,
+		btCache:  bib.btCache,
 		vmio:     bib.vmio,
 	}
 
@@ -0,0 +1,152 @@
+package backingimage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/image/importer"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// needsImport returns whether the image is uploaded to the backing image by harvester, since Longhorn can't pull
+// images from registries, send credentials with the downloads or convert the images.
+func needsImport(vmi *harvesterv1.VirtualMachineImage) bool {
+	switch vmi.Spec.SourceType {
+	case harvesterv1.VirtualMachineImageSourceTypeRegistry:
+		return true
+	case harvesterv1.VirtualMachineImageSourceTypeDownload:
+		return vmi.Spec.DownloadSecretName != "" || needsConversion(vmi)
+	}
+	return false
+}
+
+// needsConversion returns whether the detected source format of the image has to be converted for Longhorn
+func needsConversion(vmi *harvesterv1.VirtualMachineImage) bool {
+	return vmi.Status.SourceCompression != "" || !isLonghornFormat(vmi.Status.SourceFormat)
+}
+
+// isLonghornFormat returns whether Longhorn imports the disk format as is, the format is empty if not detected yet
+func isLonghornFormat(diskFormat harvesterv1.VirtualMachineImageFormat) bool {
+	switch diskFormat {
+	case "", harvesterv1.VirtualMachineImageFormatQCOW2, harvesterv1.VirtualMachineImageFormatRaw, harvesterv1.VirtualMachineImageFormatISO:
+		return true
+	}
+	return false
+}
+
+// startImport starts the import in the background, the entry of the import is kept after it's done until the image is
+// initialized again or deleted, so isImportStarted tells the imports lost by a restart of harvester
+func (bib *Backend) startImport(vmi *harvesterv1.VirtualMachineImage) {
+	ctx, cancel := context.WithCancel(bib.ctx)
+	key := ref.Construct(vmi.Namespace, vmi.Name)
+	if previous, loaded := bib.imports.Swap(key, cancel); loaded {
+		previous.(context.CancelFunc)()
+	}
+
+	go func() {
+		defer cancel()
+		bib.importImage(ctx, vmi)
+	}()
+}
+
+func (bib *Backend) isImportStarted(vmi *harvesterv1.VirtualMachineImage) bool {
+	_, ok := bib.imports.Load(ref.Construct(vmi.Namespace, vmi.Name))
+	return ok
+}
+
+func (bib *Backend) stopImport(vmi *harvesterv1.VirtualMachineImage) {
+	if cancel, loaded := bib.imports.LoadAndDelete(ref.Construct(vmi.Namespace, vmi.Name)); loaded {
+		cancel.(context.CancelFunc)()
+	}
+}
+
+// importImage uploads the image to the backing image, the progress is synced from the backing image like the
+// uploaded images. If the import fails before the upload starts, the backing image stays pending, so it's removed
+// for the VM image controller to initialize it again.
+func (bib *Backend) importImage(ctx context.Context, vmi *harvesterv1.VirtualMachineImage) {
+	err := bib.upload(ctx, vmi)
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+
+	logrus.WithError(err).WithFields(logrus.Fields{
+		"namespace": vmi.Namespace,
+		"name":      vmi.Name,
+	}).Error("failed to import vm image")
+
+	current, getErr := bib.vmiCache.Get(vmi.Namespace, vmi.Name)
+	if getErr != nil || current.DeletionTimestamp != nil {
+		return
+	}
+	if _, err := bib.vmio.FailImported(current, err, current.Status.Progress); err != nil {
+		logrus.WithError(err).Errorf("failed to update vm image %s/%s", vmi.Namespace, vmi.Name)
+	}
+	if err := bib.deleteBackingImage(current); err != nil && !apierrors.IsNotFound(err) {
+		logrus.WithError(err).Errorf("failed to delete backing image of vm image %s/%s", vmi.Namespace, vmi.Name)
+	}
+}
+
+// importSource returns the source of the job importing the image, it has the credentials of the secret the
+// image references, the webhook checked the user creating the image can get it.
+func (bib *Backend) importSource(vmi *harvesterv1.VirtualMachineImage) (*importer.Source, error) {
+	source := &importer.Source{
+		Type:     bib.vmio.GetSourceType(vmi),
+		URL:      bib.vmio.GetURL(vmi),
+		Name:     bib.vmio.GetDisplayName(vmi),
+		Checksum: bib.vmio.GetChecksum(vmi),
+	}
+	var err error
+	switch source.Type {
+	case harvesterv1.VirtualMachineImageSourceTypeRegistry:
+		source.RegistryCredentials, err = common.GetRegistryCredentials(bib.secretCache, vmi)
+	case harvesterv1.VirtualMachineImageSourceTypeDownload:
+		source.DownloadCredentials, err = common.GetDownloadCredentials(bib.secretCache, vmi)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// upload imports the image in the job, which fetches the image, converts it and uploads it to the backing image
+// data source. Only the header is read by harvester to detect the format and the size of the disk, they decide
+// the conversion and the scratch volume of the job.
+func (bib *Backend) upload(ctx context.Context, vmi *harvesterv1.VirtualMachineImage) error {
+	dsName, err := util.GetBackingImageDataSourceName(bib.biCache, vmi)
+	if err != nil {
+		return fmt.Errorf("failed to get backing image name for VMImage %s/%s, error: %w", vmi.Namespace, vmi.Name, err)
+	}
+	if err := waitForBackingImageDataSourceReady(bib.bidsClient, dsName); err != nil {
+		return err
+	}
+
+	source, err := bib.importSource(vmi)
+	if err != nil {
+		return err
+	}
+	image, size, err := importer.Open(ctx, source)
+	if err != nil {
+		return err
+	}
+	img, err := importer.Probe(image, size)
+	image.Close()
+	if err != nil {
+		return fmt.Errorf("failed to detect the format of image %s: %w", source.URL, err)
+	}
+	source.DiskFormat, source.Compression = img.DiskFormat, img.Compression
+	if err := common.RecordSourceFormat(bib.vmio, vmi, img); err != nil {
+		return err
+	}
+
+	if err := runImportJob(ctx, bib.clientSet, vmi, dsName, source, img, nil, 0); err != nil {
+		return fmt.Errorf("failed to import image %s: %w", source.URL, err)
+	}
+	logrus.Infof("Imported image %s to backing image data source %s", source.URL, dsName)
+	return nil
+}
@@ -0,0 +1,455 @@
+package backingimage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	wranglername "github.com/rancher/wrangler/v3/pkg/name"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/image/format"
+	"github.com/harvester/harvester/pkg/image/importer"
+	"github.com/harvester/harvester/pkg/util"
+	utilHelm "github.com/harvester/harvester/pkg/util/helm"
+)
+
+const (
+	importJobPrefix      = "image-import"
+	importerCommand      = "image-importer"
+	fetchContainerName   = "fetch"
+	convertContainerName = "convert"
+	uploadContainerName  = "upload"
+
+	importSourceVolume  = "source"
+	importSourceDir     = "/etc/image-importer"
+	importSourceKey     = "source.json"
+	importScratchVolume = "scratch"
+	importScratchPath   = "/scratch"
+	importSourcePath    = importScratchPath + "/source"
+	importDiskPath      = importScratchPath + "/disk.qcow2"
+	importUploadPort    = 8080
+
+	// importScratchOverhead is added to the scratch volume for the metadata of the converted disk
+	importScratchOverhead = 1 << 30
+	// importJobDeadlineSeconds ends the job left behind, e.g. when harvester restarts during the import
+	importJobDeadlineSeconds = 24 * 60 * 60
+	importJobTTLSeconds      = 60
+	// importUser is the qemu user of the virt-handler image
+	importUser = 107
+
+	importPodTimeout   = 10 * time.Minute
+	importPollInterval = 2 * time.Second
+	uploadTokenBytes   = 32
+)
+
+// qemuImgFormats are the qemu-img formats of the disks converted by the job. The format is always passed to
+// qemu-img, so it never probes the format of the untrusted disk.
+var qemuImgFormats = map[harvesterv1.VirtualMachineImageFormat]string{
+	harvesterv1.VirtualMachineImageFormatRaw:   "raw",
+	harvesterv1.VirtualMachineImageFormatISO:   "raw",
+	harvesterv1.VirtualMachineImageFormatQCOW2: "qcow2",
+	harvesterv1.VirtualMachineImageFormatVMDK:  "vmdk",
+	harvesterv1.VirtualMachineImageFormatVHD:   "vpc",
+	harvesterv1.VirtualMachineImageFormatVHDX:  "vhdx",
+	harvesterv1.VirtualMachineImageFormatVDI:   "vdi",
+}
+
+// needsDiskConversion returns whether the disk is converted by qemu-img in the job, Longhorn can't import the
+// other formats. The size of the decompressed disks is known once the job wrote them to the scratch volume.
+func needsDiskConversion(img *format.Image) bool {
+	return !isLonghornFormat(img.DiskFormat)
+}
+
+func importJobName(vmi *harvesterv1.VirtualMachineImage) string {
+	return wranglername.SafeConcatName(importJobPrefix, vmi.Namespace, vmi.Name)
+}
+
+// importJobImages are the harvester image running the importer and the virt-handler image having qemu-img
+type importJobImages struct {
+	importer  string
+	converter string
+}
+
+func getImportJobImages(clientSet kubernetes.Interface) (*importJobImages, error) {
+	harvesterImage, err := utilHelm.FetchImageFromHelmValues(clientSet, util.HarvesterSystemNamespaceName, util.HarvesterChartReleaseName,
+		[]string{"containers", "apiserver", "image"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvester image: %w", err)
+	}
+	handlerImage, err := utilHelm.FetchImageFromHelmValues(clientSet, util.HarvesterSystemNamespaceName, util.HarvesterChartReleaseName,
+		[]string{"kubevirt-operator", "containers", "handler", "image"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virt-handler image: %w", err)
+	}
+	return &importJobImages{importer: harvesterImage.ImageName(), converter: handlerImage.ImageName()}, nil
+}
+
+// newImportJob returns the job importing the image to the backing image data source. The fetch container
+// downloads, pulls or receives the uploaded image and writes the disk to the scratch volume, the convert container
+// converts it with qemu-img if needed, and the upload container uploads the disk to the data source. The scratch
+// volume is sized for the disk and the converted one, bounded by the virtual size read from the header, so the
+// import fails instead of filling the storage if the header lies.
+func newImportJob(name, dsName string, images *importJobImages, source *importer.Source, img *format.Image) (*batchv1.Job, error) {
+	diskSize := img.Size
+	if diskSize < 0 {
+		diskSize = img.VirtualSize
+	}
+	if diskSize <= 0 {
+		return nil, fmt.Errorf("%w: the size of the %s %s disk is unknown", format.ErrUnsupported, img.Compression, img.DiskFormat)
+	}
+	if diskSize > format.MaxVirtualSize {
+		return nil, fmt.Errorf("%w: the size %d of the %s disk exceeds %d", format.ErrUnsupported, diskSize, img.DiskFormat, format.MaxVirtualSize)
+	}
+	scratchSize := diskSize + importScratchOverhead
+
+	securityContext := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+	scratchMount := corev1.VolumeMount{Name: importScratchVolume, MountPath: importScratchPath}
+
+	fetch := corev1.Container{
+		Name:            fetchContainerName,
+		Image:           images.importer,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{importerCommand, "fetch"},
+		Args:            []string{"--source", importSourceDir + "/" + importSourceKey, "--disk", importSourcePath},
+		VolumeMounts: []corev1.VolumeMount{
+			scratchMount,
+			{Name: importSourceVolume, MountPath: importSourceDir, ReadOnly: true},
+		},
+		SecurityContext:          securityContext,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+	if source.Type == harvesterv1.VirtualMachineImageSourceTypeUpload {
+		fetch.Args = append(fetch.Args, "--listen", ":"+strconv.Itoa(importUploadPort))
+		fetch.Ports = []corev1.ContainerPort{{Name: "upload", ContainerPort: importUploadPort}}
+	}
+	initContainers := []corev1.Container{fetch}
+
+	diskPath := importSourcePath
+	if needsDiskConversion(img) {
+		qemuImgFormat, ok := qemuImgFormats[img.DiskFormat]
+		if !ok {
+			return nil, fmt.Errorf("%w: format %s", format.ErrUnsupported, img.DiskFormat)
+		}
+		if img.VirtualSize <= 0 {
+			return nil, fmt.Errorf("%w: the virtual size of the %s %s disk is unknown", format.ErrUnsupported, img.Compression, img.DiskFormat)
+		}
+		scratchSize += img.VirtualSize
+		initContainers = append(initContainers, corev1.Container{
+			Name:                     convertContainerName,
+			Image:                    images.converter,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			Command:                  []string{"qemu-img", "convert", "-f", qemuImgFormat, "-O", "qcow2", importSourcePath, importDiskPath},
+			VolumeMounts:             []corev1.VolumeMount{scratchMount},
+			SecurityContext:          securityContext,
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		})
+		diskPath = importDiskPath
+	}
+
+	upload := corev1.Container{
+		Name:                     uploadContainerName,
+		Image:                    images.importer,
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+		Command:                  []string{importerCommand, "upload"},
+		Args:                     []string{"--disk", diskPath, "--data-source", dsName, "--name", source.Name},
+		VolumeMounts:             []corev1.VolumeMount{scratchMount},
+		SecurityContext:          securityContext,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: util.HarvesterSystemNamespaceName,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To(int32(0)),
+			ActiveDeadlineSeconds:   ptr.To(int64(importJobDeadlineSeconds)),
+			TTLSecondsAfterFinished: ptr.To(int32(importJobTTLSeconds)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: ptr.To(true),
+						RunAsUser:    ptr.To(int64(importUser)),
+						RunAsGroup:   ptr.To(int64(importUser)),
+						FSGroup:      ptr.To(int64(importUser)),
+					},
+					InitContainers: initContainers,
+					Containers:     []corev1.Container{upload},
+					Volumes: []corev1.Volume{
+						{
+							Name: importScratchVolume,
+							VolumeSource: corev1.VolumeSource{
+								Ephemeral: &corev1.EphemeralVolumeSource{
+									VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+										Spec: corev1.PersistentVolumeClaimSpec{
+											AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+											Resources: corev1.VolumeResourceRequirements{
+												Requests: corev1.ResourceList{
+													corev1.ResourceStorage: *resource.NewQuantity(scratchSize, resource.BinarySI),
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+						{
+							Name: importSourceVolume,
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName:  name,
+									DefaultMode: ptr.To(int32(0440)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// runImportJob imports the image to the backing image data source in the job, the uploaded image is read from
+// upload and sent to the job, the others are fetched by the job itself. The job is deleted once it's done.
+func runImportJob(
+	ctx context.Context,
+	clientSet kubernetes.Interface,
+	vmi *harvesterv1.VirtualMachineImage,
+	dsName string,
+	source *importer.Source,
+	img *format.Image,
+	upload io.Reader,
+	uploadSize int64,
+) error {
+	images, err := getImportJobImages(clientSet)
+	if err != nil {
+		return err
+	}
+	if source.Type == harvesterv1.VirtualMachineImageSourceTypeUpload {
+		token := make([]byte, uploadTokenBytes)
+		if _, err := rand.Read(token); err != nil {
+			return err
+		}
+		source.UploadToken = hex.EncodeToString(token)
+	}
+	job, err := newImportJob(importJobName(vmi), dsName, images, source, img)
+	if err != nil {
+		return err
+	}
+
+	defer deleteImportJob(clientSet, job.Name)
+	if job, err = startImportJob(ctx, clientSet, job, source); err != nil {
+		return err
+	}
+	if upload != nil {
+		if err := sendUpload(ctx, clientSet, job, source.UploadToken, upload, uploadSize); err != nil {
+			return err
+		}
+	}
+	return waitForImportJob(ctx, clientSet, job)
+}
+
+// startImportJob creates the job and the secret of its source, the job and the secret left behind by a previous
+// import of the image are deleted first.
+func startImportJob(ctx context.Context, clientSet kubernetes.Interface, job *batchv1.Job, source *importer.Source) (*batchv1.Job, error) {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := clientSet.BatchV1().Jobs(job.Namespace)
+	secrets := clientSet.CoreV1().Secrets(job.Namespace)
+	if err := jobs.Delete(ctx, job.Name, metav1.DeleteOptions{PropagationPolicy: ptr.To(metav1.DeletePropagationBackground)}); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err := secrets.Delete(ctx, job.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	var created *batchv1.Job
+	err = wait.PollUntilContextTimeout(ctx, importPollInterval, importPodTimeout, true, func(ctx context.Context) (bool, error) {
+		// the previous job may still be deleted
+		if created, err = jobs.Create(ctx, job, metav1.CreateOptions{}); err != nil {
+			return false, ignoreAlreadyExists(err)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import job %s: %w", job.Name, err)
+	}
+
+	// the pod of the job waits for the secret, which is garbage collected with the job
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      created.Name,
+			Namespace: created.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: batchv1.SchemeGroupVersion.String(),
+					Kind:       "Job",
+					Name:       created.Name,
+					UID:        created.UID,
+				},
+			},
+		},
+		Data: map[string][]byte{importSourceKey: data},
+	}
+	err = wait.PollUntilContextTimeout(ctx, importPollInterval, importPodTimeout, true, func(ctx context.Context) (bool, error) {
+		if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return false, ignoreAlreadyExists(err)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the secret of import job %s: %w", job.Name, err)
+	}
+	return created, nil
+}
+
+// sendUpload sends the uploaded image to the fetch container of the job through the pod network. It returns once
+// the disk is written to the scratch volume, the response has the error of the fetch container.
+func sendUpload(ctx context.Context, clientSet kubernetes.Interface, job *batchv1.Job, token string, upload io.Reader, size int64) error {
+	var address string
+	err := wait.PollUntilContextTimeout(ctx, importPollInterval, importPodTimeout, true, func(ctx context.Context) (bool, error) {
+		pod, err := getImportPod(ctx, clientSet, job)
+		if err != nil || pod == nil {
+			return false, err
+		}
+		if pod.Status.Phase == corev1.PodFailed {
+			return false, fmt.Errorf("pod %s failed: %w", pod.Name, importPodError(pod))
+		}
+		if pod.Status.PodIP == "" || !isContainerRunning(pod.Status.InitContainerStatuses, fetchContainerName) {
+			return false, nil
+		}
+
+		// the container is running before it listens
+		address = net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(importUploadPort))
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return false, nil
+		}
+		conn.Close()
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to wait for import job %s: %w", job.Name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, "http://"+address+importer.UploadPath, upload)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size <= 0 {
+		req.ContentLength = -1
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the image to import job %s: %w", job.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("import job %s failed: %s", job.Name, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// waitForImportJob waits for the job to complete, the job ends by itself at its deadline
+func waitForImportJob(ctx context.Context, clientSet kubernetes.Interface, job *batchv1.Job) error {
+	return wait.PollUntilContextCancel(ctx, importPollInterval, true, func(ctx context.Context) (bool, error) {
+		current, err := clientSet.BatchV1().Jobs(job.Namespace).Get(ctx, job.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, condition := range current.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch condition.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				pod, err := getImportPod(ctx, clientSet, job)
+				if err != nil || pod == nil {
+					return false, fmt.Errorf("import job %s failed: %s", job.Name, condition.Message)
+				}
+				return false, fmt.Errorf("import job %s failed: %w", job.Name, importPodError(pod))
+			}
+		}
+		return false, nil
+	})
+}
+
+func getImportPod(ctx context.Context, clientSet kubernetes.Interface, job *batchv1.Job) (*corev1.Pod, error) {
+	pods, err := clientSet.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", batchv1.JobNameLabel, job.Name),
+	})
+	if err != nil || len(pods.Items) == 0 {
+		return nil, err
+	}
+	return &pods.Items[0], nil
+}
+
+func isContainerRunning(statuses []corev1.ContainerStatus, name string) bool {
+	for _, status := range statuses {
+		if status.Name == name {
+			return status.State.Running != nil
+		}
+	}
+	return false
+}
+
+// importPodError returns the error of the failed container, its termination message has the end of its logs
+func importPodError(pod *corev1.Pod) error {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return fmt.Errorf("%s: %s", status.Name, strings.TrimSpace(terminated.Message))
+		}
+	}
+	return errors.New(pod.Status.Message)
+}
+
+func deleteImportJob(clientSet kubernetes.Interface, name string) {
+	err := clientSet.BatchV1().Jobs(util.HarvesterSystemNamespaceName).Delete(context.Background(), name,
+		metav1.DeleteOptions{PropagationPolicy: ptr.To(metav1.DeletePropagationBackground)})
+	if err != nil && !apierrors.IsNotFound(err) {
+		logrus.WithError(err).Errorf("failed to delete import job %s", name)
+	}
+}
+
+func ignoreAlreadyExists(err error) error {
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,199 @@
+package backingimage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/image/format"
+	"github.com/harvester/harvester/pkg/image/importer"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+var testImportJobImages = &importJobImages{importer: "harvester:v1", converter: "virt-handler:v1"}
+
+func TestNeedsDiskConversion(t *testing.T) {
+	tests := []struct {
+		name string
+		img  format.Image
+		want bool
+	}{
+		{name: "qcow2", img: format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatQCOW2, Size: 1 << 20}, want: false},
+		{name: "raw", img: format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatRaw, Size: 1 << 20}, want: false},
+		// the size of the decompressed disk is known once the job wrote it to the scratch volume
+		{name: "compressed qcow2", img: format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatQCOW2, Size: -1}, want: false},
+		{name: "vmdk", img: format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatVMDK, Size: 1 << 20}, want: true},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, needsDiskConversion(&tc.img), tc.name)
+	}
+}
+
+func TestNewImportJob(t *testing.T) {
+	tests := []struct {
+		name           string
+		sourceType     harvesterv1.VirtualMachineImageSourceType
+		img            format.Image
+		scratchSize    int64
+		initContainers []string
+		diskPath       string
+		wantErr        bool
+	}{
+		{
+			name:           "vmdk extracted from ova",
+			sourceType:     harvesterv1.VirtualMachineImageSourceTypeDownload,
+			img:            format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatVMDK, Size: 2 << 30, VirtualSize: 10 << 30},
+			scratchSize:    2<<30 + 10<<30 + importScratchOverhead,
+			initContainers: []string{fetchContainerName, convertContainerName},
+			diskPath:       importDiskPath,
+		},
+		{
+			name:           "compressed qcow2",
+			sourceType:     harvesterv1.VirtualMachineImageSourceTypeRegistry,
+			img:            format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatQCOW2, Size: -1, VirtualSize: 10 << 30},
+			scratchSize:    10<<30 + importScratchOverhead,
+			initContainers: []string{fetchContainerName},
+			diskPath:       importSourcePath,
+		},
+		{
+			name:           "uploaded vhdx",
+			sourceType:     harvesterv1.VirtualMachineImageSourceTypeUpload,
+			img:            format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatVHDX, Size: 1 << 30, VirtualSize: 10 << 30},
+			scratchSize:    1<<30 + 10<<30 + importScratchOverhead,
+			initContainers: []string{fetchContainerName, convertContainerName},
+			diskPath:       importDiskPath,
+		},
+		{
+			name:       "unknown size of the decompressed raw disk",
+			sourceType: harvesterv1.VirtualMachineImageSourceTypeDownload,
+			img:        format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatRaw, Size: -1},
+			wantErr:    true,
+		},
+		{
+			name:       "source larger than the limit",
+			sourceType: harvesterv1.VirtualMachineImageSourceTypeDownload,
+			img:        format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatVHD, Size: format.MaxVirtualSize + 1, VirtualSize: 10 << 30},
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported format",
+			sourceType: harvesterv1.VirtualMachineImageSourceTypeDownload,
+			img:        format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatOVA, Size: 2 << 30, VirtualSize: 10 << 30},
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			source := &importer.Source{Type: tc.sourceType, Name: "image"}
+			job, err := newImportJob("image-import-default-image", "default-image", testImportJobImages, source, &tc.img)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, format.ErrUnsupported)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, util.HarvesterSystemNamespaceName, job.Namespace)
+
+			podSpec := job.Spec.Template.Spec
+			assert.True(t, *podSpec.SecurityContext.RunAsNonRoot)
+			assert.False(t, *podSpec.AutomountServiceAccountToken)
+
+			var initContainers []string
+			for _, container := range podSpec.InitContainers {
+				initContainers = append(initContainers, container.Name)
+				assert.False(t, *container.SecurityContext.AllowPrivilegeEscalation)
+				assert.Equal(t, []corev1.Capability{"ALL"}, container.SecurityContext.Capabilities.Drop)
+			}
+			assert.Equal(t, tc.initContainers, initContainers)
+			assert.Equal(t, tc.sourceType == harvesterv1.VirtualMachineImageSourceTypeUpload, len(podSpec.InitContainers[0].Ports) == 1)
+
+			upload := podSpec.Containers[0]
+			assert.Equal(t, []string{importerCommand, "upload"}, upload.Command)
+			assert.Equal(t, []string{"--disk", tc.diskPath, "--data-source", "default-image", "--name", "image"}, upload.Args)
+
+			scratch := podSpec.Volumes[0].Ephemeral.VolumeClaimTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+			assert.Equal(t, 0, scratch.Cmp(*resource.NewQuantity(tc.scratchSize, resource.BinarySI)))
+			assert.Equal(t, job.Name, podSpec.Volumes[1].Secret.SecretName)
+		})
+	}
+}
+
+func TestStartImportJob(t *testing.T) {
+	source := &importer.Source{
+		Type:       harvesterv1.VirtualMachineImageSourceTypeDownload,
+		URL:        "https://example.com/disk.vmdk",
+		DiskFormat: harvesterv1.VirtualMachineImageFormatVMDK,
+	}
+	img := &format.Image{DiskFormat: harvesterv1.VirtualMachineImageFormatVMDK, Size: 1 << 30, VirtualSize: 10 << 30}
+	job, err := newImportJob("image-import-default-image", "default-image", testImportJobImages, source, img)
+	require.NoError(t, err)
+
+	// the job and the secret left behind by the previous import are replaced
+	leftover := job.DeepCopy()
+	leftover.Spec.Template.Spec.Containers[0].Image = "harvester:v0"
+	leftoverSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: job.Namespace, Name: job.Name}}
+	clientSet := fake.NewSimpleClientset(leftover, leftoverSecret)
+
+	created, err := startImportJob(context.TODO(), clientSet, job, source)
+	require.NoError(t, err)
+	assert.Equal(t, "harvester:v1", created.Spec.Template.Spec.Containers[0].Image)
+
+	secret, err := clientSet.CoreV1().Secrets(job.Namespace).Get(context.TODO(), job.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Job", secret.OwnerReferences[0].Kind)
+	stored := &importer.Source{}
+	require.NoError(t, json.Unmarshal(secret.Data[importSourceKey], stored))
+	assert.Equal(t, source, stored)
+
+	deleteImportJob(clientSet, job.Name)
+	_, err = clientSet.BatchV1().Jobs(job.Namespace).Get(context.TODO(), job.Name, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestWaitForImportJob(t *testing.T) {
+	newJob := func(conditionType batchv1.JobConditionType) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: util.HarvesterSystemNamespaceName, Name: "image-import-default-image"},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}},
+			},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: util.HarvesterSystemNamespaceName,
+			Name:      "image-import-default-image-abcde",
+			Labels:    map[string]string{batchv1.JobNameLabel: "image-import-default-image"},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: fetchContainerName,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  "the checksum of the image doesn't match\n",
+					}},
+				},
+			},
+		},
+	}
+
+	complete := newJob(batchv1.JobComplete)
+	assert.NoError(t, waitForImportJob(context.TODO(), fake.NewSimpleClientset(complete), complete))
+
+	// the error of the failed container is the one of the import
+	failed := newJob(batchv1.JobFailed)
+	err := waitForImportJob(context.TODO(), fake.NewSimpleClientset(failed, pod), failed)
+	assert.ErrorContains(t, err, "fetch: the checksum of the image doesn't match")
+}
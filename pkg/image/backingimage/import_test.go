@@ -0,0 +1,129 @@
+package backingimage
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	fakegenerated "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func TestNeedsImport(t *testing.T) {
+	tests := []struct {
+		name   string
+		status harvesterv1.VirtualMachineImageStatus
+		want   bool
+	}{
+		{name: "not detected", want: false},
+		{name: "qcow2", status: harvesterv1.VirtualMachineImageStatus{SourceFormat: harvesterv1.VirtualMachineImageFormatQCOW2}, want: false},
+		{name: "vmdk", status: harvesterv1.VirtualMachineImageStatus{SourceFormat: harvesterv1.VirtualMachineImageFormatVMDK}, want: true},
+		{name: "ova", status: harvesterv1.VirtualMachineImageStatus{SourceFormat: harvesterv1.VirtualMachineImageFormatOVA}, want: true},
+		{
+			name: "compressed qcow2",
+			status: harvesterv1.VirtualMachineImageStatus{
+				SourceFormat:      harvesterv1.VirtualMachineImageFormatQCOW2,
+				SourceCompression: harvesterv1.VirtualMachineImageCompressionXZ,
+			},
+			want: true,
+		},
+	}
+	for _, tc := range tests {
+		vmi := &harvesterv1.VirtualMachineImage{
+			Spec:   harvesterv1.VirtualMachineImageSpec{SourceType: harvesterv1.VirtualMachineImageSourceTypeDownload},
+			Status: tc.status,
+		}
+		assert.Equal(t, tc.want, needsImport(vmi), tc.name)
+	}
+}
+
+func TestCheckLostImport(t *testing.T) {
+	vmi := &harvesterv1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testImageName},
+		Spec: harvesterv1.VirtualMachineImageSpec{
+			SourceType: harvesterv1.VirtualMachineImageSourceTypeRegistry,
+			URL:        "quay.io/containerdisks/fedora:latest",
+		},
+	}
+	newBackingImage := func(state lhv1beta2.BackingImageState) *lhv1beta2.BackingImage {
+		return &lhv1beta2.BackingImage{
+			ObjectMeta: metav1.ObjectMeta{Namespace: util.LonghornSystemNamespaceName, Name: testNamespace + "-" + testImageName},
+			Status: lhv1beta2.BackingImageStatus{
+				DiskFileStatusMap: map[string]*lhv1beta2.BackingImageDiskFileStatus{
+					"disk": {State: state},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		backingImage  *lhv1beta2.BackingImage
+		importStarted bool
+		expectErr     error
+		expectDeleted bool
+	}{
+		{
+			name:          "import is running",
+			backingImage:  newBackingImage(lhv1beta2.BackingImageStateInProgress),
+			importStarted: true,
+		},
+		{
+			name:          "import is lost",
+			backingImage:  newBackingImage(lhv1beta2.BackingImageStateInProgress),
+			expectErr:     common.ErrRetryLater,
+			expectDeleted: true,
+		},
+		{
+			name:         "import is done before the restart",
+			backingImage: newBackingImage(lhv1beta2.BackingImageStateReady),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fakegenerated.NewSimpleClientset(tc.backingImage)
+			vmio, err := common.GetVMIOperator(
+				fakeclients.VirtualMachineImageClient(clientset.HarvesterhciV1beta1().VirtualMachineImages),
+				fakeclients.VirtualMachineImageCache(clientset.HarvesterhciV1beta1().VirtualMachineImages),
+				fakeclients.StorageClassCache(clientset.StorageV1().StorageClasses),
+				http.Client{},
+			)
+			require.NoError(t, err)
+			_, err = clientset.StorageV1().StorageClasses().Create(context.TODO(), &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{Name: vmio.GetStorageClassName(vmi)},
+			}, metav1.CreateOptions{})
+			require.NoError(t, err)
+
+			bib := &Backend{
+				biClient: fakeclients.BackingImageClient(clientset.LonghornV1beta2().BackingImages),
+				biCache:  fakeclients.BackingImageCache(clientset.LonghornV1beta2().BackingImages),
+				scCache:  fakeclients.StorageClassCache(clientset.StorageV1().StorageClasses),
+				vmio:     vmio,
+			}
+			if tc.importStarted {
+				bib.imports.Store(ref.Construct(vmi.Namespace, vmi.Name), context.CancelFunc(func() {}))
+			}
+
+			err = bib.Check(vmi)
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			_, err = clientset.LonghornV1beta2().BackingImages(util.LonghornSystemNamespaceName).Get(context.TODO(), tc.backingImage.Name, metav1.GetOptions{})
+			assert.Equal(t, tc.expectDeleted, apierrors.IsNotFound(err))
+		})
+	}
+}
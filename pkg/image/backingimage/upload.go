@@ -1,48 +1,53 @@
 package backingimage
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
+	"strconv"
 	"time"
 
 	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 	"github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctllhv1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta2"
 	"github.com/harvester/harvester/pkg/image/backend"
 	"github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/image/format"
+	"github.com/harvester/harvester/pkg/image/importer"
 	"github.com/harvester/harvester/pkg/util"
 )
 
 type Uploader struct {
 	biCache    ctllhv1.BackingImageCache
 	bidsClient ctllhv1.BackingImageDataSourceClient
-	httpClient http.Client
-	vmio       common.VMIOperator
+	// clientSet runs the jobs converting the uploaded images
+	clientSet kubernetes.Interface
+	vmio      common.VMIOperator
 }
 
 func GetUploader(biCache ctllhv1.BackingImageCache,
 	bidsClient ctllhv1.BackingImageDataSourceClient,
-	httpClient http.Client,
+	clientSet kubernetes.Interface,
 	vmio common.VMIOperator) backend.Uploader {
 	return &Uploader{
 		biCache:    biCache,
 		bidsClient: bidsClient,
-		httpClient: httpClient,
+		clientSet:  clientSet,
 		vmio:       vmio,
 	}
 }
 
-func (biu *Uploader) waitForBackingImageDataSourceReady(name string) error {
+func waitForBackingImageDataSourceReady(bidsClient ctllhv1.BackingImageDataSourceClient, name string) error {
 	retry := 30
 	for i := 0; i < retry; i++ {
-		ds, err := biu.bidsClient.Get(util.LonghornSystemNamespaceName, name, metav1.GetOptions{})
+		ds, err := bidsClient.Get(util.LonghornSystemNamespaceName, name, metav1.GetOptions{})
 		if err != nil && !apierrors.IsNotFound(err) {
 			return fmt.Errorf("failed waiting for backing image data source to be ready: %w", err)
 		}
@@ -75,39 +80,51 @@ func (biu *Uploader) DoUpload(vmi *harvesterv1.VirtualMachineImage, req *http.Re
 		return fmt.Errorf("failed to get backing image name for VMImage %s/%s, error: %w", vmi.Namespace, vmi.Name, err)
 	}
 
-	if err := biu.waitForBackingImageDataSourceReady(dsName); err != nil {
+	if err := waitForBackingImageDataSourceReady(biu.bidsClient, dsName); err != nil {
 		return err
 	}
 
-	uploadURL := fmt.Sprintf("%s/backingimages/%s", util.LonghornDefaultManagerURL, dsName)
-	uploadReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, uploadURL, req.Body)
+	// the image goes through the import job like the downloaded images, the header read to detect the format and
+	// the size of the disk is sent to the job with the rest of the image
+	uploaded, err := common.OpenUploadedImage(req)
 	if err != nil {
-		return fmt.Errorf("failed to create the upload request: %w", err)
+		return err
 	}
-	uploadReq.Header = req.Header
-	uploadReq.URL.RawQuery = req.URL.RawQuery
-
-	var urlErr *url.Error
-	uploadResp, err := biu.httpClient.Do(uploadReq)
-	if errors.As(err, &urlErr) {
-		// Trim the "POST http://xxx" implementation detail for the error
-		// set the err var and it will be recorded in image condition in the defer function
-		err = errors.Unwrap(urlErr)
+	size, parseErr := strconv.ParseInt(req.URL.Query().Get("size"), 10, 64)
+	if parseErr != nil || size <= 0 {
+		size = -1
+	}
+	header := make([]byte, format.ProbeSize)
+	n, err := io.ReadFull(uploaded, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		err = fmt.Errorf("failed to read the uploaded image: %w", err)
 		return err
-	} else if err != nil {
-		return fmt.Errorf("failed to send the upload request: %w", err)
 	}
-	defer uploadResp.Body.Close()
+	header = header[:n]
 
-	body, err := io.ReadAll(uploadResp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+	// the size of the request is the upper bound of the image sizing the scratch volume of the job if the size
+	// isn't given, the size of the disk uploaded to Longhorn is known once the job wrote it
+	sizeBound := size
+	if sizeBound < 0 {
+		sizeBound = req.ContentLength
 	}
-	if uploadResp.StatusCode >= http.StatusBadRequest {
+	img, err := importer.Probe(bytes.NewReader(header), sizeBound)
+	if err != nil {
 		// err will be recorded in image condition in the defer function
-		err = fmt.Errorf("upload failed: %s", string(body))
+		err = fmt.Errorf("failed to detect the format of the uploaded image: %w", err)
+		return err
+	}
+	if err = common.RecordSourceFormat(biu.vmio, vmi, img); err != nil {
 		return err
 	}
 
-	return nil
+	source := &importer.Source{
+		Type:        harvesterv1.VirtualMachineImageSourceTypeUpload,
+		Name:        biu.vmio.GetDisplayName(vmi),
+		Checksum:    biu.vmio.GetChecksum(vmi),
+		DiskFormat:  img.DiskFormat,
+		Compression: img.Compression,
+	}
+	err = runImportJob(req.Context(), biu.clientSet, vmi, dsName, source, img, io.MultiReader(bytes.NewReader(header), uploaded), size)
+	return err
 }
@@ -28,6 +28,14 @@ func (biv *Validator) Create(request *types.Request, vmi *harvesterv1.VirtualMac
 		return err
 	}
 
+	if err := biv.vmiv.CheckSignature(vmi); err != nil {
+		return err
+	}
+
+	if err := biv.vmiv.CheckSecretAccess(request, vmi); err != nil {
+		return err
+	}
+
 	if err := biv.vmiv.CheckSecurityParameters(vmi); err != nil {
 		return err
 	}
@@ -62,6 +70,14 @@ func (biv *Validator) Update(oldVMI, newVMI *harvesterv1.VirtualMachineImage) er
 		return err
 	}
 
+	if err := biv.vmiv.SignatureConsistency(oldVMI, newVMI); err != nil {
+		return err
+	}
+
+	if err := biv.vmiv.SecretConsistency(oldVMI, newVMI); err != nil {
+		return err
+	}
+
 	if err := biv.vmiv.SecurityParameterConsistency(oldVMI, newVMI); err != nil {
 		return err
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 
 	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
 	ctlstoragev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/storage/v1"
@@ -15,6 +16,7 @@ import (
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctlcdiv1 "github.com/harvester/harvester/pkg/generated/controllers/cdi.kubevirt.io/v1beta1"
+	ctlcdiuploadv1 "github.com/harvester/harvester/pkg/generated/controllers/upload.cdi.kubevirt.io/v1beta1"
 	"github.com/harvester/harvester/pkg/image/backend"
 	"github.com/harvester/harvester/pkg/image/common"
 )
@@ -24,15 +26,27 @@ type Backend struct {
 	dataVolumeClient ctlcdiv1.DataVolumeClient
 	scClient         ctlstoragev1.StorageClassClient
 	pvcCache         ctlcorev1.PersistentVolumeClaimCache
+	secretClient     ctlcorev1.SecretClient
+	secretCache      ctlcorev1.SecretCache
+	configMapClient  ctlcorev1.ConfigMapClient
+	cdiUploadClient  ctlcdiuploadv1.UploadTokenRequestClient
 	vmio             common.VMIOperator
+	// imports are the cancel functions of the running imports uploaded by harvester, keyed by the VM image
+	imports sync.Map
 }
 
-func GetBackend(ctx context.Context, dataVolumeClient ctlcdiv1.DataVolumeClient, scClient ctlstoragev1.StorageClassClient, pvcCache ctlcorev1.PersistentVolumeClaimCache, vmio common.VMIOperator) backend.Backend {
+func GetBackend(ctx context.Context, dataVolumeClient ctlcdiv1.DataVolumeClient, scClient ctlstoragev1.StorageClassClient, pvcCache ctlcorev1.PersistentVolumeClaimCache,
+	secretClient ctlcorev1.SecretClient, secretCache ctlcorev1.SecretCache, configMapClient ctlcorev1.ConfigMapClient,
+	cdiUploadClient ctlcdiuploadv1.UploadTokenRequestClient, vmio common.VMIOperator) backend.Backend {
 	return &Backend{
 		ctx:              ctx,
 		dataVolumeClient: dataVolumeClient,
 		scClient:         scClient,
 		pvcCache:         pvcCache,
+		secretClient:     secretClient,
+		secretCache:      secretCache,
+		configMapClient:  configMapClient,
+		cdiUploadClient:  cdiUploadClient,
 		vmio:             vmio,
 	}
 }
@@ -55,6 +69,8 @@ func (b *Backend) Initialize(vmImg *harvesterv1.VirtualMachineImage) (*harvester
 		return vmImg, nil
 	case harvesterv1.VirtualMachineImageSourceTypeExportVolume:
 		return b.initializeExportFromVolume(vmImg)
+	case harvesterv1.VirtualMachineImageSourceTypeRegistry:
+		return b.initializeRegistry(vmImg)
 	default:
 		return vmImg, fmt.Errorf("unsupported source type: %s", vmImg.Spec.SourceType)
 	}
@@ -67,10 +83,14 @@ func (b *Backend) Check(vmImg *harvesterv1.VirtualMachineImage) error {
 	if err != nil {
 		return b.handleDataVolumeError(err, targetDVNs, targetDVName)
 	}
+	if needsImport(vmImg) && targetDV.Status.Phase == cdiv1.UploadReady {
+		b.resumeImport(vmImg)
+	}
 
 	// upload source type will update the progress on the upload handler
 	if vmImg.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeDownload ||
-		vmImg.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeExportVolume {
+		vmImg.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeExportVolume ||
+		vmImg.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeRegistry {
 		progress := string(targetDV.Status.Progress)
 		if progress != "N/A" && progress != "" {
 			// progress format looks like "88.82%", we just need the integer part
@@ -113,6 +133,7 @@ func (b *Backend) UpdateVirtualSize(vmi *harvesterv1.VirtualMachineImage) (*harv
 }
 
 func (b *Backend) Delete(vmImg *harvesterv1.VirtualMachineImage) error {
+	b.stopImport(vmImg)
 	targetDVNs := b.vmio.GetNamespace(vmImg)
 	targetDVName := b.vmio.GetName(vmImg)
 	_, err := b.dataVolumeClient.Get(targetDVNs, targetDVName, metav1.GetOptions{})
@@ -148,17 +169,33 @@ func (b *Backend) isDataVolumeCreated(vmImg *harvesterv1.VirtualMachineImage) (b
 }
 
 func (b *Backend) initializeDownload(vmImg *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
-	virtualSize, err := fetchImageVirtualSize(b.vmio.GetURL(vmImg))
+	credentials, err := common.GetDownloadCredentials(b.secretCache, vmImg)
+	if err != nil {
+		return vmImg, err
+	}
+	client, err := credentials.NewHTTPClient(0)
 	if err != nil {
-		return vmImg, fmt.Errorf("failed to fetch image virtual size: %v", err)
+		return vmImg, err
 	}
 
-	size, err := fetchImageSize(b.vmio.GetURL(vmImg))
+	size, err := fetchImageSize(client, b.vmio.GetURL(vmImg))
 	if err != nil {
 		return vmImg, fmt.Errorf("failed to fetch image size: %v", err)
 	}
 
-	// means the image is not qcow format
+	img, err := common.ProbeDownload(b.ctx, b.secretCache, vmImg)
+	if err != nil {
+		return vmImg, err
+	}
+	if vmImg, err = b.vmio.UpdateSourceFormat(vmImg, img.Format, img.Compression); err != nil {
+		return vmImg, fmt.Errorf("failed to update VM Image source format: %v", err)
+	}
+
+	// the virtual size of raw images is the size of the disk
+	virtualSize := img.VirtualSize
+	if virtualSize == 0 && img.Size > 0 {
+		virtualSize = img.Size
+	}
 	if virtualSize == 0 {
 		virtualSize = size
 	}
@@ -173,12 +210,14 @@ func (b *Backend) initializeDownload(vmImg *harvesterv1.VirtualMachineImage) (*h
 		vmImg = updatedVMImg
 	}
 
-	dvName := b.vmio.GetName(vmImg)
-	dvNamespace := b.vmio.GetNamespace(vmImg)
-
-	targetSC, err := b.scClient.Get(vmImg.Spec.TargetStorageClassName, metav1.GetOptions{})
-	if err != nil {
-		return vmImg, fmt.Errorf("failed to get StorageClass %s: %v", vmImg.Spec.TargetStorageClassName, err)
+	if needsImport(vmImg) {
+		// CDI can't import the image, the disk is uploaded by importImage
+		dvSource := &cdiv1.DataVolumeSource{Upload: &cdiv1.DataVolumeSourceUpload{}}
+		if vmImg, err = b.createDataVolume(vmImg, dvSource); err != nil {
+			return vmImg, err
+		}
+		b.startImport(vmImg)
+		return vmImg, nil
 	}
 
 	// generate DV source
@@ -186,6 +225,20 @@ func (b *Backend) initializeDownload(vmImg *harvesterv1.VirtualMachineImage) (*h
 	if err != nil {
 		return vmImg, fmt.Errorf("failed to generate DV source: %v", err)
 	}
+	if err := b.setDVSourceHTTPCredentials(vmImg, dvSource.HTTP, credentials); err != nil {
+		return vmImg, fmt.Errorf("failed to generate DV source: %v", err)
+	}
+	return b.createDataVolume(vmImg, dvSource)
+}
+
+func (b *Backend) createDataVolume(vmImg *harvesterv1.VirtualMachineImage, dvSource *cdiv1.DataVolumeSource) (*harvesterv1.VirtualMachineImage, error) {
+	dvName := b.vmio.GetName(vmImg)
+	dvNamespace := b.vmio.GetNamespace(vmImg)
+
+	targetSC, err := b.scClient.Get(vmImg.Spec.TargetStorageClassName, metav1.GetOptions{})
+	if err != nil {
+		return vmImg, fmt.Errorf("failed to get StorageClass %s: %v", vmImg.Spec.TargetStorageClassName, err)
+	}
 
 	// generate DV target storage
 	dvTargetStorage, err := generateDVTargetStorage(vmImg)
@@ -239,49 +292,10 @@ func (b *Backend) initializeExportFromVolume(vmImg *harvesterv1.VirtualMachineIm
 		vmImg = updatedVMImg
 	}
 
-	dvName := b.vmio.GetName(vmImg)
-	dvNamespace := b.vmio.GetNamespace(vmImg)
-
-	targetSC, err := b.scClient.Get(vmImg.Spec.TargetStorageClassName, metav1.GetOptions{})
-	if err != nil {
-		return vmImg, fmt.Errorf("failed to get StorageClass %s: %v", vmImg.Spec.TargetStorageClassName, err)
-	}
-
 	// generate DV source
 	dvSource, err := generateDVSource(vmImg, b.vmio.GetSourceType(vmImg))
 	if err != nil {
 		return vmImg, fmt.Errorf("failed to generate DV source: %v", err)
 	}
-
-	// generate DV target storage
-	dvTargetStorage, err := generateDVTargetStorage(vmImg)
-	if err != nil {
-		return vmImg, fmt.Errorf("failed to generate DV target storage: %v", err)
-	}
-	var boolTrue = true
-	dataVolumeTemplate := &cdiv1.DataVolume{
-		ObjectMeta: metav1.ObjectMeta{
-			Annotations: GenerateDVAnnotations(targetSC),
-			Name:        dvName,
-			Namespace:   dvNamespace,
-			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion:         common.HarvesterAPIV1Beta1,
-					Kind:               common.VMImageKind,
-					Name:               b.vmio.GetName(vmImg),
-					UID:                b.vmio.GetUID(vmImg),
-					BlockOwnerDeletion: &boolTrue,
-				},
-			},
-		},
-		Spec: cdiv1.DataVolumeSpec{
-			Source:  dvSource,
-			Storage: dvTargetStorage,
-		},
-	}
-	if _, err := b.dataVolumeClient.Create(dataVolumeTemplate); err != nil {
-		return vmImg, fmt.Errorf("failed to create DataVolume %s/%s: %v", dvNamespace, dvName, err)
-	}
-	logrus.Infof("DataVolume %s/%s created", dvNamespace, dvName)
-	return vmImg, nil
+	return b.createDataVolume(vmImg, dvSource)
 }
@@ -1,10 +1,8 @@
 package cdi
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strconv"
 	"sync"
@@ -128,13 +126,13 @@ func generateDVTargetStorage(vmi *harvesterv1.VirtualMachineImage) (*cdiv1.Stora
 	return targetDVStorage, nil
 }
 
-func fetchImageSize(url string) (int64, error) {
+func fetchImageSize(client *http.Client, url string) (int64, error) {
 	req, err := http.NewRequest(http.MethodHead, url, nil)
 	if err != nil {
 		return 0, err
 	}
 
-	rsp, err := http.DefaultClient.Do(req)
+	rsp, err := client.Do(req)
 	if err != nil {
 		return 0, err
 	}
@@ -162,44 +160,3 @@ func getContentLength(rsp *http.Response) (string, error) {
 	// if both headers are missing, return an error
 	return "", ErrHeaderContentLengthNotFound
 }
-
-func fetchImageVirtualSize(url string) (int64, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return 0, err
-	}
-	req.Header.Set("Range", "bytes=0-127")
-
-	rsp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return 0, err
-	}
-	defer rsp.Body.Close()
-
-	// Use LimitReader to prevent OOM, some http servers may ignore the Range header and return full content
-	// which may lead to OOM if the image is too large
-	limitedReader := io.LimitReader(rsp.Body, 128)
-	rawContent, err := io.ReadAll(limitedReader)
-	if err != nil {
-		return 0, err
-	}
-
-	if len(rawContent) < 128 {
-		return 0, fmt.Errorf("content length is less than 128 bytes")
-	}
-
-	// REF: https://gitlab.com/qemu-project/qemu/-/blob/master/docs/interop/qcow2.txt
-	// 0-3 bytes are the magic number, should be "QFI\xfb" for qcow format
-	magicNumber := rawContent[0:4]
-	if string(magicNumber) != "QFI\xfb" {
-		logrus.Infof("Magic number is not correct: %v, this image is not qcow format", magicNumber)
-		return 0, nil
-	}
-
-	// 24-31 bytes are the virtual size
-	virtualSizeRaw := rawContent[24:32]
-	virtualSize := binary.BigEndian.Uint64(virtualSizeRaw)
-
-	// ensure the virtual size is not too large, skip gosec G115
-	return int64(virtualSize), nil //nolint:gosec
-}
@@ -0,0 +1,173 @@
+package cdi
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	uploadcdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/image/format"
+	"github.com/harvester/harvester/pkg/ref"
+)
+
+// needsImport returns whether the downloaded image is uploaded to the DataVolume by harvester, since CDI doesn't
+// support zstd compression and OVA archives. CDI converts the other formats itself.
+func needsImport(vmImg *harvesterv1.VirtualMachineImage) bool {
+	return vmImg.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeDownload &&
+		(vmImg.Status.SourceCompression == harvesterv1.VirtualMachineImageCompressionZstd ||
+			vmImg.Status.SourceFormat == harvesterv1.VirtualMachineImageFormatOVA)
+}
+
+// startImport starts the import of the VM image, the running one is stopped first
+func (b *Backend) startImport(vmImg *harvesterv1.VirtualMachineImage) {
+	ctx, cancel := context.WithCancel(b.ctx)
+	key := ref.Construct(vmImg.Namespace, vmImg.Name)
+	if previous, loaded := b.imports.Swap(key, cancel); loaded {
+		previous.(context.CancelFunc)()
+	}
+	go b.runImport(ctx, key, cancel, vmImg)
+}
+
+// resumeImport starts the import of the VM image if it's not running, e.g. the import is lost when harvester
+// restarts before the upload is completed.
+func (b *Backend) resumeImport(vmImg *harvesterv1.VirtualMachineImage) {
+	ctx, cancel := context.WithCancel(b.ctx)
+	key := ref.Construct(vmImg.Namespace, vmImg.Name)
+	if _, loaded := b.imports.LoadOrStore(key, cancel); loaded {
+		cancel()
+		return
+	}
+	go b.runImport(ctx, key, cancel, vmImg)
+}
+
+func (b *Backend) stopImport(vmImg *harvesterv1.VirtualMachineImage) {
+	if cancel, loaded := b.imports.LoadAndDelete(ref.Construct(vmImg.Namespace, vmImg.Name)); loaded {
+		cancel.(context.CancelFunc)()
+	}
+}
+
+func (b *Backend) runImport(ctx context.Context, key string, cancel context.CancelFunc, vmImg *harvesterv1.VirtualMachineImage) {
+	defer b.imports.CompareAndDelete(key, cancel)
+	b.importImage(ctx, vmImg)
+}
+
+// importImage uploads the downloaded image to the DataVolume, the DataVolume is removed if the import fails for
+// the VM image controller to initialize it again.
+func (b *Backend) importImage(ctx context.Context, vmImg *harvesterv1.VirtualMachineImage) {
+	err := b.upload(ctx, vmImg)
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+
+	logrus.WithError(err).WithFields(logrus.Fields{
+		"namespace": vmImg.Namespace,
+		"name":      vmImg.Name,
+	}).Error("failed to import vm image")
+
+	current, getErr := b.vmio.GetVMImageObj(vmImg.Namespace, vmImg.Name)
+	if getErr != nil || current.DeletionTimestamp != nil {
+		return
+	}
+	if _, err := b.vmio.FailImported(current, err, current.Status.Progress); err != nil {
+		logrus.WithError(err).Errorf("failed to update vm image %s/%s", vmImg.Namespace, vmImg.Name)
+	}
+	if err := b.dataVolumeClient.Delete(vmImg.Namespace, vmImg.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		logrus.WithError(err).Errorf("failed to delete DataVolume %s/%s", vmImg.Namespace, vmImg.Name)
+	}
+}
+
+// upload streams the disk extracted from the downloaded image to the CDI upload proxy, CDI converts the disk
+// to raw like the uploaded images.
+func (b *Backend) upload(ctx context.Context, vmImg *harvesterv1.VirtualMachineImage) error {
+	dvNamespace := b.vmio.GetNamespace(vmImg)
+	dvName := b.vmio.GetName(vmImg)
+	if err := wait.PollUntilContextTimeout(ctx, tickPolling, tickTimeout, true, func(context.Context) (bool, error) {
+		dv, err := b.dataVolumeClient.Get(dvNamespace, dvName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return dv.Status.Phase == cdiv1.UploadReady, nil
+	}); err != nil {
+		return fmt.Errorf("failed to wait for DataVolume %s/%s to be ready for upload: %w", dvNamespace, dvName, err)
+	}
+
+	tokenRequest, err := b.cdiUploadClient.Create(&uploadcdiv1.UploadTokenRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "upload-token-",
+			Namespace:    dvNamespace,
+		},
+		Spec: uploadcdiv1.UploadTokenRequestSpec{
+			PvcName: dvName,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create UploadTokenRequest %s/%s: %w", dvNamespace, dvName, err)
+	}
+
+	credentials, err := common.GetDownloadCredentials(b.secretCache, vmImg)
+	if err != nil {
+		return err
+	}
+	client, err := credentials.NewHTTPClient(0)
+	if err != nil {
+		return err
+	}
+	imageURL := b.vmio.GetURL(vmImg)
+	downloadReq, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return err
+	}
+	downloadResp, err := client.Do(downloadReq)
+	if err != nil {
+		return fmt.Errorf("failed to download image %s: %w", imageURL, err)
+	}
+	defer downloadResp.Body.Close()
+	if downloadResp.StatusCode < http.StatusOK || downloadResp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("got %d status code from %s", downloadResp.StatusCode, imageURL)
+	}
+
+	size := downloadResp.ContentLength
+	if size <= 0 {
+		size = -1
+	}
+	img, err := format.Open(downloadResp.Body, size)
+	if err != nil {
+		return fmt.Errorf("failed to detect the format of image %s: %w", imageURL, err)
+	}
+	defer img.Close()
+
+	uploadReq, err := http.NewRequestWithContext(ctx, http.MethodPost, getUploadFormURL(false), io.NopCloser(img))
+	if err != nil {
+		return fmt.Errorf("failed to create the upload request: %w", err)
+	}
+	uploadReq.Header.Set("Content-Type", "application/octet-stream")
+	uploadReq.Header.Set("Authorization", "Bearer "+tokenRequest.Status.Token)
+
+	// the upload proxy is reached with the cluster internal service name like the uploads
+	uploadClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	uploadResp, err := uploadClient.Do(uploadReq)
+	if err != nil {
+		return fmt.Errorf("failed to send the upload request: %w", err)
+	}
+	defer uploadResp.Body.Close()
+	if uploadResp.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(uploadResp.Body)
+		return fmt.Errorf("upload failed: %s", string(body))
+	}
+	logrus.Infof("Uploaded image %s to DataVolume %s/%s", imageURL, dvNamespace, dvName)
+	return nil
+}
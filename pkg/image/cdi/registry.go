@@ -0,0 +1,113 @@
+package cdi
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/util/registry"
+)
+
+// initializeRegistry imports the container disk image with the registry source of the DataVolume,
+// the disk is inspected first since the DataVolume needs the virtual size.
+func (b *Backend) initializeRegistry(vmImg *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
+	credentials, err := common.GetRegistryCredentials(b.secretCache, vmImg)
+	if err != nil {
+		return vmImg, err
+	}
+
+	if vmImg.Status.Size == 0 && vmImg.Status.VirtualSize == 0 {
+		ref, err := registry.ParseReference(b.vmio.GetURL(vmImg))
+		if err != nil {
+			return vmImg, err
+		}
+		client, err := registry.NewClient(credentials)
+		if err != nil {
+			return vmImg, err
+		}
+		size, virtualSize, err := client.Inspect(b.ctx, ref)
+		if err != nil {
+			return vmImg, fmt.Errorf("failed to inspect image %s: %w", ref, err)
+		}
+
+		logrus.Infof("Update VM Image size (%v) and virtual size (%v) before we create the DataVolume", size, virtualSize)
+		updatedVMImg, err := b.vmio.UpdateVirtualSizeAndSize(vmImg, virtualSize, size)
+		if err != nil {
+			return vmImg, fmt.Errorf("failed to update VM Image size and virtual size: %v", err)
+		}
+		// use latest updated VM Image
+		vmImg = updatedVMImg
+	}
+
+	dvSource, err := b.generateDVSourceRegistry(vmImg, credentials)
+	if err != nil {
+		return vmImg, fmt.Errorf("failed to generate DV source: %v", err)
+	}
+	return b.createDataVolume(vmImg, dvSource)
+}
+
+// generateDVSourceRegistry creates the secret and the CA config map the CDI importer expects from the credentials,
+// they're owned by the VM image. CDI only supports insecure registries in its config, so the insecure option is ignored.
+func (b *Backend) generateDVSourceRegistry(vmImg *harvesterv1.VirtualMachineImage, credentials *registry.Credentials) (*cdiv1.DataVolumeSource, error) {
+	source := &cdiv1.DataVolumeSourceRegistry{
+		URL: ptr.To(registry.SourceURL(b.vmio.GetURL(vmImg))),
+	}
+	objectMeta := b.newOwnedObjectMeta(vmImg, getRegistryObjectName(vmImg))
+
+	if credentials.Username != "" {
+		secret := &corev1.Secret{
+			ObjectMeta: objectMeta,
+			Data: map[string][]byte{
+				registry.SecretUsernameKey: []byte(credentials.Username),
+				registry.SecretPasswordKey: []byte(credentials.Password),
+			},
+		}
+		if _, err := b.secretClient.Create(secret); err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to create registry secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		source.SecretRef = ptr.To(secret.Name)
+	}
+
+	if len(credentials.CACert) > 0 {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: objectMeta,
+			Data: map[string]string{
+				registry.SecretCAKey: string(credentials.CACert),
+			},
+		}
+		if _, err := b.configMapClient.Create(configMap); err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to create registry CA config map %s/%s: %w", configMap.Namespace, configMap.Name, err)
+		}
+		source.CertConfigMap = ptr.To(configMap.Name)
+	}
+
+	return &cdiv1.DataVolumeSource{Registry: source}, nil
+}
+
+// newOwnedObjectMeta returns the object meta of the objects the CDI importer needs, they're removed with the VM image
+func (b *Backend) newOwnedObjectMeta(vmImg *harvesterv1.VirtualMachineImage, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: b.vmio.GetNamespace(vmImg),
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion:         common.HarvesterAPIV1Beta1,
+				Kind:               common.VMImageKind,
+				Name:               b.vmio.GetName(vmImg),
+				UID:                b.vmio.GetUID(vmImg),
+				BlockOwnerDeletion: ptr.To(true),
+			},
+		},
+	}
+}
+
+func getRegistryObjectName(vmImg *harvesterv1.VirtualMachineImage) string {
+	return vmImg.Name + "-registry"
+}
@@ -1,17 +1,14 @@
 package cdi
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +26,7 @@ import (
 	ctlcdiuploadv1 "github.com/harvester/harvester/pkg/generated/controllers/upload.cdi.kubevirt.io/v1beta1"
 	"github.com/harvester/harvester/pkg/image/backend"
 	"github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/image/format"
 )
 
 const (
@@ -85,40 +83,41 @@ func (cu *Uploader) DoUpload(vmImg *harvesterv1.VirtualMachineImage, req *http.R
 	if err != nil {
 		return fmt.Errorf("failed to parse file size: %v", err)
 	}
-	virtualSize := int64(fileSize)
 
-	// check multipart
-	contentType := req.Header.Get("Content-Type")
-	isMultipartFormat := false
-	if strings.HasPrefix(contentType, "multipart/form-data") {
-		isMultipartFormat = true
+	progress := &ProgressUpdater{
+		targetBytes:       fileSize,
+		lastTime:          time.Now(),
+		imageNS:           vmImg.Namespace,
+		imageName:         vmImg.Name,
+		vmImgUpdateLocker: updaterLocker,
+		vmImgCond:         updaterCond,
 	}
 
-	// no matter multipart or not, the first 4k would be enough
-	// to find the magic number and virtual size
-	tmpBuff := make([]byte, 1024)
-	readLen, err := req.Body.Read(tmpBuff)
-	if err != nil && err != io.EOF {
-		return fmt.Errorf("failed to read the first 1k request body (for checking format): %v", err)
+	// the progress is the uploaded bytes of the image, which is decompressed and extracted from the OVA archive
+	// before being sent to CDI, since CDI doesn't support zstd compression and OVA archives
+	uploaded, err := common.OpenUploadedImage(req)
+	if err != nil {
+		return err
 	}
-	logrus.Debugf("Read %d bytes from the request body", readLen)
-
-	rawContent := tmpBuff[:readLen]
-	// try to find the magic number of first 4096 bytes
-	// the multipart body will contain the boundary string and the headers.
-	// We should still find the magic number in the first 4096 bytes
-	qcowMagic := []byte("QFI\xfb")
-	qcowHeaderIndex := bytes.Index(rawContent, qcowMagic)
-	logrus.Debugf("first 1024 bytes: %v", string(rawContent))
-	if qcowHeaderIndex == -1 {
-		logrus.Infof("Magic number is not correct: %v, this image is not qcow format", rawContent)
-	} else {
-		// The virtual size is at 24-31 bytes (from the qcow image header)
-		virtualSizeRaw := rawContent[qcowHeaderIndex+24 : qcowHeaderIndex+32]
-		// ensure the virtual size is not too large, skip gosec G115
-		virtualSize = int64(binary.BigEndian.Uint64(virtualSizeRaw)) //nolint:gosec
+	img, err := format.Open(io.TeeReader(uploaded, progress), fileSize)
+	if err != nil {
+		err = fmt.Errorf("failed to detect the format of the uploaded image: %w", err)
+		return err
+	}
+	defer img.Close()
+	logrus.Debugf("Uploaded image format: %s, compression: %s, virtual size: %d", img.Format, img.Compression, img.VirtualSize)
+
+	virtualSize := img.VirtualSize
+	if virtualSize == 0 {
+		if img.Size < 0 {
+			err = fmt.Errorf("the virtual size of the %s compressed %s image is unknown", img.Compression, img.DiskFormat)
+			return err
+		}
+		virtualSize = img.Size
+	}
+	if err = common.RecordSourceFormat(cu.vmio, vmImg, img); err != nil {
+		return err
 	}
-	logrus.Debugf("Qcow header index: %v, virtual size: %v", qcowHeaderIndex, virtualSize)
 
 	if err = cu.updateVirtualSizeAndSize(vmImg, virtualSize, fileSize); err != nil {
 		return err
@@ -206,25 +205,13 @@ func (cu *Uploader) DoUpload(vmImg *harvesterv1.VirtualMachineImage, req *http.R
 		return fmt.Errorf("failed to create UploadTokenRequest %s/%s: %v", dvNamespace, dvName, err)
 	}
 
-	newBody := io.MultiReader(bytes.NewReader(rawContent), req.Body)
-
-	progress := &ProgressUpdater{
-		targetBytes:       fileSize,
-		lastTime:          time.Now(),
-		imageNS:           vmImg.Namespace,
-		imageName:         vmImg.Name,
-		vmImgUpdateLocker: updaterLocker,
-		vmImgCond:         updaterCond,
-	}
-	hookedReader := io.TeeReader(newBody, progress)
-
 	token := retUploadTokenRequest.Status.Token
-	uploadReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, getUploadFormURL(isMultipartFormat), io.NopCloser(hookedReader))
+	uploadReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, getUploadFormURL(false), io.NopCloser(img))
 	if err != nil {
 		return fmt.Errorf("failed to wrap the upload request: %w", err)
 	}
-	uploadReq.Header = req.Header
-	uploadReq.Header.Add("Authorization", "Bearer "+token)
+	uploadReq.Header.Set("Content-Type", "application/octet-stream")
+	uploadReq.Header.Set("Authorization", "Bearer "+token)
 
 	// create VMI progress updater
 	go cu.updateVMImageProgress(vmImg, updaterCond, progress, fileSize, &uploadErr)
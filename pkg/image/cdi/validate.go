@@ -28,6 +28,14 @@ func (cv *Validator) Create(req *types.Request, vmImg *harvesterv1.VirtualMachin
 		return err
 	}
 
+	if err := cv.vmiv.CheckSignature(vmImg); err != nil {
+		return err
+	}
+
+	if err := cv.vmiv.CheckSecretAccess(req, vmImg); err != nil {
+		return err
+	}
+
 	if err := cv.vmiv.CheckPVCInUse(vmImg); err != nil {
 		return err
 	}
@@ -57,6 +65,14 @@ func (cv *Validator) Update(oldVMImg, newVMImg *harvesterv1.VirtualMachineImage)
 		return err
 	}
 
+	if err := cv.vmiv.SignatureConsistency(oldVMImg, newVMImg); err != nil {
+		return err
+	}
+
+	if err := cv.vmiv.SecretConsistency(oldVMImg, newVMImg); err != nil {
+		return err
+	}
+
 	if err := cv.vmiv.CheckUpdateDisplayName(oldVMImg, newVMImg); err != nil {
 		return err
 	}
@@ -0,0 +1,142 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+const (
+	// The keys of the download secret, the username and password keys are the ones of the CDI endpoint secrets
+	DownloadSecretUsernameKey = "accessKeyId"
+	DownloadSecretPasswordKey = "secretKey"
+	DownloadSecretTokenKey    = "token"
+	DownloadSecretHeadersKey  = "headers"
+	DownloadSecretCAKey       = "ca.crt"
+)
+
+// DownloadCredentials authenticate the requests downloading the image and its signature
+type DownloadCredentials struct {
+	Username string
+	Password string
+	Token    string
+	// Headers are the extra request headers in the "Name: value" form
+	Headers []string
+	CACert  []byte
+}
+
+// GetDownloadCredentials returns the credentials in the download secret of the VM image, they're empty if the
+// VM image doesn't reference one.
+func GetDownloadCredentials(secretCache ctlcorev1.SecretCache, vmi *harvesterv1.VirtualMachineImage) (*DownloadCredentials, error) {
+	credentials := &DownloadCredentials{}
+	if vmi.Spec.DownloadSecretName == "" {
+		return credentials, nil
+	}
+
+	secret, err := secretCache.Get(vmi.Namespace, vmi.Spec.DownloadSecretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get download secret %s/%s: %w", vmi.Namespace, vmi.Spec.DownloadSecretName, err)
+	}
+	credentials.Username = string(secret.Data[DownloadSecretUsernameKey])
+	credentials.Password = string(secret.Data[DownloadSecretPasswordKey])
+	credentials.Token = strings.TrimSpace(string(secret.Data[DownloadSecretTokenKey]))
+	credentials.CACert = secret.Data[DownloadSecretCAKey]
+
+	for _, line := range strings.Split(string(secret.Data[DownloadSecretHeadersKey]), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q in download secret %s/%s", name, vmi.Namespace, vmi.Spec.DownloadSecretName)
+		}
+		credentials.Headers = append(credentials.Headers, fmt.Sprintf("%s: %s", name, strings.TrimSpace(value)))
+	}
+	return credentials, nil
+}
+
+// AuthorizationHeader returns the Authorization header of the bearer token or the basic auth, it's empty without them
+func (c *DownloadCredentials) AuthorizationHeader() string {
+	if c.Token != "" {
+		return "Bearer " + c.Token
+	}
+	if c.Username != "" {
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(c.Username, c.Password)
+		return req.Header.Get("Authorization")
+	}
+	return ""
+}
+
+// NewHTTPClient returns the client trusting the CA and sending the credentials with the requests. The credentials
+// aren't sent once a request is redirected to another host, e.g. the pre-signed URLs of object stores.
+func (c *DownloadCredentials) NewHTTPClient(timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(c.CACert) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(c.CACert) {
+			return nil, errors.New("failed to parse the CA certificate of the download secret")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &credentialsTransport{credentials: c, base: transport},
+	}, nil
+}
+
+type credentialsTransport struct {
+	credentials *DownloadCredentials
+	base        http.RoundTripper
+}
+
+func (t *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if leftOrigin(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	for _, header := range t.credentials.Headers {
+		name, value, _ := strings.Cut(header, ": ")
+		req.Header.Set(name, value)
+	}
+	if authorization := t.credentials.AuthorizationHeader(); authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// leftOrigin returns true if the request or any request redirecting to it isn't sent to the host of the
+// original request, the redirects back to the original host don't get the credentials either.
+func leftOrigin(req *http.Request) bool {
+	var hops []*url.URL
+	for r := req; r != nil; {
+		hops = append(hops, r.URL)
+		if r.Response == nil {
+			break
+		}
+		r = r.Response.Request
+	}
+
+	origin := hops[len(hops)-1]
+	for _, hop := range hops {
+		if hop.Host != origin.Host || hop.Scheme != origin.Scheme {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadCredentials_NewHTTPClient_redirect(t *testing.T) {
+	// origin redirects to /same on itself, and /other to the other host which redirects on itself and back
+	var originURL, otherURL string
+	received := map[string]string{}
+	record := func(host string) http.HandlerFunc {
+		return func(rw http.ResponseWriter, req *http.Request) {
+			received[host+req.URL.Path] = req.Header.Get("Authorization") + "|" + req.Header.Get("X-Tenant")
+			switch req.URL.Path {
+			case "/same":
+				http.Redirect(rw, req, originURL+"/image.qcow2", http.StatusFound)
+			case "/other":
+				http.Redirect(rw, req, otherURL+"/hop", http.StatusFound)
+			case "/hop":
+				http.Redirect(rw, req, otherURL+"/image.qcow2", http.StatusFound)
+			case "/back":
+				http.Redirect(rw, req, originURL+"/returned", http.StatusFound)
+			}
+		}
+	}
+	origin := httptest.NewServer(record("origin"))
+	defer origin.Close()
+	other := httptest.NewServer(record("other"))
+	defer other.Close()
+	originURL, otherURL = origin.URL, other.URL
+
+	credentials := &DownloadCredentials{Token: "secret-token", Headers: []string{"X-Tenant: harvester"}}
+	client, err := credentials.NewHTTPClient(0)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		url      string
+		expected map[string]string
+	}{
+		{
+			name: "redirect on the origin",
+			url:  originURL + "/same",
+			expected: map[string]string{
+				"origin/same":        "Bearer secret-token|harvester",
+				"origin/image.qcow2": "Bearer secret-token|harvester",
+			},
+		},
+		{
+			name: "redirect chain on another host",
+			url:  originURL + "/other",
+			expected: map[string]string{
+				"origin/other":      "Bearer secret-token|harvester",
+				"other/hop":         "|",
+				"other/image.qcow2": "|",
+			},
+		},
+		{
+			name: "redirect back to the origin",
+			url:  otherURL + "/back",
+			expected: map[string]string{
+				"other/back":      "Bearer secret-token|harvester",
+				"origin/returned": "|",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clear(received)
+			resp, err := client.Get(tc.url)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.expected, received)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/image/format"
+)
+
+const probeTimeout = 60 * time.Second
+
+// ProbeDownload downloads the header of the image with the credentials of the download secret to detect its format,
+// compression and virtual size. The server may ignore the range and send the whole image, only the header is read then.
+func ProbeDownload(ctx context.Context, secretCache ctlcorev1.SecretCache, vmi *harvesterv1.VirtualMachineImage) (*format.Image, error) {
+	credentials, err := GetDownloadCredentials(secretCache, vmi)
+	if err != nil {
+		return nil, err
+	}
+	client, err := credentials.NewHTTPClient(probeTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	imageURL := vmi.Spec.URL
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", format.ProbeSize-1))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("got %d status code from %s", resp.StatusCode, imageURL)
+	}
+
+	img, err := format.Probe(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect the format of image %s: %w", imageURL, err)
+	}
+	return img, nil
+}
+
+// OpenUploadedImage returns the image of the upload request, which is the file of the multipart form sent by the
+// dashboard, or the request body itself.
+func OpenUploadedImage(req *http.Request) (io.Reader, error) {
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/form-data") {
+		return req.Body, nil
+	}
+	form, err := req.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the upload form: %w", err)
+	}
+	for {
+		part, err := form.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no image file in the upload form")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the upload form: %w", err)
+		}
+		if part.FileName() != "" {
+			return part, nil
+		}
+	}
+}
+
+// RecordSourceFormat records the detected format of the image in the status, it's retried on conflicts since the
+// VM image controller may update the image at the same time.
+func RecordSourceFormat(vmio VMIOperator, vmi *harvesterv1.VirtualMachineImage, img *format.Image) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		current, err := vmio.GetVMImageObj(vmi.Namespace, vmi.Name)
+		if err != nil {
+			return err
+		}
+		_, err = vmio.UpdateSourceFormat(current, img.Format, img.Compression)
+		return err
+	})
+}
@@ -65,7 +65,7 @@ type VMIOperator interface {
 	GetSecurityCryptoOption(vmi *harvesterv1.VirtualMachineImage) string
 	GetSecuritySrcImgNamespace(vmi *harvesterv1.VirtualMachineImage) string
 	GetSecuritySrcImgName(vmi *harvesterv1.VirtualMachineImage) string
-	GetBackupTarget(vmi *harvesterv1.VirtualMachineImage) *harvesterv1.BackupTarget
+	GetBackupTarget(vmi *harvesterv1.VirtualMachineImage) *harvesterv1.BackupTargetInfo
 	GetDisplayName(vmi *harvesterv1.VirtualMachineImage) string
 	GetStorageClassName(vmi *harvesterv1.VirtualMachineImage) string
 
@@ -80,7 +80,8 @@ type VMIOperator interface {
 	UpdateSize(old *harvesterv1.VirtualMachineImage, size int64) (*harvesterv1.VirtualMachineImage, error)
 	UpdateVirtualSizeAndSize(old *harvesterv1.VirtualMachineImage, virtualSize, size int64) (*harvesterv1.VirtualMachineImage, error)
 	UpdateLastFailedTime(old *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error)
-	UpdateBackupTarget(old *harvesterv1.VirtualMachineImage, bt *harvesterv1.BackupTarget) (*harvesterv1.VirtualMachineImage, error)
+	UpdateBackupTarget(old *harvesterv1.VirtualMachineImage, bt *harvesterv1.BackupTargetInfo) (*harvesterv1.VirtualMachineImage, error)
+	UpdateSourceFormat(old *harvesterv1.VirtualMachineImage, sourceFormat harvesterv1.VirtualMachineImageFormat, compression harvesterv1.VirtualMachineImageCompression) (*harvesterv1.VirtualMachineImage, error)
 
 	FailUpload(old *harvesterv1.VirtualMachineImage, msg string) error
 
@@ -150,6 +151,9 @@ func (vmio *vmiOperator) GetUID(vmi *harvesterv1.VirtualMachineImage) types.UID
 }
 
 func (vmio *vmiOperator) GetChecksum(vmi *harvesterv1.VirtualMachineImage) string {
+	if vmi.Spec.Checksum == "" {
+		return vmi.Status.VerifiedChecksum
+	}
 	return vmi.Spec.Checksum
 }
 
@@ -204,7 +208,7 @@ func (vmio *vmiOperator) GetStorageClassName(vmi *harvesterv1.VirtualMachineImag
 	return scName
 }
 
-func (vmio *vmiOperator) GetBackupTarget(vmi *harvesterv1.VirtualMachineImage) *harvesterv1.BackupTarget {
+func (vmio *vmiOperator) GetBackupTarget(vmi *harvesterv1.VirtualMachineImage) *harvesterv1.BackupTargetInfo {
 	return vmi.Status.BackupTarget
 }
 
@@ -233,7 +237,8 @@ func (vmio *vmiOperator) IsEncryptOperation(vmi *harvesterv1.VirtualMachineImage
 }
 
 func (vmio *vmiOperator) CheckURLAndUpdate(old *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
-	if old.Spec.SourceType != harvesterv1.VirtualMachineImageSourceTypeDownload {
+	// the URL needing credentials is checked by the import itself
+	if old.Spec.SourceType != harvesterv1.VirtualMachineImageSourceTypeDownload || old.Spec.DownloadSecretName != "" {
 		return old, nil
 	}
 
@@ -282,12 +287,19 @@ func (vmio *vmiOperator) UpdateLastFailedTime(old *harvesterv1.VirtualMachineIma
 	return vmio.UpdateVMI(old, newVMI)
 }
 
-func (vmio *vmiOperator) UpdateBackupTarget(old *harvesterv1.VirtualMachineImage, bt *harvesterv1.BackupTarget) (*harvesterv1.VirtualMachineImage, error) {
+func (vmio *vmiOperator) UpdateBackupTarget(old *harvesterv1.VirtualMachineImage, bt *harvesterv1.BackupTargetInfo) (*harvesterv1.VirtualMachineImage, error) {
 	newVMI := old.DeepCopy()
 	newVMI.Status.BackupTarget = bt
 	return vmio.UpdateVMI(old, newVMI)
 }
 
+func (vmio *vmiOperator) UpdateSourceFormat(old *harvesterv1.VirtualMachineImage, sourceFormat harvesterv1.VirtualMachineImageFormat, compression harvesterv1.VirtualMachineImageCompression) (*harvesterv1.VirtualMachineImage, error) {
+	newVMI := old.DeepCopy()
+	newVMI.Status.SourceFormat = sourceFormat
+	newVMI.Status.SourceCompression = compression
+	return vmio.UpdateVMI(old, newVMI)
+}
+
 func (vmio *vmiOperator) FailUpload(old *harvesterv1.VirtualMachineImage, msg string) error {
 	retry := 3
 	for i := 0; i < retry; i++ {
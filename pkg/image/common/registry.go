@@ -0,0 +1,70 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/containerd"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/registry"
+)
+
+// GetRegistryCredentials returns the credentials to pull the container disk image of the VM image, they come from
+// the secret referenced by the VM image, or from the registry config of the containerd-registry setting. The webhook
+// checks the user creating the VM image can get the referenced secret.
+// The credentials of the setting are only used by the images in the harvester-system namespace, otherwise any user
+// able to create images could pull the private images, and the CDI importer secret would copy them to the namespace.
+// The CA files in the setting are paths on the nodes, so only the insecure option of its TLS config is used.
+func GetRegistryCredentials(secretCache ctlcorev1.SecretCache, vmi *harvesterv1.VirtualMachineImage) (*registry.Credentials, error) {
+	if vmi.Spec.RegistrySecretName != "" {
+		secret, err := secretCache.Get(vmi.Namespace, vmi.Spec.RegistrySecretName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get registry secret %s/%s: %w", vmi.Namespace, vmi.Spec.RegistrySecretName, err)
+		}
+		return &registry.Credentials{
+			Username: string(secret.Data[registry.SecretUsernameKey]),
+			Password: string(secret.Data[registry.SecretPasswordKey]),
+			CACert:   secret.Data[registry.SecretCAKey],
+		}, nil
+	}
+
+	ref, err := registry.ParseReference(vmi.Spec.URL)
+	if err != nil {
+		return nil, err
+	}
+	value := settings.ContainerdRegistry.Get()
+	if value == "" {
+		return &registry.Credentials{}, nil
+	}
+	registries := &containerd.Registry{}
+	if err := json.Unmarshal([]byte(value), registries); err != nil {
+		return nil, fmt.Errorf("failed to parse setting %s: %w", settings.ContainerdRegistrySettingName, err)
+	}
+
+	credentials := &registry.Credentials{}
+	config, ok := registries.Configs[ref.Domain]
+	if !ok {
+		return credentials, nil
+	}
+	if config.TLS != nil {
+		credentials.InsecureSkipVerify = config.TLS.InsecureSkipVerify
+	}
+	if config.Auth == nil || vmi.Namespace != util.HarvesterSystemNamespaceName {
+		return credentials, nil
+	}
+	credentials.Username, credentials.Password = config.Auth.Username, config.Auth.Password
+	if credentials.Username == "" && config.Auth.Auth != "" {
+		auth, err := base64.StdEncoding.DecodeString(config.Auth.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the auth of registry %s: %w", ref.Domain, err)
+		}
+		credentials.Username, credentials.Password, _ = strings.Cut(string(auth), ":")
+	}
+	return credentials, nil
+}
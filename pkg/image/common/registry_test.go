@@ -0,0 +1,89 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+	"github.com/harvester/harvester/pkg/util/registry"
+)
+
+func TestGetRegistryCredentials(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: util.HarvesterSystemNamespaceName, Name: "harbor"},
+		Data: map[string][]byte{
+			registry.SecretUsernameKey: []byte("robot"),
+			registry.SecretPasswordKey: []byte("secret"),
+			registry.SecretCAKey:       []byte("ca"),
+		},
+	}
+	newImage := func(url, secretName string) *harvesterv1.VirtualMachineImage {
+		return &harvesterv1.VirtualMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Namespace: util.HarvesterSystemNamespaceName, Name: "image"},
+			Spec: harvesterv1.VirtualMachineImageSpec{
+				SourceType:         harvesterv1.VirtualMachineImageSourceTypeRegistry,
+				URL:                url,
+				RegistrySecretName: secretName,
+			},
+		}
+	}
+
+	assert.NoError(t, settings.ContainerdRegistry.Set(`{"configs":{"harbor.example.com":{"auth":{"username":"admin","password":"pass"},"tls":{"insecureSkipVerify":true}},"registry.example.com":{"auth":{"auth":"dXNlcjpwYXNz"}}}}`))
+	defer func() {
+		_ = settings.ContainerdRegistry.Set("")
+	}()
+
+	inNamespace := func(image *harvesterv1.VirtualMachineImage, namespace string) *harvesterv1.VirtualMachineImage {
+		image.Namespace = namespace
+		return image
+	}
+
+	tests := []struct {
+		name     string
+		image    *harvesterv1.VirtualMachineImage
+		expected *registry.Credentials
+	}{
+		{
+			name:     "credentials from the secret",
+			image:    newImage("docker://harbor.example.com/images/ubuntu:22.04", "harbor"),
+			expected: &registry.Credentials{Username: "robot", Password: "secret", CACert: []byte("ca")},
+		},
+		{
+			name:     "credentials from the setting",
+			image:    newImage("docker://harbor.example.com/images/ubuntu:22.04", ""),
+			expected: &registry.Credentials{Username: "admin", Password: "pass", InsecureSkipVerify: true},
+		},
+		{
+			name:     "encoded auth from the setting",
+			image:    newImage("registry.example.com/images/ubuntu", ""),
+			expected: &registry.Credentials{Username: "user", Password: "pass"},
+		},
+		{
+			name:     "credentials of the setting aren't used by other namespaces",
+			image:    inNamespace(newImage("docker://harbor.example.com/images/ubuntu:22.04", ""), "default"),
+			expected: &registry.Credentials{InsecureSkipVerify: true},
+		},
+		{
+			name:     "registry isn't configured",
+			image:    newImage("quay.io/containerdisks/fedora:latest", ""),
+			expected: &registry.Credentials{},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(secret)
+	for _, tc := range tests {
+		credentials, err := GetRegistryCredentials(fakeclients.SecretCache(clientset.CoreV1().Secrets), tc.image)
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, credentials, tc.name)
+	}
+
+	_, err := GetRegistryCredentials(fakeclients.SecretCache(clientset.CoreV1().Secrets), newImage("quay.io/containerdisks/fedora", "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck // detached signatures are all we need from the frozen package
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+)
+
+const (
+	SignatureSecretPublicKeyKey = "publicKey"
+
+	maxSignatureSize = 64 * 1024
+)
+
+var (
+	ErrSignatureMismatch = errors.New("the signature doesn't match the image")
+)
+
+// GetSignaturePublicKey returns the public key verifying the signature of the VM image, the trusted public key of
+// the vm-image-signature-policy setting takes precedence over the one of the VM image. The key of the VM image is
+// never used when the policy requires signatures.
+func GetSignaturePublicKey(secretCache ctlcorev1.SecretCache, vmi *harvesterv1.VirtualMachineImage) ([]byte, error) {
+	policy, err := settings.DecodeVMImageSignaturePolicy(settings.VMImageSignaturePolicySet.Get())
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, name := policy.PublicKeySecretNamespace, policy.PublicKeySecretName
+	if name == "" && !policy.Required {
+		namespace, name = vmi.Namespace, vmi.Spec.Signature.PublicKeySecretName
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no public key to verify the signature of image %s/%s", vmi.Namespace, vmi.Name)
+	}
+
+	secret, err := secretCache.Get(namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key secret %s/%s: %w", namespace, name, err)
+	}
+	publicKey := secret.Data[SignatureSecretPublicKeyKey]
+	if len(publicKey) == 0 {
+		return nil, fmt.Errorf("public key secret %s/%s has no %s", namespace, name, SignatureSecretPublicKeyKey)
+	}
+	return publicKey, nil
+}
+
+// VerifySignature downloads the image and its detached signature with the client, and verifies the signature with
+// the public key. It returns the SHA-512 checksum of the verified image, so the import can be pinned to the content.
+func VerifySignature(ctx context.Context, client *http.Client, imageURL string, signature *harvesterv1.VirtualMachineImageSignature, publicKey []byte) (string, error) {
+	sigBody, err := openURL(ctx, client, signature.URL)
+	if err != nil {
+		return "", err
+	}
+	sig, err := io.ReadAll(io.LimitReader(sigBody, maxSignatureSize))
+	sigBody.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read signature %s: %w", signature.URL, err)
+	}
+
+	imageBody, err := openURL(ctx, client, imageURL)
+	if err != nil {
+		return "", err
+	}
+	defer imageBody.Close()
+
+	checksum := sha512.New()
+	image := io.TeeReader(imageBody, checksum)
+	switch signature.Type {
+	case harvesterv1.VirtualMachineImageSignatureTypeCosign:
+		err = verifyCosignSignature(image, sig, publicKey)
+	case harvesterv1.VirtualMachineImageSignatureTypeGPG:
+		err = verifyGPGSignature(image, sig, publicKey)
+	default:
+		err = fmt.Errorf("unsupported signature type %s", signature.Type)
+	}
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(checksum.Sum(nil)), nil
+}
+
+func openURL(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		resp.Body.Close()
+		return nil, fmt.Errorf("got %d status code from %s", resp.StatusCode, url)
+	}
+	return resp.Body, nil
+}
+
+// verifyCosignSignature verifies the signature of `cosign sign-blob`, which signs the SHA-256 digest of the blob
+// with an ECDSA or RSA key and outputs the base64 encoded signature.
+func verifyCosignSignature(image io.Reader, signature, publicKey []byte) error {
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return errors.New("failed to decode the PEM public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse the public key: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("failed to decode the signature: %w", err)
+	}
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, image); err != nil {
+		return fmt.Errorf("failed to read the image: %w", err)
+	}
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest.Sum(nil), sig) {
+			return ErrSignatureMismatch
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest.Sum(nil), sig); err != nil {
+			return ErrSignatureMismatch
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	return nil
+}
+
+// verifyGPGSignature verifies the binary or armored detached signature with the armored or binary keyring
+func verifyGPGSignature(image io.Reader, signature, publicKey []byte) error {
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(publicKey))
+	if err != nil {
+		if keyring, err = openpgp.ReadKeyRing(bytes.NewReader(publicKey)); err != nil {
+			return fmt.Errorf("failed to read the public key: %w", err)
+		}
+	}
+
+	if bytes.Contains(signature, []byte("-----BEGIN PGP SIGNATURE-----")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, image, bytes.NewReader(signature))
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, image, bytes.NewReader(signature))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSignatureMismatch, err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func TestGetDownloadCredentials(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "artifactory"},
+		Data: map[string][]byte{
+			DownloadSecretTokenKey:   []byte("secret-token\n"),
+			DownloadSecretHeadersKey: []byte("X-JFrog-Art-Api: key\n\n  X-Trace : 1 \n"),
+			DownloadSecretCAKey:      []byte("ca"),
+		},
+	}
+	invalid := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "invalid"},
+		Data: map[string][]byte{
+			DownloadSecretHeadersKey: []byte("no-colon"),
+		},
+	}
+	clientset := fake.NewSimpleClientset(secret, invalid)
+	secretCache := fakeclients.SecretCache(clientset.CoreV1().Secrets)
+	newImage := func(secretName string) *harvesterv1.VirtualMachineImage {
+		return &harvesterv1.VirtualMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "image"},
+			Spec: harvesterv1.VirtualMachineImageSpec{
+				SourceType:         harvesterv1.VirtualMachineImageSourceTypeDownload,
+				DownloadSecretName: secretName,
+			},
+		}
+	}
+
+	credentials, err := GetDownloadCredentials(secretCache, newImage("artifactory"))
+	assert.NoError(t, err)
+	assert.Equal(t, &DownloadCredentials{
+		Token:   "secret-token",
+		Headers: []string{"X-JFrog-Art-Api: key", "X-Trace: 1"},
+		CACert:  []byte("ca"),
+	}, credentials)
+	assert.Equal(t, "Bearer secret-token", credentials.AuthorizationHeader())
+
+	credentials, err = GetDownloadCredentials(secretCache, newImage(""))
+	assert.NoError(t, err)
+	assert.Equal(t, &DownloadCredentials{}, credentials)
+	assert.Equal(t, "", credentials.AuthorizationHeader())
+
+	_, err = GetDownloadCredentials(secretCache, newImage("invalid"))
+	assert.ErrorContains(t, err, "invalid header")
+	_, err = GetDownloadCredentials(secretCache, newImage("missing"))
+	assert.Error(t, err)
+}
+
+func TestVerifySignature(t *testing.T) {
+	image := bytes.Repeat([]byte("harvester"), 1024)
+	digest := sha256.Sum256(image)
+	checksum := sha512.Sum512(image)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecdsaSig, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	encodePublicKey := func(key crypto.PublicKey) []byte {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+
+	entity, err := openpgp.NewEntity("harvester", "", "harvester@example.com", nil)
+	require.NoError(t, err)
+	gpgSig := &bytes.Buffer{}
+	require.NoError(t, openpgp.ArmoredDetachSign(gpgSig, entity, bytes.NewReader(image), nil))
+	gpgKey := &bytes.Buffer{}
+	require.NoError(t, entity.Serialize(gpgKey))
+
+	files := map[string][]byte{
+		"/image.qcow2":        image,
+		"/image.qcow2.ecdsa":  []byte(base64.StdEncoding.EncodeToString(ecdsaSig) + "\n"),
+		"/image.qcow2.rsa":    []byte(base64.StdEncoding.EncodeToString(rsaSig)),
+		"/image.qcow2.asc":    gpgSig.Bytes(),
+		"/image.qcow2.broken": []byte(base64.StdEncoding.EncodeToString(rsaSig[1:])),
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret-token" || req.Header.Get("X-Tenant") != "harvester" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		content, ok := files[req.URL.Path]
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = rw.Write(content)
+	}))
+	defer server.Close()
+
+	credentials := &DownloadCredentials{
+		Token:   "secret-token",
+		Headers: []string{"X-Tenant: harvester"},
+		CACert:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+	}
+	client, err := credentials.NewHTTPClient(0)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		client    *http.Client
+		signature *harvesterv1.VirtualMachineImageSignature
+		publicKey []byte
+		expectErr bool
+	}{
+		{
+			name:      "cosign ecdsa signature",
+			client:    client,
+			signature: &harvesterv1.VirtualMachineImageSignature{Type: harvesterv1.VirtualMachineImageSignatureTypeCosign, URL: server.URL + "/image.qcow2.ecdsa"},
+			publicKey: encodePublicKey(&ecdsaKey.PublicKey),
+		},
+		{
+			name:      "cosign rsa signature",
+			client:    client,
+			signature: &harvesterv1.VirtualMachineImageSignature{Type: harvesterv1.VirtualMachineImageSignatureTypeCosign, URL: server.URL + "/image.qcow2.rsa"},
+			publicKey: encodePublicKey(&rsaKey.PublicKey),
+		},
+		{
+			name:      "gpg signature",
+			client:    client,
+			signature: &harvesterv1.VirtualMachineImageSignature{Type: harvesterv1.VirtualMachineImageSignatureTypeGPG, URL: server.URL + "/image.qcow2.asc"},
+			publicKey: gpgKey.Bytes(),
+		},
+		{
+			name:      "signed by another key",
+			client:    client,
+			signature: &harvesterv1.VirtualMachineImageSignature{Type: harvesterv1.VirtualMachineImageSignatureTypeCosign, URL: server.URL + "/image.qcow2.ecdsa"},
+			publicKey: encodePublicKey(&rsaKey.PublicKey),
+			expectErr: true,
+		},
+		{
+			name:      "broken signature",
+			client:    client,
+			signature: &harvesterv1.VirtualMachineImageSignature{Type: harvesterv1.VirtualMachineImageSignatureTypeCosign, URL: server.URL + "/image.qcow2.broken"},
+			publicKey: encodePublicKey(&rsaKey.PublicKey),
+			expectErr: true,
+		},
+		{
+			name:      "missing signature",
+			client:    client,
+			signature: &harvesterv1.VirtualMachineImageSignature{Type: harvesterv1.VirtualMachineImageSignatureTypeGPG, URL: server.URL + "/image.qcow2.sig"},
+			publicKey: gpgKey.Bytes(),
+			expectErr: true,
+		},
+		{
+			name:      "without credentials",
+			client:    &http.Client{Transport: &http.Transport{TLSClientConfig: server.Client().Transport.(*http.Transport).TLSClientConfig}},
+			signature: &harvesterv1.VirtualMachineImageSignature{Type: harvesterv1.VirtualMachineImageSignatureTypeCosign, URL: server.URL + "/image.qcow2.ecdsa"},
+			publicKey: encodePublicKey(&ecdsaKey.PublicKey),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		verified, err := VerifySignature(context.Background(), tc.client, server.URL+"/image.qcow2", tc.signature, tc.publicKey)
+		if tc.expectErr {
+			assert.Error(t, err, tc.name)
+			continue
+		}
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, hex.EncodeToString(checksum[:]), verified, tc.name)
+	}
+}
+
+func TestGetSignaturePublicKey(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "image-key"},
+			Data:       map[string][]byte{SignatureSecretPublicKeyKey: []byte("image")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "harvester-system", Name: "trusted-key"},
+			Data:       map[string][]byte{SignatureSecretPublicKeyKey: []byte("trusted")},
+		},
+	)
+	secretCache := fakeclients.SecretCache(clientset.CoreV1().Secrets)
+	vmi := &harvesterv1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "image"},
+		Spec: harvesterv1.VirtualMachineImageSpec{
+			Signature: &harvesterv1.VirtualMachineImageSignature{PublicKeySecretName: "image-key"},
+		},
+	}
+
+	publicKey, err := GetSignaturePublicKey(secretCache, vmi)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("image"), publicKey)
+
+	assert.NoError(t, settings.VMImageSignaturePolicySet.Set(`{"publicKeySecretNamespace":"harvester-system","publicKeySecretName":"trusted-key"}`))
+	defer func() {
+		_ = settings.VMImageSignaturePolicySet.Set("{}")
+	}()
+	publicKey, err = GetSignaturePublicKey(secretCache, vmi)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("trusted"), publicKey)
+
+	// the key of the image isn't trusted when signatures are required
+	assert.NoError(t, settings.VMImageSignaturePolicySet.Set(`{"required":true}`))
+	_, err = GetSignaturePublicKey(secretCache, vmi)
+	assert.Error(t, err)
+}
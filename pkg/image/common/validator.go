@@ -17,10 +17,13 @@ import (
 
 	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/registry"
 	werror "github.com/harvester/harvester/pkg/webhook/error"
 	"github.com/harvester/harvester/pkg/webhook/indexeres"
 	"github.com/harvester/harvester/pkg/webhook/types"
+	webhookutil "github.com/harvester/harvester/pkg/webhook/util"
 )
 
 const (
@@ -33,7 +36,9 @@ type VMIValidator interface {
 	CheckDisplayName(vmi *v1beta1.VirtualMachineImage) error
 	CheckUpdateDisplayName(oldVMI, newVMI *v1beta1.VirtualMachineImage) error
 	CheckURL(vmi *v1beta1.VirtualMachineImage) error
+	CheckSignature(vmi *v1beta1.VirtualMachineImage) error
 	CheckSecurityParameters(vmi *v1beta1.VirtualMachineImage) error
+	CheckSecretAccess(request *types.Request, vmi *v1beta1.VirtualMachineImage) error
 	CheckImagePVC(request *types.Request, vmi *v1beta1.VirtualMachineImage) error
 	CheckPVCInUse(vmi *v1beta1.VirtualMachineImage) error
 
@@ -45,6 +50,8 @@ type VMIValidator interface {
 	PVCConsistency(oldVMI, newVMI *v1beta1.VirtualMachineImage) error
 	URLConsistency(oldVMI, newVMI *v1beta1.VirtualMachineImage) error
 	SecurityParameterConsistency(oldVMI, newVMI *v1beta1.VirtualMachineImage) error
+	SignatureConsistency(oldVMI, newVMI *v1beta1.VirtualMachineImage) error
+	SecretConsistency(oldVMI, newVMI *v1beta1.VirtualMachineImage) error
 
 	VMTemplateVersionOccupation(vmi *v1beta1.VirtualMachineImage) error
 	PVCOccupation(vmi *v1beta1.VirtualMachineImage) error
@@ -55,6 +62,7 @@ type vmiValidator struct {
 	vmiCache               ctlharvesterv1.VirtualMachineImageCache
 	scCache                ctlstoragev1.StorageClassCache
 	ssar                   authorizationv1client.SelfSubjectAccessReviewInterface
+	sar                    authorizationv1client.SubjectAccessReviewInterface
 	podCache               ctlcorev1.PodCache
 	pvcCache               ctlcorev1.PersistentVolumeClaimCache
 	vmTemplateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache
@@ -64,6 +72,7 @@ type vmiValidator struct {
 func GetVMIValidator(vmiCache ctlharvesterv1.VirtualMachineImageCache,
 	scCache ctlstoragev1.StorageClassCache,
 	ssar authorizationv1client.SelfSubjectAccessReviewInterface,
+	sar authorizationv1client.SubjectAccessReviewInterface,
 	podCache ctlcorev1.PodCache,
 	pvcCache ctlcorev1.PersistentVolumeClaimCache,
 	vmTemplateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache,
@@ -72,6 +81,7 @@ func GetVMIValidator(vmiCache ctlharvesterv1.VirtualMachineImageCache,
 		vmiCache:               vmiCache,
 		scCache:                scCache,
 		ssar:                   ssar,
+		sar:                    sar,
 		podCache:               podCache,
 		pvcCache:               pvcCache,
 		vmTemplateVersionCache: vmTemplateVersionCache,
@@ -114,6 +124,24 @@ func (v *vmiValidator) CheckUpdateDisplayName(oldVMI, newVMI *v1beta1.VirtualMac
 }
 
 func (v *vmiValidator) CheckURL(vmi *v1beta1.VirtualMachineImage) error {
+	if vmi.Spec.RegistrySecretName != "" && vmi.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeRegistry {
+		return werror.NewInvalidError(fmt.Sprintf(`registrySecretName should be empty when image source type is "%s"`, vmi.Spec.SourceType), "spec.registrySecretName")
+	}
+
+	if vmi.Spec.DownloadSecretName != "" && vmi.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeDownload {
+		return werror.NewInvalidError(fmt.Sprintf(`downloadSecretName should be empty when image source type is "%s"`, vmi.Spec.SourceType), "spec.downloadSecretName")
+	}
+
+	if vmi.Spec.SourceType == v1beta1.VirtualMachineImageSourceTypeRegistry {
+		if vmi.Spec.URL == "" {
+			return werror.NewInvalidError("url is required", "spec.url")
+		}
+		if _, err := registry.ParseReference(vmi.Spec.URL); err != nil {
+			return werror.NewInvalidError(err.Error(), "spec.url")
+		}
+		return nil
+	}
+
 	shouldHaveURL := false
 	if vmi.Spec.SourceType == v1beta1.VirtualMachineImageSourceTypeDownload || vmi.Spec.SourceType == v1beta1.VirtualMachineImageSourceTypeRestore {
 		shouldHaveURL = true
@@ -137,6 +165,87 @@ func (v *vmiValidator) CheckURL(vmi *v1beta1.VirtualMachineImage) error {
 	return nil
 }
 
+// CheckSignature checks the signature of the VM image and enforces the vm-image-signature-policy setting. When the policy
+// requires signatures, the downloaded images must be signed, and the uploaded and registry images are rejected since
+// their signatures can't be verified. The images created from the volumes, backups and other images in the cluster
+// and the images of the upgrade repo are trusted. Only the trusted public key of the policy verifies the signatures
+// when they're required. The CDI importer downloads the image by itself, the bytes it imports may differ from the
+// verified ones, so the signed images aren't supported by the CDI backend, and its downloaded images are rejected when
+// the policy requires signatures.
+func (v *vmiValidator) CheckSignature(vmi *v1beta1.VirtualMachineImage) error {
+	sig := vmi.Spec.Signature
+	if sig != nil {
+		if vmi.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeDownload {
+			return werror.NewInvalidError(fmt.Sprintf(`signature should be empty when image source type is "%s"`, vmi.Spec.SourceType), "spec.signature")
+		}
+		if vmi.Spec.Backend == v1beta1.VMIBackendCDI {
+			return werror.NewInvalidError(fmt.Sprintf(`signature is not supported by backend "%s"`, vmi.Spec.Backend), "spec.signature")
+		}
+		if sig.Type != v1beta1.VirtualMachineImageSignatureTypeCosign && sig.Type != v1beta1.VirtualMachineImageSignatureTypeGPG {
+			return werror.NewInvalidError(fmt.Sprintf("unsupported signature type %q", sig.Type), "spec.signature.type")
+		}
+		if sig.URL == "" {
+			return werror.NewInvalidError("signature url is required", "spec.signature.url")
+		}
+		if _, err := url.Parse(sig.URL); err != nil {
+			return werror.NewInvalidError(fmt.Sprintf("signature url is invalid: %s", err.Error()), "spec.signature.url")
+		}
+	}
+
+	policy, err := settings.DecodeVMImageSignaturePolicy(settings.VMImageSignaturePolicySet.Get())
+	if err != nil {
+		return werror.NewInternalError(fmt.Sprintf("failed to decode setting %s: %v", settings.VMImageSignaturePolicySettingName, err))
+	}
+	if sig != nil && sig.PublicKeySecretName == "" && policy.PublicKeySecretName == "" {
+		return werror.NewInvalidError("publicKeySecretName is required when there is no trusted public key in the signature policy", "spec.signature.publicKeySecretName")
+	}
+	if !policy.Required || vmi.Annotations[util.AnnotationUpgradeImage] == "True" {
+		return nil
+	}
+	if policy.PublicKeySecretName == "" {
+		return werror.NewInvalidError("the image signature policy requires signatures without a trusted public key", "spec.signature")
+	}
+
+	switch vmi.Spec.SourceType {
+	case v1beta1.VirtualMachineImageSourceTypeDownload:
+		if vmi.Spec.Backend == v1beta1.VMIBackendCDI {
+			return werror.NewInvalidError(fmt.Sprintf(`backend "%s" is not allowed by the image signature policy`, vmi.Spec.Backend), "spec.backend")
+		}
+		if sig == nil {
+			return werror.NewInvalidError("signature is required by the image signature policy", "spec.signature")
+		}
+	case v1beta1.VirtualMachineImageSourceTypeUpload, v1beta1.VirtualMachineImageSourceTypeRegistry:
+		return werror.NewInvalidError(fmt.Sprintf(`image source type "%s" is not allowed by the image signature policy`, vmi.Spec.SourceType), "spec.sourceType")
+	}
+	return nil
+}
+
+// CheckSecretAccess checks the user can get the secrets referenced by the VM image, the controller reads them
+// and sends their credentials to the hosts chosen by the user.
+func (v *vmiValidator) CheckSecretAccess(request *types.Request, vmi *v1beta1.VirtualMachineImage) error {
+	secrets := map[string]string{
+		"spec.downloadSecretName": vmi.Spec.DownloadSecretName,
+		"spec.registrySecretName": vmi.Spec.RegistrySecretName,
+	}
+	if vmi.Spec.Signature != nil {
+		secrets["spec.signature.publicKeySecretName"] = vmi.Spec.Signature.PublicKeySecretName
+	}
+
+	for field, name := range secrets {
+		if name == "" {
+			continue
+		}
+		allowed, err := webhookutil.CanGetSecret(v.sar, request.UserInfo, vmi.Namespace, name)
+		if err != nil {
+			return werror.NewInternalError(fmt.Sprintf("failed to check the permission of secret %s/%s: %v", vmi.Namespace, name, err))
+		}
+		if !allowed {
+			return werror.NewInvalidError(fmt.Sprintf("user %s is not allowed to get secret %s/%s", request.UserInfo.Username, vmi.Namespace, name), field)
+		}
+	}
+	return nil
+}
+
 func (v *vmiValidator) CheckSecurityParameters(vmi *v1beta1.VirtualMachineImage) error {
 	if vmi.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeClone {
 		return nil
@@ -315,6 +424,24 @@ func (v *vmiValidator) SecurityParameterConsistency(oldVMI, newVMI *v1beta1.Virt
 	return nil
 }
 
+func (v *vmiValidator) SignatureConsistency(oldVMI, newVMI *v1beta1.VirtualMachineImage) error {
+	if !reflect.DeepEqual(oldVMI.Spec.Signature, newVMI.Spec.Signature) {
+		return werror.NewInvalidError("signature cannot be modified", "spec.signature")
+	}
+	return nil
+}
+
+// SecretConsistency keeps the secrets referenced by the VM image, their permission is checked on creation
+func (v *vmiValidator) SecretConsistency(oldVMI, newVMI *v1beta1.VirtualMachineImage) error {
+	if oldVMI.Spec.DownloadSecretName != newVMI.Spec.DownloadSecretName {
+		return werror.NewInvalidError("downloadSecretName cannot be modified", "spec.downloadSecretName")
+	}
+	if oldVMI.Spec.RegistrySecretName != newVMI.Spec.RegistrySecretName {
+		return werror.NewInvalidError("registrySecretName cannot be modified", "spec.registrySecretName")
+	}
+	return nil
+}
+
 func (v *vmiValidator) VMTemplateVersionOccupation(vmi *v1beta1.VirtualMachineImage) error {
 	for _, ownerRef := range vmi.GetOwnerReferences() {
 		if ownerRef.Kind == "VirtualMachineTemplateVersion" {
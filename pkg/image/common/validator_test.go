@@ -0,0 +1,225 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/rancher/wrangler/v3/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+func TestCheckSignature(t *testing.T) {
+	signature := &harvesterv1.VirtualMachineImageSignature{
+		Type:                harvesterv1.VirtualMachineImageSignatureTypeCosign,
+		URL:                 "https://example.com/image.qcow2.sig",
+		PublicKeySecretName: "image-key",
+	}
+	newImage := func(sourceType harvesterv1.VirtualMachineImageSourceType, signature *harvesterv1.VirtualMachineImageSignature) *harvesterv1.VirtualMachineImage {
+		return &harvesterv1.VirtualMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "image"},
+			Spec: harvesterv1.VirtualMachineImageSpec{
+				SourceType: sourceType,
+				URL:        "https://example.com/image.qcow2",
+				Signature:  signature,
+			},
+		}
+	}
+	cdiImage := newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, signature)
+	cdiImage.Spec.Backend = harvesterv1.VMIBackendCDI
+	upgradeImage := newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, nil)
+	unsignedCDIImage := newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, nil)
+	unsignedCDIImage.Spec.Backend = harvesterv1.VMIBackendCDI
+	upgradeImage.Annotations = map[string]string{util.AnnotationUpgradeImage: "True"}
+	requiredPolicy := `{"required":true,"publicKeySecretNamespace":"harvester-system","publicKeySecretName":"trusted-key"}`
+
+	tests := []struct {
+		name   string
+		policy string
+		image  *harvesterv1.VirtualMachineImage
+		errMsg string
+	}{
+		{
+			name:  "unsigned image without policy",
+			image: newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, nil),
+		},
+		{
+			name:  "signed image without policy",
+			image: newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, signature),
+		},
+		{
+			name:   "signature of uploaded image",
+			image:  newImage(harvesterv1.VirtualMachineImageSourceTypeUpload, signature),
+			errMsg: "signature should be empty",
+		},
+		{
+			name:   "signature of cdi image",
+			image:  cdiImage,
+			errMsg: "signature is not supported",
+		},
+		{
+			name:   "cdi image required to be signed",
+			policy: requiredPolicy,
+			image:  cdiImage,
+			errMsg: "signature is not supported",
+		},
+		{
+			name:   "unsigned cdi image required to be signed",
+			policy: requiredPolicy,
+			image:  unsignedCDIImage,
+			errMsg: "not allowed by the image signature policy",
+		},
+		{
+			name:   "required signatures without trusted public key",
+			policy: `{"required":true}`,
+			image:  newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, signature),
+			errMsg: "without a trusted public key",
+		},
+		{
+			name:   "signature without public key",
+			image:  newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, &harvesterv1.VirtualMachineImageSignature{Type: harvesterv1.VirtualMachineImageSignatureTypeGPG, URL: "https://example.com/image.qcow2.asc"}),
+			errMsg: "publicKeySecretName is required",
+		},
+		{
+			name:   "signature with trusted public key of policy",
+			policy: `{"publicKeySecretNamespace":"harvester-system","publicKeySecretName":"trusted-key"}`,
+			image:  newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, &harvesterv1.VirtualMachineImageSignature{Type: harvesterv1.VirtualMachineImageSignatureTypeGPG, URL: "https://example.com/image.qcow2.asc"}),
+		},
+		{
+			name:   "unsigned image required to be signed",
+			policy: requiredPolicy,
+			image:  newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, nil),
+			errMsg: "signature is required",
+		},
+		{
+			name:   "signed image required to be signed",
+			policy: requiredPolicy,
+			image:  newImage(harvesterv1.VirtualMachineImageSourceTypeDownload, signature),
+		},
+		{
+			name:   "uploaded image required to be signed",
+			policy: requiredPolicy,
+			image:  newImage(harvesterv1.VirtualMachineImageSourceTypeUpload, nil),
+			errMsg: "not allowed by the image signature policy",
+		},
+		{
+			name:   "registry image required to be signed",
+			policy: requiredPolicy,
+			image:  newImage(harvesterv1.VirtualMachineImageSourceTypeRegistry, nil),
+			errMsg: "not allowed by the image signature policy",
+		},
+		{
+			name:   "exported image required to be signed",
+			policy: requiredPolicy,
+			image:  newImage(harvesterv1.VirtualMachineImageSourceTypeExportVolume, nil),
+		},
+		{
+			name:   "upgrade image required to be signed",
+			policy: requiredPolicy,
+			image:  upgradeImage,
+		},
+	}
+
+	defer func() {
+		_ = settings.VMImageSignaturePolicySet.Set("{}")
+	}()
+	v := &vmiValidator{}
+	for _, tc := range tests {
+		policy := tc.policy
+		if policy == "" {
+			policy = "{}"
+		}
+		assert.NoError(t, settings.VMImageSignaturePolicySet.Set(policy), tc.name)
+
+		err := v.CheckSignature(tc.image)
+		if tc.errMsg == "" {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+		assert.ErrorContains(t, err, tc.errMsg, tc.name)
+	}
+}
+
+func TestCheckSecretAccess(t *testing.T) {
+	// alice can only get the secret "mine"
+	allowed := map[string]bool{"mine": true}
+
+	tests := []struct {
+		name        string
+		spec        harvesterv1.VirtualMachineImageSpec
+		expectError bool
+	}{
+		{
+			name: "no secret",
+			spec: harvesterv1.VirtualMachineImageSpec{SourceType: harvesterv1.VirtualMachineImageSourceTypeDownload},
+		},
+		{
+			name: "download secret with the permission",
+			spec: harvesterv1.VirtualMachineImageSpec{SourceType: harvesterv1.VirtualMachineImageSourceTypeDownload, DownloadSecretName: "mine"},
+		},
+		{
+			name:        "download secret without the permission",
+			spec:        harvesterv1.VirtualMachineImageSpec{SourceType: harvesterv1.VirtualMachineImageSourceTypeDownload, DownloadSecretName: "others"},
+			expectError: true,
+		},
+		{
+			name: "registry secret with the permission",
+			spec: harvesterv1.VirtualMachineImageSpec{SourceType: harvesterv1.VirtualMachineImageSourceTypeRegistry, RegistrySecretName: "mine"},
+		},
+		{
+			name:        "registry secret without the permission",
+			spec:        harvesterv1.VirtualMachineImageSpec{SourceType: harvesterv1.VirtualMachineImageSourceTypeRegistry, RegistrySecretName: "others"},
+			expectError: true,
+		},
+		{
+			name: "public key secret without the permission",
+			spec: harvesterv1.VirtualMachineImageSpec{
+				SourceType: harvesterv1.VirtualMachineImageSourceTypeDownload,
+				Signature:  &harvesterv1.VirtualMachineImageSignature{PublicKeySecretName: "others"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := corefake.NewSimpleClientset()
+			clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				attributes := review.Spec.ResourceAttributes
+				assert.Equal(t, "alice", review.Spec.User)
+				assert.Equal(t, "default", attributes.Namespace)
+				review.Status.Allowed = attributes.Verb == "get" && attributes.Resource == "secrets" && allowed[attributes.Name]
+				return true, review, nil
+			})
+			request := &types.Request{
+				Request: &webhook.Request{
+					AdmissionRequest: admissionv1.AdmissionRequest{
+						UserInfo: authenticationv1.UserInfo{Username: "alice"},
+					},
+				},
+			}
+			v := &vmiValidator{sar: clientset.AuthorizationV1().SubjectAccessReviews()}
+
+			err := v.CheckSecretAccess(request, &harvesterv1.VirtualMachineImage{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "image"},
+				Spec:       tc.spec,
+			})
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
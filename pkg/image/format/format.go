@@ -0,0 +1,273 @@
+package format
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+const (
+	// ProbeSize is the size of the image header read to detect the format. It covers the ISO volume descriptor
+	// and the metadata region of VHDX images, and the OVF descriptor preceding the disks of OVA images.
+	ProbeSize = 4 << 20
+	// MaxVirtualSize is the largest virtual disk of the images, the virtual size read from the header sizes the
+	// scratch space of the conversion, so the images claiming a larger one are rejected.
+	MaxVirtualSize = 64 << 40
+
+	sectorSize = 512
+)
+
+var (
+	ErrUnsupported = errors.New("unsupported image")
+
+	magicGzip  = []byte{0x1f, 0x8b}
+	magicXZ    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	magicZstd  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	magicQCOW2 = []byte{'Q', 'F', 'I', 0xfb}
+	magicVMDK  = []byte("KDMV")
+	magicVHD   = []byte("conectix")
+	magicVHDX  = []byte("vhdxfile")
+	magicTar   = []byte("ustar")
+	magicISO   = []byte("CD001")
+
+	// the text of the VMDK descriptor file of the disks split into several extent files
+	magicVMDKDescriptor = []byte("# Disk DescriptorFile")
+)
+
+const (
+	offsetTarMagic = 257
+	offsetISOMagic = 0x8001
+	offsetVDIMagic = 0x40
+	magicVDI       = 0xbeda107f
+)
+
+// Image is the disk stream of the image, it's decompressed and extracted from the OVA archive.
+type Image struct {
+	io.Reader
+
+	// Format is the detected format of the image, it's ova for the disk extracted from the OVA archive
+	Format harvesterv1.VirtualMachineImageFormat
+	// DiskFormat is the format of the disk stream
+	DiskFormat  harvesterv1.VirtualMachineImageFormat
+	Compression harvesterv1.VirtualMachineImageCompression
+	// DiskName is the name of the disk extracted from the OVA archive
+	DiskName string
+	// Size is the size of the disk stream, it's negative if unknown, e.g. the decompressed size
+	Size int64
+	// VirtualSize is the size of the virtual disk read from the header, it's 0 if unknown
+	VirtualSize int64
+
+	closers []io.Closer
+}
+
+// Open detects the compression and format of the image stream of the size, the size is negative if unknown.
+// The returned image reads the disk, which is decompressed and the first disk extracted from OVA archives.
+func Open(r io.Reader, size int64) (*Image, error) {
+	img := &Image{Size: size}
+	stream := bufio.NewReaderSize(r, ProbeSize)
+	header, err := peek(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	img.Compression = detectCompression(header)
+	if img.Compression != "" {
+		decompressed, err := img.decompress(stream)
+		if err != nil {
+			img.Close()
+			return nil, fmt.Errorf("failed to decompress the %s image: %w", img.Compression, err)
+		}
+		img.Size = -1
+		stream = bufio.NewReaderSize(decompressed, ProbeSize)
+		if header, err = peek(stream); err != nil {
+			img.Close()
+			return nil, err
+		}
+	}
+
+	img.Format = detect(header)
+	img.DiskFormat = img.Format
+	if img.Format == harvesterv1.VirtualMachineImageFormatOVA {
+		disk, err := img.extractOVADisk(stream)
+		if err != nil {
+			img.Close()
+			return nil, err
+		}
+		stream = bufio.NewReaderSize(disk, ProbeSize)
+		if header, err = peek(stream); err != nil {
+			img.Close()
+			return nil, err
+		}
+		img.DiskFormat = detect(header)
+	}
+
+	img.Reader = stream
+	img.VirtualSize = virtualSize(img.DiskFormat, header)
+	if img.VirtualSize < 0 || img.VirtualSize > MaxVirtualSize {
+		img.Close()
+		return nil, fmt.Errorf("%w: the virtual size %d of the %s disk exceeds %d", ErrUnsupported, uint64(img.VirtualSize), img.DiskFormat, MaxVirtualSize) //nolint:gosec
+	}
+	return img, nil
+}
+
+// Probe detects the image of the header read from r, e.g. the prefix of the downloaded image. The returned image
+// only has the detected attributes without the disk stream.
+func Probe(r io.Reader) (*Image, error) {
+	img, err := Open(io.LimitReader(r, ProbeSize), -1)
+	if err != nil {
+		return nil, err
+	}
+	img.Close()
+	img.Reader = nil
+	return img, nil
+}
+
+// Close closes the decompressors of the image, the source stream is closed by the caller.
+func (img *Image) Close() {
+	for _, c := range img.closers {
+		c.Close()
+	}
+}
+
+// peek returns the header of the stream, the header of truncated streams is the content read so far, so the
+// prefix of images downloaded to probe the format can be detected.
+func peek(r *bufio.Reader) ([]byte, error) {
+	header, err := r.Peek(ProbeSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("failed to read the image header: %w", err)
+	}
+	return header, nil
+}
+
+func (img *Image) decompress(r io.Reader) (io.Reader, error) {
+	switch img.Compression {
+	case harvesterv1.VirtualMachineImageCompressionGzip:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		img.closers = append(img.closers, gz)
+		return gz, nil
+	case harvesterv1.VirtualMachineImageCompressionXZ:
+		return xz.NewReader(r)
+	case harvesterv1.VirtualMachineImageCompressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		rc := zr.IOReadCloser()
+		img.closers = append(img.closers, rc)
+		return rc, nil
+	}
+	return nil, fmt.Errorf("%w: compression %s", ErrUnsupported, img.Compression)
+}
+
+// extractOVADisk returns the first disk of the OVA archive, the OVF descriptor, manifest and certificate
+// preceding it are skipped.
+func (img *Image) extractOVADisk(r io.Reader) (io.Reader, error) {
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: no disk in the OVA archive", ErrUnsupported)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the OVA archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		switch strings.ToLower(path.Ext(header.Name)) {
+		case ".ovf", ".mf", ".cert", ".nvram":
+			continue
+		}
+		img.DiskName = path.Base(header.Name)
+		img.Size = header.Size
+		return archive, nil
+	}
+}
+
+func detectCompression(header []byte) harvesterv1.VirtualMachineImageCompression {
+	switch {
+	case bytes.HasPrefix(header, magicGzip):
+		return harvesterv1.VirtualMachineImageCompressionGzip
+	case bytes.HasPrefix(header, magicXZ):
+		return harvesterv1.VirtualMachineImageCompressionXZ
+	case bytes.HasPrefix(header, magicZstd):
+		return harvesterv1.VirtualMachineImageCompressionZstd
+	}
+	return ""
+}
+
+// detect returns the format of the disk header, disks without a known header are raw. The fixed VHD only has
+// the footer at the end, it's detected as raw, which is the same disk followed by the 512 bytes footer.
+func detect(header []byte) harvesterv1.VirtualMachineImageFormat {
+	switch {
+	case bytes.HasPrefix(header, magicQCOW2):
+		return harvesterv1.VirtualMachineImageFormatQCOW2
+	case bytes.HasPrefix(header, magicVMDK), bytes.HasPrefix(header, magicVMDKDescriptor):
+		return harvesterv1.VirtualMachineImageFormatVMDK
+	case bytes.HasPrefix(header, magicVHD):
+		return harvesterv1.VirtualMachineImageFormatVHD
+	case bytes.HasPrefix(header, magicVHDX):
+		return harvesterv1.VirtualMachineImageFormatVHDX
+	case len(header) >= offsetVDIMagic+4 && binary.LittleEndian.Uint32(header[offsetVDIMagic:]) == magicVDI:
+		return harvesterv1.VirtualMachineImageFormatVDI
+	case len(header) >= offsetTarMagic+len(magicTar) && bytes.Equal(header[offsetTarMagic:offsetTarMagic+len(magicTar)], magicTar):
+		return harvesterv1.VirtualMachineImageFormatOVA
+	case len(header) >= offsetISOMagic+len(magicISO) && bytes.Equal(header[offsetISOMagic:offsetISOMagic+len(magicISO)], magicISO):
+		return harvesterv1.VirtualMachineImageFormatISO
+	}
+	return harvesterv1.VirtualMachineImageFormatRaw
+}
+
+// virtualSize returns the size of the virtual disk in the header, it's 0 if the header doesn't have it and negative
+// if it overflows int64
+func virtualSize(format harvesterv1.VirtualMachineImageFormat, header []byte) int64 {
+	var size uint64
+	switch format {
+	case harvesterv1.VirtualMachineImageFormatQCOW2:
+		if len(header) >= 32 {
+			size = binary.BigEndian.Uint64(header[24:])
+		}
+	case harvesterv1.VirtualMachineImageFormatVMDK:
+		if h, err := parseVMDKHeader(header); err == nil {
+			// the capacity overflowing the size is rejected as too large
+			size = math.MaxUint64
+			if h.capacity <= MaxVirtualSize/sectorSize {
+				size = h.capacity * sectorSize
+			}
+		}
+	case harvesterv1.VirtualMachineImageFormatVHD:
+		if len(header) >= 56 {
+			size = binary.BigEndian.Uint64(header[48:])
+		}
+	case harvesterv1.VirtualMachineImageFormatVHDX:
+		if h, err := parseVHDXHeader(header); err == nil {
+			size = h.virtualSize
+		}
+	case harvesterv1.VirtualMachineImageFormatVDI:
+		if h, err := parseVDIHeader(header); err == nil {
+			size = h.diskSize
+		}
+	case harvesterv1.VirtualMachineImageFormatISO:
+		// the volume space size and the logical block size of the primary volume descriptor
+		if len(header) >= 0x8082 {
+			size = uint64(binary.LittleEndian.Uint32(header[0x8050:])) * uint64(binary.LittleEndian.Uint16(header[0x8080:]))
+		}
+	}
+	return int64(size) //nolint:gosec
+}
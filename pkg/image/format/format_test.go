@@ -0,0 +1,234 @@
+package format
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func TestOpen(t *testing.T) {
+	qcow2 := buildQCOW2(t, pattern(512, 'a'), pattern(512, 'b'), pattern(512, 'c'), false)
+	vmdk := buildStreamOptimizedVMDK(t, pattern(4<<10, 'a'), pattern(4<<10, 'b'))
+	iso := image{}
+	iso.write(offsetISOMagic, magicISO)
+	iso.putUint32(0x8050, binary.LittleEndian, 20)
+	iso.write(0x8080, []byte{0x00, 0x08})
+	iso.write(20*2048-1, []byte{0})
+
+	compress := func(compression harvesterv1.VirtualMachineImageCompression, data []byte) []byte {
+		buf := &bytes.Buffer{}
+		var w io.WriteCloser
+		var err error
+		switch compression {
+		case harvesterv1.VirtualMachineImageCompressionGzip:
+			w = gzip.NewWriter(buf)
+		case harvesterv1.VirtualMachineImageCompressionXZ:
+			w, err = xz.NewWriter(buf)
+		case harvesterv1.VirtualMachineImageCompressionZstd:
+			w, err = zstd.NewWriter(buf)
+		}
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	ova := func(disk []byte) []byte {
+		buf := &bytes.Buffer{}
+		archive := tar.NewWriter(buf)
+		for _, file := range []struct {
+			name string
+			data []byte
+		}{
+			{"vm.ovf", []byte("<Envelope/>")},
+			{"vm.mf", []byte("SHA256(vm-disk1.vmdk)= 0")},
+			{"vm-disk1.vmdk", disk},
+		} {
+			require.NoError(t, archive.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.data)), Format: tar.FormatUSTAR}))
+			_, err := archive.Write(file.data)
+			require.NoError(t, err)
+		}
+		require.NoError(t, archive.Close())
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name        string
+		image       []byte
+		disk        []byte
+		format      harvesterv1.VirtualMachineImageFormat
+		diskFormat  harvesterv1.VirtualMachineImageFormat
+		compression harvesterv1.VirtualMachineImageCompression
+		diskName    string
+		size        int64
+		virtualSize int64
+	}{
+		{
+			name:        "qcow2",
+			image:       qcow2,
+			format:      harvesterv1.VirtualMachineImageFormatQCOW2,
+			size:        int64(len(qcow2)),
+			virtualSize: 64 << 10,
+		},
+		{
+			name:        "raw",
+			image:       pattern(4096, 'r'),
+			format:      harvesterv1.VirtualMachineImageFormatRaw,
+			size:        4096,
+			virtualSize: 0,
+		},
+		{
+			name:        "iso",
+			image:       iso,
+			format:      harvesterv1.VirtualMachineImageFormatISO,
+			size:        int64(len(iso)),
+			virtualSize: 20 * 2048,
+		},
+		{
+			name:        "vmdk",
+			image:       vmdk,
+			format:      harvesterv1.VirtualMachineImageFormatVMDK,
+			size:        int64(len(vmdk)),
+			virtualSize: 64 << 10,
+		},
+		{
+			name:        "vhd",
+			image:       buildVHD(pattern(16<<10, 'a'), pattern(16<<10, 'b')),
+			format:      harvesterv1.VirtualMachineImageFormatVHD,
+			size:        -1,
+			virtualSize: 64 << 10,
+		},
+		{
+			name:        "vhdx",
+			image:       buildVHDX(pattern(1<<20, 'a'), pattern(1<<20, 'b')),
+			format:      harvesterv1.VirtualMachineImageFormatVHDX,
+			size:        -1,
+			virtualSize: 3 << 20,
+		},
+		{
+			name:        "vdi",
+			image:       buildVDI(pattern(16<<10, 'a'), pattern(16<<10, 'b')),
+			format:      harvesterv1.VirtualMachineImageFormatVDI,
+			size:        -1,
+			virtualSize: 64 << 10,
+		},
+		{
+			name:        "gzip compressed qcow2",
+			image:       compress(harvesterv1.VirtualMachineImageCompressionGzip, qcow2),
+			disk:        qcow2,
+			format:      harvesterv1.VirtualMachineImageFormatQCOW2,
+			compression: harvesterv1.VirtualMachineImageCompressionGzip,
+			size:        -1,
+			virtualSize: 64 << 10,
+		},
+		{
+			name:        "xz compressed qcow2",
+			image:       compress(harvesterv1.VirtualMachineImageCompressionXZ, qcow2),
+			disk:        qcow2,
+			format:      harvesterv1.VirtualMachineImageFormatQCOW2,
+			compression: harvesterv1.VirtualMachineImageCompressionXZ,
+			size:        -1,
+			virtualSize: 64 << 10,
+		},
+		{
+			name:        "zstd compressed qcow2",
+			image:       compress(harvesterv1.VirtualMachineImageCompressionZstd, qcow2),
+			disk:        qcow2,
+			format:      harvesterv1.VirtualMachineImageFormatQCOW2,
+			compression: harvesterv1.VirtualMachineImageCompressionZstd,
+			size:        -1,
+			virtualSize: 64 << 10,
+		},
+		{
+			name:        "ova",
+			image:       ova(vmdk),
+			disk:        vmdk,
+			format:      harvesterv1.VirtualMachineImageFormatOVA,
+			diskFormat:  harvesterv1.VirtualMachineImageFormatVMDK,
+			diskName:    "vm-disk1.vmdk",
+			size:        int64(len(vmdk)),
+			virtualSize: 64 << 10,
+		},
+		{
+			name:        "gzip compressed ova",
+			image:       compress(harvesterv1.VirtualMachineImageCompressionGzip, ova(vmdk)),
+			disk:        vmdk,
+			format:      harvesterv1.VirtualMachineImageFormatOVA,
+			diskFormat:  harvesterv1.VirtualMachineImageFormatVMDK,
+			compression: harvesterv1.VirtualMachineImageCompressionGzip,
+			diskName:    "vm-disk1.vmdk",
+			size:        int64(len(vmdk)),
+			virtualSize: 64 << 10,
+		},
+	}
+
+	for _, tc := range tests {
+		size := tc.size
+		if size == -1 && tc.compression == "" {
+			size = int64(len(tc.image))
+		}
+		img, err := Open(bytes.NewReader(tc.image), int64(len(tc.image)))
+		require.NoError(t, err, tc.name)
+
+		diskFormat := tc.diskFormat
+		if diskFormat == "" {
+			diskFormat = tc.format
+		}
+		assert.Equal(t, tc.format, img.Format, tc.name)
+		assert.Equal(t, diskFormat, img.DiskFormat, tc.name)
+		assert.Equal(t, tc.compression, img.Compression, tc.name)
+		assert.Equal(t, tc.diskName, img.DiskName, tc.name)
+		assert.Equal(t, size, img.Size, tc.name)
+		assert.Equal(t, tc.virtualSize, img.VirtualSize, tc.name)
+
+		disk, err := io.ReadAll(img)
+		assert.NoError(t, err, tc.name)
+		if tc.disk == nil {
+			tc.disk = tc.image
+		}
+		assert.True(t, bytes.Equal(tc.disk, disk), tc.name)
+		img.Close()
+	}
+}
+
+func TestOpenVirtualSizeLimit(t *testing.T) {
+	qcow2 := buildQCOW2(t, pattern(512, 'a'), pattern(512, 'b'), pattern(512, 'c'), false)
+	qcow2.putUint64(24, binary.BigEndian, MaxVirtualSize+1)
+	_, err := Open(bytes.NewReader(qcow2), int64(len(qcow2)))
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	// the capacity of the vmdk overflows the size in bytes
+	vmdk := buildStreamOptimizedVMDK(t, pattern(4<<10, 'a'), pattern(4<<10, 'b'))
+	vmdk.putUint64(12, binary.LittleEndian, 1<<62)
+	_, err = Open(bytes.NewReader(vmdk), int64(len(vmdk)))
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestProbe(t *testing.T) {
+	// the header of the truncated compressed image is detected
+	qcow2 := buildQCOW2(t, pattern(512, 'a'), pattern(512, 'b'), pattern(512, 'c'), false)
+	qcow2.write(16<<20, []byte{0})
+	buf := &bytes.Buffer{}
+	w, err := zstd.NewWriter(buf)
+	require.NoError(t, err)
+	_, err = w.Write(append(qcow2, pattern(ProbeSize, 'z')...))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	img, err := Probe(bytes.NewReader(buf.Bytes()[:buf.Len()/2]))
+	require.NoError(t, err)
+	assert.Equal(t, harvesterv1.VirtualMachineImageFormatQCOW2, img.Format)
+	assert.Equal(t, harvesterv1.VirtualMachineImageCompressionZstd, img.Compression)
+	assert.Equal(t, int64(64<<10), img.VirtualSize)
+	assert.Nil(t, img.Reader)
+}
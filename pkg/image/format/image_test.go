@@ -0,0 +1,229 @@
+package format
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// the values of the image tables written by the builders
+const (
+	qcow2CompressedFlag = 1 << 62
+	qcow2ZeroFlag       = 1
+
+	vmdkFlagCompressed = 1 << 16
+	vmdkFlagMarkers    = 1 << 17
+
+	vhdDiskTypeDynamic  = 3
+	vhdBlockUnallocated = 0xffffffff
+
+	vdiBlockDiscarded = 0xfffffffe
+
+	vhdxBlockFullyPresent = 6
+)
+
+func pattern(size int, seed byte) []byte {
+	buf := make([]byte, size)
+	for i := range buf {
+		buf[i] = seed + byte(i%7)
+	}
+	return buf
+}
+
+// image is the builder of the test images, the data is written at the offsets
+type image []byte
+
+func (img *image) write(offset int, data []byte) {
+	if end := offset + len(data); end > len(*img) {
+		*img = append(*img, make([]byte, end-len(*img))...)
+	}
+	copy((*img)[offset:], data)
+}
+
+func (img *image) putUint32(offset int, order binary.ByteOrder, v uint32) {
+	buf := make([]byte, 4)
+	order.PutUint32(buf, v)
+	img.write(offset, buf)
+}
+
+func (img *image) putUint64(offset int, order binary.ByteOrder, v uint64) {
+	buf := make([]byte, 8)
+	order.PutUint64(buf, v)
+	img.write(offset, buf)
+}
+
+func deflate(t *testing.T, data []byte) []byte {
+	buf := &bytes.Buffer{}
+	w, err := flate.NewWriter(buf, flate.BestCompression)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func zlibCompress(t *testing.T, data []byte) []byte {
+	buf := &bytes.Buffer{}
+	w := zlib.NewWriter(buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// buildQCOW2 builds the image of 512 bytes clusters, the L2 tables precede the clusters they locate unless the
+// clusters are swapped.
+func buildQCOW2(t *testing.T, a, b, c []byte, swapped bool) image {
+	img := image{}
+	img.write(0, magicQCOW2)
+	img.putUint32(4, binary.BigEndian, 3)
+	img.putUint32(20, binary.BigEndian, 9)
+	img.putUint64(24, binary.BigEndian, 64<<10)
+	img.putUint32(36, binary.BigEndian, 2)
+	img.putUint64(40, binary.BigEndian, 512)
+	img.putUint32(100, binary.BigEndian, 104)
+
+	// L1 at cluster 1, the L2 tables at cluster 2 and 5
+	img.putUint64(512, binary.BigEndian, 2*512)
+	img.putUint64(520, binary.BigEndian, 5*512)
+	aCluster, bCluster := uint64(3), uint64(4)
+	if swapped {
+		aCluster, bCluster = bCluster, aCluster
+	}
+	img.putUint64(2*512, binary.BigEndian, aCluster*512)
+	img.write(int(aCluster)*512, a)
+
+	// the compressed cluster of b
+	img.putUint64(2*512+8, binary.BigEndian, qcow2CompressedFlag|bCluster*512)
+	img.write(int(bCluster)*512, deflate(t, b))
+
+	// the zero cluster at 64 and the cluster of c at 67
+	img.putUint64(5*512, binary.BigEndian, qcow2ZeroFlag)
+	img.putUint64(5*512+3*8, binary.BigEndian, 6*512)
+	img.write(6*512, c)
+	return img
+}
+
+func buildStreamOptimizedVMDK(t *testing.T, a, b []byte) image {
+	img := image{}
+	img.write(0, magicVMDK)
+	img.putUint32(4, binary.LittleEndian, 3)
+	img.putUint32(8, binary.LittleEndian, vmdkFlagCompressed|vmdkFlagMarkers|1)
+	img.putUint64(12, binary.LittleEndian, 128)
+	img.putUint64(20, binary.LittleEndian, 8)
+	img.putUint32(44, binary.LittleEndian, 512)
+	img.putUint64(56, binary.LittleEndian, 1<<64-1)
+	img.putUint64(64, binary.LittleEndian, 1)
+
+	offset := 512
+	for _, grain := range []struct {
+		lba  uint64
+		data []byte
+	}{{0, a}, {16, b}} {
+		compressed := zlibCompress(t, grain.data)
+		img.putUint64(offset, binary.LittleEndian, grain.lba)
+		img.putUint32(offset+8, binary.LittleEndian, uint32(len(compressed))) //nolint:gosec
+		img.write(offset+12, compressed)
+		offset += (12 + len(compressed) + 511) / 512 * 512
+	}
+
+	// the grain table marker of one sector followed by the end-of-stream marker
+	img.putUint64(offset, binary.LittleEndian, 1)
+	img.putUint32(offset+12, binary.LittleEndian, 1)
+	img.write(offset+512, pattern(512, 0xee))
+	img.write(offset+1024, make([]byte, 512))
+	return img
+}
+
+func buildVHD(a, b []byte) image {
+	footer := image{}
+	footer.write(0, magicVHD)
+	footer.putUint64(16, binary.BigEndian, 512)
+	footer.putUint64(48, binary.BigEndian, 64<<10)
+	footer.putUint32(60, binary.BigEndian, vhdDiskTypeDynamic)
+	footer.write(511, []byte{0})
+
+	img := image{}
+	img.write(0, footer)
+	img.write(512, []byte("cxsparse"))
+	img.putUint64(512+16, binary.BigEndian, 1536)
+	img.putUint32(512+28, binary.BigEndian, 4)
+	img.putUint32(512+32, binary.BigEndian, 16<<10)
+
+	// the block 0 at sector 4 has all sectors, the block 2 at sector 37 only has the first one
+	for i, sector := range []uint32{4, vhdBlockUnallocated, 37, vhdBlockUnallocated} {
+		img.putUint32(1536+i*4, binary.BigEndian, sector)
+	}
+	img.write(4*512, []byte{0xff, 0xff, 0xff, 0xff})
+	img.write(5*512, a)
+	img.write(37*512, []byte{0x80})
+	img.write(38*512, b)
+	img.write(len(img), footer)
+	return img
+}
+
+func buildVDI(a, b []byte) image {
+	img := image{}
+	img.write(0, []byte("<<< Oracle VM VirtualBox Disk Image >>>\n"))
+	img.putUint32(offsetVDIMagic, binary.LittleEndian, magicVDI)
+	img.putUint32(0x44, binary.LittleEndian, 0x00010001)
+	img.putUint32(0x154, binary.LittleEndian, 512)
+	img.putUint32(0x158, binary.LittleEndian, 1024)
+	img.putUint64(0x170, binary.LittleEndian, 64<<10)
+	img.putUint32(0x178, binary.LittleEndian, 16<<10)
+	img.putUint32(0x180, binary.LittleEndian, 4)
+
+	for i, index := range []uint32{0, 0xffffffff, 1, vdiBlockDiscarded} {
+		img.putUint32(512+i*4, binary.LittleEndian, index)
+	}
+	img.write(1024, a)
+	img.write(1024+16<<10, b)
+	return img
+}
+
+func buildVHDX(a, b []byte) image {
+	checksum := func(img *image, offset, size int) {
+		img.putUint32(offset+4, binary.LittleEndian, crc32.Checksum((*img)[offset:offset+size], crc32c))
+	}
+
+	img := image{}
+	img.write(0, magicVHDX)
+	img.write(64<<10, []byte("head"))
+	img.putUint64(64<<10+8, binary.LittleEndian, 1)
+	img.write(64<<10+vhdxHeaderSize-1, []byte{0})
+	checksum(&img, 64<<10, vhdxHeaderSize)
+
+	// the metadata region at 1 MiB and the block allocation table at 2 MiB
+	img.write(192<<10, []byte("regi"))
+	img.putUint32(192<<10+8, binary.LittleEndian, 2)
+	img.write(192<<10+16, vhdxRegionBAT)
+	img.putUint64(192<<10+32, binary.LittleEndian, 2<<20)
+	img.putUint32(192<<10+40, binary.LittleEndian, 1<<20)
+	img.write(192<<10+48, vhdxRegionMetadata)
+	img.putUint64(192<<10+64, binary.LittleEndian, 1<<20)
+	img.putUint32(192<<10+72, binary.LittleEndian, 1<<20)
+	img.write(192<<10+vhdxRegionTableSize-1, []byte{0})
+	checksum(&img, 192<<10, vhdxRegionTableSize)
+
+	metadata := 1 << 20
+	img.write(metadata, []byte("metadata"))
+	img.write(metadata+10, []byte{3, 0})
+	for i, item := range [][]byte{vhdxMetadataFileParams, vhdxMetadataVirtualSize, vhdxMetadataLogicalSector} {
+		img.write(metadata+32+i*32, item)
+		img.putUint32(metadata+32+i*32+16, binary.LittleEndian, uint32(64<<10+i*8)) //nolint:gosec
+	}
+	img.putUint32(metadata+64<<10, binary.LittleEndian, 1<<20)
+	img.putUint64(metadata+64<<10+8, binary.LittleEndian, 3<<20)
+	img.putUint32(metadata+64<<10+16, binary.LittleEndian, 512)
+
+	img.putUint64(2<<20, binary.LittleEndian, 3<<20|vhdxBlockFullyPresent)
+	img.putUint64(2<<20+16, binary.LittleEndian, 4<<20|vhdxBlockFullyPresent)
+	img.write(3<<20, a)
+	img.write(4<<20, b)
+	return img
+}
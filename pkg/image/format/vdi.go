@@ -0,0 +1,27 @@
+package format
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const vdiHeaderSize = 0x190
+
+type vdiHeader struct {
+	diskSize  uint64
+	blockSize uint32
+}
+
+func parseVDIHeader(header []byte) (*vdiHeader, error) {
+	if len(header) < vdiHeaderSize || binary.LittleEndian.Uint32(header[offsetVDIMagic:]) != magicVDI {
+		return nil, fmt.Errorf("%w: invalid vdi header", ErrUnsupported)
+	}
+	h := &vdiHeader{
+		diskSize:  binary.LittleEndian.Uint64(header[0x170:]),
+		blockSize: binary.LittleEndian.Uint32(header[0x178:]),
+	}
+	if h.blockSize == 0 || h.blockSize > 1<<30 {
+		return nil, fmt.Errorf("%w: vdi block size %d", ErrUnsupported, h.blockSize)
+	}
+	return h, nil
+}
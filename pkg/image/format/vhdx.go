@@ -0,0 +1,135 @@
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+const (
+	vhdxHeaderSectionSize = 1 << 20
+	vhdxHeaderSize        = 4 << 10
+	vhdxRegionTableSize   = 64 << 10
+	vhdxMaxTableSize      = 256 << 20
+	vhdxFileHasParent     = 1 << 1
+)
+
+var (
+	// the GUIDs of the regions and metadata items in the on-disk byte order
+	vhdxRegionBAT             = []byte{0x66, 0x77, 0xc2, 0x2d, 0x23, 0xf6, 0x00, 0x42, 0x9d, 0x64, 0x11, 0x5e, 0x9b, 0xfd, 0x4a, 0x08}
+	vhdxRegionMetadata        = []byte{0x06, 0xa2, 0x7c, 0x8b, 0x90, 0x47, 0x9a, 0x4b, 0xb8, 0xfe, 0x57, 0x5f, 0x05, 0x0f, 0x88, 0x6e}
+	vhdxMetadataFileParams    = []byte{0x37, 0x67, 0xa1, 0xca, 0x36, 0xfa, 0x43, 0x4d, 0xb3, 0xb6, 0x33, 0xf0, 0xaa, 0x44, 0xe7, 0x6b}
+	vhdxMetadataVirtualSize   = []byte{0x24, 0x42, 0xa5, 0x2f, 0x1b, 0xcd, 0x76, 0x48, 0xb2, 0x11, 0x5d, 0xbe, 0xd8, 0x3b, 0xf4, 0xb8}
+	vhdxMetadataLogicalSector = []byte{0x1d, 0xbf, 0x41, 0x81, 0x6f, 0xa9, 0x09, 0x47, 0xba, 0x47, 0xf2, 0x33, 0xa8, 0xfa, 0xab, 0x5f}
+
+	crc32c = crc32.MakeTable(crc32.Castagnoli)
+)
+
+type vhdxRegion struct {
+	offset int64
+	length int64
+}
+
+type vhdxHeader struct {
+	bat               vhdxRegion
+	metadata          vhdxRegion
+	virtualSize       uint64
+	blockSize         uint32
+	logicalSectorSize uint32
+}
+
+// parseVHDXHeader parses the regions of the header section, and the metadata if the header covers it
+func parseVHDXHeader(header []byte) (*vhdxHeader, error) {
+	if len(header) < vhdxHeaderSectionSize || !bytes.HasPrefix(header, magicVHDX) {
+		return nil, fmt.Errorf("%w: invalid vhdx header", ErrUnsupported)
+	}
+
+	// the current header is the valid one with the greater sequence number
+	var current []byte
+	for _, offset := range []int{64 << 10, 128 << 10} {
+		h := header[offset : offset+vhdxHeaderSize]
+		if !bytes.HasPrefix(h, []byte("head")) || !vhdxChecksumValid(h) {
+			continue
+		}
+		if current == nil || binary.LittleEndian.Uint64(h[8:]) > binary.LittleEndian.Uint64(current[8:]) {
+			current = h
+		}
+	}
+	if current == nil {
+		return nil, fmt.Errorf("%w: no valid vhdx header", ErrUnsupported)
+	}
+	if !bytes.Equal(current[48:64], make([]byte, 16)) {
+		return nil, fmt.Errorf("%w: the vhdx log isn't replayed, the disk wasn't closed cleanly", ErrUnsupported)
+	}
+
+	h := &vhdxHeader{}
+	for _, offset := range []int{192 << 10, 256 << 10} {
+		table := header[offset : offset+vhdxRegionTableSize]
+		if !bytes.HasPrefix(table, []byte("regi")) || !vhdxChecksumValid(table) {
+			continue
+		}
+		count := min(int(binary.LittleEndian.Uint32(table[8:])), (vhdxRegionTableSize-16)/32)
+		for i := 0; i < count; i++ {
+			entry := table[16+i*32 : 16+(i+1)*32]
+			region := vhdxRegion{
+				offset: int64(binary.LittleEndian.Uint64(entry[16:])), //nolint:gosec
+				length: int64(binary.LittleEndian.Uint32(entry[24:])),
+			}
+			switch {
+			case bytes.Equal(entry[:16], vhdxRegionBAT):
+				h.bat = region
+			case bytes.Equal(entry[:16], vhdxRegionMetadata):
+				h.metadata = region
+			}
+		}
+		break
+	}
+	if h.bat.length == 0 || h.metadata.length == 0 || h.bat.length > vhdxMaxTableSize || h.metadata.length > vhdxMaxTableSize {
+		return nil, fmt.Errorf("%w: invalid vhdx region table", ErrUnsupported)
+	}
+
+	if end := h.metadata.offset + h.metadata.length; end <= int64(len(header)) {
+		if err := h.parseMetadata(header[h.metadata.offset:end]); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *vhdxHeader) parseMetadata(metadata []byte) error {
+	if len(metadata) < 32 || !bytes.HasPrefix(metadata, []byte("metadata")) {
+		return fmt.Errorf("%w: invalid vhdx metadata", ErrUnsupported)
+	}
+	count := int(binary.LittleEndian.Uint16(metadata[10:]))
+	for i := 0; i < count && 32+(i+1)*32 <= len(metadata); i++ {
+		entry := metadata[32+i*32 : 32+(i+1)*32]
+		offset := int(binary.LittleEndian.Uint32(entry[16:]))
+		if offset+8 > len(metadata) {
+			return fmt.Errorf("%w: invalid vhdx metadata", ErrUnsupported)
+		}
+		item := metadata[offset:]
+		switch {
+		case bytes.Equal(entry[:16], vhdxMetadataFileParams):
+			if binary.LittleEndian.Uint32(item[4:])&vhdxFileHasParent != 0 {
+				return fmt.Errorf("%w: differencing vhdx", ErrUnsupported)
+			}
+			h.blockSize = binary.LittleEndian.Uint32(item)
+		case bytes.Equal(entry[:16], vhdxMetadataVirtualSize):
+			h.virtualSize = binary.LittleEndian.Uint64(item)
+		case bytes.Equal(entry[:16], vhdxMetadataLogicalSector):
+			h.logicalSectorSize = binary.LittleEndian.Uint32(item)
+		}
+	}
+	if h.blockSize == 0 || h.logicalSectorSize == 0 || h.virtualSize == 0 {
+		return fmt.Errorf("%w: incomplete vhdx metadata", ErrUnsupported)
+	}
+	return nil
+}
+
+func vhdxChecksumValid(buf []byte) bool {
+	checksum := binary.LittleEndian.Uint32(buf[4:])
+	data := bytes.Clone(buf)
+	binary.LittleEndian.PutUint32(data[4:], 0)
+	return crc32.Checksum(data, crc32c) == checksum
+}
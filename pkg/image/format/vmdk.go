@@ -0,0 +1,30 @@
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+type vmdkHeader struct {
+	capacity     uint64
+	grainSize    uint64
+	numGTEsPerGT uint32
+}
+
+// parseVMDKHeader parses the header of the hosted sparse extent, the capacity and grain size are in sectors.
+// The descriptor files of the disks split into several extent files have no header.
+func parseVMDKHeader(header []byte) (*vmdkHeader, error) {
+	if len(header) < sectorSize || !bytes.HasPrefix(header, magicVMDK) {
+		return nil, fmt.Errorf("%w: vmdk disk without sparse extent", ErrUnsupported)
+	}
+	h := &vmdkHeader{
+		capacity:     binary.LittleEndian.Uint64(header[12:]),
+		grainSize:    binary.LittleEndian.Uint64(header[20:]),
+		numGTEsPerGT: binary.LittleEndian.Uint32(header[44:]),
+	}
+	if h.grainSize == 0 || h.grainSize > 1<<20 || h.numGTEsPerGT == 0 {
+		return nil, fmt.Errorf("%w: vmdk grain size %d", ErrUnsupported, h.grainSize)
+	}
+	return h, nil
+}
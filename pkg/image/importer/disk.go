@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/harvester/harvester/pkg/image/format"
+)
+
+// WriteDisk writes the disk of the image read from r to the path, the disk is decompressed and extracted from the
+// OVA archive. The format detected by harvester is checked again, and the SHA-512 checksum of the image is verified
+// if it's not empty, so the file is only written if it's the image harvester detected.
+func WriteDisk(source *Source, r io.Reader, size int64, diskPath string) error {
+	disk, img, err := openDisk(r, size, source.Checksum)
+	if err != nil {
+		return err
+	}
+	defer disk.Close()
+	if img.DiskFormat != source.DiskFormat || img.Compression != source.Compression {
+		return fmt.Errorf("%w: the image changed to a %s %s disk since it was detected as a %s %s disk", format.ErrUnsupported,
+			img.Compression, img.DiskFormat, source.Compression, source.DiskFormat)
+	}
+
+	file, err := os.Create(diskPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, disk); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write the disk: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// openDisk detects the format of the image, the returned reader reads the disk which is decompressed and extracted
+// from the OVA archive. The SHA-512 checksum of the image is verified at the end of the disk if it's not empty.
+func openDisk(r io.Reader, size int64, checksum string) (*checkedDisk, *format.Image, error) {
+	disk := &checkedDisk{source: r, checksum: checksum, hash: sha512.New()}
+	img, err := format.Open(io.TeeReader(r, disk.hash), size)
+	if err != nil {
+		return nil, nil, err
+	}
+	disk.img = img
+	return disk, img, nil
+}
+
+// checkedDisk reads the disk extracted from the image. The rest of the image not read with the disk, e.g. the
+// OVF files following the disk, is drained at the end of the disk to verify the checksum, so the disk isn't
+// completed if the checksum mismatches.
+type checkedDisk struct {
+	img      *format.Image
+	source   io.Reader
+	checksum string
+	hash     hash.Hash
+}
+
+func (d *checkedDisk) Read(p []byte) (int, error) {
+	n, err := d.img.Read(p)
+	if !errors.Is(err, io.EOF) || d.checksum == "" {
+		return n, err
+	}
+	if _, err := io.Copy(d.hash, d.source); err != nil {
+		return n, fmt.Errorf("failed to read the image: %w", err)
+	}
+	if actual := hex.EncodeToString(d.hash.Sum(nil)); actual != d.checksum {
+		return n, fmt.Errorf("the checksum %s of the image doesn't match the expected %s", actual, d.checksum)
+	}
+	return n, io.EOF
+}
+
+func (d *checkedDisk) Close() error {
+	d.img.Close()
+	return nil
+}
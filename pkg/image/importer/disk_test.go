@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func TestWriteDisk(t *testing.T) {
+	disk := bytes.Repeat([]byte("harvester"), 1024)
+	sum := sha512.Sum512(disk)
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name       string
+		checksum   string
+		diskFormat harvesterv1.VirtualMachineImageFormat
+		wantErr    bool
+	}{
+		{name: "without checksum"},
+		{name: "matched checksum", checksum: checksum},
+		{name: "mismatched checksum", checksum: checksum[1:] + "0", wantErr: true},
+		{name: "format changed since detected", diskFormat: harvesterv1.VirtualMachineImageFormatVMDK, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			source := &Source{Checksum: tc.checksum, DiskFormat: harvesterv1.VirtualMachineImageFormatRaw}
+			if tc.diskFormat != "" {
+				source.DiskFormat = tc.diskFormat
+			}
+			diskPath := filepath.Join(t.TempDir(), "disk")
+
+			err := WriteDisk(source, bytes.NewReader(disk), int64(len(disk)), diskPath)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			data, err := os.ReadFile(diskPath)
+			require.NoError(t, err)
+			assert.Equal(t, disk, data)
+		})
+	}
+}
+
+func TestWriteDiskDecompressed(t *testing.T) {
+	disk := bytes.Repeat([]byte("harvester"), 1024)
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	_, err := w.Write(disk)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	compressed := buf.Bytes()
+	sum := sha512.Sum512(compressed)
+
+	// the checksum is the one of the compressed image, the size of the decompressed disk is unknown until it's written
+	source := &Source{
+		Checksum:    hex.EncodeToString(sum[:]),
+		DiskFormat:  harvesterv1.VirtualMachineImageFormatRaw,
+		Compression: harvesterv1.VirtualMachineImageCompressionGzip,
+	}
+	diskPath := filepath.Join(t.TempDir(), "disk")
+	require.NoError(t, WriteDisk(source, bytes.NewReader(compressed), int64(len(compressed)), diskPath))
+
+	info, err := os.Stat(diskPath)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(disk)), info.Size())
+}
+
+func TestProbe(t *testing.T) {
+	header := make([]byte, 64)
+	copy(header, "QFI\xfb")
+	header[30] = 0x10 // the virtual size of 4096 bytes at offset 24
+
+	img, err := Probe(bytes.NewReader(header), 1<<20)
+	require.NoError(t, err)
+	assert.Equal(t, harvesterv1.VirtualMachineImageFormatQCOW2, img.DiskFormat)
+	assert.Equal(t, int64(1<<20), img.Size)
+	assert.Equal(t, int64(4096), img.VirtualSize)
+	assert.Nil(t, img.Reader)
+}
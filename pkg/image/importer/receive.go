@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// UploadPath is where harvester sends the uploaded image to the job
+	UploadPath = "/upload"
+
+	receiveShutdownTimeout = 10 * time.Second
+)
+
+// Receive serves the image uploaded by harvester to the job, the upload is authenticated with the token of the
+// source. The image is passed to handle with its size, which is negative if unknown. Only one upload is handled,
+// Receive returns once it's done, the response tells harvester the result of handle.
+func Receive(ctx context.Context, listener net.Listener, token string, handle func(r io.Reader, size int64) error) error {
+	var started atomic.Bool
+	done := make(chan error, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(UploadPath, func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPut {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !started.CompareAndSwap(false, true) {
+			http.Error(rw, "the image is already uploaded", http.StatusConflict)
+			return
+		}
+
+		err := handle(req.Body, req.ContentLength)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+		} else {
+			rw.WriteHeader(http.StatusOK)
+		}
+		done <- err
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 30 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case err = <-serveErr:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// the response of the upload is sent before the server is shut down
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), receiveShutdownTimeout)
+	defer cancel()
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil && !errors.Is(shutdownErr, http.ErrServerClosed) && err == nil {
+		err = shutdownErr
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceive(t *testing.T) {
+	tests := []struct {
+		name       string
+		handleErr  error
+		wantStatus int
+	}{
+		{name: "image is written", wantStatus: http.StatusOK},
+		{name: "image is rejected", handleErr: errors.New("checksum mismatch"), wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			uploadURL := "http://" + listener.Addr().String() + UploadPath
+
+			var received string
+			result := make(chan error, 1)
+			go func() {
+				result <- Receive(context.TODO(), listener, "token", func(r io.Reader, _ int64) error {
+					data, err := io.ReadAll(r)
+					received = string(data)
+					if err != nil {
+						return err
+					}
+					return tc.handleErr
+				})
+			}()
+
+			// the uploads without the token are refused and don't end the receiver
+			resp, err := http.DefaultClient.Do(newUploadRequest(t, uploadURL, "other", "image"))
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+			resp, err = http.DefaultClient.Do(newUploadRequest(t, uploadURL, "token", "image"))
+			require.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, string(body))
+
+			err = <-result
+			assert.Equal(t, tc.handleErr, err)
+			assert.Equal(t, "image", received)
+		})
+	}
+}
+
+func newUploadRequest(t *testing.T, uploadURL, token, image string) *http.Request {
+	req, err := http.NewRequest(http.MethodPut, uploadURL, strings.NewReader(image))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
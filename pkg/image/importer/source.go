@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/image/format"
+	"github.com/harvester/harvester/pkg/util/registry"
+)
+
+// Source is the image imported by the job, it's stored in the secret of the job since it has the credentials
+type Source struct {
+	Type harvesterv1.VirtualMachineImageSourceType `json:"type"`
+	URL  string                                    `json:"url,omitempty"`
+	// Name is the file name of the disk uploaded to the backing image data source
+	Name     string `json:"name"`
+	Checksum string `json:"checksum,omitempty"`
+	// DiskFormat and Compression are detected by harvester from the header of the image, the job fails if the
+	// image it reads is different, e.g. the server sends another image the second time.
+	DiskFormat  harvesterv1.VirtualMachineImageFormat      `json:"diskFormat"`
+	Compression harvesterv1.VirtualMachineImageCompression `json:"compression,omitempty"`
+
+	DownloadCredentials *common.DownloadCredentials `json:"downloadCredentials,omitempty"`
+	RegistryCredentials *registry.Credentials       `json:"registryCredentials,omitempty"`
+	// UploadToken authenticates harvester sending the uploaded image to the job
+	UploadToken string `json:"uploadToken,omitempty"`
+}
+
+// LoadSource reads the source from the file mounted from the secret of the job
+func LoadSource(sourcePath string) (*Source, error) {
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	source := &Source{}
+	if err := json.Unmarshal(data, source); err != nil {
+		return nil, fmt.Errorf("failed to parse the source %s: %w", sourcePath, err)
+	}
+	return source, nil
+}
+
+// Open returns the stream of the downloaded or pulled image and its size
+func Open(ctx context.Context, source *Source) (io.ReadCloser, int64, error) {
+	switch source.Type {
+	case harvesterv1.VirtualMachineImageSourceTypeRegistry:
+		return openRegistry(ctx, source)
+	case harvesterv1.VirtualMachineImageSourceTypeDownload:
+		return openDownload(ctx, source)
+	}
+	return nil, 0, fmt.Errorf("images of source type %s can't be opened", source.Type)
+}
+
+func openRegistry(ctx context.Context, source *Source) (io.ReadCloser, int64, error) {
+	imageRef, err := registry.ParseReference(source.URL)
+	if err != nil {
+		return nil, 0, err
+	}
+	client, err := registry.NewClient(source.RegistryCredentials)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	disk, err := client.OpenDisk(ctx, imageRef)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to pull image %s: %w", imageRef, err)
+	}
+	return disk, disk.Size, nil
+}
+
+func openDownload(ctx context.Context, source *Source) (io.ReadCloser, int64, error) {
+	credentials := source.DownloadCredentials
+	if credentials == nil {
+		credentials = &common.DownloadCredentials{}
+	}
+	client, err := credentials.NewHTTPClient(0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to download image %s: %w", source.URL, err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("got %d status code from %s", resp.StatusCode, source.URL)
+	}
+	if resp.ContentLength <= 0 {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("the size of image %s is unknown", source.URL)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// Probe detects the image from its header, only the header is read from r. The size of the disk is the one of
+// the image if it isn't compressed or archived.
+func Probe(r io.Reader, size int64) (*format.Image, error) {
+	img, err := format.Open(io.LimitReader(r, format.ProbeSize), size)
+	if err != nil {
+		return nil, err
+	}
+	img.Close()
+	img.Reader = nil
+	return img, nil
+}
@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// UploadDisk uploads the disk file to the backing image data source, the size Longhorn needs before the upload
+// starts is the one of the file.
+func UploadDisk(ctx context.Context, client *http.Client, dsName, name, diskPath string) error {
+	file, err := os.Open(diskPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return uploadToDataSource(ctx, client, dsName, name, info.Size(), file)
+}
+
+// uploadToDataSource streams the disk as the chunk of the multipart form like the uploads of the dashboard
+func uploadToDataSource(ctx context.Context, client *http.Client, dsName, name string, size int64, disk io.Reader) error {
+	pipeReader, pipeWriter := io.Pipe()
+	form := multipart.NewWriter(pipeWriter)
+	go func() {
+		part, err := form.CreateFormFile("chunk", name)
+		if err == nil {
+			_, err = io.Copy(part, disk)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		pipeWriter.CloseWithError(err)
+	}()
+
+	query := url.Values{}
+	query.Set("action", "upload")
+	query.Set("size", strconv.FormatInt(size, 10))
+	uploadURL := fmt.Sprintf("%s/backingimages/%s?%s", util.LonghornDefaultManagerURL, dsName, query.Encode())
+	uploadReq, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, pipeReader)
+	if err != nil {
+		return fmt.Errorf("failed to create the upload request: %w", err)
+	}
+	uploadReq.Header.Set("Content-Type", form.FormDataContentType())
+
+	var urlErr *url.Error
+	uploadResp, err := client.Do(uploadReq)
+	if err != nil {
+		pipeReader.CloseWithError(err)
+		if errors.As(err, &urlErr) {
+			// Trim the "POST http://xxx" implementation detail for the error
+			return errors.Unwrap(urlErr)
+		}
+		return fmt.Errorf("failed to send the upload request: %w", err)
+	}
+	defer uploadResp.Body.Close()
+
+	if uploadResp.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(uploadResp.Body)
+		return fmt.Errorf("upload failed: %s", string(body))
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package indexeres
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
 	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
@@ -18,6 +19,7 @@ import (
 
 const (
 	PVCByDataSourceVolumeSnapshotIndex = "harvesterhci.io/pvc-by-data-source-volume-snapshot"
+	PVCByImageIDIndex                  = "harvesterhci.io/pvc-by-image-id"
 	PodByNodeNameIndex                 = "harvesterhci.io/pod-by-nodename"
 	PodByPVCIndex                      = "harvesterhci.io/pod-by-pvc"
 	VolumeByNodeIndex                  = "harvesterhci.io/volume-by-node"
@@ -33,6 +35,7 @@ func Setup(ctx context.Context, _ *server.Server, _ *server.Controllers, _ confi
 
 	pvcInformer := management.CoreFactory.Core().V1().PersistentVolumeClaim().Cache()
 	pvcInformer.AddIndexer(PVCByDataSourceVolumeSnapshotIndex, pvcByDataSourceVolumeSnapshot)
+	pvcInformer.AddIndexer(PVCByImageIDIndex, PVCByImageID)
 
 	podInformer := management.CoreFactory.Core().V1().Pod().Cache()
 	podInformer.AddIndexer(PodByNodeNameIndex, PodByNodeName)
@@ -93,10 +96,23 @@ func VMBackupBySourceVMName(obj *harvesterv1.VirtualMachineBackup) ([]string, er
 	return []string{obj.Spec.Source.Name}, nil
 }
 
+func PVCByImageID(obj *corev1.PersistentVolumeClaim) ([]string, error) {
+	imageID := obj.Annotations[util.AnnotationImageID]
+	if imageID == "" {
+		return []string{}, nil
+	}
+	return []string{imageID}, nil
+}
+
 func VMTemplateVersionByImageID(obj *harvesterv1.VirtualMachineTemplateVersion) ([]string, error) {
+	imageIDs := []string{}
+	if obj.Spec.ImageID != "" {
+		imageIDs = append(imageIDs, obj.Spec.ImageID)
+	}
+
 	volumeClaimTemplateStr, ok := obj.Spec.VM.ObjectMeta.Annotations[util.AnnotationVolumeClaimTemplates]
 	if !ok || volumeClaimTemplateStr == "" {
-		return []string{}, nil
+		return imageIDs, nil
 	}
 
 	entries, err := util.UnmarshalVolumeClaimTemplates(volumeClaimTemplateStr)
@@ -109,13 +125,12 @@ func VMTemplateVersionByImageID(obj *harvesterv1.VirtualMachineTemplateVersion)
 			"namespace":  obj.Namespace,
 			"err":        err.Error(),
 		}).Error("can't unmarshal JSON data")
-		return []string{}, nil
+		return imageIDs, nil
 	}
 
-	imageIDs := []string{}
 	for _, entry := range entries {
 		imageID, ok := entry.Annotations[util.AnnotationImageID]
-		if !ok || imageID == "" {
+		if !ok || imageID == "" || slices.Contains(imageIDs, imageID) {
 			continue
 		}
 		imageIDs = append(imageIDs, imageID)
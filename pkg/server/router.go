@@ -14,6 +14,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"k8s.io/client-go/rest"
 
+	"github.com/harvester/harvester/pkg/api/backupcatalog"
 	"github.com/harvester/harvester/pkg/api/backuptarget"
 	"github.com/harvester/harvester/pkg/api/kubeconfig"
 	"github.com/harvester/harvester/pkg/api/proxy"
@@ -72,6 +73,9 @@ func (r *Router) Routes(h router.Handlers) http.Handler {
 	btHealthyHandler := harvesterServer.NewHandler(backuptarget.NewHealthyHandler(r.scaled))
 	m.Path("/v1/harvester/backuptarget/healthz").Methods("GET").Handler(btHealthyHandler)
 
+	backupCatalogHandler := harvesterServer.NewHandler(backupcatalog.NewHandler(r.scaled))
+	m.Path("/v1/harvester/backupcatalog/{namespace}/{name}").Methods("GET").Handler(backupCatalogHandler)
+
 	readyzHandlerv1 := harvesterServer.NewHandler(readyz.NewReadyzHandler(
 		r.scaled.CoreFactory.Core().V1().Pod().Cache(),
 		r.scaled.Management.RKEFactory.Rke().V1().RKEControlPlane().Cache()))
@@ -67,6 +67,11 @@ var (
 	VMMigrationNetwork                = NewSetting(VMMigrationNetworkSettingName, "")
 	KubeVirtMigration                 = NewSetting(KubeVirtMigrationSettingName, `{"parallelOutboundMigrationsPerNode":2,"parallelMigrationsPerCluster":5,"allowAutoConverge":false,"bandwidthPerMigration":0,"completionTimeoutPerGiB":150,"progressTimeout":150,"unsafeMigrationOverride":false,"allowPostCopy":false,"allowWorkloadDisruption":false,"disableTLS":false,"matchSELinuxLevelOnMigration":false}`)
 	ClusterPodSecurityStandardSetting = NewSetting(ClusterPodSecurityStandardSettingName, `{"enabled":false,"whitelistedNamespacesList":"", "privilegedNamespacesList":"", "restrictedNamespacesList":""}`)
+	BackupThrottleSet                 = NewSetting(BackupThrottleSettingName, "{}")
+	VMMigrationPolicies               = NewSetting(VMMigrationPoliciesSettingName, "[]")
+	VMTemplateSysprepImage            = NewSetting(VMTemplateSysprepImageSettingName, "quay.io/kubevirt/libguestfs-tools:v1.7.0")
+	VMImageSignaturePolicySet         = NewSetting(VMImageSignaturePolicySettingName, "{}")
+	VMImageLifecycleSet               = NewSetting(VMImageLifecycleSettingName, "{}")
 )
 
 const (
@@ -121,6 +126,11 @@ const (
 	RancherClusterSettingName                         = "rancher-cluster"
 	KubeVirtMigrationSettingName                      = "kubevirt-migration"
 	ClusterPodSecurityStandardSettingName             = "cluster-pod-security-standard"
+	BackupThrottleSettingName                         = "backup-throttle"
+	VMMigrationPoliciesSettingName                    = "vm-migration-policies"
+	VMTemplateSysprepImageSettingName                 = "vm-template-sysprep-image"
+	VMImageSignaturePolicySettingName                 = "vm-image-signature-policy"
+	VMImageLifecycleSettingName                       = "vm-image-lifecycle"
 
 	// settings have `default` and `value` string used in many places, replace them with const
 	KeywordDefault = "default"
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"time"
 
 	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	networkutil "github.com/harvester/harvester/pkg/util/network"
@@ -30,6 +32,9 @@ type BackupTarget struct {
 	Cert                     string     `json:"cert"`
 	VirtualHostedStyle       bool       `json:"virtualHostedStyle"`
 	RefreshIntervalInSeconds int64      `json:"refreshIntervalInSeconds"`
+
+	// Name is the name of the BackupTarget object, it's empty for the backup-target setting
+	Name string `json:"-"`
 }
 
 type VMForceResetPolicy struct {
@@ -103,6 +108,141 @@ func DecodeVMForceResetPolicy(value string) (*VMForceResetPolicy, error) {
 	return policy, nil
 }
 
+// BackupThrottle limits the VM backups of the cluster
+type BackupThrottle struct {
+	// Window is the default backup window of the ScheduleVMBackups, `.spec.window` overrides it
+	Window *harvesterv1.BackupWindow `json:"window,omitempty"`
+	// MaxConcurrentVolumeBackups caps the volume backups running in parallel across the cluster, 0 means no limit
+	MaxConcurrentVolumeBackups int `json:"maxConcurrentVolumeBackups,omitempty"`
+}
+
+func DecodeBackupThrottle(value string) (*BackupThrottle, error) {
+	throttle := &BackupThrottle{}
+	if value == "" {
+		return throttle, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), throttle); err != nil {
+		return nil, fmt.Errorf("unmarshal failed, error: %w, value: %s", err, value)
+	}
+	return throttle, nil
+}
+
+// VMMigrationPolicy tunes the live migrations of the VMs selecting it with the `harvesterhci.io/migrationPolicy`
+// annotation, the unset fields fall back to the kubevirt-migration setting
+type VMMigrationPolicy struct {
+	Name                    string             `json:"name"`
+	BandwidthPerMigration   *resource.Quantity `json:"bandwidthPerMigration,omitempty"`
+	AllowAutoConverge       *bool              `json:"allowAutoConverge,omitempty"`
+	AllowPostCopy           *bool              `json:"allowPostCopy,omitempty"`
+	CompletionTimeoutPerGiB *int64             `json:"completionTimeoutPerGiB,omitempty"`
+	// NodeSelector limits the target nodes of all migrations of the VMs, including the ones started by evictions
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+func DecodeVMMigrationPolicies(value string) ([]VMMigrationPolicy, error) {
+	var policies []VMMigrationPolicy
+	if value == "" {
+		return policies, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &policies); err != nil {
+		return nil, fmt.Errorf("unmarshal failed, error: %w, value: %s", err, value)
+	}
+	return policies, nil
+}
+
+// GetVMMigrationPolicy returns the policy of the given name in the vm-migration-policies setting, nil if it's not there
+func GetVMMigrationPolicy(name string) (*VMMigrationPolicy, error) {
+	policies, err := DecodeVMMigrationPolicies(VMMigrationPolicies.Get())
+	if err != nil {
+		return nil, err
+	}
+	for i := range policies {
+		if policies[i].Name == name {
+			return &policies[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// VMImageSignaturePolicy is the cluster policy of the image signature verification
+type VMImageSignaturePolicy struct {
+	// Required rejects the downloaded images without signatures, and the uploaded and registry images
+	// whose signatures can't be verified. It needs the trusted public key, so the images can't bring their
+	// own keys. The CDI backend can't verify signatures, its downloaded images are rejected as well.
+	Required bool `json:"required"`
+	// PublicKeySecretNamespace and PublicKeySecretName point to the secret holding the trusted public key
+	// in the publicKey key, the public keys of the images are ignored when it's set
+	PublicKeySecretNamespace string `json:"publicKeySecretNamespace,omitempty"`
+	PublicKeySecretName      string `json:"publicKeySecretName,omitempty"`
+}
+
+func DecodeVMImageSignaturePolicy(value string) (*VMImageSignaturePolicy, error) {
+	policy := &VMImageSignaturePolicy{}
+	if value == "" {
+		return policy, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), policy); err != nil {
+		return nil, fmt.Errorf("unmarshal failed, error: %w, value: %s", err, value)
+	}
+	return policy, nil
+}
+
+const (
+	DeprecatedImagePolicyWarn   = "warn"
+	DeprecatedImagePolicyRefuse = "refuse"
+
+	DefaultVMImageGCTTL = 30 * 24 * time.Hour
+)
+
+// VMImageLifecycle is the cluster policy of the deprecated and unused images
+type VMImageLifecycle struct {
+	// DeprecatedImagePolicy is warn or refuse, the new VMs using the deprecated images are warned by default
+	DeprecatedImagePolicy string `json:"deprecatedImagePolicy,omitempty"`
+	// GC deletes the images unused for the TTL, it's disabled by default
+	GC VMImageGC `json:"gc"`
+}
+
+type VMImageGC struct {
+	Enabled bool `json:"enabled"`
+	// TTL is how long the image is unused before it's deleted, e.g. 720h, it's 30 days by default
+	TTL string `json:"ttl,omitempty"`
+	// DeprecatedOnly only deletes the deprecated images
+	DeprecatedOnly bool `json:"deprecatedOnly,omitempty"`
+}
+
+func DecodeVMImageLifecycle(value string) (*VMImageLifecycle, error) {
+	lifecycle := &VMImageLifecycle{}
+	if value == "" {
+		return lifecycle, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), lifecycle); err != nil {
+		return nil, fmt.Errorf("unmarshal failed, error: %w, value: %s", err, value)
+	}
+	return lifecycle, nil
+}
+
+func (l *VMImageLifecycle) RefuseDeprecatedImages() bool {
+	return l.DeprecatedImagePolicy == DeprecatedImagePolicyRefuse
+}
+
+func (gc *VMImageGC) GetTTL() (time.Duration, error) {
+	if gc.TTL == "" {
+		return DefaultVMImageGCTTL, nil
+	}
+	ttl, err := time.ParseDuration(gc.TTL)
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("ttl %s should be positive", gc.TTL)
+	}
+	return ttl, nil
+}
+
 type Overcommit struct {
 	CPU     int `json:"cpu"`
 	Memory  int `json:"memory"`
@@ -3,6 +3,7 @@ package restorevm
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	ctlharvester "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/bootorder"
 )
 
 const (
@@ -122,6 +124,8 @@ func (h *RestoreVMHandler) Run(ctx context.Context) error {
 		return fmt.Errorf("KubeVirt not ready: %w", err)
 	}
 
+	vmNames = h.sortByBootGroup(ctx, vmNames)
+
 	vmSuccessCnt := 0
 	vmFailedCnt := 0
 	for _, vmFullName := range vmNames {
@@ -182,6 +186,30 @@ func (h *RestoreVMHandler) getVMNamesFromConfigMap(ctx context.Context) ([]strin
 	return vmNames, nil
 }
 
+// sortByBootGroup puts the VMs in the lower boot groups first, the VMs booting after others are held
+// by the boot order controller until the others are up, starting them first avoids holding them long.
+func (h *RestoreVMHandler) sortByBootGroup(ctx context.Context, vmNames []string) []string {
+	groups := make(map[string]int, len(vmNames))
+	for _, vmFullName := range vmNames {
+		parts := strings.SplitN(vmFullName, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		vm, err := h.virtClient.VirtualMachine(parts[0]).Get(ctx, parts[1], metav1.GetOptions{})
+		if err != nil {
+			logrus.Warnf("Failed to get VM %s for boot group: %v", vmFullName, err)
+			continue
+		}
+		groups[vmFullName] = bootorder.Group(vm)
+	}
+
+	sorted := slices.Clone(vmNames)
+	slices.SortStableFunc(sorted, func(a, b string) int {
+		return groups[a] - groups[b]
+	})
+	return sorted
+}
+
 func (h *RestoreVMHandler) startVM(ctx context.Context, namespace, name string) error {
 	return h.virtClient.VirtualMachine(namespace).Start(ctx, name, &kubevirtv1.StartOptions{})
 }
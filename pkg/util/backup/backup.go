@@ -9,9 +9,11 @@ import (
 	"time"
 
 	"github.com/longhorn/backupstore"
+	longhorntypes "github.com/longhorn/longhorn-manager/types"
 	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
 )
@@ -36,7 +38,7 @@ func ConstructEndpoint(target *settings.BackupTarget) string {
 
 func GetBackupStoreDriver(secretCache ctlcorev1.SecretCache, target *settings.BackupTarget) (backupstore.BackupStoreDriver, error) {
 	if target.Type == settings.S3BackupType {
-		secret, err := secretCache.Get(util.LonghornSystemNamespaceName, util.BackupTargetSecretName)
+		secret, err := secretCache.Get(util.LonghornSystemNamespaceName, GetBackupTargetSecretName(target.Name))
 		if err != nil {
 			return nil, err
 		}
@@ -60,11 +62,72 @@ func GetBackupStoreDriver(secretCache ctlcorev1.SecretCache, target *settings.Ba
 	return bsDriver, nil
 }
 
-func IsBackupTargetSame(statusBackupTarget *harvesterv1.BackupTarget, target *settings.BackupTarget) bool {
+func IsBackupTargetSame(statusBackupTarget *harvesterv1.BackupTargetInfo, target *settings.BackupTarget) bool {
 	if (statusBackupTarget == nil && target != nil) || (statusBackupTarget != nil && target == nil) {
 		return false
 	}
-	return statusBackupTarget.Endpoint == target.Endpoint && statusBackupTarget.BucketName == target.BucketName && statusBackupTarget.BucketRegion == target.BucketRegion
+	return statusBackupTarget.Name == target.Name && statusBackupTarget.Endpoint == target.Endpoint && statusBackupTarget.BucketName == target.BucketName && statusBackupTarget.BucketRegion == target.BucketRegion
+}
+
+// GetBackupTargetSecretName returns the name of the secret in the longhorn-system namespace
+// which holds the credentials of the backup target. An empty name means the backup-target setting.
+func GetBackupTargetSecretName(backupTargetName string) string {
+	if backupTargetName == "" {
+		return util.BackupTargetSecretName
+	}
+	return fmt.Sprintf("%s-%s", util.BackupTargetSecretName, backupTargetName)
+}
+
+// GetLonghornBackupTargetName returns the name of the Longhorn BackupTarget serving the backup target.
+// An empty name means the backup-target setting, which is served by the Longhorn default backup target.
+func GetLonghornBackupTargetName(backupTargetName string) string {
+	if backupTargetName == "" {
+		return longhorntypes.DefaultBackupTargetName
+	}
+	return backupTargetName
+}
+
+// IsBackupProgressing returns true if the VM backup has neither an error nor been ready to use
+func IsBackupProgressing(backup *harvesterv1.VirtualMachineBackup) bool {
+	return backup.Status.Error == nil &&
+		(backup.Status.ReadyToUse == nil || !*backup.Status.ReadyToUse)
+}
+
+// GetBackupTargetNameFromLonghorn returns the name of the BackupTarget served by the Longhorn BackupTarget.
+// The default Longhorn BackupTarget serves the backup-target setting, which has an empty name.
+func GetBackupTargetNameFromLonghorn(lhBackupTargetName string) string {
+	if lhBackupTargetName == longhorntypes.DefaultBackupTargetName {
+		return ""
+	}
+	return lhBackupTargetName
+}
+
+// ConvertBackupTarget converts a BackupTarget object to the settings.BackupTarget format.
+// Credentials are not filled in, they are read from the secret in the longhorn-system namespace.
+func ConvertBackupTarget(bt *harvesterv1.BackupTarget) *settings.BackupTarget {
+	return &settings.BackupTarget{
+		Name:                     bt.Name,
+		Type:                     settings.TargetType(bt.Spec.Type),
+		Endpoint:                 bt.Spec.Endpoint,
+		BucketName:               bt.Spec.BucketName,
+		BucketRegion:             bt.Spec.BucketRegion,
+		Cert:                     bt.Spec.Cert,
+		VirtualHostedStyle:       bt.Spec.VirtualHostedStyle,
+		RefreshIntervalInSeconds: bt.Spec.RefreshIntervalInSeconds,
+	}
+}
+
+// GetBackupTarget returns the backup target with the given name.
+// An empty name means the backup-target setting.
+func GetBackupTarget(backupTargetCache ctlharvesterv1.BackupTargetCache, backupTargetName string) (*settings.BackupTarget, error) {
+	if backupTargetName == "" {
+		return settings.DecodeBackupTarget(settings.BackupTargetSet.Get())
+	}
+	bt, err := backupTargetCache.Get(backupTargetName)
+	if err != nil {
+		return nil, err
+	}
+	return ConvertBackupTarget(bt), nil
 }
 
 func GetVMImageMetadataFilePath(vmImageNamespace, vmImageName string) string {
@@ -0,0 +1,33 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LonghornBackupTargetName(t *testing.T) {
+	tests := []struct {
+		name             string
+		backupTargetName string
+		lhName           string
+	}{
+		{
+			name:             "setting backup target",
+			backupTargetName: "",
+			lhName:           "default",
+		},
+		{
+			name:             "named backup target",
+			backupTargetName: "nfs-target",
+			lhName:           "nfs-target",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.lhName, GetLonghornBackupTargetName(tt.backupTargetName))
+			assert.Equal(t, tt.backupTargetName, GetBackupTargetNameFromLonghorn(tt.lhName))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// MaxGuestHookTimeoutSeconds caps how long a guest hook may run, the backup waits for the pre-freeze hook
+const MaxGuestHookTimeoutSeconds = 600
+
+// GetGuestHooks returns the guest hooks of application-consistent backups in the annotation of the VM,
+// it returns nil if the VM doesn't have any.
+func GetGuestHooks(vm *kubevirtv1.VirtualMachine) (*harvesterv1.GuestHooks, error) {
+	value, ok := vm.Annotations[util.AnnotationBackupGuestHooks]
+	if !ok {
+		return nil, nil
+	}
+
+	hooks := &harvesterv1.GuestHooks{}
+	if err := json.Unmarshal([]byte(value), hooks); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s: %w", util.AnnotationBackupGuestHooks, err)
+	}
+	for name, hook := range map[string]*harvesterv1.GuestHook{
+		"preFreezeHook": hooks.PreFreezeHook,
+		"postThawHook":  hooks.PostThawHook,
+	} {
+		if hook == nil {
+			continue
+		}
+		if err := validateGuestHook(hook); err != nil {
+			return nil, fmt.Errorf("invalid %s in annotation %s: %w", name, util.AnnotationBackupGuestHooks, err)
+		}
+	}
+	return hooks, nil
+}
+
+func validateGuestHook(hook *harvesterv1.GuestHook) error {
+	if len(hook.Command) == 0 || hook.Command[0] == "" {
+		return errors.New("command is empty")
+	}
+	if hook.TimeoutSeconds < 0 || hook.TimeoutSeconds > MaxGuestHookTimeoutSeconds {
+		return fmt.Errorf("timeoutSeconds must be between 1 and %d", MaxGuestHookTimeoutSeconds)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func Test_GetGuestHooks(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    *harvesterv1.GuestHooks
+		expectError bool
+	}{
+		{
+			name:     "no annotation",
+			expected: nil,
+		},
+		{
+			name: "pre-freeze and post-thaw hooks",
+			annotations: map[string]string{
+				util.AnnotationBackupGuestHooks: `{"preFreezeHook":{"command":["/usr/bin/mysql","-e","FLUSH TABLES"],"timeoutSeconds":60},"postThawHook":{"command":["/usr/bin/true"]}}`,
+			},
+			expected: &harvesterv1.GuestHooks{
+				PreFreezeHook: &harvesterv1.GuestHook{Command: []string{"/usr/bin/mysql", "-e", "FLUSH TABLES"}, TimeoutSeconds: 60},
+				PostThawHook:  &harvesterv1.GuestHook{Command: []string{"/usr/bin/true"}},
+			},
+		},
+		{
+			name:        "invalid json",
+			annotations: map[string]string{util.AnnotationBackupGuestHooks: `{"preFreezeHook":`},
+			expectError: true,
+		},
+		{
+			name:        "empty command",
+			annotations: map[string]string{util.AnnotationBackupGuestHooks: `{"postThawHook":{"command":[]}}`},
+			expectError: true,
+		},
+		{
+			name:        "timeout too long",
+			annotations: map[string]string{util.AnnotationBackupGuestHooks: `{"preFreezeHook":{"command":["/usr/bin/true"],"timeoutSeconds":3600}}`},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vm := &kubevirtv1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			hooks, err := GetGuestHooks(vm)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, hooks)
+		})
+	}
+}
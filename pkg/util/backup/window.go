@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+	// the time zones of backup windows don't depend on the tzdata of the image
+	_ "time/tzdata"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+const windowTimeFormat = "15:04"
+
+// ValidateBackupWindow checks the times and the time zone of the window
+func ValidateBackupWindow(window *harvesterv1.BackupWindow) error {
+	_, _, _, err := parseBackupWindow(window)
+	return err
+}
+
+func parseBackupWindow(window *harvesterv1.BackupWindow) (start, end time.Duration, loc *time.Location, err error) {
+	startTime, err := time.Parse(windowTimeFormat, window.Start)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid start %q of backup window, it should be in HH:MM format", window.Start)
+	}
+
+	endTime, err := time.Parse(windowTimeFormat, window.End)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid end %q of backup window, it should be in HH:MM format", window.End)
+	}
+
+	loc = time.UTC
+	if window.TimeZone != "" {
+		if loc, err = time.LoadLocation(window.TimeZone); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid time zone %q of backup window: %w", window.TimeZone, err)
+		}
+	}
+
+	sinceMidnight := func(t time.Time) time.Duration {
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return sinceMidnight(startTime), sinceMidnight(endTime), loc, nil
+}
+
+// UntilBackupWindow returns how long it takes for the window to open, 0 means now is in the window.
+// A nil window or a window with the same start and end is always open.
+func UntilBackupWindow(window *harvesterv1.BackupWindow, now time.Time) (time.Duration, error) {
+	if window == nil {
+		return 0, nil
+	}
+
+	start, end, loc, err := parseBackupWindow(window)
+	if err != nil {
+		return 0, err
+	}
+	if start == end {
+		return 0, nil
+	}
+
+	now = now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	current := now.Sub(midnight)
+
+	inWindow := current >= start && current < end
+	if end < start {
+		// the window wraps around midnight
+		inWindow = current >= start || current < end
+	}
+	if inWindow {
+		return 0, nil
+	}
+
+	// compute the next start with the calendar so that DST changes are respected
+	next := time.Date(now.Year(), now.Month(), now.Day(), int(start/time.Hour), int(start%time.Hour/time.Minute), 0, 0, loc)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next.Sub(now), nil
+}
@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func Test_UntilBackupWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 5, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		window   *harvesterv1.BackupWindow
+		now      time.Time
+		expected time.Duration
+	}{
+		{
+			name:     "no window",
+			window:   nil,
+			now:      at(12, 0),
+			expected: 0,
+		},
+		{
+			name:     "whole day",
+			window:   &harvesterv1.BackupWindow{Start: "08:00", End: "08:00"},
+			now:      at(3, 0),
+			expected: 0,
+		},
+		{
+			name:     "in window",
+			window:   &harvesterv1.BackupWindow{Start: "01:00", End: "05:00"},
+			now:      at(3, 0),
+			expected: 0,
+		},
+		{
+			name:     "before window",
+			window:   &harvesterv1.BackupWindow{Start: "01:00", End: "05:00"},
+			now:      at(0, 30),
+			expected: 30 * time.Minute,
+		},
+		{
+			name:     "window end is exclusive",
+			window:   &harvesterv1.BackupWindow{Start: "01:00", End: "05:00"},
+			now:      at(5, 0),
+			expected: 20 * time.Hour,
+		},
+		{
+			name:     "wrapped window before midnight",
+			window:   &harvesterv1.BackupWindow{Start: "22:00", End: "06:00"},
+			now:      at(23, 0),
+			expected: 0,
+		},
+		{
+			name:     "wrapped window after midnight",
+			window:   &harvesterv1.BackupWindow{Start: "22:00", End: "06:00"},
+			now:      at(5, 59),
+			expected: 0,
+		},
+		{
+			name:     "outside wrapped window",
+			window:   &harvesterv1.BackupWindow{Start: "22:00", End: "06:00"},
+			now:      at(12, 0),
+			expected: 10 * time.Hour,
+		},
+		{
+			name:     "time zone",
+			window:   &harvesterv1.BackupWindow{Start: "22:00", End: "06:00", TimeZone: "Asia/Taipei"},
+			now:      at(12, 0), // 20:00 in Taipei
+			expected: 2 * time.Hour,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			until, err := UntilBackupWindow(tc.window, tc.now)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, until)
+		})
+	}
+}
+
+func Test_ValidateBackupWindow(t *testing.T) {
+	assert.NoError(t, ValidateBackupWindow(&harvesterv1.BackupWindow{Start: "22:00", End: "06:00", TimeZone: "Europe/Berlin"}))
+	assert.Error(t, ValidateBackupWindow(&harvesterv1.BackupWindow{Start: "24:00", End: "06:00"}))
+	assert.Error(t, ValidateBackupWindow(&harvesterv1.BackupWindow{Start: "22:00", End: "6"}))
+	assert.Error(t, ValidateBackupWindow(&harvesterv1.BackupWindow{Start: "22:00", End: "06:00", TimeZone: "Mars/Olympus"}))
+}
@@ -0,0 +1,209 @@
+package bootorder
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// Spec is the boot ordering of a VM declared by the annotations:
+//   - harvesterhci.io/bootGroup: the VM boots after the VMs with a lower group in the same namespace
+//   - harvesterhci.io/bootAfter: the comma separated names of the VMs in the same namespace the VM boots after
+//   - harvesterhci.io/bootCondition: the condition the VMs booted before have to reach, Ready or AgentConnected
+//   - harvesterhci.io/bootDelaySeconds: how long to wait after the VMs booted before reach the condition
+type Spec struct {
+	Group     int
+	After     []string
+	Condition kubevirtv1.VirtualMachineInstanceConditionType
+	Delay     time.Duration
+}
+
+// Parse returns the boot ordering of the VM, the default is group 0 without any dependency
+func Parse(vm *kubevirtv1.VirtualMachine) (Spec, error) {
+	spec := Spec{Condition: kubevirtv1.VirtualMachineInstanceReady}
+
+	if value := vm.Annotations[util.AnnotationBootGroup]; value != "" {
+		group, err := strconv.Atoi(value)
+		if err != nil || group < 0 {
+			return spec, fmt.Errorf("%s must be a non-negative integer", util.AnnotationBootGroup)
+		}
+		spec.Group = group
+	}
+
+	if value := vm.Annotations[util.AnnotationBootAfter]; value != "" {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+				return spec, fmt.Errorf("%s has invalid VM name %q: %s", util.AnnotationBootAfter, name, strings.Join(errs, ", "))
+			}
+			if name == vm.Name {
+				return spec, fmt.Errorf("%s can't contain the VM itself", util.AnnotationBootAfter)
+			}
+			spec.After = append(spec.After, name)
+		}
+	}
+
+	if value := vm.Annotations[util.AnnotationBootCondition]; value != "" {
+		condition := kubevirtv1.VirtualMachineInstanceConditionType(value)
+		if condition != kubevirtv1.VirtualMachineInstanceReady && condition != kubevirtv1.VirtualMachineInstanceAgentConnected {
+			return spec, fmt.Errorf("%s must be %s or %s", util.AnnotationBootCondition,
+				kubevirtv1.VirtualMachineInstanceReady, kubevirtv1.VirtualMachineInstanceAgentConnected)
+		}
+		spec.Condition = condition
+	}
+
+	if value := vm.Annotations[util.AnnotationBootDelaySeconds]; value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return spec, fmt.Errorf("%s must be a non-negative integer", util.AnnotationBootDelaySeconds)
+		}
+		spec.Delay = time.Duration(seconds) * time.Second
+	}
+
+	return spec, nil
+}
+
+// Group returns the boot group of the VM, an invalid group is treated as the default group 0
+func Group(vm *kubevirtv1.VirtualMachine) int {
+	spec, err := Parse(vm)
+	if err != nil {
+		return 0
+	}
+	return spec.Group
+}
+
+// SortByGroup sorts the VMs by the boot group, the order in the same group is kept
+func SortByGroup(vms []*kubevirtv1.VirtualMachine) {
+	sort.SliceStable(vms, func(i, j int) bool {
+		return Group(vms[i]) < Group(vms[j])
+	})
+}
+
+// Dependencies returns the VMs the VM boots after from the VMs in its namespace, which are the VMs in the lower
+// groups and the VMs in bootAfter. The VMs which aren't supposed to run are left out, so that a stopped VM
+// doesn't hold the others.
+func Dependencies(vm *kubevirtv1.VirtualMachine, spec Spec, vms []*kubevirtv1.VirtualMachine) []*kubevirtv1.VirtualMachine {
+	after := map[string]bool{}
+	for _, name := range spec.After {
+		after[name] = true
+	}
+
+	var dependencies []*kubevirtv1.VirtualMachine
+	for _, dependency := range vms {
+		if dependency.Namespace != vm.Namespace || dependency.Name == vm.Name || !isSupposedToRun(dependency) {
+			continue
+		}
+		if after[dependency.Name] || Group(dependency) < spec.Group {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies
+}
+
+func isSupposedToRun(vm *kubevirtv1.VirtualMachine) bool {
+	if vm.DeletionTimestamp != nil {
+		return false
+	}
+	strategy, err := vm.RunStrategy()
+	if err != nil {
+		return false
+	}
+	return strategy != kubevirtv1.RunStrategyHalted && strategy != kubevirtv1.RunStrategyManual
+}
+
+// Check returns the names of the dependencies which haven't reached the boot condition yet. If all of them
+// have, it returns the time the last of them reached the condition.
+func Check(spec Spec, dependencies []*kubevirtv1.VirtualMachine, vmiCache ctlkubevirtv1.VirtualMachineInstanceCache) ([]string, time.Time, error) {
+	var (
+		pending []string
+		readyAt time.Time
+	)
+	for _, dependency := range dependencies {
+		vmi, err := vmiCache.Get(dependency.Namespace, dependency.Name)
+		if apierrors.IsNotFound(err) {
+			pending = append(pending, dependency.Name)
+			continue
+		} else if err != nil {
+			return nil, readyAt, err
+		}
+
+		condition := getCondition(vmi, spec.Condition)
+		if vmi.IsFinal() || getCondition(vmi, kubevirtv1.VirtualMachineInstancePaused) != nil || condition == nil {
+			pending = append(pending, dependency.Name)
+			continue
+		}
+		if condition.LastTransitionTime.Time.After(readyAt) {
+			readyAt = condition.LastTransitionTime.Time
+		}
+	}
+	return pending, readyAt, nil
+}
+
+func getCondition(vmi *kubevirtv1.VirtualMachineInstance, conditionType kubevirtv1.VirtualMachineInstanceConditionType) *kubevirtv1.VirtualMachineInstanceCondition {
+	for i, condition := range vmi.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return &vmi.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// HasCycle checks whether the VM ends up booting after itself with the VMs in its namespace,
+// the VM in the VMs is replaced by the given one.
+func HasCycle(vm *kubevirtv1.VirtualMachine, vms []*kubevirtv1.VirtualMachine) bool {
+	byName := map[string]*kubevirtv1.VirtualMachine{vm.Name: vm}
+	for _, v := range vms {
+		if v.Namespace == vm.Namespace && v.Name != vm.Name {
+			byName[v.Name] = v
+		}
+	}
+
+	visited := map[string]bool{}
+	var visit func(current *kubevirtv1.VirtualMachine) bool
+	visit = func(current *kubevirtv1.VirtualMachine) bool {
+		spec, err := Parse(current)
+		if err != nil {
+			return false
+		}
+		for _, name := range spec.After {
+			if name == vm.Name {
+				return true
+			}
+			next, ok := byName[name]
+			if !ok || visited[name] {
+				continue
+			}
+			visited[name] = true
+			if visit(next) {
+				return true
+			}
+		}
+		for name, next := range byName {
+			if visited[name] || Group(next) >= spec.Group {
+				continue
+			}
+			if name == vm.Name {
+				return true
+			}
+			visited[name] = true
+			if visit(next) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(vm)
+}
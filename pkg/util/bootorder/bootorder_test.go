@@ -0,0 +1,200 @@
+package bootorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newVM(name string, runStrategy kubevirtv1.VirtualMachineRunStrategy, annotations map[string]string) *kubevirtv1.VirtualMachine {
+	return &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: annotations},
+		Spec:       kubevirtv1.VirtualMachineSpec{RunStrategy: ptr.To(runStrategy)},
+	}
+}
+
+func vmNames(vms []*kubevirtv1.VirtualMachine) []string {
+	var names []string
+	for _, vm := range vms {
+		names = append(names, vm.Name)
+	}
+	return names
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    Spec
+		expectError bool
+	}{
+		{
+			name:     "default",
+			expected: Spec{Condition: kubevirtv1.VirtualMachineInstanceReady},
+		},
+		{
+			name: "all annotations",
+			annotations: map[string]string{
+				util.AnnotationBootGroup:        "2",
+				util.AnnotationBootAfter:        "db, cache,",
+				util.AnnotationBootCondition:    "AgentConnected",
+				util.AnnotationBootDelaySeconds: "30",
+			},
+			expected: Spec{
+				Group:     2,
+				After:     []string{"db", "cache"},
+				Condition: kubevirtv1.VirtualMachineInstanceAgentConnected,
+				Delay:     30 * time.Second,
+			},
+		},
+		{
+			name:        "negative group",
+			annotations: map[string]string{util.AnnotationBootGroup: "-1"},
+			expectError: true,
+		},
+		{
+			name:        "after itself",
+			annotations: map[string]string{util.AnnotationBootAfter: "app"},
+			expectError: true,
+		},
+		{
+			name:        "unknown condition",
+			annotations: map[string]string{util.AnnotationBootCondition: "Paused"},
+			expectError: true,
+		},
+		{
+			name:        "invalid delay",
+			annotations: map[string]string{util.AnnotationBootDelaySeconds: "1m"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := Parse(newVM("app", kubevirtv1.RunStrategyAlways, tt.annotations))
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, spec)
+		})
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	vms := []*kubevirtv1.VirtualMachine{
+		newVM("db", kubevirtv1.RunStrategyAlways, map[string]string{util.AnnotationBootGroup: "0"}),
+		newVM("cache", kubevirtv1.RunStrategyRerunOnFailure, map[string]string{util.AnnotationBootGroup: "1"}),
+		newVM("stopped", kubevirtv1.RunStrategyHalted, nil),
+		newVM("monitor", kubevirtv1.RunStrategyAlways, map[string]string{util.AnnotationBootGroup: "3"}),
+		newVM("app", kubevirtv1.RunStrategyAlways, map[string]string{
+			util.AnnotationBootGroup: "2",
+			util.AnnotationBootAfter: "monitor,stopped",
+		}),
+	}
+	SortByGroup(vms)
+	assert.Equal(t, []string{"db", "stopped", "cache", "app", "monitor"}, vmNames(vms))
+
+	app := vms[3]
+	spec, err := Parse(app)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db", "cache", "monitor"}, vmNames(Dependencies(app, spec, vms)))
+}
+
+func TestCheck(t *testing.T) {
+	readyAt := metav1.NewTime(time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC))
+	clientset := fake.NewSimpleClientset(
+		&kubevirtv1.VirtualMachineInstance{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"},
+			Status: kubevirtv1.VirtualMachineInstanceStatus{
+				Phase: kubevirtv1.Running,
+				Conditions: []kubevirtv1.VirtualMachineInstanceCondition{
+					{Type: kubevirtv1.VirtualMachineInstanceReady, Status: corev1.ConditionTrue, LastTransitionTime: readyAt},
+				},
+			},
+		},
+		&kubevirtv1.VirtualMachineInstance{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cache"},
+			Status: kubevirtv1.VirtualMachineInstanceStatus{
+				Phase: kubevirtv1.Running,
+				Conditions: []kubevirtv1.VirtualMachineInstanceCondition{
+					{Type: kubevirtv1.VirtualMachineInstanceReady, Status: corev1.ConditionTrue},
+					{Type: kubevirtv1.VirtualMachineInstancePaused, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+	)
+	vmiCache := fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances)
+
+	db := newVM("db", kubevirtv1.RunStrategyAlways, nil)
+	cache := newVM("cache", kubevirtv1.RunStrategyAlways, nil)
+	missing := newVM("missing", kubevirtv1.RunStrategyAlways, nil)
+
+	pending, lastReadyAt, err := Check(Spec{Condition: kubevirtv1.VirtualMachineInstanceReady}, []*kubevirtv1.VirtualMachine{db}, vmiCache)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.Equal(t, readyAt.Time, lastReadyAt)
+
+	pending, _, err = Check(Spec{Condition: kubevirtv1.VirtualMachineInstanceAgentConnected}, []*kubevirtv1.VirtualMachine{db}, vmiCache)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db"}, pending)
+
+	// a paused VMI isn't up even though it's ready
+	pending, _, err = Check(Spec{Condition: kubevirtv1.VirtualMachineInstanceReady}, []*kubevirtv1.VirtualMachine{db, cache, missing}, vmiCache)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cache", "missing"}, pending)
+}
+
+func TestHasCycle(t *testing.T) {
+	vms := []*kubevirtv1.VirtualMachine{
+		newVM("db", kubevirtv1.RunStrategyAlways, nil),
+		newVM("app", kubevirtv1.RunStrategyAlways, map[string]string{util.AnnotationBootAfter: "db"}),
+		newVM("web", kubevirtv1.RunStrategyAlways, map[string]string{util.AnnotationBootGroup: "1"}),
+	}
+
+	tests := []struct {
+		name     string
+		vm       *kubevirtv1.VirtualMachine
+		expected bool
+	}{
+		{
+			name: "boot after a VM booting after others",
+			vm:   newVM("proxy", kubevirtv1.RunStrategyAlways, map[string]string{util.AnnotationBootGroup: "2", util.AnnotationBootAfter: "app,web"}),
+		},
+		{
+			name:     "boot after each other",
+			vm:       newVM("db", kubevirtv1.RunStrategyAlways, map[string]string{util.AnnotationBootAfter: "app"}),
+			expected: true,
+		},
+		{
+			name:     "boot after a VM in a higher group",
+			vm:       newVM("db", kubevirtv1.RunStrategyAlways, map[string]string{util.AnnotationBootAfter: "web"}),
+			expected: true,
+		},
+		{
+			name:     "move to a higher group than a VM booting after it",
+			vm:       newVM("db", kubevirtv1.RunStrategyAlways, map[string]string{util.AnnotationBootGroup: "1"}),
+			expected: true,
+		},
+		{
+			name: "boot after a VM in a lower group",
+			vm:   newVM("web", kubevirtv1.RunStrategyAlways, map[string]string{util.AnnotationBootGroup: "1", util.AnnotationBootAfter: "app"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, HasCycle(tt.vm, vms))
+		})
+	}
+}
@@ -13,6 +13,7 @@ const (
 	AnnotationWaitingStorageMigration   = prefix + "/waitingStorageMigration"
 	AnnotationUpgradePatched            = prefix + "/upgrade-patched"
 	AnnotationImageID                   = prefix + "/imageId"
+	AnnotationBackupTargetName          = prefix + "/backupTargetName"
 	AnnotationReservedMemory            = prefix + "/reservedMemory"
 	AnnotationHash                      = prefix + "/hash"
 	AnnotationRunStrategy               = prefix + "/vmRunStrategy"
@@ -20,13 +21,30 @@ const (
 	AnnotationSnapshotRevise            = prefix + "/snapRevise"
 	AnnotationSVMBackupID               = prefix + "/svmbackupId"
 	AnnotationSVMBackupSkipCronCheck    = prefix + "/svmbackupSkipCronCheck"
+	AnnotationSVMBackupSequence         = prefix + "/svmbackupSequence"
+	AnnotationSVMPowerActionID          = prefix + "/svmpowerActionId"
+	AnnotationPromotedFrom              = prefix + "/promotedFrom"
 	AnnotationGoldenImage               = prefix + "/goldenImage"
+	AnnotationMigrationPolicy           = prefix + "/migrationPolicy"
+	AnnotationBootGroup                 = prefix + "/bootGroup"
+	AnnotationBootAfter                 = prefix + "/bootAfter"
+	AnnotationBootCondition             = prefix + "/bootCondition"
+	AnnotationBootDelaySeconds          = prefix + "/bootDelaySeconds"
+	AnnotationVolumeExpansion           = prefix + "/volumeExpansion"
+	AnnotationTemplateGeneralize        = prefix + "/templateGeneralize"
+	AnnotationImageReplicationID        = prefix + "/imageReplicationId"
+	AnnotationImageReplicationSource    = prefix + "/imageReplicationSource"
+	AnnotationBackupGuestHooks          = prefix + "/backupGuestHooks"
 	LabelImageDisplayName               = prefix + "/imageDisplayName"
 	LabelSetting                        = prefix + "/setting"
 	LabelVMName                         = prefix + "/vmName"
+	LabelVMDownloader                   = prefix + "/vmDownloader"
 	LabelSVMBackupUID                   = prefix + "/svmbackupUID"
 	LabelSVMBackupTimestamp             = prefix + "/svmbackupTimestamp"
+	LabelBackupVerification             = prefix + "/backupVerification"
 	LabelVMCreator                      = prefix + "/creator"
+	LabelMigrationPolicy                = prefix + "/migrationPolicy"
+	LabelBootHeld                       = prefix + "/bootHeld"
 	LabelVMimported                     = "migration.harvesterhci.io/imported"
 	LabelNodeNameKey                    = "kubevirt.io/nodeName"
 	LabelKubeVirtPersistentState        = "persistent-state-for" // KubeVirt-managed label for persistent state PVCs
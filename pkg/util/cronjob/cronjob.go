@@ -0,0 +1,83 @@
+package cronjob
+
+import (
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	utilHelm "github.com/harvester/harvester/pkg/util/helm"
+)
+
+const (
+	Namespace = "harvester-system"
+
+	backoffLimit            = 3
+	cmd                     = "sleep"
+	arg                     = "10"
+	releaseAppHarvesterName = "harvester"
+
+	// scheduleTimeTolerance is how long the last schedule time of a CronJob is considered up-to-date
+	scheduleTimeTolerance = time.Minute
+)
+
+// NewTrigger returns a CronJob which doesn't do anything in its own job, it's utilized to trigger the
+// controllers watching the CronJob at the schedule. The controllers resolve their object from the annotations.
+func NewTrigger(clientset kubernetes.Interface, harvesterNamespace, name, schedule string, annotations map[string]string) (*batchv1.CronJob, error) {
+	limit := int32(backoffLimit)
+	jobImage, err := utilHelm.FetchImageFromHelmValues(clientset, harvesterNamespace,
+		releaseAppHarvesterName, []string{"generalJob", "image"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvester image (%s): %v", jobImage.ImageName(), err)
+	}
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   Namespace,
+			Annotations: annotations,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &limit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: name,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            name,
+									Image:           jobImage.ImageName(),
+									Command:         []string{cmd},
+									Args:            []string{arg},
+									Resources:       corev1.ResourceRequirements{},
+									ImagePullPolicy: corev1.PullIfNotPresent,
+								},
+							},
+							RestartPolicy: corev1.RestartPolicyNever,
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// LastScheduleTime returns the time the CronJob was just scheduled at, it's nil if the CronJob isn't scheduled yet
+// or the last schedule time is out-of-date, which happens if the schedule experiences suspend and resume.
+func LastScheduleTime(cronJob *batchv1.CronJob) *metav1.Time {
+	if cronJob == nil || cronJob.Status.LastScheduleTime == nil {
+		return nil
+	}
+	if time.Since(cronJob.Status.LastScheduleTime.Time) > scheduleTimeTolerance {
+		return nil
+	}
+	return cronJob.Status.LastScheduleTime
+}
@@ -0,0 +1,136 @@
+package fakeclients
+
+import (
+	"context"
+	"time"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harvestertype "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+)
+
+// BackupTargetClient has the semantics of the status subresource, Update ignores the status and UpdateStatus only
+// updates the status, which the fake object tracker doesn't do
+type BackupTargetClient func() harvestertype.BackupTargetInterface
+
+func (c BackupTargetClient) Informer() cache.SharedIndexInformer {
+	panic("implement me")
+}
+
+func (c BackupTargetClient) GroupVersionKind() schema.GroupVersionKind {
+	panic("implement me")
+}
+
+func (c BackupTargetClient) AddGenericHandler(_ context.Context, _ string, _ generic.Handler) {
+	panic("implement me")
+}
+
+func (c BackupTargetClient) AddGenericRemoveHandler(_ context.Context, _ string, _ generic.Handler) {
+	panic("implement me")
+}
+
+func (c BackupTargetClient) Updater() generic.Updater {
+	panic("implement me")
+}
+
+func (c BackupTargetClient) OnChange(_ context.Context, _ string, _ generic.ObjectHandler[*harvesterv1.BackupTarget]) {
+	panic("implement me")
+}
+
+func (c BackupTargetClient) OnRemove(_ context.Context, _ string, _ generic.ObjectHandler[*harvesterv1.BackupTarget]) {
+	panic("implement me")
+}
+
+func (c BackupTargetClient) Enqueue(_ string) {
+	// do nothing
+}
+
+func (c BackupTargetClient) EnqueueAfter(_ string, _ time.Duration) {
+	// do nothing
+}
+
+func (c BackupTargetClient) Cache() generic.NonNamespacedCacheInterface[*harvesterv1.BackupTarget] {
+	panic("implement me")
+}
+
+func (c BackupTargetClient) Create(bt *harvesterv1.BackupTarget) (*harvesterv1.BackupTarget, error) {
+	return c().Create(context.TODO(), bt, metav1.CreateOptions{})
+}
+
+func (c BackupTargetClient) Update(bt *harvesterv1.BackupTarget) (*harvesterv1.BackupTarget, error) {
+	existing, err := c().Get(context.TODO(), bt.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	toUpdate := bt.DeepCopy()
+	toUpdate.Status = existing.Status
+	return c().Update(context.TODO(), toUpdate, metav1.UpdateOptions{})
+}
+
+func (c BackupTargetClient) UpdateStatus(bt *harvesterv1.BackupTarget) (*harvesterv1.BackupTarget, error) {
+	existing, err := c().Get(context.TODO(), bt.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	toUpdate := existing.DeepCopy()
+	toUpdate.Status = bt.Status
+	return c().UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{})
+}
+
+func (c BackupTargetClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return c().Delete(context.TODO(), name, *options)
+}
+
+func (c BackupTargetClient) Get(name string, options metav1.GetOptions) (*harvesterv1.BackupTarget, error) {
+	return c().Get(context.TODO(), name, options)
+}
+
+func (c BackupTargetClient) List(opts metav1.ListOptions) (*harvesterv1.BackupTargetList, error) {
+	return c().List(context.TODO(), opts)
+}
+
+func (c BackupTargetClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c().Watch(context.TODO(), opts)
+}
+
+func (c BackupTargetClient) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (*harvesterv1.BackupTarget, error) {
+	return c().Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+func (c BackupTargetClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.NonNamespacedClientInterface[*harvesterv1.BackupTarget, *harvesterv1.BackupTargetList], error) {
+	panic("implement me")
+}
+
+type BackupTargetCache func() harvestertype.BackupTargetInterface
+
+func (c BackupTargetCache) Get(name string) (*harvesterv1.BackupTarget, error) {
+	return c().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c BackupTargetCache) List(selector labels.Selector) ([]*harvesterv1.BackupTarget, error) {
+	list, err := c().List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*harvesterv1.BackupTarget, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+
+func (c BackupTargetCache) AddIndexer(_ string, _ generic.Indexer[*harvesterv1.BackupTarget]) {
+	panic("implement me")
+}
+
+func (c BackupTargetCache) GetByIndex(_, _ string) ([]*harvesterv1.BackupTarget, error) {
+	panic("implement me")
+}
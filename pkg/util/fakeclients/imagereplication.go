@@ -0,0 +1,79 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	harvesterv1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harvestertype "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+)
+
+type ImageReplicationClient func(string) harvestertype.ImageReplicationInterface
+
+func (c ImageReplicationClient) Create(imageReplication *harvesterv1beta1.ImageReplication) (*harvesterv1beta1.ImageReplication, error) {
+	return c(imageReplication.Namespace).Create(context.TODO(), imageReplication, metav1.CreateOptions{})
+}
+
+func (c ImageReplicationClient) Update(imageReplication *harvesterv1beta1.ImageReplication) (*harvesterv1beta1.ImageReplication, error) {
+	return c(imageReplication.Namespace).Update(context.TODO(), imageReplication, metav1.UpdateOptions{})
+}
+
+func (c ImageReplicationClient) UpdateStatus(imageReplication *harvesterv1beta1.ImageReplication) (*harvesterv1beta1.ImageReplication, error) {
+	return c(imageReplication.Namespace).UpdateStatus(context.TODO(), imageReplication, metav1.UpdateOptions{})
+}
+
+func (c ImageReplicationClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
+}
+
+func (c ImageReplicationClient) Get(namespace, name string, options metav1.GetOptions) (*harvesterv1beta1.ImageReplication, error) {
+	return c(namespace).Get(context.TODO(), name, options)
+}
+
+func (c ImageReplicationClient) List(namespace string, opts metav1.ListOptions) (*harvesterv1beta1.ImageReplicationList, error) {
+	return c(namespace).List(context.TODO(), opts)
+}
+
+func (c ImageReplicationClient) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c(namespace).Watch(context.TODO(), opts)
+}
+
+func (c ImageReplicationClient) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (result *harvesterv1beta1.ImageReplication, err error) {
+	return c(namespace).Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+func (c ImageReplicationClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.ClientInterface[*harvesterv1beta1.ImageReplication, *harvesterv1beta1.ImageReplicationList], error) {
+	panic("implement me")
+}
+
+type ImageReplicationCache func(string) harvestertype.ImageReplicationInterface
+
+func (c ImageReplicationCache) Get(namespace, name string) (*harvesterv1beta1.ImageReplication, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c ImageReplicationCache) List(namespace string, selector labels.Selector) ([]*harvesterv1beta1.ImageReplication, error) {
+	list, err := c(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*harvesterv1beta1.ImageReplication, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+
+func (c ImageReplicationCache) AddIndexer(_ string, _ generic.Indexer[*harvesterv1beta1.ImageReplication]) {
+	panic("implement me")
+}
+
+func (c ImageReplicationCache) GetByIndex(_, _ string) ([]*harvesterv1beta1.ImageReplication, error) {
+	panic("implement me")
+}
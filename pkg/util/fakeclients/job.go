@@ -56,8 +56,8 @@ func (c JobClient) Create(job *batchv1.Job) (*batchv1.Job, error) {
 func (c JobClient) UpdateStatus(*batchv1.Job) (*batchv1.Job, error) {
 	panic("implement me")
 }
-func (c JobClient) Delete(_, _ string, _ *metav1.DeleteOptions) error {
-	panic("implement me")
+func (c JobClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
 }
 func (c JobClient) List(_ string, _ metav1.ListOptions) (*batchv1.JobList, error) {
 	panic("implement me")
@@ -0,0 +1,82 @@
+package fakeclients
+
+import (
+	"context"
+
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	longhornv1beta2 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/longhorn.io/v1beta2"
+)
+
+type LonghornBackupClient func(string) longhornv1beta2.BackupInterface
+
+func (c LonghornBackupClient) Create(backup *lhv1beta2.Backup) (*lhv1beta2.Backup, error) {
+	return c(backup.Namespace).Create(context.TODO(), backup, metav1.CreateOptions{})
+}
+
+func (c LonghornBackupClient) Update(backup *lhv1beta2.Backup) (*lhv1beta2.Backup, error) {
+	return c(backup.Namespace).Update(context.TODO(), backup, metav1.UpdateOptions{})
+}
+
+func (c LonghornBackupClient) UpdateStatus(*lhv1beta2.Backup) (*lhv1beta2.Backup, error) {
+	panic("implement me")
+}
+
+func (c LonghornBackupClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
+}
+
+func (c LonghornBackupClient) Get(namespace, name string, options metav1.GetOptions) (*lhv1beta2.Backup, error) {
+	return c(namespace).Get(context.TODO(), name, options)
+}
+
+func (c LonghornBackupClient) List(namespace string, opts metav1.ListOptions) (*lhv1beta2.BackupList, error) {
+	return c(namespace).List(context.TODO(), opts)
+}
+
+func (c LonghornBackupClient) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c(namespace).Watch(context.TODO(), opts)
+}
+
+func (c LonghornBackupClient) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (result *lhv1beta2.Backup, err error) {
+	return c(namespace).Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+func (c LonghornBackupClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.ClientInterface[*lhv1beta2.Backup, *lhv1beta2.BackupList], error) {
+	panic("implement me")
+}
+
+type LonghornBackupCache func(string) longhornv1beta2.BackupInterface
+
+func (c LonghornBackupCache) Get(namespace, name string) (*lhv1beta2.Backup, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c LonghornBackupCache) List(namespace string, selector labels.Selector) ([]*lhv1beta2.Backup, error) {
+	backupList, err := c(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	returnBackups := make([]*lhv1beta2.Backup, 0, len(backupList.Items))
+	for i := range backupList.Items {
+		returnBackups = append(returnBackups, &backupList.Items[i])
+	}
+
+	return returnBackups, nil
+}
+
+func (c LonghornBackupCache) AddIndexer(_ string, _ generic.Indexer[*lhv1beta2.Backup]) {
+	panic("implement me")
+}
+
+func (c LonghornBackupCache) GetByIndex(_, _ string) ([]*lhv1beta2.Backup, error) {
+	panic("implement me")
+}
@@ -0,0 +1,79 @@
+package fakeclients
+
+import (
+	"context"
+
+	lhv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	lhtype "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/longhorn.io/v1beta2"
+)
+
+type LonghornBackupTargetClient func(string) lhtype.BackupTargetInterface
+
+func (c LonghornBackupTargetClient) Create(backupTarget *lhv1beta2.BackupTarget) (*lhv1beta2.BackupTarget, error) {
+	return c(backupTarget.Namespace).Create(context.TODO(), backupTarget, metav1.CreateOptions{})
+}
+
+func (c LonghornBackupTargetClient) Update(backupTarget *lhv1beta2.BackupTarget) (*lhv1beta2.BackupTarget, error) {
+	return c(backupTarget.Namespace).Update(context.TODO(), backupTarget, metav1.UpdateOptions{})
+}
+
+func (c LonghornBackupTargetClient) UpdateStatus(backupTarget *lhv1beta2.BackupTarget) (*lhv1beta2.BackupTarget, error) {
+	return c(backupTarget.Namespace).UpdateStatus(context.TODO(), backupTarget, metav1.UpdateOptions{})
+}
+
+func (c LonghornBackupTargetClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
+}
+
+func (c LonghornBackupTargetClient) Get(namespace, name string, options metav1.GetOptions) (*lhv1beta2.BackupTarget, error) {
+	return c(namespace).Get(context.TODO(), name, options)
+}
+
+func (c LonghornBackupTargetClient) List(namespace string, opts metav1.ListOptions) (*lhv1beta2.BackupTargetList, error) {
+	return c(namespace).List(context.TODO(), opts)
+}
+
+func (c LonghornBackupTargetClient) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c(namespace).Watch(context.TODO(), opts)
+}
+
+func (c LonghornBackupTargetClient) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (*lhv1beta2.BackupTarget, error) {
+	return c(namespace).Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+func (c LonghornBackupTargetClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.ClientInterface[*lhv1beta2.BackupTarget, *lhv1beta2.BackupTargetList], error) {
+	panic("implement me")
+}
+
+type LonghornBackupTargetCache func(string) lhtype.BackupTargetInterface
+
+func (c LonghornBackupTargetCache) Get(namespace, name string) (*lhv1beta2.BackupTarget, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c LonghornBackupTargetCache) List(namespace string, selector labels.Selector) ([]*lhv1beta2.BackupTarget, error) {
+	list, err := c(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*lhv1beta2.BackupTarget, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+
+func (c LonghornBackupTargetCache) AddIndexer(_ string, _ generic.Indexer[*lhv1beta2.BackupTarget]) {
+	panic("implement me")
+}
+
+func (c LonghornBackupTargetCache) GetByIndex(_, _ string) ([]*lhv1beta2.BackupTarget, error) {
+	panic("implement me")
+}
@@ -9,6 +9,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
 
 	longhornv1beta2 "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/longhorn.io/v1beta2"
 )
@@ -47,6 +48,10 @@ func (c LonghornVolumeClient) Patch(namespace, name string, pt types.PatchType,
 	return c(namespace).Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
 }
 
+func (c LonghornVolumeClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.ClientInterface[*lhv1beta2.Volume, *lhv1beta2.VolumeList], error) {
+	panic("implement me")
+}
+
 type LonghornVolumeCache func(string) longhornv1beta2.VolumeInterface
 
 func (c LonghornVolumeCache) Get(namespace, name string) (*lhv1beta2.Volume, error) {
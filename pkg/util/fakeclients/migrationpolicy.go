@@ -0,0 +1,79 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
+
+	migrationstype "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/migrations.kubevirt.io/v1alpha1"
+)
+
+type MigrationPolicyClient func() migrationstype.MigrationPolicyInterface
+
+func (c MigrationPolicyClient) Create(p *migrationsv1alpha1.MigrationPolicy) (*migrationsv1alpha1.MigrationPolicy, error) {
+	return c().Create(context.TODO(), p, metav1.CreateOptions{})
+}
+
+func (c MigrationPolicyClient) Update(p *migrationsv1alpha1.MigrationPolicy) (*migrationsv1alpha1.MigrationPolicy, error) {
+	return c().Update(context.TODO(), p, metav1.UpdateOptions{})
+}
+
+func (c MigrationPolicyClient) UpdateStatus(_ *migrationsv1alpha1.MigrationPolicy) (*migrationsv1alpha1.MigrationPolicy, error) {
+	panic("implement me")
+}
+
+func (c MigrationPolicyClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return c().Delete(context.TODO(), name, *options)
+}
+
+func (c MigrationPolicyClient) Get(name string, options metav1.GetOptions) (*migrationsv1alpha1.MigrationPolicy, error) {
+	return c().Get(context.TODO(), name, options)
+}
+
+func (c MigrationPolicyClient) List(opts metav1.ListOptions) (*migrationsv1alpha1.MigrationPolicyList, error) {
+	return c().List(context.TODO(), opts)
+}
+
+func (c MigrationPolicyClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c().Watch(context.TODO(), opts)
+}
+
+func (c MigrationPolicyClient) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *migrationsv1alpha1.MigrationPolicy, err error) {
+	return c().Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+func (c MigrationPolicyClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.NonNamespacedClientInterface[*migrationsv1alpha1.MigrationPolicy, *migrationsv1alpha1.MigrationPolicyList], error) {
+	panic("implement me")
+}
+
+type MigrationPolicyCache func() migrationstype.MigrationPolicyInterface
+
+func (c MigrationPolicyCache) Get(name string) (*migrationsv1alpha1.MigrationPolicy, error) {
+	return c().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c MigrationPolicyCache) List(selector labels.Selector) ([]*migrationsv1alpha1.MigrationPolicy, error) {
+	list, err := c().List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*migrationsv1alpha1.MigrationPolicy, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+
+func (c MigrationPolicyCache) AddIndexer(_ string, _ generic.Indexer[*migrationsv1alpha1.MigrationPolicy]) {
+	panic("implement me")
+}
+
+func (c MigrationPolicyCache) GetByIndex(_, _ string) ([]*migrationsv1alpha1.MigrationPolicy, error) {
+	panic("implement me")
+}
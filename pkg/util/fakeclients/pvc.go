@@ -12,6 +12,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	corev1type "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/v1"
+	"github.com/harvester/harvester/pkg/indexeres"
 )
 
 type PersistentVolumeClaimClient func(string) corev1type.PersistentVolumeClaimInterface
@@ -76,6 +77,21 @@ func (c PersistentVolumeClaimCache) AddIndexer(_ string, _ generic.Indexer[*core
 	panic("implement me")
 }
 
-func (c PersistentVolumeClaimCache) GetByIndex(_, _ string) ([]*corev1.PersistentVolumeClaim, error) {
-	panic("implement me")
+func (c PersistentVolumeClaimCache) GetByIndex(indexName, key string) ([]*corev1.PersistentVolumeClaim, error) {
+	switch indexName {
+	case indexeres.PVCByImageIDIndex:
+		list, err := c(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var pvcs []*corev1.PersistentVolumeClaim
+		for i := range list.Items {
+			if imageIDs, _ := indexeres.PVCByImageID(&list.Items[i]); len(imageIDs) > 0 && imageIDs[0] == key {
+				pvcs = append(pvcs, &list.Items[i])
+			}
+		}
+		return pvcs, nil
+	default:
+		panic("implement me")
+	}
 }
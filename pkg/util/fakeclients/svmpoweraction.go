@@ -0,0 +1,79 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	harvesterv1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harvestertype "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+)
+
+type SVMPowerActionClient func(string) harvestertype.ScheduleVMPowerActionInterface
+
+func (c SVMPowerActionClient) Create(svmPowerAction *harvesterv1beta1.ScheduleVMPowerAction) (*harvesterv1beta1.ScheduleVMPowerAction, error) {
+	return c(svmPowerAction.Namespace).Create(context.TODO(), svmPowerAction, metav1.CreateOptions{})
+}
+
+func (c SVMPowerActionClient) Update(svmPowerAction *harvesterv1beta1.ScheduleVMPowerAction) (*harvesterv1beta1.ScheduleVMPowerAction, error) {
+	return c(svmPowerAction.Namespace).Update(context.TODO(), svmPowerAction, metav1.UpdateOptions{})
+}
+
+func (c SVMPowerActionClient) UpdateStatus(_ *harvesterv1beta1.ScheduleVMPowerAction) (*harvesterv1beta1.ScheduleVMPowerAction, error) {
+	panic("implement me")
+}
+
+func (c SVMPowerActionClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
+}
+
+func (c SVMPowerActionClient) Get(namespace, name string, options metav1.GetOptions) (*harvesterv1beta1.ScheduleVMPowerAction, error) {
+	return c(namespace).Get(context.TODO(), name, options)
+}
+
+func (c SVMPowerActionClient) List(namespace string, opts metav1.ListOptions) (*harvesterv1beta1.ScheduleVMPowerActionList, error) {
+	return c(namespace).List(context.TODO(), opts)
+}
+
+func (c SVMPowerActionClient) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c(namespace).Watch(context.TODO(), opts)
+}
+
+func (c SVMPowerActionClient) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (result *harvesterv1beta1.ScheduleVMPowerAction, err error) {
+	return c(namespace).Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+func (c SVMPowerActionClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.ClientInterface[*harvesterv1beta1.ScheduleVMPowerAction, *harvesterv1beta1.ScheduleVMPowerActionList], error) {
+	panic("implement me")
+}
+
+type SVMPowerActionCache func(string) harvestertype.ScheduleVMPowerActionInterface
+
+func (c SVMPowerActionCache) Get(namespace, name string) (*harvesterv1beta1.ScheduleVMPowerAction, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c SVMPowerActionCache) List(namespace string, selector labels.Selector) ([]*harvesterv1beta1.ScheduleVMPowerAction, error) {
+	list, err := c(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*harvesterv1beta1.ScheduleVMPowerAction, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+
+func (c SVMPowerActionCache) AddIndexer(_ string, _ generic.Indexer[*harvesterv1beta1.ScheduleVMPowerAction]) {
+	panic("implement me")
+}
+
+func (c SVMPowerActionCache) GetByIndex(_, _ string) ([]*harvesterv1beta1.ScheduleVMPowerAction, error) {
+	panic("implement me")
+}
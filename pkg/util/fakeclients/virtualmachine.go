@@ -32,8 +32,8 @@ func (c VirtualMachineClient) Create(virtualMachine *kubevirtv1api.VirtualMachin
 	return c(virtualMachine.Namespace).Create(context.TODO(), virtualMachine, metav1.CreateOptions{})
 }
 
-func (c VirtualMachineClient) Delete(_, _ string, _ *metav1.DeleteOptions) error {
-	panic("implement me")
+func (c VirtualMachineClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
 }
 
 func (c VirtualMachineClient) List(_ string, _ metav1.ListOptions) (*kubevirtv1api.VirtualMachineList, error) {
@@ -85,7 +85,7 @@ func (c VirtualMachineClient) Enqueue(_, _ string) {
 }
 
 func (c VirtualMachineClient) EnqueueAfter(_, _ string, _ time.Duration) {
-	panic("implement me")
+	// do nothing
 }
 
 func (c VirtualMachineClient) Cache() generic.CacheInterface[*kubevirtv1api.VirtualMachine] {
@@ -0,0 +1,52 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	harvesterv1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harvestertype "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+)
+
+type VMBulkActionClient func(string) harvestertype.VirtualMachineBulkActionInterface
+
+func (c VMBulkActionClient) Create(bulkAction *harvesterv1beta1.VirtualMachineBulkAction) (*harvesterv1beta1.VirtualMachineBulkAction, error) {
+	return c(bulkAction.Namespace).Create(context.TODO(), bulkAction, metav1.CreateOptions{})
+}
+
+func (c VMBulkActionClient) Update(bulkAction *harvesterv1beta1.VirtualMachineBulkAction) (*harvesterv1beta1.VirtualMachineBulkAction, error) {
+	return c(bulkAction.Namespace).Update(context.TODO(), bulkAction, metav1.UpdateOptions{})
+}
+
+func (c VMBulkActionClient) UpdateStatus(_ *harvesterv1beta1.VirtualMachineBulkAction) (*harvesterv1beta1.VirtualMachineBulkAction, error) {
+	panic("implement me")
+}
+
+func (c VMBulkActionClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
+}
+
+func (c VMBulkActionClient) Get(namespace, name string, options metav1.GetOptions) (*harvesterv1beta1.VirtualMachineBulkAction, error) {
+	return c(namespace).Get(context.TODO(), name, options)
+}
+
+func (c VMBulkActionClient) List(namespace string, opts metav1.ListOptions) (*harvesterv1beta1.VirtualMachineBulkActionList, error) {
+	return c(namespace).List(context.TODO(), opts)
+}
+
+func (c VMBulkActionClient) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c(namespace).Watch(context.TODO(), opts)
+}
+
+func (c VMBulkActionClient) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (result *harvesterv1beta1.VirtualMachineBulkAction, err error) {
+	return c(namespace).Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+func (c VMBulkActionClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.ClientInterface[*harvesterv1beta1.VirtualMachineBulkAction, *harvesterv1beta1.VirtualMachineBulkActionList], error) {
+	panic("implement me")
+}
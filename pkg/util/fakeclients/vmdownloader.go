@@ -0,0 +1,79 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	harvesterv1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harvestertype "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+)
+
+type VirtualMachineDownloaderClient func(string) harvestertype.VirtualMachineDownloaderInterface
+
+func (c VirtualMachineDownloaderClient) Create(downloader *harvesterv1beta1.VirtualMachineDownloader) (*harvesterv1beta1.VirtualMachineDownloader, error) {
+	return c(downloader.Namespace).Create(context.TODO(), downloader, metav1.CreateOptions{})
+}
+
+func (c VirtualMachineDownloaderClient) Update(downloader *harvesterv1beta1.VirtualMachineDownloader) (*harvesterv1beta1.VirtualMachineDownloader, error) {
+	return c(downloader.Namespace).Update(context.TODO(), downloader, metav1.UpdateOptions{})
+}
+
+func (c VirtualMachineDownloaderClient) UpdateStatus(downloader *harvesterv1beta1.VirtualMachineDownloader) (*harvesterv1beta1.VirtualMachineDownloader, error) {
+	return c(downloader.Namespace).UpdateStatus(context.TODO(), downloader, metav1.UpdateOptions{})
+}
+
+func (c VirtualMachineDownloaderClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
+}
+
+func (c VirtualMachineDownloaderClient) Get(namespace, name string, options metav1.GetOptions) (*harvesterv1beta1.VirtualMachineDownloader, error) {
+	return c(namespace).Get(context.TODO(), name, options)
+}
+
+func (c VirtualMachineDownloaderClient) List(namespace string, opts metav1.ListOptions) (*harvesterv1beta1.VirtualMachineDownloaderList, error) {
+	return c(namespace).List(context.TODO(), opts)
+}
+
+func (c VirtualMachineDownloaderClient) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c(namespace).Watch(context.TODO(), opts)
+}
+
+func (c VirtualMachineDownloaderClient) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (result *harvesterv1beta1.VirtualMachineDownloader, err error) {
+	return c(namespace).Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+func (c VirtualMachineDownloaderClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.ClientInterface[*harvesterv1beta1.VirtualMachineDownloader, *harvesterv1beta1.VirtualMachineDownloaderList], error) {
+	panic("implement me")
+}
+
+type VirtualMachineDownloaderCache func(string) harvestertype.VirtualMachineDownloaderInterface
+
+func (c VirtualMachineDownloaderCache) Get(namespace, name string) (*harvesterv1beta1.VirtualMachineDownloader, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c VirtualMachineDownloaderCache) List(namespace string, selector labels.Selector) ([]*harvesterv1beta1.VirtualMachineDownloader, error) {
+	list, err := c(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*harvesterv1beta1.VirtualMachineDownloader, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+
+func (c VirtualMachineDownloaderCache) AddIndexer(_ string, _ generic.Indexer[*harvesterv1beta1.VirtualMachineDownloader]) {
+	panic("implement me")
+}
+
+func (c VirtualMachineDownloaderCache) GetByIndex(_, _ string) ([]*harvesterv1beta1.VirtualMachineDownloader, error) {
+	panic("implement me")
+}
@@ -0,0 +1,79 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	harvesterv1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harvestertype "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+)
+
+type VirtualMachineImageDownloaderClient func(string) harvestertype.VirtualMachineImageDownloaderInterface
+
+func (c VirtualMachineImageDownloaderClient) Create(downloader *harvesterv1beta1.VirtualMachineImageDownloader) (*harvesterv1beta1.VirtualMachineImageDownloader, error) {
+	return c(downloader.Namespace).Create(context.TODO(), downloader, metav1.CreateOptions{})
+}
+
+func (c VirtualMachineImageDownloaderClient) Update(downloader *harvesterv1beta1.VirtualMachineImageDownloader) (*harvesterv1beta1.VirtualMachineImageDownloader, error) {
+	return c(downloader.Namespace).Update(context.TODO(), downloader, metav1.UpdateOptions{})
+}
+
+func (c VirtualMachineImageDownloaderClient) UpdateStatus(downloader *harvesterv1beta1.VirtualMachineImageDownloader) (*harvesterv1beta1.VirtualMachineImageDownloader, error) {
+	return c(downloader.Namespace).UpdateStatus(context.TODO(), downloader, metav1.UpdateOptions{})
+}
+
+func (c VirtualMachineImageDownloaderClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
+}
+
+func (c VirtualMachineImageDownloaderClient) Get(namespace, name string, options metav1.GetOptions) (*harvesterv1beta1.VirtualMachineImageDownloader, error) {
+	return c(namespace).Get(context.TODO(), name, options)
+}
+
+func (c VirtualMachineImageDownloaderClient) List(namespace string, opts metav1.ListOptions) (*harvesterv1beta1.VirtualMachineImageDownloaderList, error) {
+	return c(namespace).List(context.TODO(), opts)
+}
+
+func (c VirtualMachineImageDownloaderClient) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c(namespace).Watch(context.TODO(), opts)
+}
+
+func (c VirtualMachineImageDownloaderClient) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (result *harvesterv1beta1.VirtualMachineImageDownloader, err error) {
+	return c(namespace).Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+func (c VirtualMachineImageDownloaderClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.ClientInterface[*harvesterv1beta1.VirtualMachineImageDownloader, *harvesterv1beta1.VirtualMachineImageDownloaderList], error) {
+	panic("implement me")
+}
+
+type VirtualMachineImageDownloaderCache func(string) harvestertype.VirtualMachineImageDownloaderInterface
+
+func (c VirtualMachineImageDownloaderCache) Get(namespace, name string) (*harvesterv1beta1.VirtualMachineImageDownloader, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c VirtualMachineImageDownloaderCache) List(namespace string, selector labels.Selector) ([]*harvesterv1beta1.VirtualMachineImageDownloader, error) {
+	list, err := c(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*harvesterv1beta1.VirtualMachineImageDownloader, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+
+func (c VirtualMachineImageDownloaderCache) AddIndexer(_ string, _ generic.Indexer[*harvesterv1beta1.VirtualMachineImageDownloader]) {
+	panic("implement me")
+}
+
+func (c VirtualMachineImageDownloaderCache) GetByIndex(_, _ string) ([]*harvesterv1beta1.VirtualMachineImageDownloader, error) {
+	panic("implement me")
+}
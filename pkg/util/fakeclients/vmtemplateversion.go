@@ -0,0 +1,55 @@
+package fakeclients
+
+import (
+	"context"
+	"slices"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	harvesterv1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harvestertype "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/indexeres"
+)
+
+type VirtualMachineTemplateVersionCache func(string) harvestertype.VirtualMachineTemplateVersionInterface
+
+func (c VirtualMachineTemplateVersionCache) Get(namespace, name string) (*harvesterv1beta1.VirtualMachineTemplateVersion, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c VirtualMachineTemplateVersionCache) List(namespace string, selector labels.Selector) ([]*harvesterv1beta1.VirtualMachineTemplateVersion, error) {
+	list, err := c(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*harvesterv1beta1.VirtualMachineTemplateVersion, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+
+func (c VirtualMachineTemplateVersionCache) AddIndexer(_ string, _ generic.Indexer[*harvesterv1beta1.VirtualMachineTemplateVersion]) {
+	panic("implement me")
+}
+
+func (c VirtualMachineTemplateVersionCache) GetByIndex(indexName, key string) ([]*harvesterv1beta1.VirtualMachineTemplateVersion, error) {
+	switch indexName {
+	case indexeres.VMTemplateVersionByImageIDIndex:
+		list, err := c(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var templateVersions []*harvesterv1beta1.VirtualMachineTemplateVersion
+		for i := range list.Items {
+			if imageIDs, _ := indexeres.VMTemplateVersionByImageID(&list.Items[i]); slices.Contains(imageIDs, key) {
+				templateVersions = append(templateVersions, &list.Items[i])
+			}
+		}
+		return templateVersions, nil
+	default:
+		panic("implement me")
+	}
+}
@@ -0,0 +1,81 @@
+package fakeclients
+
+import (
+	"context"
+
+	snapshotv1type "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/snapshot.storage.k8s.io/v1"
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+)
+
+type VolumeSnapshotContentClient func() snapshotv1type.VolumeSnapshotContentInterface
+
+func (c VolumeSnapshotContentClient) Create(obj *snapshotv1.VolumeSnapshotContent) (*snapshotv1.VolumeSnapshotContent, error) {
+	// the apiserver drops the namespace of cluster-scoped objects, the fake clientset rejects it instead
+	obj = obj.DeepCopy()
+	obj.Namespace = ""
+	return c().Create(context.TODO(), obj, metav1.CreateOptions{})
+}
+
+func (c VolumeSnapshotContentClient) Update(obj *snapshotv1.VolumeSnapshotContent) (*snapshotv1.VolumeSnapshotContent, error) {
+	return c().Update(context.TODO(), obj, metav1.UpdateOptions{})
+}
+
+func (c VolumeSnapshotContentClient) UpdateStatus(_ *snapshotv1.VolumeSnapshotContent) (*snapshotv1.VolumeSnapshotContent, error) {
+	panic("implement me")
+}
+
+func (c VolumeSnapshotContentClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return c().Delete(context.TODO(), name, *options)
+}
+
+func (c VolumeSnapshotContentClient) Get(name string, options metav1.GetOptions) (*snapshotv1.VolumeSnapshotContent, error) {
+	return c().Get(context.TODO(), name, options)
+}
+
+func (c VolumeSnapshotContentClient) List(opts metav1.ListOptions) (*snapshotv1.VolumeSnapshotContentList, error) {
+	return c().List(context.TODO(), opts)
+}
+
+func (c VolumeSnapshotContentClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c().Watch(context.TODO(), opts)
+}
+
+func (c VolumeSnapshotContentClient) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *snapshotv1.VolumeSnapshotContent, err error) {
+	return c().Patch(context.TODO(), name, pt, data, metav1.PatchOptions{}, subresources...)
+}
+
+type VolumeSnapshotContentCache func() snapshotv1type.VolumeSnapshotContentInterface
+
+func (c VolumeSnapshotContentCache) Get(name string) (*snapshotv1.VolumeSnapshotContent, error) {
+	return c().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c VolumeSnapshotContentCache) List(selector labels.Selector) ([]*snapshotv1.VolumeSnapshotContent, error) {
+	list, err := c().List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*snapshotv1.VolumeSnapshotContent, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+
+func (c VolumeSnapshotContentClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.NonNamespacedClientInterface[*snapshotv1.VolumeSnapshotContent, *snapshotv1.VolumeSnapshotContentList], error) {
+	panic("implement me")
+}
+
+func (c VolumeSnapshotContentCache) AddIndexer(_ string, _ generic.Indexer[*snapshotv1.VolumeSnapshotContent]) {
+	panic("implement me")
+}
+
+func (c VolumeSnapshotContentCache) GetByIndex(indexName, key string) ([]*snapshotv1.VolumeSnapshotContent, error) {
+	panic("implement me")
+}
@@ -0,0 +1,238 @@
+package ovf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// The OVF 1.x envelope, the namespace prefixes are written as they are since encoding/xml
+// can't marshal the prefixes the OVF tools expect.
+const (
+	envelopeNamespace = "http://schemas.dmtf.org/ovf/envelope/1"
+	rasdNamespace     = "http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData"
+	vssdNamespace     = "http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_VirtualSystemSettingData"
+
+	// QCOW2Format is the disk format URI of qcow2 used by the OVF tools, e.g. virt-v2v
+	QCOW2Format = "http://www.gnome.org/~markmc/qcow-image-format.html"
+
+	resourceTypeCPU      = 3
+	resourceTypeMemory   = 4
+	resourceTypeNIC      = 10
+	resourceTypeCDROM    = 15
+	resourceTypeDisk     = 17
+	virtualSystemTypeKVM = "kvm"
+)
+
+// Disk is a disk of the VM in the package
+type Disk struct {
+	// ID is the name of the volume in the VM spec
+	ID       string
+	FileName string
+	// Capacity is the virtual size of the disk in bytes
+	Capacity int64
+	CDROM    bool
+}
+
+type envelope struct {
+	XMLName        xml.Name       `xml:"Envelope"`
+	Xmlns          string         `xml:"xmlns,attr"`
+	XmlnsOVF       string         `xml:"xmlns:ovf,attr"`
+	XmlnsRASD      string         `xml:"xmlns:rasd,attr"`
+	XmlnsVSSD      string         `xml:"xmlns:vssd,attr"`
+	References     []file         `xml:"References>File"`
+	DiskSection    diskSection    `xml:"DiskSection"`
+	NetworkSection networkSection `xml:"NetworkSection"`
+	VirtualSystem  virtualSystem  `xml:"VirtualSystem"`
+}
+
+type file struct {
+	ID   string `xml:"ovf:id,attr"`
+	Href string `xml:"ovf:href,attr"`
+}
+
+type diskSection struct {
+	Info  string     `xml:"Info"`
+	Disks []diskInfo `xml:"Disk"`
+}
+
+type diskInfo struct {
+	DiskID   string `xml:"ovf:diskId,attr"`
+	FileRef  string `xml:"ovf:fileRef,attr"`
+	Capacity int64  `xml:"ovf:capacity,attr"`
+	Format   string `xml:"ovf:format,attr"`
+}
+
+type networkSection struct {
+	Info     string    `xml:"Info"`
+	Networks []network `xml:"Network"`
+}
+
+type network struct {
+	Name        string `xml:"ovf:name,attr"`
+	Description string `xml:"Description"`
+}
+
+type virtualSystem struct {
+	ID                     string                 `xml:"ovf:id,attr"`
+	Info                   string                 `xml:"Info"`
+	Name                   string                 `xml:"Name"`
+	VirtualHardwareSection virtualHardwareSection `xml:"VirtualHardwareSection"`
+}
+
+type virtualHardwareSection struct {
+	Info   string `xml:"Info"`
+	System system `xml:"System"`
+	Items  []item `xml:"Item"`
+}
+
+type system struct {
+	ElementName       string `xml:"vssd:ElementName"`
+	InstanceID        int    `xml:"vssd:InstanceID"`
+	VirtualSystemType string `xml:"vssd:VirtualSystemType"`
+}
+
+type item struct {
+	Address         string `xml:"rasd:Address,omitempty"`
+	AllocationUnits string `xml:"rasd:AllocationUnits,omitempty"`
+	Connection      string `xml:"rasd:Connection,omitempty"`
+	ElementName     string `xml:"rasd:ElementName"`
+	HostResource    string `xml:"rasd:HostResource,omitempty"`
+	InstanceID      int    `xml:"rasd:InstanceID"`
+	ResourceSubType string `xml:"rasd:ResourceSubType,omitempty"`
+	ResourceType    int    `xml:"rasd:ResourceType"`
+	VirtualQuantity int64  `xml:"rasd:VirtualQuantity,omitempty"`
+}
+
+// Build renders the OVF descriptor of the VM with the disks in the package.
+// The extra files, e.g. the cloud-init data, are listed in the references so they're kept in the package.
+func Build(vm *kubevirtv1.VirtualMachine, disks []Disk, extraFiles []string) ([]byte, error) {
+	e, err := newEnvelope(vm, disks, extraFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := xml.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func newEnvelope(vm *kubevirtv1.VirtualMachine, disks []Disk, extraFiles []string) (*envelope, error) {
+	if vm.Spec.Template == nil {
+		return nil, fmt.Errorf("vm %s/%s has no template", vm.Namespace, vm.Name)
+	}
+	spec := vm.Spec.Template.Spec
+
+	e := &envelope{
+		Xmlns:          envelopeNamespace,
+		XmlnsOVF:       envelopeNamespace,
+		XmlnsRASD:      rasdNamespace,
+		XmlnsVSSD:      vssdNamespace,
+		DiskSection:    diskSection{Info: "Virtual disk information"},
+		NetworkSection: networkSection{Info: "The list of logical networks"},
+		VirtualSystem: virtualSystem{
+			ID:   vm.Name,
+			Info: "A virtual machine exported from Harvester",
+			Name: vm.Name,
+			VirtualHardwareSection: virtualHardwareSection{
+				Info: "Virtual hardware requirements",
+				System: system{
+					ElementName:       "Virtual Hardware Family",
+					VirtualSystemType: virtualSystemTypeKVM,
+				},
+			},
+		},
+	}
+	hardware := &e.VirtualSystem.VirtualHardwareSection
+	instanceID := 0
+	addItem := func(i item) {
+		instanceID++
+		i.InstanceID = instanceID
+		hardware.Items = append(hardware.Items, i)
+	}
+
+	vcpus := int64(1)
+	if cpu := spec.Domain.CPU; cpu != nil {
+		vcpus = int64(max(cpu.Sockets, 1) * max(cpu.Cores, 1) * max(cpu.Threads, 1))
+	}
+	addItem(item{
+		AllocationUnits: "hertz * 10^6",
+		ElementName:     fmt.Sprintf("%d virtual CPU(s)", vcpus),
+		ResourceType:    resourceTypeCPU,
+		VirtualQuantity: vcpus,
+	})
+
+	var memory int64
+	if spec.Domain.Memory != nil && spec.Domain.Memory.Guest != nil {
+		memory = spec.Domain.Memory.Guest.Value()
+	} else if limit, ok := spec.Domain.Resources.Limits[corev1.ResourceMemory]; ok {
+		memory = limit.Value()
+	}
+	memoryMiB := memory / 1024 / 1024
+	addItem(item{
+		AllocationUnits: "byte * 2^20",
+		ElementName:     fmt.Sprintf("%dMB of memory", memoryMiB),
+		ResourceType:    resourceTypeMemory,
+		VirtualQuantity: memoryMiB,
+	})
+
+	for i, disk := range disks {
+		fileID := "file-" + disk.ID
+		e.References = append(e.References, file{ID: fileID, Href: disk.FileName})
+		e.DiskSection.Disks = append(e.DiskSection.Disks, diskInfo{
+			DiskID:   disk.ID,
+			FileRef:  fileID,
+			Capacity: disk.Capacity,
+			Format:   QCOW2Format,
+		})
+		resourceType := resourceTypeDisk
+		if disk.CDROM {
+			resourceType = resourceTypeCDROM
+		}
+		addItem(item{
+			Address:      strconv.Itoa(i),
+			ElementName:  disk.ID,
+			HostResource: "ovf:/disk/" + disk.ID,
+			ResourceType: resourceType,
+		})
+	}
+	for _, extraFile := range extraFiles {
+		e.References = append(e.References, file{ID: "file-" + extraFile, Href: extraFile})
+	}
+
+	networks, addedNetworks := map[string]string{}, map[string]bool{}
+	for _, n := range spec.Networks {
+		switch {
+		case n.Multus != nil:
+			networks[n.Name] = n.Multus.NetworkName
+		case n.Pod != nil:
+			networks[n.Name] = "pod"
+		}
+	}
+	for _, iface := range spec.Domain.Devices.Interfaces {
+		networkName, ok := networks[iface.Name]
+		if !ok {
+			continue
+		}
+		if !addedNetworks[networkName] {
+			addedNetworks[networkName] = true
+			e.NetworkSection.Networks = append(e.NetworkSection.Networks, network{
+				Name:        networkName,
+				Description: fmt.Sprintf("The %s network", networkName),
+			})
+		}
+		addItem(item{
+			Address:         iface.MacAddress,
+			Connection:      networkName,
+			ElementName:     iface.Name,
+			ResourceSubType: iface.Model,
+			ResourceType:    resourceTypeNIC,
+		})
+	}
+	return e, nil
+}
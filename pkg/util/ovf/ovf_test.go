@@ -0,0 +1,72 @@
+package ovf
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+func TestBuild(t *testing.T) {
+	guest := resource.MustParse("4Gi")
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Domain: kubevirtv1.DomainSpec{
+						CPU:    &kubevirtv1.CPU{Sockets: 1, Cores: 2, Threads: 1},
+						Memory: &kubevirtv1.Memory{Guest: &guest},
+						Devices: kubevirtv1.Devices{
+							Interfaces: []kubevirtv1.Interface{
+								{Name: "default", Model: "virtio", MacAddress: "52:54:00:00:00:01"},
+							},
+						},
+					},
+					Networks: []kubevirtv1.Network{
+						{
+							Name: "default",
+							NetworkSource: kubevirtv1.NetworkSource{
+								Multus: &kubevirtv1.MultusNetwork{NetworkName: "default/vlan1"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	disks := []Disk{
+		{ID: "rootdisk", FileName: "rootdisk.qcow2", Capacity: 10 * 1024 * 1024 * 1024},
+		{ID: "cdrom", FileName: "cdrom.qcow2", Capacity: 1024, CDROM: true},
+	}
+
+	e, err := newEnvelope(vm, disks, []string{"user-data"})
+	assert.NoError(t, err)
+	assert.Equal(t, []file{
+		{ID: "file-rootdisk", Href: "rootdisk.qcow2"},
+		{ID: "file-cdrom", Href: "cdrom.qcow2"},
+		{ID: "file-user-data", Href: "user-data"},
+	}, e.References)
+	assert.Equal(t, diskInfo{DiskID: "rootdisk", FileRef: "file-rootdisk", Capacity: 10 * 1024 * 1024 * 1024, Format: QCOW2Format}, e.DiskSection.Disks[0])
+	assert.Equal(t, "vm", e.VirtualSystem.Name)
+	assert.Equal(t, []network{{Name: "default/vlan1", Description: "The default/vlan1 network"}}, e.NetworkSection.Networks)
+
+	items := e.VirtualSystem.VirtualHardwareSection.Items
+	assert.Len(t, items, 5)
+	assert.Equal(t, item{AllocationUnits: "hertz * 10^6", ElementName: "2 virtual CPU(s)", InstanceID: 1, ResourceType: resourceTypeCPU, VirtualQuantity: 2}, items[0])
+	assert.Equal(t, int64(4096), items[1].VirtualQuantity)
+	assert.Equal(t, "ovf:/disk/rootdisk", items[2].HostResource)
+	assert.Equal(t, resourceTypeDisk, items[2].ResourceType)
+	assert.Equal(t, resourceTypeCDROM, items[3].ResourceType)
+	assert.Equal(t, item{Address: "52:54:00:00:00:01", Connection: "default/vlan1", ElementName: "default", InstanceID: 5, ResourceSubType: "virtio", ResourceType: resourceTypeNIC}, items[4])
+
+	// the prefixes of the OVF namespaces are kept
+	data, err := Build(vm, disks, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `<File ovf:id="file-rootdisk" ovf:href="rootdisk.qcow2"></File>`)
+	assert.Contains(t, string(data), `<rasd:HostResource>ovf:/disk/rootdisk</rasd:HostResource>`)
+	assert.NoError(t, xml.Unmarshal(data, &struct{}{}))
+}
@@ -0,0 +1,250 @@
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// The keys of the registry secret, the username and password keys are the ones CDI expects
+	SecretUsernameKey = "accessKeyId"
+	SecretPasswordKey = "secretKey"
+	SecretCAKey       = "ca.crt"
+
+	dockerManifestMediaType     = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// maxManifestSize is the size limit of the manifests, the same as containerd's
+	maxManifestSize = 4 << 20
+)
+
+var manifestMediaTypes = []string{
+	ocispec.MediaTypeImageManifest,
+	ocispec.MediaTypeImageIndex,
+	dockerManifestMediaType,
+	dockerManifestListMediaType,
+}
+
+// Credentials are used to pull the images from a registry
+type Credentials struct {
+	Username           string
+	Password           string
+	CACert             []byte
+	InsecureSkipVerify bool
+}
+
+// Client pulls container disk images with the registry API
+type Client struct {
+	httpClient  *http.Client
+	credentials Credentials
+	// authorization is the Authorization header answering the last challenge of the registry
+	authorization string
+}
+
+func NewClient(credentials *Credentials) (*Client, error) {
+	c := &Client{httpClient: &http.Client{}}
+	if credentials == nil {
+		return c, nil
+	}
+	c.credentials = *credentials
+
+	if len(credentials.CACert) == 0 && !credentials.InsecureSkipVerify {
+		return c, nil
+	}
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if len(credentials.CACert) > 0 && !rootCAs.AppendCertsFromPEM(credentials.CACert) {
+		return nil, errors.New("failed to parse the CA certificate of the registry")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:            rootCAs,
+		InsecureSkipVerify: credentials.InsecureSkipVerify, //nolint:gosec
+	}
+	c.httpClient.Transport = transport
+	return c, nil
+}
+
+// get sends the GET request to the registry API, it answers the authentication challenge of the registry once
+func (c *Client) get(ctx context.Context, ref *Reference, path string, accept []string) (*http.Response, error) {
+	apiURL := fmt.Sprintf("https://%s/v2/%s/%s", ref.Host(), ref.Repository, path)
+	resp, err := c.doGet(ctx, apiURL, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := c.authenticate(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = c.doGet(ctx, apiURL, accept); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s: %s", apiURL, resp.Status)
+	}
+	return resp, nil
+}
+
+func (c *Client) doGet(ctx context.Context, apiURL string, accept []string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	return c.httpClient.Do(req)
+}
+
+// authenticate answers the basic or bearer challenge of the registry
+func (c *Client) authenticate(ctx context.Context, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.credentials.Username == "" {
+			return errors.New("the registry requires credentials")
+		}
+		auth := c.credentials.Username + ":" + c.credentials.Password
+		c.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
+		return nil
+	case "bearer":
+		token, err := c.getToken(ctx, params)
+		if err != nil {
+			return err
+		}
+		c.authorization = "Bearer " + token
+		return nil
+	default:
+		return fmt.Errorf("unsupported authentication challenge %q of the registry", challenge)
+	}
+}
+
+func (c *Client) getToken(ctx context.Context, params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid realm %q of the registry", params["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.credentials.Username != "" {
+		req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get the token of the registry: %s", resp.Status)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode the token of the registry: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// parseChallenge parses the WWW-Authenticate header, e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = strings.TrimPrefix(strings.TrimSpace(value[end+2:]), ",")
+			continue
+		}
+		params[key], rest, _ = strings.Cut(value, ",")
+	}
+	return scheme, params
+}
+
+// getManifest returns the manifest of the image, the platform of the node is picked from the image index.
+// The manifest is verified against the digest if it's referenced by the digest.
+func (c *Client) getManifest(ctx context.Context, ref *Reference) (*ocispec.Manifest, error) {
+	resp, err := c.get(ctx, ref, "manifests/"+ref.Reference, manifestMediaTypes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the manifest of %s: %w", ref, err)
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("manifest of %s exceeds %d bytes", ref, maxManifestSize)
+	}
+	if expected, err := digest.Parse(ref.Reference); err == nil && expected.Algorithm().FromBytes(data) != expected {
+		return nil, fmt.Errorf("manifest of %s doesn't match its digest", ref)
+	}
+
+	manifest := struct {
+		ocispec.Manifest
+		Manifests []ocispec.Descriptor `json:"manifests,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode the manifest of %s: %w", ref, err)
+	}
+	if len(manifest.Manifests) == 0 {
+		return &manifest.Manifest, nil
+	}
+
+	for _, desc := range manifest.Manifests {
+		if desc.Platform == nil || (desc.Platform.OS == "linux" && desc.Platform.Architecture == runtime.GOARCH) {
+			platformRef := *ref
+			platformRef.Reference = desc.Digest.String()
+			return c.getManifest(ctx, &platformRef)
+		}
+	}
+	return nil, fmt.Errorf("image %s has no manifest for linux/%s", ref, runtime.GOARCH)
+}
@@ -0,0 +1,182 @@
+package registry
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// diskDir is the directory of the disk in a container disk image
+	diskDir = "disk/"
+
+	qcow2Magic      = "QFI\xfb"
+	qcow2HeaderSize = 32
+)
+
+var ErrDiskNotFound = errors.New("no disk is found in the container disk image")
+
+// Disk is the disk file in a container disk image, reading it streams the layer from the registry.
+// The layer is verified against its digest once the disk is read to the end, Read returns the error
+// instead of io.EOF if it mismatches.
+type Disk struct {
+	io.Reader
+	Name string
+	// Size is the size of the disk file
+	Size int64
+
+	body  io.Closer
+	layer *verifiedLayer
+}
+
+func (d *Disk) Read(p []byte) (int, error) {
+	n, err := d.Reader.Read(p)
+	if err == io.EOF && d.layer != nil {
+		if verifyErr := d.layer.verify(); verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return n, err
+}
+
+func (d *Disk) Close() error {
+	return d.body.Close()
+}
+
+// verifiedLayer hashes the layer blob while it's read
+type verifiedLayer struct {
+	io.Reader
+	digest   digest.Digest
+	verifier digest.Verifier
+	err      error
+	verified bool
+}
+
+func newVerifiedLayer(body io.Reader, layer ocispec.Descriptor) (*verifiedLayer, error) {
+	if err := layer.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid digest %q of the layer: %w", layer.Digest, err)
+	}
+	if layer.Size > 0 {
+		body = io.LimitReader(body, layer.Size)
+	}
+	verifier := layer.Digest.Verifier()
+	return &verifiedLayer{
+		Reader:   io.TeeReader(body, verifier),
+		digest:   layer.Digest,
+		verifier: verifier,
+	}, nil
+}
+
+// verify reads the rest of the layer after the disk and checks the digest of the whole layer
+func (l *verifiedLayer) verify() error {
+	if l.verified {
+		return l.err
+	}
+	l.verified = true
+	if _, err := io.Copy(io.Discard, l.Reader); err != nil {
+		l.err = fmt.Errorf("failed to read layer %s: %w", l.digest, err)
+	} else if !l.verifier.Verified() {
+		l.err = fmt.Errorf("layer %s doesn't match its digest", l.digest)
+	}
+	return l.err
+}
+
+// OpenDisk finds the disk of the container disk image from the top layer down, the image is expected
+// to have a single file in the /disk directory as KubeVirt expects.
+func (c *Client) OpenDisk(ctx context.Context, ref *Reference) (*Disk, error) {
+	manifest, err := c.getManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(manifest.Layers) - 1; i >= 0; i-- {
+		layer := manifest.Layers[i]
+		resp, err := c.get(ctx, ref, "blobs/"+layer.Digest.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		verified, err := newVerifiedLayer(resp.Body, layer)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+
+		disk, err := findDisk(verified, layer.MediaType)
+		if err != nil {
+			resp.Body.Close()
+			if errors.Is(err, ErrDiskNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read layer %s of %s: %w", layer.Digest, ref, err)
+		}
+		disk.body = resp.Body
+		disk.layer = verified
+		return disk, nil
+	}
+	return nil, ErrDiskNotFound
+}
+
+func findDisk(layer io.Reader, mediaType string) (*Disk, error) {
+	switch {
+	case strings.HasSuffix(mediaType, "gzip"):
+		gzipReader, err := gzip.NewReader(layer)
+		if err != nil {
+			return nil, err
+		}
+		layer = gzipReader
+	case strings.HasSuffix(mediaType, "zstd"):
+		return nil, fmt.Errorf("unsupported layer media type %s", mediaType)
+	}
+
+	tarReader := tar.NewReader(layer)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, ErrDiskNotFound
+		} else if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if header.Typeflag == tar.TypeReg && strings.HasPrefix(name, diskDir) {
+			return &Disk{Reader: tarReader, Name: path.Base(name), Size: header.Size}, nil
+		}
+	}
+}
+
+// Inspect returns the size and the virtual size of the disk in the container disk image,
+// the virtual size of a raw disk is its size.
+func (c *Client) Inspect(ctx context.Context, ref *Reference) (int64, int64, error) {
+	disk, err := c.OpenDisk(ctx, ref)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer disk.Close()
+
+	header := make([]byte, qcow2HeaderSize)
+	if _, err := io.ReadFull(disk, header); errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return disk.Size, disk.Size, nil
+	} else if err != nil {
+		return 0, 0, fmt.Errorf("failed to read the disk header of %s: %w", ref, err)
+	}
+	return disk.Size, virtualSize(header, disk.Size), nil
+}
+
+// virtualSize reads the virtual size from the qcow2 header,
+// REF: https://gitlab.com/qemu-project/qemu/-/blob/master/docs/interop/qcow2.txt
+func virtualSize(header []byte, size int64) int64 {
+	if len(header) < qcow2HeaderSize || string(header[0:4]) != qcow2Magic {
+		return size
+	}
+	return int64(binary.BigEndian.Uint64(header[24:32])) //nolint:gosec
+}
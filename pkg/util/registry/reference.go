@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+const (
+	// SourcePrefix is the scheme of the container disk image reference, it's the one CDI expects
+	SourcePrefix = "docker://"
+
+	dockerHubDomain   = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// Reference is the parsed reference of a container disk image
+type Reference struct {
+	// Domain is the domain of the image, it's the key of the registry configs in the containerd-registry setting
+	Domain     string
+	Repository string
+	// Reference is the digest or the tag of the image
+	Reference string
+}
+
+// ParseReference parses the image reference with or without the docker:// scheme,
+// the tag defaults to latest like the container runtimes.
+func ParseReference(url string) (*Reference, error) {
+	named, err := reference.ParseNormalizedNamed(strings.TrimPrefix(url, SourcePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %s: %w", url, err)
+	}
+
+	ref := &Reference{
+		Domain:     reference.Domain(named),
+		Repository: reference.Path(named),
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		ref.Reference = digested.Digest().String()
+	} else {
+		ref.Reference = reference.TagNameOnly(named).(reference.Tagged).Tag()
+	}
+	return ref, nil
+}
+
+// SourceURL returns the image reference with the docker:// scheme
+func SourceURL(url string) string {
+	return SourcePrefix + strings.TrimPrefix(url, SourcePrefix)
+}
+
+// Host is the host serving the registry API of the image
+func (r *Reference) Host() string {
+	if r.Domain == dockerHubDomain {
+		return dockerHubRegistry
+	}
+	return r.Domain
+}
+
+func (r *Reference) String() string {
+	if strings.Contains(r.Reference, ":") {
+		return fmt.Sprintf("%s/%s@%s", r.Domain, r.Repository, r.Reference)
+	}
+	return fmt.Sprintf("%s/%s:%s", r.Domain, r.Repository, r.Reference)
+}
@@ -0,0 +1,275 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		url         string
+		expected    *Reference
+		expectError bool
+	}{
+		{
+			url:      "docker://registry.example.com:5000/images/ubuntu:22.04",
+			expected: &Reference{Domain: "registry.example.com:5000", Repository: "images/ubuntu", Reference: "22.04"},
+		},
+		{
+			url:      "registry.example.com/images/ubuntu",
+			expected: &Reference{Domain: "registry.example.com", Repository: "images/ubuntu", Reference: "latest"},
+		},
+		{
+			url:      "docker://quay.io/containerdisks/fedora@sha256:" + strings.Repeat("a", 64),
+			expected: &Reference{Domain: "quay.io", Repository: "containerdisks/fedora", Reference: "sha256:" + strings.Repeat("a", 64)},
+		},
+		{
+			url:      "ubuntu",
+			expected: &Reference{Domain: "docker.io", Repository: "library/ubuntu", Reference: "latest"},
+		},
+		{
+			url:         "docker://Invalid/Image",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		ref, err := ParseReference(tc.url)
+		if tc.expectError {
+			assert.Error(t, err, tc.url)
+			continue
+		}
+		assert.NoError(t, err, tc.url)
+		assert.Equal(t, tc.expected, ref, tc.url)
+	}
+
+	ref, _ := ParseReference("ubuntu")
+	assert.Equal(t, "registry-1.docker.io", ref.Host())
+	assert.Equal(t, "docker://ubuntu", SourceURL("ubuntu"))
+	assert.Equal(t, "docker://ubuntu", SourceURL("docker://ubuntu"))
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:images/ubuntu:pull"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:images/ubuntu:pull",
+	}, params)
+
+	scheme, params = parseChallenge(`Basic realm=registry`)
+	assert.Equal(t, "Basic", scheme)
+	assert.Equal(t, map[string]string{"realm": "registry"}, params)
+}
+
+func newLayer(t *testing.T, files map[string][]byte) []byte {
+	buf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	return buf.Bytes()
+}
+
+func TestOpenDisk(t *testing.T) {
+	qcow2 := make([]byte, 64)
+	copy(qcow2, qcow2Magic)
+	binary.BigEndian.PutUint64(qcow2[24:32], 10*1024*1024*1024)
+
+	blobs := map[string][]byte{}
+	addBlob := func(data []byte) ocispec.Descriptor {
+		d := digest.FromBytes(data)
+		blobs[d.String()] = data
+		return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayerGzip, Digest: d, Size: int64(len(data))}
+	}
+	manifest, _ := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Layers: []ocispec.Descriptor{
+			addBlob(newLayer(t, map[string][]byte{"./disk/disk.qcow2": qcow2})),
+			addBlob(newLayer(t, map[string][]byte{"etc/os-release": []byte("ID=test")})),
+		},
+	})
+	manifestDigest := digest.FromBytes(manifest)
+	index, _ := json.Marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{{MediaType: ocispec.MediaTypeImageManifest, Digest: manifestDigest}},
+	})
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			username, password, _ := req.BasicAuth()
+			if username != "user" || password != "pass" || req.URL.Query().Get("scope") != "repository:images/ubuntu:pull" {
+				rw.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = rw.Write([]byte(`{"token":"secret-token"}`))
+			return
+		}
+		if req.Header.Get("Authorization") != "Bearer secret-token" {
+			rw.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:images/ubuntu:pull"`)
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case req.URL.Path == "/v2/images/ubuntu/manifests/22.04":
+			_, _ = rw.Write(index)
+		case req.URL.Path == "/v2/images/ubuntu/manifests/"+manifestDigest.String():
+			_, _ = rw.Write(manifest)
+		case strings.HasPrefix(req.URL.Path, "/v2/images/ubuntu/blobs/"):
+			blob, ok := blobs[strings.TrimPrefix(req.URL.Path, "/v2/images/ubuntu/blobs/")]
+			if !ok {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = rw.Write(blob)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	ref, err := ParseReference(SourcePrefix + strings.TrimPrefix(server.URL, "https://") + "/images/ubuntu:22.04")
+	require.NoError(t, err)
+
+	client, err := NewClient(&Credentials{Username: "user", Password: "pass", CACert: caCert})
+	require.NoError(t, err)
+	disk, err := client.OpenDisk(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, "disk.qcow2", disk.Name)
+	assert.Equal(t, int64(len(qcow2)), disk.Size)
+	data, err := io.ReadAll(disk)
+	assert.NoError(t, err)
+	assert.Equal(t, qcow2, data)
+	assert.NoError(t, disk.Close())
+
+	size, virtualSize, err := client.Inspect(context.Background(), ref)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(qcow2)), size)
+	assert.Equal(t, int64(10*1024*1024*1024), virtualSize)
+
+	// wrong credentials
+	client, err = NewClient(&Credentials{Username: "user", Password: "wrong", CACert: caCert})
+	require.NoError(t, err)
+	_, err = client.OpenDisk(context.Background(), ref)
+	assert.Error(t, err)
+
+	// the CA of the registry is unknown
+	client, err = NewClient(&Credentials{Username: "user", Password: "pass"})
+	require.NoError(t, err)
+	_, err = client.OpenDisk(context.Background(), ref)
+	assert.Error(t, err)
+}
+
+func TestOpenDiskDigestMismatch(t *testing.T) {
+	disk := []byte("raw disk")
+	layer := newLayer(t, map[string][]byte{"disk/disk.img": disk})
+	tampered := newLayer(t, map[string][]byte{"disk/disk.img": []byte("bad disk")})
+	layerDigest := digest.FromBytes(layer)
+	manifest, _ := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Layers:    []ocispec.Descriptor{{MediaType: ocispec.MediaTypeImageLayerGzip, Digest: layerDigest, Size: int64(len(layer))}},
+	})
+	manifestDigest := digest.FromBytes(manifest)
+
+	tests := []struct {
+		name           string
+		reference      string
+		manifest       []byte
+		blob           []byte
+		expectOpenErr  bool
+		expectReadErr  bool
+		expectDiskData []byte
+	}{
+		{
+			name:           "verified layer and manifest",
+			reference:      manifestDigest.String(),
+			manifest:       manifest,
+			blob:           layer,
+			expectDiskData: disk,
+		},
+		{
+			name:          "tampered layer",
+			reference:     manifestDigest.String(),
+			manifest:      manifest,
+			blob:          tampered,
+			expectReadErr: true,
+		},
+		{
+			name:          "tampered manifest",
+			reference:     manifestDigest.String(),
+			manifest:      append(manifest, ' '),
+			blob:          layer,
+			expectOpenErr: true,
+		},
+		{
+			name:          "tampered layer of the tagged image",
+			reference:     "latest",
+			manifest:      manifest,
+			blob:          tampered,
+			expectReadErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case "/v2/images/disk/manifests/" + tc.reference:
+					_, _ = rw.Write(tc.manifest)
+				case "/v2/images/disk/blobs/" + layerDigest.String():
+					_, _ = rw.Write(tc.blob)
+				default:
+					rw.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			separator := ":"
+			if strings.HasPrefix(tc.reference, "sha256:") {
+				separator = "@"
+			}
+			ref, err := ParseReference(SourcePrefix + strings.TrimPrefix(server.URL, "https://") + "/images/disk" + separator + tc.reference)
+			require.NoError(t, err)
+			client, err := NewClient(&Credentials{InsecureSkipVerify: true})
+			require.NoError(t, err)
+
+			disk, err := client.OpenDisk(context.Background(), ref)
+			if tc.expectOpenErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer disk.Close()
+			data, err := io.ReadAll(disk)
+			if tc.expectReadErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectDiskData, data)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package rightsizing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+const (
+	// launcherComputeContainer is the virt-launcher container running QEMU
+	launcherComputeContainer = "compute"
+
+	podMetricsPathFmt = "/apis/metrics.k8s.io/v1beta1/namespaces/%s/pods/%s"
+)
+
+// podMetrics is the part of the metrics.k8s.io/v1beta1 PodMetrics used to sample the usage
+type podMetrics struct {
+	Containers []struct {
+		Name  string              `json:"name"`
+		Usage corev1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+// GetLauncherUsage returns the usage of the compute container of the virt-launcher pod from the metrics API,
+// the sample is timestamped with now rather than the end of the metrics window to keep the interval steady.
+func GetLauncherUsage(ctx context.Context, restClient rest.Interface, vmi *kubevirtv1.VirtualMachineInstance, pod *corev1.Pod, now time.Time) (*Sample, error) {
+	data, err := restClient.Get().AbsPath(fmt.Sprintf(podMetricsPathFmt, pod.Namespace, pod.Name)).Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+	return parsePodMetrics(data, vmi, pod, now)
+}
+
+// parsePodMetrics converts the working set of the compute container to the memory used by the guest,
+// the memory KubeVirt reserves for QEMU and virt-launcher is taken off and the result is capped by the guest memory.
+func parsePodMetrics(data []byte, vmi *kubevirtv1.VirtualMachineInstance, pod *corev1.Pod, now time.Time) (*Sample, error) {
+	metrics := &podMetrics{}
+	if err := json.Unmarshal(data, metrics); err != nil {
+		return nil, fmt.Errorf("failed to parse pod metrics: %w", err)
+	}
+
+	for _, container := range metrics.Containers {
+		if container.Name != launcherComputeContainer {
+			continue
+		}
+		memory := max(container.Usage.Memory().Value()-launcherMemoryOverhead(vmi, pod), 0)
+		if guest := guestMemory(vmi); guest > 0 {
+			memory = min(memory, guest)
+		}
+		return &Sample{
+			Time:   now.Unix(),
+			CPU:    container.Usage.Cpu().MilliValue(),
+			Memory: memory,
+		}, nil
+	}
+	return nil, fmt.Errorf("no metrics of container %s", launcherComputeContainer)
+}
+
+// launcherMemoryOverhead returns the memory virt-controller adds to the request of the compute container
+// on top of the memory request of the VMI, which is the estimated usage of QEMU and virt-launcher.
+func launcherMemoryOverhead(vmi *kubevirtv1.VirtualMachineInstance, pod *corev1.Pod) int64 {
+	for _, container := range pod.Spec.Containers {
+		if container.Name != launcherComputeContainer {
+			continue
+		}
+		overhead := container.Resources.Requests.Memory().Value() - vmi.Spec.Domain.Resources.Requests.Memory().Value()
+		return max(overhead, 0)
+	}
+	return 0
+}
+
+// guestMemory returns the memory currently plugged into the guest, or 0 if it's unknown
+func guestMemory(vmi *kubevirtv1.VirtualMachineInstance) int64 {
+	if vmi.Status.Memory != nil && vmi.Status.Memory.GuestCurrent != nil {
+		return vmi.Status.Memory.GuestCurrent.Value()
+	}
+	if vmi.Spec.Domain.Memory != nil && vmi.Spec.Domain.Memory.Guest != nil {
+		return vmi.Spec.Domain.Memory.Guest.Value()
+	}
+	return vmi.Spec.Domain.Resources.Limits.Memory().Value()
+}
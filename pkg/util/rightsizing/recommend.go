@@ -0,0 +1,141 @@
+package rightsizing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+const (
+	// MinSamples is the number of samples needed before suggesting new values, i.e. an hour of usage
+	MinSamples = 12
+
+	CPUPercentile    = 95
+	MemoryPercentile = 99
+	// HeadroomPercent is added on top of the percentiles of the usage
+	HeadroomPercent = 20
+
+	// MemoryStep is the granularity the suggested memory is rounded up to
+	MemoryStep = 128 * 1024 * 1024
+	// MinHotplugMemory is the minimum guest memory KubeVirt supports memory hotplug with
+	MinHotplugMemory = 1024 * 1024 * 1024
+)
+
+var ErrNotEnoughSamples = errors.New("not enough usage samples")
+
+// Recommendation is the suggested sockets and memory of a VM, the values can be posted to the
+// cpuAndMemoryHotplug action as they are. KubeVirt only hotplugs CPU and memory, so the values lower than
+// the current ones take effect once the VM is restarted, which is told by RestartRequired.
+type Recommendation struct {
+	Sockets uint32 `json:"sockets"`
+	Memory  string `json:"memory"`
+
+	CurrentSockets uint32 `json:"currentSockets"`
+	CurrentMemory  string `json:"currentMemory"`
+	MaxSockets     uint32 `json:"maxSockets,omitempty"`
+	MaxMemory      string `json:"maxMemory,omitempty"`
+
+	// CPUUsage is the 95th percentile of the CPU usage
+	CPUUsage string `json:"cpuUsage"`
+	// MemoryUsage is the 99th percentile of the memory usage
+	MemoryUsage string      `json:"memoryUsage"`
+	Samples     int         `json:"samples"`
+	Since       metav1.Time `json:"since"`
+	// Hotpluggable is true if the cpuAndMemoryHotplug action is currently available on the VM
+	Hotpluggable bool `json:"hotpluggable"`
+	// RestartRequired is true if the suggested sockets or memory are lower than the current ones
+	RestartRequired bool `json:"restartRequired"`
+}
+
+// Recommend suggests the sockets and memory of the VM from the percentiles of its usage.
+// The suggestions are capped by the hotplug limits of the VM, which are taken from the VM spec,
+// then from the running VMI spec defaulted by KubeVirt, and at last from the max hotplug ratio.
+// The memory is never suggested above the current memory, the samples are capped by the guest memory
+// so the headroom on top of a busy guest would otherwise raise the memory on every recommendation.
+func Recommend(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1.VirtualMachineInstance, usage *Usage, maxHotplugRatio uint32) (*Recommendation, error) {
+	if vm.Spec.Template == nil {
+		return nil, fmt.Errorf("vm %s/%s has no template", vm.Namespace, vm.Name)
+	}
+	if usage == nil || len(usage.Samples) < MinSamples {
+		return nil, ErrNotEnoughSamples
+	}
+	domain := vm.Spec.Template.Spec.Domain
+
+	currentSockets, threadsPerSocket, maxSockets := uint32(1), uint32(1), uint32(0)
+	if domain.CPU != nil {
+		if domain.CPU.Sockets != 0 {
+			currentSockets = domain.CPU.Sockets
+		}
+		threadsPerSocket = max(domain.CPU.Cores, 1) * max(domain.CPU.Threads, 1)
+		maxSockets = domain.CPU.MaxSockets
+	}
+	if maxSockets == 0 && vmi != nil && vmi.Spec.Domain.CPU != nil {
+		maxSockets = vmi.Spec.Domain.CPU.MaxSockets
+	}
+	if maxSockets == 0 {
+		maxSockets = currentSockets * maxHotplugRatio
+	}
+
+	var currentMemory, maxMemory int64
+	if domain.Memory != nil && domain.Memory.Guest != nil {
+		currentMemory = domain.Memory.Guest.Value()
+	} else if limit, ok := domain.Resources.Limits[corev1.ResourceMemory]; ok {
+		currentMemory = limit.Value()
+	}
+	if domain.Memory != nil && domain.Memory.MaxGuest != nil {
+		maxMemory = domain.Memory.MaxGuest.Value()
+	} else if vmi != nil && vmi.Spec.Domain.Memory != nil && vmi.Spec.Domain.Memory.MaxGuest != nil {
+		maxMemory = vmi.Spec.Domain.Memory.MaxGuest.Value()
+	} else {
+		maxMemory = currentMemory * int64(maxHotplugRatio)
+	}
+
+	cpuUsage := usage.CPUPercentile(CPUPercentile)
+	memoryUsage := usage.MemoryPercentile(MemoryPercentile)
+
+	millicoresPerSocket := int64(threadsPerSocket) * 1000
+	sockets := uint32(ceilDiv(withHeadroom(cpuUsage), millicoresPerSocket)) //nolint:gosec
+	sockets = max(sockets, 1)
+	if maxSockets > 0 {
+		sockets = min(sockets, maxSockets)
+	}
+
+	memory := ceilDiv(withHeadroom(memoryUsage), MemoryStep) * MemoryStep
+	memory = max(memory, MinHotplugMemory)
+	if maxMemory > 0 {
+		memory = min(memory, maxMemory)
+	}
+	if currentMemory > 0 {
+		memory = min(memory, currentMemory)
+	}
+
+	recommendation := &Recommendation{
+		Sockets:         sockets,
+		Memory:          resource.NewQuantity(memory, resource.BinarySI).String(),
+		CurrentSockets:  currentSockets,
+		CurrentMemory:   resource.NewQuantity(currentMemory, resource.BinarySI).String(),
+		MaxSockets:      maxSockets,
+		CPUUsage:        resource.NewMilliQuantity(cpuUsage, resource.DecimalSI).String(),
+		MemoryUsage:     resource.NewQuantity(memoryUsage, resource.BinarySI).String(),
+		Samples:         len(usage.Samples),
+		Since:           metav1.NewTime(time.Unix(usage.Samples[0].Time, 0)),
+		RestartRequired: sockets < currentSockets || memory < currentMemory,
+	}
+	if maxMemory > 0 {
+		recommendation.MaxMemory = resource.NewQuantity(maxMemory, resource.BinarySI).String()
+	}
+	return recommendation, nil
+}
+
+func withHeadroom(value int64) int64 {
+	return value * (100 + HeadroomPercent) / 100
+}
+
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,126 @@
+package rightsizing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+func TestRecommend(t *testing.T) {
+	const gi = 1024 * 1024 * 1024
+	newVM := func(sockets uint32, guest string) *kubevirtv1.VirtualMachine {
+		guestMemory := resource.MustParse(guest)
+		return &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"},
+			Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineInstanceSpec{
+						Domain: kubevirtv1.DomainSpec{
+							CPU:    &kubevirtv1.CPU{Cores: 1, Threads: 1, Sockets: sockets},
+							Memory: &kubevirtv1.Memory{Guest: &guestMemory},
+						},
+					},
+				},
+			},
+		}
+	}
+	newUsage := func(n int, cpu, memory int64) *Usage {
+		usage := &Usage{}
+		for i := 0; i < n; i++ {
+			usage.Samples = append(usage.Samples, Sample{Time: int64(i * 300), CPU: cpu, Memory: memory})
+		}
+		return usage
+	}
+
+	tests := []struct {
+		name            string
+		vm              *kubevirtv1.VirtualMachine
+		vmi             *kubevirtv1.VirtualMachineInstance
+		usage           *Usage
+		expectError     error
+		expectSockets   uint32
+		expectMemory    string
+		expectMaxMemory string
+		expectRestart   bool
+	}{
+		{
+			name:        "not enough samples",
+			vm:          newVM(16, "32Gi"),
+			usage:       newUsage(MinSamples-1, 2000, 4*gi),
+			expectError: ErrNotEnoughSamples,
+		},
+		{
+			name:            "oversized VM is scaled down",
+			vm:              newVM(16, "32Gi"),
+			usage:           newUsage(MinSamples, 1800, 3*gi),
+			expectSockets:   3,
+			expectMemory:    "3712Mi",
+			expectMaxMemory: "128Gi",
+			expectRestart:   true,
+		},
+		{
+			name:            "the memory isn't suggested under the minimum of memory hotplug",
+			vm:              newVM(1, "2Gi"),
+			usage:           newUsage(MinSamples, 10, 100*1024*1024),
+			expectSockets:   1,
+			expectMemory:    "1Gi",
+			expectMaxMemory: "8Gi",
+			expectRestart:   true,
+		},
+		{
+			name:            "the memory of a busy guest isn't suggested above the current memory",
+			vm:              newVM(2, "4Gi"),
+			usage:           newUsage(MinSamples, 1000, 4*gi),
+			expectSockets:   2,
+			expectMemory:    "4Gi",
+			expectMaxMemory: "16Gi",
+		},
+		{
+			name:            "the sockets of an undersized VM are capped by the max hotplug ratio",
+			vm:              newVM(1, "1Gi"),
+			usage:           newUsage(MinSamples, 8000, gi),
+			expectSockets:   4,
+			expectMemory:    "1Gi",
+			expectMaxMemory: "4Gi",
+		},
+		{
+			name: "the sockets of an undersized VM are capped by the hotplug limits of the VMI",
+			vm:   newVM(1, "1Gi"),
+			vmi: &kubevirtv1.VirtualMachineInstance{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Domain: kubevirtv1.DomainSpec{
+						CPU:    &kubevirtv1.CPU{MaxSockets: 8},
+						Memory: &kubevirtv1.Memory{MaxGuest: resource.NewQuantity(6*gi, resource.BinarySI)},
+					},
+				},
+			},
+			usage:           newUsage(MinSamples, 8000, gi),
+			expectSockets:   8,
+			expectMemory:    "1Gi",
+			expectMaxMemory: "6Gi",
+		},
+	}
+
+	for _, tc := range tests {
+		recommendation, err := Recommend(tc.vm, tc.vmi, tc.usage, 4)
+		if tc.expectError != nil {
+			assert.ErrorIs(t, err, tc.expectError, tc.name)
+			continue
+		}
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expectSockets, recommendation.Sockets, tc.name)
+		assert.Equal(t, tc.expectMemory, recommendation.Memory, tc.name)
+		assert.Equal(t, tc.expectMaxMemory, recommendation.MaxMemory, tc.name)
+		assert.Equal(t, tc.expectRestart, recommendation.RestartRequired, tc.name)
+		assert.Equal(t, tc.vm.Spec.Template.Spec.Domain.CPU.Sockets, recommendation.CurrentSockets, tc.name)
+		assert.Equal(t, tc.vm.Spec.Template.Spec.Domain.Memory.Guest.String(), recommendation.CurrentMemory, tc.name)
+		assert.Equal(t, MinSamples, recommendation.Samples, tc.name)
+
+		// the suggested memory is a valid value of the cpuAndMemoryHotplug action
+		_, err = resource.ParseQuantity(recommendation.Memory)
+		assert.NoError(t, err, tc.name)
+	}
+}
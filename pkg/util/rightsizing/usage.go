@@ -0,0 +1,118 @@
+package rightsizing
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+const (
+	// SampleInterval is the interval the usage of a running VM is sampled at
+	SampleInterval = 5 * time.Minute
+	// Window is how long the samples are kept, the percentiles are calculated over the samples in the window
+	Window = 24 * time.Hour
+
+	// UsageConfigMapKey is the key the usage is stored with in the usage ConfigMap of a VM
+	UsageConfigMapKey = "usage"
+
+	usageConfigMapSuffix = "-resource-usage"
+)
+
+// UsageConfigMapName returns the name of the ConfigMap in the namespace of the VM the usage of the VM is kept in.
+// The usage isn't kept in the VM, updating the VM on every sample would trigger all the VM controllers.
+func UsageConfigMapName(vmName string) string {
+	return vmName + usageConfigMapSuffix
+}
+
+// Sample is the CPU and memory usage of the virt-launcher compute container of a VM at a point in time.
+// The JSON keys are kept short since a full window of samples is stored in the usage ConfigMap.
+type Sample struct {
+	// Time is the unix time in seconds the usage is collected at
+	Time int64 `json:"t"`
+	// CPU is the CPU usage in millicores
+	CPU int64 `json:"c"`
+	// Memory is the memory used by the guest in bytes, which is the working set without the QEMU overhead
+	Memory int64 `json:"m"`
+}
+
+// Usage is the rolling window of the usage samples of a VM,
+// which is kept in the usage ConfigMap of the VM.
+type Usage struct {
+	Samples []Sample `json:"samples"`
+}
+
+// LastSampleTime returns the time of the latest sample, or the zero time if there is no sample
+func (u *Usage) LastSampleTime() time.Time {
+	if len(u.Samples) == 0 {
+		return time.Time{}
+	}
+	return time.Unix(u.Samples[len(u.Samples)-1].Time, 0)
+}
+
+// AddSample appends the sample and drops the samples which fall out of the window
+func (u *Usage) AddSample(sample Sample) {
+	u.Samples = append(u.Samples, sample)
+
+	oldest := sample.Time - int64(Window/time.Second)
+	i := 0
+	for i < len(u.Samples) && u.Samples[i].Time <= oldest {
+		i++
+	}
+	u.Samples = u.Samples[i:]
+}
+
+// CPUPercentile returns the p-th percentile of the CPU usage in millicores
+func (u *Usage) CPUPercentile(p int) int64 {
+	values := make([]int64, 0, len(u.Samples))
+	for _, sample := range u.Samples {
+		values = append(values, sample.CPU)
+	}
+	return percentile(values, p)
+}
+
+// MemoryPercentile returns the p-th percentile of the memory usage in bytes
+func (u *Usage) MemoryPercentile(p int) int64 {
+	values := make([]int64, 0, len(u.Samples))
+	for _, sample := range u.Samples {
+		values = append(values, sample.Memory)
+	}
+	return percentile(values, p)
+}
+
+// percentile returns the p-th percentile of the values with the nearest-rank method
+func percentile(values []int64, p int) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	rank := (p*len(values) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	} else if rank > len(values) {
+		rank = len(values)
+	}
+	return values[rank-1]
+}
+
+// UnmarshalUsage parses the usage stored in the usage ConfigMap, it returns an empty usage if the value is empty.
+func UnmarshalUsage(data string) (*Usage, error) {
+	usage := &Usage{}
+	if data == "" {
+		return usage, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// MarshalUsage serializes the usage to JSON for the usage ConfigMap.
+func MarshalUsage(usage *Usage) (string, error) {
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,114 @@
+package rightsizing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+func TestUsage_AddSample(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	usage := &Usage{}
+	assert.True(t, usage.LastSampleTime().IsZero())
+
+	for i := 0; i < 300; i++ {
+		usage.AddSample(Sample{Time: start.Add(time.Duration(i) * SampleInterval).Unix(), CPU: int64(i)})
+	}
+
+	// the samples older than the window are dropped
+	assert.Len(t, usage.Samples, int(Window/SampleInterval))
+	assert.Equal(t, start.Add(299*SampleInterval), usage.LastSampleTime().UTC())
+	assert.Equal(t, int64(300-len(usage.Samples)), usage.Samples[0].CPU)
+}
+
+func TestPercentile(t *testing.T) {
+	values := func() []int64 {
+		// 1..100 in reverse order
+		values := make([]int64, 0, 100)
+		for i := int64(100); i > 0; i-- {
+			values = append(values, i)
+		}
+		return values
+	}
+
+	assert.Equal(t, int64(0), percentile(nil, 95))
+	assert.Equal(t, int64(95), percentile(values(), 95))
+	assert.Equal(t, int64(99), percentile(values(), 99))
+	assert.Equal(t, int64(100), percentile(values(), 100))
+	assert.Equal(t, int64(1), percentile(values(), 0))
+	assert.Equal(t, int64(7), percentile([]int64{7}, 50))
+}
+
+func TestUnmarshalUsage(t *testing.T) {
+	usage, err := UnmarshalUsage("")
+	assert.NoError(t, err)
+	assert.Empty(t, usage.Samples)
+
+	data, err := MarshalUsage(&Usage{Samples: []Sample{{Time: 1, CPU: 500, Memory: 1024}}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"samples":[{"t":1,"c":500,"m":1024}]}`, data)
+
+	usage, err = UnmarshalUsage(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []Sample{{Time: 1, CPU: 500, Memory: 1024}}, usage.Samples)
+
+	_, err = UnmarshalUsage("{")
+	assert.Error(t, err)
+}
+
+func TestParsePodMetrics(t *testing.T) {
+	now := time.Unix(1000, 0)
+	data := []byte(`{
+		"kind": "PodMetrics",
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"timestamp": "2024-01-01T00:00:00Z",
+		"window": "30s",
+		"containers": [
+			{"name": "guest-console-log", "usage": {"cpu": "1m", "memory": "10Mi"}},
+			{"name": "compute", "usage": {"cpu": "1500m", "memory": "2Gi"}}
+		]
+	}`)
+
+	newVMI := func(guest string) *kubevirtv1.VirtualMachineInstance {
+		guestMemory := resource.MustParse(guest)
+		return &kubevirtv1.VirtualMachineInstance{
+			Spec: kubevirtv1.VirtualMachineInstanceSpec{
+				Domain: kubevirtv1.DomainSpec{
+					Memory: &kubevirtv1.Memory{Guest: &guestMemory},
+					Resources: kubevirtv1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1536Mi")},
+					},
+				},
+			},
+		}
+	}
+	// virt-controller requests the memory of the VMI plus 256Mi of overhead for the compute container
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "compute",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1792Mi")},
+					},
+				},
+			},
+		},
+	}
+
+	sample, err := parsePodMetrics(data, newVMI("4Gi"), pod, now)
+	assert.NoError(t, err)
+	assert.Equal(t, &Sample{Time: 1000, CPU: 1500, Memory: 1792 * 1024 * 1024}, sample)
+
+	// the guest can't use more memory than it has
+	sample, err = parsePodMetrics(data, newVMI("1Gi"), pod, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024*1024*1024), sample.Memory)
+
+	_, err = parsePodMetrics([]byte(`{"containers": []}`), newVMI("4Gi"), pod, now)
+	assert.Error(t, err)
+}
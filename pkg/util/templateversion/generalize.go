@@ -0,0 +1,64 @@
+package templateversion
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type GeneralizePhase string
+
+const (
+	// GeneralizePending means the guest isn't frozen and the disks aren't snapshotted yet
+	GeneralizePending GeneralizePhase = "Pending"
+	// GeneralizeSnapshotting means the snapshots of the disks are being taken, the guest is frozen if it's running
+	GeneralizeSnapshotting GeneralizePhase = "Snapshotting"
+	// GeneralizeCopying means the copies of the disks are being restored from the snapshots
+	GeneralizeCopying GeneralizePhase = "Copying"
+	// GeneralizeSysprepping means the sysprep job is cleaning up the copies of the disks
+	GeneralizeSysprepping GeneralizePhase = "Sysprepping"
+	// GeneralizeExporting means the copies of the disks are being exported into the VM images of the template version
+	GeneralizeExporting GeneralizePhase = "Exporting"
+	GeneralizeCompleted GeneralizePhase = "Completed"
+	GeneralizeFailed    GeneralizePhase = "Failed"
+)
+
+// Generalize is the progress of the template version created from the generalized disks of a VM,
+// which is kept in the template version annotation harvesterhci.io/templateGeneralize.
+type Generalize struct {
+	// VM is the name of the source VM
+	VM string `json:"vm"`
+	// Sysprep runs virt-sysprep on the copies of the disks before they're exported
+	Sysprep bool `json:"sysprep,omitempty"`
+	// FrozenAt is the time the guest file systems were frozen, they're thawed once the snapshots are taken
+	FrozenAt *metav1.Time    `json:"frozenAt,omitempty"`
+	Phase    GeneralizePhase `json:"phase"`
+	Message  string          `json:"message,omitempty"`
+}
+
+// IsFinished returns true if the generalization is completed or failed
+func (g *Generalize) IsFinished() bool {
+	return g.Phase == GeneralizeCompleted || g.Phase == GeneralizeFailed
+}
+
+// UnmarshalGeneralize parses the templateGeneralize annotation value, it returns nil if the value is empty.
+func UnmarshalGeneralize(data string) (*Generalize, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	generalize := &Generalize{}
+	if err := json.Unmarshal([]byte(data), generalize); err != nil {
+		return nil, err
+	}
+	return generalize, nil
+}
+
+// MarshalGeneralize serializes the generalization to JSON for the annotation.
+func MarshalGeneralize(generalize *Generalize) (string, error) {
+	data, err := json.Marshal(generalize)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
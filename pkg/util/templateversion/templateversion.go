@@ -0,0 +1,235 @@
+package templateversion
+
+import (
+	"fmt"
+	"strings"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	ctlstoragev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/storage/v1"
+	wranglername "github.com/rancher/wrangler/v3/pkg/name"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	cdicommon "kubevirt.io/containerized-data-importer/pkg/controller/common"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	vmicommon "github.com/harvester/harvester/pkg/image/common"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func PVCName(templateVersionName string, diskIndex int) string {
+	return wranglername.SafeConcatName("templateversion", templateVersionName, fmt.Sprintf("disk-%d", diskIndex))
+}
+
+func VMImageName(templateVersionName string, imageIndex int) string {
+	return wranglername.SafeConcatName("templateversion", templateVersionName, fmt.Sprintf("image-%d", imageIndex))
+}
+
+func SnapshotName(templateVersionName string, diskIndex int) string {
+	return wranglername.SafeConcatName("templateversion", templateVersionName, fmt.Sprintf("snapshot-%d", diskIndex))
+}
+
+func CopyPVCName(templateVersionName string, diskIndex int) string {
+	return wranglername.SafeConcatName("templateversion", templateVersionName, fmt.Sprintf("copy-%d", diskIndex))
+}
+
+func SysprepJobName(templateVersionName string) string {
+	return wranglername.SafeConcatName("templateversion", templateVersionName, "sysprep")
+}
+
+// Exporter exports the PVCs of a VM into the VM images of a template version, and generates the volume claim
+// templates which restore the volumes of the template version VM from the images.
+type Exporter struct {
+	pvcCache          ctlcorev1.PersistentVolumeClaimCache
+	storageClassCache ctlstoragev1.StorageClassCache
+	vmImageClient     ctlharvesterv1.VirtualMachineImageClient
+	vmImageCache      ctlharvesterv1.VirtualMachineImageCache
+	vmio              vmicommon.VMIOperator
+}
+
+func NewExporter(
+	pvcCache ctlcorev1.PersistentVolumeClaimCache,
+	storageClassCache ctlstoragev1.StorageClassCache,
+	vmImageClient ctlharvesterv1.VirtualMachineImageClient,
+	vmImageCache ctlharvesterv1.VirtualMachineImageCache,
+	vmio vmicommon.VMIOperator,
+) *Exporter {
+	return &Exporter{
+		pvcCache:          pvcCache,
+		storageClassCache: storageClassCache,
+		vmImageClient:     vmImageClient,
+		vmImageCache:      vmImageCache,
+		vmio:              vmio,
+	}
+}
+
+// CreateVMImage creates a VM image which exports the PVC
+func (e *Exporter) CreateVMImage(namespace, name, claimName string, owner metav1.OwnerReference) (*harvesterv1.VirtualMachineImage, error) {
+	pvc, err := e.pvcCache.Get(namespace, claimName)
+	if err != nil {
+		return nil, err
+	}
+	targetSCName, err := e.getSCNameFromPVC(pvc)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := e.getVMImageBackend(targetSCName, pvc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure vm image backend for pvc %s", claimName)
+	}
+
+	return e.vmImageClient.Create(&harvesterv1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				util.AnnotationStorageClassName: targetSCName,
+			},
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Spec: harvesterv1.VirtualMachineImageSpec{
+			Backend:                backend,
+			DisplayName:            name,
+			SourceType:             harvesterv1.VirtualMachineImageSourceTypeExportVolume,
+			PVCName:                claimName,
+			PVCNamespace:           namespace,
+			TargetStorageClassName: targetSCName,
+		},
+	})
+}
+
+// Get Storage Class name from PVC or Backing Image
+// If the PVC has an annoation, which associates it with a BackingImage, then
+// this function will return the StorageClass name of the BackingImage. Otherwise it
+// will return the StorageClass name of the PVC itself.
+func (e *Exporter) getSCNameFromPVC(pvc *corev1.PersistentVolumeClaim) (scName string, err error) {
+	if imageId, ok := pvc.Annotations[util.AnnotationImageID]; ok {
+		return e.getSCNameFromImgID(imageId)
+	}
+	if pvc.Spec.StorageClassName == nil {
+		return "", fmt.Errorf("failed to get the storage class name from PVC")
+	}
+	// There should really be a bit more logic here to deal with default storage
+	// classes properly.
+	return *pvc.Spec.StorageClassName, nil
+}
+
+// Get Storage Class Name from ImageID
+// Given an ImageID, find the corresponding VMImage and figure out it's
+// StorageClass name
+func (e *Exporter) getSCNameFromImgID(imageId string) (scName string, err error) {
+	imageIdSplit := strings.Split(imageId, "/")
+	if len(imageIdSplit) != 2 {
+		return "", fmt.Errorf("malformed image ID: %s", imageId)
+	}
+
+	vmImage, err := e.vmImageCache.Get(imageIdSplit[0], imageIdSplit[1])
+	if err != nil {
+		return "", err
+	}
+
+	if scName, ok := vmImage.Annotations[util.AnnotationStorageClassName]; ok {
+		return scName, nil
+	}
+	return "", fmt.Errorf("VMImage %s/%s does not have an annotation %s", vmImage.Namespace, vmImage.Name, util.AnnotationStorageClassName)
+}
+
+// If target StorageClass name starts with `longhorn-templateversion-` use
+// backingImage
+// If target StorageClass uses does not use Longhorn as provisioner, use CDI
+// backend.
+// If target StorageClass uses Longhorn as provisioner with v2 data engine, use
+// CDI backend as well.
+// Otherwise (Longhorn as provisioner, with v1 data engine or no data engine
+// explicitly specified) use backingImage backend
+func (e *Exporter) getVMImageBackend(targetSCName string, pvc *corev1.PersistentVolumeClaim) (harvesterv1.VMIBackend, error) {
+	if strings.HasPrefix(targetSCName, "longhorn-templateversion-") {
+		return harvesterv1.VMIBackendBackingImage, nil
+	}
+
+	targetSC, err := e.storageClassCache.Get(targetSCName)
+	if err != nil {
+		return "", err
+	}
+	provisioner := util.GetProvisionedPVCProvisioner(pvc, e.storageClassCache)
+	if provisioner != util.CSIProvisionerLonghorn {
+		return harvesterv1.VMIBackendCDI, nil
+	}
+
+	v := util.GetLonghornDataEngineType(targetSC)
+	if v == longhorn.DataEngineTypeV2 {
+		return harvesterv1.VMIBackendCDI, nil
+	}
+	return harvesterv1.VMIBackendBackingImage, nil
+}
+
+// SetVolumeClaimTemplates replaces the PVCs of the template version VM with volume claim templates restoring the
+// volumes from the VM images of the template version. The pvcMap contains the PVCs of the source VM by name.
+func (e *Exporter) SetVolumeClaimTemplates(templateVersion *harvesterv1.VirtualMachineTemplateVersion, pvcMap map[string]corev1.PersistentVolumeClaim, vmImageMap map[string]harvesterv1.VirtualMachineImage) error {
+	vmSource := &templateVersion.Spec.VM
+	var entries []util.VolumeClaimTemplateEntry
+	for index, volume := range vmSource.Spec.Template.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		volumeClaimTemplate := e.generateVolumeClaimTemplate(index, templateVersion.Name, templateVersion.Namespace, volume.PersistentVolumeClaim.ClaimName, pvcMap, vmImageMap)
+		entries = append(entries, util.VolumeClaimTemplateEntry{PersistentVolumeClaim: volumeClaimTemplate})
+		vmSource.Spec.Template.Spec.Volumes[index].PersistentVolumeClaim.ClaimName = volumeClaimTemplate.Name
+	}
+
+	data, err := util.MarshalVolumeClaimTemplates(entries)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"namespace":       templateVersion.Namespace,
+			"templateVersion": templateVersion.Name,
+			"entries":         entries,
+		}).Error("Failed to marshal volume claim templates")
+		return err
+	}
+
+	if vmSource.ObjectMeta.Annotations == nil {
+		vmSource.ObjectMeta.Annotations = make(map[string]string)
+	}
+	vmSource.ObjectMeta.Annotations[util.AnnotationVolumeClaimTemplates] = data
+	return nil
+}
+
+// generate new volume template
+// - longhorn v1, we need to use the new storageclass Name (for backingImage)
+// - others, use the pvc StorageClassName
+func (e *Exporter) generateVolumeClaimTemplate(index int, tvName, tvNamespace, claimName string, pvcMap map[string]corev1.PersistentVolumeClaim, vmImageMap map[string]harvesterv1.VirtualMachineImage) (pvc corev1.PersistentVolumeClaim) {
+	var targetSCName *string
+
+	vmImageName := VMImageName(tvName, index)
+	vmImage := vmImageMap[vmImageName]
+
+	claim := pvcMap[claimName]
+	if _, ok := claim.Annotations[cdicommon.AnnCreatedForDataVolume]; ok {
+		// no nil check, because if claim.Spec.StorageClassName is nil, it needs to
+		// be copied to the PVC template as well, so the PVC template can also use
+		// the default storage class
+		targetSCName = claim.Spec.StorageClassName
+	} else {
+		targetSCName = ptr.To(e.vmio.GetStorageClassName(&vmImage))
+	}
+
+	pvc = corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: PVCName(tvName, index),
+			Annotations: map[string]string{
+				util.AnnotationImageID: fmt.Sprintf("%s/%s", tvNamespace, vmImageName),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      claim.Spec.AccessModes,
+			Resources:        claim.Spec.Resources,
+			VolumeMode:       claim.Spec.VolumeMode,
+			StorageClassName: targetSCName,
+		},
+	}
+	return pvc
+}
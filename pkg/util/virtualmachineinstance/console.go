@@ -0,0 +1,90 @@
+package virtualmachineinstance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+const (
+	// GuestConsoleLogContainer is the virt-launcher container streaming the log of the guest serial console
+	GuestConsoleLogContainer = "guest-console-log"
+
+	DefaultSerialConsoleLogBytes = 64 * 1024
+)
+
+// Screenshot returns the PNG of the current framebuffer of the VMI through the VNC subresource
+func Screenshot(ctx context.Context, virtSubresourceRestClient rest.Interface, namespace, name string) ([]byte, error) {
+	return virtSubresourceRestClient.Get().Namespace(namespace).Resource("virtualmachineinstances").
+		SubResource("vnc", "screenshot").Name(name).Param("moveCursor", "false").Do(ctx).Raw()
+}
+
+// GetLauncherPod returns the running virt-launcher pod of the VMI on the node the VMI runs on
+func GetLauncherPod(vmi *kubevirtv1.VirtualMachineInstance, podCache ctlcorev1.PodCache) (*corev1.Pod, error) {
+	pods, err := podCache.List(vmi.Namespace, labels.SelectorFromSet(labels.Set{
+		kubevirtv1.CreatedByLabel: string(vmi.UID),
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && pod.Spec.NodeName == vmi.Status.NodeName {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("can't find running virt-launcher pod of vm %s/%s", vmi.Namespace, vmi.Name)
+}
+
+// SerialConsoleLog returns the last maxBytes of the guest serial console log streamed by the virt-launcher pod.
+// The log isn't available if the serial console log is disabled on the VM or in the KubeVirt config.
+func SerialConsoleLog(ctx context.Context, clientSet kubernetes.Interface, pod *corev1.Pod, maxBytes int) ([]byte, error) {
+	hasContainer := false
+	for _, container := range pod.Spec.Containers {
+		if container.Name == GuestConsoleLogContainer {
+			hasContainer = true
+			break
+		}
+	}
+	if !hasContainer {
+		return nil, fmt.Errorf("serial console log is disabled on pod %s/%s", pod.Namespace, pod.Name)
+	}
+
+	stream, err := clientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: GuestConsoleLogContainer,
+	}).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	return readTail(stream, maxBytes)
+}
+
+// readTail reads the reader to the end and keeps the last n bytes
+func readTail(r io.Reader, n int) ([]byte, error) {
+	tail := make([]byte, 0, n)
+	buf := make([]byte, 32*1024)
+	for {
+		read, err := r.Read(buf)
+		if read > 0 {
+			tail = append(tail, buf[:read]...)
+			if len(tail) > n {
+				tail = append(tail[:0], tail[len(tail)-n:]...)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return tail, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package virtualmachineinstance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_readTail(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		n        int
+		expected string
+	}{
+		{
+			name:     "shorter than the limit",
+			input:    "login:",
+			n:        16,
+			expected: "login:",
+		},
+		{
+			name:     "longer than the limit",
+			input:    "booting...\nlogin:",
+			n:        6,
+			expected: "login:",
+		},
+		{
+			name:     "longer than the read buffer",
+			input:    strings.Repeat("a", 64*1024) + "kernel panic",
+			n:        12,
+			expected: "kernel panic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tail, err := readTail(strings.NewReader(tt.input), tt.n)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(tail))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"encoding/json"
+)
+
+type VolumeExpansionPhase string
+
+const (
+	// VolumeExpansionPending means the PVC is requested to be expanded, the resizing isn't started yet
+	VolumeExpansionPending VolumeExpansionPhase = "Pending"
+	// VolumeExpansionExpandingVolume means the CSI driver is expanding the volume, e.g. the Longhorn engine is expanding
+	VolumeExpansionExpandingVolume VolumeExpansionPhase = "ExpandingVolume"
+	// VolumeExpansionResizingFileSystem means the volume is expanded and the file system on the node is being resized
+	VolumeExpansionResizingFileSystem VolumeExpansionPhase = "ResizingFileSystem"
+	// VolumeExpansionWaitingForGuest means the PVC is expanded but the new size isn't visible in the VMI status yet
+	VolumeExpansionWaitingForGuest VolumeExpansionPhase = "WaitingForGuest"
+	VolumeExpansionCompleted       VolumeExpansionPhase = "Completed"
+	VolumeExpansionFailed          VolumeExpansionPhase = "Failed"
+)
+
+// VolumeExpansion is the progress of the expansion of a VM volume requested through the VM API,
+// which is kept in the PVC annotation harvesterhci.io/volumeExpansion.
+type VolumeExpansion struct {
+	// Size is the requested size of the PVC
+	Size string `json:"size"`
+	// VM is the name of the VM the volume is attached to
+	VM string `json:"vm"`
+	// Volume is the name of the volume in the VM
+	Volume  string               `json:"volume"`
+	Phase   VolumeExpansionPhase `json:"phase"`
+	Message string               `json:"message,omitempty"`
+}
+
+// IsFinished returns true if the expansion is completed or failed
+func (e *VolumeExpansion) IsFinished() bool {
+	return e.Phase == VolumeExpansionCompleted || e.Phase == VolumeExpansionFailed
+}
+
+// UnmarshalVolumeExpansion parses the volumeExpansion annotation value, it returns nil if the value is empty.
+func UnmarshalVolumeExpansion(data string) (*VolumeExpansion, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	expansion := &VolumeExpansion{}
+	if err := json.Unmarshal([]byte(data), expansion); err != nil {
+		return nil, err
+	}
+	return expansion, nil
+}
+
+// MarshalVolumeExpansion serializes the expansion to JSON for the annotation.
+func MarshalVolumeExpansion(expansion *VolumeExpansion) (string, error) {
+	data, err := json.Marshal(expansion)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,328 @@
+package generic
+
+import (
+	"fmt"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlsnapshotv1 "github.com/harvester/harvester/pkg/generated/controllers/snapshot.storage.k8s.io/v1"
+	backuputil "github.com/harvester/harvester/pkg/util/backup"
+	"github.com/harvester/harvester/pkg/volumeremotebackup/common"
+	"github.com/harvester/harvester/pkg/volumeremotebackup/driver"
+	"github.com/harvester/harvester/pkg/volumeremotebackup/mover"
+)
+
+// GenericBackupOperation backs up a PVC of any CSI driver supporting snapshots. It takes a CSI snapshot,
+// hands it over to the system namespace, provisions a temporary PVC from it there and runs a mover job
+// which streams the PVC to the backup target.
+type GenericBackupOperation struct {
+	bo          common.BackupOperator
+	mover       *MoverJobOperator
+	vsCache     ctlsnapshotv1.VolumeSnapshotCache
+	vsClient    ctlsnapshotv1.VolumeSnapshotClient
+	vscCache    ctlsnapshotv1.VolumeSnapshotContentCache
+	vscClient   ctlsnapshotv1.VolumeSnapshotContentClient
+	pvcCache    ctlcorev1.PersistentVolumeClaimCache
+	pvcClient   ctlcorev1.PersistentVolumeClaimClient
+	secretCache ctlcorev1.SecretCache
+}
+
+func GetGenericBackupOperation(
+	bo common.BackupOperator,
+	mover *MoverJobOperator,
+	vsCache ctlsnapshotv1.VolumeSnapshotCache,
+	vsClient ctlsnapshotv1.VolumeSnapshotClient,
+	vscCache ctlsnapshotv1.VolumeSnapshotContentCache,
+	vscClient ctlsnapshotv1.VolumeSnapshotContentClient,
+	pvcCache ctlcorev1.PersistentVolumeClaimCache,
+	pvcClient ctlcorev1.PersistentVolumeClaimClient,
+	secretCache ctlcorev1.SecretCache,
+) driver.BackupOperation {
+	return &GenericBackupOperation{
+		bo:          bo,
+		mover:       mover,
+		vsCache:     vsCache,
+		vsClient:    vsClient,
+		vscCache:    vscCache,
+		vscClient:   vscClient,
+		pvcCache:    pvcCache,
+		pvcClient:   pvcClient,
+		secretCache: secretCache,
+	}
+}
+
+// getMoverName returns the name of the snapshot, the temporary PVC, the mover job and the credential secret
+// in the system namespace. The uid keeps the VolumeRemoteBackups of all namespaces apart.
+func (gbo *GenericBackupOperation) getMoverName(vrb *harvesterv1.VolumeRemoteBackup) string {
+	return "vrb-" + string(gbo.bo.GetUID(vrb))
+}
+
+func (gbo *GenericBackupOperation) getManifestPath(vrb *harvesterv1.VolumeRemoteBackup) string {
+	return mover.ManifestPath(gbo.bo.GetNamespace(vrb), gbo.bo.GetName(vrb), string(gbo.bo.GetUID(vrb)))
+}
+
+// BuildOwnerReference creates an owner reference to a `VolumeRemoteBackup` CR, the snapshot in the namespace
+// of the `VolumeRemoteBackup` is removed with it. The resources in the system namespace are removed by Delete.
+func (gbo *GenericBackupOperation) BuildOwnerReference(vrb *harvesterv1.VolumeRemoteBackup) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: harvesterv1.SchemeGroupVersion.String(),
+		Kind:       gbo.bo.GetKind(vrb),
+		Name:       gbo.bo.GetName(vrb),
+		UID:        gbo.bo.GetUID(vrb),
+		Controller: ptr.To(true),
+	}
+}
+
+func (gbo *GenericBackupOperation) Create(vrb *harvesterv1.VolumeRemoteBackup) error {
+	if vrb == nil {
+		return fmt.Errorf("RemoteBackup cannot be nil")
+	}
+
+	srcPVC, err := gbo.pvcCache.Get(gbo.bo.GetNamespace(vrb), gbo.bo.GetSource(vrb))
+	if err != nil {
+		return err
+	}
+
+	vsClassInfo, err := gbo.bo.GetSnapshotClassInfo(vrb)
+	if err != nil {
+		return err
+	}
+
+	if err := gbo.ensureVolumeSnapshot(vrb, vsClassInfo.VolumeSnapshotClassName); err != nil {
+		return err
+	}
+
+	// the VolumeRemoteBackup is enqueued again when the snapshot becomes ready
+	vs, err := gbo.vsCache.Get(gbo.bo.GetNamespace(vrb), gbo.bo.GetName(vrb))
+	if err != nil {
+		return err
+	}
+	if vs.Status == nil || vs.Status.BoundVolumeSnapshotContentName == nil || !ptr.Deref(vs.Status.ReadyToUse, false) {
+		return nil
+	}
+
+	if err := gbo.ensureMoverSnapshot(vrb, *vs.Status.BoundVolumeSnapshotContentName); err != nil {
+		return err
+	}
+	if err := gbo.ensureMoverPVC(vrb, srcPVC); err != nil {
+		return err
+	}
+
+	return gbo.mover.ensureJob(gbo.getMoverName(vrb), "backup", vrb.Spec.BackupTargetName,
+		gbo.getManifestPath(vrb), gbo.getMoverName(vrb), srcPVC.Spec.VolumeMode)
+}
+
+// ensureVolumeSnapshot takes an in-cluster CSI snapshot of the source PVC
+func (gbo *GenericBackupOperation) ensureVolumeSnapshot(vrb *harvesterv1.VolumeRemoteBackup, vsClassName string) error {
+	if _, err := gbo.vsCache.Get(gbo.bo.GetNamespace(vrb), gbo.bo.GetName(vrb)); err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	vs := &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            gbo.bo.GetName(vrb),
+			Namespace:       gbo.bo.GetNamespace(vrb),
+			OwnerReferences: []metav1.OwnerReference{gbo.BuildOwnerReference(vrb)},
+		},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			Source: snapshotv1.VolumeSnapshotSource{
+				PersistentVolumeClaimName: ptr.To(gbo.bo.GetSource(vrb)),
+			},
+			VolumeSnapshotClassName: ptr.To(vsClassName),
+		},
+	}
+	if _, err := gbo.vsClient.Create(vs); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// ensureMoverSnapshot makes the snapshot available in the system namespace with a pre-provisioned
+// VolumeSnapshotContent referring to the same snapshot handle. The copy retains the snapshot,
+// which is removed with the snapshot in the namespace of the VolumeRemoteBackup.
+func (gbo *GenericBackupOperation) ensureMoverSnapshot(vrb *harvesterv1.VolumeRemoteBackup, vscName string) error {
+	moverName := gbo.getMoverName(vrb)
+
+	if _, err := gbo.vscCache.Get(moverName); apierrors.IsNotFound(err) {
+		vsc, err := gbo.vscCache.Get(vscName)
+		if err != nil {
+			return err
+		}
+		if vsc.Status == nil || vsc.Status.SnapshotHandle == nil {
+			return fmt.Errorf("VolumeSnapshotContent %s has no snapshot handle", vscName)
+		}
+
+		vscCopy := &snapshotv1.VolumeSnapshotContent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   moverName,
+				Labels: map[string]string{labelMoverOwner: string(gbo.bo.GetUID(vrb))},
+			},
+			Spec: snapshotv1.VolumeSnapshotContentSpec{
+				DeletionPolicy: snapshotv1.VolumeSnapshotContentRetain,
+				Driver:         vsc.Spec.Driver,
+				Source: snapshotv1.VolumeSnapshotContentSource{
+					SnapshotHandle: ptr.To(*vsc.Status.SnapshotHandle),
+				},
+				VolumeSnapshotRef: corev1.ObjectReference{
+					Name:      moverName,
+					Namespace: gbo.mover.namespace,
+				},
+				VolumeSnapshotClassName: vsc.Spec.VolumeSnapshotClassName,
+			},
+		}
+		if _, err := gbo.vscClient.Create(vscCopy); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := gbo.vsCache.Get(gbo.mover.namespace, moverName); err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	vs := &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moverName,
+			Namespace: gbo.mover.namespace,
+			Labels:    map[string]string{labelMoverOwner: string(gbo.bo.GetUID(vrb))},
+		},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			Source: snapshotv1.VolumeSnapshotSource{
+				VolumeSnapshotContentName: ptr.To(moverName),
+			},
+		},
+	}
+	if _, err := gbo.vsClient.Create(vs); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// ensureMoverPVC provisions the temporary PVC which the mover job reads from the snapshot,
+// the source PVC stays attached to the running VM.
+func (gbo *GenericBackupOperation) ensureMoverPVC(vrb *harvesterv1.VolumeRemoteBackup, srcPVC *corev1.PersistentVolumeClaim) error {
+	moverName := gbo.getMoverName(vrb)
+	if _, err := gbo.pvcCache.Get(gbo.mover.namespace, moverName); err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moverName,
+			Namespace: gbo.mover.namespace,
+			Labels:    map[string]string{labelMoverOwner: string(gbo.bo.GetUID(vrb))},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      srcPVC.Spec.AccessModes,
+			Resources:        srcPVC.Spec.Resources,
+			StorageClassName: srcPVC.Spec.StorageClassName,
+			VolumeMode:       srcPVC.Spec.VolumeMode,
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To(snapshotv1.GroupName),
+				Kind:     "VolumeSnapshot",
+				Name:     moverName,
+			},
+		},
+	}
+	if _, err := gbo.pvcClient.Create(pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// cleanupMoverResources removes the mover job, the temporary PVC and the snapshots
+// once the data is on the backup target or the backup is deleted.
+func (gbo *GenericBackupOperation) cleanupMoverResources(vrb *harvesterv1.VolumeRemoteBackup) error {
+	moverName := gbo.getMoverName(vrb)
+	if _, err := gbo.mover.jobCache.Get(gbo.mover.namespace, moverName); err == nil {
+		if err := gbo.mover.deleteJob(moverName); err != nil {
+			return err
+		}
+	}
+	if _, err := gbo.pvcCache.Get(gbo.mover.namespace, moverName); err == nil {
+		if err := gbo.pvcClient.Delete(gbo.mover.namespace, moverName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	if _, err := gbo.vsCache.Get(gbo.mover.namespace, moverName); err == nil {
+		if err := gbo.vsClient.Delete(gbo.mover.namespace, moverName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	if _, err := gbo.vscCache.Get(moverName); err == nil {
+		if err := gbo.vscClient.Delete(moverName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	if _, err := gbo.vsCache.Get(gbo.bo.GetNamespace(vrb), gbo.bo.GetName(vrb)); err == nil {
+		if err := gbo.vsClient.Delete(gbo.bo.GetNamespace(vrb), gbo.bo.GetName(vrb), &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gbo *GenericBackupOperation) Readiness(vrb *harvesterv1.VolumeRemoteBackup) (bool, error) {
+	if vrb == nil {
+		return false, fmt.Errorf("PVCBackup cannot be nil")
+	}
+
+	// the handle is set after the mover job succeeded, the mover resources are removed afterwards
+	// so that a failed update doesn't run the backup again
+	if gbo.bo.GetHandle(vrb) != "" {
+		return true, gbo.cleanupMoverResources(vrb)
+	}
+
+	ready, err := gbo.mover.jobReadiness(gbo.getMoverName(vrb))
+	if err != nil || !ready {
+		return false, err
+	}
+
+	if _, err := gbo.bo.SetHandle(vrb, gbo.getManifestPath(vrb)); err != nil {
+		return false, err
+	}
+	return true, gbo.cleanupMoverResources(vrb)
+}
+
+// Delete removes the manifest of the backup from the backup target and starts a sweep job removing the chunks
+// which aren't shared with other backups. A backup target which is not reachable doesn't block the deletion.
+func (gbo *GenericBackupOperation) Delete(vrb *harvesterv1.VolumeRemoteBackup) error {
+	if err := gbo.cleanupMoverResources(vrb); err != nil {
+		return err
+	}
+
+	handle := gbo.bo.GetHandle(vrb)
+	if handle == "" {
+		return nil
+	}
+
+	target, err := gbo.mover.getBackupTarget(vrb.Spec.BackupTargetName)
+	if err != nil {
+		logrus.WithError(err).Warnf("skip removing backup %s of VolumeRemoteBackup %s/%s", handle, gbo.bo.GetNamespace(vrb), gbo.bo.GetName(vrb))
+		return nil
+	}
+
+	store, err := backuputil.GetBackupStoreDriver(gbo.secretCache, target)
+	if err != nil {
+		logrus.WithError(err).Warnf("skip removing backup %s of VolumeRemoteBackup %s/%s", handle, gbo.bo.GetNamespace(vrb), gbo.bo.GetName(vrb))
+		return nil
+	}
+
+	if err := mover.Delete(store, handle); err != nil {
+		return err
+	}
+
+	// the chunks left behind are removed by the sweep after the next deletion otherwise
+	if err := gbo.mover.ensureSweepJob(gbo.getMoverName(vrb)+"-sweep", vrb.Spec.BackupTargetName); err != nil {
+		logrus.WithError(err).Warnf("failed to start removing the chunks of VolumeRemoteBackup %s/%s", gbo.bo.GetNamespace(vrb), gbo.bo.GetName(vrb))
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package generic
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	ctlbatchv1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/batch/v1"
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	backuputil "github.com/harvester/harvester/pkg/util/backup"
+	utilHelm "github.com/harvester/harvester/pkg/util/helm"
+	"github.com/harvester/harvester/pkg/volumeremotebackup/common"
+)
+
+const (
+	releaseAppHarvesterName = "harvester"
+
+	moverCommand      = "volume-mover"
+	moverVolumeName   = "data"
+	moverDevicePath   = "/dev/mover-data"
+	moverMountPath    = "/mnt/mover-data"
+	moverBackoffLimit = 3
+
+	// labelMoverOwner is set to the uid of the VolumeRemoteBackup or the VolumeRemoteRestore on the resources
+	// created for it outside of its namespace, they can't have an owner reference to it.
+	labelMoverOwner = "harvesterhci.io/volume-mover-owner"
+	// annotationReclaimPolicy keeps the reclaim policy of a restored volume while it's retained
+	annotationReclaimPolicy = "harvesterhci.io/volume-mover-reclaim-policy"
+
+	moverBackupTargetVolumeName = "backup-target"
+	moverBackupTargetPath       = "/mnt/backup-target"
+
+	// sweepJobTTL keeps a finished sweep job for a while to check its logs, nothing waits for it
+	sweepJobTTL = 3600
+)
+
+// MoverJobOperator runs the volume-mover of the harvester image in jobs which move the data between
+// a PVC and the backup target. The jobs run in the system namespace of harvester.
+type MoverJobOperator struct {
+	jobCache          ctlbatchv1.JobCache
+	jobClient         ctlbatchv1.JobClient
+	secretCache       ctlcorev1.SecretCache
+	secretClient      ctlcorev1.SecretClient
+	backupTargetCache ctlharvesterv1.BackupTargetCache
+	clientset         kubernetes.Interface
+	namespace         string
+}
+
+func NewMoverJobOperator(
+	jobCache ctlbatchv1.JobCache,
+	jobClient ctlbatchv1.JobClient,
+	secretCache ctlcorev1.SecretCache,
+	secretClient ctlcorev1.SecretClient,
+	backupTargetCache ctlharvesterv1.BackupTargetCache,
+	clientset kubernetes.Interface,
+	namespace string,
+) *MoverJobOperator {
+	return &MoverJobOperator{
+		jobCache:          jobCache,
+		jobClient:         jobClient,
+		secretCache:       secretCache,
+		secretClient:      secretClient,
+		backupTargetCache: backupTargetCache,
+		clientset:         clientset,
+		namespace:         namespace,
+	}
+}
+
+// getBackupTarget returns the named backup target of a VolumeRemoteBackup,
+// or the backup-target setting if the VolumeRemoteBackup doesn't name one.
+func (m *MoverJobOperator) getBackupTarget(backupTargetName string) (*settings.BackupTarget, error) {
+	target, err := backuputil.GetBackupTarget(m.backupTargetCache, backupTargetName)
+	if err != nil {
+		return nil, err
+	}
+	if target.IsDefaultBackupTarget() {
+		return nil, fmt.Errorf("backup target is not set")
+	}
+	return target, nil
+}
+
+// ensureCredentialSecret copies the credentials of the S3 backup target for the job. The copy lives in the
+// system namespace next to the job and is owned by it, so that it's removed together with the job.
+func (m *MoverJobOperator) ensureCredentialSecret(target *settings.BackupTarget, job *batchv1.Job) error {
+	if target.Type != settings.S3BackupType {
+		return nil
+	}
+
+	if _, err := m.secretCache.Get(job.Namespace, job.Name); err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	targetSecret, err := m.secretCache.Get(util.LonghornSystemNamespaceName, backuputil.GetBackupTargetSecretName(target.Name))
+	if err != nil {
+		return err
+	}
+
+	data := map[string][]byte{}
+	for _, key := range []string{util.AWSAccessKey, util.AWSSecretKey, util.AWSEndpoints, util.AWSCERT} {
+		if value, ok := targetSecret.Data[key]; ok {
+			data[key] = value
+		}
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name,
+			Namespace: job.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: batchv1.SchemeGroupVersion.String(),
+					Kind:       "Job",
+					Name:       job.Name,
+					UID:        job.UID,
+				},
+			},
+		},
+		Data: data,
+	}
+	if _, err := m.secretClient.Create(secret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// getNFSVolumeSource returns the volume mounting the NFS backup target in the job,
+// the mover can't mount it by itself without being privileged.
+func getNFSVolumeSource(target *settings.BackupTarget) (*corev1.NFSVolumeSource, error) {
+	u, err := url.Parse(backuputil.ConstructEndpoint(target))
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" || u.Path == "" {
+		return nil, fmt.Errorf("NFS backup target %s must follow format: nfs://<server-address>:/<share-name>/", target.Endpoint)
+	}
+	return &corev1.NFSVolumeSource{
+		Server: strings.TrimSuffix(u.Host, ":"),
+		Path:   u.Path,
+	}, nil
+}
+
+// ensureJob creates the mover job running the subcommand against the PVC, the job and the PVC
+// are in the system namespace so that neither the pod nor the credentials are exposed to the tenant.
+func (m *MoverJobOperator) ensureJob(
+	name, subcommand, backupTargetName, manifestPath, pvcName string,
+	volumeMode *corev1.PersistentVolumeMode,
+) error {
+	job, err := m.jobCache.Get(m.namespace, name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	target, err := m.getBackupTarget(backupTargetName)
+	if err != nil {
+		return err
+	}
+
+	if job == nil {
+		if job, err = m.createDataJob(target, name, subcommand, manifestPath, pvcName, volumeMode); err != nil {
+			return err
+		}
+	}
+
+	// the pod of the job waits for the secret to be created
+	return m.ensureCredentialSecret(target, job)
+}
+
+// ensureSweepJob creates a job removing the chunks which aren't referenced by any backup on the backup target
+// anymore. It isn't tracked by any resource, it's removed a while after it finished.
+func (m *MoverJobOperator) ensureSweepJob(name, backupTargetName string) error {
+	job, err := m.jobCache.Get(m.namespace, name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	target, err := m.getBackupTarget(backupTargetName)
+	if err != nil {
+		return err
+	}
+
+	if job == nil {
+		container := newMoverContainer("sweep")
+		// the sweep only needs to access the backup target
+		container.SecurityContext.Capabilities.Add = nil
+		if job, err = m.createJob(target, name, container, nil, ptr.To(int32(sweepJobTTL))); err != nil {
+			return err
+		}
+	}
+
+	return m.ensureCredentialSecret(target, job)
+}
+
+func newMoverContainer(subcommand string) corev1.Container {
+	return corev1.Container{
+		Name:    "mover",
+		Command: []string{moverCommand, subcommand},
+		// the mover runs as root to read and restore the files of any owner,
+		// but only with the capabilities needed for that
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+				Add:  []corev1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER"},
+			},
+		},
+	}
+}
+
+// createDataJob creates a job moving the data between the PVC and the backup target
+func (m *MoverJobOperator) createDataJob(
+	target *settings.BackupTarget,
+	name, subcommand, manifestPath, pvcName string,
+	volumeMode *corev1.PersistentVolumeMode,
+) (*batchv1.Job, error) {
+	readOnly := subcommand == "backup"
+	container := newMoverContainer(subcommand)
+	container.Args = []string{"--manifest", manifestPath}
+
+	var volumes []corev1.Volume
+	if volumeMode != nil && *volumeMode == corev1.PersistentVolumeBlock {
+		container.Args = append(container.Args, "--mode", "block", "--path", moverDevicePath)
+		container.VolumeDevices = []corev1.VolumeDevice{{Name: moverVolumeName, DevicePath: moverDevicePath}}
+	} else {
+		container.Args = append(container.Args, "--mode", "filesystem", "--path", moverMountPath)
+		container.VolumeMounts = []corev1.VolumeMount{{Name: moverVolumeName, MountPath: moverMountPath, ReadOnly: readOnly}}
+	}
+	volumes = append(volumes, corev1.Volume{
+		Name: moverVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvcName,
+				ReadOnly:  readOnly,
+			},
+		},
+	})
+
+	return m.createJob(target, name, container, volumes, nil)
+}
+
+// createJob completes the mover container with the image and the backup target and creates its job
+func (m *MoverJobOperator) createJob(
+	target *settings.BackupTarget,
+	name string,
+	container corev1.Container,
+	volumes []corev1.Volume,
+	ttlSecondsAfterFinished *int32,
+) (*batchv1.Job, error) {
+	image, err := utilHelm.FetchImageFromHelmValues(m.clientset, m.namespace, releaseAppHarvesterName, []string{"containers", "apiserver", "image"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvester image (%s): %w", image.ImageName(), err)
+	}
+	container.Image = image.ImageName()
+	container.ImagePullPolicy = image.ImagePullPolicy
+
+	switch target.Type {
+	case settings.S3BackupType:
+		container.Args = append(container.Args, "--backup-target", backuputil.ConstructEndpoint(target))
+		container.EnvFrom = []corev1.EnvFromSource{
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}}},
+		}
+	case settings.NFSBackupType:
+		nfs, err := getNFSVolumeSource(target)
+		if err != nil {
+			return nil, err
+		}
+		container.Args = append(container.Args, "--backup-target", "vfs://"+moverBackupTargetPath)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: moverBackupTargetVolumeName, MountPath: moverBackupTargetPath})
+		volumes = append(volumes, corev1.Volume{
+			Name:         moverBackupTargetVolumeName,
+			VolumeSource: corev1.VolumeSource{NFS: nfs},
+		})
+	default:
+		return nil, fmt.Errorf("backup target type %s is not supported by the generic driver", target.Type)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: m.namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To(int32(moverBackoffLimit)),
+			TTLSecondsAfterFinished: ttlSecondsAfterFinished,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					AutomountServiceAccountToken: ptr.To(false),
+					Containers:                   []corev1.Container{container},
+					Volumes:                      volumes,
+				},
+			},
+		},
+	}
+
+	created, err := m.jobClient.Create(job)
+	if apierrors.IsAlreadyExists(err) {
+		return m.jobClient.Get(m.namespace, name, metav1.GetOptions{})
+	}
+	return created, err
+}
+
+// deleteJob removes the job with its pods, the credential secret is garbage collected with the job
+func (m *MoverJobOperator) deleteJob(name string) error {
+	err := m.jobClient.Delete(m.namespace, name, &metav1.DeleteOptions{PropagationPolicy: ptr.To(metav1.DeletePropagationBackground)})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// jobReadiness returns true if the job succeeded, NotFound if it doesn't exist
+// and an error if it failed after all retries.
+func (m *MoverJobOperator) jobReadiness(name string) (bool, error) {
+	job, err := m.jobCache.Get(m.namespace, name)
+	if err != nil {
+		return false, err
+	}
+
+	if job.DeletionTimestamp != nil {
+		return false, common.ErrRetryLater
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			return false, fmt.Errorf("job %s/%s failed: %s", m.namespace, name, c.Message)
+		}
+	}
+	return false, nil
+}
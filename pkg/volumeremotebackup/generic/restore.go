@@ -0,0 +1,332 @@
+package generic
+
+import (
+	"fmt"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/volumeremotebackup/common"
+	"github.com/harvester/harvester/pkg/volumeremotebackup/driver"
+)
+
+// GenericRestoreOperation restores a backup of the generic driver. It provisions a PVC with the spec
+// of the source PVC in the system namespace, runs a mover job which streams the backup from the backup
+// target into it and then hands the volume over to the PVC in the namespace of the restore.
+type GenericRestoreOperation struct {
+	ro        common.RestoreOperator
+	bo        common.BackupOperator
+	mover     *MoverJobOperator
+	vrbCache  ctlharvesterv1.VolumeRemoteBackupCache
+	pvcCache  ctlcorev1.PersistentVolumeClaimCache
+	pvcClient ctlcorev1.PersistentVolumeClaimClient
+	pvCache   ctlcorev1.PersistentVolumeCache
+	pvClient  ctlcorev1.PersistentVolumeClient
+}
+
+func GetGenericRestoreOperation(
+	ro common.RestoreOperator,
+	bo common.BackupOperator,
+	mover *MoverJobOperator,
+	vrbCache ctlharvesterv1.VolumeRemoteBackupCache,
+	pvcCache ctlcorev1.PersistentVolumeClaimCache,
+	pvcClient ctlcorev1.PersistentVolumeClaimClient,
+	pvCache ctlcorev1.PersistentVolumeCache,
+	pvClient ctlcorev1.PersistentVolumeClient,
+) driver.RestoreOperation {
+	return &GenericRestoreOperation{
+		ro:        ro,
+		bo:        bo,
+		mover:     mover,
+		vrbCache:  vrbCache,
+		pvcCache:  pvcCache,
+		pvcClient: pvcClient,
+		pvCache:   pvCache,
+		pvClient:  pvClient,
+	}
+}
+
+// getMoverName returns the name of the temporary PVC, the mover job and the credential secret
+// in the system namespace
+func (gro *GenericRestoreOperation) getMoverName(vrr *harvesterv1.VolumeRemoteRestore) string {
+	return "vrr-" + string(gro.ro.GetUID(vrr))
+}
+
+// BuildOwnerReference creates an owner reference to a `VolumeRemoteRestore` CR,
+// the restored PVC is removed with the `VolumeRemoteRestore`.
+func (gro *GenericRestoreOperation) BuildOwnerReference(vrr *harvesterv1.VolumeRemoteRestore) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: harvesterv1.SchemeGroupVersion.String(),
+		Kind:       gro.ro.GetKind(vrr),
+		Name:       gro.ro.GetName(vrr),
+		UID:        gro.ro.GetUID(vrr),
+		Controller: ptr.To(true),
+	}
+}
+
+func (gro *GenericRestoreOperation) getSourceRemoteBackup(vrr *harvesterv1.VolumeRemoteRestore) (*harvesterv1.VolumeRemoteBackup, error) {
+	namespace, name := ref.Parse(gro.ro.GetFrom(vrr))
+	if namespace == "" {
+		namespace = gro.ro.GetNamespace(vrr)
+	}
+
+	vrb, err := gro.vrbCache.Get(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if gro.bo.GetType(vrb) != harvesterv1.VolumeRemoteBackupGeneric {
+		return nil, fmt.Errorf("source RemoteBackup %s/%s is not created by the generic driver", namespace, name)
+	}
+	if gro.bo.GetHandle(vrb) == "" {
+		return nil, fmt.Errorf("source RemoteBackup %s/%s has no handle", namespace, name)
+	}
+	return vrb, nil
+}
+
+func (gro *GenericRestoreOperation) Create(vrr *harvesterv1.VolumeRemoteRestore) error {
+	if vrr == nil {
+		return fmt.Errorf("RemoteRestore cannot be nil")
+	}
+
+	vrb, err := gro.getSourceRemoteBackup(vrr)
+	if err != nil {
+		return err
+	}
+
+	pvc, err := gro.ensureMoverPVC(vrr, vrb)
+	if err != nil {
+		return err
+	}
+
+	return gro.mover.ensureJob(gro.getMoverName(vrr), "restore", vrb.Spec.BackupTargetName,
+		gro.bo.GetHandle(vrb), pvc.Name, pvc.Spec.VolumeMode)
+}
+
+// ensureMoverPVC provisions an empty PVC with the spec of the source PVC in the system namespace
+func (gro *GenericRestoreOperation) ensureMoverPVC(
+	vrr *harvesterv1.VolumeRemoteRestore,
+	vrb *harvesterv1.VolumeRemoteBackup,
+) (*corev1.PersistentVolumeClaim, error) {
+	moverName := gro.getMoverName(vrr)
+	pvc, err := gro.pvcCache.Get(gro.mover.namespace, moverName)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return pvc, err
+	}
+
+	sourceSpec := gro.bo.GetSourceSpec(vrb)
+	pvc = &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moverName,
+			Namespace: gro.mover.namespace,
+			Labels:    map[string]string{labelMoverOwner: string(gro.ro.GetUID(vrr))},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      sourceSpec.AccessModes,
+			Resources:        sourceSpec.Resources,
+			StorageClassName: sourceSpec.StorageClassName,
+			VolumeMode:       sourceSpec.VolumeMode,
+		},
+	}
+
+	created, err := gro.pvcClient.Create(pvc)
+	if apierrors.IsAlreadyExists(err) {
+		return gro.pvcClient.Get(gro.mover.namespace, moverName, metav1.GetOptions{})
+	}
+	return created, err
+}
+
+// getRestoredPV returns the volume the mover job restored the backup to
+func (gro *GenericRestoreOperation) getRestoredPV(vrr *harvesterv1.VolumeRemoteRestore) (*corev1.PersistentVolume, error) {
+	pvs, err := gro.pvCache.List(labels.SelectorFromSet(labels.Set{labelMoverOwner: string(gro.ro.GetUID(vrr))}))
+	if err != nil {
+		return nil, err
+	}
+	if len(pvs) == 0 {
+		return nil, nil
+	}
+	return pvs[0], nil
+}
+
+// retainPV keeps the volume when the PVC in the system namespace is deleted,
+// the original reclaim policy is restored once the volume is bound to the restored PVC.
+func (gro *GenericRestoreOperation) retainPV(vrr *harvesterv1.VolumeRemoteRestore, pv *corev1.PersistentVolume) error {
+	if pv.Labels[labelMoverOwner] == string(gro.ro.GetUID(vrr)) && pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain {
+		return nil
+	}
+
+	pvCpy := pv.DeepCopy()
+	if pvCpy.Labels == nil {
+		pvCpy.Labels = map[string]string{}
+	}
+	pvCpy.Labels[labelMoverOwner] = string(gro.ro.GetUID(vrr))
+	if pvCpy.Annotations == nil {
+		pvCpy.Annotations = map[string]string{}
+	}
+	pvCpy.Annotations[annotationReclaimPolicy] = string(pv.Spec.PersistentVolumeReclaimPolicy)
+	pvCpy.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	_, err := gro.pvClient.Update(pvCpy)
+	return err
+}
+
+// restorePVReclaimPolicy sets the reclaim policy of the volume back to the one it's provisioned with
+func (gro *GenericRestoreOperation) restorePVReclaimPolicy(pv *corev1.PersistentVolume) error {
+	policy, ok := pv.Annotations[annotationReclaimPolicy]
+	if !ok {
+		return nil
+	}
+
+	pvCpy := pv.DeepCopy()
+	delete(pvCpy.Annotations, annotationReclaimPolicy)
+	pvCpy.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimPolicy(policy)
+	_, err := gro.pvClient.Update(pvCpy)
+	return err
+}
+
+// transferPV hands the restored volume over to the namespace of the restore: the volume is retained,
+// released by the PVC in the system namespace and pre-bound to a new PVC in the namespace of the restore.
+func (gro *GenericRestoreOperation) transferPV(vrr *harvesterv1.VolumeRemoteRestore) error {
+	moverName := gro.getMoverName(vrr)
+	moverPVC, err := gro.pvcCache.Get(gro.mover.namespace, moverName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if moverPVC != nil {
+		if moverPVC.Spec.VolumeName == "" {
+			return fmt.Errorf("PVC %s/%s is not bound", gro.mover.namespace, moverName)
+		}
+		pv, err := gro.pvCache.Get(moverPVC.Spec.VolumeName)
+		if err != nil {
+			return err
+		}
+		if err := gro.retainPV(vrr, pv); err != nil {
+			return err
+		}
+		if moverPVC.DeletionTimestamp != nil {
+			return nil
+		}
+		return gro.pvcClient.Delete(gro.mover.namespace, moverName, &metav1.DeleteOptions{})
+	}
+
+	pv, err := gro.getRestoredPV(vrr)
+	if err != nil {
+		return err
+	}
+	if pv == nil {
+		return fmt.Errorf("restored volume of VolumeRemoteRestore %s/%s not found", gro.ro.GetNamespace(vrr), gro.ro.GetName(vrr))
+	}
+
+	namespace, name := gro.ro.GetNamespace(vrr), gro.ro.GetName(vrr)
+	if ref := pv.Spec.ClaimRef; ref == nil || ref.Namespace != namespace || ref.Name != name {
+		pvCpy := pv.DeepCopy()
+		pvCpy.Spec.ClaimRef = &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+			Namespace:  namespace,
+			Name:       name,
+		}
+		if _, err := gro.pvClient.Update(pvCpy); err != nil {
+			return err
+		}
+	}
+
+	vrb, err := gro.getSourceRemoteBackup(vrr)
+	if err != nil {
+		return err
+	}
+	sourceSpec := gro.bo.GetSourceSpec(vrb)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				driver.AnnotationPVCRestoreRef: fmt.Sprintf("%s/%s", namespace, name),
+			},
+			OwnerReferences: []metav1.OwnerReference{gro.BuildOwnerReference(vrr)},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      sourceSpec.AccessModes,
+			Resources:        sourceSpec.Resources,
+			StorageClassName: ptr.To(pv.Spec.StorageClassName),
+			VolumeMode:       sourceSpec.VolumeMode,
+			VolumeName:       pv.Name,
+		},
+	}
+	if _, err := gro.pvcClient.Create(pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+func (gro *GenericRestoreOperation) Readiness(vrr *harvesterv1.VolumeRemoteRestore) (bool, error) {
+	if vrr == nil {
+		return false, fmt.Errorf("PVCRestore cannot be nil")
+	}
+
+	pvc, err := gro.pvcCache.Get(gro.ro.GetNamespace(vrr), gro.ro.GetName(vrr))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	if pvc != nil {
+		return gro.completeRestore(vrr, pvc)
+	}
+
+	// the job is kept until the volume is handed over, a missing job means the restore isn't started
+	ready, err := gro.mover.jobReadiness(gro.getMoverName(vrr))
+	if err != nil || !ready {
+		return false, err
+	}
+	return false, gro.transferPV(vrr)
+}
+
+// completeRestore waits for the restored PVC to be bound to the restored volume
+// and removes the mover job afterwards.
+func (gro *GenericRestoreOperation) completeRestore(vrr *harvesterv1.VolumeRemoteRestore, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	pv, err := gro.getRestoredPV(vrr)
+	if err != nil {
+		return false, err
+	}
+	if pv == nil || pvc.Spec.VolumeName != pv.Name {
+		return false, fmt.Errorf("PVC %s/%s isn't restored by VolumeRemoteRestore %s/%s",
+			pvc.Namespace, pvc.Name, gro.ro.GetNamespace(vrr), gro.ro.GetName(vrr))
+	}
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return false, nil
+	}
+
+	if err := gro.restorePVReclaimPolicy(pv); err != nil {
+		return false, err
+	}
+	if _, err := gro.mover.jobCache.Get(gro.mover.namespace, gro.getMoverName(vrr)); err == nil {
+		if err := gro.mover.deleteJob(gro.getMoverName(vrr)); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Delete removes the mover resources in the system namespace. A volume which isn't handed over yet gets
+// its reclaim policy back, so that it's removed when it's released. The restored PVC is removed by
+// owner references.
+func (gro *GenericRestoreOperation) Delete(vrr *harvesterv1.VolumeRemoteRestore) error {
+	moverName := gro.getMoverName(vrr)
+	if err := gro.mover.deleteJob(moverName); err != nil {
+		return err
+	}
+	if err := gro.pvcClient.Delete(gro.mover.namespace, moverName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	pv, err := gro.getRestoredPV(vrr)
+	if err != nil || pv == nil {
+		return err
+	}
+	return gro.restorePVReclaimPolicy(pv)
+}
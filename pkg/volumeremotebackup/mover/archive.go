@@ -0,0 +1,175 @@
+package mover
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Archive writes the content of dir to w as a tar stream, the entries are in the lexical order of
+// filepath.WalkDir so that unchanged files produce the same chunks in the following backups.
+func Archive(dir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			// sockets and other special files can't be archived
+			return nil
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		// access and change times change on every read, they would break the deduplication
+		hdr.AccessTime = hdr.ModTime
+		hdr.ChangeTime = hdr.ModTime
+		hdr.Format = tar.FormatPAX
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// Extract restores a tar stream created by Archive into dir. Every entry is resolved against dir with
+// os.Root, so that neither ".." nor a symlink, including one created by an earlier entry, can make
+// an entry land outside of dir.
+func Extract(r io.Reader, dir string) error {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid entry %s in archive", hdr.Name)
+		}
+
+		if err := extractEntry(root, tr, hdr, name); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// checkNotSymlink rejects an entry replacing an existing symlink, the files and directories
+// would otherwise be written through the link.
+func checkNotSymlink(root *os.Root, name string) error {
+	info, err := root.Lstat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("%s is an existing symlink", name)
+	}
+	return nil
+}
+
+func extractEntry(root *os.Root, tr *tar.Reader, hdr *tar.Header, name string) error {
+	mode := os.FileMode(hdr.Mode).Perm()
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := checkNotSymlink(root, name); err != nil {
+			return err
+		}
+		if err := root.MkdirAll(name, mode); err != nil {
+			return err
+		}
+	case tar.TypeReg:
+		if err := checkNotSymlink(root, name); err != nil {
+			return err
+		}
+		f, err := root.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|syscall.O_NOFOLLOW, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	case tar.TypeSymlink:
+		if err := checkNotSymlink(root, name); err != nil {
+			return err
+		}
+		if err := root.Symlink(hdr.Linkname, name); err != nil {
+			return err
+		}
+		if err := root.Lchown(name, hdr.Uid, hdr.Gid); err != nil && !errors.Is(err, os.ErrPermission) {
+			return err
+		}
+		return nil
+	default:
+		// device files, fifos, etc. are skipped
+		return nil
+	}
+
+	// ownership can only be restored with CAP_CHOWN, which the mover job is granted
+	if err := root.Lchown(name, hdr.Uid, hdr.Gid); err != nil && !errors.Is(err, os.ErrPermission) {
+		return err
+	}
+	if err := root.Chmod(name, mode); err != nil {
+		return err
+	}
+	return root.Chtimes(name, hdr.ModTime, hdr.ModTime)
+}
@@ -0,0 +1,185 @@
+package mover
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/longhorn/backupstore/util"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	locksDirectory = "locks"
+	lockPrefix     = "lock"
+	lockSuffix     = ".lck"
+
+	lockDuration        = 150 * time.Second
+	lockRefreshInterval = 60 * time.Second
+)
+
+// lockCheckWaitTime is the time to wait between writing the lock and checking the other locks, so that
+// the locks written at the same time by other movers are seen. It's a variable to be shortened in tests.
+var lockCheckWaitTime = 2 * time.Second
+
+type LockType string
+
+const (
+	// LockShared is held by backups and restores, they can run at the same time
+	LockShared LockType = "shared"
+	// LockExclusive is held by the sweep removing the unreferenced chunks, it can't run
+	// while a backup uploads chunks which aren't referenced by a manifest yet.
+	LockExclusive LockType = "exclusive"
+)
+
+// lockRecord is the content of a lock file, the time of the lock is the modification time of the file
+type lockRecord struct {
+	Type     LockType `json:"type"`
+	Acquired bool     `json:"acquired"`
+}
+
+// Lock is a lock on the chunks of a backup target, it works like the locks of the Longhorn backupstore
+// but is kept under Root so that the Longhorn backup volumes aren't affected. An acquired lock is refreshed
+// until it's released, a lock which isn't refreshed for lockDuration is expired.
+type Lock struct {
+	store    Store
+	name     string
+	lockType LockType
+
+	// mu keeps a refresh from writing the lock again after it's released
+	mu       sync.Mutex
+	released bool
+	stop     chan struct{}
+}
+
+func lockPath(name string) string {
+	return path.Join(Root, locksDirectory, name+lockSuffix)
+}
+
+// AcquireLock tries to acquire the lock once. It fails if a lock of a conflicting type is acquired or
+// was requested earlier, the caller is expected to retry later.
+func AcquireLock(store Store, lockType LockType) (*Lock, error) {
+	l := &Lock{
+		store:    store,
+		name:     util.GenerateName(lockPrefix),
+		lockType: lockType,
+		stop:     make(chan struct{}),
+	}
+
+	if err := l.save(false); err != nil {
+		return nil, err
+	}
+	time.Sleep(lockCheckWaitTime)
+
+	conflict, err := l.findConflict()
+	if err == nil && conflict != "" {
+		err = fmt.Errorf("backup target is locked by %s, please try again later", conflict)
+	}
+	if err == nil {
+		err = l.save(true)
+	}
+	if err != nil {
+		if removeErr := store.Remove(lockPath(l.name)); removeErr != nil {
+			logrus.WithError(removeErr).Warnf("Failed to remove lock %s", l.name)
+		}
+		return nil, err
+	}
+
+	go l.refresh()
+	return l, nil
+}
+
+func (l *Lock) save(acquired bool) error {
+	content, err := json.Marshal(lockRecord{Type: l.lockType, Acquired: acquired})
+	if err != nil {
+		return err
+	}
+	return l.store.Write(lockPath(l.name), bytes.NewReader(content))
+}
+
+func conflicts(a, b LockType) bool {
+	return a == LockExclusive || b == LockExclusive
+}
+
+// findConflict returns the name of a conflicting lock which has priority over this one: an acquired lock
+// always has it, otherwise the older lock has it.
+func (l *Lock) findConflict() (string, error) {
+	ownTime := l.store.FileTime(lockPath(l.name))
+
+	files, err := l.store.List(path.Join(Root, locksDirectory))
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		name, ok := strings.CutSuffix(file, lockSuffix)
+		if !ok || name == l.name {
+			continue
+		}
+
+		record, err := readLockRecord(l.store, name)
+		if err != nil {
+			// the lock may be released in the meantime
+			logrus.WithError(err).Warnf("Failed to read lock %s", name)
+			continue
+		}
+		lockTime := l.store.FileTime(lockPath(name))
+		if time.Since(lockTime) > lockDuration || !conflicts(l.lockType, record.Type) {
+			continue
+		}
+
+		if record.Acquired || lockTime.Before(ownTime) || (lockTime.Equal(ownTime) && name < l.name) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+func readLockRecord(store Store, name string) (*lockRecord, error) {
+	rc, err := store.Read(lockPath(name))
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	record := &lockRecord{}
+	if err := json.NewDecoder(rc).Decode(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (l *Lock) refresh() {
+	ticker := time.NewTicker(lockRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			if !l.released {
+				if err := l.save(true); err != nil {
+					// the lock expires only after missing two refreshes
+					logrus.WithError(err).Warnf("Failed to refresh lock %s", l.name)
+				}
+			}
+			l.mu.Unlock()
+		}
+	}
+}
+
+// Release stops refreshing the lock and removes it
+func (l *Lock) Release() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.released {
+		return nil
+	}
+	l.released = true
+	close(l.stop)
+	return l.store.Remove(lockPath(l.name))
+}
@@ -0,0 +1,323 @@
+package mover
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/longhorn/backupstore/util"
+)
+
+const (
+	// Root is the directory on the backup target where the generic driver stores its data
+	Root = "harvester/volumeremotebackups"
+
+	// DefaultChunkSize is the size of the chunks the volume data is split into
+	DefaultChunkSize int64 = 2 << 20
+
+	manifestVersion   = 1
+	manifestFileName  = "manifest.json"
+	chunksDirectory   = "chunks"
+	chunkExtension    = ".blk"
+	compressionMethod = "lz4"
+)
+
+type Mode string
+
+const (
+	// ModeBlock streams the raw content of a block device
+	ModeBlock Mode = "block"
+	// ModeFilesystem streams a tar archive of a mounted filesystem
+	ModeFilesystem Mode = "filesystem"
+)
+
+// Store is the subset of backupstore.BackupStoreDriver used by the mover
+type Store interface {
+	FileExists(filePath string) bool
+	FileTime(filePath string) time.Time
+	Read(src string) (io.ReadCloser, error)
+	Write(dst string, rs io.ReadSeeker) error
+	Remove(path string) error
+	List(path string) ([]string, error)
+}
+
+// Manifest describes the chunks of a volume backup, the chunks are content addressed
+// and shared between all backups on the same backup target.
+type Manifest struct {
+	Version     int     `json:"version"`
+	Mode        Mode    `json:"mode"`
+	Size        int64   `json:"size"`
+	ChunkSize   int64   `json:"chunkSize"`
+	Compression string  `json:"compression"`
+	Chunks      []Chunk `json:"chunks"`
+}
+
+// Chunk is a non-zero chunk of the volume data, zero chunks are not stored
+type Chunk struct {
+	Offset   int64  `json:"offset"`
+	Checksum string `json:"checksum"`
+}
+
+// Stats counts how the chunks of a backup were handled
+type Stats struct {
+	Total        int
+	Uploaded     int
+	Deduplicated int
+	Zero         int
+}
+
+// ManifestPath returns the path of the manifest of a VolumeRemoteBackup,
+// the uid makes it unique when a VolumeRemoteBackup is recreated with the same name.
+func ManifestPath(namespace, name, uid string) string {
+	return path.Join(Root, "volumes", namespace, fmt.Sprintf("%s-%s", name, uid), manifestFileName)
+}
+
+func chunkPath(checksum string) string {
+	return path.Join(Root, chunksDirectory, checksum[0:2], checksum[2:4], checksum+chunkExtension)
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Backup splits src into chunks, uploads the chunks which don't exist on the store yet
+// and writes the manifest to manifestPath once all chunks are uploaded.
+func Backup(store Store, manifestPath string, mode Mode, src io.Reader, chunkSize int64) (*Manifest, *Stats, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	manifest := &Manifest{
+		Version:     manifestVersion,
+		Mode:        mode,
+		ChunkSize:   chunkSize,
+		Compression: compressionMethod,
+	}
+	stats := &Stats{}
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(src, buf)
+		if n > 0 {
+			data := buf[:n]
+			stats.Total++
+			if isZero(data) {
+				stats.Zero++
+			} else {
+				checksum := util.GetChecksum(data)
+				uploaded, err := writeChunk(store, checksum, data)
+				if err != nil {
+					return nil, nil, err
+				}
+				if uploaded {
+					stats.Uploaded++
+				} else {
+					stats.Deduplicated++
+				}
+				manifest.Chunks = append(manifest.Chunks, Chunk{Offset: manifest.Size, Checksum: checksum})
+			}
+			manifest.Size += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read chunk at offset %d: %w", manifest.Size, err)
+		}
+	}
+
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := store.Write(manifestPath, bytes.NewReader(content)); err != nil {
+		return nil, nil, fmt.Errorf("failed to write manifest %s: %w", manifestPath, err)
+	}
+	return manifest, stats, nil
+}
+
+// writeChunk uploads the chunk unless it's already on the store, returns false if it's deduplicated
+func writeChunk(store Store, checksum string, data []byte) (bool, error) {
+	chunkPath := chunkPath(checksum)
+	if store.FileExists(chunkPath) {
+		return false, nil
+	}
+
+	rs, err := util.CompressData(compressionMethod, data)
+	if err != nil {
+		return false, fmt.Errorf("failed to compress chunk %s: %w", checksum, err)
+	}
+	if err := store.Write(chunkPath, rs); err != nil {
+		return false, fmt.Errorf("failed to write chunk %s: %w", checksum, err)
+	}
+	return true, nil
+}
+
+// ReadManifest loads the manifest of a backup from the store
+func ReadManifest(store Store, manifestPath string) (*Manifest, error) {
+	rc, err := store.Read(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", manifestPath, err)
+	}
+	defer rc.Close()
+
+	manifest := &Manifest{}
+	if err := json.NewDecoder(rc).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest %s: %w", manifestPath, err)
+	}
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+// Restore writes the data of the backup to dst. The gaps of zero chunks are skipped with Seek
+// if dst is an io.Seeker, e.g. a freshly provisioned block device, otherwise zeros are written.
+func Restore(store Store, manifest *Manifest, dst io.Writer) error {
+	seeker, seekable := dst.(io.Seeker)
+	zeros := make([]byte, manifest.ChunkSize)
+
+	var offset int64
+	fill := func(end int64) error {
+		if end <= offset {
+			return nil
+		}
+		if seekable {
+			if _, err := seeker.Seek(end, io.SeekStart); err != nil {
+				return err
+			}
+			offset = end
+			return nil
+		}
+		for offset < end {
+			n := min(end-offset, int64(len(zeros)))
+			if _, err := dst.Write(zeros[:n]); err != nil {
+				return err
+			}
+			offset += n
+		}
+		return nil
+	}
+
+	for _, chunk := range manifest.Chunks {
+		if err := fill(chunk.Offset); err != nil {
+			return fmt.Errorf("failed to skip to offset %d: %w", chunk.Offset, err)
+		}
+
+		n, err := readChunk(store, manifest.Compression, chunk.Checksum, dst)
+		if err != nil {
+			return err
+		}
+		offset += n
+	}
+
+	if err := fill(manifest.Size); err != nil {
+		return fmt.Errorf("failed to skip to offset %d: %w", manifest.Size, err)
+	}
+	return nil
+}
+
+func readChunk(store Store, compression, checksum string, dst io.Writer) (int64, error) {
+	rc, err := store.Read(chunkPath(checksum))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read chunk %s: %w", checksum, err)
+	}
+	defer rc.Close()
+
+	r, err := util.DecompressAndVerify(compression, rc, checksum)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decompress chunk %s: %w", checksum, err)
+	}
+	return io.Copy(dst, r)
+}
+
+// Delete removes the manifest of a backup. The chunks are shared with other backups,
+// the ones which are no longer referenced are removed by Sweep.
+func Delete(store Store, manifestPath string) error {
+	if !store.FileExists(manifestPath) {
+		return nil
+	}
+	return store.Remove(path.Dir(manifestPath))
+}
+
+// referencedChunks returns the checksums of the chunks referenced by the manifests of all backups
+func referencedChunks(store Store) (map[string]bool, error) {
+	referenced := map[string]bool{}
+	volumesPath := path.Join(Root, "volumes")
+	namespaces, err := store.List(volumesPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, namespace := range namespaces {
+		backups, err := store.List(path.Join(volumesPath, namespace))
+		if err != nil {
+			return nil, err
+		}
+		for _, backup := range backups {
+			manifestPath := path.Join(volumesPath, namespace, backup, manifestFileName)
+			if !store.FileExists(manifestPath) {
+				continue
+			}
+			// a manifest which can't be read stops the sweep, its chunks would be removed otherwise
+			manifest, err := ReadManifest(store, manifestPath)
+			if err != nil {
+				return nil, err
+			}
+			for _, chunk := range manifest.Chunks {
+				referenced[chunk.Checksum] = true
+			}
+		}
+	}
+	return referenced, nil
+}
+
+// Sweep removes the chunks which aren't referenced by any manifest and returns how many are removed.
+// It must hold the exclusive lock, so that the chunks of a running backup aren't removed before
+// its manifest is written.
+func Sweep(store Store) (int, error) {
+	referenced, err := referencedChunks(store)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	chunksPath := path.Join(Root, chunksDirectory)
+	layer1, err := store.List(chunksPath)
+	if err != nil {
+		return 0, err
+	}
+	for _, dir1 := range layer1 {
+		layer2, err := store.List(path.Join(chunksPath, dir1))
+		if err != nil {
+			return removed, err
+		}
+		for _, dir2 := range layer2 {
+			files, err := store.List(path.Join(chunksPath, dir1, dir2))
+			if err != nil {
+				return removed, err
+			}
+			for _, file := range files {
+				checksum, ok := strings.CutSuffix(file, chunkExtension)
+				if !ok || referenced[checksum] {
+					continue
+				}
+				if err := store.Remove(path.Join(chunksPath, dir1, dir2, file)); err != nil {
+					return removed, fmt.Errorf("failed to remove chunk %s: %w", checksum, err)
+				}
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
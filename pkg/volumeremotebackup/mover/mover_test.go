@@ -0,0 +1,395 @@
+package mover
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/longhorn/backupstore/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	lock   sync.Mutex
+	files  map[string][]byte
+	times  map[string]time.Time
+	writes int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{files: map[string][]byte{}, times: map[string]time.Time{}}
+}
+
+func (s *fakeStore) FileTime(filePath string) time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.times[filePath]
+}
+
+func (s *fakeStore) List(listPath string) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	names := map[string]bool{}
+	for f := range s.files {
+		if rest, ok := strings.CutPrefix(f, listPath+"/"); ok {
+			names[strings.Split(rest, "/")[0]] = true
+		}
+	}
+	var result []string
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (s *fakeStore) FileExists(filePath string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.files[filePath]
+	return ok
+}
+
+func (s *fakeStore) Read(src string) (io.ReadCloser, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	data, ok := s.files[src]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", src)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *fakeStore) Write(dst string, rs io.ReadSeeker) error {
+	data, err := io.ReadAll(rs)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.files[dst] = data
+	s.times[dst] = time.Now()
+	s.writes++
+	return nil
+}
+
+func (s *fakeStore) Remove(path string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for f := range s.files {
+		if f == path || strings.HasPrefix(f, path+"/") {
+			delete(s.files, f)
+			delete(s.times, f)
+		}
+	}
+	return nil
+}
+
+func newVolumeData(chunkSize int64) []byte {
+	var data []byte
+	// chunk 0: unique, chunk 1: zero, chunk 2: same as chunk 0, chunk 3: short tail
+	unique := bytes.Repeat([]byte("harvester"), int(chunkSize))[:chunkSize]
+	data = append(data, unique...)
+	data = append(data, make([]byte, chunkSize)...)
+	data = append(data, unique...)
+	data = append(data, []byte("tail")...)
+	return data
+}
+
+func Test_BackupRestore(t *testing.T) {
+	const chunkSize = 1024
+	store := newFakeStore()
+	data := newVolumeData(chunkSize)
+	manifestPath := ManifestPath("default", "vrb", "uid")
+
+	manifest, stats, err := Backup(store, manifestPath, ModeBlock, bytes.NewReader(data), chunkSize)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), manifest.Size)
+	assert.Equal(t, &Stats{Total: 4, Uploaded: 2, Deduplicated: 1, Zero: 1}, stats)
+	assert.Len(t, manifest.Chunks, 3)
+	assert.Equal(t, manifest.Chunks[0].Checksum, manifest.Chunks[1].Checksum)
+	assert.Equal(t, int64(2*chunkSize), manifest.Chunks[1].Offset)
+
+	restored, err := ReadManifest(store, manifestPath)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, restored)
+
+	var buf bytes.Buffer
+	require.NoError(t, Restore(store, restored, &buf))
+	assert.Equal(t, data, buf.Bytes())
+
+	// the second backup of the same data only writes the manifest
+	writes := store.writes
+	_, stats, err = Backup(store, ManifestPath("default", "vrb2", "uid2"), ModeBlock, bytes.NewReader(data), chunkSize)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Uploaded)
+	assert.Equal(t, writes+1, store.writes)
+
+	require.NoError(t, Delete(store, manifestPath))
+	assert.False(t, store.FileExists(manifestPath))
+	_, err = ReadManifest(store, ManifestPath("default", "vrb2", "uid2"))
+	assert.NoError(t, err)
+}
+
+func Test_RestoreSeekable(t *testing.T) {
+	const chunkSize = 1024
+	store := newFakeStore()
+	data := append(newVolumeData(chunkSize), make([]byte, chunkSize)...)
+	manifestPath := ManifestPath("default", "vrb", "uid")
+
+	manifest, _, err := Backup(store, manifestPath, ModeBlock, bytes.NewReader(data), chunkSize)
+	require.NoError(t, err)
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "disk"))
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, f.Truncate(int64(len(data))))
+
+	require.NoError(t, Restore(store, manifest, f))
+	restored, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	assert.Equal(t, data, restored)
+}
+
+func Test_RestoreCorruptedChunk(t *testing.T) {
+	const chunkSize = 1024
+	store := newFakeStore()
+	manifestPath := ManifestPath("default", "vrb", "uid")
+
+	manifest, _, err := Backup(store, manifestPath, ModeBlock, bytes.NewReader(newVolumeData(chunkSize)), chunkSize)
+	require.NoError(t, err)
+
+	rs, err := util.CompressData(compressionMethod, []byte("corrupted"))
+	require.NoError(t, err)
+	require.NoError(t, store.Write(chunkPath(manifest.Chunks[0].Checksum), rs))
+
+	assert.Error(t, Restore(store, manifest, io.Discard))
+}
+
+func Test_ArchiveExtract(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "dir", "sub"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "dir", "sub", "file"), []byte("content"), 0640))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "empty"), nil, 0600))
+	require.NoError(t, os.Symlink("dir/sub/file", filepath.Join(src, "link")))
+
+	store := newFakeStore()
+	manifestPath := ManifestPath("default", "vrb", "uid")
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(Archive(src, pw))
+	}()
+	manifest, _, err := Backup(store, manifestPath, ModeFilesystem, pr, 1024)
+	require.NoError(t, err)
+
+	dst := t.TempDir()
+	pr, pw = io.Pipe()
+	go func() {
+		pw.CloseWithError(Restore(store, manifest, pw))
+	}()
+	require.NoError(t, Extract(pr, dst))
+
+	content, err := os.ReadFile(filepath.Join(dst, "dir", "sub", "file"))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+
+	info, err := os.Stat(filepath.Join(dst, "dir", "sub", "file"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	link, err := os.Readlink(filepath.Join(dst, "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "dir/sub/file", link)
+
+	info, err = os.Stat(filepath.Join(dst, "empty"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+}
+
+func Test_ExtractInvalidEntry(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../file", Typeflag: tar.TypeReg, Mode: 0600, Size: 7}))
+	_, err := tw.Write([]byte("content"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	dir := t.TempDir()
+	assert.Error(t, Extract(&buf, filepath.Join(dir, "dst")))
+	assert.NoFileExists(t, filepath.Join(dir, "file"))
+}
+
+func Test_ExtractMaliciousArchive(t *testing.T) {
+	outside := t.TempDir()
+
+	tests := []struct {
+		name    string
+		entries []*tar.Header
+	}{
+		{
+			name: "file written through a symlink of the same name",
+			entries: []*tar.Header{
+				{Name: "evil", Typeflag: tar.TypeSymlink, Linkname: filepath.Join(outside, "file")},
+				{Name: "evil", Typeflag: tar.TypeReg, Mode: 0600, Size: 7},
+			},
+		},
+		{
+			name: "file written through a symlink relative to the root",
+			entries: []*tar.Header{
+				{Name: "evil", Typeflag: tar.TypeSymlink, Linkname: "../../../../../../.." + filepath.Join(outside, "file")},
+				{Name: "evil", Typeflag: tar.TypeReg, Mode: 0600, Size: 7},
+			},
+		},
+		{
+			name: "file written in a symlinked directory",
+			entries: []*tar.Header{
+				{Name: "dir", Typeflag: tar.TypeSymlink, Linkname: outside},
+				{Name: "dir/file", Typeflag: tar.TypeReg, Mode: 0600, Size: 7},
+			},
+		},
+		{
+			name: "directory created through a symlink",
+			entries: []*tar.Header{
+				{Name: "dir", Typeflag: tar.TypeSymlink, Linkname: outside},
+				{Name: "dir/file", Typeflag: tar.TypeDir, Mode: 0700},
+			},
+		},
+		{
+			name: "absolute path",
+			entries: []*tar.Header{
+				{Name: filepath.Join(outside, "file"), Typeflag: tar.TypeReg, Mode: 0600, Size: 7},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for _, hdr := range tt.entries {
+				require.NoError(t, tw.WriteHeader(hdr))
+				if hdr.Typeflag == tar.TypeReg {
+					_, err := tw.Write([]byte("content"))
+					require.NoError(t, err)
+				}
+			}
+			require.NoError(t, tw.Close())
+
+			assert.Error(t, Extract(&buf, t.TempDir()))
+			assert.NoFileExists(t, filepath.Join(outside, "file"))
+			assert.NoDirExists(t, filepath.Join(outside, "file"))
+		})
+	}
+}
+
+func Test_Sweep(t *testing.T) {
+	const chunkSize = 1024
+	store := newFakeStore()
+	shared := bytes.Repeat([]byte("shared"), chunkSize)[:chunkSize]
+	unique := bytes.Repeat([]byte("unique"), chunkSize)[:chunkSize]
+	manifestPath := ManifestPath("default", "vrb", "uid")
+	otherManifestPath := ManifestPath("other", "vrb", "uid2")
+
+	manifest, _, err := Backup(store, manifestPath, ModeBlock, bytes.NewReader(append(shared, unique...)), chunkSize)
+	require.NoError(t, err)
+	other, _, err := Backup(store, otherManifestPath, ModeBlock, bytes.NewReader(shared), chunkSize)
+	require.NoError(t, err)
+
+	// nothing is removed while all chunks are referenced
+	removed, err := Sweep(store)
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+
+	require.NoError(t, Delete(store, manifestPath))
+	removed, err = Sweep(store)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.False(t, store.FileExists(chunkPath(manifest.Chunks[1].Checksum)))
+
+	var buf bytes.Buffer
+	require.NoError(t, Restore(store, other, &buf))
+	assert.Equal(t, shared, buf.Bytes())
+
+	require.NoError(t, Delete(store, otherManifestPath))
+	removed, err = Sweep(store)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	chunks, err := store.List(Root + "/" + chunksDirectory)
+	require.NoError(t, err)
+	for _, dir := range chunks {
+		files, err := store.List(Root + "/" + chunksDirectory + "/" + dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	}
+}
+
+func Test_SweepUnreadableManifest(t *testing.T) {
+	const chunkSize = 1024
+	store := newFakeStore()
+	manifestPath := ManifestPath("default", "vrb", "uid")
+
+	manifest, _, err := Backup(store, manifestPath, ModeBlock, bytes.NewReader(newVolumeData(chunkSize)), chunkSize)
+	require.NoError(t, err)
+	require.NoError(t, store.Write(ManifestPath("default", "broken", "uid2"), bytes.NewReader([]byte("{"))))
+
+	_, err = Sweep(store)
+	assert.Error(t, err)
+	assert.True(t, store.FileExists(chunkPath(manifest.Chunks[0].Checksum)))
+}
+
+func Test_Lock(t *testing.T) {
+	lockCheckWaitTime = time.Millisecond
+	store := newFakeStore()
+
+	shared1, err := AcquireLock(store, LockShared)
+	require.NoError(t, err)
+	shared2, err := AcquireLock(store, LockShared)
+	require.NoError(t, err)
+
+	// the sweep waits for the backups and restores
+	_, err = AcquireLock(store, LockExclusive)
+	assert.Error(t, err)
+
+	require.NoError(t, shared1.Release())
+	require.NoError(t, shared2.Release())
+	require.NoError(t, shared2.Release())
+
+	exclusive, err := AcquireLock(store, LockExclusive)
+	require.NoError(t, err)
+
+	// backups and restores wait for the sweep
+	_, err = AcquireLock(store, LockShared)
+	assert.Error(t, err)
+
+	require.NoError(t, exclusive.Release())
+	locks, err := store.List(Root + "/" + locksDirectory)
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+}
+
+func Test_LockExpired(t *testing.T) {
+	lockCheckWaitTime = time.Millisecond
+	store := newFakeStore()
+
+	exclusive, err := AcquireLock(store, LockExclusive)
+	require.NoError(t, err)
+
+	// a lock which isn't refreshed doesn't block the others
+	store.lock.Lock()
+	store.times[lockPath(exclusive.name)] = time.Now().Add(-2 * lockDuration)
+	store.lock.Unlock()
+
+	shared, err := AcquireLock(store, LockShared)
+	require.NoError(t, err)
+	require.NoError(t, shared.Release())
+}
@@ -9,8 +9,8 @@ import (
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
-	"github.com/harvester/harvester/pkg/controller/master/backup"
 	"github.com/harvester/harvester/pkg/util"
+	backuputil "github.com/harvester/harvester/pkg/util/backup"
 	indexeresutil "github.com/harvester/harvester/pkg/util/indexeres"
 	"github.com/harvester/harvester/pkg/webhook/clients"
 )
@@ -30,6 +30,8 @@ const (
 	ImageByStorageClass                   = "harvesterhci.io/image-by-storage-class"
 	VMInstanceMigrationByVM               = "harvesterhci.io/vmim-by-vm"
 	VMByMacAddress                        = "harvesterhci.io/vm-by-macaddr"
+	VMBackupByBackupTargetName            = "harvesterhci.io/vmbackup-by-backup-target-name"
+	ScheduleVMBackupByBackupTargetName    = "harvesterhci.io/svmbackup-by-backup-target-name"
 )
 
 func RegisterIndexers(clients *clients.Clients) {
@@ -38,6 +40,7 @@ func RegisterIndexers(clients *clients.Clients) {
 	vmBackupCache.AddIndexer(VMBackupSnapshotByPVCNamespaceAndName, vmBackupSnapshotByPVCNamespaceAndName)
 	vmBackupCache.AddIndexer(VMBackupByIsProgressing, vmBackupByIsProgressing)
 	vmBackupCache.AddIndexer(VMBackupByStorageClassNameIndex, vmBackupByStorageClassName)
+	vmBackupCache.AddIndexer(VMBackupByBackupTargetName, vmBackupByBackupTargetName)
 
 	vmRestoreCache := clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineRestore().Cache()
 	vmRestoreCache.AddIndexer(VMRestoreByTargetNamespaceAndName, vmRestoreByTargetNamespaceAndName)
@@ -62,6 +65,7 @@ func RegisterIndexers(clients *clients.Clients) {
 	svmBackupCache.AddIndexer(ScheduleVMBackupBySourceVM, scheduleVMBackupBySourceVM)
 	svmBackupCache.AddIndexer(ScheduleVMBackupByCronGranularity, scheduleVMBackupByCronGranularity)
 	svmBackupCache.AddIndexer(ScheduleVMBackupBySuspended, scheduleVMBackupBySuspended)
+	svmBackupCache.AddIndexer(ScheduleVMBackupByBackupTargetName, scheduleVMBackupByBackupTargetName)
 
 	scInformer := clients.StorageFactory.Storage().V1().StorageClass().Cache()
 	scInformer.AddIndexer(indexeresutil.StorageClassBySecretIndex, indexeresutil.StorageClassBySecret)
@@ -94,7 +98,7 @@ func vmBackupSnapshotByPVCNamespaceAndName(obj *harvesterv1.VirtualMachineBackup
 }
 
 func vmBackupByIsProgressing(obj *harvesterv1.VirtualMachineBackup) ([]string, error) {
-	isProgressingStr := strconv.FormatBool(backup.IsBackupProgressing(obj))
+	isProgressingStr := strconv.FormatBool(backuputil.IsBackupProgressing(obj))
 	return []string{string(isProgressingStr)}, nil
 }
 
@@ -106,6 +110,13 @@ func vmBackupByStorageClassName(obj *harvesterv1.VirtualMachineBackup) ([]string
 	return storageClassNames, nil
 }
 
+func vmBackupByBackupTargetName(obj *harvesterv1.VirtualMachineBackup) ([]string, error) {
+	if obj.Spec.BackupTargetName == "" {
+		return []string{}, nil
+	}
+	return []string{obj.Spec.BackupTargetName}, nil
+}
+
 func vmRestoreByTargetNamespaceAndName(obj *harvesterv1.VirtualMachineRestore) ([]string, error) {
 	if obj == nil {
 		return []string{}, nil
@@ -156,6 +167,13 @@ func scheduleVMBackupBySuspended(obj *harvesterv1.ScheduleVMBackup) ([]string, e
 	return []string{string(suspenedStr)}, nil
 }
 
+func scheduleVMBackupByBackupTargetName(obj *harvesterv1.ScheduleVMBackup) ([]string, error) {
+	if obj.Spec.VMBackupSpec.BackupTargetName == "" {
+		return []string{}, nil
+	}
+	return []string{obj.Spec.VMBackupSpec.BackupTargetName}, nil
+}
+
 func imageByStorageClass(obj *harvesterv1.VirtualMachineImage) ([]string, error) {
 	sc, ok := obj.Annotations[util.AnnotationStorageClassName]
 	if !ok {
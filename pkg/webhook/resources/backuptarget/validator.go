@@ -0,0 +1,148 @@
+package backuptarget
+
+import (
+	"fmt"
+
+	longhorntypes "github.com/longhorn/longhorn-manager/types"
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/indexeres"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+const (
+	fieldName             = "metadata.name"
+	fieldType             = "spec.type"
+	fieldEndpoint         = "spec.endpoint"
+	fieldBucketName       = "spec.bucketName"
+	fieldBucketRegion     = "spec.bucketRegion"
+	fieldCredentialSecret = "spec.credentialSecret"
+)
+
+func NewValidator(
+	secretCache ctlcorev1.SecretCache,
+	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache,
+	svmBackupCache ctlharvesterv1.ScheduleVMBackupCache,
+) types.Validator {
+	return &backupTargetValidator{
+		secretCache:    secretCache,
+		vmBackupCache:  vmBackupCache,
+		svmBackupCache: svmBackupCache,
+	}
+}
+
+type backupTargetValidator struct {
+	types.DefaultValidator
+
+	secretCache    ctlcorev1.SecretCache
+	vmBackupCache  ctlharvesterv1.VirtualMachineBackupCache
+	svmBackupCache ctlharvesterv1.ScheduleVMBackupCache
+}
+
+func (v *backupTargetValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{v1beta1.BackupTargetResourceName},
+		Scope:      admissionregv1.ClusterScope,
+		APIGroup:   v1beta1.SchemeGroupVersion.Group,
+		APIVersion: v1beta1.SchemeGroupVersion.Version,
+		ObjectType: &v1beta1.BackupTarget{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+			admissionregv1.Delete,
+		},
+	}
+}
+
+func (v *backupTargetValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	bt := newObj.(*v1beta1.BackupTarget)
+
+	// the Longhorn default backup target is reserved for the backup-target setting
+	if bt.Name == longhorntypes.DefaultBackupTargetName {
+		return werror.NewInvalidError(fmt.Sprintf("name %s is reserved for the backup-target setting", bt.Name), fieldName)
+	}
+
+	return v.validateSpec(bt)
+}
+
+func (v *backupTargetValidator) Update(_ *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
+	oldBT := oldObj.(*v1beta1.BackupTarget)
+	newBT := newObj.(*v1beta1.BackupTarget)
+
+	if newBT.DeletionTimestamp != nil {
+		return nil
+	}
+
+	if oldBT.Spec.Type != newBT.Spec.Type {
+		return werror.NewInvalidError("backup target type can't be changed", fieldType)
+	}
+
+	return v.validateSpec(newBT)
+}
+
+func (v *backupTargetValidator) Delete(_ *types.Request, oldObj runtime.Object) error {
+	bt := oldObj.(*v1beta1.BackupTarget)
+
+	svmBackups, err := v.svmBackupCache.GetByIndex(indexeres.ScheduleVMBackupByBackupTargetName, bt.Name)
+	if err != nil {
+		return werror.NewInternalError(fmt.Sprintf("can't get schedulevmbackups with backup target %s, err: %s", bt.Name, err))
+	}
+	if len(svmBackups) > 0 {
+		return werror.NewBadRequest(fmt.Sprintf("backup target %s is used by schedulevmbackup %s/%s", bt.Name, svmBackups[0].Namespace, svmBackups[0].Name))
+	}
+
+	vmBackups, err := v.vmBackupCache.GetByIndex(indexeres.VMBackupByBackupTargetName, bt.Name)
+	if err != nil {
+		return werror.NewInternalError(fmt.Sprintf("can't get vmbackups with backup target %s, err: %s", bt.Name, err))
+	}
+	if len(vmBackups) > 0 {
+		return werror.NewBadRequest(fmt.Sprintf("backup target %s is used by vmbackup %s/%s", bt.Name, vmBackups[0].Namespace, vmBackups[0].Name))
+	}
+
+	return nil
+}
+
+func (v *backupTargetValidator) validateSpec(bt *v1beta1.BackupTarget) error {
+	switch bt.Spec.Type {
+	case v1beta1.BackupTargetTypeNFS:
+		if bt.Spec.Endpoint == "" {
+			return werror.NewInvalidError("endpoint is required for nfs backup target", fieldEndpoint)
+		}
+	case v1beta1.BackupTargetTypeS3:
+		if bt.Spec.BucketName == "" {
+			return werror.NewInvalidError("bucket name is required for s3 backup target", fieldBucketName)
+		}
+		if bt.Spec.BucketRegion == "" {
+			return werror.NewInvalidError("bucket region is required for s3 backup target", fieldBucketRegion)
+		}
+		return v.validateCredentialSecret(bt)
+	default:
+		return werror.NewInvalidError(fmt.Sprintf("invalid backup target type %s", bt.Spec.Type), fieldType)
+	}
+	return nil
+}
+
+func (v *backupTargetValidator) validateCredentialSecret(bt *v1beta1.BackupTarget) error {
+	ref := bt.Spec.CredentialSecret
+	if ref == nil || ref.Namespace == "" || ref.Name == "" {
+		return werror.NewInvalidError("credential secret namespace and name are required for s3 backup target", fieldCredentialSecret)
+	}
+
+	secret, err := v.secretCache.Get(ref.Namespace, ref.Name)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("can't get credential secret %s/%s, err: %s", ref.Namespace, ref.Name, err), fieldCredentialSecret)
+	}
+
+	for _, key := range []string{util.AWSAccessKey, util.AWSSecretKey} {
+		if len(secret.Data[key]) == 0 {
+			return werror.NewInvalidError(fmt.Sprintf("credential secret %s/%s doesn't contain %s", ref.Namespace, ref.Name, key), fieldCredentialSecret)
+		}
+	}
+	return nil
+}
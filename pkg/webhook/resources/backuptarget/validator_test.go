@@ -0,0 +1,121 @@
+package backuptarget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func Test_backupTargetValidator_Create(t *testing.T) {
+	tests := []struct {
+		name        string
+		bt          *v1beta1.BackupTarget
+		expectError bool
+	}{
+		{
+			name: "reserved name",
+			bt: &v1beta1.BackupTarget{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec: v1beta1.BackupTargetSpec{
+					Type:     v1beta1.BackupTargetTypeNFS,
+					Endpoint: "nfs://10.0.0.1:/exports",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "nfs without endpoint",
+			bt: &v1beta1.BackupTarget{
+				ObjectMeta: metav1.ObjectMeta{Name: "nfs"},
+				Spec: v1beta1.BackupTargetSpec{
+					Type: v1beta1.BackupTargetTypeNFS,
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "valid nfs",
+			bt: &v1beta1.BackupTarget{
+				ObjectMeta: metav1.ObjectMeta{Name: "nfs"},
+				Spec: v1beta1.BackupTargetSpec{
+					Type:     v1beta1.BackupTargetTypeNFS,
+					Endpoint: "nfs://10.0.0.1:/exports",
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "s3 without credential secret",
+			bt: &v1beta1.BackupTarget{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3"},
+				Spec: v1beta1.BackupTargetSpec{
+					Type:         v1beta1.BackupTargetTypeS3,
+					BucketName:   "bucket",
+					BucketRegion: "us-east-1",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "s3 with incomplete credential secret",
+			bt: &v1beta1.BackupTarget{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3"},
+				Spec: v1beta1.BackupTargetSpec{
+					Type:             v1beta1.BackupTargetTypeS3,
+					BucketName:       "bucket",
+					BucketRegion:     "us-east-1",
+					CredentialSecret: &corev1.SecretReference{Namespace: "default", Name: "incomplete"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "valid s3",
+			bt: &v1beta1.BackupTarget{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3"},
+				Spec: v1beta1.BackupTargetSpec{
+					Type:             v1beta1.BackupTargetTypeS3,
+					BucketName:       "bucket",
+					BucketRegion:     "us-east-1",
+					CredentialSecret: &corev1.SecretReference{Namespace: "default", Name: "credential"},
+				},
+			},
+			expectError: false,
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "credential"},
+			Data: map[string][]byte{
+				util.AWSAccessKey: []byte("access"),
+				util.AWSSecretKey: []byte("secret"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "incomplete"},
+			Data: map[string][]byte{
+				util.AWSAccessKey: []byte("access"),
+			},
+		},
+	)
+	validator := NewValidator(fakeclients.SecretCache(clientset.CoreV1().Secrets), nil, nil).(*backupTargetValidator)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validator.Create(nil, tc.bt)
+			if tc.expectError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package imagereplication
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/robfig/cron"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	validationutil "k8s.io/apimachinery/pkg/util/validation"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/types"
+	webhookutil "github.com/harvester/harvester/pkg/webhook/util"
+)
+
+const (
+	fieldCron            = "spec.cron"
+	fieldSelector        = "spec.selector"
+	fieldNames           = "spec.names"
+	fieldTargetNamespace = "spec.target.namespace"
+	fieldTargetExportURL = "spec.target.exportURL"
+	fieldTargetBackend   = "spec.target.backend"
+
+	fieldTargetKubeconfigSecretName = "spec.target.kubeconfigSecretName"
+	fieldTargetDownloadSecretName   = "spec.target.downloadSecretName"
+)
+
+func NewValidator(sar authorizationv1client.SubjectAccessReviewInterface) types.Validator {
+	return &imageReplicationValidator{
+		sar: sar,
+	}
+}
+
+type imageReplicationValidator struct {
+	types.DefaultValidator
+	sar authorizationv1client.SubjectAccessReviewInterface
+}
+
+func (v *imageReplicationValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{v1beta1.ImageReplicationResourceName},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   v1beta1.SchemeGroupVersion.Group,
+		APIVersion: v1beta1.SchemeGroupVersion.Version,
+		ObjectType: &v1beta1.ImageReplication{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (v *imageReplicationValidator) Create(request *types.Request, newObj runtime.Object) error {
+	r := newObj.(*v1beta1.ImageReplication)
+	if err := validateSpec(r); err != nil {
+		return err
+	}
+	return v.checkTargetPermission(request, r)
+}
+
+func (v *imageReplicationValidator) Update(request *types.Request, _ runtime.Object, newObj runtime.Object) error {
+	r := newObj.(*v1beta1.ImageReplication)
+	if r.DeletionTimestamp != nil {
+		return nil
+	}
+	if err := validateSpec(r); err != nil {
+		return err
+	}
+	return v.checkTargetPermission(request, r)
+}
+
+// checkTargetPermission checks the user can create images in the target namespace, since the images are created by
+// the controller on behalf of the user. The remote replication is authorized by the kubeconfig of the remote cluster,
+// but the user must be allowed to get the secrets the controller reads and copies to the remote cluster.
+func (v *imageReplicationValidator) checkTargetPermission(request *types.Request, r *v1beta1.ImageReplication) error {
+	if r.Spec.Target.KubeconfigSecretName != "" {
+		return v.checkSecretPermission(request, r)
+	}
+
+	allowed, err := webhookutil.CanAccess(v.sar, request.UserInfo, authorizationv1.ResourceAttributes{
+		Namespace: r.Spec.Target.Namespace,
+		Verb:      "create",
+		Group:     v1beta1.SchemeGroupVersion.Group,
+		Version:   v1beta1.SchemeGroupVersion.Version,
+		Resource:  v1beta1.VirtualMachineImageResourceName,
+	})
+	if err != nil {
+		return werror.NewInternalError(fmt.Sprintf("failed to check the permission of namespace %s: %v", r.Spec.Target.Namespace, err))
+	}
+	if !allowed {
+		return werror.NewInvalidError(fmt.Sprintf("user %s is not allowed to create images in namespace %s", request.UserInfo.Username, r.Spec.Target.Namespace), fieldTargetNamespace)
+	}
+	return nil
+}
+
+func (v *imageReplicationValidator) checkSecretPermission(request *types.Request, r *v1beta1.ImageReplication) error {
+	for field, name := range map[string]string{
+		fieldTargetKubeconfigSecretName: r.Spec.Target.KubeconfigSecretName,
+		fieldTargetDownloadSecretName:   r.Spec.Target.DownloadSecretName,
+	} {
+		if name == "" {
+			continue
+		}
+		allowed, err := webhookutil.CanGetSecret(v.sar, request.UserInfo, r.Namespace, name)
+		if err != nil {
+			return werror.NewInternalError(fmt.Sprintf("failed to check the permission of secret %s/%s: %v", r.Namespace, name, err))
+		}
+		if !allowed {
+			return werror.NewInvalidError(fmt.Sprintf("user %s is not allowed to get secret %s/%s", request.UserInfo.Username, r.Namespace, name), field)
+		}
+	}
+	return nil
+}
+
+func validateSpec(r *v1beta1.ImageReplication) error {
+	if r.Spec.Cron != "" {
+		if _, err := cron.ParseStandard(r.Spec.Cron); err != nil {
+			return werror.NewInvalidError("invalid cron format", fieldCron)
+		}
+	}
+
+	if r.Spec.Selector == "" && len(r.Spec.Names) == 0 {
+		return werror.NewInvalidError("one of selector and names is required", fieldSelector)
+	}
+
+	if r.Spec.Selector != "" {
+		if _, err := labels.Parse(r.Spec.Selector); err != nil {
+			return werror.NewInvalidError(fmt.Sprintf("invalid selector: %v", err), fieldSelector)
+		}
+	}
+
+	for _, name := range r.Spec.Names {
+		if errs := validationutil.IsDNS1123Subdomain(name); len(errs) != 0 {
+			return werror.NewInvalidError(fmt.Sprintf("invalid image name %q: %v", name, errs), fieldNames)
+		}
+	}
+
+	return validateTarget(r)
+}
+
+func validateTarget(r *v1beta1.ImageReplication) error {
+	target := r.Spec.Target
+	if target.Namespace != "" {
+		if errs := validationutil.IsDNS1123Label(target.Namespace); len(errs) != 0 {
+			return werror.NewInvalidError(fmt.Sprintf("invalid namespace %q: %v", target.Namespace, errs), fieldTargetNamespace)
+		}
+	}
+
+	if target.KubeconfigSecretName == "" {
+		if target.Namespace == "" || target.Namespace == r.Namespace {
+			return werror.NewInvalidError("the images have to be replicated to another namespace in this cluster", fieldTargetNamespace)
+		}
+	} else {
+		exportURL, err := url.Parse(target.ExportURL)
+		if err != nil || (exportURL.Scheme != "http" && exportURL.Scheme != "https") || exportURL.Host == "" {
+			return werror.NewInvalidError("the http(s) URL of this cluster is required by the remote replication", fieldTargetExportURL)
+		}
+	}
+
+	switch target.Backend {
+	case "", v1beta1.VMIBackendBackingImage, v1beta1.VMIBackendCDI:
+	default:
+		return werror.NewInvalidError(fmt.Sprintf("unknown backend %q", target.Backend), fieldTargetBackend)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package imagereplication
+
+import (
+	"testing"
+
+	"github.com/rancher/wrangler/v3/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+func Test_validateSpec(t *testing.T) {
+	newImageReplication := func(cron string, target v1beta1.ImageReplicationTarget, selector string, names ...string) *v1beta1.ImageReplication {
+		return &v1beta1.ImageReplication{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "golden-images"},
+			Spec: v1beta1.ImageReplicationSpec{
+				Cron:     cron,
+				Selector: selector,
+				Names:    names,
+				Target:   target,
+			},
+		}
+	}
+	local := v1beta1.ImageReplicationTarget{Namespace: "team-a"}
+	remote := v1beta1.ImageReplicationTarget{KubeconfigSecretName: "dr-site", ExportURL: "https://192.168.1.10"}
+
+	tests := []struct {
+		name             string
+		imageReplication *v1beta1.ImageReplication
+		expectError      bool
+	}{
+		{
+			name:             "local by selector",
+			imageReplication: newImageReplication("", local, "os=ubuntu"),
+		},
+		{
+			name:             "remote by names on schedule",
+			imageReplication: newImageReplication("0 2 * * *", remote, "", "ubuntu-22.04", "rocky-9"),
+		},
+		{
+			name:             "invalid cron",
+			imageReplication: newImageReplication("0 25 * * *", local, "os=ubuntu"),
+			expectError:      true,
+		},
+		{
+			name:             "no images selected",
+			imageReplication: newImageReplication("", local, ""),
+			expectError:      true,
+		},
+		{
+			name:             "invalid selector",
+			imageReplication: newImageReplication("", local, "os in ubuntu"),
+			expectError:      true,
+		},
+		{
+			name:             "invalid name",
+			imageReplication: newImageReplication("", local, "", "Ubuntu_22.04"),
+			expectError:      true,
+		},
+		{
+			name:             "local to the same namespace",
+			imageReplication: newImageReplication("", v1beta1.ImageReplicationTarget{Namespace: "default"}, "os=ubuntu"),
+			expectError:      true,
+		},
+		{
+			name:             "local without namespace",
+			imageReplication: newImageReplication("", v1beta1.ImageReplicationTarget{}, "os=ubuntu"),
+			expectError:      true,
+		},
+		{
+			name:             "remote to the same namespace",
+			imageReplication: newImageReplication("", v1beta1.ImageReplicationTarget{KubeconfigSecretName: "dr-site", ExportURL: "https://vip.example.com/"}, "os=ubuntu"),
+		},
+		{
+			name:             "remote without export URL",
+			imageReplication: newImageReplication("", v1beta1.ImageReplicationTarget{KubeconfigSecretName: "dr-site"}, "os=ubuntu"),
+			expectError:      true,
+		},
+		{
+			name:             "unknown backend",
+			imageReplication: newImageReplication("", v1beta1.ImageReplicationTarget{Namespace: "team-a", Backend: "lvm"}, "os=ubuntu"),
+			expectError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSpec(tt.imageReplication)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestImageReplicationValidator_Create(t *testing.T) {
+	newImageReplication := func(target v1beta1.ImageReplicationTarget) *v1beta1.ImageReplication {
+		return &v1beta1.ImageReplication{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "golden-images"},
+			Spec: v1beta1.ImageReplicationSpec{
+				Selector: "os=ubuntu",
+				Target:   target,
+			},
+		}
+	}
+	// alice can only create images in team-a and get the dr-site and mirror secrets
+	allowed := map[string]bool{"team-a": true}
+	allowedSecrets := map[string]bool{"dr-site": true, "mirror": true}
+
+	tests := []struct {
+		name             string
+		imageReplication *v1beta1.ImageReplication
+		expectError      bool
+	}{
+		{
+			name:             "local to the namespace with the permission",
+			imageReplication: newImageReplication(v1beta1.ImageReplicationTarget{Namespace: "team-a"}),
+		},
+		{
+			name:             "local to the namespace without the permission",
+			imageReplication: newImageReplication(v1beta1.ImageReplicationTarget{Namespace: "team-b"}),
+			expectError:      true,
+		},
+		{
+			name:             "remote is authorized by the remote cluster",
+			imageReplication: newImageReplication(v1beta1.ImageReplicationTarget{Namespace: "team-b", KubeconfigSecretName: "dr-site", ExportURL: "https://192.168.1.10"}),
+		},
+		{
+			name:             "remote with the download secret",
+			imageReplication: newImageReplication(v1beta1.ImageReplicationTarget{Namespace: "team-b", KubeconfigSecretName: "dr-site", DownloadSecretName: "mirror", ExportURL: "https://192.168.1.10"}),
+		},
+		{
+			name:             "remote without the permission of the kubeconfig secret",
+			imageReplication: newImageReplication(v1beta1.ImageReplicationTarget{Namespace: "team-b", KubeconfigSecretName: "others", ExportURL: "https://192.168.1.10"}),
+			expectError:      true,
+		},
+		{
+			name:             "remote without the permission of the download secret",
+			imageReplication: newImageReplication(v1beta1.ImageReplicationTarget{Namespace: "team-b", KubeconfigSecretName: "dr-site", DownloadSecretName: "others", ExportURL: "https://192.168.1.10"}),
+			expectError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := corefake.NewSimpleClientset()
+			clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				attributes := review.Spec.ResourceAttributes
+				assert.Equal(t, "alice", review.Spec.User)
+				switch attributes.Resource {
+				case v1beta1.VirtualMachineImageResourceName:
+					review.Status.Allowed = attributes.Verb == "create" && allowed[attributes.Namespace]
+				case "secrets":
+					assert.Equal(t, "default", attributes.Namespace)
+					review.Status.Allowed = attributes.Verb == "get" && allowedSecrets[attributes.Name]
+				default:
+					t.Errorf("unexpected resource %s", attributes.Resource)
+				}
+				return true, review, nil
+			})
+			request := &types.Request{
+				Request: &webhook.Request{
+					AdmissionRequest: admissionv1.AdmissionRequest{
+						UserInfo: authenticationv1.UserInfo{Username: "alice"},
+					},
+				},
+			}
+
+			validator := NewValidator(clientset.AuthorizationV1().SubjectAccessReviews())
+			err := validator.Create(request, tt.imageReplication)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/robfig/cron"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 
 	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
@@ -17,6 +18,7 @@ import (
 	backuputil "github.com/harvester/harvester/pkg/util/backup"
 	werror "github.com/harvester/harvester/pkg/webhook/error"
 	"github.com/harvester/harvester/pkg/webhook/indexeres"
+	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachinebackupverification"
 	"github.com/harvester/harvester/pkg/webhook/types"
 )
 
@@ -26,26 +28,39 @@ const (
 	fieldSuspend    = "spec.suspend"
 	fieldVMBackup   = "spec.vmbackup"
 
+	fieldVerification                 = "spec.verification"
+	fieldVerificationScratchNamespace = "spec.verification.scratchNamespace"
+	fieldWindow                       = "spec.window"
+
 	minCronGranularity = time.Hour
 	minCronOffset      = 10 * time.Minute
 )
 
 type scheuldeVMBackupValidator struct {
 	types.DefaultValidator
-	settingCache   ctlharvesterv1.SettingCache
-	secretCache    ctlv1.SecretCache
-	svmbackupCache ctlharvesterv1.ScheduleVMBackupCache
+	settingCache      ctlharvesterv1.SettingCache
+	secretCache       ctlv1.SecretCache
+	svmbackupCache    ctlharvesterv1.ScheduleVMBackupCache
+	backupTargetCache ctlharvesterv1.BackupTargetCache
+	namespaceCache    ctlv1.NamespaceCache
+	sar               authorizationv1client.SubjectAccessReviewInterface
 }
 
 func NewValidator(
 	settingCache ctlharvesterv1.SettingCache,
 	secretCache ctlv1.SecretCache,
 	svmbackupCache ctlharvesterv1.ScheduleVMBackupCache,
+	backupTargetCache ctlharvesterv1.BackupTargetCache,
+	namespaceCache ctlv1.NamespaceCache,
+	sar authorizationv1client.SubjectAccessReviewInterface,
 ) types.Validator {
 	return &scheuldeVMBackupValidator{
-		settingCache:   settingCache,
-		secretCache:    secretCache,
-		svmbackupCache: svmbackupCache,
+		settingCache:      settingCache,
+		secretCache:       secretCache,
+		svmbackupCache:    svmbackupCache,
+		backupTargetCache: backupTargetCache,
+		namespaceCache:    namespaceCache,
+		sar:               sar,
 	}
 }
 
@@ -64,7 +79,19 @@ func (v *scheuldeVMBackupValidator) Resource() types.Resource {
 	}
 }
 
-func (v *scheuldeVMBackupValidator) checkTargetHealth() error {
+func (v *scheuldeVMBackupValidator) checkTargetHealth(backupTargetName string) error {
+	if backupTargetName != "" {
+		bt, err := v.backupTargetCache.Get(backupTargetName)
+		if err != nil {
+			return fmt.Errorf("can't get backup target %s, error: %w", backupTargetName, err)
+		}
+
+		if _, err := backuputil.GetBackupStoreDriver(v.secretCache, backuputil.ConvertBackupTarget(bt)); err != nil {
+			return err
+		}
+		return nil
+	}
+
 	targetSetting, err := v.settingCache.Get(settings.BackupTargetSettingName)
 	if err != nil {
 		return err
@@ -124,7 +151,43 @@ func cronGranularityCheck(v *scheuldeVMBackupValidator, svmbackup *v1beta1.Sched
 	return nil
 }
 
-func (v *scheuldeVMBackupValidator) Create(_ *types.Request, newObj runtime.Object) error {
+// checkVerification only allows verifying backups, snapshots can't be restored into the scratch namespace.
+// The verifications are created by the controller, so the user must be allowed to use the scratch namespace.
+func (v *scheuldeVMBackupValidator) checkVerification(request *types.Request, svmbackup *v1beta1.ScheduleVMBackup) error {
+	if svmbackup.Spec.Verification == nil {
+		return nil
+	}
+
+	if svmbackup.Spec.VMBackupSpec.Type == v1beta1.Snapshot {
+		return werror.NewInvalidError("verification only works with backup type", fieldVerification)
+	}
+
+	if svmbackup.Spec.Verification.Every < 1 {
+		return werror.NewInvalidError("verification every should be at least 1", fieldVerification)
+	}
+
+	return virtualmachinebackupverification.CheckScratchNamespace(v.namespaceCache, v.sar, request, svmbackup.Namespace,
+		svmbackup.Spec.Verification.ScratchNamespace, fieldVerificationScratchNamespace)
+}
+
+// checkWindow only allows backup windows on backups, snapshots don't use the backup target
+func checkWindow(svmbackup *v1beta1.ScheduleVMBackup) error {
+	if svmbackup.Spec.Window == nil {
+		return nil
+	}
+
+	if svmbackup.Spec.VMBackupSpec.Type == v1beta1.Snapshot {
+		return werror.NewInvalidError("backup window only works with backup type", fieldWindow)
+	}
+
+	if err := backuputil.ValidateBackupWindow(svmbackup.Spec.Window); err != nil {
+		return werror.NewInvalidError(err.Error(), fieldWindow)
+	}
+
+	return nil
+}
+
+func (v *scheuldeVMBackupValidator) Create(request *types.Request, newObj runtime.Object) error {
 	newSVMBackup := newObj.(*v1beta1.ScheduleVMBackup)
 
 	if newSVMBackup.Spec.MaxFailure >= newSVMBackup.Spec.Retain {
@@ -135,6 +198,14 @@ func (v *scheuldeVMBackupValidator) Create(_ *types.Request, newObj runtime.Obje
 		return werror.NewInvalidError("invalid cron format", fieldCron)
 	}
 
+	if err := v.checkVerification(request, newSVMBackup); err != nil {
+		return err
+	}
+
+	if err := checkWindow(newSVMBackup); err != nil {
+		return err
+	}
+
 	if !util.SkipCronGranularityCheck(newSVMBackup) {
 		if err := cronGranularityCheck(v, newSVMBackup); err != nil {
 			return werror.NewInvalidError(err.Error(), fieldCron)
@@ -157,14 +228,14 @@ func (v *scheuldeVMBackupValidator) Create(_ *types.Request, newObj runtime.Obje
 		return nil
 	}
 
-	if err := v.checkTargetHealth(); err != nil {
+	if err := v.checkTargetHealth(newSVMBackup.Spec.VMBackupSpec.BackupTargetName); err != nil {
 		return werror.NewInvalidError(err.Error(), fieldSuspend)
 	}
 
 	return nil
 }
 
-func (v *scheuldeVMBackupValidator) Update(_ *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
+func (v *scheuldeVMBackupValidator) Update(request *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
 	newSVMBackup := newObj.(*v1beta1.ScheduleVMBackup)
 	oldSVMBackup := oldObj.(*v1beta1.ScheduleVMBackup)
 
@@ -184,6 +255,17 @@ func (v *scheuldeVMBackupValidator) Update(_ *types.Request, oldObj runtime.Obje
 		return werror.NewInvalidError("invalid cron format", fieldCron)
 	}
 
+	// the scratch namespace is only checked when it's changed, the controller updates the schedule as well
+	if !reflect.DeepEqual(oldSVMBackup.Spec.Verification, newSVMBackup.Spec.Verification) {
+		if err := v.checkVerification(request, newSVMBackup); err != nil {
+			return err
+		}
+	}
+
+	if err := checkWindow(newSVMBackup); err != nil {
+		return err
+	}
+
 	if !util.SkipCronGranularityCheck(newSVMBackup) {
 		if err := cronGranularityCheck(v, newSVMBackup); err != nil {
 			return werror.NewInvalidError(err.Error(), fieldCron)
@@ -199,7 +281,7 @@ func (v *scheuldeVMBackupValidator) Update(_ *types.Request, oldObj runtime.Obje
 		return nil
 	}
 
-	if err := v.checkTargetHealth(); err != nil {
+	if err := v.checkTargetHealth(newSVMBackup.Spec.VMBackupSpec.BackupTargetName); err != nil {
 		return werror.NewInvalidError(err.Error(), fieldSuspend)
 	}
 
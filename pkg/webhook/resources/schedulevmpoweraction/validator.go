@@ -0,0 +1,90 @@
+package schedulevmpoweraction
+
+import (
+	"fmt"
+
+	"github.com/robfig/cron"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	validationutil "k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+const (
+	fieldCron     = "spec.cron"
+	fieldAction   = "spec.action"
+	fieldSelector = "spec.selector"
+	fieldNames    = "spec.names"
+)
+
+var powerActions = map[v1beta1.VMPowerAction]bool{
+	v1beta1.VMPowerActionStart:      true,
+	v1beta1.VMPowerActionStop:       true,
+	v1beta1.VMPowerActionRestart:    true,
+	v1beta1.VMPowerActionSoftReboot: true,
+}
+
+func NewValidator() types.Validator {
+	return &svmPowerActionValidator{}
+}
+
+type svmPowerActionValidator struct {
+	types.DefaultValidator
+}
+
+func (v *svmPowerActionValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{v1beta1.ScheduleVMPowerActionResourceName},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   v1beta1.SchemeGroupVersion.Group,
+		APIVersion: v1beta1.SchemeGroupVersion.Version,
+		ObjectType: &v1beta1.ScheduleVMPowerAction{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (v *svmPowerActionValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	return validateSpec(newObj.(*v1beta1.ScheduleVMPowerAction))
+}
+
+func (v *svmPowerActionValidator) Update(_ *types.Request, _ runtime.Object, newObj runtime.Object) error {
+	svmPowerAction := newObj.(*v1beta1.ScheduleVMPowerAction)
+	if svmPowerAction.DeletionTimestamp != nil {
+		return nil
+	}
+	return validateSpec(svmPowerAction)
+}
+
+func validateSpec(svmPowerAction *v1beta1.ScheduleVMPowerAction) error {
+	if _, err := cron.ParseStandard(svmPowerAction.Spec.Cron); err != nil {
+		return werror.NewInvalidError("invalid cron format", fieldCron)
+	}
+
+	if !powerActions[svmPowerAction.Spec.Action] {
+		return werror.NewInvalidError(fmt.Sprintf("unknown power action %q", svmPowerAction.Spec.Action), fieldAction)
+	}
+
+	if svmPowerAction.Spec.Selector == "" && len(svmPowerAction.Spec.Names) == 0 {
+		return werror.NewInvalidError("one of selector and names is required", fieldSelector)
+	}
+
+	if svmPowerAction.Spec.Selector != "" {
+		if _, err := labels.Parse(svmPowerAction.Spec.Selector); err != nil {
+			return werror.NewInvalidError(fmt.Sprintf("invalid selector: %v", err), fieldSelector)
+		}
+	}
+
+	for _, name := range svmPowerAction.Spec.Names {
+		if errs := validationutil.IsDNS1123Subdomain(name); len(errs) != 0 {
+			return werror.NewInvalidError(fmt.Sprintf("invalid VM name %q: %v", name, errs), fieldNames)
+		}
+	}
+	return nil
+}
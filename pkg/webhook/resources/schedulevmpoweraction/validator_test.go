@@ -0,0 +1,75 @@
+package schedulevmpoweraction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func Test_validateSpec(t *testing.T) {
+	newSVMPowerAction := func(cron string, action v1beta1.VMPowerAction, selector string, names ...string) *v1beta1.ScheduleVMPowerAction {
+		return &v1beta1.ScheduleVMPowerAction{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "stop-at-night"},
+			Spec: v1beta1.ScheduleVMPowerActionSpec{
+				Cron:     cron,
+				Action:   action,
+				Selector: selector,
+				Names:    names,
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		svmPowerAction *v1beta1.ScheduleVMPowerAction
+		expectError    bool
+	}{
+		{
+			name:           "stop by selector",
+			svmPowerAction: newSVMPowerAction("0 20 * * 1-5", v1beta1.VMPowerActionStop, "env=dev"),
+		},
+		{
+			name:           "start by names",
+			svmPowerAction: newSVMPowerAction("0 8 * * 1-5", v1beta1.VMPowerActionStart, "", "vm1", "vm2"),
+		},
+		{
+			name:           "invalid cron",
+			svmPowerAction: newSVMPowerAction("0 25 * * *", v1beta1.VMPowerActionStop, "env=dev"),
+			expectError:    true,
+		},
+		{
+			name:           "unknown action",
+			svmPowerAction: newSVMPowerAction("0 20 * * *", "pause", "env=dev"),
+			expectError:    true,
+		},
+		{
+			name:           "no VMs selected",
+			svmPowerAction: newSVMPowerAction("0 20 * * *", v1beta1.VMPowerActionStop, ""),
+			expectError:    true,
+		},
+		{
+			name:           "invalid selector",
+			svmPowerAction: newSVMPowerAction("0 20 * * *", v1beta1.VMPowerActionStop, "env in dev"),
+			expectError:    true,
+		},
+		{
+			name:           "invalid name",
+			svmPowerAction: newSVMPowerAction("0 20 * * *", v1beta1.VMPowerActionRestart, "", "VM_1"),
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSpec(tt.svmPowerAction)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -112,6 +112,10 @@ var validateSettingFuncs = map[string]validateSettingFunc{
 	settings.AdditionalGuestMemoryOverheadRatioName:            validateAdditionalGuestMemoryOverheadRatio,
 	settings.MaxHotplugRatioSettingName:                        validateMaxHotplugRatio,
 	settings.LHIMResourcesSettingName:                          validateLHIMResources,
+	settings.BackupThrottleSettingName:                         validateBackupThrottle,
+	settings.VMMigrationPoliciesSettingName:                    validateVMMigrationPolicies,
+	settings.VMImageSignaturePolicySettingName:                 validateVMImageSignaturePolicy,
+	settings.VMImageLifecycleSettingName:                       validateVMImageLifecycle,
 }
 
 type validateSettingUpdateFunc func(request *types.Request, oldSetting *v1beta1.Setting, newSetting *v1beta1.Setting) error
@@ -134,6 +138,10 @@ var validateSettingUpdateFuncs = map[string]validateSettingUpdateFunc{
 	settings.KubeconfigDefaultTokenTTLMinutesSettingName:       validateUpdateKubeConfigTTLSetting,
 	settings.AdditionalGuestMemoryOverheadRatioName:            validateUpdateAdditionalGuestMemoryOverheadRatio,
 	settings.MaxHotplugRatioSettingName:                        validateUpdateMaxHotplugRatio,
+	settings.BackupThrottleSettingName:                         validateUpdateBackupThrottle,
+	settings.VMMigrationPoliciesSettingName:                    validateUpdateVMMigrationPolicies,
+	settings.VMImageSignaturePolicySettingName:                 validateUpdateVMImageSignaturePolicy,
+	settings.VMImageLifecycleSettingName:                       validateUpdateVMImageLifecycle,
 }
 
 type validateSettingDeleteFunc func(setting *v1beta1.Setting) error
@@ -428,6 +436,158 @@ func validateUpdateOvercommitConfig(_ *types.Request, _ *v1beta1.Setting, newSet
 	return validateOvercommitConfig(newSetting)
 }
 
+func validateBackupThrottleHelper(field, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	throttle, err := settings.DecodeBackupThrottle(value)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("Invalid JSON: %s", value), field)
+	}
+
+	if throttle.Window != nil {
+		if err := backuputil.ValidateBackupWindow(throttle.Window); err != nil {
+			return werror.NewInvalidError(err.Error(), field)
+		}
+	}
+
+	if throttle.MaxConcurrentVolumeBackups < 0 {
+		return werror.NewInvalidError("maxConcurrentVolumeBackups can't be negative", field)
+	}
+	return nil
+}
+
+func validateBackupThrottle(setting *v1beta1.Setting) error {
+	if err := validateBackupThrottleHelper(settings.KeywordDefault, setting.Default); err != nil {
+		return err
+	}
+
+	return validateBackupThrottleHelper(settings.KeywordValue, setting.Value)
+}
+
+func validateUpdateBackupThrottle(_ *types.Request, _ *v1beta1.Setting, newSetting *v1beta1.Setting) error {
+	return validateBackupThrottle(newSetting)
+}
+
+func validateVMMigrationPoliciesHelper(field, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	policies, err := settings.DecodeVMMigrationPolicies(value)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("Invalid JSON: %s", value), field)
+	}
+
+	names := map[string]bool{}
+	for _, policy := range policies {
+		// the name is used as label value and in the name of the KubeVirt MigrationPolicy
+		if errs := validation.IsDNS1123Label(policy.Name); len(errs) > 0 {
+			return werror.NewInvalidError(fmt.Sprintf("invalid policy name %q: %s", policy.Name, strings.Join(errs, ", ")), field)
+		}
+		if names[policy.Name] {
+			return werror.NewInvalidError(fmt.Sprintf("duplicated policy name %q", policy.Name), field)
+		}
+		names[policy.Name] = true
+
+		if policy.BandwidthPerMigration != nil && policy.BandwidthPerMigration.Sign() < 0 {
+			return werror.NewInvalidError(fmt.Sprintf("bandwidthPerMigration of policy %s can't be negative", policy.Name), field)
+		}
+		if policy.CompletionTimeoutPerGiB != nil && *policy.CompletionTimeoutPerGiB <= 0 {
+			return werror.NewInvalidError(fmt.Sprintf("completionTimeoutPerGiB of policy %s should be greater than 0", policy.Name), field)
+		}
+		if _, err := labels.ValidatedSelectorFromSet(policy.NodeSelector); err != nil {
+			return werror.NewInvalidError(fmt.Sprintf("invalid nodeSelector of policy %s: %v", policy.Name, err), field)
+		}
+	}
+	return nil
+}
+
+func validateVMMigrationPolicies(setting *v1beta1.Setting) error {
+	if err := validateVMMigrationPoliciesHelper(settings.KeywordDefault, setting.Default); err != nil {
+		return err
+	}
+
+	return validateVMMigrationPoliciesHelper(settings.KeywordValue, setting.Value)
+}
+
+func validateUpdateVMMigrationPolicies(_ *types.Request, _ *v1beta1.Setting, newSetting *v1beta1.Setting) error {
+	return validateVMMigrationPolicies(newSetting)
+}
+
+func validateVMImageSignaturePolicyHelper(field, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	policy, err := settings.DecodeVMImageSignaturePolicy(value)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("Invalid JSON: %s", value), field)
+	}
+
+	if (policy.PublicKeySecretNamespace == "") != (policy.PublicKeySecretName == "") {
+		return werror.NewInvalidError("publicKeySecretNamespace and publicKeySecretName should be set together", field)
+	}
+	if policy.Required && policy.PublicKeySecretName == "" {
+		return werror.NewInvalidError("the trusted public key is required when signatures are required", field)
+	}
+	if policy.PublicKeySecretNamespace != "" {
+		if errs := validation.IsDNS1123Label(policy.PublicKeySecretNamespace); len(errs) > 0 {
+			return werror.NewInvalidError(fmt.Sprintf("invalid publicKeySecretNamespace %q: %s", policy.PublicKeySecretNamespace, strings.Join(errs, ", ")), field)
+		}
+		if errs := validation.IsDNS1123Subdomain(policy.PublicKeySecretName); len(errs) > 0 {
+			return werror.NewInvalidError(fmt.Sprintf("invalid publicKeySecretName %q: %s", policy.PublicKeySecretName, strings.Join(errs, ", ")), field)
+		}
+	}
+	return nil
+}
+
+func validateVMImageSignaturePolicy(setting *v1beta1.Setting) error {
+	if err := validateVMImageSignaturePolicyHelper(settings.KeywordDefault, setting.Default); err != nil {
+		return err
+	}
+
+	return validateVMImageSignaturePolicyHelper(settings.KeywordValue, setting.Value)
+}
+
+func validateUpdateVMImageSignaturePolicy(_ *types.Request, _ *v1beta1.Setting, newSetting *v1beta1.Setting) error {
+	return validateVMImageSignaturePolicy(newSetting)
+}
+
+func validateVMImageLifecycleHelper(field, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	lifecycle, err := settings.DecodeVMImageLifecycle(value)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("Invalid JSON: %s", value), field)
+	}
+
+	switch lifecycle.DeprecatedImagePolicy {
+	case "", settings.DeprecatedImagePolicyWarn, settings.DeprecatedImagePolicyRefuse:
+	default:
+		return werror.NewInvalidError(fmt.Sprintf("deprecatedImagePolicy should be %s or %s", settings.DeprecatedImagePolicyWarn, settings.DeprecatedImagePolicyRefuse), field)
+	}
+	if _, err := lifecycle.GC.GetTTL(); err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("invalid gc.ttl: %v", err), field)
+	}
+	return nil
+}
+
+func validateVMImageLifecycle(setting *v1beta1.Setting) error {
+	if err := validateVMImageLifecycleHelper(settings.KeywordDefault, setting.Default); err != nil {
+		return err
+	}
+
+	return validateVMImageLifecycleHelper(settings.KeywordValue, setting.Value)
+}
+
+func validateUpdateVMImageLifecycle(_ *types.Request, _ *v1beta1.Setting, newSetting *v1beta1.Setting) error {
+	return validateVMImageLifecycle(newSetting)
+}
+
 func validateVMForceResetPolicyHelper(value string) error {
 	if value == "" {
 		return nil
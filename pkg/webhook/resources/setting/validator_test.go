@@ -1488,6 +1488,65 @@ func Test_validateMaxHotplugRatio(t *testing.T) {
 	}
 }
 
+func Test_validateBackupThrottle(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   *v1beta1.Setting
+		errMsg string
+	}{
+		{
+			name: "ok to create backup-throttle with empty value",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.BackupThrottleSettingName},
+				Default:    "{}",
+			},
+		},
+		{
+			name: "ok to create backup-throttle with valid value",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.BackupThrottleSettingName},
+				Default:    "{}",
+				Value:      `{"window":{"start":"22:00","end":"06:00","timeZone":"Europe/Berlin"},"maxConcurrentVolumeBackups":4}`,
+			},
+		},
+		{
+			name: "invalid json",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.BackupThrottleSettingName},
+				Value:      `{"window":`,
+			},
+			errMsg: "Invalid JSON",
+		},
+		{
+			name: "invalid window",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.BackupThrottleSettingName},
+				Value:      `{"window":{"start":"22:00","end":"6"}}`,
+			},
+			errMsg: "invalid end",
+		},
+		{
+			name: "negative limit",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.BackupThrottleSettingName},
+				Value:      `{"maxConcurrentVolumeBackups":-1}`,
+			},
+			errMsg: "can't be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBackupThrottle(tt.args)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
 func Test_validateStorageNetwork_Update_InProgress(t *testing.T) {
 	clientset := fake.NewSimpleClientset()
 	v := NewValidator(fakeclients.HarvesterSettingCache(clientset.HarvesterhciV1beta1().Settings), nil, nil, nil, nil, fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines), nil, nil, nil, fakeclients.LonghornVolumeCache(clientset.LonghornV1beta2().Volumes), fakeclients.PersistentVolumeClaimCache(clientset.CoreV1().PersistentVolumeClaims), nil, nil, nil, fakeclients.LonghornNodeCache(clientset.LonghornV1beta2().Nodes), nil)
@@ -2283,3 +2342,220 @@ func Test_checkRWXNetworkRangeValid(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateVMMigrationPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   *v1beta1.Setting
+		errMsg string
+	}{
+		{
+			name: "ok to create vm-migration-policies with empty value",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+				Default:    "[]",
+			},
+		},
+		{
+			name: "ok to create vm-migration-policies with valid value",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+				Default:    "[]",
+				Value:      `[{"name":"database","allowPostCopy":true,"bandwidthPerMigration":"1Gi","completionTimeoutPerGiB":300,"nodeSelector":{"tier":"db"}}]`,
+			},
+		},
+		{
+			name: "invalid json",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+				Value:      `[{"name":`,
+			},
+			errMsg: "Invalid JSON",
+		},
+		{
+			name: "invalid name",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+				Value:      `[{"name":"Database"}]`,
+			},
+			errMsg: "invalid policy name",
+		},
+		{
+			name: "duplicated name",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+				Value:      `[{"name":"database"},{"name":"database","allowPostCopy":true}]`,
+			},
+			errMsg: "duplicated policy name",
+		},
+		{
+			name: "negative bandwidth",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+				Value:      `[{"name":"database","bandwidthPerMigration":"-1Mi"}]`,
+			},
+			errMsg: "can't be negative",
+		},
+		{
+			name: "zero completion timeout",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+				Value:      `[{"name":"database","completionTimeoutPerGiB":0}]`,
+			},
+			errMsg: "should be greater than 0",
+		},
+		{
+			name: "invalid node selector",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+				Value:      `[{"name":"database","nodeSelector":{"tier":"db/primary"}}]`,
+			},
+			errMsg: "invalid nodeSelector",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVMMigrationPolicies(tt.args)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func Test_validateVMImageSignaturePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   *v1beta1.Setting
+		errMsg string
+	}{
+		{
+			name: "ok to create vm-image-signature-policy with default value",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageSignaturePolicySettingName},
+				Default:    "{}",
+			},
+		},
+		{
+			name: "ok to require signatures with a trusted public key",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageSignaturePolicySettingName},
+				Default:    "{}",
+				Value:      `{"required":true,"publicKeySecretNamespace":"harvester-system","publicKeySecretName":"image-signing-key"}`,
+			},
+		},
+		{
+			name: "invalid json",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageSignaturePolicySettingName},
+				Value:      `{"required":`,
+			},
+			errMsg: "Invalid JSON",
+		},
+		{
+			name: "secret name without namespace",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageSignaturePolicySettingName},
+				Value:      `{"required":true,"publicKeySecretName":"image-signing-key"}`,
+			},
+			errMsg: "should be set together",
+		},
+		{
+			name: "required signatures without a trusted public key",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageSignaturePolicySettingName},
+				Value:      `{"required":true}`,
+			},
+			errMsg: "trusted public key is required",
+		},
+		{
+			name: "invalid secret name",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageSignaturePolicySettingName},
+				Value:      `{"publicKeySecretNamespace":"default","publicKeySecretName":"Image_Key"}`,
+			},
+			errMsg: "invalid publicKeySecretName",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVMImageSignaturePolicy(tt.args)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func Test_validateVMImageLifecycle(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   *v1beta1.Setting
+		errMsg string
+	}{
+		{
+			name: "ok to create vm-image-lifecycle with default value",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageLifecycleSettingName},
+				Default:    "{}",
+			},
+		},
+		{
+			name: "ok to refuse deprecated images and collect unused images",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageLifecycleSettingName},
+				Default:    "{}",
+				Value:      `{"deprecatedImagePolicy":"refuse","gc":{"enabled":true,"ttl":"168h","deprecatedOnly":true}}`,
+			},
+		},
+		{
+			name: "invalid json",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageLifecycleSettingName},
+				Value:      `{"gc":`,
+			},
+			errMsg: "Invalid JSON",
+		},
+		{
+			name: "unknown deprecated image policy",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageLifecycleSettingName},
+				Value:      `{"deprecatedImagePolicy":"ignore"}`,
+			},
+			errMsg: "deprecatedImagePolicy should be",
+		},
+		{
+			name: "invalid ttl",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageLifecycleSettingName},
+				Value:      `{"gc":{"enabled":true,"ttl":"30d"}}`,
+			},
+			errMsg: "invalid gc.ttl",
+		},
+		{
+			name: "negative ttl",
+			args: &v1beta1.Setting{
+				ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageLifecycleSettingName},
+				Value:      `{"gc":{"enabled":true,"ttl":"-1h"}}`,
+			},
+			errMsg: "invalid gc.ttl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVMImageLifecycle(tt.args)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
@@ -9,11 +9,17 @@ import (
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 
+	"github.com/harvester/harvester/pkg/ref"
 	werror "github.com/harvester/harvester/pkg/webhook/error"
 	"github.com/harvester/harvester/pkg/webhook/types"
 )
 
+const (
+	maxVMs = 10
+)
+
 func NewValidator(namespaceCache v1.NamespaceCache) types.Validator {
 	return &supportBundleValidator{
 		namespaceCache: namespaceCache,
@@ -53,7 +59,10 @@ func (v *supportBundleValidator) Update(_ *types.Request, oldObj, newObj runtime
 func (v *supportBundleValidator) Create(_ *types.Request, obj runtime.Object) error {
 	supportBundle := obj.(*v1beta1.SupportBundle)
 
-	return v.checkExtraCollectionNamespaces(supportBundle)
+	if err := v.checkExtraCollectionNamespaces(supportBundle); err != nil {
+		return err
+	}
+	return v.checkVMs(supportBundle)
 }
 
 func (v *supportBundleValidator) checkExtraCollectionNamespaces(supportBundle *v1beta1.SupportBundle) error {
@@ -74,3 +83,18 @@ func (v *supportBundleValidator) checkExtraCollectionNamespaces(supportBundle *v
 
 	return nil
 }
+
+func (v *supportBundleValidator) checkVMs(supportBundle *v1beta1.SupportBundle) error {
+	if len(supportBundle.Spec.VMs) > maxVMs {
+		return werror.NewBadRequest(fmt.Sprintf("at most %d VMs can be captured into a support bundle", maxVMs))
+	}
+
+	for _, vm := range supportBundle.Spec.VMs {
+		namespace, name := ref.Parse(vm)
+		if len(validation.IsDNS1123Label(namespace)) != 0 || len(validation.IsDNS1123Subdomain(name)) != 0 {
+			return werror.NewBadRequest(fmt.Sprintf("invalid VM %q, it should be in the format of namespace/name", vm))
+		}
+	}
+
+	return nil
+}
@@ -104,6 +104,51 @@ func TestSupportBundleValidator_Create(t *testing.T) {
 			namespaces:    []*corev1.Namespace{},
 			expectedError: false,
 		},
+		{
+			name: "support bundle with VMs",
+			supportBundle: &harvesterv1.SupportBundle{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-bundle",
+					Namespace: "default",
+				},
+				Spec: harvesterv1.SupportBundleSpec{
+					Description: "test bundle",
+					VMs:         []string{"default/vm1", "dev/vm2"},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "support bundle with VM without namespace",
+			supportBundle: &harvesterv1.SupportBundle{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-bundle",
+					Namespace: "default",
+				},
+				Spec: harvesterv1.SupportBundleSpec{
+					Description: "test bundle",
+					VMs:         []string{"vm1"},
+				},
+			},
+			expectedError: true,
+			errorMessage:  `invalid VM "vm1"`,
+		},
+		{
+			name: "support bundle with too many VMs",
+			supportBundle: &harvesterv1.SupportBundle{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-bundle",
+					Namespace: "default",
+				},
+				Spec: harvesterv1.SupportBundleSpec{
+					Description: "test bundle",
+					VMs: []string{"default/vm1", "default/vm2", "default/vm3", "default/vm4", "default/vm5", "default/vm6",
+						"default/vm7", "default/vm8", "default/vm9", "default/vm10", "default/vm11"},
+				},
+			},
+			expectedError: true,
+			errorMessage:  "at most 10 VMs",
+		},
 	}
 
 	for _, tt := range tests {
@@ -14,14 +14,20 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctlharvestercorev1 "github.com/harvester/harvester/pkg/generated/controllers/core/v1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	ctlcniv1 "github.com/harvester/harvester/pkg/generated/controllers/k8s.cni.cncf.io/v1"
 	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
+	backuputil "github.com/harvester/harvester/pkg/util/backup"
+	"github.com/harvester/harvester/pkg/util/bootorder"
 	"github.com/harvester/harvester/pkg/util/resourcequota"
 	vmutil "github.com/harvester/harvester/pkg/util/virtualmachine"
 	werror "github.com/harvester/harvester/pkg/webhook/error"
@@ -43,6 +49,8 @@ func NewValidator(
 	kubevirtCache ctlkubevirtv1.KubeVirtCache,
 	scCache ctlstoragev1.StorageClassCache,
 	settingCache ctlharvesterv1.SettingCache,
+	imageCache ctlharvesterv1.VirtualMachineImageCache,
+	vmDownloaderCache ctlharvesterv1.VirtualMachineDownloaderCache,
 ) types.Validator {
 	return &vmValidator{
 		pvcCache:      pvcCache,
@@ -53,6 +61,9 @@ func NewValidator(
 		kubevirtCache: kubevirtCache,
 		scCache:       scCache,
 		settingCache:  settingCache,
+		imageCache:    imageCache,
+
+		vmDownloaderCache: vmDownloaderCache,
 
 		rqCalculator: resourcequota.NewCalculator(nsCache, podCache, rqCache, vmimCache, settingCache),
 	}
@@ -68,7 +79,10 @@ type vmValidator struct {
 	kubevirtCache ctlkubevirtv1.KubeVirtCache
 	scCache       ctlstoragev1.StorageClassCache
 	settingCache  ctlharvesterv1.SettingCache
+	imageCache    ctlharvesterv1.VirtualMachineImageCache
 	rqCalculator  *resourcequota.Calculator
+
+	vmDownloaderCache ctlharvesterv1.VirtualMachineDownloaderCache
 }
 
 func (v *vmValidator) Resource() types.Resource {
@@ -219,7 +233,7 @@ func comparemacs(macList1 map[string]string, macList2 map[string]string) bool {
 	return false
 }
 
-func (v *vmValidator) Create(_ *types.Request, newObj runtime.Object) error {
+func (v *vmValidator) Create(request *types.Request, newObj runtime.Object) error {
 	vm := newObj.(*kubevirtv1.VirtualMachine)
 	if vm == nil {
 		return nil
@@ -245,10 +259,14 @@ func (v *vmValidator) Create(_ *types.Request, newObj runtime.Object) error {
 		return err
 	}
 
+	if err := v.checkDeprecatedImages(request, nil, vm); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func (v *vmValidator) Update(_ *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
+func (v *vmValidator) Update(request *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
 	newVM := newObj.(*kubevirtv1.VirtualMachine)
 	if newVM == nil {
 		return nil
@@ -278,6 +296,13 @@ func (v *vmValidator) Update(_ *types.Request, oldObj runtime.Object, newObj run
 		}
 	}
 
+	// Prevent users to start VM when it's being exported, the export reads its volumes.
+	if v.checkVMStartingStatus(oldVM, newVM) {
+		if err := v.checkVMExport(newVM); err != nil {
+			return err
+		}
+	}
+
 	// Check volume annotations
 	entries, err := v.checkVolumeAnnotations(oldVM, newVM)
 	if err != nil {
@@ -293,6 +318,10 @@ func (v *vmValidator) Update(_ *types.Request, oldObj runtime.Object, newObj run
 		return err
 	}
 
+	if err := v.checkDeprecatedImages(request, oldVM, newVM); err != nil {
+		return err
+	}
+
 	// This logic will return in case interfaces are existing and not changed
 	// make sure new validation logic is added above this comment
 	if oldVM.Spec.Template != nil && newVM.Spec.Template != nil && reflect.DeepEqual(oldVM.Spec.Template.Spec.Domain.Devices.Interfaces, newVM.Spec.Template.Spec.Domain.Devices.Interfaces) {
@@ -330,6 +359,12 @@ func (v *vmValidator) checkVMSpec(vm *kubevirtv1.VirtualMachine) error {
 	if err := v.checkReservedMemoryAnnotation(vm); err != nil {
 		return err
 	}
+	if err := v.checkBootOrderAnnotations(vm); err != nil {
+		return err
+	}
+	if _, err := backuputil.GetGuestHooks(vm); err != nil {
+		return werror.NewInvalidError(err.Error(), fmt.Sprintf("metadata.annotations[%s]", util.AnnotationBackupGuestHooks))
+	}
 	return v.rqCalculator.CheckIfVMCanStartByResourceQuota(vm)
 }
 
@@ -392,6 +427,18 @@ func (v *vmValidator) checkVMStoppingStatus(oldVM *kubevirtv1.VirtualMachine, ne
 	return false
 }
 
+func (v *vmValidator) checkVMStartingStatus(oldVM *kubevirtv1.VirtualMachine, newVM *kubevirtv1.VirtualMachine) bool {
+	oldRunStrategy, _ := oldVM.RunStrategy()
+	newRunStrategy, _ := newVM.RunStrategy()
+	if oldRunStrategy == kubevirtv1.RunStrategyHalted && newRunStrategy != kubevirtv1.RunStrategyHalted {
+		return true
+	}
+
+	// KubeVirt sends start request when users start a VM of the manual or rerun-on-failure run strategy
+	return len(newVM.Status.StateChangeRequests) != 0 && newVM.Status.StateChangeRequests[0].Action == kubevirtv1.StartRequest &&
+		(len(oldVM.Status.StateChangeRequests) == 0 || oldVM.Status.StateChangeRequests[0].Action != kubevirtv1.StartRequest)
+}
+
 func (v *vmValidator) checkVolumeClaimTemplatesAnnotation(vm *kubevirtv1.VirtualMachine) error {
 	volumeClaimTemplatesStr, ok := vm.Annotations[util.AnnotationVolumeClaimTemplates]
 	if !ok || volumeClaimTemplatesStr == "" {
@@ -556,6 +603,75 @@ func (v *vmValidator) checkOccupiedPVCs(vm *kubevirtv1.VirtualMachine) error {
 	return nil
 }
 
+// checkDeprecatedImages warns or refuses the VM using the deprecated images according to the vm-image-lifecycle
+// setting, only the images newly added to the VM are checked
+func (v *vmValidator) checkDeprecatedImages(request *types.Request, oldVM, newVM *kubevirtv1.VirtualMachine) error {
+	oldImageIDs := []string{}
+	if oldVM != nil {
+		oldImageIDs = getVolumeClaimTemplateImageIDs(oldVM)
+	}
+
+	var deprecated []string
+	for _, imageID := range getVolumeClaimTemplateImageIDs(newVM) {
+		if slices.Contains(oldImageIDs, imageID) {
+			continue
+		}
+		namespace, name := ref.Parse(imageID)
+		image, err := v.imageCache.Get(namespace, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return werror.NewInternalError(fmt.Sprintf("failed to get image %s, err: %s", imageID, err))
+		}
+		if image.Spec.Deprecated && !slices.Contains(deprecated, imageID) {
+			deprecated = append(deprecated, imageID)
+		}
+	}
+	if len(deprecated) == 0 {
+		return nil
+	}
+
+	lifecycle, err := v.getVMImageLifecycle()
+	if err != nil {
+		return werror.NewInternalError(err.Error())
+	}
+	for _, imageID := range deprecated {
+		message := fmt.Sprintf("image %s is deprecated", imageID)
+		if lifecycle.RefuseDeprecatedImages() {
+			return werror.NewInvalidError(message, "metadata.annotations")
+		}
+		request.AddWarning(message)
+	}
+	return nil
+}
+
+func (v *vmValidator) getVMImageLifecycle() (*settings.VMImageLifecycle, error) {
+	setting, err := v.settingCache.Get(settings.VMImageLifecycleSettingName)
+	if apierrors.IsNotFound(err) {
+		return settings.DecodeVMImageLifecycle(settings.VMImageLifecycleSet.Default)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get setting %s: %w", settings.VMImageLifecycleSettingName, err)
+	}
+	return settings.DecodeVMImageLifecycle(setting.EffectiveValue())
+}
+
+func getVolumeClaimTemplateImageIDs(vm *kubevirtv1.VirtualMachine) []string {
+	entries, err := util.UnmarshalVolumeClaimTemplates(vm.Annotations[util.AnnotationVolumeClaimTemplates])
+	if err != nil {
+		// the invalid annotation is refused by checkVolumeClaimTemplatesAnnotation
+		return nil
+	}
+
+	imageIDs := []string{}
+	for _, entry := range entries {
+		if imageID := entry.Annotations[util.AnnotationImageID]; imageID != "" {
+			imageIDs = append(imageIDs, imageID)
+		}
+	}
+	return imageIDs
+}
+
 func (v *vmValidator) checkVMBackup(vm *kubevirtv1.VirtualMachine) error {
 	if exist, err := webhookutil.HasInProgressingVMBackupBySourceUID(v.vmBackupCache, string(vm.UID)); err != nil {
 		return werror.NewInternalError(err.Error())
@@ -565,6 +681,19 @@ func (v *vmValidator) checkVMBackup(vm *kubevirtv1.VirtualMachine) error {
 	return nil
 }
 
+func (v *vmValidator) checkVMExport(vm *kubevirtv1.VirtualMachine) error {
+	downloaders, err := v.vmDownloaderCache.List(vm.Namespace, labels.Everything())
+	if err != nil {
+		return werror.NewInternalError(err.Error())
+	}
+	for _, downloader := range downloaders {
+		if downloader.Spec.VMName == vm.Name && downloader.Status.Status != harvesterv1.ImageDownloaderStatusReady {
+			return werror.NewBadRequest(fmt.Sprintf("vm %s/%s is being exported, please wait for the export or remove it before start the vm", vm.Namespace, vm.Name))
+		}
+	}
+	return nil
+}
+
 func (v *vmValidator) checkTerminationGracePeriodSeconds(vm *kubevirtv1.VirtualMachine) error {
 	terminationGracePeriodSeconds := vm.Spec.Template.Spec.TerminationGracePeriodSeconds
 
@@ -616,6 +745,26 @@ func (v *vmValidator) checkReservedMemoryAnnotation(vm *kubevirtv1.VirtualMachin
 	return nil
 }
 
+func (v *vmValidator) checkBootOrderAnnotations(vm *kubevirtv1.VirtualMachine) error {
+	spec, err := bootorder.Parse(vm)
+	if err != nil {
+		return werror.NewInvalidError(err.Error(), "metadata.annotations")
+	}
+	if spec.Group == 0 && len(spec.After) == 0 {
+		return nil
+	}
+
+	vms, err := v.vmCache.List(vm.Namespace, labels.Everything())
+	if err != nil {
+		return werror.NewInternalError(fmt.Sprintf("failed to list VMs in namespace %s: %v", vm.Namespace, err))
+	}
+	if bootorder.HasCycle(vm, vms) {
+		return werror.NewInvalidError(fmt.Sprintf("the VM boots after itself with %s", vm.Annotations[util.AnnotationBootAfter]),
+			fmt.Sprintf("metadata.annotations[%s]", util.AnnotationBootAfter))
+	}
+	return nil
+}
+
 func (v *vmValidator) checkStorageResourceQuota(vm *kubevirtv1.VirtualMachine, oldVM *kubevirtv1.VirtualMachine) error {
 	return v.rqCalculator.CheckStorageResourceQuota(vm, oldVM)
 }
@@ -1,6 +1,7 @@
 package virtualmachine
 
 import (
+	"fmt"
 	"testing"
 
 	cniv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -12,9 +13,12 @@ import (
 	corefake "k8s.io/client-go/kubernetes/fake"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
 	"github.com/harvester/harvester/pkg/util/fakeclients"
+	"github.com/harvester/harvester/pkg/webhook/types"
 )
 
 func TestCheckMaintenanceModeStrategyIsValid(t *testing.T) {
@@ -240,7 +244,7 @@ func TestCheckMaintenanceModeStrategyIsValid(t *testing.T) {
 		},
 	}
 
-	validator := NewValidator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*vmValidator)
+	validator := NewValidator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*vmValidator)
 
 	for _, tc := range testCases {
 		err := validator.checkMaintenanceModeStrategyIsValid(tc.newVM, tc.oldVM)
@@ -888,7 +892,7 @@ func Test_virtualMachineValidator_duplicateMacAddress(t *testing.T) {
 	fakeVMCache := fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines)
 	fakeNadCache := fakeclients.NetworkAttachmentDefinitionCache(clientset.K8sCniCncfIoV1().NetworkAttachmentDefinitions)
 
-	validator := NewValidator(nil, nil, nil, nil, nil, nil, fakeVMCache, nil, fakeNadCache, nil, nil, nil).(*vmValidator)
+	validator := NewValidator(nil, nil, nil, nil, nil, nil, fakeVMCache, nil, fakeNadCache, nil, nil, nil, nil, nil).(*vmValidator)
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -1079,7 +1083,7 @@ func TestVmValidator_Update(t *testing.T) {
 	}})
 	assert.NoError(t, err)
 	fakeNSCache := fakeclients.NamespaceCache(corefakeclientset.CoreV1().Namespaces)
-	validator := NewValidator(fakeNSCache, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*vmValidator)
+	validator := NewValidator(fakeNSCache, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*vmValidator)
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1243,7 +1247,7 @@ func TestCheckCdRomVolumeIsValid(t *testing.T) {
 		},
 	}
 
-	validator := NewValidator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*vmValidator)
+	validator := NewValidator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*vmValidator)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -1670,7 +1674,7 @@ func TestCheckTargetVolumes(t *testing.T) {
 				assert.NoError(t, err, "Mock resource should add into fake controller tracker")
 			}
 			pvcCache := fakeclients.PersistentVolumeClaimCache(clientset.CoreV1().PersistentVolumeClaims)
-			validator := NewValidator(nil, nil, pvcCache, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*vmValidator)
+			validator := NewValidator(nil, nil, pvcCache, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*vmValidator)
 
 			err := validator.checkTargetVolumes(nil, tc.vm)
 			if tc.expectError {
@@ -1684,3 +1688,189 @@ func TestCheckTargetVolumes(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckDeprecatedImages(t *testing.T) {
+	newVM := func(imageIDs ...string) *kubevirtv1.VirtualMachine {
+		entries := []util.VolumeClaimTemplateEntry{}
+		for i, imageID := range imageIDs {
+			entry := util.VolumeClaimTemplateEntry{}
+			entry.Name = fmt.Sprintf("disk-%d", i)
+			entry.Annotations = map[string]string{util.AnnotationImageID: imageID}
+			entries = append(entries, entry)
+		}
+		annotation, err := util.MarshalVolumeClaimTemplates(entries)
+		assert.NoError(t, err)
+		return &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "vm",
+				Namespace:   "default",
+				Annotations: map[string]string{util.AnnotationVolumeClaimTemplates: annotation},
+			},
+		}
+	}
+	newImage := func(name string, deprecated bool) *harvesterv1.VirtualMachineImage {
+		return &harvesterv1.VirtualMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       harvesterv1.VirtualMachineImageSpec{Deprecated: deprecated},
+		}
+	}
+
+	var testCases = []struct {
+		name           string
+		lifecycle      string
+		oldVM          *kubevirtv1.VirtualMachine
+		newVM          *kubevirtv1.VirtualMachine
+		expectError    bool
+		expectWarnings []string
+	}{
+		{
+			name:  "new VM using the image",
+			newVM: newVM("default/ubuntu"),
+		},
+		{
+			name:           "new VM using the deprecated image is warned by default",
+			newVM:          newVM("default/ubuntu", "default/centos"),
+			expectWarnings: []string{"image default/centos is deprecated"},
+		},
+		{
+			name:        "new VM using the deprecated image is refused",
+			lifecycle:   `{"deprecatedImagePolicy":"refuse"}`,
+			newVM:       newVM("default/centos"),
+			expectError: true,
+		},
+		{
+			name:      "existing VM using the deprecated image",
+			lifecycle: `{"deprecatedImagePolicy":"refuse"}`,
+			oldVM:     newVM("default/centos"),
+			newVM:     newVM("default/centos"),
+		},
+		{
+			name:        "existing VM adding the deprecated image",
+			lifecycle:   `{"deprecatedImagePolicy":"refuse"}`,
+			oldVM:       newVM("default/ubuntu"),
+			newVM:       newVM("default/ubuntu", "default/centos"),
+			expectError: true,
+		},
+		{
+			name:  "new VM using the nonexistent image",
+			newVM: newVM("default/rocky"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(newImage("ubuntu", false), newImage("centos", true))
+			if tc.lifecycle != "" {
+				err := clientset.Tracker().Add(&harvesterv1.Setting{
+					ObjectMeta: metav1.ObjectMeta{Name: settings.VMImageLifecycleSettingName},
+					Value:      tc.lifecycle,
+				})
+				assert.NoError(t, err)
+			}
+			imageCache := fakeclients.VirtualMachineImageCache(clientset.HarvesterhciV1beta1().VirtualMachineImages)
+			settingCache := fakeclients.HarvesterSettingCache(clientset.HarvesterhciV1beta1().Settings)
+			validator := NewValidator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, settingCache, imageCache, nil).(*vmValidator)
+
+			request := &types.Request{}
+			err := validator.checkDeprecatedImages(request, tc.oldVM, tc.newVM)
+			if tc.expectError {
+				assert.NotNil(t, err, tc.name)
+			} else {
+				assert.Nil(t, err, tc.name)
+				assert.Equal(t, tc.expectWarnings, request.Warnings(), tc.name)
+			}
+		})
+	}
+}
+
+func TestCheckVMExport(t *testing.T) {
+	newVM := func(runStrategy kubevirtv1.VirtualMachineRunStrategy, requests ...kubevirtv1.StateChangeRequestAction) *kubevirtv1.VirtualMachine {
+		vm := &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "vm", Namespace: "default"},
+			Spec:       kubevirtv1.VirtualMachineSpec{RunStrategy: &runStrategy},
+		}
+		for _, action := range requests {
+			vm.Status.StateChangeRequests = append(vm.Status.StateChangeRequests, kubevirtv1.VirtualMachineStateChangeRequest{Action: action})
+		}
+		return vm
+	}
+	newDownloader := func(vmName string, status harvesterv1.DownloderStatus) *harvesterv1.VirtualMachineDownloader {
+		return &harvesterv1.VirtualMachineDownloader{
+			ObjectMeta: metav1.ObjectMeta{Name: "export-" + vmName, Namespace: "default"},
+			Spec:       harvesterv1.VirtualMachineDownloaderSpec{VMName: vmName},
+			Status:     harvesterv1.VirtualMachineDownloaderStatus{Status: status},
+		}
+	}
+
+	var testCases = []struct {
+		name          string
+		oldVM         *kubevirtv1.VirtualMachine
+		newVM         *kubevirtv1.VirtualMachine
+		downloader    *harvesterv1.VirtualMachineDownloader
+		expectStarted bool
+		expectError   bool
+	}{
+		{
+			name:          "start the halted VM being exported",
+			oldVM:         newVM(kubevirtv1.RunStrategyHalted),
+			newVM:         newVM(kubevirtv1.RunStrategyAlways),
+			downloader:    newDownloader("vm", harvesterv1.ImageDownloaderStatusProgressing),
+			expectStarted: true,
+			expectError:   true,
+		},
+		{
+			name:          "start the manual VM being exported",
+			oldVM:         newVM(kubevirtv1.RunStrategyManual),
+			newVM:         newVM(kubevirtv1.RunStrategyManual, kubevirtv1.StartRequest),
+			downloader:    newDownloader("vm", harvesterv1.ImageDownloaderStatusProgressing),
+			expectStarted: true,
+			expectError:   true,
+		},
+		{
+			name:          "start the VM after the export is ready",
+			oldVM:         newVM(kubevirtv1.RunStrategyHalted),
+			newVM:         newVM(kubevirtv1.RunStrategyAlways),
+			downloader:    newDownloader("vm", harvesterv1.ImageDownloaderStatusReady),
+			expectStarted: true,
+		},
+		{
+			name:          "start the VM while another VM is exported",
+			oldVM:         newVM(kubevirtv1.RunStrategyHalted),
+			newVM:         newVM(kubevirtv1.RunStrategyAlways),
+			downloader:    newDownloader("other", harvesterv1.ImageDownloaderStatusProgressing),
+			expectStarted: true,
+		},
+		{
+			name:       "stop the VM being exported",
+			oldVM:      newVM(kubevirtv1.RunStrategyAlways),
+			newVM:      newVM(kubevirtv1.RunStrategyHalted),
+			downloader: newDownloader("vm", harvesterv1.ImageDownloaderStatusProgressing),
+		},
+		{
+			name:       "start request is already sent",
+			oldVM:      newVM(kubevirtv1.RunStrategyManual, kubevirtv1.StartRequest),
+			newVM:      newVM(kubevirtv1.RunStrategyManual, kubevirtv1.StartRequest),
+			downloader: newDownloader("vm", harvesterv1.ImageDownloaderStatusProgressing),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(tc.downloader)
+			downloaderCache := fakeclients.VirtualMachineDownloaderCache(clientset.HarvesterhciV1beta1().VirtualMachineDownloaders)
+			validator := NewValidator(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, downloaderCache).(*vmValidator)
+
+			started := validator.checkVMStartingStatus(tc.oldVM, tc.newVM)
+			assert.Equal(t, tc.expectStarted, started, tc.name)
+			if !started {
+				return
+			}
+			err := validator.checkVMExport(tc.newVM)
+			if tc.expectError {
+				assert.NotNil(t, err, tc.name)
+			} else {
+				assert.Nil(t, err, tc.name)
+			}
+		})
+	}
+}
@@ -24,8 +24,9 @@ import (
 )
 
 const (
-	fieldSourceName = "spec.source.name"
-	fieldTypeName   = "spec.type"
+	fieldSourceName       = "spec.source.name"
+	fieldTypeName         = "spec.type"
+	fieldBackupTargetName = "spec.backupTargetName"
 )
 
 func NewValidator(
@@ -37,6 +38,8 @@ func NewValidator(
 	scCache ctlstoragev1.StorageClassCache,
 	resourceQuotaCache ctlharvesterv1.ResourceQuotaCache,
 	vmimCache ctlkubevirtv1.VirtualMachineInstanceMigrationCache,
+	backupTargetCache ctlharvesterv1.BackupTargetCache,
+	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache,
 ) types.Validator {
 	return &virtualMachineBackupValidator{
 		vms:                vms,
@@ -47,6 +50,8 @@ func NewValidator(
 		scCache:            scCache,
 		resourceQuotaCache: resourceQuotaCache,
 		vmimCache:          vmimCache,
+		backupTargetCache:  backupTargetCache,
+		vmBackupCache:      vmBackupCache,
 	}
 }
 
@@ -61,6 +66,8 @@ type virtualMachineBackupValidator struct {
 	scCache            ctlstoragev1.StorageClassCache
 	resourceQuotaCache ctlharvesterv1.ResourceQuotaCache
 	vmimCache          ctlkubevirtv1.VirtualMachineInstanceMigrationCache
+	backupTargetCache  ctlharvesterv1.BackupTargetCache
+	vmBackupCache      ctlharvesterv1.VirtualMachineBackupCache
 }
 
 func (v *virtualMachineBackupValidator) Resource() types.Resource {
@@ -88,6 +95,8 @@ func (v *virtualMachineBackupValidator) Create(_ *types.Request, newObj runtime.
 	validateFunc := v.validateStandardBackup
 	if !backup.IsBackupMissingStatus(newVMBackup) {
 		validateFunc = v.validateVMBackupRecover
+	} else if backup.IsPromotedBackup(newVMBackup) {
+		validateFunc = v.validatePromotedBackup
 	}
 
 	// Execute the selected validation.
@@ -96,11 +105,14 @@ func (v *virtualMachineBackupValidator) Create(_ *types.Request, newObj runtime.
 	}
 
 	if newVMBackup.Spec.Type == v1beta1.Snapshot {
+		if newVMBackup.Spec.BackupTargetName != "" {
+			return werror.NewInvalidError("backup target can only be set for backup type", fieldBackupTargetName)
+		}
 		return nil
 	}
 
 	// Additional check for backup type.
-	if err := v.checkBackupTarget(); err != nil {
+	if err := v.checkBackupTarget(newVMBackup.Spec.BackupTargetName); err != nil {
 		return werror.NewInvalidError(err.Error(), fieldTypeName)
 	}
 
@@ -133,6 +145,35 @@ func (v *virtualMachineBackupValidator) validateVMBackupRecover(vmb *v1beta1.Vir
 	return webhookutil.IsLHBackupRelated(vmb)
 }
 
+// validatePromotedBackup checks the snapshot a backup is promoted from, the source VM isn't
+// required because the backup is created from the volume snapshots of the snapshot.
+func (v *virtualMachineBackupValidator) validatePromotedBackup(vmb *v1beta1.VirtualMachineBackup) error {
+	if vmb.Spec.Type != v1beta1.Backup {
+		return werror.NewInvalidError("only backup type can be promoted from a snapshot", fieldTypeName)
+	}
+
+	snapshotName := vmb.Annotations[util.AnnotationPromotedFrom]
+	snapshot, err := v.vmBackupCache.Get(vmb.Namespace, snapshotName)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("can't get snapshot %s/%s, err: %v", vmb.Namespace, snapshotName, err), fieldSourceName)
+	}
+	if snapshot.Spec.Type != v1beta1.Snapshot {
+		return werror.NewInvalidError(fmt.Sprintf("vmbackup %s/%s is not a snapshot", vmb.Namespace, snapshotName), fieldSourceName)
+	}
+	if !backup.IsBackupReady(snapshot) {
+		return werror.NewInvalidError(fmt.Sprintf("snapshot %s/%s is not ready", vmb.Namespace, snapshotName), fieldSourceName)
+	}
+	if snapshot.Spec.Source.Name != vmb.Spec.Source.Name {
+		return werror.NewInvalidError(fmt.Sprintf("snapshot %s/%s is not taken from VM %s", vmb.Namespace, snapshotName, vmb.Spec.Source.Name), fieldSourceName)
+	}
+	for _, vb := range snapshot.Status.VolumeBackups {
+		if vb.CSIDriverName != util.CSIProvisionerLonghorn {
+			return werror.NewInvalidError(fmt.Sprintf("volume %s of snapshot %s/%s is not a Longhorn volume", vb.VolumeName, vmb.Namespace, snapshotName), fieldSourceName)
+		}
+	}
+	return nil
+}
+
 // checkBackupVolumeSnapshotClass checks if the volumeSnapshotClassName is configured for the provisioner used by the PVCs in the VirtualMachine.
 func (v *virtualMachineBackupValidator) checkBackupVolumeSnapshotClass(vm *kubevirtv1.VirtualMachine, newVMBackup *v1beta1.VirtualMachineBackup) error {
 	// Load the CSI driver configuration.
@@ -165,7 +206,21 @@ func (v *virtualMachineBackupValidator) checkBackupVolumeSnapshotClass(vm *kubev
 	return nil
 }
 
-func (v *virtualMachineBackupValidator) checkBackupTarget() error {
+func (v *virtualMachineBackupValidator) checkBackupTarget(backupTargetName string) error {
+	if backupTargetName != "" {
+		bt, err := v.backupTargetCache.Get(backupTargetName)
+		if err != nil {
+			return fmt.Errorf("can't get backup target %s, err: %w", backupTargetName, err)
+		}
+		if bt.DeletionTimestamp != nil {
+			return fmt.Errorf("backup target %s is being deleted", backupTargetName)
+		}
+		if !v1beta1.BackupTargetConditionAvailable.IsTrue(bt) {
+			return fmt.Errorf("backup target %s is not available", backupTargetName)
+		}
+		return nil
+	}
+
 	backupTargetSetting, err := v.setting.Get(settings.BackupTargetSettingName)
 	if err != nil {
 		return fmt.Errorf("can't get backup target setting, err: %w", err)
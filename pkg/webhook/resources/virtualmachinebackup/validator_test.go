@@ -0,0 +1,120 @@
+package virtualmachinebackup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func Test_virtualMachineBackupValidator_validatePromotedBackup(t *testing.T) {
+	newSnapshot := func() *v1beta1.VirtualMachineBackup {
+		return &v1beta1.VirtualMachineBackup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "snap"},
+			Spec: v1beta1.VirtualMachineBackupSpec{
+				Source: corev1.TypedLocalObjectReference{Kind: "VirtualMachine", Name: "vm"},
+				Type:   v1beta1.Snapshot,
+			},
+			Status: v1beta1.VirtualMachineBackupStatus{
+				ReadyToUse: ptr.To(true),
+				VolumeBackups: []v1beta1.VolumeBackup{
+					{VolumeName: "disk-0", CSIDriverName: util.CSIProvisionerLonghorn},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		snapshot    func(*v1beta1.VirtualMachineBackup)
+		noSnapshot  bool
+		backupType  v1beta1.BackupType
+		sourceName  string
+		expectError bool
+	}{
+		{
+			name: "promoted from a ready snapshot",
+		},
+		{
+			name:        "promoted to a snapshot",
+			backupType:  v1beta1.Snapshot,
+			expectError: true,
+		},
+		{
+			name:        "snapshot doesn't exist",
+			noSnapshot:  true,
+			expectError: true,
+		},
+		{
+			name:        "promoted from a backup",
+			snapshot:    func(snapshot *v1beta1.VirtualMachineBackup) { snapshot.Spec.Type = v1beta1.Backup },
+			expectError: true,
+		},
+		{
+			name:        "snapshot isn't ready",
+			snapshot:    func(snapshot *v1beta1.VirtualMachineBackup) { snapshot.Status.ReadyToUse = ptr.To(false) },
+			expectError: true,
+		},
+		{
+			name:        "snapshot of another VM",
+			sourceName:  "other-vm",
+			expectError: true,
+		},
+		{
+			name: "snapshot of a volume out of Longhorn",
+			snapshot: func(snapshot *v1beta1.VirtualMachineBackup) {
+				snapshot.Status.VolumeBackups = append(snapshot.Status.VolumeBackups,
+					v1beta1.VolumeBackup{VolumeName: "disk-1", CSIDriverName: "lvm.driver.harvesterhci.io"})
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			if !tc.noSnapshot {
+				snapshot := newSnapshot()
+				if tc.snapshot != nil {
+					tc.snapshot(snapshot)
+				}
+				assert.NoError(t, clientset.Tracker().Add(snapshot))
+			}
+			v := &virtualMachineBackupValidator{
+				vmBackupCache: fakeclients.VMBackupCache(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+			}
+
+			vmBackup := &v1beta1.VirtualMachineBackup{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "default",
+					Name:        "backup",
+					Annotations: map[string]string{util.AnnotationPromotedFrom: "snap"},
+				},
+				Spec: v1beta1.VirtualMachineBackupSpec{
+					Source: corev1.TypedLocalObjectReference{Kind: "VirtualMachine", Name: "vm"},
+					Type:   v1beta1.Backup,
+				},
+			}
+			if tc.backupType != "" {
+				vmBackup.Spec.Type = tc.backupType
+			}
+			if tc.sourceName != "" {
+				vmBackup.Spec.Source.Name = tc.sourceName
+			}
+
+			err := v.validatePromotedBackup(vmBackup)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package virtualmachinebackupverification
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	validationutil "k8s.io/apimachinery/pkg/util/validation"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+const (
+	fieldSpec                     = "spec"
+	fieldVirtualMachineBackupName = "spec.virtualMachineBackupName"
+	fieldScratchNamespace         = "spec.scratchNamespace"
+)
+
+// scratchResourceAttributes are the permissions the user needs in a custom scratch namespace,
+// the controller creates and deletes these resources there on behalf of the user.
+var scratchResourceAttributes = []authorizationv1.ResourceAttributes{
+	{Verb: "create", Group: kubevirtv1.SchemeGroupVersion.Group, Resource: "virtualmachines"},
+	{Verb: "delete", Group: kubevirtv1.SchemeGroupVersion.Group, Resource: "virtualmachines"},
+	{Verb: "create", Group: v1beta1.SchemeGroupVersion.Group, Resource: v1beta1.VirtualMachineRestoreResourceName},
+	{Verb: "delete", Group: v1beta1.SchemeGroupVersion.Group, Resource: v1beta1.VirtualMachineRestoreResourceName},
+	{Verb: "delete", Group: "", Resource: "persistentvolumeclaims"},
+}
+
+func NewValidator(
+	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache,
+	namespaceCache ctlcorev1.NamespaceCache,
+	sar authorizationv1client.SubjectAccessReviewInterface,
+) types.Validator {
+	return &verificationValidator{
+		vmBackupCache:  vmBackupCache,
+		namespaceCache: namespaceCache,
+		sar:            sar,
+	}
+}
+
+type verificationValidator struct {
+	types.DefaultValidator
+
+	vmBackupCache  ctlharvesterv1.VirtualMachineBackupCache
+	namespaceCache ctlcorev1.NamespaceCache
+	sar            authorizationv1client.SubjectAccessReviewInterface
+}
+
+func (v *verificationValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{v1beta1.VirtualMachineBackupVerificationResourceName},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   v1beta1.SchemeGroupVersion.Group,
+		APIVersion: v1beta1.SchemeGroupVersion.Version,
+		ObjectType: &v1beta1.VirtualMachineBackupVerification{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (v *verificationValidator) Create(request *types.Request, newObj runtime.Object) error {
+	verification := newObj.(*v1beta1.VirtualMachineBackupVerification)
+
+	if err := CheckScratchNamespace(v.namespaceCache, v.sar, request, verification.Namespace,
+		verification.Spec.ScratchNamespace, fieldScratchNamespace); err != nil {
+		return err
+	}
+
+	vmBackup, err := v.vmBackupCache.Get(verification.Namespace, verification.Spec.VirtualMachineBackupName)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("can't get vmbackup %s/%s, err: %v", verification.Namespace, verification.Spec.VirtualMachineBackupName, err), fieldVirtualMachineBackupName)
+	}
+
+	if vmBackup.Spec.Type == v1beta1.Snapshot {
+		return werror.NewInvalidError(fmt.Sprintf("vmbackup %s/%s is a snapshot, only backups can be verified", vmBackup.Namespace, vmBackup.Name), fieldVirtualMachineBackupName)
+	}
+
+	if !ctlbackup.IsBackupReady(vmBackup) {
+		return werror.NewInvalidError(fmt.Sprintf("vmbackup %s/%s is not ready", vmBackup.Namespace, vmBackup.Name), fieldVirtualMachineBackupName)
+	}
+
+	return nil
+}
+
+func (v *verificationValidator) Update(_ *types.Request, oldObj, newObj runtime.Object) error {
+	oldVerification := oldObj.(*v1beta1.VirtualMachineBackupVerification)
+	newVerification := newObj.(*v1beta1.VirtualMachineBackupVerification)
+
+	if newVerification.DeletionTimestamp != nil {
+		return nil
+	}
+
+	if !reflect.DeepEqual(oldVerification.Spec, newVerification.Spec) {
+		return werror.NewInvalidError("VirtualMachineBackupVerification spec is immutable", fieldSpec)
+	}
+	return nil
+}
+
+// CheckScratchNamespace checks the scratch namespace of a verification created in the namespace. The default
+// scratch namespace is managed by the controller, a custom one must exist and the user must be allowed to create
+// and delete the verification resources in it.
+func CheckScratchNamespace(
+	namespaceCache ctlcorev1.NamespaceCache,
+	sar authorizationv1client.SubjectAccessReviewInterface,
+	request *types.Request,
+	namespace, scratchNamespace, field string,
+) error {
+	if scratchNamespace == "" || scratchNamespace == ctlbackup.DefaultBackupVerificationNamespace {
+		return nil
+	}
+
+	if errs := validationutil.IsDNS1123Label(scratchNamespace); len(errs) != 0 {
+		return werror.NewInvalidError(fmt.Sprintf("scratch namespace is invalid, err: %v", errs), field)
+	}
+	// the verification VM must not run along with the workloads
+	if scratchNamespace == namespace {
+		return werror.NewInvalidError("scratch namespace can't be the namespace of the vmbackup", field)
+	}
+	if _, err := namespaceCache.Get(scratchNamespace); err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("can't get scratch namespace %s, err: %v", scratchNamespace, err), field)
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(request.UserInfo.Extra))
+	for k, v := range request.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	for _, attributes := range scratchResourceAttributes {
+		attributes.Namespace = scratchNamespace
+		review, err := sar.Create(context.TODO(), &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				ResourceAttributes: &attributes,
+				User:               request.UserInfo.Username,
+				Groups:             request.UserInfo.Groups,
+				UID:                request.UserInfo.UID,
+				Extra:              extra,
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return werror.NewInternalError(fmt.Sprintf("failed to check the permission of namespace %s: %v", scratchNamespace, err))
+		}
+		if !review.Status.Allowed {
+			return werror.NewInvalidError(fmt.Sprintf("user %s is not allowed to %s %s in scratch namespace %s",
+				request.UserInfo.Username, attributes.Verb, attributes.Resource, scratchNamespace), field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package virtualmachinebackupverification
+
+import (
+	"testing"
+
+	"github.com/rancher/wrangler/v3/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+func Test_verificationValidator_Create(t *testing.T) {
+	newVerification := func(backupName, scratchNamespace string) *v1beta1.VirtualMachineBackupVerification {
+		return &v1beta1.VirtualMachineBackupVerification{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "verification"},
+			Spec: v1beta1.VirtualMachineBackupVerificationSpec{
+				VirtualMachineBackupName: backupName,
+				VerificationOptions: v1beta1.VerificationOptions{
+					ScratchNamespace: scratchNamespace,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		verification *v1beta1.VirtualMachineBackupVerification
+		expectError  bool
+	}{
+		{
+			name:         "ready backup",
+			verification: newVerification("ready", "scratch"),
+			expectError:  false,
+		},
+		{
+			name:         "nonexistent backup",
+			verification: newVerification("nonexistent", "scratch"),
+			expectError:  true,
+		},
+		{
+			name:         "backup not ready",
+			verification: newVerification("not-ready", "scratch"),
+			expectError:  true,
+		},
+		{
+			name:         "snapshot",
+			verification: newVerification("snapshot", "scratch"),
+			expectError:  true,
+		},
+		{
+			name:         "scratch namespace is the backup namespace",
+			verification: newVerification("ready", "default"),
+			expectError:  true,
+		},
+		{
+			name:         "default scratch namespace",
+			verification: newVerification("ready", ctlbackup.DefaultBackupVerificationNamespace),
+			expectError:  false,
+		},
+		{
+			name:         "nonexistent scratch namespace",
+			verification: newVerification("ready", "nonexistent"),
+			expectError:  true,
+		},
+		{
+			name:         "scratch namespace without the permission",
+			verification: newVerification("ready", "kube-system"),
+			expectError:  true,
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&v1beta1.VirtualMachineBackup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ready"},
+			Spec:       v1beta1.VirtualMachineBackupSpec{Type: v1beta1.Backup},
+			Status:     v1beta1.VirtualMachineBackupStatus{ReadyToUse: ptr.To(true)},
+		},
+		&v1beta1.VirtualMachineBackup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "not-ready"},
+			Spec:       v1beta1.VirtualMachineBackupSpec{Type: v1beta1.Backup},
+			Status:     v1beta1.VirtualMachineBackupStatus{ReadyToUse: ptr.To(false)},
+		},
+		&v1beta1.VirtualMachineBackup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "snapshot"},
+			Spec:       v1beta1.VirtualMachineBackupSpec{Type: v1beta1.Snapshot},
+			Status:     v1beta1.VirtualMachineBackupStatus{ReadyToUse: ptr.To(true)},
+		},
+	)
+	// alice can only use the scratch namespace
+	coreclientset := corefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "scratch"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	)
+	coreclientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		assert.Equal(t, "alice", review.Spec.User)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Namespace == "scratch"
+		return true, review, nil
+	})
+	request := &types.Request{
+		Request: &webhook.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: "alice"},
+			},
+		},
+	}
+	validator := NewValidator(
+		fakeclients.VMBackupCache(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+		fakeclients.NamespaceCache(coreclientset.CoreV1().Namespaces),
+		coreclientset.AuthorizationV1().SubjectAccessReviews(),
+	)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validator.Create(request, tc.verification)
+			if tc.expectError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package virtualmachinebulkaction
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	validationutil "k8s.io/apimachinery/pkg/util/validation"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/types"
+	webhookutil "github.com/harvester/harvester/pkg/webhook/util"
+)
+
+const (
+	fieldSpec     = "spec"
+	fieldAction   = "spec.action"
+	fieldInput    = "spec.input"
+	fieldSelector = "spec.selector"
+
+	backupAction = "backup"
+)
+
+func vmSubresource(subresource string) authorizationv1.ResourceAttributes {
+	return authorizationv1.ResourceAttributes{
+		Verb:        "update",
+		Group:       kubevirtv1.SubresourceGroupName,
+		Version:     kubevirtv1.SchemeGroupVersion.Version,
+		Resource:    "virtualmachines",
+		Subresource: subresource,
+	}
+}
+
+func vmiSubresource(subresource string) authorizationv1.ResourceAttributes {
+	return authorizationv1.ResourceAttributes{
+		Verb:        "update",
+		Group:       kubevirtv1.SubresourceGroupName,
+		Version:     kubevirtv1.SchemeGroupVersion.Version,
+		Resource:    "virtualmachineinstances",
+		Subresource: subresource,
+	}
+}
+
+func kubevirtResource(verb, resource string) authorizationv1.ResourceAttributes {
+	return authorizationv1.ResourceAttributes{
+		Verb:     verb,
+		Group:    kubevirtv1.SchemeGroupVersion.Group,
+		Version:  kubevirtv1.SchemeGroupVersion.Version,
+		Resource: resource,
+	}
+}
+
+// actionResourceAttributes are what the bulk action controller does to a VM for each action, the user
+// creating a bulk action must be allowed to do it since the controller runs the action on behalf of the user.
+var actionResourceAttributes = map[string]authorizationv1.ResourceAttributes{
+	"start":                  vmSubresource("start"),
+	"stop":                   kubevirtResource("update", "virtualmachines"),
+	"restart":                vmSubresource("restart"),
+	"forceStop":              vmSubresource("stop"),
+	"softreboot":             vmiSubresource("softreboot"),
+	"pause":                  vmiSubresource("pause"),
+	"unpause":                vmiSubresource("unpause"),
+	"migrate":                kubevirtResource("create", "virtualmachineinstancemigrations"),
+	"abortMigration":         kubevirtResource("delete", "virtualmachineinstancemigrations"),
+	"cancelStorageMigration": kubevirtResource("update", "virtualmachines"),
+	backupAction: {
+		Verb:     "create",
+		Group:    v1beta1.SchemeGroupVersion.Group,
+		Version:  v1beta1.SchemeGroupVersion.Version,
+		Resource: v1beta1.VirtualMachineBackupResourceName,
+	},
+}
+
+func NewValidator(sar authorizationv1client.SubjectAccessReviewInterface) types.Validator {
+	return &bulkActionValidator{
+		sar: sar,
+	}
+}
+
+type bulkActionValidator struct {
+	types.DefaultValidator
+	sar authorizationv1client.SubjectAccessReviewInterface
+}
+
+func (v *bulkActionValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{v1beta1.VirtualMachineBulkActionResourceName},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   v1beta1.SchemeGroupVersion.Group,
+		APIVersion: v1beta1.SchemeGroupVersion.Version,
+		ObjectType: &v1beta1.VirtualMachineBulkAction{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (v *bulkActionValidator) Create(request *types.Request, newObj runtime.Object) error {
+	bulk := newObj.(*v1beta1.VirtualMachineBulkAction)
+	if err := validateSpec(bulk); err != nil {
+		return err
+	}
+	return v.checkActionPermission(request, bulk)
+}
+
+// Update keeps the spec as it is, the action runs with the permission checked on creation
+func (v *bulkActionValidator) Update(_ *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
+	oldBulk := oldObj.(*v1beta1.VirtualMachineBulkAction)
+	newBulk := newObj.(*v1beta1.VirtualMachineBulkAction)
+	if newBulk.DeletionTimestamp != nil {
+		return nil
+	}
+	if !reflect.DeepEqual(oldBulk.Spec, newBulk.Spec) {
+		return werror.NewInvalidError("VirtualMachineBulkAction spec is immutable", fieldSpec)
+	}
+	return nil
+}
+
+// checkActionPermission checks the user can run the action on the VMs of the namespace
+func (v *bulkActionValidator) checkActionPermission(request *types.Request, bulk *v1beta1.VirtualMachineBulkAction) error {
+	attributes := actionResourceAttributes[bulk.Spec.Action]
+	attributes.Namespace = bulk.Namespace
+	allowed, err := webhookutil.CanAccess(v.sar, request.UserInfo, attributes)
+	if err != nil {
+		return werror.NewInternalError(fmt.Sprintf("failed to check the permission of namespace %s: %v", bulk.Namespace, err))
+	}
+	if !allowed {
+		resource := attributes.Resource
+		if attributes.Subresource != "" {
+			resource += "/" + attributes.Subresource
+		}
+		return werror.NewInvalidError(fmt.Sprintf("user %s is not allowed to %s %s in namespace %s",
+			request.UserInfo.Username, attributes.Verb, resource, bulk.Namespace), fieldAction)
+	}
+	return nil
+}
+
+func validateSpec(bulk *v1beta1.VirtualMachineBulkAction) error {
+	if _, ok := actionResourceAttributes[bulk.Spec.Action]; !ok {
+		return werror.NewInvalidError(fmt.Sprintf("action %q can't run on many VMs", bulk.Spec.Action), fieldAction)
+	}
+
+	if bulk.Spec.Selector == "" && len(bulk.Spec.Names) == 0 {
+		return werror.NewInvalidError("one of selector and names is required", fieldSelector)
+	}
+	if bulk.Spec.Selector != "" {
+		if _, err := labels.Parse(bulk.Spec.Selector); err != nil {
+			return werror.NewInvalidError(fmt.Sprintf("invalid selector: %v", err), fieldSelector)
+		}
+	}
+
+	if bulk.Spec.Action == backupAction {
+		return validateBackupInput(bulk.Spec.Input)
+	}
+	return nil
+}
+
+// validateBackupInput checks the name of the backups, every VM gets a backup named after it and the VM
+func validateBackupInput(input string) error {
+	var backup struct {
+		Name string `json:"name"`
+	}
+	if input != "" {
+		if err := json.Unmarshal([]byte(input), &backup); err != nil {
+			return werror.NewInvalidError(fmt.Sprintf("invalid backup input: %v", err), fieldInput)
+		}
+	}
+	if backup.Name == "" {
+		return werror.NewInvalidError("backup name is required", fieldInput)
+	}
+	if errs := validationutil.IsDNS1123Label(backup.Name); len(errs) != 0 {
+		return werror.NewInvalidError(fmt.Sprintf("invalid backup name %q: %v", backup.Name, errs), fieldInput)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package virtualmachinebulkaction
+
+import (
+	"testing"
+
+	"github.com/rancher/wrangler/v3/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+func newBulkAction(action, input, selector string, names ...string) *v1beta1.VirtualMachineBulkAction {
+	return &v1beta1.VirtualMachineBulkAction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bulk"},
+		Spec: v1beta1.VirtualMachineBulkActionSpec{
+			Action:   action,
+			Input:    input,
+			Selector: selector,
+			Names:    names,
+		},
+	}
+}
+
+func Test_validateSpec(t *testing.T) {
+	tests := []struct {
+		name        string
+		bulk        *v1beta1.VirtualMachineBulkAction
+		expectError bool
+	}{
+		{
+			name: "stop by selector",
+			bulk: newBulkAction("stop", "", "app=web"),
+		},
+		{
+			name: "backup by names",
+			bulk: newBulkAction("backup", `{"name":"nightly"}`, "", "vm1", "vm2"),
+		},
+		{
+			name:        "unknown action",
+			bulk:        newBulkAction("addVolume", "", "app=web"),
+			expectError: true,
+		},
+		{
+			name:        "no VMs selected",
+			bulk:        newBulkAction("stop", "", ""),
+			expectError: true,
+		},
+		{
+			name:        "invalid selector",
+			bulk:        newBulkAction("stop", "", "app in web"),
+			expectError: true,
+		},
+		{
+			name:        "backup without a name",
+			bulk:        newBulkAction("backup", "", "app=web"),
+			expectError: true,
+		},
+		{
+			name:        "backup with an invalid name",
+			bulk:        newBulkAction("backup", `{"name":"Nightly_Backup"}`, "app=web"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSpec(tt.bulk)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBulkActionValidator_Create(t *testing.T) {
+	// alice can stop but not migrate the VMs of the default namespace
+	allowed := map[string]bool{
+		"update/virtualmachines":                  true,
+		"create/virtualmachineinstancemigrations": false,
+		"update/virtualmachines/start":            true,
+	}
+
+	tests := []struct {
+		name        string
+		bulk        *v1beta1.VirtualMachineBulkAction
+		expectError bool
+	}{
+		{
+			name: "stop with the permission",
+			bulk: newBulkAction("stop", "", "app=web"),
+		},
+		{
+			name: "start with the permission of the subresource",
+			bulk: newBulkAction("start", "", "app=web"),
+		},
+		{
+			name:        "migrate without the permission",
+			bulk:        newBulkAction("migrate", `{"nodeName":"node1"}`, "app=web"),
+			expectError: true,
+		},
+		{
+			name:        "restart without the permission of the subresource",
+			bulk:        newBulkAction("restart", "", "app=web"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := corefake.NewSimpleClientset()
+			clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				assert.Equal(t, "alice", review.Spec.User)
+				assert.Equal(t, "alice-uid", review.Spec.UID)
+				attributes := review.Spec.ResourceAttributes
+				assert.Equal(t, "default", attributes.Namespace)
+				key := attributes.Verb + "/" + attributes.Resource
+				if attributes.Subresource != "" {
+					key += "/" + attributes.Subresource
+				}
+				review.Status.Allowed = allowed[key]
+				return true, review, nil
+			})
+			request := &types.Request{
+				Request: &webhook.Request{
+					AdmissionRequest: admissionv1.AdmissionRequest{
+						UserInfo: authenticationv1.UserInfo{Username: "alice", UID: "alice-uid"},
+					},
+				},
+			}
+
+			validator := NewValidator(clientset.AuthorizationV1().SubjectAccessReviews())
+			err := validator.Create(request, tt.bulk)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBulkActionValidator_Update(t *testing.T) {
+	oldBulk := newBulkAction("stop", "", "app=web")
+
+	// the controller records the progress of the bulk action
+	running := oldBulk.DeepCopy()
+	running.Status.Phase = v1beta1.BulkActionPhaseRunning
+	assert.NoError(t, NewValidator(nil).Update(nil, oldBulk, running))
+
+	// the action is checked on creation only
+	changed := oldBulk.DeepCopy()
+	changed.Spec.Action = "migrate"
+	assert.Error(t, NewValidator(nil).Update(nil, oldBulk, changed))
+}
@@ -22,11 +22,12 @@ func NewValidator(
 	podCache ctlcorev1.PodCache,
 	pvcCache ctlcorev1.PersistentVolumeClaimCache,
 	ssar authorizationv1client.SelfSubjectAccessReviewInterface,
+	sar authorizationv1client.SubjectAccessReviewInterface,
 	vmTemplateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache,
 	scCache ctlstoragev1.StorageClassCache,
 	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache) types.Validator {
 
-	vmiv := common.GetVMIValidator(vmiCache, scCache, ssar, podCache, pvcCache, vmTemplateVersionCache, vmBackupCache)
+	vmiv := common.GetVMIValidator(vmiCache, scCache, ssar, sar, podCache, pvcCache, vmTemplateVersionCache, vmBackupCache)
 	validators := map[v1beta1.VMIBackend]backend.Validator{
 		v1beta1.VMIBackendBackingImage: backingimage.GetValidator(vmiv),
 		v1beta1.VMIBackendCDI:          cdi.GetValidator(vmiv),
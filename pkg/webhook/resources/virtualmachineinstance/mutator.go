@@ -9,11 +9,14 @@ import (
 	"github.com/sirupsen/logrus"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+	"kubevirt.io/kubevirt/pkg/apimachinery/patch"
 
 	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
 	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/bootorder"
 	"github.com/harvester/harvester/pkg/webhook/types"
 )
 
@@ -69,6 +72,50 @@ func (m *vmiMutator) Create(_ *types.Request, newObj runtime.Object) (types.Patc
 		patchOps = append(patchOps, devicesPatch...)
 	}
 
+	bootHoldPatch, err := m.patchBootHold(vm, vmi)
+	if err != nil {
+		return nil, fmt.Errorf("error patching boot hold for vmi %s/%s: %w", vmi.Namespace, vmi.Name, err)
+	}
+	patchOps = append(patchOps, bootHoldPatch...)
+
+	return patchOps, nil
+}
+
+// patchBootHold starts the VMI paused if the VM boots after other VMs, the boot order controller
+// unpauses it once the VMs it boots after are up.
+func (m *vmiMutator) patchBootHold(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1.VirtualMachineInstance) (types.PatchOps, error) {
+	if vmi.Spec.StartStrategy != nil {
+		return nil, nil
+	}
+
+	spec, err := bootorder.Parse(vm)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":      vm.Name,
+			"namespace": vm.Namespace,
+		}).Warn("ignore the invalid boot order of vm")
+		return nil, nil
+	}
+	if spec.Group == 0 && len(spec.After) == 0 {
+		return nil, nil
+	}
+
+	vms, err := m.vm.List(vm.Namespace, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	if len(bootorder.Dependencies(vm, spec, vms)) == 0 {
+		return nil, nil
+	}
+
+	patchOps := types.PatchOps{
+		fmt.Sprintf(`{"op": "add", "path": "/spec/startStrategy", "value": "%s"}`, kubevirtv1.StartStrategyPaused),
+	}
+	if vmi.Labels == nil {
+		patchOps = append(patchOps, fmt.Sprintf(`{"op": "add", "path": "/metadata/labels", "value": {"%s": "true"}}`, util.LabelBootHeld))
+	} else {
+		patchOps = append(patchOps, fmt.Sprintf(`{"op": "add", "path": "/metadata/labels/%s", "value": "true"}`, patch.EscapeJSONPointer(util.LabelBootHeld)))
+	}
 	return patchOps, nil
 }
 
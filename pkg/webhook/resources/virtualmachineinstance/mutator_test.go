@@ -255,6 +255,64 @@ func TestCreateWithoutVM(t *testing.T) {
 	assert.Nil(t, patchOps)
 }
 
+func Test_patchBootHold(t *testing.T) {
+	newVM := func(name string, runStrategy kubevirtv1.VirtualMachineRunStrategy, annotations map[string]string) *kubevirtv1.VirtualMachine {
+		return &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: annotations},
+			Spec:       kubevirtv1.VirtualMachineSpec{RunStrategy: &runStrategy},
+		}
+	}
+	clientSet := fake.NewSimpleClientset(
+		newVM("db", kubevirtv1.RunStrategyAlways, nil),
+		newVM("stopped", kubevirtv1.RunStrategyHalted, nil),
+	)
+	mutator := NewMutator(fakeclients.VirtualMachineCache(clientSet.KubevirtV1().VirtualMachines)).(*vmiMutator)
+
+	tests := []struct {
+		name        string
+		vm          *kubevirtv1.VirtualMachine
+		vmiLabels   map[string]string
+		expectedOps types.PatchOps
+	}{
+		{
+			name: "vm without boot order",
+			vm:   newVM("app", kubevirtv1.RunStrategyAlways, nil),
+		},
+		{
+			name: "vm boots after a stopped vm",
+			vm:   newVM("app", kubevirtv1.RunStrategyAlways, map[string]string{"harvesterhci.io/bootAfter": "stopped"}),
+		},
+		{
+			name: "vm boots after a running vm",
+			vm:   newVM("app", kubevirtv1.RunStrategyAlways, map[string]string{"harvesterhci.io/bootAfter": "db,stopped"}),
+			expectedOps: types.PatchOps{
+				`{"op": "add", "path": "/spec/startStrategy", "value": "Paused"}`,
+				`{"op": "add", "path": "/metadata/labels", "value": {"harvesterhci.io/bootHeld": "true"}}`,
+			},
+		},
+		{
+			name:      "vm in a higher boot group",
+			vm:        newVM("app", kubevirtv1.RunStrategyAlways, map[string]string{"harvesterhci.io/bootGroup": "1"}),
+			vmiLabels: map[string]string{"app": "web"},
+			expectedOps: types.PatchOps{
+				`{"op": "add", "path": "/spec/startStrategy", "value": "Paused"}`,
+				`{"op": "add", "path": "/metadata/labels/harvesterhci.io~1bootHeld", "value": "true"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vmi := &kubevirtv1.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: tt.vm.Name, Labels: tt.vmiLabels},
+			}
+			patchOps, err := mutator.patchBootHold(tt.vm, vmi)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOps, patchOps)
+		})
+	}
+}
+
 const (
 	vmiWithHostDevice = `{
     "apiVersion": "kubevirt.io/v1",
@@ -0,0 +1,117 @@
+package virtualmachineinstancemigration
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+func NewMutator(
+	vmi ctlkubevirtv1.VirtualMachineInstanceCache,
+	setting ctlharvesterv1.SettingCache,
+) types.Mutator {
+	return &vmimMutator{
+		vmi:     vmi,
+		setting: setting,
+	}
+}
+
+// vmimMutator limits the target nodes of the migrations to the node selector of the VMI migration policy.
+// KubeVirt MigrationPolicies can't select nodes, the migrations started by evictions and node drains
+// get the node selector here as well as the ones started by the migrate action.
+type vmimMutator struct {
+	types.DefaultMutator
+	vmi     ctlkubevirtv1.VirtualMachineInstanceCache
+	setting ctlharvesterv1.SettingCache
+}
+
+func (m *vmimMutator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"virtualmachineinstancemigrations"},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   kubevirtv1.SchemeGroupVersion.Group,
+		APIVersion: kubevirtv1.SchemeGroupVersion.Version,
+		ObjectType: &kubevirtv1.VirtualMachineInstanceMigration{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+		},
+	}
+}
+
+func (m *vmimMutator) Create(_ *types.Request, newObj runtime.Object) (types.PatchOps, error) {
+	vmim := newObj.(*kubevirtv1.VirtualMachineInstanceMigration)
+
+	vmi, err := m.vmi.Get(vmim.Namespace, vmim.Spec.VMIName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	policy, err := m.getMigrationPolicy(vmi.Labels[util.LabelMigrationPolicy])
+	if err != nil || policy == nil {
+		return nil, err
+	}
+
+	// the selectors of the migration take precedence, e.g. the target node of the migrate action
+	nodeSelector := maps.Clone(vmim.Spec.AddedNodeSelector)
+	if nodeSelector == nil {
+		nodeSelector = make(map[string]string, len(policy.NodeSelector))
+	}
+	added := false
+	for key, value := range policy.NodeSelector {
+		if _, ok := nodeSelector[key]; !ok {
+			nodeSelector[key] = value
+			added = true
+		}
+	}
+	if !added {
+		return nil, nil
+	}
+
+	value, err := json.Marshal(nodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	return types.PatchOps{fmt.Sprintf(`{"op": "add", "path": "/spec/addedNodeSelector", "value": %s}`, value)}, nil
+}
+
+// getMigrationPolicy returns the policy of the given name in the vm-migration-policies setting, nil if it's not there
+func (m *vmimMutator) getMigrationPolicy(name string) (*settings.VMMigrationPolicy, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	setting, err := m.setting.Get(settings.VMMigrationPoliciesSettingName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	value := setting.Value
+	if value == "" {
+		value = setting.Default
+	}
+	policies, err := settings.DecodeVMMigrationPolicies(value)
+	if err != nil {
+		return nil, err
+	}
+	for i := range policies {
+		if policies[i].Name == name {
+			return &policies[i], nil
+		}
+	}
+	return nil, nil
+}
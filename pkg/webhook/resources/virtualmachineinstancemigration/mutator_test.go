@@ -0,0 +1,84 @@
+package virtualmachineinstancemigration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+func TestCreate(t *testing.T) {
+	setting := &harvesterv1.Setting{
+		ObjectMeta: metav1.ObjectMeta{Name: settings.VMMigrationPoliciesSettingName},
+		Value:      `[{"name":"database","nodeSelector":{"zone":"zone3"}},{"name":"fast","allowPostCopy":true}]`,
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		nodeSelector map[string]string
+		patches      types.PatchOps
+	}{
+		{
+			name: "vmi without policy",
+		},
+		{
+			name:   "policy without node selector",
+			policy: "fast",
+		},
+		{
+			name:   "policy not in the setting",
+			policy: "unknown",
+		},
+		{
+			name:    "migration started by an eviction",
+			policy:  "database",
+			patches: types.PatchOps{`{"op": "add", "path": "/spec/addedNodeSelector", "value": {"zone":"zone3"}}`},
+		},
+		{
+			name:         "migration to a target node",
+			policy:       "database",
+			nodeSelector: map[string]string{corev1.LabelHostname: "node3"},
+			patches:      types.PatchOps{`{"op": "add", "path": "/spec/addedNodeSelector", "value": {"kubernetes.io/hostname":"node3","zone":"zone3"}}`},
+		},
+		{
+			name:         "migration already selecting the nodes of the policy",
+			policy:       "database",
+			nodeSelector: map[string]string{"zone": "zone3"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vmi := &kubevirtv1.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"},
+			}
+			if tc.policy != "" {
+				vmi.Labels = map[string]string{util.LabelMigrationPolicy: tc.policy}
+			}
+			clientset := fake.NewSimpleClientset(vmi, setting)
+			mutator := NewMutator(
+				fakeclients.VirtualMachineInstanceCache(clientset.KubevirtV1().VirtualMachineInstances),
+				fakeclients.HarvesterSettingCache(clientset.HarvesterhciV1beta1().Settings),
+			)
+
+			vmim := &kubevirtv1.VirtualMachineInstanceMigration{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm-abcde"},
+				Spec:       kubevirtv1.VirtualMachineInstanceMigrationSpec{VMIName: "vm", AddedNodeSelector: tc.nodeSelector},
+			}
+			patches, err := mutator.Create(nil, vmim)
+			require.NoError(t, err)
+			assert.Equal(t, tc.patches, patches)
+		})
+	}
+}
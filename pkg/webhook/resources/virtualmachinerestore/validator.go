@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	ctlv1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	ctlstoragev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/storage/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,6 +38,8 @@ const (
 	fieldVirtualMachineBackupName = "spec.virtualMachineBackupName"
 	fieldNewVM                    = "spec.newVM"
 	fieldKeepMacAddress           = "spec.keepMacAddress"
+	fieldVolumes                  = "spec.volumes"
+	fieldVolumeRestoreMode        = "spec.volumeRestoreMode"
 )
 
 func NewValidator(
@@ -51,6 +54,8 @@ func NewValidator(
 	vmims ctlkubevirtv1.VirtualMachineInstanceMigrationCache,
 	snapshotClass ctlsnapshotv1.VolumeSnapshotClassCache,
 	networkAttachmentDefinitionsCache ctlcniv1.NetworkAttachmentDefinitionCache,
+	backupTargetCache ctlharvesterv1.BackupTargetCache,
+	scCache ctlstoragev1.StorageClassCache,
 ) types.Validator {
 	return &restoreValidator{
 		vms:                               vms,
@@ -60,6 +65,8 @@ func NewValidator(
 		svmbackup:                         svmbackup,
 		snapshotClass:                     snapshotClass,
 		networkAttachmentDefinitionsCache: networkAttachmentDefinitionsCache,
+		backupTargetCache:                 backupTargetCache,
+		scCache:                           scCache,
 
 		vmrCalculator: resourcequota.NewCalculator(nss, pods, rqs, vmims, setting),
 	}
@@ -75,6 +82,8 @@ type restoreValidator struct {
 	svmbackup                         ctlharvesterv1.ScheduleVMBackupCache
 	snapshotClass                     ctlsnapshotv1.VolumeSnapshotClassCache
 	networkAttachmentDefinitionsCache ctlcniv1.NetworkAttachmentDefinitionCache
+	backupTargetCache                 ctlharvesterv1.BackupTargetCache
+	scCache                           ctlstoragev1.StorageClassCache
 
 	vmrCalculator *resourcequota.Calculator
 }
@@ -106,8 +115,9 @@ func (v *restoreValidator) Create(_ *types.Request, newObj runtime.Object) error
 		return werror.NewInvalidError(err.Error(), fieldVirtualMachineBackupName)
 	}
 
+	// standalone volume restores don't touch the source VM, e.g. the ones of backup verifications
 	svmbackup := util.ResolveSVMBackupRef(v.svmbackup, vmBackup)
-	if svmbackup != nil && !svmbackup.Spec.Suspend {
+	if svmbackup != nil && !svmbackup.Spec.Suspend && !ctlbackup.IsStandaloneVolumeRestore(newRestore) {
 		return werror.NewInternalError(fmt.Sprintf("Source schedule %s/%s is running", svmbackup.Namespace, svmbackup.Name))
 	}
 
@@ -115,7 +125,11 @@ func (v *restoreValidator) Create(_ *types.Request, newObj runtime.Object) error
 		return werror.NewInvalidError(err.Error(), fieldVirtualMachineBackupName)
 	}
 
-	if err := v.checkNetwork(vmBackup); err != nil {
+	if err := v.checkNetwork(newRestore, vmBackup); err != nil {
+		return werror.NewInvalidError(err.Error(), fieldVirtualMachineBackupName)
+	}
+
+	if err := v.checkStorageClass(newRestore, vmBackup); err != nil {
 		return werror.NewInvalidError(err.Error(), fieldVirtualMachineBackupName)
 	}
 
@@ -123,6 +137,15 @@ func (v *restoreValidator) Create(_ *types.Request, newObj runtime.Object) error
 		return err
 	}
 
+	if err := v.checkVolumes(newRestore, vmBackup); err != nil {
+		return err
+	}
+
+	// standalone volume restores don't touch any VM
+	if ctlbackup.IsStandaloneVolumeRestore(newRestore) {
+		return nil
+	}
+
 	return v.checkNewVMField(newRestore, vmBackup)
 }
 
@@ -245,7 +268,7 @@ func (v *restoreValidator) checkSnapshot(vmRestore *v1beta1.VirtualMachineRestor
 	if vmRestore.Namespace != vmBackup.Namespace {
 		return fmt.Errorf("restore to other namespace with backup type snapshot is not supported")
 	}
-	if !vmRestore.Spec.NewVM && vmRestore.Spec.DeletionPolicy != v1beta1.VirtualMachineRestoreRetain {
+	if !ctlbackup.IsNewVMOrHasRetainPolicy(vmRestore) {
 		// We don't allow users to use "delete" policy for replacing a VM when the backup type is snapshot.
 		// This will also remove the VMBackup when VMRestore is finished.
 		return fmt.Errorf("delete policy with backup type snapshot for replacing VM is not supported")
@@ -253,19 +276,107 @@ func (v *restoreValidator) checkSnapshot(vmRestore *v1beta1.VirtualMachineRestor
 	return nil
 }
 
-func (v *restoreValidator) checkNetwork(vmBackup *v1beta1.VirtualMachineBackup) error {
+// checkVolumes validates the selected volumes of partial restores
+func (v *restoreValidator) checkVolumes(vmRestore *v1beta1.VirtualMachineRestore, vmBackup *v1beta1.VirtualMachineBackup) error {
+	if len(vmRestore.Spec.Volumes) == 0 {
+		if vmRestore.Spec.VolumeRestoreMode != "" {
+			return werror.NewInvalidError("volume restore mode only works with selected volumes", fieldVolumeRestoreMode)
+		}
+		return nil
+	}
+
+	switch vmRestore.Spec.VolumeRestoreMode {
+	case v1beta1.VolumeRestoreModeStandalone, v1beta1.VolumeRestoreModeInPlace:
+		if vmRestore.Spec.NewVM {
+			return werror.NewInvalidError(fmt.Sprintf("can't restore selected volumes to a new vm in %s mode", vmRestore.Spec.VolumeRestoreMode), fieldNewVM)
+		}
+	default:
+		return werror.NewInvalidError(fmt.Sprintf("volume restore mode must be %s or %s", v1beta1.VolumeRestoreModeStandalone, v1beta1.VolumeRestoreModeInPlace), fieldVolumeRestoreMode)
+	}
+
+	backupVolumes := map[string]bool{}
+	for _, volumeBackup := range vmBackup.Status.VolumeBackups {
+		backupVolumes[volumeBackup.VolumeName] = true
+	}
+
+	selectedVolumes := map[string]bool{}
+	for _, volume := range vmRestore.Spec.Volumes {
+		if selectedVolumes[volume] {
+			return werror.NewInvalidError(fmt.Sprintf("volume %s is duplicated", volume), fieldVolumes)
+		}
+		if !backupVolumes[volume] {
+			return werror.NewInvalidError(fmt.Sprintf("volume %s is not found in vmbackup %s/%s", volume, vmBackup.Namespace, vmBackup.Name), fieldVolumes)
+		}
+		selectedVolumes[volume] = true
+	}
+
+	if vmRestore.Spec.VolumeRestoreMode == v1beta1.VolumeRestoreModeStandalone {
+		return nil
+	}
+
+	// the target vm existence is checked by checkNewVMField
+	vm, err := v.vms.Get(vmRestore.Namespace, vmRestore.Spec.Target.Name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return werror.NewInternalError(fmt.Sprintf("failed to get the VM %s/%s, err: %+v", vmRestore.Namespace, vmRestore.Spec.Target.Name, err))
+	}
+
+	vmVolumes := map[string]bool{}
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		vmVolumes[volume.Name] = volume.PersistentVolumeClaim != nil
+	}
+	for _, volume := range vmRestore.Spec.Volumes {
+		if !vmVolumes[volume] {
+			return werror.NewInvalidError(fmt.Sprintf("volume %s is not a PVC volume of vm %s/%s", volume, vm.Namespace, vm.Name), fieldVolumes)
+		}
+	}
+	return nil
+}
+
+func (v *restoreValidator) checkNetwork(vmRestore *v1beta1.VirtualMachineRestore, vmBackup *v1beta1.VirtualMachineBackup) error {
+	// partial volume restores keep the networks of the target VM or create no VM
+	if vmRestore.Spec.VolumeRestoreMode != "" {
+		return nil
+	}
+
 	for _, network := range vmBackup.Status.SourceSpec.Spec.Template.Spec.Networks {
 		if network.Multus != nil {
-			namespace, name := ref.Parse(network.Multus.NetworkName)
+			networkName := ctlbackup.MapNetworkName(vmRestore, vmBackup.Status.SourceSpec.ObjectMeta.Namespace, network.Multus.NetworkName)
+			namespace, name := ref.Parse(networkName)
+			if namespace == "" {
+				namespace = vmRestore.Namespace
+			}
 			_, err := v.networkAttachmentDefinitionsCache.Get(namespace, name)
 			if err != nil {
-				return fmt.Errorf("failed to get network attachment definition %s, err: %v", network.Multus.NetworkName, err)
+				return fmt.Errorf("failed to get network attachment definition %s, map it to another network with spec.networkMapping, err: %v", networkName, err)
 			}
 		}
 	}
 	return nil
 }
 
+// checkStorageClass checks the storage classes of the restored PVCs, which may differ in another cluster
+func (v *restoreValidator) checkStorageClass(vmRestore *v1beta1.VirtualMachineRestore, vmBackup *v1beta1.VirtualMachineBackup) error {
+	for _, volumeBackup := range vmBackup.Status.VolumeBackups {
+		storageClassName := volumeBackup.PersistentVolumeClaim.Spec.StorageClassName
+		if storageClassName == nil || !ctlbackup.IsVolumeSelected(vmRestore, volumeBackup.VolumeName) {
+			continue
+		}
+
+		name := ctlbackup.MapStorageClassName(vmRestore, *storageClassName)
+		sc, err := v.scCache.Get(name)
+		if err != nil {
+			return fmt.Errorf("failed to get storage class %s of volume %s, map it to another storage class with spec.storageClassMapping, err: %v", name, volumeBackup.VolumeName, err)
+		}
+		if sc.DeletionTimestamp != nil {
+			return fmt.Errorf("storage class %s of volume %s is being deleted", name, volumeBackup.VolumeName)
+		}
+	}
+	return nil
+}
+
 func (v *restoreValidator) checkVMBackupType(vmRestore *v1beta1.VirtualMachineRestore, vmBackup *v1beta1.VirtualMachineBackup) error {
 	var err error
 	switch vmBackup.Spec.Type {
@@ -315,6 +426,20 @@ func (v *restoreValidator) checkBackup(vmRestore *v1beta1.VirtualMachineRestore,
 }
 
 func (v *restoreValidator) checkBackupTarget(vmBackup *v1beta1.VirtualMachineBackup) error {
+	if vmBackup.Spec.BackupTargetName != "" {
+		bt, err := v.backupTargetCache.Get(vmBackup.Spec.BackupTargetName)
+		if err != nil {
+			return fmt.Errorf("can't get backup target %s, err: %w", vmBackup.Spec.BackupTargetName, err)
+		}
+		if !v1beta1.BackupTargetConditionAvailable.IsTrue(bt) {
+			return fmt.Errorf("backup target %s is not available", bt.Name)
+		}
+		if !backuputil.IsBackupTargetSame(vmBackup.Status.BackupTarget, backuputil.ConvertBackupTarget(bt)) {
+			return fmt.Errorf("backup target %s is not matched in vmBackup %s/%s", bt.Name, vmBackup.Namespace, vmBackup.Name)
+		}
+		return nil
+	}
+
 	backupTargetSetting, err := v.setting.Get(settings.BackupTargetSettingName)
 	if err != nil {
 		return fmt.Errorf("can't get backup target setting, err: %w", err)
@@ -17,15 +17,17 @@ import (
 )
 
 const (
-	fieldType   = "spec.type"
-	fieldSource = "spec.source"
-	fieldFrom   = "spec.from"
+	fieldType             = "spec.type"
+	fieldSource           = "spec.source"
+	fieldFrom             = "spec.from"
+	fieldBackupTargetName = "spec.backupTargetName"
 )
 
 type remoteBackupValidator struct {
 	types.DefaultValidator
-	pvcCache ctlv1.PersistentVolumeClaimCache
-	bo       common.BackupOperator
+	pvcCache          ctlv1.PersistentVolumeClaimCache
+	backupTargetCache ctlharvesterv1.BackupTargetCache
+	bo                common.BackupOperator
 }
 
 func NewBackupValidator(
@@ -33,10 +35,12 @@ func NewBackupValidator(
 	remoteBackupClient ctlharvesterv1.VolumeRemoteBackupClient,
 	scCache ctlstoragev1.StorageClassCache,
 	settingCache ctlharvesterv1.SettingCache,
+	backupTargetCache ctlharvesterv1.BackupTargetCache,
 ) types.Validator {
 	return &remoteBackupValidator{
-		pvcCache: pvcCache,
-		bo:       common.NewBackupOperator(remoteBackupClient, pvcCache, scCache, settingCache),
+		pvcCache:          pvcCache,
+		backupTargetCache: backupTargetCache,
+		bo:                common.NewBackupOperator(remoteBackupClient, pvcCache, scCache, settingCache),
 	}
 }
 
@@ -67,6 +71,26 @@ func (v *remoteBackupValidator) Create(request *types.Request, newObj runtime.Ob
 		return werror.NewInternalError(fmt.Sprintf("failed to get PVC %s/%s: %v", v.bo.GetNamespace(vrb), v.bo.GetSource(vrb), err))
 	}
 
+	return v.checkBackupTarget(vrb)
+}
+
+// checkBackupTarget checks the named backup target, only the generic driver stores the data on it.
+// The Longhorn driver uses the backup target of Longhorn.
+func (v *remoteBackupValidator) checkBackupTarget(vrb *v1beta1.VolumeRemoteBackup) error {
+	if vrb.Spec.BackupTargetName == "" {
+		return nil
+	}
+	if vrb.Spec.Type != v1beta1.VolumeRemoteBackupGeneric {
+		return werror.NewInvalidError("backup target can only be set for generic type", fieldBackupTargetName)
+	}
+
+	bt, err := v.backupTargetCache.Get(vrb.Spec.BackupTargetName)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("can't get backup target %s, err: %v", vrb.Spec.BackupTargetName, err), fieldBackupTargetName)
+	}
+	if bt.DeletionTimestamp != nil {
+		return werror.NewInvalidError(fmt.Sprintf("backup target %s is being deleted", vrb.Spec.BackupTargetName), fieldBackupTargetName)
+	}
 	return nil
 }
 
@@ -82,6 +106,10 @@ func (v *remoteBackupValidator) Update(request *types.Request, oldObj runtime.Ob
 		return werror.NewInvalidError("spec.source cannot be changed", fieldSource)
 	}
 
+	if oldVrb.Spec.BackupTargetName != newVrb.Spec.BackupTargetName {
+		return werror.NewInvalidError("spec.backupTargetName cannot be changed", fieldBackupTargetName)
+	}
+
 	return nil
 }
 
@@ -150,6 +178,12 @@ func (v *remoteRestoreValidator) Create(request *types.Request, newObj runtime.O
 		return werror.NewInvalidError(fmt.Sprintf("PVCBackup %s/%s is not ready", vrbNamespace, vrbName), fieldFrom)
 	}
 
+	// The backup can only be restored by the driver which created it
+	if string(v.ro.GetType(vrr)) != string(vrb.Spec.Type) {
+		return werror.NewInvalidError(fmt.Sprintf("PVCBackup %s/%s is of type %s, can't be restored with type %s",
+			vrbNamespace, vrbName, vrb.Spec.Type, v.ro.GetType(vrr)), fieldType)
+	}
+
 	// Check if a PVCBackup with the same name exists in the restore's namespace
 	_, err = v.remoteBackupCache.Get(v.ro.GetNamespace(vrr), v.ro.GetName(vrr))
 	if err == nil {
@@ -19,6 +19,7 @@ import (
 	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachinebackup"
 	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachineimage"
 	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachineinstance"
+	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachineinstancemigration"
 	"github.com/harvester/harvester/pkg/webhook/types"
 )
 
@@ -32,6 +33,7 @@ func Mutation(clients *clients.Clients, options *config.Options, crdExists bool)
 	vmImgCache := clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage().Cache()
 	vmCache := clients.KubevirtFactory.Kubevirt().V1().VirtualMachine().Cache()
 	kubevirtCache := clients.KubevirtFactory.Kubevirt().V1().KubeVirt().Cache()
+	vmiCache := clients.KubevirtFactory.Kubevirt().V1().VirtualMachineInstance().Cache()
 	var kubeovnSubnetCache ctlkubeovnv1.SubnetCache
 	if crdExists {
 		kubeovnSubnetCache = clients.KubeovnFactory.Kubeovn().V1().Subnet().Cache()
@@ -43,6 +45,7 @@ func Mutation(clients *clients.Clients, options *config.Options, crdExists bool)
 		upgrade.NewMutator(nodeCache, settingCache),
 		virtualmachine.NewMutator(settingCache, nadCache, kubevirtCache, kubeovnSubnetCache),
 		virtualmachineinstance.NewMutator(vmCache),
+		virtualmachineinstancemigration.NewMutator(vmiCache, settingCache),
 		virtualmachineimage.NewMutator(storageClassCache),
 		virtualmachinebackup.NewMutator(vmBackupCache),
 		storageclass.NewMutator(),
@@ -13,10 +13,12 @@ import (
 	"github.com/harvester/harvester/pkg/webhook/clients"
 	"github.com/harvester/harvester/pkg/webhook/config"
 	"github.com/harvester/harvester/pkg/webhook/resources/addon"
+	"github.com/harvester/harvester/pkg/webhook/resources/backuptarget"
 	"github.com/harvester/harvester/pkg/webhook/resources/bundle"
 	"github.com/harvester/harvester/pkg/webhook/resources/bundledeployment"
 	"github.com/harvester/harvester/pkg/webhook/resources/datavolume"
 	"github.com/harvester/harvester/pkg/webhook/resources/deployment"
+	"github.com/harvester/harvester/pkg/webhook/resources/imagereplication"
 	"github.com/harvester/harvester/pkg/webhook/resources/keypair"
 	"github.com/harvester/harvester/pkg/webhook/resources/managedchart"
 	"github.com/harvester/harvester/pkg/webhook/resources/namespace"
@@ -25,6 +27,7 @@ import (
 	"github.com/harvester/harvester/pkg/webhook/resources/persistentvolumeclaim"
 	"github.com/harvester/harvester/pkg/webhook/resources/resourcequota"
 	"github.com/harvester/harvester/pkg/webhook/resources/schedulevmbackup"
+	"github.com/harvester/harvester/pkg/webhook/resources/schedulevmpoweraction"
 	"github.com/harvester/harvester/pkg/webhook/resources/secret"
 	"github.com/harvester/harvester/pkg/webhook/resources/setting"
 	"github.com/harvester/harvester/pkg/webhook/resources/storageclass"
@@ -34,6 +37,8 @@ import (
 	"github.com/harvester/harvester/pkg/webhook/resources/version"
 	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachine"
 	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachinebackup"
+	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachinebackupverification"
+	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachinebulkaction"
 	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachineimage"
 	"github.com/harvester/harvester/pkg/webhook/resources/virtualmachinerestore"
 	"github.com/harvester/harvester/pkg/webhook/resources/volumeremotebackup"
@@ -79,7 +84,8 @@ func Validation(clients *clients.Clients, options *config.Options, crdExists boo
 			clients.Core.PersistentVolumeClaim().Cache(),
 			clients.HarvesterFactory.Harvesterhci().V1beta1().VolumeRemoteBackup(),
 			clients.StorageFactory.Storage().V1().StorageClass().Cache(),
-			clients.HarvesterFactory.Harvesterhci().V1beta1().Setting().Cache()),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().Setting().Cache(),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget().Cache()),
 		volumeremotebackup.NewRestoreValidator(
 			clients.HarvesterFactory.Harvesterhci().V1beta1().VolumeRemoteRestore(),
 			clients.Core.PersistentVolumeClaim().Cache(),
@@ -105,12 +111,15 @@ func Validation(clients *clients.Clients, options *config.Options, crdExists boo
 			clients.CNIFactory.K8s().V1().NetworkAttachmentDefinition().Cache(),
 			clients.KubevirtFactory.Kubevirt().V1().KubeVirt().Cache(),
 			clients.StorageFactory.Storage().V1().StorageClass().Cache(),
-			clients.HarvesterFactory.Harvesterhci().V1beta1().Setting().Cache()),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().Setting().Cache(),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage().Cache(),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineDownloader().Cache()),
 		virtualmachineimage.NewValidator(
 			clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage().Cache(),
 			clients.Core.Pod().Cache(),
 			clients.Core.PersistentVolumeClaim().Cache(),
 			clients.K8s.AuthorizationV1().SelfSubjectAccessReviews(),
+			clients.K8s.AuthorizationV1().SubjectAccessReviews(),
 			clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplateVersion().Cache(),
 			clients.StorageFactory.Storage().V1().StorageClass().Cache(),
 			clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup().Cache()),
@@ -143,6 +152,8 @@ func Validation(clients *clients.Clients, options *config.Options, crdExists boo
 			clients.StorageFactory.Storage().V1().StorageClass().Cache(),
 			clients.HarvesterFactory.Harvesterhci().V1beta1().ResourceQuota().Cache(),
 			clients.KubevirtFactory.Kubevirt().V1().VirtualMachineInstanceMigration().Cache(),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget().Cache(),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup().Cache(),
 		),
 		virtualmachinerestore.NewValidator(
 			clients.Core.Namespace().Cache(),
@@ -156,6 +167,8 @@ func Validation(clients *clients.Clients, options *config.Options, crdExists boo
 			clients.KubevirtFactory.Kubevirt().V1().VirtualMachineInstanceMigration().Cache(),
 			clients.SnapshotFactory.Snapshot().V1().VolumeSnapshotClass().Cache(),
 			clients.CNIFactory.K8s().V1().NetworkAttachmentDefinition().Cache(),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget().Cache(),
+			clients.StorageFactory.Storage().V1().StorageClass().Cache(),
 		),
 		setting.NewValidator(
 			clients.HarvesterFactory.Harvesterhci().V1beta1().Setting().Cache(),
@@ -220,6 +233,22 @@ func Validation(clients *clients.Clients, options *config.Options, crdExists boo
 			clients.HarvesterFactory.Harvesterhci().V1beta1().Setting().Cache(),
 			clients.Core.Secret().Cache(),
 			clients.HarvesterFactory.Harvesterhci().V1beta1().ScheduleVMBackup().Cache(),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget().Cache(),
+			clients.Core.Namespace().Cache(),
+			clients.K8s.AuthorizationV1().SubjectAccessReviews(),
+		),
+		schedulevmpoweraction.NewValidator(),
+		imagereplication.NewValidator(clients.K8s.AuthorizationV1().SubjectAccessReviews()),
+		virtualmachinebulkaction.NewValidator(clients.K8s.AuthorizationV1().SubjectAccessReviews()),
+		backuptarget.NewValidator(
+			clients.Core.Secret().Cache(),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup().Cache(),
+			clients.HarvesterFactory.Harvesterhci().V1beta1().ScheduleVMBackup().Cache(),
+		),
+		virtualmachinebackupverification.NewValidator(
+			clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup().Cache(),
+			clients.Core.Namespace().Cache(),
+			clients.K8s.AuthorizationV1().SubjectAccessReviews(),
 		),
 		secret.NewValidator(clients.StorageFactory.Storage().V1().StorageClass().Cache()),
 		supportbundle.NewValidator(clients.Core.Namespace().Cache()),
@@ -116,6 +116,7 @@ func (v *AdmissionHandler) admit(response *webhook.Response, req *Request) {
 		logrus.Debugf("%s patchOps: %s", req, patchData)
 	}
 
+	response.Warnings = req.Warnings()
 	logrus.Debugf("%s operation is allowed", req)
 	response.Allowed = true
 }
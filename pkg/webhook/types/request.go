@@ -13,6 +13,8 @@ import (
 type Request struct {
 	*webhook.Request
 	options *config.Options
+	// warnings are returned to the client when the operation is allowed
+	warnings []string
 }
 
 func NewRequest(webhookRequest *webhook.Request, options *config.Options) *Request {
@@ -37,6 +39,15 @@ func (r *Request) IsGarbageCollection() bool {
 	return r.Operation == admissionv1.Delete && r.Username() == r.options.GarbageCollectionUsername
 }
 
+// AddWarning adds a warning shown to the client, e.g. kubectl, if the operation is allowed
+func (r *Request) AddWarning(warning string) {
+	r.warnings = append(r.warnings, warning)
+}
+
+func (r *Request) Warnings() []string {
+	return r.warnings
+}
+
 func (r *Request) DecodeObjects() (oldObj runtime.Object, newObj runtime.Object, err error) {
 	operation := r.Operation
 	if operation == admissionv1.Delete || operation == admissionv1.Update {
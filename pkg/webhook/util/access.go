@@ -0,0 +1,51 @@
+package util
+
+import (
+	"context"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// CanAccess checks whether the user of an admission request is allowed to do what the attributes describe,
+// it's used for the resources which a controller reads or changes on behalf of the user.
+func CanAccess(
+	sar authorizationv1client.SubjectAccessReviewInterface,
+	userInfo authenticationv1.UserInfo,
+	attributes authorizationv1.ResourceAttributes,
+) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(userInfo.Extra))
+	for k, v := range userInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review, err := sar.Create(context.TODO(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+			User:               userInfo.Username,
+			Groups:             userInfo.Groups,
+			UID:                userInfo.UID,
+			Extra:              extra,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// CanGetSecret checks whether the user of an admission request is allowed to get the secret
+func CanGetSecret(
+	sar authorizationv1client.SubjectAccessReviewInterface,
+	userInfo authenticationv1.UserInfo,
+	namespace, name string,
+) (bool, error) {
+	return CanAccess(sar, userInfo, authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Name:      name,
+		Verb:      "get",
+		Version:   "v1",
+		Resource:  "secrets",
+	})
+}